@@ -0,0 +1,661 @@
+// Command wazuh-simulator is a small in-memory stand-in for the Wazuh manager API.
+// It implements the endpoints used by this service so suppression pushes, rollbacks,
+// rule snapshots, logtest replays and agent group lookups can be exercised without a real manager:
+//
+//	go run ./cmd/wazuh-simulator -addr :55000
+//	WAZUH_URL=http://localhost:55000 go run ./cmd/server
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"automation-wazuh-triage/internal/entity"
+)
+
+const simulatorToken = "simulator-token"
+
+const defaultLocalRules = `<!-- Local rules -->
+
+<group name="local,syslog,sshd,">
+  <rule id="100001" level="5">
+    <if_sid>5716</if_sid>
+    <srcip>1.1.1.1</srcip>
+    <description>sshd: authentication failed from IP 1.1.1.1.</description>
+    <group>authentication_failed,pci_dss_10.2.4,pci_dss_10.2.5,</group>
+  </rule>
+</group>
+`
+
+// simRule is a rule known to the simulator. Besides the API representation it keeps the
+// parent rule and the options needed to evaluate logtest samples.
+type simRule struct {
+	entity.WazuhRule
+	IfSid   int
+	Options []simOption
+}
+
+// simOption is a single rule option such as <match>, <srcip> or <field name="...">
+type simOption struct {
+	Tag    string
+	Name   string
+	Type   string
+	Negate bool
+	Value  string
+}
+
+type simulator struct {
+	mu        sync.Mutex
+	baseRules []simRule
+	ruleFiles map[string]string
+	fileRules map[string][]simRule
+	sessions  map[string]bool
+	restarts  int
+	agents    []entity.WazuhAgent
+}
+
+func main() {
+	addr := flag.String("addr", ":55000", "listen address")
+	flag.Parse()
+
+	sim := newSimulator()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /security/user/authenticate", sim.authenticate)
+	mux.HandleFunc("GET /agents", sim.authorized(sim.getAgents))
+	mux.HandleFunc("GET /rules", sim.authorized(sim.getRules))
+	mux.HandleFunc("GET /rules/files/{filename}", sim.authorized(sim.getRuleFile))
+	mux.HandleFunc("PUT /rules/files/{filename}", sim.authorized(sim.putRuleFile))
+	mux.HandleFunc("DELETE /rules/files/{filename}", sim.authorized(sim.deleteRuleFile))
+	mux.HandleFunc("GET /manager/configuration/validation", sim.authorized(sim.validate))
+	mux.HandleFunc("PUT /manager/restart", sim.authorized(sim.restart))
+	mux.HandleFunc("PUT /logtest", sim.authorized(sim.logtest))
+	mux.HandleFunc("DELETE /logtest/sessions/{token}", sim.authorized(sim.endLogtestSession))
+
+	log.Printf("wazuh simulator listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func newSimulator() *simulator {
+	sim := &simulator{
+		baseRules: []simRule{
+			{WazuhRule: entity.WazuhRule{Filename: "0095-sshd_rules.xml", RelativeDirname: "ruleset/rules", ID: 5710, Level: 5, Status: "enabled", Groups: []string{"syslog", "sshd", "authentication_failed", "invalid_login"}, PciDss: []string{"10.2.4", "10.2.5", "10.6.1"}, Mitre: []string{"T1110.001", "T1021.004"}, Description: "sshd: Attempt to login using a non-existent user"}, Options: []simOption{{Tag: "match", Value: `illegal user|invalid user`}}},
+			{WazuhRule: entity.WazuhRule{Filename: "0095-sshd_rules.xml", RelativeDirname: "ruleset/rules", ID: 5715, Level: 3, Status: "enabled", Groups: []string{"syslog", "sshd", "authentication_success"}, PciDss: []string{"10.2.5"}, Mitre: []string{"T1078", "T1021"}, Description: "sshd: authentication success."}, Options: []simOption{{Tag: "match", Value: `Accepted password|Accepted publickey`}}},
+			{WazuhRule: entity.WazuhRule{Filename: "0095-sshd_rules.xml", RelativeDirname: "ruleset/rules", ID: 5716, Level: 5, Status: "enabled", Groups: []string{"syslog", "sshd", "authentication_failed"}, PciDss: []string{"10.2.4", "10.2.5"}, Mitre: []string{"T1110.001"}, Description: "sshd: authentication failed."}, Options: []simOption{{Tag: "match", Value: `Failed password|Failed keyboard|authentication failure`}}},
+			{WazuhRule: entity.WazuhRule{Filename: "0095-sshd_rules.xml", RelativeDirname: "ruleset/rules", ID: 5763, Level: 10, Status: "enabled", Groups: []string{"syslog", "sshd", "authentication_failures"}, PciDss: []string{"11.4", "10.2.4", "10.2.5"}, Mitre: []string{"T1110"}, Description: "sshd: brute force trying to get access to the system. Authentication failed."}},
+			{WazuhRule: entity.WazuhRule{Filename: "0015-ossec_rules.xml", RelativeDirname: "ruleset/rules", ID: 550, Level: 7, Status: "enabled", Groups: []string{"ossec", "syscheck", "syscheck_entry_modified", "syscheck_file"}, PciDss: []string{"11.5"}, Mitre: []string{"T1565.001"}, Description: "Integrity checksum changed."}},
+			{WazuhRule: entity.WazuhRule{Filename: "0245-web_rules.xml", RelativeDirname: "ruleset/rules", ID: 31101, Level: 5, Status: "enabled", Groups: []string{"web", "accesslog", "attack"}, PciDss: []string{"6.5", "11.4"}, Description: "Web server 400 error code."}, Options: []simOption{{Tag: "match", Value: `" 400 `}}},
+		},
+		ruleFiles: map[string]string{"local_rules.xml": defaultLocalRules},
+		fileRules: map[string][]simRule{},
+		sessions:  map[string]bool{},
+		agents: []entity.WazuhAgent{
+			{ID: "000", Name: "wazuh-manager", IP: "127.0.0.1", Groups: []string{}},
+			{ID: "001", Name: "web-01", IP: "10.0.0.11", Groups: []string{"default", "web"}},
+			{ID: "002", Name: "web-02", IP: "10.0.0.12", Groups: []string{"default", "web"}},
+			{ID: "003", Name: "db-01", IP: "10.0.0.21", Groups: []string{"default", "database"}},
+		},
+	}
+
+	if rules, err := parseRuleFile("local_rules.xml", defaultLocalRules); err == nil {
+		sim.fileRules["local_rules.xml"] = rules
+	}
+
+	return sim
+}
+
+func (s *simulator) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+simulatorToken {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"title": "Unauthorized", "detail": "Invalid token", "error": 6000})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *simulator) authenticate(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := r.BasicAuth(); !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"title": "Unauthorized", "error": 401})
+		return
+	}
+
+	if r.URL.Query().Get("raw") == "true" {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, simulatorToken)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]string{"token": simulatorToken}, "error": 0})
+}
+
+func (s *simulator) getRules(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	rules := s.allRules()
+	s.mu.Unlock()
+
+	query := r.URL.Query()
+
+	if ids := query.Get("rule_ids"); ids != "" {
+		wanted := map[string]bool{}
+		for _, id := range strings.Split(ids, ",") {
+			wanted[strings.TrimSpace(id)] = true
+		}
+		rules = filterRules(rules, func(rule entity.WazuhRule) bool { return wanted[strconv.Itoa(rule.ID)] })
+	}
+
+	if filename := query.Get("filename"); filename != "" {
+		rules = filterRules(rules, func(rule entity.WazuhRule) bool { return rule.Filename == filename })
+	}
+
+	total := len(rules)
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 500
+	}
+
+	if offset > len(rules) {
+		offset = len(rules)
+	}
+	end := offset + limit
+	if end > len(rules) {
+		end = len(rules)
+	}
+
+	writeAffectedItems(w, rules[offset:end], total)
+}
+
+func (s *simulator) getAgents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var agents []entity.WazuhAgent
+	for _, agent := range s.agents {
+		if group := query.Get("group"); group != "" && !containsGroup(agent.Groups, group) {
+			continue
+		}
+		agents = append(agents, agent)
+	}
+
+	total := len(agents)
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 500
+	}
+
+	if offset > len(agents) {
+		offset = len(agents)
+	}
+	end := offset + limit
+	if end > len(agents) {
+		end = len(agents)
+	}
+
+	writeAffectedItems(w, agents[offset:end], total)
+}
+
+func containsGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *simulator) getRuleFile(w http.ResponseWriter, r *http.Request) {
+	filename := r.PathValue("filename")
+
+	s.mu.Lock()
+	content, ok := s.ruleFiles[filename]
+	s.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"title": "Not Found", "detail": "File not found", "error": 1415})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, content)
+}
+
+func (s *simulator) putRuleFile(w http.ResponseWriter, r *http.Request) {
+	filename := r.PathValue("filename")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"title": "Bad Request", "error": 1})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.ruleFiles[filename]; exists && r.URL.Query().Get("overwrite") != "true" {
+		writeJSON(w, http.StatusOK, failedItem(filename, 1905, "File could not be uploaded, it already exists"))
+		return
+	}
+
+	rules, err := parseRuleFile(filename, string(body))
+	if err != nil {
+		writeJSON(w, http.StatusOK, failedItem(filename, 1113, "XML syntax error: "+err.Error()))
+		return
+	}
+
+	s.ruleFiles[filename] = string(body)
+	s.fileRules[filename] = rules
+
+	log.Printf("rule file %s uploaded (%d rules)", filename, len(rules))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":    map[string]interface{}{"affected_items": []string{"etc/rules/" + filename}, "total_affected_items": 1, "failed_items": []interface{}{}, "total_failed_items": 0},
+		"message": "File was successfully updated",
+		"error":   0,
+	})
+}
+
+func (s *simulator) deleteRuleFile(w http.ResponseWriter, r *http.Request) {
+	filename := r.PathValue("filename")
+
+	s.mu.Lock()
+	delete(s.ruleFiles, filename)
+	delete(s.fileRules, filename)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":    map[string]interface{}{"affected_items": []string{"etc/rules/" + filename}, "total_affected_items": 1, "failed_items": []interface{}{}, "total_failed_items": 0},
+		"message": "File was successfully deleted",
+		"error":   0,
+	})
+}
+
+func (s *simulator) validate(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for filename, content := range s.ruleFiles {
+		if _, err := parseRuleFile(filename, content); err != nil {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"data":  map[string]interface{}{"affected_items": []interface{}{}, "failed_items": []interface{}{map[string]interface{}{"error": map[string]interface{}{"code": 1908, "message": filename + ": " + err.Error()}}}},
+				"error": 1,
+			})
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  map[string]interface{}{"affected_items": []interface{}{map[string]string{"name": "simulator", "status": "OK"}}, "failed_items": []interface{}{}},
+		"error": 0,
+	})
+}
+
+func (s *simulator) restart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.restarts++
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Restart request sent to all specified nodes", "error": 0})
+}
+
+var (
+	// syslogHeader splits a classic syslog line into timestamp, hostname, program name and message
+	syslogHeader = regexp.MustCompile(`^(\w{3}\s+\d+\s+\d{2}:\d{2}:\d{2})\s+(\S+)\s+([^\s\[:]+)(?:\[\d+\])?:\s*(.*)$`)
+	srcIPPattern = regexp.MustCompile(`from (\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3})`)
+	userPattern  = regexp.MustCompile(`(?:for (?:invalid user |illegal user )?|user=|user )(\S+)`)
+)
+
+// logtest evaluates a single log line. Decoding is reduced to the syslog predecoder plus
+// srcip and user extraction, and rules are matched through <match>, IP and pcre2 options.
+func (s *simulator) logtest(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Token     string `json:"token"`
+		LogFormat string `json:"log_format"`
+		Location  string `json:"location"`
+		Event     string `json:"event"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Event == "" || request.Location == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"title": "Bad Request", "detail": "event, log_format and location are required", "error": 1})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []string
+	token := request.Token
+	if token == "" || !s.sessions[token] {
+		if token != "" {
+			messages = append(messages, fmt.Sprintf("WARNING: (7309): '%s' is not a valid token", token))
+		}
+		token = fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+		s.sessions[token] = true
+		messages = append(messages, fmt.Sprintf("INFO: (7202): Session initialized with token '%s'", token))
+	}
+
+	event := decodeEvent(request.Event, request.Location)
+	output := map[string]interface{}{
+		"timestamp": time.Now().UTC().Format("2006-01-02T15:04:05.000-0700"),
+		"full_log":  request.Event,
+		"location":  request.Location,
+		"agent":     map[string]string{"id": "000", "name": "wazuh-manager"},
+		"manager":   map[string]string{"name": "wazuh-manager"},
+	}
+
+	if program := event["program_name"]; program != "" {
+		output["predecoder"] = map[string]string{"program_name": program, "hostname": event["hostname"], "timestamp": event["timestamp"]}
+		output["decoder"] = map[string]string{"name": program}
+	}
+
+	data := map[string]string{}
+	for _, key := range []string{"srcip", "srcuser"} {
+		if value := event[key]; value != "" {
+			data[key] = value
+		}
+	}
+	if len(data) > 0 {
+		output["data"] = data
+	}
+
+	alert := false
+	if rule := matchRule(s.orderedRules(), event); rule != nil {
+		output["rule"] = map[string]interface{}{
+			"id":          strconv.Itoa(rule.ID),
+			"level":       rule.Level,
+			"description": rule.Description,
+			"groups":      rule.Groups,
+			"firedtimes":  1,
+			"mail":        false,
+		}
+		alert = rule.Level >= 3
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"token":    token,
+			"messages": messages,
+			"output":   output,
+			"alert":    alert,
+			"codemsg":  1,
+		},
+		"error": 0,
+	})
+}
+
+func (s *simulator) endLogtestSession(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.sessions[token] {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Session not found", "error": 1})
+		return
+	}
+
+	delete(s.sessions, token)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Session removed", "error": 0})
+}
+
+// decodeEvent extracts the fields rule options are evaluated against
+func decodeEvent(line string, location string) map[string]string {
+	event := map[string]string{"full_log": line, "location": location}
+
+	if parts := syslogHeader.FindStringSubmatch(line); parts != nil {
+		event["timestamp"] = parts[1]
+		event["hostname"] = parts[2]
+		event["program_name"] = parts[3]
+	}
+	if parts := srcIPPattern.FindStringSubmatch(line); parts != nil {
+		event["srcip"] = parts[1]
+	}
+	if parts := userPattern.FindStringSubmatch(line); parts != nil {
+		event["srcuser"] = parts[1]
+		event["user"] = parts[1]
+	}
+
+	return event
+}
+
+// matchRule picks the first matching root rule and descends into its matching children,
+// the way analysisd walks the rule tree
+func matchRule(rules []simRule, event map[string]string) *simRule {
+	var matched *simRule
+	parent := 0
+
+	for {
+		var next *simRule
+		for i := range rules {
+			rule := &rules[i]
+			if rule.IfSid != parent || (parent == 0 && len(rule.Options) == 0) {
+				continue
+			}
+			if ruleMatches(rule, event) {
+				next = rule
+				break
+			}
+		}
+
+		if next == nil {
+			return matched
+		}
+		matched = next
+		parent = next.ID
+	}
+}
+
+func ruleMatches(rule *simRule, event map[string]string) bool {
+	for _, option := range rule.Options {
+		if optionMatches(option, event) == option.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+func optionMatches(option simOption, event map[string]string) bool {
+	var value string
+	switch option.Tag {
+	case "match":
+		value = event["full_log"]
+	case "field":
+		value = event[option.Name]
+	default:
+		value = event[option.Tag]
+	}
+
+	switch {
+	case option.Tag == "srcip" || option.Tag == "dstip":
+		expected := strings.TrimPrefix(option.Value, "!")
+		negated := strings.HasPrefix(option.Value, "!")
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return false
+		}
+		matches := expected == value
+		if _, network, err := net.ParseCIDR(expected); err == nil {
+			matches = network.Contains(ip)
+		}
+		return matches != negated
+	case option.Type == "pcre2":
+		pattern, err := regexp.Compile(option.Value)
+		return err == nil && pattern.MatchString(value)
+	default:
+		lower := strings.ToLower(value)
+		for _, alternative := range strings.Split(option.Value, "|") {
+			if alternative != "" && strings.Contains(lower, strings.ToLower(alternative)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// allRules returns the built-in rules plus every rule parsed from uploaded files. Callers hold the lock.
+func (s *simulator) allRules() []entity.WazuhRule {
+	var rules []entity.WazuhRule
+	for _, rule := range s.orderedRules() {
+		rules = append(rules, rule.WazuhRule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// orderedRules returns the rules in load order: built-in rules first, then uploaded files by name.
+// Callers hold the lock.
+func (s *simulator) orderedRules() []simRule {
+	rules := append([]simRule{}, s.baseRules...)
+
+	filenames := make([]string, 0, len(s.fileRules))
+	for filename := range s.fileRules {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		rules = append(rules, s.fileRules[filename]...)
+	}
+	return rules
+}
+
+type xmlRuleFileOption struct {
+	XMLName xml.Name
+	Name    string `xml:"name,attr"`
+	Type    string `xml:"type,attr"`
+	Negate  string `xml:"negate,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type xmlRuleFileRule struct {
+	ID          int                 `xml:"id,attr"`
+	Level       int                 `xml:"level,attr"`
+	IfSid       string              `xml:"if_sid"`
+	Description string              `xml:"description"`
+	Group       string              `xml:"group"`
+	Options     []xmlRuleFileOption `xml:",any"`
+}
+
+type xmlRuleFileGroup struct {
+	Name  string            `xml:"name,attr"`
+	Rules []xmlRuleFileRule `xml:"rule"`
+}
+
+// parseRuleFile checks the file is well-formed and extracts its rules.
+// Rule files have several top-level <group> elements, so they are wrapped before decoding.
+func parseRuleFile(filename string, content string) ([]simRule, error) {
+	var root struct {
+		Groups []xmlRuleFileGroup `xml:"group"`
+	}
+
+	if err := xml.Unmarshal([]byte("<root>"+content+"</root>"), &root); err != nil {
+		return nil, err
+	}
+
+	var rules []simRule
+	for _, group := range root.Groups {
+		for _, rule := range group.Rules {
+			parsed := simRule{
+				WazuhRule: entity.WazuhRule{
+					Filename:        filename,
+					RelativeDirname: "etc/rules",
+					ID:              rule.ID,
+					Level:           rule.Level,
+					Status:          "enabled",
+					Groups:          splitGroups(group.Name + "," + rule.Group),
+					Description:     strings.TrimSpace(rule.Description),
+				},
+			}
+
+			if rule.IfSid != "" {
+				// Only the first parent is evaluated, which covers the rules this service generates
+				first := strings.TrimSpace(strings.Split(rule.IfSid, ",")[0])
+				ifSid, err := strconv.Atoi(first)
+				if err != nil {
+					return nil, fmt.Errorf("rule %d: invalid if_sid %q", rule.ID, rule.IfSid)
+				}
+				parsed.IfSid = ifSid
+			}
+
+			for _, option := range rule.Options {
+				if option.Type == "pcre2" {
+					if _, err := regexp.Compile(option.Value); err != nil {
+						return nil, fmt.Errorf("rule %d: invalid pcre2 expression in <%s>: %v", rule.ID, option.XMLName.Local, err)
+					}
+				}
+				parsed.Options = append(parsed.Options, simOption{
+					Tag:    option.XMLName.Local,
+					Name:   option.Name,
+					Type:   option.Type,
+					Negate: option.Negate == "yes",
+					Value:  strings.TrimSpace(option.Value),
+				})
+			}
+
+			rules = append(rules, parsed)
+		}
+	}
+
+	return rules, nil
+}
+
+func splitGroups(value string) []string {
+	var groups []string
+	for _, group := range strings.Split(value, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func filterRules(rules []entity.WazuhRule, keep func(entity.WazuhRule) bool) []entity.WazuhRule {
+	var filtered []entity.WazuhRule
+	for _, rule := range rules {
+		if keep(rule) {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+func writeAffectedItems(w http.ResponseWriter, items interface{}, total int) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"affected_items":       items,
+			"total_affected_items": total,
+			"total_failed_items":   0,
+			"failed_items":         []interface{}{},
+		},
+		"message": "All selected items were returned",
+		"error":   0,
+	})
+}
+
+func failedItem(filename string, code int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"affected_items":     []interface{}{},
+			"total_failed_items": 1,
+			"failed_items":       []interface{}{map[string]interface{}{"error": map[string]interface{}{"code": code, "message": message}, "id": []string{filename}}},
+		},
+		"message": "Could not upload file",
+		"error":   1,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+)
+
+type AgentRepository interface {
+	FetchAgentsByGroup(ctx context.Context, group string) ([]entity.WazuhAgent, error)
+	FetchAgentByID(ctx context.Context, agentID string) (*entity.WazuhAgent, error)
+}
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type AnalyticsUsecase interface {
+	FetchRuleAnalytics(ctx context.Context, request *model.RuleAnalyticsRequest) (*entity.RuleAnalyticsReport, error)
+	FetchCountryAnalytics(ctx context.Context, request *model.CountryAnalyticsRequest) (*entity.CountryAnalyticsReport, error)
+	FetchMitreCoverage(ctx context.Context, request *model.MitreCoverageRequest) (*entity.MitreCoverageReport, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type AssetRepository interface {
+	SaveAsset(ctx context.Context, asset *entity.Asset) error
+	UpdateAsset(ctx context.Context, asset *entity.Asset) error
+	DeleteAsset(ctx context.Context, id int) error
+	FetchAssetByID(ctx context.Context, id int) (*entity.Asset, error)
+	FetchAssets(ctx context.Context) ([]*entity.Asset, error)
+	ReplaceFileAssets(ctx context.Context, assets []*entity.Asset) error
+}
+
+type AssetUsecase interface {
+	CreateAsset(ctx context.Context, request *model.AssetRequest) (*entity.Asset, error)
+	UpdateAsset(ctx context.Context, id int, request *model.AssetRequest) (*entity.Asset, error)
+	DeleteAsset(ctx context.Context, id int) error
+	FetchAssets(ctx context.Context, request *model.FetchAssetsRequest) ([]*entity.Asset, error)
+	FetchAssetByID(ctx context.Context, id int) (*entity.Asset, error)
+	MatchHost(ctx context.Context, agentID string, hostname string, ip string) (*entity.AssetMatch, error)
+	MatchAlert(ctx context.Context, source []byte) *entity.AssetMatch
+	EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit
+	ReloadInventory(ctx context.Context) (*entity.AssetInventoryReload, error)
+	RunScheduledReload(ctx context.Context) error
+}
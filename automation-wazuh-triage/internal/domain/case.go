@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type CaseRepository interface {
+	SaveCase(ctx context.Context, c *entity.Case) error
+	FetchCaseByID(ctx context.Context, id int) (*entity.Case, error)
+	FetchCases(ctx context.Context, status string, correlationKey string) ([]*entity.Case, error)
+	FetchActiveCaseByKey(ctx context.Context, correlationKey string, since time.Time) (*entity.Case, error)
+	UpdateCaseActivity(ctx context.Context, c *entity.Case) error
+	EscalateCase(ctx context.Context, id int, escalatedBy string, reason string, escalatedAt time.Time) error
+	CloseCase(ctx context.Context, id int, closedBy string, reason string, label string, closedAt time.Time) error
+	SaveCaseAlert(ctx context.Context, alert *entity.CaseAlert) (bool, error)
+	FetchCaseIDByEventID(ctx context.Context, eventID string) (int, error)
+	FetchCaseAlerts(ctx context.Context, caseID int) ([]*entity.CaseAlert, error)
+}
+
+type CaseUsecase interface {
+	FetchCorrelationConfig(ctx context.Context) (*entity.CorrelationConfig, error)
+	UpdateCorrelationConfig(ctx context.Context, request *model.UpdateCorrelationConfigRequest) (*entity.CorrelationConfig, error)
+	CorrelateAlerts(ctx context.Context, hits []*elastic.SearchHit) (*entity.CorrelationResult, error)
+	Correlate(ctx context.Context, window time.Duration) (*entity.CorrelationResult, error)
+	IngestAlerts(ctx context.Context, alerts []json.RawMessage) (*entity.CorrelationResult, error)
+	RunScheduledCorrelation(ctx context.Context) error
+	FetchCases(ctx context.Context, status string, correlationKey string) ([]*entity.Case, error)
+	FetchCaseByID(ctx context.Context, id int) (*entity.Case, []*entity.CaseAlert, error)
+	EscalateCase(ctx context.Context, id int, request *model.EscalateCaseRequest) (*entity.Case, error)
+	CloseCase(ctx context.Context, id int, request *model.CloseCaseRequest) (*entity.Case, error)
+}
@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type ComplianceUsecase interface {
+	FetchComplianceReport(ctx context.Context, request *model.ComplianceReportRequest) (*entity.ComplianceReport, error)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type CVERepository interface {
+	SaveCVEs(ctx context.Context, cves []*entity.CVE) error
+	ReplaceKEV(ctx context.Context, entries map[string]*entity.KEVEntry) error
+	ReplaceEPSS(ctx context.Context, scores map[string]*entity.EPSSScore) error
+	FetchCVEByID(ctx context.Context, id string) (*entity.CVE, error)
+	FetchCVEsByIDs(ctx context.Context, ids []string) (map[string]*entity.CVE, error)
+	SaveFeedImport(ctx context.Context, feedImport *entity.CVEFeedImport) error
+	FetchFeedImport(ctx context.Context, path string) (*entity.CVEFeedImport, error)
+	FetchFeedImports(ctx context.Context) ([]*entity.CVEFeedImport, error)
+}
+
+type CVEUsecase interface {
+	ImportFeeds(ctx context.Context) ([]*entity.CVEFeedImport, error)
+	FetchFeedImports(ctx context.Context) ([]*entity.CVEFeedImport, error)
+	FetchCVEByID(ctx context.Context, id string) (*entity.CVE, error)
+	EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit
+	FetchAgentVulnerabilities(ctx context.Context, request *model.VulnerabilityReportRequest) (*entity.VulnerabilityReport, error)
+	RunScheduledImport(ctx context.Context) error
+}
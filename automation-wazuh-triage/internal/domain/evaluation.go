@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+)
+
+type AutoCloseDecisionRepository interface {
+	SaveDecision(ctx context.Context, decision *entity.AutoCloseDecision) (bool, error)
+	FetchDecisionByID(ctx context.Context, id int) (*entity.AutoCloseDecision, error)
+	FetchDecisionByEventID(ctx context.Context, eventID string, mode string) (*entity.AutoCloseDecision, error)
+	FetchDecisionsSince(ctx context.Context, since time.Time, mode string) ([]*entity.AutoCloseDecision, error)
+	FetchUnlabeledDecisionSample(ctx context.Context, request *model.DecisionSampleRequest, since time.Time) ([]*entity.AutoCloseDecision, error)
+	FetchUndecidedFalsePositiveClosuresSince(ctx context.Context, since time.Time, mode string) ([]*entity.ClosedEvent, error)
+	UpdateDecisionLabel(ctx context.Context, id int, label string, labeledBy string, labeledAt time.Time) error
+}
+
+type EvaluationUsecase interface {
+	FetchEvaluation(ctx context.Context, window time.Duration, mode string) (*entity.EvaluationReport, error)
+	FetchDecisionSample(ctx context.Context, request *model.DecisionSampleRequest) ([]*entity.AutoCloseDecision, error)
+	LabelDecision(ctx context.Context, id int, request *model.LabelDecisionRequest) (*entity.AutoCloseDecision, error)
+}
@@ -4,28 +4,45 @@ import (
 	"automation-wazuh-triage/internal/entity"
 	"automation-wazuh-triage/internal/model"
 	"context"
+	"time"
 
 	"github.com/olivere/elastic/v7"
 )
 
 type WazuhEventRepository interface {
 	FetchSecurityEvents(ctx context.Context, filter *model.FetchEventsRequest) (searchResults []*elastic.SearchHit, err error)
+	FetchFingerprintGroups(ctx context.Context, filter *model.FetchEventsRequest, since time.Time, fields []string, scripts map[string]*elastic.Script, after map[string]interface{}, size int) ([]*elastic.AggregationBucketCompositeItem, map[string]interface{}, error)
+	FetchSecurityEventsSince(ctx context.Context, since time.Time, searchAfter []interface{}, limit int) ([]*elastic.SearchHit, error)
+	FetchVulnerabilityAlertsSince(ctx context.Context, since time.Time, agentID string, searchAfter []interface{}, limit int) ([]*elastic.SearchHit, error)
+	FetchComplianceAlerts(ctx context.Context, framework string, ruleIDs []string, from time.Time, to time.Time, limit int) ([]*elastic.SearchHit, error)
+	FetchAlertsSharingValues(ctx context.Context, fields []string, values []string, from time.Time, to time.Time, limit int) ([]*elastic.SearchHit, error)
+	FetchIndicatorAlerts(ctx context.Context, index string, indicators map[string][]string, from time.Time, to time.Time, searchAfter []interface{}, size int) ([]*elastic.SearchHit, error)
+	FetchAgentAlerts(ctx context.Context, agentID string, from time.Time, to time.Time, minLevel int, ruleID string, searchAfter []interface{}, size int) ([]*elastic.SearchHit, error)
 	FetchSecurityEventByID(ctx context.Context, eventID string) (event *entity.WazuhSecurityEvent, searchHit *elastic.SearchHit, err error)
+	CountEventsByField(ctx context.Context, field string, since time.Time) (map[string]int64, error)
+	CountEventsByFieldAfter(ctx context.Context, field string, since time.Time, after map[string]interface{}, size int) (map[string]int64, map[string]interface{}, error)
+	CountEventsByDay(ctx context.Context, since time.Time) (map[string]int64, error)
 }
 
 type ClosedEventRepository interface {
 	SaveClosedEvent(ctx context.Context, closedEvent *entity.ClosedEvent) error
 	FetchClosedEvents(ctx context.Context) ([]*entity.ClosedEvent, error)
+	FetchClosedEventsSince(ctx context.Context, since time.Time) ([]*entity.ClosedEvent, error)
 	FetchClosedEventByID(ctx context.Context, id string) (*entity.ClosedEvent, error)
 	FetchClosedEventByEventID(ctx context.Context, eventID string) (*entity.ClosedEvent, error)
 	UpdateClosedEventReason(ctx context.Context, id string, reason string) error
+	UpdateClosedEventLabel(ctx context.Context, id string, label string) error
+	DeleteClosedEvent(ctx context.Context, id string) error
+	CountAutoClosuresSince(ctx context.Context, ruleID string, since time.Time) (int, error)
 }
 
 type EventUsecase interface {
 	FetchEvents(ctx context.Context, filter *model.FetchEventsRequest) (searchResults []*elastic.SearchHit, err error)
 	FetchEventsWithAutoClose(ctx context.Context, filter *model.FetchEventsRequest) (searchResults []*elastic.SearchHit, err error)
-	AddEventToCloseEvent(ctx context.Context, eventID string, reason string) error
+	AddEventToCloseEvent(ctx context.Context, eventID string, request *model.CloseEventRequest) error
+	AcknowledgeEvent(ctx context.Context, eventID string, analyst string) (*entity.TriageAction, error)
 	FetchClosedEvents(ctx context.Context) ([]*entity.ClosedEvent, error)
 	FetchClosedEventDetailsByID(ctx context.Context, id string) (*entity.ClosedEvent, *entity.WazuhRule, []entity.WazuhRule, error)
 	UpdateClosedEventReason(ctx context.Context, id string, reason string) error
+	UpdateClosedEventLabel(ctx context.Context, id string, label string) error
 }
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+)
+
+type FingerprintUsecase interface {
+	FetchConfig(ctx context.Context) (*entity.FingerprintConfig, error)
+	UpdateConfig(ctx context.Context, request *model.UpdateFingerprintConfigRequest) (*entity.FingerprintConfig, error)
+	CollapseEvents(ctx context.Context, request *model.FetchEventsRequest) (*model.FingerprintGroupPage, error)
+	CloseByFingerprint(ctx context.Context, fingerprint string, window time.Duration, request *model.CloseEventRequest) (*entity.FingerprintCloseResult, error)
+}
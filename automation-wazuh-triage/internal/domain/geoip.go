@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type GeoIPRepository interface {
+	LookupIP(ctx context.Context, ip string) (*entity.GeoLocation, error)
+}
+
+type GeoIPUsecase interface {
+	EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit
+}
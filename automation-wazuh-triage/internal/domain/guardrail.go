@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+)
+
+type GuardrailTripRepository interface {
+	SaveGuardrailTrip(ctx context.Context, trip *entity.GuardrailTrip) error
+	FetchGuardrailTrips(ctx context.Context, since time.Time, guardrail string, ruleID string, limit int) ([]*entity.GuardrailTrip, error)
+	CountGuardrailTripsSince(ctx context.Context, since time.Time) (map[string]int, error)
+}
+
+type GuardrailUsecase interface {
+	CheckAutoClose(ctx context.Context, eventID string, rule *entity.WazuhSecurityEventRule, source []byte) (*entity.GuardrailTrip, error)
+	FetchStatus(ctx context.Context) (*entity.GuardrailStatus, error)
+	UpdateGuardrails(ctx context.Context, request *model.UpdateGuardrailsRequest) (*entity.AutoCloseGuardrails, error)
+	SetKillSwitch(ctx context.Context, request *model.KillSwitchRequest) (*entity.KillSwitch, error)
+	FetchTrips(ctx context.Context, request *model.FetchGuardrailTripsRequest) ([]*entity.GuardrailTrip, error)
+}
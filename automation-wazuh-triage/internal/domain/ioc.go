@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type IOCUsecase interface {
+	FetchStatus(ctx context.Context) (*entity.IOCStoreStatus, error)
+	ReloadFeeds(ctx context.Context) (*entity.IOCStoreStatus, error)
+	LookupValue(ctx context.Context, value string) ([]entity.IOCMatch, error)
+	MatchAlert(ctx context.Context, source []byte) []entity.IOCMatch
+	EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit
+	RunScheduledReload(ctx context.Context) error
+}
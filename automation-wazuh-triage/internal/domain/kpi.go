@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+	"time"
+)
+
+type KPIUsecase interface {
+	FetchKPIs(ctx context.Context, window time.Duration) (*entity.KPIReport, error)
+}
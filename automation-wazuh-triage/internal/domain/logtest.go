@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type LogtestRepository interface {
+	// RunLogtest replays a sample in the given session and returns the result with the session token to reuse
+	RunLogtest(ctx context.Context, token string, sample entity.LogtestSample) (result *entity.LogtestResult, sessionToken string, err error)
+	EndLogtestSession(ctx context.Context, token string) error
+}
+
+type LogtestUsecase interface {
+	TestLogs(ctx context.Context, request *model.LogtestRequest) ([]entity.LogtestResult, error)
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type MaintenanceRepository interface {
+	SaveMaintenanceWindow(ctx context.Context, window *entity.MaintenanceWindow) error
+	UpdateMaintenanceWindow(ctx context.Context, window *entity.MaintenanceWindow) error
+	FetchMaintenanceWindowByID(ctx context.Context, id int) (*entity.MaintenanceWindow, error)
+	FetchMaintenanceWindows(ctx context.Context, enabledOnly bool) ([]*entity.MaintenanceWindow, error)
+	SaveMaintenanceAlert(ctx context.Context, alert *entity.MaintenanceAlert) (bool, error)
+	FetchMaintenanceAlertByEventID(ctx context.Context, eventID string) (*entity.MaintenanceAlert, error)
+	FetchMaintenanceAlerts(ctx context.Context, windowID int, limit int) ([]*entity.MaintenanceAlert, error)
+}
+
+type MaintenanceUsecase interface {
+	CreateWindow(ctx context.Context, request *model.MaintenanceWindowRequest) (*entity.MaintenanceWindow, error)
+	UpdateWindow(ctx context.Context, id int, request *model.MaintenanceWindowRequest) (*entity.MaintenanceWindow, error)
+	FetchWindows(ctx context.Context, activeOnly bool) ([]*entity.MaintenanceWindow, error)
+	FetchWindowByID(ctx context.Context, id int, limit int) (*entity.MaintenanceWindow, []*entity.MaintenanceAlert, error)
+	ApplyMaintenance(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, int, error)
+	FetchAgentOccurrences(ctx context.Context, agentID string, agentName string, from time.Time, to time.Time) ([]entity.MaintenanceOccurrence, error)
+}
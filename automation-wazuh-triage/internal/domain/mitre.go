@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type MitreUsecase interface {
+	FetchStatus(ctx context.Context) (*entity.MitreMatrixStatus, error)
+	ReloadMatrix(ctx context.Context) (*entity.MitreMatrixStatus, error)
+	FetchMatrix(ctx context.Context) (*entity.MitreMatrix, error)
+	FetchTechniqueByID(ctx context.Context, id string) (*entity.MitreTechnique, error)
+	ResolveTechniques(ctx context.Context, ids []string) []entity.MitreTechnique
+	EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit
+	RunScheduledReload(ctx context.Context) error
+}
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type ObservableUsecase interface {
+	ExtractRawEventObservables(rawEvent string) []entity.Observable
+	FetchEventObservables(ctx context.Context, eventID string) (*entity.EventObservables, error)
+	FetchObservables(ctx context.Context, request *model.ObservablesRequest) (*entity.ObservableReport, error)
+}
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type PivotUsecase interface {
+	ExtractRawEventPivots(rawEvent string) []entity.PivotValue
+	FetchRelatedAlerts(ctx context.Context, eventID string, request *model.RelatedAlertsRequest) (*entity.RelatedAlerts, error)
+}
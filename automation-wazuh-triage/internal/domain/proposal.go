@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type ProposalRepository interface {
+	SaveProposal(ctx context.Context, proposal *entity.Proposal) error
+	FetchProposals(ctx context.Context, status string) ([]*entity.Proposal, error)
+	FetchProposalByID(ctx context.Context, id int) (*entity.Proposal, error)
+	UpdateProposal(ctx context.Context, proposal *entity.Proposal, fromStatus string) error
+}
+
+type ProposalUsecase interface {
+	CreateProposal(ctx context.Context, request *model.CreateProposalRequest) (*entity.Proposal, error)
+	FetchProposals(ctx context.Context, status string) ([]*entity.Proposal, error)
+	FetchProposalDetails(ctx context.Context, id int) (*entity.Proposal, *entity.Suppression, []*entity.ClosedEvent, error)
+	SubmitProposal(ctx context.Context, id int, actor string) (*entity.Proposal, error)
+	ApproveProposal(ctx context.Context, id int, actor string, comment string) (*entity.Proposal, error)
+	RejectProposal(ctx context.Context, id int, actor string, comment string) (*entity.Proposal, error)
+	DeployProposal(ctx context.Context, id int, actor string) (*entity.Proposal, error)
+	RollbackProposal(ctx context.Context, id int, actor string, comment string) (*entity.Proposal, error)
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+)
+
+type SettingRepository interface {
+	FetchSetting(ctx context.Context, key string) (*entity.Setting, error)
+	SaveSetting(ctx context.Context, setting *entity.Setting) error
+}
+
+type QAReviewRepository interface {
+	SaveQAReview(ctx context.Context, review *entity.QAReview) (bool, error)
+	FetchQAReviewByID(ctx context.Context, id int) (*entity.QAReview, error)
+	FetchQAReviews(ctx context.Context, status string, ruleID string) ([]*entity.QAReview, error)
+	CountQAReviewsByRule(ctx context.Context, sampleDay string) (map[string]int, error)
+	FetchUnsampledAutoClosures(ctx context.Context, from time.Time, to time.Time) ([]*entity.ClosedEvent, error)
+	ResolveQAReview(ctx context.Context, id int, status string, reviewer string, note string, reviewedAt time.Time) error
+}
+
+type QAUsecase interface {
+	FetchSamplingPolicy(ctx context.Context) (*entity.QASamplingPolicy, error)
+	UpdateSamplingPolicy(ctx context.Context, request *model.UpdateQASamplingPolicyRequest) (*entity.QASamplingPolicy, error)
+	SampleDay(ctx context.Context, day time.Time) ([]*entity.QAReview, error)
+	RunScheduledSampling(ctx context.Context) error
+	FetchQAReviews(ctx context.Context, status string, ruleID string) ([]*entity.QAReview, error)
+	FetchQAReviewByID(ctx context.Context, id int) (*entity.QAReview, error)
+	ConfirmQAReview(ctx context.Context, id int, request *model.QAReviewActionRequest) (*entity.QAReview, error)
+	OverturnQAReview(ctx context.Context, id int, request *model.QAReviewActionRequest) (*entity.QAReview, error)
+	FetchCriterionReports(ctx context.Context) ([]*entity.QACriterionReport, error)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+)
+
+type RetroHuntRepository interface {
+	SaveRetroHunt(ctx context.Context, hunt *entity.RetroHunt) error
+	StartRetroHunt(ctx context.Context, id int, startedAt time.Time) error
+	UpdateRetroHuntSearched(ctx context.Context, id int, searched int) error
+	FinishRetroHunt(ctx context.Context, id int, status string, errMessage string, truncated bool, finishedAt time.Time) error
+	FetchRetroHunts(ctx context.Context) ([]*entity.RetroHunt, error)
+	FetchRetroHuntByID(ctx context.Context, id int) (*entity.RetroHunt, error)
+	SaveRetroHuntMatch(ctx context.Context, match *entity.RetroHuntMatch, documentID string) (bool, error)
+	FetchRetroHuntMatches(ctx context.Context, huntID int) ([]*entity.RetroHuntMatch, error)
+}
+
+type RetroHuntUsecase interface {
+	CreateRetroHunt(ctx context.Context, request *model.CreateRetroHuntRequest) (*entity.RetroHunt, error)
+	FetchRetroHunts(ctx context.Context) ([]*entity.RetroHunt, error)
+	FetchRetroHuntByID(ctx context.Context, id int) (*entity.RetroHunt, error)
+	FetchRetroHuntMatches(ctx context.Context, id int, filter *model.RetroHuntMatchFilter) ([]*entity.RetroHuntMatch, error)
+}
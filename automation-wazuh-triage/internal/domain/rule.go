@@ -13,4 +13,5 @@ type RuleUsecase interface {
 type RuleRepository interface {
 	GetDetailRules(ctx context.Context, ruleID string) (*entity.WazuhRule, error)
 	GetListRulesByFiles(ctx context.Context, filename string) ([]entity.WazuhRule, error)
+	GetAllRules(ctx context.Context) ([]entity.WazuhRule, error)
 }
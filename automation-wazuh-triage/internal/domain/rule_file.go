@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+)
+
+type RuleFileRepository interface {
+	GetRuleFile(ctx context.Context, filename string) (content string, exists bool, err error)
+	UpdateRuleFile(ctx context.Context, filename string, content string) error
+	DeleteRuleFile(ctx context.Context, filename string) error
+	ValidateConfiguration(ctx context.Context) error
+	RestartManager(ctx context.Context) error
+}
+
+type RuleFileVersionRepository interface {
+	SaveVersion(ctx context.Context, version *entity.RuleFileVersion) error
+	FetchVersions(ctx context.Context, filename string) ([]*entity.RuleFileVersion, error)
+}
+
+type RuleFileUsecase interface {
+	PushRuleFile(ctx context.Context, filename string, content string, reason string) (*entity.RuleFileVersion, error)
+	FetchRuleFileVersions(ctx context.Context, filename string) ([]*entity.RuleFileVersion, error)
+}
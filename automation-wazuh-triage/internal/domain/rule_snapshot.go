@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+)
+
+type RuleSnapshotRepository interface {
+	SaveSnapshot(ctx context.Context, snapshot *entity.RuleSnapshot, items []entity.RuleSnapshotItem) error
+	FetchSnapshots(ctx context.Context) ([]*entity.RuleSnapshot, error)
+	FetchSnapshotByID(ctx context.Context, id int) (*entity.RuleSnapshot, error)
+	FetchLatestSnapshot(ctx context.Context) (*entity.RuleSnapshot, error)
+	FetchSnapshotItems(ctx context.Context, snapshotID int) ([]entity.RuleSnapshotItem, error)
+}
+
+type RuleSnapshotUsecase interface {
+	CreateSnapshot(ctx context.Context, trigger string) (*entity.RuleSnapshot, error)
+	FetchSnapshots(ctx context.Context) ([]*entity.RuleSnapshot, error)
+	DiffSnapshots(ctx context.Context, fromID int, toID int) (*entity.RuleSnapshotDiff, error)
+	RunScheduledSnapshot(ctx context.Context) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type SequenceFindingRepository interface {
+	SaveSequenceFinding(ctx context.Context, finding *entity.SequenceFinding) error
+	FetchSequenceFindingByID(ctx context.Context, id int) (*entity.SequenceFinding, error)
+	FetchSequenceFindings(ctx context.Context, since time.Time, ruleName string, limit int) ([]*entity.SequenceFinding, error)
+}
+
+type SequenceUsecase interface {
+	FetchRules(ctx context.Context) (*entity.SequenceRules, error)
+	UpdateRules(ctx context.Context, request *model.UpdateSequenceRulesRequest) (*entity.SequenceRules, error)
+	EvaluateAlerts(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, error)
+	FetchFindings(ctx context.Context, request *model.FetchSequenceFindingsRequest) ([]*entity.SequenceFinding, error)
+	FetchFindingByID(ctx context.Context, id int) (*entity.SequenceFinding, error)
+}
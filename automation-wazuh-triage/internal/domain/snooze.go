@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type SnoozeRepository interface {
+	SaveSnooze(ctx context.Context, snooze *entity.Snooze) error
+	FetchSnoozeByID(ctx context.Context, id int) (*entity.Snooze, error)
+	FetchSnoozes(ctx context.Context, status string, now time.Time) ([]*entity.Snooze, error)
+	FetchUnendedSnoozes(ctx context.Context) ([]*entity.Snooze, error)
+	EndSnooze(ctx context.Context, id int, status string, endedBy string, endedAt time.Time, summary *entity.SnoozeSummary) error
+	SaveSnoozedAlert(ctx context.Context, alert *entity.SnoozedAlert) (bool, error)
+	FetchSnoozedAlerts(ctx context.Context, snoozeID int) ([]*entity.SnoozedAlert, error)
+}
+
+type SnoozeUsecase interface {
+	CreateSnooze(ctx context.Context, request *model.CreateSnoozeRequest) (*entity.Snooze, error)
+	FetchSnoozes(ctx context.Context, status string) ([]*entity.Snooze, error)
+	FetchSnoozeByID(ctx context.Context, id int) (*entity.Snooze, []*entity.SnoozedAlert, error)
+	CancelSnooze(ctx context.Context, id int, request *model.CancelSnoozeRequest) (*entity.Snooze, error)
+	ApplySnoozes(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, int, error)
+	RunScheduledSummary(ctx context.Context) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+)
+
+type SuppressionRepository interface {
+	SaveSuppression(ctx context.Context, suppression *entity.Suppression) error
+	FetchSuppressions(ctx context.Context) ([]*entity.Suppression, error)
+	FetchSuppressionsByStatus(ctx context.Context, status string) ([]*entity.Suppression, error)
+	FetchSuppressionByID(ctx context.Context, id int) (*entity.Suppression, error)
+	UpdateSuppression(ctx context.Context, suppression *entity.Suppression) error
+}
+
+type SuppressionUsecase interface {
+	CreateSuppression(ctx context.Context, suppression *entity.Suppression) (*entity.Suppression, error)
+	FetchSuppressions(ctx context.Context) ([]*entity.Suppression, error)
+	FetchSuppressionByID(ctx context.Context, id int) (*entity.Suppression, error)
+	PreviewSuppressionXML(ctx context.Context, id int) (string, error)
+	PreviewRuleFile(ctx context.Context, id int) (current string, proposed string, err error)
+	DeploySuppression(ctx context.Context, id int) (*entity.Suppression, error)
+	RetractSuppression(ctx context.Context, id int) (*entity.Suppression, error)
+}
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+)
+
+type SuppressionMinerUsecase interface {
+	// MineSuppressions groups analyst closures and, unless dryRun is set, files a proposal for every new candidate
+	MineSuppressions(ctx context.Context, dryRun bool) ([]entity.SuppressionCandidate, error)
+	RunScheduledMining(ctx context.Context) error
+}
@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+)
+
+type TimelineUsecase interface {
+	FetchAgentTimeline(ctx context.Context, agentID string, request *model.AgentTimelineRequest) (*entity.AgentTimeline, error)
+}
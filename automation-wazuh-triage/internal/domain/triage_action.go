@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+	"time"
+)
+
+type TriageActionRepository interface {
+	SaveTriageAction(ctx context.Context, action *entity.TriageAction) error
+	FetchTriageActionsByEventID(ctx context.Context, eventID string) ([]*entity.TriageAction, error)
+	FetchTriageActionsForClosuresSince(ctx context.Context, since time.Time) ([]*entity.TriageAction, error)
+	FetchTriageActionsForAlertsSince(ctx context.Context, since time.Time) ([]*entity.TriageAction, error)
+	FetchTriageActionsByAgent(ctx context.Context, agentID string, agentName string, ruleID string, from time.Time, to time.Time, afterID int, limit int) ([]*entity.TriageAction, error)
+}
@@ -0,0 +1,19 @@
+package entity
+
+// WazuhAgent is an agent as listed by the Wazuh API
+type WazuhAgent struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	IP     string   `json:"ip"`
+	Groups []string `json:"group"`
+}
+
+// WazuhAgentsAPIResponse represents the Wazuh API response listing agents
+type WazuhAgentsAPIResponse struct {
+	Data struct {
+		AffectedItems      []WazuhAgent `json:"affected_items"`
+		TotalAffectedItems int          `json:"total_affected_items"`
+	} `json:"data"`
+	Message string `json:"message"`
+	Error   int    `json:"error"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// RuleAnalytics summarises how much triage work a single Wazuh rule generated within a window
+type RuleAnalytics struct {
+	RuleID                   string   `json:"rule_id"`
+	Level                    int      `json:"level"`
+	Description              string   `json:"description"`
+	Groups                   []string `json:"groups"`
+	Filename                 string   `json:"filename"`
+	Firings                  int64    `json:"firings"`  // alerts in the indexer
+	Closures                 int      `json:"closures"` // rows in closed_events
+	AutoClosed               int      `json:"auto_closed"`
+	ManualClosed             int      `json:"manual_closed"`
+	FalsePositives           int      `json:"false_positives"`
+	TruePositives            int      `json:"true_positives"`
+	Unlabeled                int      `json:"unlabeled"`
+	MedianTimeToCloseSeconds *float64 `json:"median_time_to_close_seconds"` // nil when no closure has a parsable alert timestamp
+	NoiseScore               float64  `json:"noise_score"`
+}
+
+// RuleAnalyticsReport is the ranked list of rules returned by the analytics endpoint
+type RuleAnalyticsReport struct {
+	Window      string          `json:"window"`
+	Since       time.Time       `json:"since"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Rules       []RuleAnalytics `json:"rules"`
+}
+
+// CountryAnalytics counts the alerts whose source address is located in one country
+type CountryAnalytics struct {
+	CountryISOCode string `json:"country_iso_code"`
+	CountryName    string `json:"country_name"`
+	Events         int64  `json:"events"`
+	SourceIPs      int    `json:"source_ips"`
+}
+
+// CountryAnalyticsReport is the breakdown of alerts by source country, busiest first
+type CountryAnalyticsReport struct {
+	Window      string             `json:"window"`
+	Since       time.Time          `json:"since"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	SourceIPs   int                `json:"source_ips"`       // distinct source addresses counted
+	Unlocated   int64              `json:"unlocated_events"` // private addresses and addresses the database does not know
+	Truncated   bool               `json:"truncated"`        // the window had more source addresses than a report reads
+	Countries   []CountryAnalytics `json:"countries"`
+}
@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+// Business criticality of an asset, lowest first
+const (
+	AssetCriticalityLow      = "low"
+	AssetCriticalityMedium   = "medium"
+	AssetCriticalityHigh     = "high"
+	AssetCriticalityCritical = "critical"
+)
+
+// Where an asset comes from. File assets are replaced on every inventory reload; API assets are kept.
+const (
+	AssetSourceFile = "file"
+	AssetSourceAPI  = "api"
+)
+
+// How an event was matched to an asset, in order of precedence
+const (
+	AssetMatchAgentID  = "agent_id"
+	AssetMatchHostname = "hostname"
+	AssetMatchIP       = "ip"
+)
+
+// Asset is a host of the inventory. It is matched to events by agent ID, hostname or an address or CIDR,
+// whichever of them are set.
+type Asset struct {
+	ID          int       `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	AgentID     string    `json:"agent_id,omitempty" db:"agent_id"`
+	Hostname    string    `json:"hostname,omitempty" db:"hostname"`
+	IP          string    `json:"ip,omitempty" db:"ip"` // address or CIDR such as 10.0.4.0/24
+	Owner       string    `json:"owner" db:"owner"`
+	Criticality string    `json:"criticality" db:"criticality"` // low, medium, high or critical
+	Environment string    `json:"environment" db:"environment"` // e.g. production, staging
+	Tags        []string  `json:"tags" db:"tags"`
+	Source      string    `json:"source" db:"source"` // file or api
+	UpdatedBy   string    `json:"updated_by" db:"updated_by"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AssetMatch is the asset an event belongs to and the event field that matched it
+type AssetMatch struct {
+	*Asset
+	MatchedBy string `json:"matched_by"` // agent_id, hostname or ip
+}
+
+// AssetInventoryReload is the outcome of reading the inventory file
+type AssetInventoryReload struct {
+	Path       string    `json:"path"`
+	Loaded     int       `json:"loaded"`
+	Changed    bool      `json:"changed"` // false when the file was unchanged since the last reload and skipped
+	ReloadedAt time.Time `json:"reloaded_at"`
+}
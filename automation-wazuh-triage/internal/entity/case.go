@@ -0,0 +1,95 @@
+package entity
+
+import "time"
+
+const (
+	CaseStatusOpen      = "open"
+	CaseStatusEscalated = "escalated"
+	CaseStatusClosed    = "closed"
+)
+
+// Alert fields alerts can be correlated on
+const (
+	CorrelationKeySrcIP      = "srcip"
+	CorrelationKeyAgentID    = "agent.id"
+	CorrelationKeyRuleGroups = "rule.groups"
+	CorrelationKeyUser       = "user"
+)
+
+const (
+	// SettingKeyCorrelationConfig is the settings key holding the CorrelationConfig
+	SettingKeyCorrelationConfig = "correlation_config"
+
+	// SettingKeyCorrelationCursor is the settings key holding the CorrelationCursor
+	SettingKeyCorrelationCursor = "correlation_cursor"
+)
+
+// CorrelationConfig groups alerts sharing the values of all Keys into one case, as long as each alert
+// fires within Window of the case's last alert
+type CorrelationConfig struct {
+	Keys      []string   `json:"keys"`
+	Window    string     `json:"window"` // duration such as 30m
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // nil while the defaults are in use
+}
+
+// CorrelationCursor is the position, by timestamp then alert id, of the newest alert the scheduled correlator
+// processed
+type CorrelationCursor struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        string    `json:"id,omitempty"`
+}
+
+// Case groups correlated alerts so they are triaged together
+type Case struct {
+	ID             int               `json:"id" db:"id"`
+	CorrelationKey string            `json:"correlation_key" db:"correlation_key"` // e.g. agent.id=001,srcip=10.0.0.5
+	KeyValues      map[string]string `json:"key_values" db:"key_values"`
+	Title          string            `json:"title" db:"title"`
+	Status         string            `json:"status" db:"status"`
+	MaxLevel       int               `json:"max_level" db:"max_level"`
+	AlertCount     int               `json:"alert_count" db:"alert_count"`
+	RuleIDs        []string          `json:"rule_ids"`
+	LowPriority    bool              `json:"low_priority" db:"low_priority"`   // opened by alerts lowered by a maintenance window
+	HighPriority   bool              `json:"high_priority" db:"high_priority"` // holds an alert matching a threat-intel indicator
+	FirstSeen      time.Time         `json:"first_seen" db:"first_seen"`
+	LastSeen       time.Time         `json:"last_seen" db:"last_seen"`
+	EscalatedBy    string            `json:"escalated_by,omitempty" db:"escalated_by"`
+	EscalateReason string            `json:"escalate_reason,omitempty" db:"escalate_reason"`
+	EscalatedAt    *time.Time        `json:"escalated_at,omitempty" db:"escalated_at"`
+	ClosedBy       string            `json:"closed_by,omitempty" db:"closed_by"`
+	CloseReason    string            `json:"close_reason,omitempty" db:"close_reason"`
+	Label          string            `json:"label,omitempty" db:"label"` // label copied to every alert closed with the case
+	ClosedAt       *time.Time        `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CaseAlert is one alert of a case. It keeps a copy of the alert so the case can be closed without
+// querying the indexer again.
+type CaseAlert struct {
+	ID        int       `json:"id" db:"id"`
+	CaseID    int       `json:"case_id" db:"case_id"`
+	EventID   string    `json:"event_id" db:"event_id"`
+	RuleID    string    `json:"rule_id" db:"rule_id"`
+	RuleLevel int       `json:"rule_level" db:"rule_level"`
+	AgentName string    `json:"agent_name" db:"agent_name"`
+	RawEvent  string    `json:"-" db:"raw_event"`
+	AlertAt   time.Time `json:"alert_at" db:"alert_at"`
+	AddedAt   time.Time `json:"added_at" db:"added_at"`
+}
+
+// CorrelationResult summarises one correlation run
+type CorrelationResult struct {
+	Since        time.Time `json:"since"`
+	Alerts       int       `json:"alerts"`        // alerts read from the indexer or received by the webhook
+	Maintenance  int       `json:"maintenance"`   // alerts that fired during a maintenance window
+	Snoozed      int       `json:"snoozed"`       // alerts closed by an active snooze, left out of cases
+	IOCMatches   int       `json:"ioc_matches"`   // alerts matching a threat-intel indicator
+	Findings     int       `json:"findings"`      // sequence findings raised by the alerts
+	Correlated   int       `json:"correlated"`    // alerts and findings added to a case
+	Uncorrelated int       `json:"uncorrelated"`  // alerts missing a correlation key
+	Duplicates   int       `json:"duplicates"`    // alerts already in a case
+	CasesCreated int       `json:"cases_created"` // new cases
+	CasesUpdated int       `json:"cases_updated"` // existing cases that gained alerts
+}
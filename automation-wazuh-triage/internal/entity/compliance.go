@@ -0,0 +1,89 @@
+package entity
+
+import "time"
+
+// Compliance frameworks, named after the rule fields that tag them
+const (
+	ComplianceFrameworkPCIDSS    = "pci_dss"
+	ComplianceFrameworkGDPR      = "gdpr"
+	ComplianceFrameworkHIPAA     = "hipaa"
+	ComplianceFrameworkNIST80053 = "nist_800_53"
+	ComplianceFrameworkTSC       = "tsc"
+)
+
+// Triage outcomes of an alert in a compliance report
+const (
+	ComplianceOutcomeOpen         = "open"
+	ComplianceOutcomeAcknowledged = "acknowledged" // picked up by an analyst but not closed
+	ComplianceOutcomeAutoClosed   = "auto_closed"  // closed by auto-close, a snooze or a maintenance window
+	ComplianceOutcomeClosed       = "closed"       // closed by an analyst
+)
+
+// Tags returns the requirements the rule is tagged with in the framework
+func (r *WazuhRule) Tags(framework string) []string {
+	switch framework {
+	case ComplianceFrameworkPCIDSS:
+		return r.PciDss
+	case ComplianceFrameworkGDPR:
+		return r.Gdpr
+	case ComplianceFrameworkHIPAA:
+		return r.Hipaa
+	case ComplianceFrameworkNIST80053:
+		return r.Nist80053
+	case ComplianceFrameworkTSC:
+		return r.Tsc
+	default:
+		return nil
+	}
+}
+
+// ComplianceAlert is an alert of a requirement and how it was triaged
+type ComplianceAlert struct {
+	EventID        string     `json:"event_id"`
+	FiredAt        time.Time  `json:"fired_at"`
+	RuleID         string     `json:"rule_id"`
+	Level          int        `json:"level"`
+	Description    string     `json:"description"`
+	AgentID        string     `json:"agent_id"`
+	AgentName      string     `json:"agent_name"`
+	Outcome        string     `json:"outcome"` // open, acknowledged, auto_closed or closed
+	Label          string     `json:"label,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	ClosedBy       string     `json:"closed_by,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+}
+
+// ComplianceReason counts the closures of a requirement's alerts with one reason
+type ComplianceReason struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// ComplianceRequirement is a requirement of the framework with the rules tagged with it and their alerts
+type ComplianceRequirement struct {
+	Requirement    string             `json:"requirement"` // e.g. 10.2.4 for PCI DSS
+	Rules          []int              `json:"rules"`
+	AlertCount     int                `json:"alert_count"`
+	Open           int                `json:"open"`
+	Acknowledged   int                `json:"acknowledged"`
+	AutoClosed     int                `json:"auto_closed"`
+	Closed         int                `json:"closed"`
+	FalsePositives int                `json:"false_positives"`
+	TruePositives  int                `json:"true_positives"`
+	Reasons        []ComplianceReason `json:"reasons"`    // most frequent first
+	OpenItems      []ComplianceAlert  `json:"open_items"` // open and acknowledged alerts, oldest first
+	Alerts         []ComplianceAlert  `json:"alerts"`     // newest first
+}
+
+// ComplianceReport lists the requirements of a framework with the alerts that fired in the period
+type ComplianceReport struct {
+	Framework    string                  `json:"framework"`
+	From         time.Time               `json:"from"`
+	To           time.Time               `json:"to"`
+	GeneratedAt  time.Time               `json:"generated_at"`
+	Rules        int                     `json:"rules"`     // rules tagged with the framework
+	Alerts       int                     `json:"alerts"`    // alerts of the period tagged with the framework
+	Truncated    bool                    `json:"truncated"` // the period had more alerts than a report reads
+	Requirements []ComplianceRequirement `json:"requirements"`
+}
@@ -0,0 +1,108 @@
+package entity
+
+import "time"
+
+// Kinds of vulnerability feed file
+const (
+	CVEFeedNVD  = "nvd"  // NVD CVE API 2.0 JSON
+	CVEFeedKEV  = "kev"  // CISA Known Exploited Vulnerabilities catalog JSON
+	CVEFeedEPSS = "epss" // FIRST EPSS scores CSV
+)
+
+// CVE is a vulnerability of the local NVD feed with its KEV listing and EPSS score, when known
+type CVE struct {
+	ID           string         `json:"cve_id" db:"cve_id"`
+	Description  string         `json:"description" db:"description"`
+	Published    *time.Time     `json:"published,omitempty" db:"published"`
+	LastModified *time.Time     `json:"last_modified,omitempty" db:"last_modified"`
+	CVSS         *CVSSMetric    `json:"cvss,omitempty"`                 // the newest CVSS version, scored by NVD when it did
+	CVSSMetrics  []CVSSMetric   `json:"cvss_metrics" db:"cvss_metrics"` // every score NVD lists
+	CWEs         []string       `json:"cwes" db:"cwes"`
+	References   []CVEReference `json:"references" db:"refs"`
+	EPSS         *EPSSScore     `json:"epss,omitempty" db:"epss"`
+	KEV          *KEVEntry      `json:"kev,omitempty" db:"kev"` // set while the CVE is in the KEV catalog
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// CVSSMetric is one CVSS score of a CVE
+type CVSSMetric struct {
+	Version   string  `json:"version"` // 2.0, 3.0, 3.1 or 4.0
+	Vector    string  `json:"vector"`
+	BaseScore float64 `json:"base_score"`
+	Severity  string  `json:"severity"`
+	Source    string  `json:"source"`
+	Type      string  `json:"type"` // Primary or Secondary
+}
+
+type CVEReference struct {
+	URL    string   `json:"url"`
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// EPSSScore is the probability of exploitation in the next 30 days and its percentile among all CVEs
+type EPSSScore struct {
+	Score      float64 `json:"score"`
+	Percentile float64 `json:"percentile"`
+	Date       string  `json:"date,omitempty"` // score date of the EPSS file
+}
+
+// KEVEntry is the CISA KEV catalog entry of a CVE
+type KEVEntry struct {
+	VendorProject  string `json:"vendor_project"`
+	Product        string `json:"product"`
+	Name           string `json:"name"`
+	DateAdded      string `json:"date_added"`
+	DueDate        string `json:"due_date"`
+	RequiredAction string `json:"required_action"`
+	Ransomware     string `json:"known_ransomware_campaign_use"` // Known or Unknown
+}
+
+// CVEFeedImport is the outcome of the last import of a feed file
+type CVEFeedImport struct {
+	Path        string     `json:"path" db:"path"`
+	Kind        string     `json:"kind" db:"kind"` // nvd, kev or epss
+	Records     int        `json:"records" db:"records"`
+	FileModTime *time.Time `json:"file_mod_time,omitempty" db:"file_mod_time"`
+	ImportedAt  *time.Time `json:"imported_at,omitempty" db:"imported_at"`
+	Error       string     `json:"error,omitempty" db:"error"` // why the last import failed; the previous data stays
+	Changed     bool       `json:"changed"`                    // false when the file was unchanged and skipped
+}
+
+// AgentVulnerability is a CVE an agent was last reported vulnerable to
+type AgentVulnerability struct {
+	CVE            string     `json:"cve"`
+	Package        string     `json:"package,omitempty"`
+	PackageVersion string     `json:"package_version,omitempty"`
+	CVSSScore      float64    `json:"cvss_score"`
+	CVSSVector     string     `json:"cvss_vector,omitempty"`
+	Severity       string     `json:"severity"`
+	KEV            bool       `json:"kev"`
+	EPSS           *EPSSScore `json:"epss,omitempty"`
+	Known          bool       `json:"known"` // false when the CVE is not in the local NVD feed
+	LastSeen       time.Time  `json:"last_seen"`
+}
+
+// AgentVulnerabilitySummary counts the open vulnerabilities of an agent
+type AgentVulnerabilitySummary struct {
+	AgentID         string               `json:"agent_id"`
+	AgentName       string               `json:"agent_name"`
+	CVEs            int                  `json:"cves"`
+	KEV             int                  `json:"kev"`
+	Critical        int                  `json:"critical"`
+	High            int                  `json:"high"`
+	Medium          int                  `json:"medium"`
+	Low             int                  `json:"low"`
+	MaxCVSS         float64              `json:"max_cvss"`
+	Vulnerabilities []AgentVulnerability `json:"vulnerabilities"` // KEV first, then by CVSS
+}
+
+// VulnerabilityReport ranks agents by their KEV vulnerabilities, then by their highest CVSS score
+type VulnerabilityReport struct {
+	Window      string                      `json:"window"`
+	Since       time.Time                   `json:"since"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+	Alerts      int                         `json:"alerts"`    // vulnerability alerts read from the indexer
+	Truncated   bool                        `json:"truncated"` // the window had more alerts than a report reads
+	Agents      []AgentVulnerabilitySummary `json:"agents"`
+}
@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+const (
+	// AutoCloseModeEnforce closes the matched events
+	AutoCloseModeEnforce = "enforce"
+
+	// AutoCloseModeShadow only records what auto-close would have closed
+	AutoCloseModeShadow = "shadow"
+)
+
+// AutoCloseDecision records one event auto-close closed, or would have closed in shadow mode
+type AutoCloseDecision struct {
+	ID              int        `json:"id" db:"id"`
+	EventID         string     `json:"event_id" db:"event_id"`
+	RuleID          string     `json:"rule_id" db:"rule_id"`
+	RuleLevel       int        `json:"rule_level" db:"rule_level"`
+	Criterion       string     `json:"criterion" db:"criterion"`               // canonical form of the matching filter, e.g. level<=3
+	CriterionFilter string     `json:"criterion_filter" db:"criterion_filter"` // the filter as JSON, used to replay it against other events
+	Mode            string     `json:"mode" db:"mode"`                         // enforce or shadow
+	ClosedEventID   int        `json:"closed_event_id" db:"closed_event_id"`   // 0 in shadow mode
+	RawEvent        string     `json:"-" db:"raw_event"`
+	Label           string     `json:"label" db:"label"` // analyst verdict on the alert: false_positive means closing was right
+	LabeledBy       string     `json:"labeled_by" db:"labeled_by"`
+	LabeledAt       *time.Time `json:"labeled_at" db:"labeled_at"`
+	DecidedAt       time.Time  `json:"decided_at" db:"decided_at"`
+}
+
+// EvaluationMetrics is the confusion matrix of auto-close decisions for one criterion, rule or the whole window.
+// The positive class is "safe to close": a closed alert labeled false_positive is a correct closure, one labeled
+// true_positive is an attack that automation closed, and an analyst closure labeled false_positive that automation
+// left open is a missed closure.
+type EvaluationMetrics struct {
+	Key             string   `json:"key"`
+	Decisions       int      `json:"decisions"`
+	Labeled         int      `json:"labeled"`
+	CorrectClosures int      `json:"correct_closures"` // true positives of the classifier
+	AttacksClosed   int      `json:"attacks_closed"`   // false positives of the classifier
+	MissedClosures  int      `json:"missed_closures"`  // false negatives of the classifier
+	Precision       *float64 `json:"precision"`        // nil until a decision is labeled
+	Recall          *float64 `json:"recall"`
+	F1              *float64 `json:"f1"`
+}
+
+// EvaluationReport summarises how well auto-close decisions agree with analyst labels within a window
+type EvaluationReport struct {
+	Window      string              `json:"window"`
+	Since       time.Time           `json:"since"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Mode        string              `json:"mode,omitempty"` // empty when both modes are evaluated together
+	Overall     EvaluationMetrics   `json:"overall"`
+	ByCriterion []EvaluationMetrics `json:"by_criterion"`
+	ByRule      []EvaluationMetrics `json:"by_rule"`
+}
@@ -7,9 +7,10 @@ import (
 )
 
 type WazuhSecurityEventRule struct {
-	Description string `json:"description"`
-	Level       int    `json:"level"`
-	ID          string `json:"id"`
+	Description string   `json:"description"`
+	Level       int      `json:"level"`
+	ID          string   `json:"id"`
+	Groups      []string `json:"groups"`
 }
 
 type WazuhSecurityEvent struct {
@@ -18,14 +19,33 @@ type WazuhSecurityEvent struct {
 	Rule      *WazuhSecurityEventRule `json:"rule"`
 }
 
+const (
+	CloseTypeAuto   = "auto"
+	CloseTypeManual = "manual"
+)
+
+const (
+	LabelFalsePositive = "false_positive"
+	LabelTruePositive  = "true_positive"
+)
+
 type ClosedEvent struct {
-	ID       int       `json:"id" db:"id"`
-	EventID  string    `json:"event_id" db:"event_id"`
-	RuleID   string    `json:"rule_id" db:"rule_id"`
-	RawEvent string    `json:"raw_event" db:"raw_event"`
-	Reason   string    `json:"reason" db:"reason"`
-	Status   string    `json:"status" db:"status"`
-	CloseAt  time.Time `json:"close_at" db:"close_at"`
+	ID        int       `json:"id" db:"id"`
+	EventID   string    `json:"event_id" db:"event_id"`
+	RuleID    string    `json:"rule_id" db:"rule_id"`
+	RawEvent  string    `json:"raw_event" db:"raw_event"`
+	Reason    string    `json:"reason" db:"reason"`
+	Status    string    `json:"status" db:"status"`
+	CloseType string    `json:"close_type" db:"close_type"` // auto or manual
+	Label     string    `json:"label" db:"label"`           // false_positive, true_positive or empty when unlabeled
+	CloseAt   time.Time `json:"close_at" db:"close_at"`
+
+	Asset *AssetMatch `json:"asset,omitempty"` // inventory entry of the agent, matched when read
+}
+
+// IsValidLabel reports whether label is a known triage label; empty clears the label
+func IsValidLabel(label string) bool {
+	return label == "" || label == LabelFalsePositive || label == LabelTruePositive
 }
 
 func (m *WazuhSecurityEvent) UnmarshalJSON(data []byte) error {
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// SettingKeyFingerprintConfig is the settings key holding the FingerprintConfig
+const SettingKeyFingerprintConfig = "fingerprint_config"
+
+// FingerprintConfig lists the alert fields, as dotted paths into the alert, that make up its fingerprint.
+// full_log is normalized first so numbers, addresses and hashes do not split a pattern.
+type FingerprintConfig struct {
+	Fields    []string   `json:"fields"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"` // nil while the defaults are in use
+}
+
+// FingerprintCloseResult summarises closing every open alert of one fingerprint
+type FingerprintCloseResult struct {
+	Fingerprint   string    `json:"fingerprint"`
+	Since         time.Time `json:"since"`
+	Scanned       int       `json:"scanned"`        // alerts read from the indexer
+	Matched       int       `json:"matched"`        // alerts with the fingerprint
+	Closed        int       `json:"closed"`         // alerts closed now
+	AlreadyClosed int       `json:"already_closed"` // alerts closed before
+	Truncated     bool      `json:"truncated"`      // the alert limit was reached, newer alerts were not read
+}
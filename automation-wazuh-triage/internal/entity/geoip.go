@@ -0,0 +1,18 @@
+package entity
+
+// GeoLocation is the GeoIP and ASN data of an address. Fields the databases do not have are left empty.
+type GeoLocation struct {
+	IP             string    `json:"ip"`
+	CountryISOCode string    `json:"country_iso_code,omitempty"`
+	CountryName    string    `json:"country_name,omitempty"`
+	CityName       string    `json:"city_name,omitempty"`
+	ContinentCode  string    `json:"continent_code,omitempty"`
+	Location       *GeoPoint `json:"location,omitempty"`
+	ASN            uint      `json:"asn,omitempty"`
+	ASOrganization string    `json:"as_organization,omitempty"`
+}
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
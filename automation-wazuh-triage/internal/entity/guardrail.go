@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// Guardrails that can stop an auto-closure
+const (
+	GuardrailKillSwitch     = "kill_switch"
+	GuardrailIOCMatch       = "ioc_match"
+	GuardrailMaxLevel       = "max_level"
+	GuardrailProtectedRule  = "protected_rule"
+	GuardrailProtectedGroup = "protected_group"
+	GuardrailRateLimit      = "rate_limit"
+)
+
+const (
+	// SettingKeyAutoCloseGuardrails is the settings key holding the AutoCloseGuardrails
+	SettingKeyAutoCloseGuardrails = "auto_close_guardrails"
+
+	// SettingKeyAutoCloseKillSwitch is the settings key holding the KillSwitch
+	SettingKeyAutoCloseKillSwitch = "auto_close_kill_switch"
+)
+
+// AutoCloseGuardrails are the limits every automated closure is checked against
+type AutoCloseGuardrails struct {
+	MaxLevel            int        `json:"max_level"`               // highest rule level that may be auto-closed
+	ProtectedRules      []string   `json:"protected_rules"`         // rule IDs that are never auto-closed
+	ProtectedGroups     []string   `json:"protected_groups"`        // rule groups that are never auto-closed
+	MaxPerMinutePerRule int        `json:"max_per_minute_per_rule"` // 0 disables the rate cap
+	BlockIOCMatches     bool       `json:"block_ioc_matches"`       // events matching a threat-intel indicator are never auto-closed
+	UpdatedBy           string     `json:"updated_by,omitempty"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty"` // nil while the defaults are in use
+}
+
+// KillSwitch stops all automated closure while engaged
+type KillSwitch struct {
+	Engaged   bool       `json:"engaged"`
+	Reason    string     `json:"reason,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// GuardrailTrip records an auto-closure a guardrail prevented
+type GuardrailTrip struct {
+	ID        int       `json:"id" db:"id"`
+	Guardrail string    `json:"guardrail" db:"guardrail"`
+	EventID   string    `json:"event_id" db:"event_id"`
+	RuleID    string    `json:"rule_id" db:"rule_id"`
+	RuleLevel int       `json:"rule_level" db:"rule_level"`
+	Detail    string    `json:"detail" db:"detail"`
+	TrippedAt time.Time `json:"tripped_at" db:"tripped_at"`
+}
+
+// GuardrailStatus is the current guardrail configuration with the trips of the last day per guardrail
+type GuardrailStatus struct {
+	KillSwitch   KillSwitch          `json:"kill_switch"`
+	Guardrails   AutoCloseGuardrails `json:"guardrails"`
+	TripsLastDay map[string]int      `json:"trips_last_day"`
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// Kinds of indicator of compromise
+const (
+	IOCTypeIP     = "ip" // address or CIDR
+	IOCTypeDomain = "domain"
+	IOCTypeURL    = "url"
+	IOCTypeHash   = "hash" // MD5, SHA-1 or SHA-256
+)
+
+// Formats of an IOC feed file, chosen by its extension
+const (
+	IOCFeedFormatList = "list"
+	IOCFeedFormatCSV  = "csv"
+	IOCFeedFormatSTIX = "stix"
+)
+
+// IOC is an indicator of compromise read from a feed file
+type IOC struct {
+	Type       string     `json:"type"`
+	Value      string     `json:"value"`
+	Source     string     `json:"source"`     // who published the indicator, the feed file name when the feed does not say
+	Confidence int        `json:"confidence"` // 0 to 100
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	Feed       string     `json:"feed"` // path of the feed file
+}
+
+// Expired reports whether the indicator is past its expiry at the given time
+func (i *IOC) Expired(at time.Time) bool {
+	return i.ExpiresAt != nil && !at.Before(*i.ExpiresAt)
+}
+
+// IOCMatch is an indicator found in an event and the event field holding it
+type IOCMatch struct {
+	Field    string `json:"field,omitempty"` // e.g. data.srcip or syscheck.sha256_after, empty for a lookup
+	Observed string `json:"observed"`        // value of the field, e.g. the address inside a matching CIDR
+	IOC
+}
+
+// IOCFeed is the state of one feed file
+type IOCFeed struct {
+	Path       string     `json:"path"`
+	Format     string     `json:"format"`
+	Indicators int        `json:"indicators"`
+	Skipped    int        `json:"skipped"` // entries that were not a valid indicator
+	LoadedAt   *time.Time `json:"loaded_at,omitempty"`
+	Error      string     `json:"error,omitempty"` // why the last read failed; the previous indicators of the feed stay loaded
+}
+
+// IOCStoreStatus is the content of the IOC store per feed
+type IOCStoreStatus struct {
+	Feeds      []IOCFeed      `json:"feeds"`
+	Indicators int            `json:"indicators"`
+	Expired    int            `json:"expired"` // loaded but past their expiry, no longer matched
+	ByType     map[string]int `json:"by_type"`
+	CheckedAt  time.Time      `json:"checked_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// KPIBucket holds the triage KPIs of one slice of alerts, such as a day, rule, agent or analyst
+type KPIBucket struct {
+	Key            string   `json:"key"`
+	AlertVolume    *int64   `json:"alert_volume,omitempty"` // alerts in the indexer, not available per analyst
+	Closures       int      `json:"closures"`
+	AutoClosed     int      `json:"auto_closed"`
+	ManualClosed   int      `json:"manual_closed"`
+	AutoCloseRatio float64  `json:"auto_close_ratio"`
+	MTTTSeconds    *float64 `json:"mttt_seconds"` // mean time from alert to first triage action
+	MTTRSeconds    *float64 `json:"mttr_seconds"` // mean time from alert to closure
+}
+
+// KPIReport is the SOC KPI summary over a window, computed from closures and their triage actions
+type KPIReport struct {
+	Window           string      `json:"window"`
+	Since            time.Time   `json:"since"`
+	GeneratedAt      time.Time   `json:"generated_at"`
+	DailyAlertVolume float64     `json:"daily_alert_volume"` // mean alerts per day over the window
+	Overall          KPIBucket   `json:"overall"`
+	ByDay            []KPIBucket `json:"by_day"`
+	ByRule           []KPIBucket `json:"by_rule"`
+	ByAgent          []KPIBucket `json:"by_agent"`
+	ByAnalyst        []KPIBucket `json:"by_analyst"`
+}
@@ -0,0 +1,54 @@
+package entity
+
+const (
+	LogtestSourceInput       = "input"
+	LogtestSourceClosedEvent = "closed_event"
+)
+
+// LogtestSample is a single log line to replay, either given directly or taken from a closed event
+type LogtestSample struct {
+	Log           string `json:"log"`
+	LogFormat     string `json:"log_format"`
+	Location      string `json:"location"`
+	Source        string `json:"source"`
+	ClosedEventID string `json:"closed_event_id,omitempty"`
+}
+
+// LogtestResult is the outcome of replaying one sample through the manager ruleset
+type LogtestResult struct {
+	Log             string                 `json:"log"`
+	Source          string                 `json:"source"`
+	ClosedEventID   string                 `json:"closed_event_id,omitempty"`
+	Decoder         string                 `json:"decoder"`
+	RuleID          string                 `json:"rule_id"`
+	RuleLevel       int                    `json:"rule_level"`
+	RuleDescription string                 `json:"rule_description"`
+	RuleGroups      []string               `json:"rule_groups"`
+	Alert           bool                   `json:"alert"`
+	Messages        []string               `json:"messages,omitempty"`
+	Output          map[string]interface{} `json:"output,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+}
+
+// WazuhLogtestAPIResponse represents the response of PUT /logtest
+type WazuhLogtestAPIResponse struct {
+	Data struct {
+		Token    string   `json:"token"`
+		Messages []string `json:"messages"`
+		Output   struct {
+			Decoder struct {
+				Name string `json:"name"`
+			} `json:"decoder"`
+			Rule struct {
+				ID          string   `json:"id"`
+				Level       int      `json:"level"`
+				Description string   `json:"description"`
+				Groups      []string `json:"groups"`
+			} `json:"rule"`
+		} `json:"output"`
+		Alert   bool `json:"alert"`
+		Codemsg int  `json:"codemsg"`
+	} `json:"data"`
+	Message string `json:"message"`
+	Error   int    `json:"error"`
+}
@@ -0,0 +1,72 @@
+package entity
+
+import "time"
+
+// What happens to alerts from agents in a maintenance window
+const (
+	MaintenancePolicyAutoClose     = "auto_close"
+	MaintenancePolicyLowerPriority = "lower_priority"
+	MaintenancePolicyKeep          = "keep"
+)
+
+// What a maintenance window did with one alert. Auto-close falls back to kept when a guardrail blocks it.
+const (
+	MaintenanceOutcomeClosed  = "closed"
+	MaintenanceOutcomeLowered = "lowered"
+	MaintenanceOutcomeKept    = "kept"
+)
+
+// MaintenanceWindow is planned change work on a set of agents. It is either one-off, from StartsAt to EndsAt,
+// or recurring, starting at each run of Cron in Timezone and lasting Duration.
+type MaintenanceWindow struct {
+	ID          int        `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description,omitempty" db:"description"`
+	StartsAt    *time.Time `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty" db:"ends_at"`
+	Cron        string     `json:"cron,omitempty" db:"cron"`         // e.g. 0 22 * * fri
+	Duration    string     `json:"duration,omitempty" db:"duration"` // e.g. 4h
+	Timezone    string     `json:"timezone" db:"timezone"`           // IANA name the cron is read in
+	AgentIDs    []string   `json:"agent_ids" db:"agent_ids"`
+	AgentGroups []string   `json:"agent_groups" db:"agent_groups"`
+	AgentNames  []string   `json:"agent_names" db:"agent_names"` // globs such as web-*
+	Policy      string     `json:"policy" db:"policy"`           // auto_close, lower_priority or keep
+	Enabled     bool       `json:"enabled" db:"enabled"`
+	CreatedBy   string     `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedBy   string     `json:"updated_by" db:"updated_by"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	TaggedCount int        `json:"tagged_count"`
+
+	// Schedule as of the request
+	Active     bool       `json:"active"`
+	CurrentEnd *time.Time `json:"current_end,omitempty"` // end of the running occurrence
+	NextStart  *time.Time `json:"next_start,omitempty"`
+}
+
+// MaintenanceOccurrence is one run of a maintenance window, from StartsAt to EndsAt
+type MaintenanceOccurrence struct {
+	WindowID  int       `json:"window_id"`
+	Name      string    `json:"name"`
+	Policy    string    `json:"policy"`
+	Recurring bool      `json:"recurring"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+// MaintenanceAlert is an alert that fired on an agent during a maintenance window
+type MaintenanceAlert struct {
+	ID            int       `json:"id" db:"id"`
+	WindowID      int       `json:"window_id" db:"window_id"`
+	EventID       string    `json:"event_id" db:"event_id"`
+	RuleID        string    `json:"rule_id" db:"rule_id"`
+	RuleLevel     int       `json:"rule_level" db:"rule_level"`
+	AgentID       string    `json:"agent_id" db:"agent_id"`
+	AgentName     string    `json:"agent_name" db:"agent_name"`
+	Policy        string    `json:"policy" db:"policy"`
+	Outcome       string    `json:"outcome" db:"outcome"`         // closed, lowered or kept
+	Detail        string    `json:"detail,omitempty" db:"detail"` // why auto-close was not applied
+	ClosedEventID int       `json:"closed_event_id,omitempty" db:"closed_event_id"`
+	AlertAt       time.Time `json:"alert_at" db:"alert_at"`
+	TaggedAt      time.Time `json:"tagged_at" db:"tagged_at"`
+}
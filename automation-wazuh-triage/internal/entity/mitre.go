@@ -0,0 +1,76 @@
+package entity
+
+import "time"
+
+// MitreTactic is an ATT&CK tactic, such as TA0006 Credential Access
+type MitreTactic struct {
+	ID        string `json:"id"`
+	ShortName string `json:"short_name"` // kill chain phase name, e.g. credential-access
+	Name      string `json:"name"`
+}
+
+// MitreTechnique is an ATT&CK technique or sub-technique resolved from the local STIX bundle
+type MitreTechnique struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Tactics      []string `json:"tactics"` // tactic names, in matrix order
+	SubTechnique bool     `json:"sub_technique"`
+	ParentID     string   `json:"parent_id,omitempty"`
+	ParentName   string   `json:"parent_name,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	Deprecated   bool     `json:"deprecated,omitempty"`
+}
+
+// MitreMatrix is the enterprise matrix of the loaded bundle: its tactics in matrix order and every technique
+type MitreMatrix struct {
+	Tactics    []MitreTactic
+	Techniques []*MitreTechnique
+}
+
+// MitreMatrixStatus describes the loaded ATT&CK bundle
+type MitreMatrixStatus struct {
+	Path          string     `json:"path"`
+	Version       string     `json:"version,omitempty"` // ATT&CK release of the bundle
+	Tactics       int        `json:"tactics"`
+	Techniques    int        `json:"techniques"`
+	SubTechniques int        `json:"sub_techniques"`
+	LoadedAt      *time.Time `json:"loaded_at,omitempty"`
+	Error         string     `json:"error,omitempty"` // why the last read failed; the previous bundle stays loaded
+}
+
+// MitreTechniqueCoverage counts the rules mapped to a technique and how they behaved in the window
+type MitreTechniqueCoverage struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	SubTechnique        bool   `json:"sub_technique"`
+	Rules               []int  `json:"rules"`
+	FiredRules          int    `json:"fired_rules"`
+	Alerts              int64  `json:"alerts"`
+	AutoClosed          int    `json:"auto_closed"`
+	RoutinelyAutoClosed []int  `json:"routinely_auto_closed"` // rules whose alerts are mostly auto-closed
+}
+
+// MitreTacticCoverage counts the rules and techniques of a tactic that the rule catalog covers
+type MitreTacticCoverage struct {
+	MitreTactic
+	Techniques               int                      `json:"techniques"`         // techniques of the tactic in the matrix, sub-techniques excluded
+	CoveredTechniques        int                      `json:"covered_techniques"` // of those, techniques with a rule on them or a sub-technique
+	Rules                    int                      `json:"rules"`
+	FiredRules               int                      `json:"fired_rules"`
+	Alerts                   int64                    `json:"alerts"`
+	AutoClosed               int                      `json:"auto_closed"`
+	RoutinelyAutoClosedRules int                      `json:"routinely_auto_closed_rules"`
+	Coverage                 []MitreTechniqueCoverage `json:"coverage"` // techniques with rules, by ID
+}
+
+// MitreCoverageReport crosses the rule catalog with the ATT&CK matrix
+type MitreCoverageReport struct {
+	Window            string                `json:"window"`
+	Since             time.Time             `json:"since"`
+	GeneratedAt       time.Time             `json:"generated_at"`
+	MatrixVersion     string                `json:"matrix_version,omitempty"`
+	Rules             int                   `json:"rules"`        // rules in the catalog
+	MappedRules       int                   `json:"mapped_rules"` // rules with at least one known technique
+	UnknownTechniques []string              `json:"unknown_techniques"`
+	Tactics           []MitreTacticCoverage `json:"tactics"` // in matrix order
+}
@@ -0,0 +1,69 @@
+package entity
+
+import "time"
+
+// Kinds of observable extracted from alerts
+const (
+	ObservableTypeIPv4     = "ipv4"
+	ObservableTypeIPv6     = "ipv6"
+	ObservableTypeDomain   = "domain"
+	ObservableTypeURL      = "url"
+	ObservableTypeEmail    = "email"
+	ObservableTypeUsername = "username"
+	ObservableTypeMD5      = "md5"
+	ObservableTypeSHA1     = "sha1"
+	ObservableTypeSHA256   = "sha256"
+)
+
+// ObservableTypes lists the observable kinds in the order they are reported
+var ObservableTypes = []string{
+	ObservableTypeIPv4,
+	ObservableTypeIPv6,
+	ObservableTypeDomain,
+	ObservableTypeURL,
+	ObservableTypeEmail,
+	ObservableTypeUsername,
+	ObservableTypeMD5,
+	ObservableTypeSHA1,
+	ObservableTypeSHA256,
+}
+
+// Observable is a value of interest found in an alert and the fields it was found in
+type Observable struct {
+	Type     string   `json:"type"`
+	Value    string   `json:"value"`              // normalized, e.g. lower-case domains and hashes, canonical addresses
+	Internal bool     `json:"internal,omitempty"` // private, loopback, link-local or unspecified address
+	Fields   []string `json:"fields"`             // e.g. data.srcip or full_log
+}
+
+// EventObservables are the observables of one alert
+type EventObservables struct {
+	EventID     string       `json:"event_id"`
+	Status      string       `json:"status"` // open, or closed when the alert was closed
+	Observables []Observable `json:"observables"`
+}
+
+// ObservableSummary is an observable seen across the alerts of a window
+type ObservableSummary struct {
+	Type      string    `json:"type"`
+	Value     string    `json:"value"`
+	Internal  bool      `json:"internal,omitempty"`
+	Alerts    int       `json:"alerts"`
+	Agents    []string  `json:"agents"`
+	Rules     []string  `json:"rules"`
+	Fields    []string  `json:"fields"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	EventIDs  []string  `json:"event_ids"` // a sample of the alerts, newest first
+}
+
+// ObservableReport aggregates the observables of the alerts of a window, most seen first
+type ObservableReport struct {
+	Window      string              `json:"window"`
+	Since       time.Time           `json:"since"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Alerts      int                 `json:"alerts"`    // alerts read
+	Truncated   bool                `json:"truncated"` // the window had more alerts than a report reads
+	ByType      map[string]int      `json:"by_type"`
+	Observables []ObservableSummary `json:"observables"`
+}
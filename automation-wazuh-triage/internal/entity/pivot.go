@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// Entity fields alerts can be pivoted on
+const (
+	PivotFieldSrcIP   = "srcip"
+	PivotFieldAgentID = "agent.id"
+	PivotFieldUser    = "user"
+	PivotFieldFile    = "file" // the path syscheck reported
+	PivotFieldHash    = "hash" // any syscheck hash of the file after the change
+)
+
+// PivotFields lists the entity fields in the order they are reported
+var PivotFields = []string{
+	PivotFieldSrcIP,
+	PivotFieldAgentID,
+	PivotFieldUser,
+	PivotFieldFile,
+	PivotFieldHash,
+}
+
+// Triage statuses of a related alert
+const (
+	PivotStatusOpen         = "open"
+	PivotStatusAcknowledged = "acknowledged" // picked up by an analyst but not closed
+	PivotStatusAutoClosed   = "auto_closed"  // closed by auto-close, a snooze or a maintenance window
+	PivotStatusClosed       = "closed"       // closed by an analyst
+)
+
+// PivotValue is the value of an entity field of an alert
+type PivotValue struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"` // one value, except the hashes of a file
+}
+
+// RelatedAlert is an alert sharing an entity value with the pivot alert, and how it was triaged
+type RelatedAlert struct {
+	EventID        string     `json:"event_id"`
+	FiredAt        time.Time  `json:"fired_at"`
+	Offset         string     `json:"offset"` // fired this long after the pivot alert, negative when before
+	RuleID         string     `json:"rule_id"`
+	Level          int        `json:"level"`
+	Description    string     `json:"description"`
+	AgentID        string     `json:"agent_id"`
+	AgentName      string     `json:"agent_name"`
+	Status         string     `json:"status"` // open, acknowledged, auto_closed or closed
+	Label          string     `json:"label,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	ClosedAt       *time.Time `json:"closed_at,omitempty"`
+	ClosedBy       string     `json:"closed_by,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+}
+
+// RelatedAlerts are the alerts sharing the value of one entity field with an alert around the time it fired
+type RelatedAlerts struct {
+	EventID   string         `json:"event_id"`
+	FiredAt   time.Time      `json:"fired_at"`
+	Field     string         `json:"field"`
+	Values    []string       `json:"values"`
+	Window    string         `json:"window"` // alerts are searched this long before and after the pivot alert
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Total     int            `json:"total"`     // related alerts read, which the counts cover
+	Truncated bool           `json:"truncated"` // more alerts shared the value than were read
+	ByStatus  map[string]int `json:"by_status"`
+	Rules     int            `json:"rules"`  // distinct rules among the related alerts
+	Agents    int            `json:"agents"` // distinct agents among the related alerts
+	Alerts    []RelatedAlert `json:"alerts"` // the nearest to the pivot alert, in the order they fired
+}
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+const (
+	ProposalTypeSuppression = "suppression"
+	ProposalTypeRuleChange  = "rule_change"
+
+	ProposalStatusDraft           = "draft"
+	ProposalStatusPendingApproval = "pending_approval"
+	ProposalStatusApproved        = "approved"
+	ProposalStatusRejected        = "rejected"
+	ProposalStatusDeployed        = "deployed"
+	ProposalStatusRolledBack      = "rolled_back"
+)
+
+// Proposal is a change that can stop alerts from reaching an analyst. It has to be approved
+// by someone other than its author before it is deployed.
+type Proposal struct {
+	ID            int    `json:"id" db:"id"`
+	Type          string `json:"type" db:"type"`
+	Title         string `json:"title" db:"title"`
+	Description   string `json:"description" db:"description"`
+	Author        string `json:"author" db:"author"`
+	Status        string `json:"status" db:"status"`
+	SuppressionID int    `json:"suppression_id,omitempty" db:"suppression_id"` // suppression proposals only
+	Filename      string `json:"filename" db:"filename"`
+
+	// Content is the proposed rule file content of a rule change. BaseContent is the file content
+	// the diff was computed against, it is restored when a deployed rule change is rolled back.
+	Content     string `json:"content,omitempty" db:"content"`
+	BaseContent string `json:"-" db:"base_content"`
+	BaseHash    string `json:"base_hash" db:"base_hash"`
+	Diff        string `json:"diff" db:"diff"`
+
+	EvidenceEventIDs []string `json:"evidence_event_ids" db:"evidence_event_ids"` // closed event IDs that motivated the change
+
+	Reviewer      string     `json:"reviewer,omitempty" db:"reviewer"`
+	ReviewComment string     `json:"review_comment,omitempty" db:"review_comment"`
+	DeployedBy    string     `json:"deployed_by,omitempty" db:"deployed_by"`
+	RolledBackBy  string     `json:"rolled_back_by,omitempty" db:"rolled_back_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	DeployedAt    *time.Time `json:"deployed_at,omitempty" db:"deployed_at"`
+	RolledBackAt  *time.Time `json:"rolled_back_at,omitempty" db:"rolled_back_at"`
+}
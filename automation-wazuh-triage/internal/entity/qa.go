@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+const (
+	QAReviewStatusPending    = "pending"
+	QAReviewStatusConfirmed  = "confirmed"
+	QAReviewStatusOverturned = "overturned"
+)
+
+// SettingKeyQASamplingPolicy is the settings key holding the QASamplingPolicy
+const SettingKeyQASamplingPolicy = "qa_sampling_policy"
+
+// QASamplingPolicy decides how many auto-closures of each rule are queued for review per UTC day:
+// RatePercent of the day's closures, rounded up, and never fewer than MinPerRule
+type QASamplingPolicy struct {
+	RatePercent float64    `json:"rate_percent"`
+	MinPerRule  int        `json:"min_per_rule"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"` // nil while the defaults are in use
+}
+
+// QAReview is an auto-closed event sampled for an analyst to confirm or overturn. It keeps a copy
+// of the alert, since overturning it reopens the event and removes the closure.
+type QAReview struct {
+	ID            int        `json:"id" db:"id"`
+	ClosedEventID int        `json:"closed_event_id" db:"closed_event_id"`
+	EventID       string     `json:"event_id" db:"event_id"`
+	RuleID        string     `json:"rule_id" db:"rule_id"`
+	Criterion     string     `json:"criterion" db:"criterion"` // auto-close criterion that closed the event, empty when unknown
+	SampleDay     string     `json:"sample_day" db:"sample_day"`
+	RawEvent      string     `json:"-" db:"raw_event"`
+	Status        string     `json:"status" db:"status"`
+	Reviewer      string     `json:"reviewer,omitempty" db:"reviewer"`
+	Note          string     `json:"note,omitempty" db:"note"`
+	ClosedAt      time.Time  `json:"closed_at" db:"closed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
+
+// QACriterionReport summarises QA reviews of one auto-close criterion. A criterion is flagged once any
+// of its closures has been overturned.
+type QACriterionReport struct {
+	Criterion        string     `json:"criterion"`
+	Sampled          int        `json:"sampled"`
+	Pending          int        `json:"pending"`
+	Confirmed        int        `json:"confirmed"`
+	Overturned       int        `json:"overturned"`
+	Flagged          bool       `json:"flagged"`
+	LastOverturnedAt *time.Time `json:"last_overturned_at,omitempty"`
+}
@@ -0,0 +1,92 @@
+package entity
+
+import "time"
+
+const (
+	RetroHuntStatusQueued    = "queued"
+	RetroHuntStatusRunning   = "running"
+	RetroHuntStatusCompleted = "completed"
+	RetroHuntStatusFailed    = "failed"
+)
+
+// Triage states of an alert a retro-hunt matched, read when the matches are listed
+const (
+	RetroHuntTriageOpen         = "open"
+	RetroHuntTriageAcknowledged = "acknowledged"
+	RetroHuntTriageClosed       = "closed"
+	RetroHuntTriageArchived     = "archived" // an archived event, which is never triaged
+)
+
+// RetroHuntIndicator is one indicator a retro-hunt searches for
+type RetroHuntIndicator struct {
+	Type  string `json:"type"` // ip, domain or hash
+	Value string `json:"value"`
+}
+
+// RetroHunt searches past alerts, and archives when configured, for a list of indicators
+type RetroHunt struct {
+	ID              int                  `json:"id" db:"id"`
+	Name            string               `json:"name" db:"name"`
+	Indicators      []RetroHuntIndicator `json:"indicators" db:"indicators"`
+	Indices         []string             `json:"indices" db:"indices"`
+	From            time.Time            `json:"from" db:"from_time"`
+	To              time.Time            `json:"to" db:"to_time"`
+	Status          string               `json:"status" db:"status"` // queued, running, completed or failed
+	Error           string               `json:"error,omitempty" db:"error"`
+	Searched        int                  `json:"searched" db:"searched"`   // candidate documents read from the indexer
+	Truncated       bool                 `json:"truncated" db:"truncated"` // the hunt stopped at its match limit
+	CreatedBy       string               `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time            `json:"created_at" db:"created_at"`
+	StartedAt       *time.Time           `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt      *time.Time           `json:"finished_at,omitempty" db:"finished_at"`
+	Summary         RetroHuntSummary     `json:"summary"`
+	IndicatorCounts []RetroHuntHits      `json:"indicator_counts,omitempty"` // matched indicators, most alerts first
+}
+
+// RetroHuntSummary counts the matches of a hunt by their current triage state
+type RetroHuntSummary struct {
+	Matches           int  `json:"matches"`
+	MatchedAlerts     int  `json:"matched_alerts"`
+	MatchedIndicators int  `json:"matched_indicators"`
+	Open              int  `json:"open"`
+	Acknowledged      int  `json:"acknowledged"`
+	Closed            int  `json:"closed"`
+	AutoClosed        int  `json:"auto_closed"` // closed by auto-close, a snooze or a maintenance window
+	Archived          int  `json:"archived"`
+	AnyAutoClosed     bool `json:"any_auto_closed"`
+}
+
+// RetroHuntHits are the alerts one indicator matched
+type RetroHuntHits struct {
+	RetroHuntIndicator
+	Alerts     int       `json:"alerts"`
+	AutoClosed int       `json:"auto_closed"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+}
+
+// RetroHuntMatch is an alert or archived event holding an indicator, with the alert's current triage state
+type RetroHuntMatch struct {
+	ID              int        `json:"id" db:"id"`
+	HuntID          int        `json:"hunt_id" db:"hunt_id"`
+	EventID         string     `json:"event_id" db:"event_id"`
+	Index           string     `json:"index" db:"index_name"`
+	AlertAt         time.Time  `json:"alert_at" db:"alert_at"`
+	RuleID          string     `json:"rule_id" db:"rule_id"`
+	RuleLevel       int        `json:"rule_level" db:"rule_level"`
+	RuleDescription string     `json:"rule_description" db:"rule_description"`
+	AgentID         string     `json:"agent_id" db:"agent_id"`
+	AgentName       string     `json:"agent_name" db:"agent_name"`
+	IndicatorType   string     `json:"indicator_type" db:"indicator_type"`
+	Indicator       string     `json:"indicator" db:"indicator"`
+	Observed        string     `json:"observed" db:"observed"` // the value in the alert, e.g. a subdomain of the indicator
+	Fields          []string   `json:"fields" db:"fields"`
+	Archived        bool       `json:"archived" db:"archived"`
+	TriageState     string     `json:"triage_state"` // open, acknowledged, closed or archived
+	CloseType       string     `json:"close_type,omitempty"`
+	AutoClosed      bool       `json:"auto_closed"`
+	Label           string     `json:"label,omitempty"`
+	Reason          string     `json:"reason,omitempty"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	MatchedAt       time.Time  `json:"matched_at" db:"matched_at"`
+}
@@ -23,6 +23,9 @@ type WazuhRule struct {
 	Mitre           []string         `json:"mitre"`
 	Groups          []string         `json:"groups"`
 	Description     string           `json:"description"`
+
+	// MitreTechniques resolves Mitre from the local ATT&CK bundle; the Wazuh API does not return it
+	MitreTechniques []MitreTechnique `json:"mitre_techniques,omitempty"`
 }
 
 // WazuhRulesAPIResponse represents the full Wazuh API response structure
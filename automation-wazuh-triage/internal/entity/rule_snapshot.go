@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+const (
+	SnapshotTriggerManual    = "manual"
+	SnapshotTriggerScheduled = "scheduled"
+)
+
+// RuleSnapshot represents a point-in-time capture of the Wazuh manager ruleset
+type RuleSnapshot struct {
+	ID        int       `json:"id" db:"id"`
+	Trigger   string    `json:"trigger" db:"trigger_type"` // manual or scheduled
+	RuleCount int       `json:"rule_count" db:"rule_count"`
+	Hash      string    `json:"hash" db:"hash"` // hash over every rule hash, equal hashes mean identical rulesets
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// RuleSnapshotItem represents a single rule stored inside a snapshot
+type RuleSnapshotItem struct {
+	SnapshotID  int       `json:"snapshot_id" db:"snapshot_id"`
+	RuleID      int       `json:"rule_id" db:"rule_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	Level       int       `json:"level" db:"level"`
+	Status      string    `json:"status" db:"status"`
+	Groups      []string  `json:"groups" db:"rule_groups"`
+	Description string    `json:"description" db:"description"`
+	Hash        string    `json:"hash" db:"hash"`
+	Rule        WazuhRule `json:"rule" db:"raw_rule"`
+}
+
+// RuleLevelChange describes a rule whose level differs between two snapshots
+type RuleLevelChange struct {
+	RuleID   int `json:"rule_id"`
+	OldLevel int `json:"old_level"`
+	NewLevel int `json:"new_level"`
+}
+
+// RuleGroupChange describes a rule whose groups differ between two snapshots
+type RuleGroupChange struct {
+	RuleID  int      `json:"rule_id"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// RuleDescriptionChange describes a rule whose description differs between two snapshots
+type RuleDescriptionChange struct {
+	RuleID         int    `json:"rule_id"`
+	OldDescription string `json:"old_description"`
+	NewDescription string `json:"new_description"`
+}
+
+// RuleSnapshotDiff represents the differences between two rule snapshots
+type RuleSnapshotDiff struct {
+	FromSnapshotID     int                     `json:"from_snapshot_id"`
+	ToSnapshotID       int                     `json:"to_snapshot_id"`
+	Added              []RuleSnapshotItem      `json:"added"`
+	Removed            []RuleSnapshotItem      `json:"removed"`
+	LevelChanges       []RuleLevelChange       `json:"level_changes"`
+	GroupChanges       []RuleGroupChange       `json:"group_changes"`
+	DescriptionChanges []RuleDescriptionChange `json:"description_changes"`
+	OtherChanges       []int                   `json:"other_changes"` // rule IDs whose hash changed outside the tracked fields
+	CriticalRuleIDs    []int                   `json:"critical_rule_ids"`
+}
+
+// HasChanges reports whether the diff contains any difference at all
+func (d *RuleSnapshotDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.LevelChanges) > 0 ||
+		len(d.GroupChanges) > 0 || len(d.DescriptionChanges) > 0 || len(d.OtherChanges) > 0
+}
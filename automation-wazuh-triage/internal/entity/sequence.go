@@ -0,0 +1,63 @@
+package entity
+
+import "time"
+
+const (
+	// SettingKeySequenceRules is the settings key holding the SequenceRules YAML
+	SettingKeySequenceRules = "sequence_rules"
+
+	// SequenceRuleIDPrefix prefixes the rule ID of the synthetic alert a finding is raised as
+	SequenceRuleIDPrefix = "sequence:"
+
+	// SequenceFindingIndex is the index name given to the synthetic alerts of findings
+	SequenceFindingIndex = "sequence-findings"
+)
+
+// SequenceRuleSet is the YAML document holding the sequence correlation rules
+type SequenceRuleSet struct {
+	Rules []SequenceRule `yaml:"rules" json:"rules"`
+}
+
+// SequenceRule raises a finding when alerts matching each step fire in order for the same GroupBy values,
+// all within Window
+type SequenceRule struct {
+	Name        string         `yaml:"name" json:"name"`
+	Description string         `yaml:"description" json:"description"`
+	Level       int            `yaml:"level" json:"level"`       // rule level of the finding, 12 when omitted
+	Window      string         `yaml:"window" json:"window"`     // duration such as 10m
+	GroupBy     []string       `yaml:"group_by" json:"group_by"` // correlation keys such as srcip and agent.id
+	Steps       []SequenceStep `yaml:"steps" json:"steps"`
+}
+
+// SequenceStep matches alerts of any of RuleIDs or RuleGroups at or above MinLevel. The step is complete once
+// Count alerts matched it.
+type SequenceStep struct {
+	Name       string   `yaml:"name" json:"name,omitempty"`
+	RuleIDs    []string `yaml:"rule_ids" json:"rule_ids,omitempty"`
+	RuleGroups []string `yaml:"rule_groups" json:"rule_groups,omitempty"`
+	MinLevel   int      `yaml:"min_level" json:"min_level,omitempty"`
+	Count      int      `yaml:"count" json:"count"` // 1 when omitted
+}
+
+// SequenceRules is the stored YAML with the rules parsed from it
+type SequenceRules struct {
+	YAML      string         `json:"yaml"`
+	Rules     []SequenceRule `json:"rules"`
+	UpdatedBy string         `json:"updated_by,omitempty"`
+	UpdatedAt *time.Time     `json:"updated_at,omitempty"` // nil while the rules come from SEQUENCE_RULES_FILE
+}
+
+// SequenceFinding is a completed sequence. It enters the triage queue as a synthetic alert with EventID.
+type SequenceFinding struct {
+	ID        int               `json:"id" db:"id"`
+	EventID   string            `json:"event_id" db:"event_id"`
+	RuleName  string            `json:"rule_name" db:"rule_name"`
+	Level     int               `json:"level" db:"level"`
+	GroupKey  string            `json:"group_key" db:"group_key"` // e.g. agent.id=001,srcip=10.0.0.5
+	KeyValues map[string]string `json:"key_values" db:"key_values"`
+	EventIDs  []string          `json:"event_ids" db:"event_ids"` // alerts that completed the steps, in order
+	RawEvent  string            `json:"-" db:"raw_event"`
+	FirstSeen time.Time         `json:"first_seen" db:"first_seen"`
+	LastSeen  time.Time         `json:"last_seen" db:"last_seen"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Setting is a runtime configuration value changed through the API, persisted so it survives restarts
+type Setting struct {
+	Key       string    `json:"key" db:"key"`
+	Value     string    `json:"value" db:"value"` // JSON document
+	UpdatedBy string    `json:"updated_by" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+const (
+	SnoozeStatusActive           = "active"
+	SnoozeStatusExpired          = "expired"
+	SnoozeStatusCancelled        = "cancelled"
+	SnoozeStatusConditionChanged = "condition_changed"
+)
+
+// Snooze temporarily auto-closes the alerts matching all of its scope fields that are set. It ends when it
+// expires, when it is cancelled, or when a matching alert exceeds MaxLevel, and then reports what it suppressed.
+type Snooze struct {
+	ID              int            `json:"id" db:"id"`
+	RuleID          string         `json:"rule_id,omitempty" db:"rule_id"`
+	AgentID         string         `json:"agent_id,omitempty" db:"agent_id"`
+	Fingerprint     string         `json:"fingerprint,omitempty" db:"fingerprint"`
+	MaxLevel        int            `json:"max_level,omitempty" db:"max_level"` // 0 snoozes every level
+	Reason          string         `json:"reason" db:"reason"`
+	CreatedBy       string         `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	ExpiresAt       time.Time      `json:"expires_at" db:"expires_at"`
+	Status          string         `json:"status" db:"status"` // active, expired, cancelled or condition_changed
+	SuppressedCount int            `json:"suppressed_count"`
+	EndedBy         string         `json:"ended_by,omitempty" db:"ended_by"`
+	EndedAt         *time.Time     `json:"ended_at,omitempty" db:"ended_at"`
+	Summary         *SnoozeSummary `json:"summary,omitempty" db:"summary"` // set once the snooze ended
+}
+
+// SnoozeSummary reports what a snooze suppressed, so nothing it closed goes unseen
+type SnoozeSummary struct {
+	Suppressed     int            `json:"suppressed"`
+	FirstAlertAt   *time.Time     `json:"first_alert_at,omitempty"`
+	LastAlertAt    *time.Time     `json:"last_alert_at,omitempty"`
+	ByRule         map[string]int `json:"by_rule"`
+	ByAgent        map[string]int `json:"by_agent"`
+	SampleEventIDs []string       `json:"sample_event_ids"`
+	EndNote        string         `json:"end_note,omitempty"` // why the snooze ended early
+}
+
+// SnoozedAlert is an alert a snooze closed
+type SnoozedAlert struct {
+	ID            int       `json:"id" db:"id"`
+	SnoozeID      int       `json:"snooze_id" db:"snooze_id"`
+	EventID       string    `json:"event_id" db:"event_id"`
+	RuleID        string    `json:"rule_id" db:"rule_id"`
+	RuleLevel     int       `json:"rule_level" db:"rule_level"`
+	AgentName     string    `json:"agent_name" db:"agent_name"`
+	ClosedEventID int       `json:"closed_event_id" db:"closed_event_id"`
+	AlertAt       time.Time `json:"alert_at" db:"alert_at"`
+	SnoozedAt     time.Time `json:"snoozed_at" db:"snoozed_at"`
+}
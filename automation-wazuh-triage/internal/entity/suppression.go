@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+const (
+	SuppressionStatusProposed   = "proposed"
+	SuppressionStatusApproved   = "approved"
+	SuppressionStatusRejected   = "rejected"
+	SuppressionStatusDeployed   = "deployed"
+	SuppressionStatusRolledBack = "rolled_back"
+)
+
+// SuppressionCondition is a single field condition of a suppression rule
+type SuppressionCondition struct {
+	Field    string `json:"field"`              // e.g. srcip, user, agent.name, data.win.eventdata.targetUserName
+	Value    string `json:"value"`              // literal value, CIDR for srcip/dstip or a PCRE2 pattern when operator is regex
+	Operator string `json:"operator,omitempty"` // equals (default), contains or regex
+	Negate   bool   `json:"negate,omitempty"`
+}
+
+// Suppression represents a suppression that becomes a level 0 child rule in Wazuh once its proposal is approved
+type Suppression struct {
+	ID           int                    `json:"id" db:"id"`
+	ParentRuleID int                    `json:"parent_rule_id" db:"parent_rule_id"`
+	Conditions   []SuppressionCondition `json:"conditions" db:"conditions"`
+	Description  string                 `json:"description" db:"description"`
+	CreatedBy    string                 `json:"created_by" db:"created_by"`
+	Status       string                 `json:"status" db:"status"`
+	WazuhRuleID  int                    `json:"wazuh_rule_id" db:"wazuh_rule_id"` // ID of the generated child rule
+	Filename     string                 `json:"filename" db:"filename"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+	DeployedAt   *time.Time             `json:"deployed_at,omitempty" db:"deployed_at"`
+}
+
+// RuleFileVersion is a stored copy of a Wazuh rule file, kept for rollback
+type RuleFileVersion struct {
+	ID        int       `json:"id" db:"id"`
+	Filename  string    `json:"filename" db:"filename"`
+	Content   string    `json:"content" db:"content"`
+	Hash      string    `json:"hash" db:"hash"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
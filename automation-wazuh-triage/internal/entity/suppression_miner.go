@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// ProposalAuthorMiner is the author of proposals generated from closed event history
+const ProposalAuthorMiner = "suppression-miner"
+
+// SuppressionCandidate is a rule and field value that analysts closed repeatedly within the mining window
+type SuppressionCandidate struct {
+	RuleID         string    `json:"rule_id"`
+	Field          string    `json:"field"` // agent.name, srcip, user or location
+	Value          string    `json:"value"`
+	Count          int       `json:"count"`         // analyst closures of the rule with this value
+	RuleClosures   int       `json:"rule_closures"` // analyst closures of the rule in the window
+	Share          float64   `json:"share"`         // Count / RuleClosures
+	FirstClosedAt  time.Time `json:"first_closed_at"`
+	LastClosedAt   time.Time `json:"last_closed_at"`
+	TopReasons     []string  `json:"top_reasons"`
+	SampleEventIDs []string  `json:"sample_event_ids"` // closed event IDs, used as proposal evidence
+	ProposalID     int       `json:"proposal_id,omitempty"`
+	Skipped        string    `json:"skipped,omitempty"` // why no proposal was created
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// Kinds of item on an agent timeline
+const (
+	TimelineItemAlert       = "alert"
+	TimelineItemTriage      = "triage"      // a triage action on one of the agent's alerts
+	TimelineItemMaintenance = "maintenance" // a run of a maintenance window covering the agent
+)
+
+// TimelineItemTypes lists the item kinds in the order items sharing a time are listed
+var TimelineItemTypes = []string{
+	TimelineItemAlert,
+	TimelineItemTriage,
+	TimelineItemMaintenance,
+}
+
+// TimelineItem is one entry of an agent timeline; exactly one of Alert, TriageAction and Maintenance is set
+type TimelineItem struct {
+	Type         string                 `json:"type"` // alert, triage or maintenance
+	Time         time.Time              `json:"time"` // when the alert fired, the action was taken or the run started
+	Alert        *TimelineAlert         `json:"alert,omitempty"`
+	TriageAction *TriageAction          `json:"triage_action,omitempty"`
+	Maintenance  *MaintenanceOccurrence `json:"maintenance,omitempty"`
+}
+
+// TimelineAlert is the summary of an alert on an agent timeline
+type TimelineAlert struct {
+	EventID         string   `json:"event_id"`
+	Index           string   `json:"index"`
+	RuleID          string   `json:"rule_id"`
+	RuleLevel       int      `json:"rule_level"`
+	RuleDescription string   `json:"rule_description"`
+	SrcIP           string   `json:"srcip,omitempty"`
+	User            string   `json:"user,omitempty"`
+	Mitre           []string `json:"mitre,omitempty"`
+}
+
+// AgentTimeline is one page of the alerts, triage actions and maintenance windows of an agent, oldest first
+type AgentTimeline struct {
+	AgentID    string         `json:"agent_id"`
+	AgentName  string         `json:"agent_name,omitempty"`
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Types      []string       `json:"types"`
+	Items      []TimelineItem `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"` // pass as cursor to read the next page; empty on the last one
+}
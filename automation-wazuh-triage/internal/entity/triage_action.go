@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+const (
+	TriageActionAcknowledged = "acknowledged"
+	TriageActionClosed       = "closed"
+	TriageActionReopened     = "reopened"
+	TriageActionEscalated    = "escalated"
+)
+
+const (
+	// ActorAutoClose is recorded as the actor of closures made by auto-close
+	ActorAutoClose = "auto-close"
+
+	// ActorSnooze is recorded as the actor of closures made by an active snooze
+	ActorSnooze = "snooze"
+
+	// ActorMaintenance is recorded as the actor of closures made by a maintenance window
+	ActorMaintenance = "maintenance"
+
+	// ActorUnknown is recorded when an analyst closes an event without identifying themselves
+	ActorUnknown = "unknown"
+)
+
+// TriageAction is one state transition of an alert, the source of time-to-triage and per-analyst KPIs
+type TriageAction struct {
+	ID        int        `json:"id" db:"id"`
+	EventID   string     `json:"event_id" db:"event_id"`
+	RuleID    string     `json:"rule_id" db:"rule_id"`
+	AgentID   string     `json:"agent_id" db:"agent_id"`
+	AgentName string     `json:"agent_name" db:"agent_name"`
+	Action    string     `json:"action" db:"action"` // acknowledged, closed, reopened or escalated
+	Actor     string     `json:"actor" db:"actor"`
+	AlertAt   *time.Time `json:"alert_at" db:"alert_at"` // alert timestamp, nil when it could not be parsed
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AnalyticsHandler struct {
+	analyticsUsecase domain.AnalyticsUsecase
+}
+
+func NewAnalyticsHandler(analyticsUsecase domain.AnalyticsUsecase) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsUsecase: analyticsUsecase,
+	}
+}
+
+func (h *AnalyticsHandler) FetchRuleAnalytics(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req := &model.RuleAnalyticsRequest{
+		Limit: c.QueryInt("limit"),
+	}
+
+	if window := c.Query("window"); window != "" {
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+		}
+		req.Window = duration
+	}
+
+	report, err := h.analyticsUsecase.FetchRuleAnalytics(c.Context(), req)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch rule analytics")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch rule analytics"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+}
+
+func (h *AnalyticsHandler) FetchCountryAnalytics(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req := &model.CountryAnalyticsRequest{
+		Limit: c.QueryInt("limit"),
+	}
+
+	if window := c.Query("window"); window != "" {
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+		}
+		req.Window = duration
+	}
+
+	report, err := h.analyticsUsecase.FetchCountryAnalytics(c.Context(), req)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch country analytics")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch country analytics"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+}
+
+func (h *AnalyticsHandler) FetchMitreCoverage(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req := &model.MitreCoverageRequest{}
+
+	if window := c.Query("window"); window != "" {
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+		}
+		req.Window = duration
+	}
+
+	report, err := h.analyticsUsecase.FetchMitreCoverage(c.Context(), req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch MITRE ATT&CK coverage")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch MITRE ATT&CK coverage"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AssetHandler struct {
+	assetUsecase domain.AssetUsecase
+}
+
+func NewAssetHandler(assetUsecase domain.AssetUsecase) *AssetHandler {
+	return &AssetHandler{
+		assetUsecase: assetUsecase,
+	}
+}
+
+func (h *AssetHandler) CreateAsset(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.AssetRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse asset request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	asset, err := h.assetUsecase.CreateAsset(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to create asset")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to create asset"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.NewResponseSuccess(asset))
+}
+
+func (h *AssetHandler) FetchAssets(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	assets, err := h.assetUsecase.FetchAssets(c.Context(), &model.FetchAssetsRequest{
+		Criticality: c.Query("criticality"),
+		Environment: c.Query("environment"),
+		Tag:         c.Query("tag"),
+		Source:      c.Query("source"),
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch assets")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch assets"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(assets))
+}
+
+func (h *AssetHandler) FetchAssetByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid asset ID parameter"))
+	}
+
+	asset, err := h.assetUsecase.FetchAssetByID(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Asset not found"))
+		}
+		log.WithError(err).WithField("asset_id", id).Error("[handler]: Failed to fetch asset")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch asset"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(asset))
+}
+
+func (h *AssetHandler) UpdateAsset(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid asset ID parameter"))
+	}
+
+	var req model.AssetRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse asset request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	asset, err := h.assetUsecase.UpdateAsset(c.Context(), id, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Asset not found"))
+		}
+		log.WithError(err).WithField("asset_id", id).Error("[handler]: Failed to update asset")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update asset"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(asset))
+}
+
+func (h *AssetHandler) DeleteAsset(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid asset ID parameter"))
+	}
+
+	if err := h.assetUsecase.DeleteAsset(c.Context(), id); err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Asset not found"))
+		}
+		log.WithError(err).WithField("asset_id", id).Error("[handler]: Failed to delete asset")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to delete asset"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(map[string]interface{}{
+		"id":      id,
+		"message": "Asset deleted successfully",
+	}))
+}
+
+// MatchAsset shows which asset an agent ID, hostname or address resolves to
+func (h *AssetHandler) MatchAsset(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	agentID, hostname, ip := c.Query("agent_id"), c.Query("hostname"), c.Query("ip")
+	if agentID == "" && hostname == "" && ip == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("One of agent_id, hostname and ip is required"))
+	}
+
+	match, err := h.assetUsecase.MatchHost(c.Context(), agentID, hostname, ip)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to match asset")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to match asset"))
+	}
+	if match == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("No asset matches"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(match))
+}
+
+func (h *AssetHandler) ReloadInventory(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	reload, err := h.assetUsecase.ReloadInventory(c.Context())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to reload asset inventory")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to reload asset inventory"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(reload))
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CaseHandler struct {
+	caseUsecase domain.CaseUsecase
+}
+
+func NewCaseHandler(caseUsecase domain.CaseUsecase) *CaseHandler {
+	return &CaseHandler{
+		caseUsecase: caseUsecase,
+	}
+}
+
+func (h *CaseHandler) FetchCorrelationConfig(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	config, err := h.caseUsecase.FetchCorrelationConfig(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch correlation config")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch correlation config"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(config))
+}
+
+func (h *CaseHandler) UpdateCorrelationConfig(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.UpdateCorrelationConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse correlation config request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	config, err := h.caseUsecase.UpdateCorrelationConfig(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to update correlation config")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update correlation config"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(config))
+}
+
+func (h *CaseHandler) Correlate(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	window, ok := parseWindowQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 1h"))
+	}
+
+	result, err := h.caseUsecase.Correlate(c.Context(), window)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to correlate alerts")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to correlate alerts"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(result))
+}
+
+// IngestWebhookAlerts accepts one Wazuh alert or an array of them, as sent by a custom integration. When
+// ALERT_WEBHOOK_TOKEN is set the request must carry it as a bearer token.
+func (h *CaseHandler) IngestWebhookAlerts(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	if token := os.Getenv("ALERT_WEBHOOK_TOKEN"); token != "" {
+		given := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(model.NewResponseError("Invalid webhook token"))
+		}
+	}
+
+	body := bytes.TrimSpace(c.Body())
+	var alerts []json.RawMessage
+	if bytes.HasPrefix(body, []byte("[")) {
+		if err := json.Unmarshal(body, &alerts); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+		}
+	} else {
+		if !json.Valid(body) {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+		}
+		alerts = []json.RawMessage{body}
+	}
+
+	result, err := h.caseUsecase.IngestAlerts(c.Context(), alerts)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to ingest webhook alerts")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to ingest webhook alerts"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(result))
+}
+
+func (h *CaseHandler) FetchCases(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	cases, err := h.caseUsecase.FetchCases(c.Context(), c.Query("status"), c.Query("correlation_key"))
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch cases")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch cases"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(cases))
+}
+
+func (h *CaseHandler) FetchCaseByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid case ID parameter"))
+	}
+
+	caseDetail, alerts, err := h.caseUsecase.FetchCaseByID(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Case not found"))
+		}
+		log.WithError(err).WithField("case_id", id).Error("[handler]: Failed to fetch case")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch case"))
+	}
+
+	response := model.CaseDetailResponse{Case: caseDetail, Alerts: make([]model.CaseAlertResponse, 0, len(alerts))}
+	for _, alert := range alerts {
+		var rawEvent interface{}
+		if err := json.Unmarshal([]byte(alert.RawEvent), &rawEvent); err != nil {
+			rawEvent = alert.RawEvent
+		}
+		response.Alerts = append(response.Alerts, model.CaseAlertResponse{CaseAlert: alert, RawEvent: rawEvent})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(response))
+}
+
+func (h *CaseHandler) EscalateCase(c *fiber.Ctx) error {
+	var req model.EscalateCaseRequest
+	return h.caseAction(c, "escalate", &req, func(id int) (*entity.Case, error) {
+		return h.caseUsecase.EscalateCase(c.Context(), id, &req)
+	})
+}
+
+func (h *CaseHandler) CloseCase(c *fiber.Ctx) error {
+	var req model.CloseCaseRequest
+	return h.caseAction(c, "close", &req, func(id int) (*entity.Case, error) {
+		return h.caseUsecase.CloseCase(c.Context(), id, &req)
+	})
+}
+
+// caseAction parses the case ID and request body shared by escalate and close
+func (h *CaseHandler) caseAction(c *fiber.Ctx, action string, req interface{}, run func(id int) (*entity.Case, error)) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid case ID parameter"))
+	}
+
+	if err := c.BodyParser(req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse case request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	updated, err := run(id)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Case not found"))
+		case strings.Contains(err.Error(), "is already"):
+			return c.Status(fiber.StatusConflict).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).WithField("case_id", id).Error("[handler]: Failed to " + action + " case")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to " + action + " case"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(updated))
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ComplianceHandler struct {
+	complianceUsecase domain.ComplianceUsecase
+}
+
+func NewComplianceHandler(complianceUsecase domain.ComplianceUsecase) *ComplianceHandler {
+	return &ComplianceHandler{
+		complianceUsecase: complianceUsecase,
+	}
+}
+
+// FetchComplianceReport renders the report of a framework as JSON, or as CSV or a self-contained HTML page
+// for auditors when format asks for one
+func (h *ComplianceHandler) FetchComplianceReport(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	request := &model.ComplianceReportRequest{Framework: c.Query("framework")}
+
+	from, ok := parseReportTime(c.Query("from"), false)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid from, expected a date such as 2026-01-31 or an RFC 3339 time"))
+	}
+	to, ok := parseReportTime(c.Query("to"), true)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid to, expected a date such as 2026-01-31 or an RFC 3339 time"))
+	}
+	request.From, request.To = from, to
+
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" && format != "html" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid format, expected json, csv or html"))
+	}
+
+	report, err := h.complianceUsecase.FetchComplianceReport(c.Context(), request)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to build compliance report")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to build compliance report"))
+	}
+
+	if format == "json" {
+		return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+	}
+
+	var body bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "csv" {
+		err = writeComplianceCSV(&body, report)
+	} else {
+		contentType = fiber.MIMETextHTMLCharsetUTF8
+		err = complianceReportTemplate.Execute(&body, report)
+	}
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to render compliance report")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to render compliance report"))
+	}
+
+	filename := fmt.Sprintf("compliance-%s-%s-%s.%s", report.Framework, report.From.Format("20060102"), report.To.Format("20060102"), format)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Status(fiber.StatusOK).Send(body.Bytes())
+}
+
+// parseReportTime reads an RFC 3339 time or a date. A date that ends the period covers the whole day.
+func parseReportTime(value string, end bool) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if end {
+		t = t.Add(24 * time.Hour)
+	}
+	return t, true
+}
+
+// writeComplianceCSV writes one row per requirement and alert, and a single row for a requirement without alerts
+func writeComplianceCSV(body *bytes.Buffer, report *entity.ComplianceReport) error {
+	writer := csv.NewWriter(body)
+	header := []string{
+		"framework", "requirement", "rules", "event_id", "fired_at", "rule_id", "level", "description",
+		"agent_id", "agent_name", "outcome", "label", "reason", "closed_at", "closed_by", "acknowledged_by",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, requirement := range report.Requirements {
+		rules := make([]string, 0, len(requirement.Rules))
+		for _, id := range requirement.Rules {
+			rules = append(rules, strconv.Itoa(id))
+		}
+		prefix := []string{report.Framework, requirement.Requirement, strings.Join(rules, " ")}
+
+		if len(requirement.Alerts) == 0 {
+			if err := writer.Write(append(prefix, make([]string, len(header)-len(prefix))...)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, alert := range requirement.Alerts {
+			closedAt := ""
+			if alert.ClosedAt != nil {
+				closedAt = alert.ClosedAt.Format(time.RFC3339)
+			}
+			firedAt := ""
+			if !alert.FiredAt.IsZero() {
+				firedAt = alert.FiredAt.Format(time.RFC3339)
+			}
+			row := append(append([]string{}, prefix...),
+				alert.EventID, firedAt, alert.RuleID, strconv.Itoa(alert.Level), alert.Description,
+				alert.AgentID, alert.AgentName, alert.Outcome, alert.Label, alert.Reason, closedAt, alert.ClosedBy, alert.AcknowledgedBy,
+			)
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+var complianceReportTemplate = template.Must(template.New("compliance").Funcs(template.FuncMap{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05 UTC")
+	},
+	"closedAt": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05 UTC")
+	},
+	"rules": func(ids []int) string {
+		rules := make([]string, 0, len(ids))
+		for _, id := range ids {
+			rules = append(rules, strconv.Itoa(id))
+		}
+		return strings.Join(rules, ", ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Compliance report: {{.Framework}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; margin: 2rem; }
+h1 { font-size: 1.6rem; margin-bottom: 0.2rem; }
+h2 { font-size: 1.2rem; margin-top: 2.5rem; border-bottom: 1px solid #d0d7de; padding-bottom: 0.3rem; }
+h3 { font-size: 1rem; margin-top: 1.2rem; }
+table { border-collapse: collapse; width: 100%; margin-top: 0.5rem; font-size: 0.85rem; }
+th, td { border: 1px solid #d0d7de; padding: 0.3rem 0.5rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.meta { color: #57606a; }
+.warning { background: #fff8c5; border: 1px solid #d4a72c; padding: 0.5rem; }
+.open { color: #cf222e; font-weight: 600; }
+.acknowledged { color: #9a6700; font-weight: 600; }
+.auto_closed, .closed { color: #1a7f37; }
+@media print { h2 { page-break-before: auto; } table { page-break-inside: auto; } }
+</style>
+</head>
+<body>
+<h1>Compliance report: {{.Framework}}</h1>
+<p class="meta">Period {{time .From}} to {{time .To}}, generated {{time .GeneratedAt}}.
+{{.Rules}} rules tagged, {{.Alerts}} alerts fired.</p>
+{{if .Truncated}}<p class="warning">The period had more alerts than a report reads, only the newest are included. Narrow the period for a complete report.</p>{{end}}
+
+<h2>Summary</h2>
+<table>
+<tr><th>Requirement</th><th>Rules</th><th>Alerts</th><th>Open</th><th>Acknowledged</th><th>Auto-closed</th><th>Closed</th><th>False positives</th><th>True positives</th></tr>
+{{range .Requirements}}<tr><td><a href="#req-{{.Requirement}}">{{.Requirement}}</a></td><td>{{rules .Rules}}</td><td>{{.AlertCount}}</td><td>{{.Open}}</td><td>{{.Acknowledged}}</td><td>{{.AutoClosed}}</td><td>{{.Closed}}</td><td>{{.FalsePositives}}</td><td>{{.TruePositives}}</td></tr>
+{{end}}</table>
+
+{{range .Requirements}}
+<h2 id="req-{{.Requirement}}">{{.Requirement}}</h2>
+<p class="meta">Rules {{rules .Rules}}; {{.AlertCount}} alerts.</p>
+{{if .Reasons}}<h3>Closure reasons</h3>
+<table>
+<tr><th>Reason</th><th>Alerts</th></tr>
+{{range .Reasons}}<tr><td>{{.Reason}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}
+{{if .OpenItems}}<h3>Open items</h3>
+<table>
+<tr><th>Fired</th><th>Event</th><th>Rule</th><th>Level</th><th>Agent</th><th>Description</th><th>Status</th></tr>
+{{range .OpenItems}}<tr><td>{{time .FiredAt}}</td><td>{{.EventID}}</td><td>{{.RuleID}}</td><td>{{.Level}}</td><td>{{.AgentName}} ({{.AgentID}})</td><td>{{.Description}}</td><td class="{{.Outcome}}">{{.Outcome}}{{if .AcknowledgedBy}} by {{.AcknowledgedBy}}{{end}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Alerts}}<h3>Alerts</h3>
+<table>
+<tr><th>Fired</th><th>Event</th><th>Rule</th><th>Level</th><th>Agent</th><th>Description</th><th>Outcome</th><th>Label</th><th>Reason</th><th>Closed</th></tr>
+{{range .Alerts}}<tr><td>{{time .FiredAt}}</td><td>{{.EventID}}</td><td>{{.RuleID}}</td><td>{{.Level}}</td><td>{{.AgentName}} ({{.AgentID}})</td><td>{{.Description}}</td><td class="{{.Outcome}}">{{.Outcome}}</td><td>{{.Label}}</td><td>{{.Reason}}</td><td>{{closedAt .ClosedAt}}{{if .ClosedBy}} by {{.ClosedBy}}{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No alerts fired for this requirement in the period.</p>{{end}}
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CVEHandler struct {
+	cveUsecase domain.CVEUsecase
+}
+
+func NewCVEHandler(cveUsecase domain.CVEUsecase) *CVEHandler {
+	return &CVEHandler{
+		cveUsecase: cveUsecase,
+	}
+}
+
+func (h *CVEHandler) ImportFeeds(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	imports, err := h.cveUsecase.ImportFeeds(c.Context())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to import CVE feeds")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to import CVE feeds"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(imports))
+}
+
+func (h *CVEHandler) FetchFeedImports(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	imports, err := h.cveUsecase.FetchFeedImports(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch CVE feed imports")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch CVE feed imports"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(imports))
+}
+
+func (h *CVEHandler) FetchCVEByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	cve, err := h.cveUsecase.FetchCVEByID(c.Context(), c.Params("id"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch CVE")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch CVE"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(cve))
+}
+
+// FetchAgentVulnerabilities ranks agents by the vulnerabilities they were last reported with
+func (h *CVEHandler) FetchAgentVulnerabilities(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req, ok := parseVulnerabilityReportRequest(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 720h"))
+	}
+
+	report, err := h.cveUsecase.FetchAgentVulnerabilities(c.Context(), req)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch agent vulnerabilities")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch agent vulnerabilities"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+}
+
+// FetchAgentVulnerabilitiesByID lists every open vulnerability of one agent
+func (h *CVEHandler) FetchAgentVulnerabilitiesByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req, ok := parseVulnerabilityReportRequest(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 720h"))
+	}
+	req.AgentID = c.Params("agent_id")
+
+	report, err := h.cveUsecase.FetchAgentVulnerabilities(c.Context(), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch agent vulnerabilities")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch agent vulnerabilities"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report.Agents[0]))
+}
+
+// parseVulnerabilityReportRequest reads the window and limit query parameters and reports whether the window is valid
+func parseVulnerabilityReportRequest(c *fiber.Ctx) (*model.VulnerabilityReportRequest, bool) {
+	req := &model.VulnerabilityReportRequest{
+		Limit: c.QueryInt("limit"),
+	}
+
+	if window := c.Query("window"); window != "" {
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration <= 0 {
+			return nil, false
+		}
+		req.Window = duration
+	}
+
+	return req, true
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EvaluationHandler struct {
+	evaluationUsecase domain.EvaluationUsecase
+}
+
+func NewEvaluationHandler(evaluationUsecase domain.EvaluationUsecase) *EvaluationHandler {
+	return &EvaluationHandler{
+		evaluationUsecase: evaluationUsecase,
+	}
+}
+
+func (h *EvaluationHandler) FetchEvaluation(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	window, ok := parseWindowQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 720h"))
+	}
+
+	report, err := h.evaluationUsecase.FetchEvaluation(c.Context(), window, c.Query("mode"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to evaluate auto-close decisions")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to evaluate auto-close decisions"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+}
+
+func (h *EvaluationHandler) FetchDecisionSample(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	window, ok := parseWindowQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 720h"))
+	}
+
+	req := &model.DecisionSampleRequest{
+		Window:    window,
+		Size:      c.QueryInt("size"),
+		Mode:      c.Query("mode"),
+		RuleID:    c.Query("rule_id"),
+		Criterion: c.Query("criterion"),
+	}
+
+	decisions, err := h.evaluationUsecase.FetchDecisionSample(c.Context(), req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to sample auto-close decisions")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to sample auto-close decisions"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(decisions))
+}
+
+func (h *EvaluationHandler) LabelDecision(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid decision ID"))
+	}
+
+	var req model.LabelDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse label decision request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	decision, err := h.evaluationUsecase.LabelDecision(c.Context(), id, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Decision not found"))
+		}
+		log.WithError(err).WithField("decision_id", id).Error("[handler]: Failed to label decision")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to label decision"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(decision))
+}
+
+// parseWindowQuery reads the optional window query parameter, returning zero when it is absent
+func parseWindowQuery(c *fiber.Ctx) (time.Duration, bool) {
+	value := c.Query("window")
+	if value == "" {
+		return 0, true
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 0, false
+	}
+	return window, true
+}
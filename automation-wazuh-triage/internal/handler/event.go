@@ -2,6 +2,7 @@ package handler
 
 import (
 	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
 	"automation-wazuh-triage/internal/model"
 	"automation-wazuh-triage/pkg/logger"
 	"strings"
@@ -11,12 +12,18 @@ import (
 )
 
 type EventHandler struct {
-	eventUsecase domain.EventUsecase
+	eventUsecase       domain.EventUsecase
+	fingerprintUsecase domain.FingerprintUsecase
+	observableUsecase  domain.ObservableUsecase
+	pivotUsecase       domain.PivotUsecase
 }
 
-func NewEventHandler(eventUsecase domain.EventUsecase) *EventHandler {
+func NewEventHandler(eventUsecase domain.EventUsecase, fingerprintUsecase domain.FingerprintUsecase, observableUsecase domain.ObservableUsecase, pivotUsecase domain.PivotUsecase) *EventHandler {
 	return &EventHandler{
-		eventUsecase: eventUsecase,
+		eventUsecase:       eventUsecase,
+		fingerprintUsecase: fingerprintUsecase,
+		observableUsecase:  observableUsecase,
+		pivotUsecase:       pivotUsecase,
 	}
 }
 
@@ -29,6 +36,23 @@ func (h *EventHandler) FetchEvents(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid request payload")
 	}
 
+	// Collapsing groups every alert of the window in the indexer instead of listing a page of events
+	if req.Collapse {
+		if req.AutoAddToClose {
+			return c.Status(fiber.StatusBadRequest).SendString("invalid request: collapse cannot be combined with auto_add_to_close")
+		}
+
+		page, err := h.fingerprintUsecase.CollapseEvents(c.Context(), req)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "invalid") {
+				return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+			}
+			log.WithError(err).Error("[handler]: Failed to collapse events")
+			return c.Status(fiber.StatusInternalServerError).SendString("Failed to collapse events")
+		}
+		return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(page))
+	}
+
 	var events []*elastic.SearchHit
 	var err error
 
@@ -41,6 +65,9 @@ func (h *EventHandler) FetchEvents(c *fiber.Ctx) error {
 	}
 
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid auto_close_mode") || strings.HasPrefix(err.Error(), "invalid asset condition") {
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		}
 		log.WithError(err).Error("[handler]: Failed to fetch events")
 		return c.Status(fiber.StatusInternalServerError).SendString("Failed to fetch events")
 	}
@@ -51,11 +78,15 @@ func (h *EventHandler) FetchEvents(c *fiber.Ctx) error {
 	}
 
 	if req.AutoAddToClose {
-		responseData["auto_closed"] = true
+		responseData["auto_closed"] = req.AutoCloseMode == entity.AutoCloseModeEnforce
+		responseData["auto_close_mode"] = req.AutoCloseMode
 		responseData["total_events_processed"] = len(events)
 
 		// Add informative message
 		message := "Events fetched and automatically added to closed events database"
+		if req.AutoCloseMode == entity.AutoCloseModeShadow {
+			message = "Events fetched and recorded as shadow auto-close decisions without closing them"
+		}
 		responseData["message"] = message
 	}
 
@@ -86,8 +117,12 @@ func (h *EventHandler) AddToClose(c *fiber.Ctx) error {
 	}
 
 	// Close the event
-	err := h.eventUsecase.AddEventToCloseEvent(c.Context(), eventID, req.Reason)
+	err := h.eventUsecase.AddEventToCloseEvent(c.Context(), eventID, &req)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid label") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+
 		// Check if it's a duplicate event error
 		if strings.Contains(err.Error(), "is already closed") {
 			log.WithError(err).WithField("event_id", eventID).Warn("[handler]: Event already closed")
@@ -111,6 +146,39 @@ func (h *EventHandler) AddToClose(c *fiber.Ctx) error {
 	}))
 }
 
+func (h *EventHandler) AcknowledgeEvent(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	eventID := c.Params("event_id")
+	if eventID == "" {
+		log.Error("[handler]: Missing event_id parameter")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Missing event_id parameter"))
+	}
+
+	var req model.AcknowledgeEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse acknowledge event request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	action, err := h.eventUsecase.AcknowledgeEvent(c.Context(), eventID, req.Analyst)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid acknowledgement"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "is already"):
+			return c.Status(fiber.StatusConflict).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Event not found"))
+		}
+
+		log.WithError(err).Error("[handler]: Failed to acknowledge event")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to acknowledge event"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(action))
+}
+
 func (h *EventHandler) FetchClosedEvents(c *fiber.Ctx) error {
 	log := logger.WithRequestID(c.Context())
 
@@ -158,6 +226,8 @@ func (h *EventHandler) FetchClosedEventByID(c *fiber.Ctx) error {
 		log.WithError(err).Error("[handler]: Failed to convert closed event to detail response format")
 		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to process closed event details"))
 	}
+	responseEvent.Observables = h.observableUsecase.ExtractRawEventObservables(closedEvent.RawEvent)
+	responseEvent.Pivots = h.pivotUsecase.ExtractRawEventPivots(closedEvent.RawEvent)
 
 	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(responseEvent))
 }
@@ -204,3 +274,39 @@ func (h *EventHandler) UpdateClosedEventReason(c *fiber.Ctx) error {
 		"message": "Closed event reason updated successfully",
 	}))
 }
+
+func (h *EventHandler) UpdateClosedEventLabel(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id := c.Params("id")
+	if id == "" {
+		log.Error("[handler]: Missing closed event ID parameter")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Missing closed event ID parameter"))
+	}
+
+	var req model.UpdateClosedEventLabelRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse update label request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	err := h.eventUsecase.UpdateClosedEventLabel(c.Context(), id, req.Label)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid label") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		if strings.Contains(err.Error(), "not found") {
+			log.WithError(err).WithField("id", id).Warn("[handler]: Closed event not found")
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Closed event not found"))
+		}
+
+		log.WithError(err).Error("[handler]: Failed to update closed event label")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update closed event label"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(map[string]interface{}{
+		"id":      id,
+		"label":   req.Label,
+		"message": "Closed event label updated successfully",
+	}))
+}
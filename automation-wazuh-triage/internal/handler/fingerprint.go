@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FingerprintHandler struct {
+	fingerprintUsecase domain.FingerprintUsecase
+}
+
+func NewFingerprintHandler(fingerprintUsecase domain.FingerprintUsecase) *FingerprintHandler {
+	return &FingerprintHandler{
+		fingerprintUsecase: fingerprintUsecase,
+	}
+}
+
+func (h *FingerprintHandler) FetchConfig(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	config, err := h.fingerprintUsecase.FetchConfig(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch fingerprint config")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch fingerprint config"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(config))
+}
+
+func (h *FingerprintHandler) UpdateConfig(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.UpdateFingerprintConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse fingerprint config request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	config, err := h.fingerprintUsecase.UpdateConfig(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to update fingerprint config")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update fingerprint config"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(config))
+}
+
+func (h *FingerprintHandler) CloseByFingerprint(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	window, ok := parseWindowQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+	}
+
+	var req model.CloseEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse close by fingerprint request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	result, err := h.fingerprintUsecase.CloseByFingerprint(c.Context(), c.Params("fingerprint"), window, &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to close events by fingerprint")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to close events by fingerprint"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(result))
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type GuardrailHandler struct {
+	guardrailUsecase domain.GuardrailUsecase
+}
+
+func NewGuardrailHandler(guardrailUsecase domain.GuardrailUsecase) *GuardrailHandler {
+	return &GuardrailHandler{
+		guardrailUsecase: guardrailUsecase,
+	}
+}
+
+func (h *GuardrailHandler) FetchStatus(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	status, err := h.guardrailUsecase.FetchStatus(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch guardrail status")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch guardrail status"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(status))
+}
+
+func (h *GuardrailHandler) UpdateGuardrails(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.UpdateGuardrailsRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse guardrails request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	guardrails, err := h.guardrailUsecase.UpdateGuardrails(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to update guardrails")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update guardrails"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(guardrails))
+}
+
+func (h *GuardrailHandler) SetKillSwitch(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.KillSwitchRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse kill switch request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	killSwitch, err := h.guardrailUsecase.SetKillSwitch(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to set kill switch")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to set kill switch"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(killSwitch))
+}
+
+func (h *GuardrailHandler) FetchTrips(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	window, ok := parseWindowQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+	}
+
+	trips, err := h.guardrailUsecase.FetchTrips(c.Context(), &model.FetchGuardrailTripsRequest{
+		Window:    window,
+		Guardrail: c.Query("guardrail"),
+		RuleID:    c.Query("rule_id"),
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch guardrail trips")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch guardrail trips"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(trips))
+}
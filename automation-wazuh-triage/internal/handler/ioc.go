@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IOCHandler struct {
+	iocUsecase domain.IOCUsecase
+}
+
+func NewIOCHandler(iocUsecase domain.IOCUsecase) *IOCHandler {
+	return &IOCHandler{
+		iocUsecase: iocUsecase,
+	}
+}
+
+func (h *IOCHandler) FetchStatus(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	status, err := h.iocUsecase.FetchStatus(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch IOC feeds")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch IOC feeds"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(status))
+}
+
+func (h *IOCHandler) ReloadFeeds(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	status, err := h.iocUsecase.ReloadFeeds(c.Context())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to reload IOC feeds")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to reload IOC feeds"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(status))
+}
+
+// LookupValue lists the indicators an address, domain, URL or hash matches
+func (h *IOCHandler) LookupValue(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	value := c.Query("value")
+	if value == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("value is required"))
+	}
+
+	matches, err := h.iocUsecase.LookupValue(c.Context(), value)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to look up IOC")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to look up IOC"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(matches))
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/metrics"
+	"bytes"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// defaultMetricsWindow is the KPI window exported on /metrics when KPI_METRICS_WINDOW is not set
+const defaultMetricsWindow = 24 * time.Hour
+
+type KPIHandler struct {
+	kpiUsecase    domain.KPIUsecase
+	metricsWindow time.Duration
+}
+
+func NewKPIHandler(kpiUsecase domain.KPIUsecase) *KPIHandler {
+	metricsWindow, err := time.ParseDuration(os.Getenv("KPI_METRICS_WINDOW"))
+	if err != nil || metricsWindow <= 0 {
+		metricsWindow = defaultMetricsWindow
+	}
+
+	return &KPIHandler{
+		kpiUsecase:    kpiUsecase,
+		metricsWindow: metricsWindow,
+	}
+}
+
+func (h *KPIHandler) FetchKPIs(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var window time.Duration
+	if value := c.Query("window"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 720h"))
+		}
+		window = duration
+	}
+
+	report, err := h.kpiUsecase.FetchKPIs(c.Context(), window)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch KPIs")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch KPIs"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+}
+
+// Metrics exports the KPIs over KPI_METRICS_WINDOW as Prometheus gauges, so trends come from the scrape history
+func (h *KPIHandler) Metrics(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	report, err := h.kpiUsecase.FetchKPIs(c.Context(), h.metricsWindow)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to compute KPI metrics")
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to compute KPI metrics")
+	}
+
+	var body bytes.Buffer
+	if err := metrics.Write(&body, kpiGauges(report, h.metricsWindow)); err != nil {
+		log.WithError(err).Error("[handler]: Failed to render KPI metrics")
+		return c.Status(fiber.StatusInternalServerError).SendString("Failed to render KPI metrics")
+	}
+
+	c.Set(fiber.HeaderContentType, metrics.ContentType)
+	return c.Status(fiber.StatusOK).Send(body.Bytes())
+}
+
+func kpiGauges(report *entity.KPIReport, window time.Duration) []*metrics.Gauge {
+	windowGauge := &metrics.Gauge{Name: "triage_kpi_window_seconds", Help: "Look-back window of the exported KPIs."}
+	windowGauge.Add(window.Seconds())
+
+	dailyVolume := &metrics.Gauge{Name: "triage_daily_alert_volume", Help: "Mean alerts per day over the window."}
+	dailyVolume.Add(report.DailyAlertVolume)
+
+	closedByType := &metrics.Gauge{Name: "triage_closed_events_by_type", Help: "Events closed within the window by close type."}
+	closedByType.Add(float64(report.Overall.AutoClosed), "close_type", entity.CloseTypeAuto)
+	closedByType.Add(float64(report.Overall.ManualClosed), "close_type", entity.CloseTypeManual)
+
+	gauges := []*metrics.Gauge{windowGauge, dailyVolume, closedByType}
+	gauges = append(gauges, bucketGauges("triage", "", []entity.KPIBucket{report.Overall})...)
+	gauges = append(gauges, bucketGauges("triage_rule", "rule_id", report.ByRule)...)
+	gauges = append(gauges, bucketGauges("triage_agent", "agent", report.ByAgent)...)
+	gauges = append(gauges, bucketGauges("triage_analyst", "analyst", report.ByAnalyst)...)
+
+	return gauges
+}
+
+// bucketGauges renders one gauge family per KPI, labelled by bucket key unless label is empty
+func bucketGauges(prefix string, label string, buckets []entity.KPIBucket) []*metrics.Gauge {
+	volume := &metrics.Gauge{Name: prefix + "_alert_volume", Help: "Alerts in the indexer within the window."}
+	closed := &metrics.Gauge{Name: prefix + "_closed_events", Help: "Events closed within the window."}
+	ratio := &metrics.Gauge{Name: prefix + "_auto_close_ratio", Help: "Share of closures made by auto-close."}
+	mttt := &metrics.Gauge{Name: prefix + "_mttt_seconds", Help: "Mean time from alert to first triage action."}
+	mttr := &metrics.Gauge{Name: prefix + "_mttr_seconds", Help: "Mean time from alert to closure."}
+
+	for _, bucket := range buckets {
+		var labels []string
+		if label != "" {
+			labels = []string{label, bucket.Key}
+		}
+
+		if bucket.AlertVolume != nil {
+			volume.Add(float64(*bucket.AlertVolume), labels...)
+		}
+		closed.Add(float64(bucket.Closures), labels...)
+		ratio.Add(bucket.AutoCloseRatio, labels...)
+		if bucket.MTTTSeconds != nil {
+			mttt.Add(*bucket.MTTTSeconds, labels...)
+		}
+		if bucket.MTTRSeconds != nil {
+			mttr.Add(*bucket.MTTRSeconds, labels...)
+		}
+	}
+
+	return []*metrics.Gauge{volume, closed, ratio, mttt, mttr}
+}
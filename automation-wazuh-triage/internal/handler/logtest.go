@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LogtestHandler struct {
+	logtestUsecase domain.LogtestUsecase
+}
+
+func NewLogtestHandler(logtestUsecase domain.LogtestUsecase) *LogtestHandler {
+	return &LogtestHandler{
+		logtestUsecase: logtestUsecase,
+	}
+}
+
+func (h *LogtestHandler) TestLogs(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.LogtestRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse logtest request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	results, err := h.logtestUsecase.TestLogs(c.Context(), &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid logtest request"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			log.WithError(err).Warn("[handler]: Closed event not found")
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		case strings.HasPrefix(err.Error(), "logtest rules file"):
+			log.WithError(err).Error("[handler]: Unusable logtest rules file")
+			return c.Status(fiber.StatusConflict).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "rejected by manager"):
+			log.WithError(err).Warn("[handler]: Candidate rules rejected")
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(model.NewResponseError(err.Error()))
+		}
+
+		log.WithError(err).Error("[handler]: Failed to run logtest")
+		return c.Status(fiber.StatusBadGateway).JSON(model.NewResponseError("Failed to run logtest"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(results))
+}
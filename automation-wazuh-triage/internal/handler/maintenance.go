@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MaintenanceHandler struct {
+	maintenanceUsecase domain.MaintenanceUsecase
+}
+
+func NewMaintenanceHandler(maintenanceUsecase domain.MaintenanceUsecase) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceUsecase: maintenanceUsecase,
+	}
+}
+
+func (h *MaintenanceHandler) CreateWindow(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.MaintenanceWindowRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse maintenance window request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	window, err := h.maintenanceUsecase.CreateWindow(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to create maintenance window")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to create maintenance window"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.NewResponseSuccess(window))
+}
+
+func (h *MaintenanceHandler) FetchWindows(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	windows, err := h.maintenanceUsecase.FetchWindows(c.Context(), c.QueryBool("active"))
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch maintenance windows")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch maintenance windows"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(windows))
+}
+
+func (h *MaintenanceHandler) FetchWindowByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid maintenance window ID parameter"))
+	}
+
+	window, alerts, err := h.maintenanceUsecase.FetchWindowByID(c.Context(), id, c.QueryInt("limit"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Maintenance window not found"))
+		}
+		log.WithError(err).WithField("window_id", id).Error("[handler]: Failed to fetch maintenance window")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch maintenance window"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(&model.MaintenanceWindowDetailResponse{
+		MaintenanceWindow: window,
+		Alerts:            alerts,
+	}))
+}
+
+func (h *MaintenanceHandler) UpdateWindow(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid maintenance window ID parameter"))
+	}
+
+	var req model.MaintenanceWindowRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse maintenance window request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	window, err := h.maintenanceUsecase.UpdateWindow(c.Context(), id, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Maintenance window not found"))
+		}
+		log.WithError(err).WithField("window_id", id).Error("[handler]: Failed to update maintenance window")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update maintenance window"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(window))
+}
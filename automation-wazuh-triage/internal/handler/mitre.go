@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MitreHandler struct {
+	mitreUsecase domain.MitreUsecase
+}
+
+func NewMitreHandler(mitreUsecase domain.MitreUsecase) *MitreHandler {
+	return &MitreHandler{
+		mitreUsecase: mitreUsecase,
+	}
+}
+
+func (h *MitreHandler) FetchStatus(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	status, err := h.mitreUsecase.FetchStatus(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch ATT&CK bundle status")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch ATT&CK bundle status"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(status))
+}
+
+func (h *MitreHandler) ReloadMatrix(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	status, err := h.mitreUsecase.ReloadMatrix(c.Context())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to reload ATT&CK bundle")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to reload ATT&CK bundle"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(status))
+}
+
+func (h *MitreHandler) FetchTechniqueByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	technique, err := h.mitreUsecase.FetchTechniqueByID(c.Context(), c.Params("id"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch ATT&CK technique")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch ATT&CK technique"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(technique))
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// stixNamespace is the namespace of the deterministic IDs of STIX cyber-observable objects
+var stixNamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+
+type ObservableHandler struct {
+	observableUsecase domain.ObservableUsecase
+}
+
+func NewObservableHandler(observableUsecase domain.ObservableUsecase) *ObservableHandler {
+	return &ObservableHandler{
+		observableUsecase: observableUsecase,
+	}
+}
+
+func (h *ObservableHandler) FetchEventObservables(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	observables, err := h.observableUsecase.FetchEventObservables(c.Context(), c.Params("event_id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch event observables")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch event observables"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(observables))
+}
+
+// FetchObservables aggregates the observables of a window as JSON, or exports them as CSV or a STIX 2.1 bundle
+// for a threat-intel platform when format asks for one
+func (h *ObservableHandler) FetchObservables(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req := &model.ObservablesRequest{
+		Limit:           c.QueryInt("limit"),
+		Type:            strings.ToLower(c.Query("type")),
+		AgentID:         c.Query("agent_id"),
+		RuleID:          c.Query("rule_id"),
+		ExcludeInternal: c.QueryBool("exclude_internal"),
+	}
+
+	if window := c.Query("window"); window != "" {
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+		}
+		req.Window = duration
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" && format != "stix" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid format, expected json, csv or stix"))
+	}
+
+	report, err := h.observableUsecase.FetchObservables(c.Context(), req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch observables")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch observables"))
+	}
+
+	if format == "json" {
+		return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(report))
+	}
+
+	var body bytes.Buffer
+	contentType, extension := "text/csv; charset=utf-8", "csv"
+	if format == "csv" {
+		err = writeObservablesCSV(&body, report)
+	} else {
+		contentType, extension = fiber.MIMEApplicationJSON, "json"
+		err = writeObservablesSTIX(&body, report)
+	}
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to render observables")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to render observables"))
+	}
+
+	filename := fmt.Sprintf("observables-%s.%s", report.GeneratedAt.Format("20060102T150405Z"), extension)
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Status(fiber.StatusOK).Send(body.Bytes())
+}
+
+func writeObservablesCSV(body *bytes.Buffer, report *entity.ObservableReport) error {
+	writer := csv.NewWriter(body)
+	header := []string{"type", "value", "internal", "alerts", "agents", "rules", "fields", "first_seen", "last_seen", "event_ids"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, observable := range report.Observables {
+		row := []string{
+			observable.Type,
+			observable.Value,
+			strconv.FormatBool(observable.Internal),
+			strconv.Itoa(observable.Alerts),
+			strings.Join(observable.Agents, " "),
+			strings.Join(observable.Rules, " "),
+			strings.Join(observable.Fields, " "),
+			observable.FirstSeen.Format(time.RFC3339),
+			observable.LastSeen.Format(time.RFC3339),
+			strings.Join(observable.EventIDs, " "),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeObservablesSTIX writes the observables as STIX 2.1 cyber-observable objects in a bundle. Object IDs are
+// derived from the value, as the specification asks, so the same observable keeps its ID across exports; where
+// and how often it was seen travels in x_wazuh_ properties.
+func writeObservablesSTIX(body *bytes.Buffer, report *entity.ObservableReport) error {
+	objects := make([]map[string]interface{}, 0, len(report.Observables))
+
+	for _, observable := range report.Observables {
+		var stixType string
+		var contributing map[string]interface{}
+		switch observable.Type {
+		case entity.ObservableTypeIPv4:
+			stixType, contributing = "ipv4-addr", map[string]interface{}{"value": observable.Value}
+		case entity.ObservableTypeIPv6:
+			stixType, contributing = "ipv6-addr", map[string]interface{}{"value": observable.Value}
+		case entity.ObservableTypeDomain:
+			stixType, contributing = "domain-name", map[string]interface{}{"value": observable.Value}
+		case entity.ObservableTypeURL:
+			stixType, contributing = "url", map[string]interface{}{"value": observable.Value}
+		case entity.ObservableTypeEmail:
+			stixType, contributing = "email-addr", map[string]interface{}{"value": observable.Value}
+		case entity.ObservableTypeUsername:
+			stixType, contributing = "user-account", map[string]interface{}{"account_login": observable.Value}
+		case entity.ObservableTypeMD5:
+			stixType, contributing = "file", map[string]interface{}{"hashes": map[string]string{"MD5": observable.Value}}
+		case entity.ObservableTypeSHA1:
+			stixType, contributing = "file", map[string]interface{}{"hashes": map[string]string{"SHA-1": observable.Value}}
+		case entity.ObservableTypeSHA256:
+			stixType, contributing = "file", map[string]interface{}{"hashes": map[string]string{"SHA-256": observable.Value}}
+		default:
+			continue
+		}
+
+		// encoding/json sorts map keys, which with HTML escaping off is the canonical form for these values
+		var canonical bytes.Buffer
+		encoder := json.NewEncoder(&canonical)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(contributing); err != nil {
+			return err
+		}
+
+		object := map[string]interface{}{
+			"type":                stixType,
+			"spec_version":        "2.1",
+			"id":                  stixType + "--" + uuid.NewSHA1(stixNamespace, bytes.TrimSpace(canonical.Bytes())).String(),
+			"x_wazuh_alerts":      observable.Alerts,
+			"x_wazuh_agents":      observable.Agents,
+			"x_wazuh_rules":       observable.Rules,
+			"x_wazuh_first_seen":  observable.FirstSeen.Format(time.RFC3339),
+			"x_wazuh_last_seen":   observable.LastSeen.Format(time.RFC3339),
+			"x_wazuh_internal":    observable.Internal,
+			"x_wazuh_event_ids":   observable.EventIDs,
+			"x_wazuh_observed_in": observable.Fields,
+		}
+		for key, value := range contributing {
+			object[key] = value
+		}
+		objects = append(objects, object)
+	}
+
+	bundle := map[string]interface{}{
+		"type":    "bundle",
+		"id":      "bundle--" + uuid.New().String(),
+		"objects": objects,
+	}
+
+	encoder := json.NewEncoder(body)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bundle)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PivotHandler struct {
+	pivotUsecase domain.PivotUsecase
+}
+
+func NewPivotHandler(pivotUsecase domain.PivotUsecase) *PivotHandler {
+	return &PivotHandler{
+		pivotUsecase: pivotUsecase,
+	}
+}
+
+// FetchRelatedAlerts lists the alerts sharing an entity value with an alert around the time it fired
+func (h *PivotHandler) FetchRelatedAlerts(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	req := &model.RelatedAlertsRequest{
+		Field:  strings.TrimSpace(c.Query("field")),
+		Limit:  c.QueryInt("limit"),
+		Status: strings.ToLower(c.Query("status")),
+	}
+
+	if window := c.Query("window"); window != "" {
+		duration, err := time.ParseDuration(window)
+		if err != nil || duration <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+		}
+		req.Window = duration
+	}
+
+	related, err := h.pivotUsecase.FetchRelatedAlerts(c.Context(), c.Params("event_id"), req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch related alerts")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch related alerts"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(related))
+}
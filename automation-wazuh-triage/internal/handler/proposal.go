@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProposalHandler struct {
+	proposalUsecase domain.ProposalUsecase
+}
+
+func NewProposalHandler(proposalUsecase domain.ProposalUsecase) *ProposalHandler {
+	return &ProposalHandler{
+		proposalUsecase: proposalUsecase,
+	}
+}
+
+// CreateProposal stores a draft authored by the caller
+func (h *ProposalHandler) CreateProposal(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	actor, ok := proposalActor(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(model.NewResponseError("Invalid or missing proposal actor token"))
+	}
+
+	var req model.CreateProposalRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse create proposal request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+	req.Author = actor
+
+	proposal, err := h.proposalUsecase.CreateProposal(c.Context(), &req)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to create proposal")
+
+		status := proposalErrorStatus(err, fiber.StatusInternalServerError)
+		if status == fiber.StatusInternalServerError {
+			return c.Status(status).JSON(model.NewResponseError("Failed to create proposal"))
+		}
+		return c.Status(status).JSON(model.NewResponseError(err.Error()))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.NewResponseSuccess(proposal))
+}
+
+func (h *ProposalHandler) FetchProposals(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	proposals, err := h.proposalUsecase.FetchProposals(c.Context(), c.Query("status"))
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch proposals")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch proposals"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(proposals))
+}
+
+func (h *ProposalHandler) FetchProposalByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid proposal ID parameter"))
+	}
+
+	proposal, suppression, evidence, err := h.proposalUsecase.FetchProposalDetails(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+
+		log.WithError(err).Error("[handler]: Failed to fetch proposal")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch proposal"))
+	}
+
+	response := &model.ProposalDetailResponse{
+		Proposal:    proposal,
+		Suppression: suppression,
+		Evidence:    []*model.ClosedEventResponse{},
+	}
+	for _, closedEvent := range evidence {
+		closedEventResponse, err := model.ConvertClosedEventToResponse(closedEvent)
+		if err != nil {
+			log.WithError(err).Error("[handler]: Failed to convert evidence closed event")
+			continue
+		}
+		response.Evidence = append(response.Evidence, closedEventResponse)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(response))
+}
+
+func (h *ProposalHandler) SubmitProposal(c *fiber.Ctx) error {
+	return h.proposalAction(c, "submit", func(ctx context.Context, id int, actor string, req *model.ProposalActionRequest) (*entity.Proposal, error) {
+		return h.proposalUsecase.SubmitProposal(ctx, id, actor)
+	})
+}
+
+func (h *ProposalHandler) ApproveProposal(c *fiber.Ctx) error {
+	return h.proposalAction(c, "approve", func(ctx context.Context, id int, actor string, req *model.ProposalActionRequest) (*entity.Proposal, error) {
+		return h.proposalUsecase.ApproveProposal(ctx, id, actor, req.Comment)
+	})
+}
+
+func (h *ProposalHandler) RejectProposal(c *fiber.Ctx) error {
+	return h.proposalAction(c, "reject", func(ctx context.Context, id int, actor string, req *model.ProposalActionRequest) (*entity.Proposal, error) {
+		return h.proposalUsecase.RejectProposal(ctx, id, actor, req.Comment)
+	})
+}
+
+func (h *ProposalHandler) DeployProposal(c *fiber.Ctx) error {
+	return h.proposalAction(c, "deploy", func(ctx context.Context, id int, actor string, req *model.ProposalActionRequest) (*entity.Proposal, error) {
+		return h.proposalUsecase.DeployProposal(ctx, id, actor)
+	})
+}
+
+func (h *ProposalHandler) RollbackProposal(c *fiber.Ctx) error {
+	return h.proposalAction(c, "roll back", func(ctx context.Context, id int, actor string, req *model.ProposalActionRequest) (*entity.Proposal, error) {
+		return h.proposalUsecase.RollbackProposal(ctx, id, actor, req.Comment)
+	})
+}
+
+// proposalAction identifies the caller and parses the proposal ID and comment shared by every state change
+func (h *ProposalHandler) proposalAction(c *fiber.Ctx, action string, run func(ctx context.Context, id int, actor string, req *model.ProposalActionRequest) (*entity.Proposal, error)) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid proposal ID parameter"))
+	}
+
+	actor, ok := proposalActor(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(model.NewResponseError("Invalid or missing proposal actor token"))
+	}
+
+	// The body only carries an optional comment
+	var req model.ProposalActionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			log.WithError(err).Error("[handler]: Failed to parse proposal action request")
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+		}
+	}
+
+	proposal, err := run(c.Context(), id, actor, &req)
+	if err != nil {
+		log.WithError(err).WithField("proposal_id", id).Errorf("[handler]: Failed to %s proposal", action)
+
+		status := proposalErrorStatus(err, fiber.StatusBadGateway)
+		if status == fiber.StatusBadGateway {
+			return c.Status(status).JSON(model.NewResponseError("Failed to " + action + " proposal"))
+		}
+		return c.Status(status).JSON(model.NewResponseError(err.Error()))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(proposal))
+}
+
+// proposalActor returns the caller named by its bearer token in PROPOSAL_ACTOR_TOKENS, a comma separated list
+// of name:token pairs. Authors, reviewers and deployers are never read from the request body, so nobody can
+// approve their own proposal under another name; with no tokens configured every proposal change is refused.
+func proposalActor(c *fiber.Ctx) (string, bool) {
+	given := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if given == "" {
+		return "", false
+	}
+
+	actor := ""
+	for _, pair := range strings.Split(os.Getenv("PROPOSAL_ACTOR_TOKENS"), ",") {
+		name, token, found := strings.Cut(pair, ":")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !found || name == "" || token == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+			actor = name
+		}
+	}
+
+	return actor, actor != ""
+}
+
+// proposalErrorStatus maps proposal workflow errors to HTTP status codes
+func proposalErrorStatus(err error, fallback int) int {
+	message := err.Error()
+
+	switch {
+	case strings.HasPrefix(message, "invalid proposal"):
+		return fiber.StatusBadRequest
+	case strings.Contains(message, "not found"):
+		return fiber.StatusNotFound
+	case strings.Contains(message, "must differ from the author"), strings.Contains(message, "only be submitted by its author"):
+		return fiber.StatusForbidden
+	case strings.Contains(message, "cannot move"), strings.Contains(message, "changed since"),
+		strings.Contains(message, "already deployed"), strings.Contains(message, "is not approved"), strings.Contains(message, "is not deployed"):
+		return fiber.StatusConflict
+	case strings.Contains(message, "rejected by manager"):
+		return fiber.StatusUnprocessableEntity
+	}
+
+	return fallback
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type QAHandler struct {
+	qaUsecase domain.QAUsecase
+}
+
+func NewQAHandler(qaUsecase domain.QAUsecase) *QAHandler {
+	return &QAHandler{
+		qaUsecase: qaUsecase,
+	}
+}
+
+func (h *QAHandler) FetchSamplingPolicy(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	policy, err := h.qaUsecase.FetchSamplingPolicy(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch sampling policy")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch sampling policy"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(policy))
+}
+
+func (h *QAHandler) UpdateSamplingPolicy(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.UpdateQASamplingPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse sampling policy request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	policy, err := h.qaUsecase.UpdateSamplingPolicy(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to update sampling policy")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update sampling policy"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(policy))
+}
+
+func (h *QAHandler) SampleDay(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	day := time.Now().UTC()
+	if value := c.Query("day"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid day, expected YYYY-MM-DD"))
+		}
+		day = parsed
+	}
+
+	reviews, err := h.qaUsecase.SampleDay(c.Context(), day)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to sample auto-closures")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to sample auto-closures"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(qaReviewResponses(reviews)))
+}
+
+func (h *QAHandler) FetchQAReviews(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	reviews, err := h.qaUsecase.FetchQAReviews(c.Context(), c.Query("status"), c.Query("rule_id"))
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch QA reviews")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch QA reviews"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(qaReviewResponses(reviews)))
+}
+
+func (h *QAHandler) FetchQAReviewByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid QA review ID parameter"))
+	}
+
+	review, err := h.qaUsecase.FetchQAReviewByID(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("QA review not found"))
+		}
+		log.WithError(err).WithField("review_id", id).Error("[handler]: Failed to fetch QA review")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch QA review"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(qaReviewResponse(review)))
+}
+
+func (h *QAHandler) ConfirmQAReview(c *fiber.Ctx) error {
+	return h.reviewAction(c, "confirm", h.qaUsecase.ConfirmQAReview)
+}
+
+func (h *QAHandler) OverturnQAReview(c *fiber.Ctx) error {
+	return h.reviewAction(c, "overturn", h.qaUsecase.OverturnQAReview)
+}
+
+func (h *QAHandler) FetchCriterionReports(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	reports, err := h.qaUsecase.FetchCriterionReports(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch QA criterion reports")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch QA criterion reports"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(reports))
+}
+
+// reviewAction parses the review ID and reviewer body shared by confirm and overturn
+func (h *QAHandler) reviewAction(c *fiber.Ctx, action string, run func(ctx context.Context, id int, req *model.QAReviewActionRequest) (*entity.QAReview, error)) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid QA review ID parameter"))
+	}
+
+	var req model.QAReviewActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse QA review request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	review, err := run(c.Context(), id, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("QA review not found"))
+		case strings.Contains(err.Error(), "is already"):
+			return c.Status(fiber.StatusConflict).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).WithField("review_id", id).Error("[handler]: Failed to " + action + " QA review")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to " + action + " QA review"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(qaReviewResponse(review)))
+}
+
+func qaReviewResponse(review *entity.QAReview) model.QAReviewResponse {
+	var rawEvent interface{}
+	if err := json.Unmarshal([]byte(review.RawEvent), &rawEvent); err != nil {
+		rawEvent = review.RawEvent
+	}
+
+	return model.QAReviewResponse{QAReview: review, RawEvent: rawEvent}
+}
+
+func qaReviewResponses(reviews []*entity.QAReview) []model.QAReviewResponse {
+	responses := make([]model.QAReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		responses = append(responses, qaReviewResponse(review))
+	}
+	return responses
+}
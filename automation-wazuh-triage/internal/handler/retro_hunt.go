@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RetroHuntHandler struct {
+	retroHuntUsecase domain.RetroHuntUsecase
+}
+
+func NewRetroHuntHandler(retroHuntUsecase domain.RetroHuntUsecase) *RetroHuntHandler {
+	return &RetroHuntHandler{
+		retroHuntUsecase: retroHuntUsecase,
+	}
+}
+
+// CreateRetroHunt queues a hunt and answers at once; poll the hunt for its status and matches
+func (h *RetroHuntHandler) CreateRetroHunt(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.CreateRetroHuntRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse retro-hunt request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	hunt, err := h.retroHuntUsecase.CreateRetroHunt(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to create retro-hunt")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to create retro-hunt"))
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(model.NewResponseSuccess(hunt))
+}
+
+func (h *RetroHuntHandler) FetchRetroHunts(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	hunts, err := h.retroHuntUsecase.FetchRetroHunts(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch retro-hunts")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch retro-hunts"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(hunts))
+}
+
+func (h *RetroHuntHandler) FetchRetroHuntByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid retro-hunt ID parameter"))
+	}
+
+	hunt, err := h.retroHuntUsecase.FetchRetroHuntByID(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Retro-hunt not found"))
+		}
+		log.WithError(err).WithField("hunt_id", id).Error("[handler]: Failed to fetch retro-hunt")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch retro-hunt"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(hunt))
+}
+
+func (h *RetroHuntHandler) FetchRetroHuntMatches(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid retro-hunt ID parameter"))
+	}
+
+	filter := &model.RetroHuntMatchFilter{
+		Indicator:   c.Query("indicator"),
+		TriageState: strings.ToLower(c.Query("triage_state")),
+		AutoClosed:  c.QueryBool("auto_closed"),
+	}
+
+	matches, err := h.retroHuntUsecase.FetchRetroHuntMatches(c.Context(), id, filter)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Retro-hunt not found"))
+		}
+		log.WithError(err).WithField("hunt_id", id).Error("[handler]: Failed to fetch retro-hunt matches")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch retro-hunt matches"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(matches))
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RuleSnapshotHandler struct {
+	ruleSnapshotUsecase domain.RuleSnapshotUsecase
+}
+
+func NewRuleSnapshotHandler(ruleSnapshotUsecase domain.RuleSnapshotUsecase) *RuleSnapshotHandler {
+	return &RuleSnapshotHandler{
+		ruleSnapshotUsecase: ruleSnapshotUsecase,
+	}
+}
+
+func (h *RuleSnapshotHandler) CreateSnapshot(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	snapshot, err := h.ruleSnapshotUsecase.CreateSnapshot(c.Context(), entity.SnapshotTriggerManual)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to create rule snapshot")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to create rule snapshot"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.NewResponseSuccess(snapshot))
+}
+
+func (h *RuleSnapshotHandler) FetchSnapshots(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	snapshots, err := h.ruleSnapshotUsecase.FetchSnapshots(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch rule snapshots")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch rule snapshots"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(snapshots))
+}
+
+func (h *RuleSnapshotHandler) DiffSnapshots(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	fromID := c.QueryInt("from")
+	toID := c.QueryInt("to")
+	if fromID <= 0 || toID <= 0 {
+		log.Error("[handler]: Missing from or to snapshot ID")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Query parameters from and to are required"))
+	}
+
+	diff, err := h.ruleSnapshotUsecase.DiffSnapshots(c.Context(), fromID, toID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			log.WithError(err).Warn("[handler]: Rule snapshot not found")
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError(err.Error()))
+		}
+
+		log.WithError(err).Error("[handler]: Failed to diff rule snapshots")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to diff rule snapshots"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(diff))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SequenceHandler struct {
+	sequenceUsecase domain.SequenceUsecase
+}
+
+func NewSequenceHandler(sequenceUsecase domain.SequenceUsecase) *SequenceHandler {
+	return &SequenceHandler{
+		sequenceUsecase: sequenceUsecase,
+	}
+}
+
+func (h *SequenceHandler) FetchRules(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	rules, err := h.sequenceUsecase.FetchRules(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch sequence rules")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch sequence rules"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(rules))
+}
+
+func (h *SequenceHandler) UpdateRules(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.UpdateSequenceRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse sequence rules request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	rules, err := h.sequenceUsecase.UpdateRules(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to update sequence rules")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to update sequence rules"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(rules))
+}
+
+func (h *SequenceHandler) FetchFindings(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	window, ok := parseWindowQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid window, expected a duration such as 24h"))
+	}
+
+	findings, err := h.sequenceUsecase.FetchFindings(c.Context(), &model.FetchSequenceFindingsRequest{
+		Window:   window,
+		RuleName: c.Query("rule"),
+		Limit:    c.QueryInt("limit"),
+	})
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch sequence findings")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch sequence findings"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(findings))
+}
+
+func (h *SequenceHandler) FetchFindingByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid finding ID parameter"))
+	}
+
+	finding, err := h.sequenceUsecase.FetchFindingByID(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Sequence finding not found"))
+		}
+		log.WithError(err).WithField("finding_id", id).Error("[handler]: Failed to fetch sequence finding")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch sequence finding"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(finding))
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SnoozeHandler struct {
+	snoozeUsecase domain.SnoozeUsecase
+}
+
+func NewSnoozeHandler(snoozeUsecase domain.SnoozeUsecase) *SnoozeHandler {
+	return &SnoozeHandler{
+		snoozeUsecase: snoozeUsecase,
+	}
+}
+
+func (h *SnoozeHandler) CreateSnooze(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	var req model.CreateSnoozeRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse snooze request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	snooze, err := h.snoozeUsecase.CreateSnooze(c.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to create snooze")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to create snooze"))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(model.NewResponseSuccess(snooze))
+}
+
+func (h *SnoozeHandler) FetchSnoozes(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	snoozes, err := h.snoozeUsecase.FetchSnoozes(c.Context(), c.Query("status"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch snoozes")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch snoozes"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(snoozes))
+}
+
+func (h *SnoozeHandler) FetchSnoozeByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid snooze ID parameter"))
+	}
+
+	snooze, alerts, err := h.snoozeUsecase.FetchSnoozeByID(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Snooze not found"))
+		}
+		log.WithError(err).WithField("snooze_id", id).Error("[handler]: Failed to fetch snooze")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch snooze"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(&model.SnoozeDetailResponse{
+		Snooze: snooze,
+		Alerts: alerts,
+	}))
+}
+
+func (h *SnoozeHandler) CancelSnooze(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid snooze ID parameter"))
+	}
+
+	var req model.CancelSnoozeRequest
+	if err := c.BodyParser(&req); err != nil {
+		log.WithError(err).Error("[handler]: Failed to parse snooze cancel request")
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid request payload"))
+	}
+
+	snooze, err := h.snoozeUsecase.CancelSnooze(c.Context(), id, &req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		case strings.Contains(err.Error(), "not found"):
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Snooze not found"))
+		case strings.Contains(err.Error(), "is already"):
+			return c.Status(fiber.StatusConflict).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).WithField("snooze_id", id).Error("[handler]: Failed to cancel snooze")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to cancel snooze"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(snooze))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SuppressionHandler struct {
+	suppressionUsecase domain.SuppressionUsecase
+	ruleFileUsecase    domain.RuleFileUsecase
+}
+
+func NewSuppressionHandler(suppressionUsecase domain.SuppressionUsecase, ruleFileUsecase domain.RuleFileUsecase) *SuppressionHandler {
+	return &SuppressionHandler{
+		suppressionUsecase: suppressionUsecase,
+		ruleFileUsecase:    ruleFileUsecase,
+	}
+}
+
+func (h *SuppressionHandler) FetchSuppressions(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	suppressions, err := h.suppressionUsecase.FetchSuppressions(c.Context())
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch suppressions")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch suppressions"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(suppressions))
+}
+
+func (h *SuppressionHandler) FetchSuppressionByID(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid suppression ID parameter"))
+	}
+
+	suppression, err := h.suppressionUsecase.FetchSuppressionByID(c.Context(), id)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch suppression")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch suppression"))
+	}
+
+	if suppression == nil {
+		return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Suppression not found"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(suppression))
+}
+
+func (h *SuppressionHandler) PreviewSuppressionXML(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid suppression ID parameter"))
+	}
+
+	ruleXML, err := h.suppressionUsecase.PreviewSuppressionXML(c.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(model.NewResponseError("Suppression not found"))
+		}
+
+		log.WithError(err).Error("[handler]: Failed to render suppression XML")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to render suppression XML"))
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).SendString(ruleXML)
+}
+
+func (h *SuppressionHandler) FetchRuleFileVersions(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	filename := c.Params("filename")
+	if filename == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Missing filename parameter"))
+	}
+
+	versions, err := h.ruleFileUsecase.FetchRuleFileVersions(c.Context(), filename)
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to fetch rule file versions")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch rule file versions"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(versions))
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SuppressionMinerHandler struct {
+	suppressionMinerUsecase domain.SuppressionMinerUsecase
+}
+
+func NewSuppressionMinerHandler(suppressionMinerUsecase domain.SuppressionMinerUsecase) *SuppressionMinerHandler {
+	return &SuppressionMinerHandler{
+		suppressionMinerUsecase: suppressionMinerUsecase,
+	}
+}
+
+func (h *SuppressionMinerHandler) MineSuppressions(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	candidates, err := h.suppressionMinerUsecase.MineSuppressions(c.Context(), c.QueryBool("dry_run", false))
+	if err != nil {
+		log.WithError(err).Error("[handler]: Failed to mine suppression proposals")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to mine suppression proposals"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(candidates))
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TimelineHandler struct {
+	timelineUsecase domain.TimelineUsecase
+}
+
+func NewTimelineHandler(timelineUsecase domain.TimelineUsecase) *TimelineHandler {
+	return &TimelineHandler{
+		timelineUsecase: timelineUsecase,
+	}
+}
+
+// FetchAgentTimeline returns one page of the alerts, triage actions and maintenance windows of an agent
+func (h *TimelineHandler) FetchAgentTimeline(c *fiber.Ctx) error {
+	log := logger.WithRequestID(c.Context())
+
+	agentID := strings.TrimSpace(c.Params("id"))
+	if agentID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid agent ID parameter"))
+	}
+
+	req := &model.AgentTimelineRequest{
+		MinLevel: c.QueryInt("min_level"),
+		RuleID:   strings.TrimSpace(c.Query("rule_id")),
+		Cursor:   c.Query("cursor"),
+		Limit:    c.QueryInt("limit"),
+	}
+	if types := c.Query("types"); types != "" {
+		req.Types = strings.Split(strings.ToLower(types), ",")
+	}
+
+	from, ok := parseReportTime(c.Query("from"), false)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid from, expected a date such as 2026-01-31 or an RFC 3339 time"))
+	}
+	to, ok := parseReportTime(c.Query("to"), true)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError("Invalid to, expected a date such as 2026-01-31 or an RFC 3339 time"))
+	}
+	req.From, req.To = from, to
+
+	timeline, err := h.timelineUsecase.FetchAgentTimeline(c.Context(), agentID, req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NewResponseError(err.Error()))
+		}
+		log.WithError(err).Error("[handler]: Failed to fetch agent timeline")
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NewResponseError("Failed to fetch agent timeline"))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.NewResponseSuccess(timeline))
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type RuleAnalyticsRequest struct {
+	Window time.Duration
+	Limit  int
+}
+
+type CountryAnalyticsRequest struct {
+	Window time.Duration
+	Limit  int
+}
+
+type MitreCoverageRequest struct {
+	Window time.Duration
+}
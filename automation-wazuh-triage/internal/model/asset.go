@@ -0,0 +1,30 @@
+package model
+
+// AssetRequest creates or replaces an asset managed through the API. At least one of agent_id, hostname and
+// ip is required.
+type AssetRequest struct {
+	Name        string   `json:"name"` // defaults to the hostname, agent ID or IP
+	AgentID     string   `json:"agent_id"`
+	Hostname    string   `json:"hostname"`
+	IP          string   `json:"ip"` // address or CIDR
+	Owner       string   `json:"owner"`
+	Criticality string   `json:"criticality"` // low, medium, high or critical
+	Environment string   `json:"environment"`
+	Tags        []string `json:"tags"`
+	Analyst     string   `json:"analyst"`
+}
+
+type FetchAssetsRequest struct {
+	Criticality string
+	Environment string
+	Tag         string
+	Source      string
+}
+
+// AssetCondition restricts auto-close to events from matching assets. Each given list must contain the
+// asset's value; events without an inventory entry never match.
+type AssetCondition struct {
+	Criticalities []string `json:"criticalities,omitempty"`
+	Environments  []string `json:"environments,omitempty"`
+	Tags          []string `json:"tags,omitempty"` // any of the tags
+}
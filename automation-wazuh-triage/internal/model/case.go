@@ -0,0 +1,30 @@
+package model
+
+import "automation-wazuh-triage/internal/entity"
+
+type UpdateCorrelationConfigRequest struct {
+	Keys      []string `json:"keys"`   // replaces the keys when given
+	Window    *string  `json:"window"` // duration such as 30m
+	UpdatedBy string   `json:"updated_by"`
+}
+
+type EscalateCaseRequest struct {
+	Reason  string `json:"reason"`
+	Analyst string `json:"analyst"`
+}
+
+type CloseCaseRequest struct {
+	Reason  string `json:"reason"`
+	Label   string `json:"label,omitempty"` // optional false_positive or true_positive, copied to every closed alert
+	Analyst string `json:"analyst"`
+}
+
+type CaseAlertResponse struct {
+	*entity.CaseAlert
+	RawEvent interface{} `json:"raw_event"`
+}
+
+type CaseDetailResponse struct {
+	*entity.Case
+	Alerts []CaseAlertResponse `json:"alerts"`
+}
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type ComplianceReportRequest struct {
+	Framework string
+	From      time.Time
+	To        time.Time
+}
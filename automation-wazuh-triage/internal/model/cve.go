@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+type VulnerabilityReportRequest struct {
+	Window  time.Duration
+	Limit   int
+	AgentID string // only this agent, with all its vulnerabilities
+}
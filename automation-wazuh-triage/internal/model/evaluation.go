@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+type DecisionSampleRequest struct {
+	Window    time.Duration
+	Size      int
+	Mode      string
+	RuleID    string
+	Criterion string
+}
+
+type LabelDecisionRequest struct {
+	Label   string `json:"label"`   // false_positive when closing was right, true_positive when a real attack was closed
+	Analyst string `json:"analyst"` // who labeled the decision
+}
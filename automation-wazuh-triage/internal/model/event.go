@@ -10,6 +10,13 @@ type FetchEventsRequest struct {
 	LevelRange     *RangeQuery `json:"level_range,omitempty"`
 	Limit          int         `json:"limit,omitempty"`
 	AutoAddToClose bool        `json:"auto_add_to_close,omitempty"`
+	AutoCloseMode  string      `json:"auto_close_mode,omitempty"` // enforce or shadow, defaults to AUTO_CLOSE_MODE
+	Collapse       bool        `json:"collapse,omitempty"`        // fingerprint groups of the matching alerts instead of events
+	CollapseWindow string      `json:"collapse_window,omitempty"` // how far back alerts are grouped, defaults to 24h
+	CollapseCursor string      `json:"collapse_cursor,omitempty"` // next_cursor of the previous page of groups
+
+	// Asset restricts auto-close to events whose agent is an inventory asset matching the condition
+	Asset *AssetCondition `json:"asset,omitempty"`
 }
 
 type RangeQuery struct {
@@ -20,45 +27,67 @@ type RangeQuery struct {
 }
 
 type CloseEventRequest struct {
-	Reason string `json:"reason"`
+	Reason  string `json:"reason"`
+	Label   string `json:"label,omitempty"`   // optional false_positive or true_positive
+	Analyst string `json:"analyst,omitempty"` // who closed the event, used for per-analyst KPIs
+}
+
+type AcknowledgeEventRequest struct {
+	Analyst string `json:"analyst"`
 }
 
 type UpdateClosedEventReasonRequest struct {
 	Reason string `json:"reason"`
 }
 
+type UpdateClosedEventLabelRequest struct {
+	Label string `json:"label"` // false_positive, true_positive or empty to clear
+}
+
 type ClosedEventResponse struct {
-	ID       int         `json:"id"`
-	EventID  string      `json:"event_id"`
-	RuleID   string      `json:"rule_id"`
-	RawEvent interface{} `json:"raw_event"` // This will hold the parsed JSON
-	Reason   string      `json:"reason"`
-	Status   string      `json:"status"`
-	CloseAt  time.Time   `json:"close_at"`
+	ID        int         `json:"id"`
+	EventID   string      `json:"event_id"`
+	RuleID    string      `json:"rule_id"`
+	RawEvent  interface{} `json:"raw_event"` // This will hold the parsed JSON
+	Reason    string      `json:"reason"`
+	Status    string      `json:"status"`
+	CloseType string      `json:"close_type"`
+	Label     string      `json:"label"`
+	CloseAt   time.Time   `json:"close_at"`
+
+	Asset *entity.AssetMatch `json:"asset,omitempty"`
 }
 
 type ClosedEventDetailResponse struct {
-	ID           int            `json:"id"`
-	EventID      string         `json:"event_id"`
-	RuleID       string         `json:"rule_id"`
-	RawEvent     interface{}    `json:"raw_event"` // This will hold the parsed JSON
-	Reason       string         `json:"reason"`
-	Status       string         `json:"status"`
-	CloseAt      time.Time      `json:"close_at"`
-	Rule         *RuleResponse  `json:"rule,omitempty"`          // Rule detail
-	RuleAffected []RuleResponse `json:"rule_affected,omitempty"` // Related rules from same file
+	ID           int                 `json:"id"`
+	EventID      string              `json:"event_id"`
+	RuleID       string              `json:"rule_id"`
+	RawEvent     interface{}         `json:"raw_event"` // This will hold the parsed JSON
+	Reason       string              `json:"reason"`
+	Status       string              `json:"status"`
+	CloseType    string              `json:"close_type"`
+	Label        string              `json:"label"`
+	CloseAt      time.Time           `json:"close_at"`
+	Asset        *entity.AssetMatch  `json:"asset,omitempty"`
+	Rule         *RuleResponse       `json:"rule,omitempty"`          // Rule detail
+	RuleAffected []RuleResponse      `json:"rule_affected,omitempty"` // Related rules from same file
+	Observables  []entity.Observable `json:"observables"`             // Addresses, domains, URLs, users and hashes of the alert
+	Pivots       []entity.PivotValue `json:"pivots"`                  // Entity values related alerts can be found by
 }
 
 // ConvertClosedEventToResponse converts entity.ClosedEvent to model.ClosedEventResponse
 // and parses the raw_event string into JSON object
 func ConvertClosedEventToResponse(closedEvent *entity.ClosedEvent) (*ClosedEventResponse, error) {
 	response := &ClosedEventResponse{
-		ID:      closedEvent.ID,
-		EventID: closedEvent.EventID,
-		RuleID:  closedEvent.RuleID,
-		Reason:  closedEvent.Reason,
-		Status:  closedEvent.Status,
-		CloseAt: closedEvent.CloseAt,
+		ID:        closedEvent.ID,
+		EventID:   closedEvent.EventID,
+		RuleID:    closedEvent.RuleID,
+		Reason:    closedEvent.Reason,
+		Status:    closedEvent.Status,
+		CloseType: closedEvent.CloseType,
+		Label:     closedEvent.Label,
+		CloseAt:   closedEvent.CloseAt,
+		Asset:     closedEvent.Asset,
 	}
 
 	// Parse raw_event from JSON string to object
@@ -96,12 +125,15 @@ func ConvertClosedEventsToResponse(closedEvents []*entity.ClosedEvent) ([]*Close
 // with extended rule information
 func ConvertClosedEventToDetailResponse(closedEvent *entity.ClosedEvent, rule *entity.WazuhRule, relatedRules []entity.WazuhRule) (*ClosedEventDetailResponse, error) {
 	response := &ClosedEventDetailResponse{
-		ID:      closedEvent.ID,
-		EventID: closedEvent.EventID,
-		RuleID:  closedEvent.RuleID,
-		Reason:  closedEvent.Reason,
-		Status:  closedEvent.Status,
-		CloseAt: closedEvent.CloseAt,
+		ID:        closedEvent.ID,
+		EventID:   closedEvent.EventID,
+		RuleID:    closedEvent.RuleID,
+		Reason:    closedEvent.Reason,
+		Status:    closedEvent.Status,
+		CloseType: closedEvent.CloseType,
+		Label:     closedEvent.Label,
+		CloseAt:   closedEvent.CloseAt,
+		Asset:     closedEvent.Asset,
 	}
 
 	// Parse raw_event from JSON string to object
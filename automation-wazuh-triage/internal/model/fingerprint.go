@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type UpdateFingerprintConfigRequest struct {
+	Fields    []string `json:"fields"` // dotted paths such as rule.id or data.srcip
+	UpdatedBy string   `json:"updated_by"`
+}
+
+// FingerprintGroup is one fingerprint of the alerts matching an event search, represented by its newest alert
+type FingerprintGroup struct {
+	Fingerprint string             `json:"fingerprint"`
+	Count       int64              `json:"count"` // alerts of the window with the fingerprint
+	FirstSeen   time.Time          `json:"first_seen"`
+	LastSeen    time.Time          `json:"last_seen"`
+	Event       *elastic.SearchHit `json:"event"`
+}
+
+// FingerprintGroupPage is one page of the fingerprint groups of an event search
+type FingerprintGroupPage struct {
+	Since      time.Time           `json:"since"`
+	Groups     []*FingerprintGroup `json:"groups"`
+	NextCursor string              `json:"next_cursor,omitempty"` // pass as collapse_cursor for the next page
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type UpdateGuardrailsRequest struct {
+	MaxLevel            *int     `json:"max_level"`               // highest rule level that may be auto-closed, 0 to 16
+	ProtectedRules      []string `json:"protected_rules"`         // replaces the list when given
+	ProtectedGroups     []string `json:"protected_groups"`        // replaces the list when given
+	MaxPerMinutePerRule *int     `json:"max_per_minute_per_rule"` // 0 disables the rate cap
+	BlockIOCMatches     *bool    `json:"block_ioc_matches"`
+	UpdatedBy           string   `json:"updated_by"`
+}
+
+type KillSwitchRequest struct {
+	Engaged *bool  `json:"engaged"`
+	Reason  string `json:"reason"` // required when engaging
+	Actor   string `json:"actor"`
+}
+
+type FetchGuardrailTripsRequest struct {
+	Window    time.Duration
+	Guardrail string
+	RuleID    string
+	Limit     int
+}
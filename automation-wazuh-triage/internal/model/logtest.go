@@ -0,0 +1,9 @@
+package model
+
+type LogtestRequest struct {
+	Logs           []string `json:"logs"`
+	ClosedEventIDs []string `json:"closed_event_ids"`
+	RuleXML        string   `json:"rule_xml"`
+	LogFormat      string   `json:"log_format"`
+	Location       string   `json:"location"`
+}
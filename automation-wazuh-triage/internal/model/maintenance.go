@@ -0,0 +1,29 @@
+package model
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"time"
+)
+
+// MaintenanceWindowRequest creates or replaces a maintenance window. Give starts_at and ends_at for a one-off
+// window, or cron and duration for a recurring one.
+type MaintenanceWindowRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Cron        string     `json:"cron"`
+	Duration    string     `json:"duration"`
+	Timezone    string     `json:"timezone"` // default UTC
+	AgentIDs    []string   `json:"agent_ids"`
+	AgentGroups []string   `json:"agent_groups"`
+	AgentNames  []string   `json:"agent_names"`
+	Policy      string     `json:"policy"`
+	Enabled     *bool      `json:"enabled"` // default true
+	Analyst     string     `json:"analyst"`
+}
+
+type MaintenanceWindowDetailResponse struct {
+	*entity.MaintenanceWindow
+	Alerts []*entity.MaintenanceAlert `json:"alerts"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type ObservablesRequest struct {
+	Window          time.Duration
+	Limit           int
+	Type            string // only observables of this type
+	AgentID         string // only alerts of this agent
+	RuleID          string // only alerts of this rule
+	ExcludeInternal bool   // leave out private, loopback and link-local addresses
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type RelatedAlertsRequest struct {
+	Field  string        // srcip, agent.id, user, file or hash
+	Window time.Duration // search this long before and after the alert
+	Limit  int
+	Status string // only related alerts with this triage status
+}
@@ -0,0 +1,29 @@
+package model
+
+import "automation-wazuh-triage/internal/entity"
+
+type CreateProposalRequest struct {
+	Type             string               `json:"type"`
+	Title            string               `json:"title"`
+	Description      string               `json:"description"`
+	Author           string               `json:"-"`                     // the authenticated caller, or the miner
+	Suppression      *ProposalSuppression `json:"suppression,omitempty"` // type suppression
+	Filename         string               `json:"filename,omitempty"`    // type rule_change
+	Content          string               `json:"content,omitempty"`     // type rule_change, full new file content
+	EvidenceEventIDs []string             `json:"evidence_event_ids"`
+}
+
+type ProposalSuppression struct {
+	ParentRuleID int                           `json:"parent_rule_id"`
+	Conditions   []entity.SuppressionCondition `json:"conditions"`
+}
+
+type ProposalActionRequest struct {
+	Comment string `json:"comment"`
+}
+
+type ProposalDetailResponse struct {
+	*entity.Proposal
+	Suppression *entity.Suppression    `json:"suppression,omitempty"`
+	Evidence    []*ClosedEventResponse `json:"evidence"`
+}
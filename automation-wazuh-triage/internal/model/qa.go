@@ -0,0 +1,19 @@
+package model
+
+import "automation-wazuh-triage/internal/entity"
+
+type UpdateQASamplingPolicyRequest struct {
+	RatePercent *float64 `json:"rate_percent"` // share of each rule's daily auto-closures to review, 0 < rate <= 100
+	MinPerRule  *int     `json:"min_per_rule"` // reviews per rule and day even when the rate rounds lower
+	UpdatedBy   string   `json:"updated_by"`   // who changed the policy
+}
+
+type QAReviewActionRequest struct {
+	Reviewer string `json:"reviewer"`
+	Note     string `json:"note,omitempty"`
+}
+
+type QAReviewResponse struct {
+	*entity.QAReview
+	RawEvent interface{} `json:"raw_event"` // the stored search hit, parsed
+}
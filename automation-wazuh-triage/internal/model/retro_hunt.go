@@ -0,0 +1,15 @@
+package model
+
+type CreateRetroHuntRequest struct {
+	Name            string   `json:"name"`
+	Indicators      []string `json:"indicators"`       // addresses, domains and MD5, SHA-1 or SHA-256 hashes
+	Window          string   `json:"window"`           // how far back to search, such as 720h; 90 days by default
+	IncludeArchives *bool    `json:"include_archives"` // search RETRO_HUNT_ARCHIVE_INDEX too; by default when it is set
+	CreatedBy       string   `json:"created_by"`
+}
+
+type RetroHuntMatchFilter struct {
+	Indicator   string // only matches of this indicator
+	TriageState string // only matches in this state: open, acknowledged, closed or archived
+	AutoClosed  bool   // only matches whose alert was auto-closed
+}
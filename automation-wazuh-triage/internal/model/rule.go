@@ -19,6 +19,7 @@ type RuleResponse struct {
 	Mitre           []string                `json:"mitre"`
 	Groups          []string                `json:"groups"`
 	Description     string                  `json:"description"`
+	MitreTechniques []entity.MitreTechnique `json:"mitre_techniques"`
 }
 
 // ConvertWazuhRuleToResponse converts entity.WazuhRule to model.RuleResponse
@@ -39,6 +40,7 @@ func ConvertWazuhRuleToResponse(wazuhRule *entity.WazuhRule) *RuleResponse {
 		Mitre:           wazuhRule.Mitre,
 		Groups:          wazuhRule.Groups,
 		Description:     wazuhRule.Description,
+		MitreTechniques: wazuhRule.MitreTechniques,
 	}
 }
 
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+type UpdateSequenceRulesRequest struct {
+	YAML      string `json:"yaml"` // replaces all rules, empty removes them
+	UpdatedBy string `json:"updated_by"`
+}
+
+type FetchSequenceFindingsRequest struct {
+	Window   time.Duration
+	RuleName string
+	Limit    int
+}
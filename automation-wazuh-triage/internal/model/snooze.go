@@ -0,0 +1,26 @@
+package model
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"time"
+)
+
+type CreateSnoozeRequest struct {
+	RuleID      string     `json:"rule_id"`
+	AgentID     string     `json:"agent_id"`
+	Fingerprint string     `json:"fingerprint"`
+	MaxLevel    int        `json:"max_level"`  // a matching alert above this level ends the snooze, 0 for none
+	Duration    string     `json:"duration"`   // such as 2h, or give expires_at
+	ExpiresAt   *time.Time `json:"expires_at"` // used when duration is empty
+	Reason      string     `json:"reason"`
+	CreatedBy   string     `json:"created_by"`
+}
+
+type CancelSnoozeRequest struct {
+	Analyst string `json:"analyst"`
+}
+
+type SnoozeDetailResponse struct {
+	*entity.Snooze
+	Alerts []*entity.SnoozedAlert `json:"alerts"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type AgentTimelineRequest struct {
+	From     time.Time
+	To       time.Time
+	Types    []string // alert, triage or maintenance; all when empty
+	MinLevel int      // only alerts at or above this rule level
+	RuleID   string   // only alerts of this rule and actions on them
+	Cursor   string   // next_cursor of the previous page
+	Limit    int
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/wazuh"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// agentsPageSize is the number of agents requested per page when walking a group
+const agentsPageSize = 500
+
+type agentRepository struct {
+}
+
+func NewAgentRepository() domain.AgentRepository {
+	return &agentRepository{}
+}
+
+// FetchAgentsByGroup returns every agent of a Wazuh agent group
+func (r *agentRepository) FetchAgentsByGroup(ctx context.Context, group string) ([]entity.WazuhAgent, error) {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	var agents []entity.WazuhAgent
+	offset := 0
+
+	for {
+		queryString := fmt.Sprintf("group=%s&select=id,name,ip,group&limit=%d&offset=%d&sort=+id", url.QueryEscape(group), agentsPageSize, offset)
+
+		responseBytes, err := client.GetAgents(queryString)
+		if err != nil {
+			log.WithError(err).WithField("group", group).Error("[repository - agent - FetchAgentsByGroup]: Failed to get agents page")
+			return nil, err
+		}
+
+		var apiResponse entity.WazuhAgentsAPIResponse
+		if err := json.Unmarshal(responseBytes, &apiResponse); err != nil {
+			log.WithError(err).Error("[repository - agent - FetchAgentsByGroup]: Failed to unmarshal Wazuh API response")
+			return nil, err
+		}
+
+		if apiResponse.Error != 0 {
+			log.WithField("wazuh_error", apiResponse.Error).WithField("message", apiResponse.Message).Error("[repository - agent - FetchAgentsByGroup]: Wazuh API returned error")
+			return nil, fmt.Errorf("wazuh API returned error %d: %s", apiResponse.Error, apiResponse.Message)
+		}
+
+		agents = append(agents, apiResponse.Data.AffectedItems...)
+		offset += len(apiResponse.Data.AffectedItems)
+
+		if len(apiResponse.Data.AffectedItems) == 0 || offset >= apiResponse.Data.TotalAffectedItems {
+			break
+		}
+	}
+
+	return agents, nil
+}
+
+// FetchAgentByID returns an agent, or nil when Wazuh does not know it
+func (r *agentRepository) FetchAgentByID(ctx context.Context, agentID string) (*entity.WazuhAgent, error) {
+	log := logger.WithRequestID(ctx)
+
+	queryString := fmt.Sprintf("agents_list=%s&select=id,name,ip,group", url.QueryEscape(agentID))
+
+	responseBytes, err := wazuh.NewWazuh().GetAgents(queryString)
+	if err != nil {
+		log.WithError(err).WithField("agent_id", agentID).Error("[repository - agent - FetchAgentByID]: Failed to get agent")
+		return nil, err
+	}
+
+	var apiResponse entity.WazuhAgentsAPIResponse
+	if err := json.Unmarshal(responseBytes, &apiResponse); err != nil {
+		log.WithError(err).Error("[repository - agent - FetchAgentByID]: Failed to unmarshal Wazuh API response")
+		return nil, err
+	}
+
+	// Wazuh reports an unknown agent as a failed item, which sets error 1
+	if len(apiResponse.Data.AffectedItems) == 0 {
+		return nil, nil
+	}
+	return &apiResponse.Data.AffectedItems[0], nil
+}
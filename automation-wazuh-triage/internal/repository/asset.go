@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type assetRepository struct {
+	db *sql.DB
+}
+
+func NewAssetRepository(db *sql.DB) domain.AssetRepository {
+	return &assetRepository{
+		db: db,
+	}
+}
+
+const assetColumns = `id, name, agent_id, hostname, ip, owner, criticality, environment, tags, source, updated_by, updated_at`
+
+// assetExecer is satisfied by both *sql.DB and *sql.Tx
+type assetExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *assetRepository) SaveAsset(ctx context.Context, asset *entity.Asset) error {
+	if err := insertAsset(ctx, r.db, asset); err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("name", asset.Name).Error("[repository - asset - SaveAsset]: Failed to save asset")
+		return err
+	}
+	return nil
+}
+
+// UpdateAsset replaces the asset. It returns sql.ErrNoRows when the asset does not exist.
+func (r *assetRepository) UpdateAsset(ctx context.Context, asset *entity.Asset) error {
+	log := logger.WithRequestID(ctx)
+
+	tags, err := json.Marshal(asset.Tags)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE assets
+		SET name = ?, agent_id = ?, hostname = ?, ip = ?, owner = ?, criticality = ?, environment = ?, tags = ?,
+			source = ?, updated_by = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		asset.Name,
+		asset.AgentID,
+		asset.Hostname,
+		asset.IP,
+		asset.Owner,
+		asset.Criticality,
+		asset.Environment,
+		string(tags),
+		asset.Source,
+		asset.UpdatedBy,
+		asset.UpdatedAt,
+		asset.ID,
+	)
+	if err != nil {
+		log.WithError(err).WithField("asset_id", asset.ID).Error("[repository - asset - UpdateAsset]: Failed to update asset")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteAsset removes the asset. It returns sql.ErrNoRows when the asset does not exist.
+func (r *assetRepository) DeleteAsset(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM assets WHERE id = ?`, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("asset_id", id).Error("[repository - asset - DeleteAsset]: Failed to delete asset")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *assetRepository) FetchAssetByID(ctx context.Context, id int) (*entity.Asset, error) {
+	assets, err := r.fetchAssets(ctx, `SELECT `+assetColumns+` FROM assets WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(assets) == 0 {
+		return nil, nil
+	}
+	return assets[0], nil
+}
+
+// FetchAssets returns the whole inventory, oldest first
+func (r *assetRepository) FetchAssets(ctx context.Context) ([]*entity.Asset, error) {
+	return r.fetchAssets(ctx, `SELECT `+assetColumns+` FROM assets ORDER BY id ASC`)
+}
+
+// ReplaceFileAssets swaps the assets loaded from the inventory file for the given ones in one transaction,
+// leaving the assets managed through the API untouched
+func (r *assetRepository) ReplaceFileAssets(ctx context.Context, assets []*entity.Asset) error {
+	log := logger.WithRequestID(ctx)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("[repository - asset - ReplaceFileAssets]: Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM assets WHERE source = ?`, entity.AssetSourceFile); err != nil {
+		log.WithError(err).Error("[repository - asset - ReplaceFileAssets]: Failed to delete file assets")
+		return err
+	}
+
+	for _, asset := range assets {
+		if err := insertAsset(ctx, tx, asset); err != nil {
+			log.WithError(err).WithField("name", asset.Name).Error("[repository - asset - ReplaceFileAssets]: Failed to save asset")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertAsset(ctx context.Context, db assetExecer, asset *entity.Asset) error {
+	tags, err := json.Marshal(asset.Tags)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO assets (name, agent_id, hostname, ip, owner, criticality, environment, tags, source, updated_by,
+			updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		asset.Name,
+		asset.AgentID,
+		asset.Hostname,
+		asset.IP,
+		asset.Owner,
+		asset.Criticality,
+		asset.Environment,
+		string(tags),
+		asset.Source,
+		asset.UpdatedBy,
+		asset.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	asset.ID = int(id)
+
+	return nil
+}
+
+func (r *assetRepository) fetchAssets(ctx context.Context, query string, args ...interface{}) ([]*entity.Asset, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - asset - fetchAssets]: Failed to fetch assets")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var assets []*entity.Asset
+
+	for rows.Next() {
+		var asset entity.Asset
+		var tags string
+
+		if err := rows.Scan(
+			&asset.ID,
+			&asset.Name,
+			&asset.AgentID,
+			&asset.Hostname,
+			&asset.IP,
+			&asset.Owner,
+			&asset.Criticality,
+			&asset.Environment,
+			&tags,
+			&asset.Source,
+			&asset.UpdatedBy,
+			&asset.UpdatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - asset - fetchAssets]: Failed to scan asset")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(tags), &asset.Tags); err != nil {
+			log.WithError(err).WithField("asset_id", asset.ID).Warn("[repository - asset - fetchAssets]: Failed to parse asset tags")
+		}
+		if asset.Tags == nil {
+			asset.Tags = []string{}
+		}
+
+		assets = append(assets, &asset)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - asset - fetchAssets]: Error iterating rows")
+		return nil, err
+	}
+
+	return assets, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type autoCloseDecisionRepository struct {
+	db *sql.DB
+}
+
+func NewAutoCloseDecisionRepository(db *sql.DB) domain.AutoCloseDecisionRepository {
+	return &autoCloseDecisionRepository{
+		db: db,
+	}
+}
+
+// autoCloseDecisionColumns selects the effective label: a label set on the decision wins over the label an
+// analyst put on the closed event, which covers shadow decisions on events that were later closed by hand
+const autoCloseDecisionColumns = `d.id, d.event_id, d.rule_id, d.rule_level, d.criterion, d.criterion_filter, d.mode,
+	d.closed_event_id, d.raw_event, COALESCE(NULLIF(d.label, ''), ce.label, ''), d.labeled_by, d.labeled_at, d.decided_at`
+
+const autoCloseDecisionFrom = `auto_close_decisions d LEFT JOIN closed_events ce ON ce.event_id = d.event_id`
+
+// SaveDecision stores the decision unless the event already has one in the same mode, and reports whether it was stored
+func (r *autoCloseDecisionRepository) SaveDecision(ctx context.Context, decision *entity.AutoCloseDecision) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO auto_close_decisions (event_id, rule_id, rule_level, criterion, criterion_filter, mode,
+			closed_event_id, raw_event, label, labeled_by, labeled_at, decided_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		decision.EventID,
+		decision.RuleID,
+		decision.RuleLevel,
+		decision.Criterion,
+		decision.CriterionFilter,
+		decision.Mode,
+		decision.ClosedEventID,
+		decision.RawEvent,
+		decision.Label,
+		decision.LabeledBy,
+		decision.LabeledAt,
+		decision.DecidedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("event_id", decision.EventID).Error("[repository - auto_close_decision - SaveDecision]: Failed to save decision")
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	decision.ID = int(id)
+
+	return true, nil
+}
+
+func (r *autoCloseDecisionRepository) FetchDecisionByID(ctx context.Context, id int) (*entity.AutoCloseDecision, error) {
+	decisions, err := r.fetchDecisions(ctx, `
+		SELECT `+autoCloseDecisionColumns+`
+		FROM `+autoCloseDecisionFrom+`
+		WHERE d.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decisions) == 0 {
+		return nil, nil
+	}
+	return decisions[0], nil
+}
+
+// FetchDecisionByEventID returns the decision auto-close made on an event in the given mode, or nil
+func (r *autoCloseDecisionRepository) FetchDecisionByEventID(ctx context.Context, eventID string, mode string) (*entity.AutoCloseDecision, error) {
+	decisions, err := r.fetchDecisions(ctx, `
+		SELECT `+autoCloseDecisionColumns+`
+		FROM `+autoCloseDecisionFrom+`
+		WHERE d.event_id = ? AND d.mode = ?
+	`, eventID, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decisions) == 0 {
+		return nil, nil
+	}
+	return decisions[0], nil
+}
+
+// FetchDecisionsSince returns the decisions made since the given time, in one mode or both when mode is empty
+func (r *autoCloseDecisionRepository) FetchDecisionsSince(ctx context.Context, since time.Time, mode string) ([]*entity.AutoCloseDecision, error) {
+	query := `
+		SELECT ` + autoCloseDecisionColumns + `
+		FROM ` + autoCloseDecisionFrom + `
+		WHERE d.decided_at >= ?`
+	args := []interface{}{since}
+
+	if mode != "" {
+		query += ` AND d.mode = ?`
+		args = append(args, mode)
+	}
+
+	return r.fetchDecisions(ctx, query+` ORDER BY d.decided_at DESC`, args...)
+}
+
+// FetchUnlabeledDecisionSample returns a random sample of decisions no analyst has labeled yet
+func (r *autoCloseDecisionRepository) FetchUnlabeledDecisionSample(ctx context.Context, request *model.DecisionSampleRequest, since time.Time) ([]*entity.AutoCloseDecision, error) {
+	conditions := []string{"d.decided_at >= ?", "COALESCE(NULLIF(d.label, ''), ce.label, '') = ''"}
+	args := []interface{}{since}
+
+	if request.Mode != "" {
+		conditions = append(conditions, "d.mode = ?")
+		args = append(args, request.Mode)
+	}
+	if request.RuleID != "" {
+		conditions = append(conditions, "d.rule_id = ?")
+		args = append(args, request.RuleID)
+	}
+	if request.Criterion != "" {
+		conditions = append(conditions, "d.criterion = ?")
+		args = append(args, request.Criterion)
+	}
+
+	args = append(args, request.Size)
+
+	return r.fetchDecisions(ctx, `
+		SELECT `+autoCloseDecisionColumns+`
+		FROM `+autoCloseDecisionFrom+`
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY RANDOM()
+		LIMIT ?
+	`, args...)
+}
+
+// FetchUndecidedFalsePositiveClosuresSince returns analyst closures labeled false_positive that auto-close never
+// decided on, in one mode or either when mode is empty. They are the closures automation missed.
+func (r *autoCloseDecisionRepository) FetchUndecidedFalsePositiveClosuresSince(ctx context.Context, since time.Time, mode string) ([]*entity.ClosedEvent, error) {
+	log := logger.WithRequestID(ctx)
+
+	query := `
+		SELECT ` + closedEventColumns + `
+		FROM closed_events
+		WHERE close_at >= ? AND close_type = ? AND label = ?
+		AND NOT EXISTS (
+			SELECT 1 FROM auto_close_decisions d
+			WHERE d.event_id = closed_events.event_id AND (? = '' OR d.mode = ?)
+		)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, entity.CloseTypeManual, entity.LabelFalsePositive, mode, mode)
+	if err != nil {
+		log.WithError(err).Error("[repository - auto_close_decision - FetchUndecidedFalsePositiveClosuresSince]: Failed to fetch closed events")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var closedEvents []*entity.ClosedEvent
+
+	for rows.Next() {
+		event, err := scanClosedEvent(rows)
+		if err != nil {
+			log.WithError(err).Error("[repository - auto_close_decision - FetchUndecidedFalsePositiveClosuresSince]: Failed to scan closed event")
+			return nil, err
+		}
+		closedEvents = append(closedEvents, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - auto_close_decision - FetchUndecidedFalsePositiveClosuresSince]: Error iterating rows")
+		return nil, err
+	}
+
+	return closedEvents, nil
+}
+
+func (r *autoCloseDecisionRepository) UpdateDecisionLabel(ctx context.Context, id int, label string, labeledBy string, labeledAt time.Time) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE auto_close_decisions
+		SET label = ?, labeled_by = ?, labeled_at = ?
+		WHERE id = ?
+	`, label, labeledBy, labeledAt, id)
+	if err != nil {
+		log.WithError(err).WithField("decision_id", id).Error("[repository - auto_close_decision - UpdateDecisionLabel]: Failed to update decision label")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *autoCloseDecisionRepository) fetchDecisions(ctx context.Context, query string, args ...interface{}) ([]*entity.AutoCloseDecision, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - auto_close_decision - fetchDecisions]: Failed to fetch decisions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decisions []*entity.AutoCloseDecision
+
+	for rows.Next() {
+		var decision entity.AutoCloseDecision
+		var rawEvent sql.NullString
+		var labeledAt sql.NullTime
+
+		if err := rows.Scan(
+			&decision.ID,
+			&decision.EventID,
+			&decision.RuleID,
+			&decision.RuleLevel,
+			&decision.Criterion,
+			&decision.CriterionFilter,
+			&decision.Mode,
+			&decision.ClosedEventID,
+			&rawEvent,
+			&decision.Label,
+			&decision.LabeledBy,
+			&labeledAt,
+			&decision.DecidedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - auto_close_decision - fetchDecisions]: Failed to scan decision")
+			return nil, err
+		}
+
+		decision.RawEvent = rawEvent.String
+		decision.LabeledAt = nullTimePtr(labeledAt)
+		decisions = append(decisions, &decision)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - auto_close_decision - fetchDecisions]: Error iterating rows")
+		return nil, err
+	}
+
+	return decisions, nil
+}
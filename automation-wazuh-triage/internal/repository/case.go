@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+type caseRepository struct {
+	db *sql.DB
+}
+
+func NewCaseRepository(db *sql.DB) domain.CaseRepository {
+	return &caseRepository{
+		db: db,
+	}
+}
+
+const caseColumns = `c.id, c.correlation_key, c.key_values, c.title, c.status, c.max_level, c.alert_count,
+	(SELECT GROUP_CONCAT(DISTINCT a.rule_id) FROM case_alerts a WHERE a.case_id = c.id),
+	c.low_priority, c.high_priority, c.first_seen, c.last_seen, c.escalated_by, c.escalate_reason, c.escalated_at, c.closed_by, c.close_reason,
+	c.label, c.closed_at, c.created_at, c.updated_at`
+
+const caseAlertColumns = "id, case_id, event_id, rule_id, rule_level, agent_name, raw_event, alert_at, added_at"
+
+func (r *caseRepository) SaveCase(ctx context.Context, c *entity.Case) error {
+	log := logger.WithRequestID(ctx)
+
+	keyValues, err := json.Marshal(c.KeyValues)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO cases (correlation_key, key_values, title, status, max_level, alert_count, low_priority,
+			high_priority, first_seen, last_seen, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		c.CorrelationKey,
+		string(keyValues),
+		c.Title,
+		c.Status,
+		c.MaxLevel,
+		c.AlertCount,
+		c.LowPriority,
+		c.HighPriority,
+		c.FirstSeen,
+		c.LastSeen,
+		c.CreatedAt,
+		c.UpdatedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("correlation_key", c.CorrelationKey).Error("[repository - case - SaveCase]: Failed to save case")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	c.ID = int(id)
+
+	return nil
+}
+
+func (r *caseRepository) FetchCaseByID(ctx context.Context, id int) (*entity.Case, error) {
+	cases, err := r.fetchCases(ctx, `
+		SELECT `+caseColumns+`
+		FROM cases c
+		WHERE c.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cases) == 0 {
+		return nil, nil
+	}
+	return cases[0], nil
+}
+
+// FetchCases returns the cases most recently active first, low priority cases last, optionally narrowed to one status and correlation key
+func (r *caseRepository) FetchCases(ctx context.Context, status string, correlationKey string) ([]*entity.Case, error) {
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+
+	if status != "" {
+		conditions = append(conditions, "c.status = ?")
+		args = append(args, status)
+	}
+	if correlationKey != "" {
+		conditions = append(conditions, "c.correlation_key = ?")
+		args = append(args, correlationKey)
+	}
+
+	return r.fetchCases(ctx, `
+		SELECT `+caseColumns+`
+		FROM cases c
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY c.high_priority DESC, c.low_priority ASC, c.last_seen DESC, c.id DESC
+	`, args...)
+}
+
+// FetchActiveCaseByKey returns the open or escalated case of the correlation key whose last alert fired
+// at or after since, or nil when there is none
+func (r *caseRepository) FetchActiveCaseByKey(ctx context.Context, correlationKey string, since time.Time) (*entity.Case, error) {
+	cases, err := r.fetchCases(ctx, `
+		SELECT `+caseColumns+`
+		FROM cases c
+		WHERE c.correlation_key = ? AND c.status IN (?, ?) AND c.last_seen >= ?
+		ORDER BY c.last_seen DESC
+		LIMIT 1
+	`, correlationKey, entity.CaseStatusOpen, entity.CaseStatusEscalated, since)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cases) == 0 {
+		return nil, nil
+	}
+	return cases[0], nil
+}
+
+// UpdateCaseActivity stores the alert count, level, priority and seen times of a case after alerts were added
+func (r *caseRepository) UpdateCaseActivity(ctx context.Context, c *entity.Case) error {
+	log := logger.WithRequestID(ctx)
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE cases
+		SET max_level = ?, alert_count = ?, high_priority = ?, first_seen = ?, last_seen = ?, updated_at = ?
+		WHERE id = ?
+	`, c.MaxLevel, c.AlertCount, c.HighPriority, c.FirstSeen, c.LastSeen, c.UpdatedAt, c.ID)
+	if err != nil {
+		log.WithError(err).WithField("case_id", c.ID).Error("[repository - case - UpdateCaseActivity]: Failed to update case")
+		return err
+	}
+
+	return nil
+}
+
+// EscalateCase moves an open case to escalated. It returns sql.ErrNoRows when the case is not open.
+func (r *caseRepository) EscalateCase(ctx context.Context, id int, escalatedBy string, reason string, escalatedAt time.Time) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE cases
+		SET status = ?, escalated_by = ?, escalate_reason = ?, escalated_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, entity.CaseStatusEscalated, escalatedBy, reason, escalatedAt, escalatedAt, id, entity.CaseStatusOpen)
+	if err != nil {
+		log.WithError(err).WithField("case_id", id).Error("[repository - case - EscalateCase]: Failed to escalate case")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CloseCase closes an open or escalated case. It returns sql.ErrNoRows when the case is already closed.
+func (r *caseRepository) CloseCase(ctx context.Context, id int, closedBy string, reason string, label string, closedAt time.Time) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE cases
+		SET status = ?, closed_by = ?, close_reason = ?, label = ?, closed_at = ?, updated_at = ?
+		WHERE id = ? AND status != ?
+	`, entity.CaseStatusClosed, closedBy, reason, label, closedAt, closedAt, id, entity.CaseStatusClosed)
+	if err != nil {
+		log.WithError(err).WithField("case_id", id).Error("[repository - case - CloseCase]: Failed to close case")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SaveCaseAlert adds the alert to its case unless it already belongs to a case, and reports whether it was added
+func (r *caseRepository) SaveCaseAlert(ctx context.Context, alert *entity.CaseAlert) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO case_alerts (case_id, event_id, rule_id, rule_level, agent_name, raw_event, alert_at, added_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		alert.CaseID,
+		alert.EventID,
+		alert.RuleID,
+		alert.RuleLevel,
+		alert.AgentName,
+		alert.RawEvent,
+		alert.AlertAt,
+		alert.AddedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("event_id", alert.EventID).Error("[repository - case - SaveCaseAlert]: Failed to save case alert")
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	alert.ID = int(id)
+
+	return true, nil
+}
+
+// FetchCaseIDByEventID returns the case the alert belongs to, or 0 when it is in no case
+func (r *caseRepository) FetchCaseIDByEventID(ctx context.Context, eventID string) (int, error) {
+	var caseID int
+
+	err := r.db.QueryRowContext(ctx, `SELECT case_id FROM case_alerts WHERE event_id = ?`, eventID).Scan(&caseID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("event_id", eventID).Error("[repository - case - FetchCaseIDByEventID]: Failed to fetch case of alert")
+		return 0, err
+	}
+
+	return caseID, nil
+}
+
+// FetchCaseAlerts returns the alerts of a case oldest first
+func (r *caseRepository) FetchCaseAlerts(ctx context.Context, caseID int) ([]*entity.CaseAlert, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+caseAlertColumns+`
+		FROM case_alerts
+		WHERE case_id = ?
+		ORDER BY alert_at ASC, id ASC
+	`, caseID)
+	if err != nil {
+		log.WithError(err).WithField("case_id", caseID).Error("[repository - case - FetchCaseAlerts]: Failed to fetch case alerts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*entity.CaseAlert
+
+	for rows.Next() {
+		var alert entity.CaseAlert
+		var rawEvent sql.NullString
+
+		if err := rows.Scan(
+			&alert.ID,
+			&alert.CaseID,
+			&alert.EventID,
+			&alert.RuleID,
+			&alert.RuleLevel,
+			&alert.AgentName,
+			&rawEvent,
+			&alert.AlertAt,
+			&alert.AddedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - case - FetchCaseAlerts]: Failed to scan case alert")
+			return nil, err
+		}
+
+		alert.RawEvent = rawEvent.String
+		alerts = append(alerts, &alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - case - FetchCaseAlerts]: Error iterating rows")
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (r *caseRepository) fetchCases(ctx context.Context, query string, args ...interface{}) ([]*entity.Case, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - case - fetchCases]: Failed to fetch cases")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cases []*entity.Case
+
+	for rows.Next() {
+		var c entity.Case
+		var keyValues string
+		var ruleIDs sql.NullString
+		var escalatedAt, closedAt sql.NullTime
+
+		if err := rows.Scan(
+			&c.ID,
+			&c.CorrelationKey,
+			&keyValues,
+			&c.Title,
+			&c.Status,
+			&c.MaxLevel,
+			&c.AlertCount,
+			&ruleIDs,
+			&c.LowPriority,
+			&c.HighPriority,
+			&c.FirstSeen,
+			&c.LastSeen,
+			&c.EscalatedBy,
+			&c.EscalateReason,
+			&escalatedAt,
+			&c.ClosedBy,
+			&c.CloseReason,
+			&c.Label,
+			&closedAt,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - case - fetchCases]: Failed to scan case")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(keyValues), &c.KeyValues); err != nil {
+			log.WithError(err).WithField("case_id", c.ID).Warn("[repository - case - fetchCases]: Failed to parse case key values")
+		}
+
+		c.RuleIDs = []string{}
+		if ruleIDs.String != "" {
+			c.RuleIDs = strings.Split(ruleIDs.String, ",")
+			sort.Strings(c.RuleIDs)
+		}
+
+		c.EscalatedAt = nullTimePtr(escalatedAt)
+		c.ClosedAt = nullTimePtr(closedAt)
+		cases = append(cases, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - case - fetchCases]: Error iterating rows")
+		return nil, err
+	}
+
+	return cases, nil
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type cveRepository struct {
+	db *sql.DB
+}
+
+func NewCVERepository(db *sql.DB) domain.CVERepository {
+	return &cveRepository{
+		db: db,
+	}
+}
+
+const cveColumns = `cve_id, description, published, last_modified, cvss_metrics, cwes, refs, epss, kev, updated_at`
+
+// SaveCVEs inserts or updates the NVD fields of the CVEs in one transaction, keeping their KEV and EPSS data
+func (r *cveRepository) SaveCVEs(ctx context.Context, cves []*entity.CVE) error {
+	log := logger.WithRequestID(ctx)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("[repository - cve - SaveCVEs]: Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO cves (cve_id, description, published, last_modified, cvss_score, cvss_metrics, cwes, refs, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(cve_id) DO UPDATE SET
+			description = excluded.description,
+			published = excluded.published,
+			last_modified = excluded.last_modified,
+			cvss_score = excluded.cvss_score,
+			cvss_metrics = excluded.cvss_metrics,
+			cwes = excluded.cwes,
+			refs = excluded.refs,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		log.WithError(err).Error("[repository - cve - SaveCVEs]: Failed to prepare statement")
+		return err
+	}
+	defer stmt.Close()
+
+	for _, cve := range cves {
+		metrics, err := json.Marshal(cve.CVSSMetrics)
+		if err != nil {
+			return err
+		}
+		cwes, err := json.Marshal(cve.CWEs)
+		if err != nil {
+			return err
+		}
+		references, err := json.Marshal(cve.References)
+		if err != nil {
+			return err
+		}
+
+		score := 0.0
+		if cve.CVSS != nil {
+			score = cve.CVSS.BaseScore
+		}
+
+		if _, err := stmt.ExecContext(ctx, cve.ID, cve.Description, cve.Published, cve.LastModified, score, string(metrics), string(cwes), string(references), cve.UpdatedAt); err != nil {
+			log.WithError(err).WithField("cve_id", cve.ID).Error("[repository - cve - SaveCVEs]: Failed to save CVE")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceKEV marks exactly the given CVEs as known exploited, adding the CVEs missing from the NVD data
+func (r *cveRepository) ReplaceKEV(ctx context.Context, entries map[string]*entity.KEVEntry) error {
+	values := make(map[string]interface{}, len(entries))
+	for id, entry := range entries {
+		values[id] = entry
+	}
+	return r.replaceColumn(ctx, "kev", values)
+}
+
+// ReplaceEPSS sets the EPSS scores of exactly the given CVEs, adding the CVEs missing from the NVD data
+func (r *cveRepository) ReplaceEPSS(ctx context.Context, scores map[string]*entity.EPSSScore) error {
+	values := make(map[string]interface{}, len(scores))
+	for id, score := range scores {
+		values[id] = score
+	}
+	return r.replaceColumn(ctx, "epss", values)
+}
+
+// replaceColumn clears a JSON column of every CVE and sets it for the given ones, in one transaction
+func (r *cveRepository) replaceColumn(ctx context.Context, column string, values map[string]interface{}) error {
+	log := logger.WithRequestID(ctx).WithField("column", column)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("[repository - cve - replaceColumn]: Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE cves SET `+column+` = '' WHERE `+column+` != ''`); err != nil {
+		log.WithError(err).Error("[repository - cve - replaceColumn]: Failed to clear column")
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO cves (cve_id, `+column+`, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(cve_id) DO UPDATE SET `+column+` = excluded.`+column+`
+	`)
+	if err != nil {
+		log.WithError(err).Error("[repository - cve - replaceColumn]: Failed to prepare statement")
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for id, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, id, string(encoded), now); err != nil {
+			log.WithError(err).WithField("cve_id", id).Error("[repository - cve - replaceColumn]: Failed to save CVE")
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *cveRepository) FetchCVEByID(ctx context.Context, id string) (*entity.CVE, error) {
+	cves, err := r.fetchCVEs(ctx, `SELECT `+cveColumns+` FROM cves WHERE cve_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cves) == 0 {
+		return nil, nil
+	}
+	return cves[0], nil
+}
+
+// FetchCVEsByIDs returns the known CVEs among the given IDs by ID
+func (r *cveRepository) FetchCVEsByIDs(ctx context.Context, ids []string) (map[string]*entity.CVE, error) {
+	result := map[string]*entity.CVE{}
+
+	// SQLite limits the number of bound parameters, so large lookups are split
+	const batchSize = 500
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+
+		cves, err := r.fetchCVEs(ctx, `SELECT `+cveColumns+` FROM cves WHERE cve_id IN (?`+strings.Repeat(", ?", len(batch)-1)+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for _, cve := range cves {
+			result[cve.ID] = cve
+		}
+	}
+
+	return result, nil
+}
+
+// SaveFeedImport records the outcome of importing a feed file, replacing the previous one
+func (r *cveRepository) SaveFeedImport(ctx context.Context, feedImport *entity.CVEFeedImport) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO cve_feed_imports (path, kind, records, file_mod_time, imported_at, error)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET
+			kind = excluded.kind,
+			records = excluded.records,
+			file_mod_time = excluded.file_mod_time,
+			imported_at = excluded.imported_at,
+			error = excluded.error
+	`,
+		feedImport.Path,
+		feedImport.Kind,
+		feedImport.Records,
+		feedImport.FileModTime,
+		feedImport.ImportedAt,
+		feedImport.Error,
+	)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("path", feedImport.Path).Error("[repository - cve - SaveFeedImport]: Failed to save feed import")
+		return err
+	}
+	return nil
+}
+
+func (r *cveRepository) FetchFeedImport(ctx context.Context, path string) (*entity.CVEFeedImport, error) {
+	imports, err := r.fetchFeedImports(ctx, `SELECT path, kind, records, file_mod_time, imported_at, error FROM cve_feed_imports WHERE path = ?`, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imports) == 0 {
+		return nil, nil
+	}
+	return imports[0], nil
+}
+
+// FetchFeedImports returns the last import of every feed file ever imported, by path
+func (r *cveRepository) FetchFeedImports(ctx context.Context) ([]*entity.CVEFeedImport, error) {
+	return r.fetchFeedImports(ctx, `SELECT path, kind, records, file_mod_time, imported_at, error FROM cve_feed_imports ORDER BY path ASC`)
+}
+
+func (r *cveRepository) fetchFeedImports(ctx context.Context, query string, args ...interface{}) ([]*entity.CVEFeedImport, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - cve - fetchFeedImports]: Failed to fetch feed imports")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var imports []*entity.CVEFeedImport
+
+	for rows.Next() {
+		var feedImport entity.CVEFeedImport
+		var fileModTime, importedAt sql.NullTime
+
+		if err := rows.Scan(
+			&feedImport.Path,
+			&feedImport.Kind,
+			&feedImport.Records,
+			&fileModTime,
+			&importedAt,
+			&feedImport.Error,
+		); err != nil {
+			log.WithError(err).Error("[repository - cve - fetchFeedImports]: Failed to scan feed import")
+			return nil, err
+		}
+
+		if fileModTime.Valid {
+			feedImport.FileModTime = &fileModTime.Time
+		}
+		if importedAt.Valid {
+			feedImport.ImportedAt = &importedAt.Time
+		}
+
+		imports = append(imports, &feedImport)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - cve - fetchFeedImports]: Error iterating rows")
+		return nil, err
+	}
+
+	return imports, nil
+}
+
+func (r *cveRepository) fetchCVEs(ctx context.Context, query string, args ...interface{}) ([]*entity.CVE, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - cve - fetchCVEs]: Failed to fetch CVEs")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cves []*entity.CVE
+
+	for rows.Next() {
+		var cve entity.CVE
+		var published, lastModified sql.NullTime
+		var metrics, cwes, references, epss, kev string
+
+		if err := rows.Scan(
+			&cve.ID,
+			&cve.Description,
+			&published,
+			&lastModified,
+			&metrics,
+			&cwes,
+			&references,
+			&epss,
+			&kev,
+			&cve.UpdatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - cve - fetchCVEs]: Failed to scan CVE")
+			return nil, err
+		}
+
+		if published.Valid {
+			cve.Published = &published.Time
+		}
+		if lastModified.Valid {
+			cve.LastModified = &lastModified.Time
+		}
+
+		for _, field := range []struct {
+			name   string
+			raw    string
+			target interface{}
+		}{
+			{"cvss_metrics", metrics, &cve.CVSSMetrics},
+			{"cwes", cwes, &cve.CWEs},
+			{"refs", references, &cve.References},
+			{"epss", epss, &cve.EPSS},
+			{"kev", kev, &cve.KEV},
+		} {
+			if field.raw == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(field.raw), field.target); err != nil {
+				log.WithError(err).WithField("cve_id", cve.ID).WithField("column", field.name).Warn("[repository - cve - fetchCVEs]: Failed to parse CVE column")
+			}
+		}
+
+		if cve.CVSSMetrics == nil {
+			cve.CVSSMetrics = []entity.CVSSMetric{}
+		}
+		if cve.CWEs == nil {
+			cve.CWEs = []string{}
+		}
+		if cve.References == nil {
+			cve.References = []entity.CVEReference{}
+		}
+
+		cves = append(cves, &cve)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - cve - fetchCVEs]: Error iterating rows")
+		return nil, err
+	}
+
+	return cves, nil
+}
@@ -6,8 +6,12 @@ import (
 	"automation-wazuh-triage/pkg/logger"
 	"context"
 	"database/sql"
+	"time"
 )
 
+// closedEventColumns is the column list every closed event query selects, in scanClosedEvent order
+const closedEventColumns = "id, event_id, rule_id, raw_event, reason, status, close_type, label, close_at"
+
 type closedEventRepository struct {
 	db *sql.DB
 }
@@ -22,16 +26,18 @@ func (r *closedEventRepository) SaveClosedEvent(ctx context.Context, closedEvent
 	log := logger.WithRequestID(ctx)
 
 	query := `
-		INSERT INTO closed_events (event_id, rule_id, raw_event, reason, status, close_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO closed_events (event_id, rule_id, raw_event, reason, status, close_type, label, close_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := r.db.ExecContext(ctx, query,
 		closedEvent.EventID,
 		closedEvent.RuleID,
 		closedEvent.RawEvent,
 		closedEvent.Reason,
 		closedEvent.Status,
+		closedEvent.CloseType,
+		closedEvent.Label,
 		closedEvent.CloseAt,
 	)
 
@@ -40,6 +46,10 @@ func (r *closedEventRepository) SaveClosedEvent(ctx context.Context, closedEvent
 		return err
 	}
 
+	if id, err := result.LastInsertId(); err == nil {
+		closedEvent.ID = int(id)
+	}
+
 	log.Info("[repository - event - SaveClosedEvent]: Successfully saved closed event")
 	return nil
 }
@@ -48,7 +58,7 @@ func (r *closedEventRepository) FetchClosedEvents(ctx context.Context) ([]*entit
 	log := logger.WithRequestID(ctx)
 
 	query := `
-		SELECT id, event_id, rule_id, raw_event, reason, status, close_at
+		SELECT ` + closedEventColumns + `
 		FROM closed_events
 		ORDER BY close_at DESC
 	`
@@ -63,21 +73,12 @@ func (r *closedEventRepository) FetchClosedEvents(ctx context.Context) ([]*entit
 	var closedEvents []*entity.ClosedEvent
 
 	for rows.Next() {
-		var event entity.ClosedEvent
-		err := rows.Scan(
-			&event.ID,
-			&event.EventID,
-			&event.RuleID,
-			&event.RawEvent,
-			&event.Reason,
-			&event.Status,
-			&event.CloseAt,
-		)
+		event, err := scanClosedEvent(rows)
 		if err != nil {
 			log.WithError(err).Error("[repository - event - FetchClosedEvents]: Failed to scan closed event")
 			return nil, err
 		}
-		closedEvents = append(closedEvents, &event)
+		closedEvents = append(closedEvents, event)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -93,23 +94,14 @@ func (r *closedEventRepository) FetchClosedEventByID(ctx context.Context, id str
 	log := logger.WithRequestID(ctx)
 
 	query := `
-		SELECT id, event_id, rule_id, raw_event, reason, status, close_at
+		SELECT ` + closedEventColumns + `
 		FROM closed_events
 		WHERE id = ?
 	`
 
 	row := r.db.QueryRowContext(ctx, query, id)
 
-	var event entity.ClosedEvent
-	err := row.Scan(
-		&event.ID,
-		&event.EventID,
-		&event.RuleID,
-		&event.RawEvent,
-		&event.Reason,
-		&event.Status,
-		&event.CloseAt,
-	)
+	event, err := scanClosedEvent(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -121,30 +113,21 @@ func (r *closedEventRepository) FetchClosedEventByID(ctx context.Context, id str
 	}
 
 	log.WithField("id", id).Info("[repository - event - FetchClosedEventByID]: Successfully fetched closed event by ID")
-	return &event, nil
+	return event, nil
 }
 
 func (r *closedEventRepository) FetchClosedEventByEventID(ctx context.Context, eventID string) (*entity.ClosedEvent, error) {
 	log := logger.WithRequestID(ctx)
 
 	query := `
-		SELECT id, event_id, rule_id, raw_event, reason, status, close_at
+		SELECT ` + closedEventColumns + `
 		FROM closed_events
 		WHERE event_id = ?
 	`
 
 	row := r.db.QueryRowContext(ctx, query, eventID)
 
-	var event entity.ClosedEvent
-	err := row.Scan(
-		&event.ID,
-		&event.EventID,
-		&event.RuleID,
-		&event.RawEvent,
-		&event.Reason,
-		&event.Status,
-		&event.CloseAt,
-	)
+	event, err := scanClosedEvent(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -156,7 +139,7 @@ func (r *closedEventRepository) FetchClosedEventByEventID(ctx context.Context, e
 	}
 
 	log.WithField("event_id", eventID).Info("[repository - event - FetchClosedEventByEventID]: Successfully fetched closed event by event ID")
-	return &event, nil
+	return event, nil
 }
 
 func (r *closedEventRepository) UpdateClosedEventReason(ctx context.Context, id string, reason string) error {
@@ -188,3 +171,131 @@ func (r *closedEventRepository) UpdateClosedEventReason(ctx context.Context, id
 	log.WithField("id", id).WithField("reason", reason).Info("[repository - event - UpdateClosedEventReason]: Successfully updated closed event reason")
 	return nil
 }
+
+func (r *closedEventRepository) FetchClosedEventsSince(ctx context.Context, since time.Time) ([]*entity.ClosedEvent, error) {
+	log := logger.WithRequestID(ctx)
+
+	query := `
+		SELECT ` + closedEventColumns + `
+		FROM closed_events
+		WHERE close_at >= ?
+		ORDER BY close_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		log.WithError(err).Error("[repository - event - FetchClosedEventsSince]: Failed to fetch closed events")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var closedEvents []*entity.ClosedEvent
+
+	for rows.Next() {
+		event, err := scanClosedEvent(rows)
+		if err != nil {
+			log.WithError(err).Error("[repository - event - FetchClosedEventsSince]: Failed to scan closed event")
+			return nil, err
+		}
+		closedEvents = append(closedEvents, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - event - FetchClosedEventsSince]: Error iterating rows")
+		return nil, err
+	}
+
+	return closedEvents, nil
+}
+
+func (r *closedEventRepository) UpdateClosedEventLabel(ctx context.Context, id string, label string) error {
+	log := logger.WithRequestID(ctx)
+
+	query := `
+		UPDATE closed_events
+		SET label = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, label, id)
+	if err != nil {
+		log.WithError(err).WithField("id", id).Error("[repository - event - UpdateClosedEventLabel]: Failed to update closed event label")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.WithError(err).WithField("id", id).Error("[repository - event - UpdateClosedEventLabel]: Failed to get rows affected")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		log.WithField("id", id).Warn("[repository - event - UpdateClosedEventLabel]: No closed event found with the given ID")
+		return sql.ErrNoRows
+	}
+
+	log.WithField("id", id).WithField("label", label).Info("[repository - event - UpdateClosedEventLabel]: Successfully updated closed event label")
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanClosedEvent(scanner rowScanner) (*entity.ClosedEvent, error) {
+	var event entity.ClosedEvent
+	err := scanner.Scan(
+		&event.ID,
+		&event.EventID,
+		&event.RuleID,
+		&event.RawEvent,
+		&event.Reason,
+		&event.Status,
+		&event.CloseType,
+		&event.Label,
+		&event.CloseAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// DeleteClosedEvent removes a closure, which reopens the event for triage
+func (r *closedEventRepository) DeleteClosedEvent(ctx context.Context, id string) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM closed_events WHERE id = ?`, id)
+	if err != nil {
+		log.WithError(err).WithField("id", id).Error("[repository - event - DeleteClosedEvent]: Failed to delete closed event")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CountAutoClosuresSince returns how many events of the rule auto-close closed since the given time
+func (r *closedEventRepository) CountAutoClosuresSince(ctx context.Context, ruleID string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM closed_events
+		WHERE rule_id = ? AND close_type = ? AND close_at >= ?
+	`, ruleID, entity.CloseTypeAuto, since).Scan(&count)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("rule_id", ruleID).Error("[repository - event - CountAutoClosuresSince]: Failed to count auto-closures")
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/geoip"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"net"
+	"os"
+	"strings"
+)
+
+type geoIPRepository struct {
+	city *geoip.Database
+	asn  *geoip.Database
+}
+
+// NewGeoIPRepository reads the GeoLite2 City and ASN databases named by GEOIP_CITY_DB and GEOIP_ASN_DB.
+// Either may be unset, which leaves its fields empty.
+func NewGeoIPRepository() domain.GeoIPRepository {
+	return &geoIPRepository{
+		city: geoip.Open(os.Getenv("GEOIP_CITY_DB")),
+		asn:  geoip.Open(os.Getenv("GEOIP_ASN_DB")),
+	}
+}
+
+// LookupIP returns the location and ASN of a public address, or nil for private, loopback and unknown
+// addresses and when no database is configured
+func (r *geoIPRepository) LookupIP(ctx context.Context, ip string) (*entity.GeoLocation, error) {
+	address := net.ParseIP(strings.TrimSpace(ip))
+	if address == nil || address.IsPrivate() || address.IsLoopback() || address.IsLinkLocalUnicast() || address.IsUnspecified() {
+		return nil, nil
+	}
+
+	location := &entity.GeoLocation{IP: address.String()}
+	found := false
+
+	var city geoip.City
+	ok, err := r.city.Lookup(address, &city)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("ip", ip).Warn("[repository - geoip - LookupIP]: Failed to look up city")
+		return nil, err
+	}
+	if ok {
+		found = true
+		location.CountryISOCode = city.Country.ISOCode
+		location.CountryName = city.Country.Names["en"]
+		location.CityName = city.City.Names["en"]
+		location.ContinentCode = city.Continent.Code
+		if city.Location.Latitude != 0 || city.Location.Longitude != 0 {
+			location.Location = &entity.GeoPoint{Lat: city.Location.Latitude, Lon: city.Location.Longitude}
+		}
+	}
+
+	var asn geoip.ASN
+	ok, err = r.asn.Lookup(address, &asn)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("ip", ip).Warn("[repository - geoip - LookupIP]: Failed to look up ASN")
+		return nil, err
+	}
+	if ok {
+		found = true
+		location.ASN = asn.Number
+		location.ASOrganization = asn.Organization
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return location, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type guardrailTripRepository struct {
+	db *sql.DB
+}
+
+func NewGuardrailTripRepository(db *sql.DB) domain.GuardrailTripRepository {
+	return &guardrailTripRepository{
+		db: db,
+	}
+}
+
+func (r *guardrailTripRepository) SaveGuardrailTrip(ctx context.Context, trip *entity.GuardrailTrip) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO guardrail_trips (guardrail, event_id, rule_id, rule_level, detail, tripped_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, trip.Guardrail, trip.EventID, trip.RuleID, trip.RuleLevel, trip.Detail, trip.TrippedAt)
+	if err != nil {
+		log.WithError(err).WithField("event_id", trip.EventID).Error("[repository - guardrail_trip - SaveGuardrailTrip]: Failed to save guardrail trip")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	trip.ID = int(id)
+
+	return nil
+}
+
+// FetchGuardrailTrips returns the trips since the given time newest first, optionally narrowed to one guardrail and rule
+func (r *guardrailTripRepository) FetchGuardrailTrips(ctx context.Context, since time.Time, guardrail string, ruleID string, limit int) ([]*entity.GuardrailTrip, error) {
+	log := logger.WithRequestID(ctx)
+
+	conditions := []string{"tripped_at >= ?"}
+	args := []interface{}{since}
+
+	if guardrail != "" {
+		conditions = append(conditions, "guardrail = ?")
+		args = append(args, guardrail)
+	}
+	if ruleID != "" {
+		conditions = append(conditions, "rule_id = ?")
+		args = append(args, ruleID)
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, guardrail, event_id, rule_id, rule_level, detail, tripped_at
+		FROM guardrail_trips
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY tripped_at DESC, id DESC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - guardrail_trip - FetchGuardrailTrips]: Failed to fetch guardrail trips")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []*entity.GuardrailTrip
+
+	for rows.Next() {
+		var trip entity.GuardrailTrip
+		if err := rows.Scan(&trip.ID, &trip.Guardrail, &trip.EventID, &trip.RuleID, &trip.RuleLevel, &trip.Detail, &trip.TrippedAt); err != nil {
+			log.WithError(err).Error("[repository - guardrail_trip - FetchGuardrailTrips]: Failed to scan guardrail trip")
+			return nil, err
+		}
+		trips = append(trips, &trip)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - guardrail_trip - FetchGuardrailTrips]: Error iterating rows")
+		return nil, err
+	}
+
+	return trips, nil
+}
+
+// CountGuardrailTripsSince returns the number of trips per guardrail since the given time
+func (r *guardrailTripRepository) CountGuardrailTripsSince(ctx context.Context, since time.Time) (map[string]int, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT guardrail, COUNT(*)
+		FROM guardrail_trips
+		WHERE tripped_at >= ?
+		GROUP BY guardrail
+	`, since)
+	if err != nil {
+		log.WithError(err).Error("[repository - guardrail_trip - CountGuardrailTripsSince]: Failed to count guardrail trips")
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var guardrail string
+		var count int
+		if err := rows.Scan(&guardrail, &count); err != nil {
+			log.WithError(err).Error("[repository - guardrail_trip - CountGuardrailTripsSince]: Failed to scan count")
+			return nil, err
+		}
+		counts[guardrail] = count
+	}
+
+	return counts, rows.Err()
+}
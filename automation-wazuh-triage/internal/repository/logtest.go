@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/wazuh"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// defaultLogtestFormat is the log format used when a sample does not state one
+const defaultLogtestFormat = "syslog"
+
+type logtestRepository struct {
+}
+
+func NewLogtestRepository() domain.LogtestRepository {
+	return &logtestRepository{}
+}
+
+func (r *logtestRepository) RunLogtest(ctx context.Context, token string, sample entity.LogtestSample) (*entity.LogtestResult, string, error) {
+	log := logger.WithRequestID(ctx)
+
+	logFormat := sample.LogFormat
+	if logFormat == "" {
+		logFormat = defaultLogtestFormat
+	}
+
+	client := wazuh.NewWazuh()
+
+	responseBytes, err := client.RunLogtest(wazuh.LogtestRequest{
+		Token:     token,
+		LogFormat: logFormat,
+		Location:  sample.Location,
+		Event:     sample.Log,
+	})
+	if err != nil {
+		log.WithError(err).Error("[repository - logtest - RunLogtest]: Failed to run logtest")
+		return nil, "", err
+	}
+
+	var apiResponse entity.WazuhLogtestAPIResponse
+	if err := json.Unmarshal(responseBytes, &apiResponse); err != nil {
+		log.WithError(err).Error("[repository - logtest - RunLogtest]: Failed to unmarshal Wazuh API response")
+		return nil, "", err
+	}
+
+	if apiResponse.Error != 0 {
+		log.WithField("wazuh_error", apiResponse.Error).WithField("message", apiResponse.Message).Error("[repository - logtest - RunLogtest]: Wazuh API returned error")
+		return nil, "", fmt.Errorf("logtest failed: %s", apiResponse.Message)
+	}
+
+	// Keep the full output as well, it carries the predecoder and decoded fields
+	var rawResponse struct {
+		Data struct {
+			Output map[string]interface{} `json:"output"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(responseBytes, &rawResponse); err != nil {
+		log.WithError(err).Warn("[repository - logtest - RunLogtest]: Failed to unmarshal logtest output")
+	}
+
+	output := apiResponse.Data.Output
+	result := &entity.LogtestResult{
+		Log:             sample.Log,
+		Source:          sample.Source,
+		ClosedEventID:   sample.ClosedEventID,
+		Decoder:         output.Decoder.Name,
+		RuleID:          output.Rule.ID,
+		RuleLevel:       output.Rule.Level,
+		RuleDescription: output.Rule.Description,
+		RuleGroups:      output.Rule.Groups,
+		Alert:           apiResponse.Data.Alert,
+		Messages:        apiResponse.Data.Messages,
+		Output:          rawResponse.Data.Output,
+	}
+
+	return result, apiResponse.Data.Token, nil
+}
+
+func (r *logtestRepository) EndLogtestSession(ctx context.Context, token string) error {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	if err := client.EndLogtestSession(token); err != nil {
+		log.WithError(err).Warn("[repository - logtest - EndLogtestSession]: Failed to end logtest session")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,359 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type maintenanceRepository struct {
+	db *sql.DB
+}
+
+func NewMaintenanceRepository(db *sql.DB) domain.MaintenanceRepository {
+	return &maintenanceRepository{
+		db: db,
+	}
+}
+
+const maintenanceWindowColumns = `w.id, w.name, w.description, w.starts_at, w.ends_at, w.cron, w.duration, w.timezone,
+	w.agent_ids, w.agent_groups, w.agent_names, w.policy, w.enabled, w.created_by, w.created_at, w.updated_by,
+	w.updated_at, (SELECT COUNT(*) FROM maintenance_alerts a WHERE a.window_id = w.id)`
+
+const maintenanceAlertColumns = `id, window_id, event_id, rule_id, rule_level, agent_id, agent_name, policy, outcome,
+	detail, closed_event_id, alert_at, tagged_at`
+
+func (r *maintenanceRepository) SaveMaintenanceWindow(ctx context.Context, window *entity.MaintenanceWindow) error {
+	log := logger.WithRequestID(ctx)
+
+	agentIDs, agentGroups, agentNames, err := encodeMaintenanceScope(window)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO maintenance_windows (name, description, starts_at, ends_at, cron, duration, timezone, agent_ids,
+			agent_groups, agent_names, policy, enabled, created_by, created_at, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		window.Name,
+		window.Description,
+		window.StartsAt,
+		window.EndsAt,
+		window.Cron,
+		window.Duration,
+		window.Timezone,
+		agentIDs,
+		agentGroups,
+		agentNames,
+		window.Policy,
+		window.Enabled,
+		window.CreatedBy,
+		window.CreatedAt,
+		window.UpdatedBy,
+		window.UpdatedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("name", window.Name).Error("[repository - maintenance - SaveMaintenanceWindow]: Failed to save maintenance window")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	window.ID = int(id)
+
+	return nil
+}
+
+// UpdateMaintenanceWindow replaces the schedule, scope and policy of a window. It returns sql.ErrNoRows when
+// the window does not exist.
+func (r *maintenanceRepository) UpdateMaintenanceWindow(ctx context.Context, window *entity.MaintenanceWindow) error {
+	log := logger.WithRequestID(ctx)
+
+	agentIDs, agentGroups, agentNames, err := encodeMaintenanceScope(window)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE maintenance_windows
+		SET name = ?, description = ?, starts_at = ?, ends_at = ?, cron = ?, duration = ?, timezone = ?, agent_ids = ?,
+			agent_groups = ?, agent_names = ?, policy = ?, enabled = ?, updated_by = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		window.Name,
+		window.Description,
+		window.StartsAt,
+		window.EndsAt,
+		window.Cron,
+		window.Duration,
+		window.Timezone,
+		agentIDs,
+		agentGroups,
+		agentNames,
+		window.Policy,
+		window.Enabled,
+		window.UpdatedBy,
+		window.UpdatedAt,
+		window.ID,
+	)
+	if err != nil {
+		log.WithError(err).WithField("window_id", window.ID).Error("[repository - maintenance - UpdateMaintenanceWindow]: Failed to update maintenance window")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *maintenanceRepository) FetchMaintenanceWindowByID(ctx context.Context, id int) (*entity.MaintenanceWindow, error) {
+	windows, err := r.fetchMaintenanceWindows(ctx, `
+		SELECT `+maintenanceWindowColumns+`
+		FROM maintenance_windows w
+		WHERE w.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	return windows[0], nil
+}
+
+// FetchMaintenanceWindows returns the windows oldest first, optionally only the enabled ones
+func (r *maintenanceRepository) FetchMaintenanceWindows(ctx context.Context, enabledOnly bool) ([]*entity.MaintenanceWindow, error) {
+	if enabledOnly {
+		return r.fetchMaintenanceWindows(ctx, `
+			SELECT `+maintenanceWindowColumns+`
+			FROM maintenance_windows w
+			WHERE w.enabled = 1
+			ORDER BY w.id ASC
+		`)
+	}
+
+	return r.fetchMaintenanceWindows(ctx, `
+		SELECT `+maintenanceWindowColumns+`
+		FROM maintenance_windows w
+		ORDER BY w.id ASC
+	`)
+}
+
+// SaveMaintenanceAlert records an alert that fired during a window unless it was already recorded, and
+// reports whether it was recorded
+func (r *maintenanceRepository) SaveMaintenanceAlert(ctx context.Context, alert *entity.MaintenanceAlert) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO maintenance_alerts (window_id, event_id, rule_id, rule_level, agent_id, agent_name, policy,
+			outcome, detail, closed_event_id, alert_at, tagged_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		alert.WindowID,
+		alert.EventID,
+		alert.RuleID,
+		alert.RuleLevel,
+		alert.AgentID,
+		alert.AgentName,
+		alert.Policy,
+		alert.Outcome,
+		alert.Detail,
+		alert.ClosedEventID,
+		alert.AlertAt,
+		alert.TaggedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("event_id", alert.EventID).Error("[repository - maintenance - SaveMaintenanceAlert]: Failed to save maintenance alert")
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	alert.ID = int(id)
+
+	return true, nil
+}
+
+// FetchMaintenanceAlertByEventID returns how a window handled the alert, or nil when no window tagged it
+func (r *maintenanceRepository) FetchMaintenanceAlertByEventID(ctx context.Context, eventID string) (*entity.MaintenanceAlert, error) {
+	alerts, err := r.fetchMaintenanceAlerts(ctx, `
+		SELECT `+maintenanceAlertColumns+`
+		FROM maintenance_alerts
+		WHERE event_id = ?
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	return alerts[0], nil
+}
+
+// FetchMaintenanceAlerts returns the alerts a window tagged, newest first
+func (r *maintenanceRepository) FetchMaintenanceAlerts(ctx context.Context, windowID int, limit int) ([]*entity.MaintenanceAlert, error) {
+	return r.fetchMaintenanceAlerts(ctx, `
+		SELECT `+maintenanceAlertColumns+`
+		FROM maintenance_alerts
+		WHERE window_id = ?
+		ORDER BY alert_at DESC, id DESC
+		LIMIT ?
+	`, windowID, limit)
+}
+
+func (r *maintenanceRepository) fetchMaintenanceWindows(ctx context.Context, query string, args ...interface{}) ([]*entity.MaintenanceWindow, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - maintenance - fetchMaintenanceWindows]: Failed to fetch maintenance windows")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []*entity.MaintenanceWindow
+
+	for rows.Next() {
+		var window entity.MaintenanceWindow
+		var startsAt, endsAt sql.NullTime
+		var agentIDs, agentGroups, agentNames string
+
+		if err := rows.Scan(
+			&window.ID,
+			&window.Name,
+			&window.Description,
+			&startsAt,
+			&endsAt,
+			&window.Cron,
+			&window.Duration,
+			&window.Timezone,
+			&agentIDs,
+			&agentGroups,
+			&agentNames,
+			&window.Policy,
+			&window.Enabled,
+			&window.CreatedBy,
+			&window.CreatedAt,
+			&window.UpdatedBy,
+			&window.UpdatedAt,
+			&window.TaggedCount,
+		); err != nil {
+			log.WithError(err).Error("[repository - maintenance - fetchMaintenanceWindows]: Failed to scan maintenance window")
+			return nil, err
+		}
+
+		for _, list := range []struct {
+			encoded string
+			target  *[]string
+		}{
+			{agentIDs, &window.AgentIDs},
+			{agentGroups, &window.AgentGroups},
+			{agentNames, &window.AgentNames},
+		} {
+			if err := json.Unmarshal([]byte(list.encoded), list.target); err != nil {
+				log.WithError(err).WithField("window_id", window.ID).Warn("[repository - maintenance - fetchMaintenanceWindows]: Failed to parse window scope")
+			}
+			if *list.target == nil {
+				*list.target = []string{}
+			}
+		}
+
+		window.StartsAt = nullTimePtr(startsAt)
+		window.EndsAt = nullTimePtr(endsAt)
+		windows = append(windows, &window)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - maintenance - fetchMaintenanceWindows]: Error iterating rows")
+		return nil, err
+	}
+
+	return windows, nil
+}
+
+func (r *maintenanceRepository) fetchMaintenanceAlerts(ctx context.Context, query string, args ...interface{}) ([]*entity.MaintenanceAlert, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - maintenance - fetchMaintenanceAlerts]: Failed to fetch maintenance alerts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*entity.MaintenanceAlert
+
+	for rows.Next() {
+		var alert entity.MaintenanceAlert
+
+		if err := rows.Scan(
+			&alert.ID,
+			&alert.WindowID,
+			&alert.EventID,
+			&alert.RuleID,
+			&alert.RuleLevel,
+			&alert.AgentID,
+			&alert.AgentName,
+			&alert.Policy,
+			&alert.Outcome,
+			&alert.Detail,
+			&alert.ClosedEventID,
+			&alert.AlertAt,
+			&alert.TaggedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - maintenance - fetchMaintenanceAlerts]: Failed to scan maintenance alert")
+			return nil, err
+		}
+
+		alerts = append(alerts, &alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - maintenance - fetchMaintenanceAlerts]: Error iterating rows")
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// encodeMaintenanceScope stores the agent lists of a window as JSON arrays
+func encodeMaintenanceScope(window *entity.MaintenanceWindow) (string, string, string, error) {
+	encoded := make([]string, 0, 3)
+
+	for _, list := range [][]string{window.AgentIDs, window.AgentGroups, window.AgentNames} {
+		if list == nil {
+			list = []string{}
+		}
+		value, err := json.Marshal(list)
+		if err != nil {
+			return "", "", "", err
+		}
+		encoded = append(encoded, string(value))
+	}
+
+	return encoded[0], encoded[1], encoded[2], nil
+}
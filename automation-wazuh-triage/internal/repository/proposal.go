@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type proposalRepository struct {
+	db *sql.DB
+}
+
+func NewProposalRepository(db *sql.DB) domain.ProposalRepository {
+	return &proposalRepository{
+		db: db,
+	}
+}
+
+const proposalColumns = `id, type, title, description, author, status, suppression_id, filename, content, base_content, base_hash, diff,
+	evidence_event_ids, reviewer, review_comment, deployed_by, rolled_back_by, created_at, updated_at,
+	submitted_at, reviewed_at, deployed_at, rolled_back_at`
+
+func (r *proposalRepository) SaveProposal(ctx context.Context, proposal *entity.Proposal) error {
+	log := logger.WithRequestID(ctx)
+
+	evidenceJSON, err := json.Marshal(proposal.EvidenceEventIDs)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO proposals (type, title, description, author, status, suppression_id, filename, content, base_content, base_hash, diff,
+			evidence_event_ids, reviewer, review_comment, deployed_by, rolled_back_by, created_at, updated_at,
+			submitted_at, reviewed_at, deployed_at, rolled_back_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		proposal.Type,
+		proposal.Title,
+		proposal.Description,
+		proposal.Author,
+		proposal.Status,
+		proposal.SuppressionID,
+		proposal.Filename,
+		proposal.Content,
+		proposal.BaseContent,
+		proposal.BaseHash,
+		proposal.Diff,
+		string(evidenceJSON),
+		proposal.Reviewer,
+		proposal.ReviewComment,
+		proposal.DeployedBy,
+		proposal.RolledBackBy,
+		proposal.CreatedAt,
+		proposal.UpdatedAt,
+		proposal.SubmittedAt,
+		proposal.ReviewedAt,
+		proposal.DeployedAt,
+		proposal.RolledBackAt,
+	)
+	if err != nil {
+		log.WithError(err).Error("[repository - proposal - SaveProposal]: Failed to save proposal")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	proposal.ID = int(id)
+
+	log.WithField("proposal_id", proposal.ID).Info("[repository - proposal - SaveProposal]: Successfully saved proposal")
+	return nil
+}
+
+func (r *proposalRepository) FetchProposals(ctx context.Context, status string) ([]*entity.Proposal, error) {
+	if status == "" {
+		return r.fetchProposals(ctx, `SELECT `+proposalColumns+` FROM proposals ORDER BY id DESC`)
+	}
+	return r.fetchProposals(ctx, `SELECT `+proposalColumns+` FROM proposals WHERE status = ? ORDER BY id DESC`, status)
+}
+
+func (r *proposalRepository) FetchProposalByID(ctx context.Context, id int) (*entity.Proposal, error) {
+	proposals, err := r.fetchProposals(ctx, `SELECT `+proposalColumns+` FROM proposals WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(proposals) == 0 {
+		return nil, nil // Return nil to indicate not found
+	}
+
+	return proposals[0], nil
+}
+
+// UpdateProposal stores the proposal when it is still in the fromStatus state, and returns sql.ErrNoRows
+// when another request moved it first
+func (r *proposalRepository) UpdateProposal(ctx context.Context, proposal *entity.Proposal, fromStatus string) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE proposals
+		SET status = ?, base_content = ?, base_hash = ?, diff = ?, reviewer = ?, review_comment = ?, deployed_by = ?, rolled_back_by = ?,
+			updated_at = ?, submitted_at = ?, reviewed_at = ?, deployed_at = ?, rolled_back_at = ?
+		WHERE id = ? AND status = ?
+	`,
+		proposal.Status,
+		proposal.BaseContent,
+		proposal.BaseHash,
+		proposal.Diff,
+		proposal.Reviewer,
+		proposal.ReviewComment,
+		proposal.DeployedBy,
+		proposal.RolledBackBy,
+		proposal.UpdatedAt,
+		proposal.SubmittedAt,
+		proposal.ReviewedAt,
+		proposal.DeployedAt,
+		proposal.RolledBackAt,
+		proposal.ID,
+		fromStatus,
+	)
+	if err != nil {
+		log.WithError(err).WithField("proposal_id", proposal.ID).Error("[repository - proposal - UpdateProposal]: Failed to update proposal")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		log.WithField("proposal_id", proposal.ID).WithField("from_status", fromStatus).Warn("[repository - proposal - UpdateProposal]: Proposal is no longer in the expected status")
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *proposalRepository) fetchProposals(ctx context.Context, query string, args ...interface{}) ([]*entity.Proposal, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - proposal - fetchProposals]: Failed to fetch proposals")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var proposals []*entity.Proposal
+
+	for rows.Next() {
+		var proposal entity.Proposal
+		var evidenceJSON string
+		var submittedAt, reviewedAt, deployedAt, rolledBackAt sql.NullTime
+
+		if err := rows.Scan(
+			&proposal.ID,
+			&proposal.Type,
+			&proposal.Title,
+			&proposal.Description,
+			&proposal.Author,
+			&proposal.Status,
+			&proposal.SuppressionID,
+			&proposal.Filename,
+			&proposal.Content,
+			&proposal.BaseContent,
+			&proposal.BaseHash,
+			&proposal.Diff,
+			&evidenceJSON,
+			&proposal.Reviewer,
+			&proposal.ReviewComment,
+			&proposal.DeployedBy,
+			&proposal.RolledBackBy,
+			&proposal.CreatedAt,
+			&proposal.UpdatedAt,
+			&submittedAt,
+			&reviewedAt,
+			&deployedAt,
+			&rolledBackAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - proposal - fetchProposals]: Failed to scan proposal")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(evidenceJSON), &proposal.EvidenceEventIDs); err != nil {
+			log.WithError(err).WithField("proposal_id", proposal.ID).Warn("[repository - proposal - fetchProposals]: Failed to parse evidence event IDs")
+		}
+		proposal.SubmittedAt = nullTimePtr(submittedAt)
+		proposal.ReviewedAt = nullTimePtr(reviewedAt)
+		proposal.DeployedAt = nullTimePtr(deployedAt)
+		proposal.RolledBackAt = nullTimePtr(rolledBackAt)
+
+		proposals = append(proposals, &proposal)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - proposal - fetchProposals]: Error iterating rows")
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
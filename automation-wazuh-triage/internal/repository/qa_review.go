@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type qaReviewRepository struct {
+	db *sql.DB
+}
+
+func NewQAReviewRepository(db *sql.DB) domain.QAReviewRepository {
+	return &qaReviewRepository{
+		db: db,
+	}
+}
+
+const qaReviewColumns = "id, closed_event_id, event_id, rule_id, criterion, sample_day, raw_event, status, reviewer, note, closed_at, created_at, reviewed_at"
+
+// SaveQAReview queues the review unless the closed event was already sampled, and reports whether it was queued
+func (r *qaReviewRepository) SaveQAReview(ctx context.Context, review *entity.QAReview) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO qa_reviews (closed_event_id, event_id, rule_id, criterion, sample_day, raw_event, status,
+			reviewer, note, closed_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		review.ClosedEventID,
+		review.EventID,
+		review.RuleID,
+		review.Criterion,
+		review.SampleDay,
+		review.RawEvent,
+		review.Status,
+		review.Reviewer,
+		review.Note,
+		review.ClosedAt,
+		review.CreatedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("closed_event_id", review.ClosedEventID).Error("[repository - qa_review - SaveQAReview]: Failed to save QA review")
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	review.ID = int(id)
+
+	return true, nil
+}
+
+func (r *qaReviewRepository) FetchQAReviewByID(ctx context.Context, id int) (*entity.QAReview, error) {
+	reviews, err := r.fetchQAReviews(ctx, `
+		SELECT `+qaReviewColumns+`
+		FROM qa_reviews
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reviews) == 0 {
+		return nil, nil
+	}
+	return reviews[0], nil
+}
+
+// FetchQAReviews returns the queue newest first, optionally narrowed to one status and rule
+func (r *qaReviewRepository) FetchQAReviews(ctx context.Context, status string, ruleID string) ([]*entity.QAReview, error) {
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, status)
+	}
+	if ruleID != "" {
+		conditions = append(conditions, "rule_id = ?")
+		args = append(args, ruleID)
+	}
+
+	return r.fetchQAReviews(ctx, `
+		SELECT `+qaReviewColumns+`
+		FROM qa_reviews
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY created_at DESC, id DESC
+	`, args...)
+}
+
+// CountQAReviewsByRule returns how many closures of each rule were already sampled for the day
+func (r *qaReviewRepository) CountQAReviewsByRule(ctx context.Context, sampleDay string) (map[string]int, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rule_id, COUNT(*)
+		FROM qa_reviews
+		WHERE sample_day = ?
+		GROUP BY rule_id
+	`, sampleDay)
+	if err != nil {
+		log.WithError(err).Error("[repository - qa_review - CountQAReviewsByRule]: Failed to count QA reviews")
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var ruleID string
+		var count int
+		if err := rows.Scan(&ruleID, &count); err != nil {
+			log.WithError(err).Error("[repository - qa_review - CountQAReviewsByRule]: Failed to scan count")
+			return nil, err
+		}
+		counts[ruleID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// FetchUnsampledAutoClosures returns the auto-closures within [from, to) that are not in the review queue
+func (r *qaReviewRepository) FetchUnsampledAutoClosures(ctx context.Context, from time.Time, to time.Time) ([]*entity.ClosedEvent, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+closedEventColumns+`
+		FROM closed_events
+		WHERE close_type = ? AND close_at >= ? AND close_at < ?
+		AND NOT EXISTS (SELECT 1 FROM qa_reviews q WHERE q.closed_event_id = closed_events.id)
+		ORDER BY close_at ASC
+	`, entity.CloseTypeAuto, from, to)
+	if err != nil {
+		log.WithError(err).Error("[repository - qa_review - FetchUnsampledAutoClosures]: Failed to fetch auto-closures")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var closedEvents []*entity.ClosedEvent
+
+	for rows.Next() {
+		event, err := scanClosedEvent(rows)
+		if err != nil {
+			log.WithError(err).Error("[repository - qa_review - FetchUnsampledAutoClosures]: Failed to scan closed event")
+			return nil, err
+		}
+		closedEvents = append(closedEvents, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - qa_review - FetchUnsampledAutoClosures]: Error iterating rows")
+		return nil, err
+	}
+
+	return closedEvents, nil
+}
+
+// ResolveQAReview moves a pending review to its verdict. It returns sql.ErrNoRows when the review is not pending,
+// so two analysts cannot both resolve it.
+func (r *qaReviewRepository) ResolveQAReview(ctx context.Context, id int, status string, reviewer string, note string, reviewedAt time.Time) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE qa_reviews
+		SET status = ?, reviewer = ?, note = ?, reviewed_at = ?
+		WHERE id = ? AND status = ?
+	`, status, reviewer, note, reviewedAt, id, entity.QAReviewStatusPending)
+	if err != nil {
+		log.WithError(err).WithField("review_id", id).Error("[repository - qa_review - ResolveQAReview]: Failed to resolve QA review")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *qaReviewRepository) fetchQAReviews(ctx context.Context, query string, args ...interface{}) ([]*entity.QAReview, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - qa_review - fetchQAReviews]: Failed to fetch QA reviews")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*entity.QAReview
+
+	for rows.Next() {
+		var review entity.QAReview
+		var rawEvent sql.NullString
+		var reviewedAt sql.NullTime
+
+		if err := rows.Scan(
+			&review.ID,
+			&review.ClosedEventID,
+			&review.EventID,
+			&review.RuleID,
+			&review.Criterion,
+			&review.SampleDay,
+			&rawEvent,
+			&review.Status,
+			&review.Reviewer,
+			&review.Note,
+			&review.ClosedAt,
+			&review.CreatedAt,
+			&reviewedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - qa_review - fetchQAReviews]: Failed to scan QA review")
+			return nil, err
+		}
+
+		review.RawEvent = rawEvent.String
+		review.ReviewedAt = nullTimePtr(reviewedAt)
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - qa_review - fetchQAReviews]: Error iterating rows")
+		return nil, err
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type retroHuntRepository struct {
+	db *sql.DB
+}
+
+func NewRetroHuntRepository(db *sql.DB) domain.RetroHuntRepository {
+	return &retroHuntRepository{
+		db: db,
+	}
+}
+
+const retroHuntColumns = `id, name, indicators, indices, from_time, to_time, status, error, searched, truncated, created_by,
+	created_at, started_at, finished_at`
+
+// retroHuntMatchColumns read each match with the current closure of its alert; archived events are never triaged
+const retroHuntMatchColumns = `m.id, m.hunt_id, m.event_id, m.index_name, m.alert_at, m.rule_id, m.rule_level,
+	m.rule_description, m.agent_id, m.agent_name, m.indicator_type, m.indicator, m.observed, m.fields, m.archived,
+	m.matched_at, COALESCE(c.close_type, ''), COALESCE(c.label, ''), COALESCE(c.reason, ''), c.close_at,
+	EXISTS (SELECT 1 FROM triage_actions t WHERE t.event_id = m.event_id AND t.action = 'acknowledged')`
+
+func (r *retroHuntRepository) SaveRetroHunt(ctx context.Context, hunt *entity.RetroHunt) error {
+	log := logger.WithRequestID(ctx)
+
+	indicators, err := json.Marshal(hunt.Indicators)
+	if err != nil {
+		return err
+	}
+	indices, err := json.Marshal(hunt.Indices)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO retro_hunts (name, indicators, indices, from_time, to_time, status, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		hunt.Name,
+		string(indicators),
+		string(indices),
+		hunt.From,
+		hunt.To,
+		hunt.Status,
+		hunt.CreatedBy,
+		hunt.CreatedAt,
+	)
+	if err != nil {
+		log.WithError(err).Error("[repository - retro_hunt - SaveRetroHunt]: Failed to save retro-hunt")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	hunt.ID = int(id)
+
+	return nil
+}
+
+func (r *retroHuntRepository) StartRetroHunt(ctx context.Context, id int, startedAt time.Time) error {
+	log := logger.WithRequestID(ctx)
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE retro_hunts
+		SET status = ?, started_at = ?
+		WHERE id = ?
+	`, entity.RetroHuntStatusRunning, startedAt, id)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", id).Error("[repository - retro_hunt - StartRetroHunt]: Failed to start retro-hunt")
+		return err
+	}
+
+	return nil
+}
+
+func (r *retroHuntRepository) UpdateRetroHuntSearched(ctx context.Context, id int, searched int) error {
+	log := logger.WithRequestID(ctx)
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE retro_hunts
+		SET searched = ?
+		WHERE id = ?
+	`, searched, id)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", id).Error("[repository - retro_hunt - UpdateRetroHuntSearched]: Failed to update retro-hunt progress")
+		return err
+	}
+
+	return nil
+}
+
+func (r *retroHuntRepository) FinishRetroHunt(ctx context.Context, id int, status string, errMessage string, truncated bool, finishedAt time.Time) error {
+	log := logger.WithRequestID(ctx)
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE retro_hunts
+		SET status = ?, error = ?, truncated = ?, finished_at = ?
+		WHERE id = ?
+	`, status, errMessage, truncated, finishedAt, id)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", id).Error("[repository - retro_hunt - FinishRetroHunt]: Failed to finish retro-hunt")
+		return err
+	}
+
+	return nil
+}
+
+// FetchRetroHunts returns the hunts newest first
+func (r *retroHuntRepository) FetchRetroHunts(ctx context.Context) ([]*entity.RetroHunt, error) {
+	return r.fetchRetroHunts(ctx, `
+		SELECT `+retroHuntColumns+`
+		FROM retro_hunts
+		ORDER BY id DESC
+	`)
+}
+
+func (r *retroHuntRepository) FetchRetroHuntByID(ctx context.Context, id int) (*entity.RetroHunt, error) {
+	hunts, err := r.fetchRetroHunts(ctx, `
+		SELECT `+retroHuntColumns+`
+		FROM retro_hunts
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hunts) == 0 {
+		return nil, nil
+	}
+	return hunts[0], nil
+}
+
+// SaveRetroHuntMatch records a match unless the hunt already matched the document with the indicator, and
+// reports whether it was recorded
+func (r *retroHuntRepository) SaveRetroHuntMatch(ctx context.Context, match *entity.RetroHuntMatch, documentID string) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	fields, err := json.Marshal(match.Fields)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO retro_hunt_matches (hunt_id, document_id, event_id, index_name, alert_at, rule_id, rule_level,
+			rule_description, agent_id, agent_name, indicator_type, indicator, observed, fields, archived, matched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		match.HuntID,
+		documentID,
+		match.EventID,
+		match.Index,
+		match.AlertAt,
+		match.RuleID,
+		match.RuleLevel,
+		match.RuleDescription,
+		match.AgentID,
+		match.AgentName,
+		match.IndicatorType,
+		match.Indicator,
+		match.Observed,
+		string(fields),
+		match.Archived,
+		match.MatchedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", match.HuntID).WithField("event_id", match.EventID).Error("[repository - retro_hunt - SaveRetroHuntMatch]: Failed to save retro-hunt match")
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	match.ID = int(id)
+
+	return true, nil
+}
+
+// FetchRetroHuntMatches returns the matches of a hunt newest alert first, with the current triage state of
+// each alert
+func (r *retroHuntRepository) FetchRetroHuntMatches(ctx context.Context, huntID int) ([]*entity.RetroHuntMatch, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+retroHuntMatchColumns+`
+		FROM retro_hunt_matches m
+		LEFT JOIN closed_events c ON c.event_id = m.event_id AND m.archived = 0
+		WHERE m.hunt_id = ?
+		ORDER BY m.alert_at DESC, m.id DESC
+	`, huntID)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", huntID).Error("[repository - retro_hunt - FetchRetroHuntMatches]: Failed to fetch retro-hunt matches")
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*entity.RetroHuntMatch{}
+
+	for rows.Next() {
+		var match entity.RetroHuntMatch
+		var fields string
+		var closedAt sql.NullTime
+		var acknowledged bool
+
+		if err := rows.Scan(
+			&match.ID,
+			&match.HuntID,
+			&match.EventID,
+			&match.Index,
+			&match.AlertAt,
+			&match.RuleID,
+			&match.RuleLevel,
+			&match.RuleDescription,
+			&match.AgentID,
+			&match.AgentName,
+			&match.IndicatorType,
+			&match.Indicator,
+			&match.Observed,
+			&fields,
+			&match.Archived,
+			&match.MatchedAt,
+			&match.CloseType,
+			&match.Label,
+			&match.Reason,
+			&closedAt,
+			&acknowledged,
+		); err != nil {
+			log.WithError(err).Error("[repository - retro_hunt - FetchRetroHuntMatches]: Failed to scan retro-hunt match")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(fields), &match.Fields); err != nil {
+			match.Fields = []string{}
+		}
+
+		match.ClosedAt = nullTimePtr(closedAt)
+		match.AutoClosed = match.CloseType == entity.CloseTypeAuto
+		switch {
+		case match.Archived:
+			match.TriageState = entity.RetroHuntTriageArchived
+		case match.ClosedAt != nil:
+			match.TriageState = entity.RetroHuntTriageClosed
+		case acknowledged:
+			match.TriageState = entity.RetroHuntTriageAcknowledged
+		default:
+			match.TriageState = entity.RetroHuntTriageOpen
+		}
+
+		matches = append(matches, &match)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - retro_hunt - FetchRetroHuntMatches]: Error iterating rows")
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func (r *retroHuntRepository) fetchRetroHunts(ctx context.Context, query string, args ...interface{}) ([]*entity.RetroHunt, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - retro_hunt - fetchRetroHunts]: Failed to fetch retro-hunts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	hunts := []*entity.RetroHunt{}
+
+	for rows.Next() {
+		var hunt entity.RetroHunt
+		var indicators, indices string
+		var startedAt, finishedAt sql.NullTime
+
+		if err := rows.Scan(
+			&hunt.ID,
+			&hunt.Name,
+			&indicators,
+			&indices,
+			&hunt.From,
+			&hunt.To,
+			&hunt.Status,
+			&hunt.Error,
+			&hunt.Searched,
+			&hunt.Truncated,
+			&hunt.CreatedBy,
+			&hunt.CreatedAt,
+			&startedAt,
+			&finishedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - retro_hunt - fetchRetroHunts]: Failed to scan retro-hunt")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(indicators), &hunt.Indicators); err != nil {
+			log.WithError(err).WithField("hunt_id", hunt.ID).Warn("[repository - retro_hunt - fetchRetroHunts]: Failed to parse retro-hunt indicators")
+		}
+		if err := json.Unmarshal([]byte(indices), &hunt.Indices); err != nil {
+			log.WithError(err).WithField("hunt_id", hunt.ID).Warn("[repository - retro_hunt - fetchRetroHunts]: Failed to parse retro-hunt indices")
+		}
+
+		hunt.StartedAt = nullTimePtr(startedAt)
+		hunt.FinishedAt = nullTimePtr(finishedAt)
+		hunts = append(hunts, &hunt)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - retro_hunt - fetchRetroHunts]: Error iterating rows")
+		return nil, err
+	}
+
+	return hunts, nil
+}
@@ -7,8 +7,12 @@ import (
 	"automation-wazuh-triage/pkg/wazuh"
 	"context"
 	"encoding/json"
+	"fmt"
 )
 
+// rulesPageSize is the number of rules requested per page when walking the full ruleset
+const rulesPageSize = 500
+
 type ruleRepository struct {
 }
 
@@ -85,3 +89,46 @@ func (r *ruleRepository) GetListRulesByFiles(ctx context.Context, filename strin
 
 	return apiResponse.Data.AffectedItems, nil
 }
+
+func (r *ruleRepository) GetAllRules(ctx context.Context) ([]entity.WazuhRule, error) {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	var rules []entity.WazuhRule
+	offset := 0
+
+	for {
+		queryString := fmt.Sprintf("limit=%d&offset=%d&sort=+id", rulesPageSize, offset)
+
+		responseBytes, err := client.GetRules(queryString)
+		if err != nil {
+			log.WithError(err).WithField("offset", offset).Error("[repository - rule - GetAllRules]: Failed to get rules page")
+			return nil, err
+		}
+
+		// Parse the Wazuh API response
+		var apiResponse entity.WazuhRulesAPIResponse
+		if err := json.Unmarshal(responseBytes, &apiResponse); err != nil {
+			log.WithError(err).Error("[repository - rule - GetAllRules]: Failed to unmarshal Wazuh API response")
+			return nil, err
+		}
+
+		// Check if Wazuh API returned an error
+		if apiResponse.Error != 0 {
+			log.WithField("wazuh_error", apiResponse.Error).WithField("message", apiResponse.Message).Error("[repository - rule - GetAllRules]: Wazuh API returned error")
+			return nil, fmt.Errorf("wazuh API returned error %d: %s", apiResponse.Error, apiResponse.Message)
+		}
+
+		rules = append(rules, apiResponse.Data.AffectedItems...)
+		offset += len(apiResponse.Data.AffectedItems)
+
+		if len(apiResponse.Data.AffectedItems) == 0 || offset >= apiResponse.Data.TotalAffectedItems {
+			break
+		}
+	}
+
+	log.WithField("rules_count", len(rules)).Info("[repository - rule - GetAllRules]: Successfully fetched all rules")
+
+	return rules, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/wazuh"
+	"context"
+	"database/sql"
+)
+
+type ruleFileRepository struct {
+}
+
+func NewRuleFileRepository() domain.RuleFileRepository {
+	return &ruleFileRepository{}
+}
+
+func (r *ruleFileRepository) GetRuleFile(ctx context.Context, filename string) (string, bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	content, exists, err := client.GetRuleFile(filename)
+	if err != nil {
+		log.WithError(err).WithField("filename", filename).Error("[repository - rule_file - GetRuleFile]: Failed to get rule file")
+		return "", false, err
+	}
+
+	return string(content), exists, nil
+}
+
+func (r *ruleFileRepository) UpdateRuleFile(ctx context.Context, filename string, content string) error {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	if _, err := client.UpdateRuleFile(filename, []byte(content)); err != nil {
+		log.WithError(err).WithField("filename", filename).Error("[repository - rule_file - UpdateRuleFile]: Failed to upload rule file")
+		return err
+	}
+
+	log.WithField("filename", filename).Info("[repository - rule_file - UpdateRuleFile]: Successfully uploaded rule file")
+	return nil
+}
+
+func (r *ruleFileRepository) DeleteRuleFile(ctx context.Context, filename string) error {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	if err := client.DeleteRuleFile(filename); err != nil {
+		log.WithError(err).WithField("filename", filename).Error("[repository - rule_file - DeleteRuleFile]: Failed to delete rule file")
+		return err
+	}
+
+	log.WithField("filename", filename).Info("[repository - rule_file - DeleteRuleFile]: Successfully deleted rule file")
+	return nil
+}
+
+func (r *ruleFileRepository) ValidateConfiguration(ctx context.Context) error {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	if err := client.ValidateConfiguration(); err != nil {
+		log.WithError(err).Warn("[repository - rule_file - ValidateConfiguration]: Manager configuration is invalid")
+		return err
+	}
+
+	return nil
+}
+
+func (r *ruleFileRepository) RestartManager(ctx context.Context) error {
+	log := logger.WithRequestID(ctx)
+
+	client := wazuh.NewWazuh()
+
+	if err := client.RestartManager(); err != nil {
+		log.WithError(err).Error("[repository - rule_file - RestartManager]: Failed to restart manager")
+		return err
+	}
+
+	log.Info("[repository - rule_file - RestartManager]: Manager restart requested")
+	return nil
+}
+
+type ruleFileVersionRepository struct {
+	db *sql.DB
+}
+
+func NewRuleFileVersionRepository(db *sql.DB) domain.RuleFileVersionRepository {
+	return &ruleFileVersionRepository{
+		db: db,
+	}
+}
+
+func (r *ruleFileVersionRepository) SaveVersion(ctx context.Context, version *entity.RuleFileVersion) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO rule_file_versions (filename, content, hash, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		version.Filename,
+		version.Content,
+		version.Hash,
+		version.Reason,
+		version.CreatedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("filename", version.Filename).Error("[repository - rule_file_version - SaveVersion]: Failed to save rule file version")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	version.ID = int(id)
+
+	log.WithField("filename", version.Filename).WithField("version_id", version.ID).Info("[repository - rule_file_version - SaveVersion]: Successfully saved rule file version")
+	return nil
+}
+
+func (r *ruleFileVersionRepository) FetchVersions(ctx context.Context, filename string) ([]*entity.RuleFileVersion, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, filename, content, hash, reason, created_at
+		FROM rule_file_versions
+		WHERE filename = ?
+		ORDER BY id DESC
+	`, filename)
+	if err != nil {
+		log.WithError(err).WithField("filename", filename).Error("[repository - rule_file_version - FetchVersions]: Failed to fetch rule file versions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*entity.RuleFileVersion
+
+	for rows.Next() {
+		var version entity.RuleFileVersion
+		if err := rows.Scan(
+			&version.ID,
+			&version.Filename,
+			&version.Content,
+			&version.Hash,
+			&version.Reason,
+			&version.CreatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - rule_file_version - FetchVersions]: Failed to scan rule file version")
+			return nil, err
+		}
+		versions = append(versions, &version)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - rule_file_version - FetchVersions]: Error iterating rows")
+		return nil, err
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type ruleSnapshotRepository struct {
+	db *sql.DB
+}
+
+func NewRuleSnapshotRepository(db *sql.DB) domain.RuleSnapshotRepository {
+	return &ruleSnapshotRepository{
+		db: db,
+	}
+}
+
+func (r *ruleSnapshotRepository) SaveSnapshot(ctx context.Context, snapshot *entity.RuleSnapshot, items []entity.RuleSnapshotItem) error {
+	log := logger.WithRequestID(ctx)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - SaveSnapshot]: Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO rule_snapshots (trigger_type, rule_count, hash, created_at)
+		VALUES (?, ?, ?, ?)
+	`,
+		snapshot.Trigger,
+		snapshot.RuleCount,
+		snapshot.Hash,
+		snapshot.CreatedAt,
+	)
+	if err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - SaveSnapshot]: Failed to save snapshot")
+		return err
+	}
+
+	snapshotID, err := result.LastInsertId()
+	if err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - SaveSnapshot]: Failed to get snapshot ID")
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO rule_snapshot_items (snapshot_id, rule_id, filename, level, status, rule_groups, description, hash, raw_rule)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - SaveSnapshot]: Failed to prepare snapshot item statement")
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range items {
+		groupsJSON, err := json.Marshal(item.Groups)
+		if err != nil {
+			return err
+		}
+
+		ruleJSON, err := json.Marshal(item.Rule)
+		if err != nil {
+			return err
+		}
+
+		if _, err := stmt.ExecContext(ctx,
+			snapshotID,
+			item.RuleID,
+			item.Filename,
+			item.Level,
+			item.Status,
+			string(groupsJSON),
+			item.Description,
+			item.Hash,
+			string(ruleJSON),
+		); err != nil {
+			log.WithError(err).WithField("rule_id", item.RuleID).Error("[repository - rule_snapshot - SaveSnapshot]: Failed to save snapshot item")
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - SaveSnapshot]: Failed to commit snapshot")
+		return err
+	}
+
+	snapshot.ID = int(snapshotID)
+
+	log.WithField("snapshot_id", snapshot.ID).WithField("rule_count", snapshot.RuleCount).Info("[repository - rule_snapshot - SaveSnapshot]: Successfully saved rule snapshot")
+	return nil
+}
+
+func (r *ruleSnapshotRepository) FetchSnapshots(ctx context.Context) ([]*entity.RuleSnapshot, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, trigger_type, rule_count, hash, created_at
+		FROM rule_snapshots
+		ORDER BY id DESC
+	`)
+	if err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - FetchSnapshots]: Failed to fetch snapshots")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*entity.RuleSnapshot
+
+	for rows.Next() {
+		var snapshot entity.RuleSnapshot
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.Trigger,
+			&snapshot.RuleCount,
+			&snapshot.Hash,
+			&snapshot.CreatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - rule_snapshot - FetchSnapshots]: Failed to scan snapshot")
+			return nil, err
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - FetchSnapshots]: Error iterating rows")
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (r *ruleSnapshotRepository) FetchSnapshotByID(ctx context.Context, id int) (*entity.RuleSnapshot, error) {
+	return r.fetchSnapshot(ctx, `
+		SELECT id, trigger_type, rule_count, hash, created_at
+		FROM rule_snapshots
+		WHERE id = ?
+	`, id)
+}
+
+func (r *ruleSnapshotRepository) FetchLatestSnapshot(ctx context.Context) (*entity.RuleSnapshot, error) {
+	return r.fetchSnapshot(ctx, `
+		SELECT id, trigger_type, rule_count, hash, created_at
+		FROM rule_snapshots
+		ORDER BY id DESC
+		LIMIT 1
+	`)
+}
+
+func (r *ruleSnapshotRepository) fetchSnapshot(ctx context.Context, query string, args ...interface{}) (*entity.RuleSnapshot, error) {
+	log := logger.WithRequestID(ctx)
+
+	var snapshot entity.RuleSnapshot
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&snapshot.ID,
+		&snapshot.Trigger,
+		&snapshot.RuleCount,
+		&snapshot.Hash,
+		&snapshot.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Return nil to indicate not found
+		}
+		log.WithError(err).Error("[repository - rule_snapshot - fetchSnapshot]: Failed to fetch snapshot")
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+func (r *ruleSnapshotRepository) FetchSnapshotItems(ctx context.Context, snapshotID int) ([]entity.RuleSnapshotItem, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT snapshot_id, rule_id, filename, level, status, rule_groups, description, hash, raw_rule
+		FROM rule_snapshot_items
+		WHERE snapshot_id = ?
+		ORDER BY rule_id
+	`, snapshotID)
+	if err != nil {
+		log.WithError(err).WithField("snapshot_id", snapshotID).Error("[repository - rule_snapshot - FetchSnapshotItems]: Failed to fetch snapshot items")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []entity.RuleSnapshotItem
+
+	for rows.Next() {
+		var item entity.RuleSnapshotItem
+		var groupsJSON, ruleJSON string
+
+		if err := rows.Scan(
+			&item.SnapshotID,
+			&item.RuleID,
+			&item.Filename,
+			&item.Level,
+			&item.Status,
+			&groupsJSON,
+			&item.Description,
+			&item.Hash,
+			&ruleJSON,
+		); err != nil {
+			log.WithError(err).Error("[repository - rule_snapshot - FetchSnapshotItems]: Failed to scan snapshot item")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(groupsJSON), &item.Groups); err != nil {
+			log.WithError(err).WithField("rule_id", item.RuleID).Warn("[repository - rule_snapshot - FetchSnapshotItems]: Failed to parse rule groups")
+		}
+		if err := json.Unmarshal([]byte(ruleJSON), &item.Rule); err != nil {
+			log.WithError(err).WithField("rule_id", item.RuleID).Warn("[repository - rule_snapshot - FetchSnapshotItems]: Failed to parse raw rule")
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - rule_snapshot - FetchSnapshotItems]: Error iterating rows")
+		return nil, err
+	}
+
+	return items, nil
+}
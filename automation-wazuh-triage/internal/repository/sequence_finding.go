@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type sequenceFindingRepository struct {
+	db *sql.DB
+}
+
+func NewSequenceFindingRepository(db *sql.DB) domain.SequenceFindingRepository {
+	return &sequenceFindingRepository{
+		db: db,
+	}
+}
+
+const sequenceFindingColumns = "id, event_id, rule_name, level, group_key, key_values, event_ids, raw_event, first_seen, last_seen, created_at"
+
+func (r *sequenceFindingRepository) SaveSequenceFinding(ctx context.Context, finding *entity.SequenceFinding) error {
+	log := logger.WithRequestID(ctx)
+
+	keyValues, err := json.Marshal(finding.KeyValues)
+	if err != nil {
+		return err
+	}
+	eventIDs, err := json.Marshal(finding.EventIDs)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO sequence_findings (event_id, rule_name, level, group_key, key_values, event_ids, raw_event,
+			first_seen, last_seen, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		finding.EventID,
+		finding.RuleName,
+		finding.Level,
+		finding.GroupKey,
+		string(keyValues),
+		string(eventIDs),
+		finding.RawEvent,
+		finding.FirstSeen,
+		finding.LastSeen,
+		finding.CreatedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("rule_name", finding.RuleName).Error("[repository - sequence_finding - SaveSequenceFinding]: Failed to save sequence finding")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	finding.ID = int(id)
+
+	return nil
+}
+
+func (r *sequenceFindingRepository) FetchSequenceFindingByID(ctx context.Context, id int) (*entity.SequenceFinding, error) {
+	findings, err := r.fetchSequenceFindings(ctx, `
+		SELECT `+sequenceFindingColumns+`
+		FROM sequence_findings
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(findings) == 0 {
+		return nil, nil
+	}
+	return findings[0], nil
+}
+
+// FetchSequenceFindings returns the findings raised since the given time newest first, optionally of one rule
+func (r *sequenceFindingRepository) FetchSequenceFindings(ctx context.Context, since time.Time, ruleName string, limit int) ([]*entity.SequenceFinding, error) {
+	return r.fetchSequenceFindings(ctx, `
+		SELECT `+sequenceFindingColumns+`
+		FROM sequence_findings
+		WHERE created_at >= ? AND (? = '' OR rule_name = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, since, ruleName, ruleName, limit)
+}
+
+func (r *sequenceFindingRepository) fetchSequenceFindings(ctx context.Context, query string, args ...interface{}) ([]*entity.SequenceFinding, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - sequence_finding - fetchSequenceFindings]: Failed to fetch sequence findings")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []*entity.SequenceFinding
+
+	for rows.Next() {
+		var finding entity.SequenceFinding
+		var keyValues, eventIDs string
+		var rawEvent sql.NullString
+
+		if err := rows.Scan(
+			&finding.ID,
+			&finding.EventID,
+			&finding.RuleName,
+			&finding.Level,
+			&finding.GroupKey,
+			&keyValues,
+			&eventIDs,
+			&rawEvent,
+			&finding.FirstSeen,
+			&finding.LastSeen,
+			&finding.CreatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - sequence_finding - fetchSequenceFindings]: Failed to scan sequence finding")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(keyValues), &finding.KeyValues); err != nil {
+			log.WithError(err).WithField("finding_id", finding.ID).Warn("[repository - sequence_finding - fetchSequenceFindings]: Failed to parse key values")
+		}
+		if err := json.Unmarshal([]byte(eventIDs), &finding.EventIDs); err != nil {
+			log.WithError(err).WithField("finding_id", finding.ID).Warn("[repository - sequence_finding - fetchSequenceFindings]: Failed to parse event IDs")
+		}
+
+		finding.RawEvent = rawEvent.String
+		findings = append(findings, &finding)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - sequence_finding - fetchSequenceFindings]: Error iterating rows")
+		return nil, err
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+)
+
+type settingRepository struct {
+	db *sql.DB
+}
+
+func NewSettingRepository(db *sql.DB) domain.SettingRepository {
+	return &settingRepository{
+		db: db,
+	}
+}
+
+// FetchSetting returns the setting stored under key, or nil when it was never set
+func (r *settingRepository) FetchSetting(ctx context.Context, key string) (*entity.Setting, error) {
+	log := logger.WithRequestID(ctx)
+
+	var setting entity.Setting
+	err := r.db.QueryRowContext(ctx, `
+		SELECT key, value, updated_by, updated_at
+		FROM settings
+		WHERE key = ?
+	`, key).Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.WithError(err).WithField("key", key).Error("[repository - setting - FetchSetting]: Failed to fetch setting")
+		return nil, err
+	}
+
+	return &setting, nil
+}
+
+func (r *settingRepository) SaveSetting(ctx context.Context, setting *entity.Setting) error {
+	log := logger.WithRequestID(ctx)
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO settings (key, value, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_by = excluded.updated_by, updated_at = excluded.updated_at
+	`, setting.Key, setting.Value, setting.UpdatedBy, setting.UpdatedAt)
+	if err != nil {
+		log.WithError(err).WithField("key", setting.Key).Error("[repository - setting - SaveSetting]: Failed to save setting")
+		return err
+	}
+
+	return nil
+}
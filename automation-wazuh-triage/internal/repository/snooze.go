@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type snoozeRepository struct {
+	db *sql.DB
+}
+
+func NewSnoozeRepository(db *sql.DB) domain.SnoozeRepository {
+	return &snoozeRepository{
+		db: db,
+	}
+}
+
+const snoozeColumns = `s.id, s.rule_id, s.agent_id, s.fingerprint, s.max_level, s.reason, s.created_by, s.created_at,
+	s.expires_at, s.status, (SELECT COUNT(*) FROM snoozed_alerts a WHERE a.snooze_id = s.id), s.ended_by, s.ended_at,
+	s.summary`
+
+const snoozedAlertColumns = "id, snooze_id, event_id, rule_id, rule_level, agent_name, closed_event_id, alert_at, snoozed_at"
+
+func (r *snoozeRepository) SaveSnooze(ctx context.Context, snooze *entity.Snooze) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO snoozes (rule_id, agent_id, fingerprint, max_level, reason, created_by, created_at, expires_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		snooze.RuleID,
+		snooze.AgentID,
+		snooze.Fingerprint,
+		snooze.MaxLevel,
+		snooze.Reason,
+		snooze.CreatedBy,
+		snooze.CreatedAt,
+		snooze.ExpiresAt,
+		snooze.Status,
+	)
+	if err != nil {
+		log.WithError(err).Error("[repository - snooze - SaveSnooze]: Failed to save snooze")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	snooze.ID = int(id)
+
+	return nil
+}
+
+func (r *snoozeRepository) FetchSnoozeByID(ctx context.Context, id int) (*entity.Snooze, error) {
+	snoozes, err := r.fetchSnoozes(ctx, `
+		SELECT `+snoozeColumns+`
+		FROM snoozes s
+		WHERE s.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(snoozes) == 0 {
+		return nil, nil
+	}
+	return snoozes[0], nil
+}
+
+// FetchSnoozes returns the snoozes newest first, optionally narrowed to one status as of now. A snooze past its
+// expiry that was not summarized yet counts as expired.
+func (r *snoozeRepository) FetchSnoozes(ctx context.Context, status string, now time.Time) ([]*entity.Snooze, error) {
+	switch status {
+	case "":
+		return r.fetchSnoozes(ctx, `
+			SELECT `+snoozeColumns+`
+			FROM snoozes s
+			ORDER BY s.id DESC
+		`)
+	case entity.SnoozeStatusActive:
+		return r.fetchSnoozes(ctx, `
+			SELECT `+snoozeColumns+`
+			FROM snoozes s
+			WHERE s.status = ? AND s.expires_at > ?
+			ORDER BY s.id DESC
+		`, entity.SnoozeStatusActive, now)
+	case entity.SnoozeStatusExpired:
+		return r.fetchSnoozes(ctx, `
+			SELECT `+snoozeColumns+`
+			FROM snoozes s
+			WHERE s.status = ? OR (s.status = ? AND s.expires_at <= ?)
+			ORDER BY s.id DESC
+		`, entity.SnoozeStatusExpired, entity.SnoozeStatusActive, now)
+	}
+
+	return r.fetchSnoozes(ctx, `
+		SELECT `+snoozeColumns+`
+		FROM snoozes s
+		WHERE s.status = ?
+		ORDER BY s.id DESC
+	`, status)
+}
+
+// FetchUnendedSnoozes returns the snoozes that were not ended yet, including those past their expiry, oldest first
+func (r *snoozeRepository) FetchUnendedSnoozes(ctx context.Context) ([]*entity.Snooze, error) {
+	return r.fetchSnoozes(ctx, `
+		SELECT `+snoozeColumns+`
+		FROM snoozes s
+		WHERE s.status = ?
+		ORDER BY s.id ASC
+	`, entity.SnoozeStatusActive)
+}
+
+// EndSnooze stores how an active snooze ended along with its summary. It returns sql.ErrNoRows when the
+// snooze already ended.
+func (r *snoozeRepository) EndSnooze(ctx context.Context, id int, status string, endedBy string, endedAt time.Time, summary *entity.SnoozeSummary) error {
+	log := logger.WithRequestID(ctx)
+
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE snoozes
+		SET status = ?, ended_by = ?, ended_at = ?, summary = ?
+		WHERE id = ? AND status = ?
+	`, status, endedBy, endedAt, string(encoded), id, entity.SnoozeStatusActive)
+	if err != nil {
+		log.WithError(err).WithField("snooze_id", id).Error("[repository - snooze - EndSnooze]: Failed to end snooze")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SaveSnoozedAlert records an alert closed by a snooze unless a snooze already closed it, and reports whether
+// it was recorded
+func (r *snoozeRepository) SaveSnoozedAlert(ctx context.Context, alert *entity.SnoozedAlert) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO snoozed_alerts (snooze_id, event_id, rule_id, rule_level, agent_name, closed_event_id, alert_at, snoozed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		alert.SnoozeID,
+		alert.EventID,
+		alert.RuleID,
+		alert.RuleLevel,
+		alert.AgentName,
+		alert.ClosedEventID,
+		alert.AlertAt,
+		alert.SnoozedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("event_id", alert.EventID).Error("[repository - snooze - SaveSnoozedAlert]: Failed to save snoozed alert")
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	alert.ID = int(id)
+
+	return true, nil
+}
+
+// FetchSnoozedAlerts returns the alerts a snooze closed, oldest first
+func (r *snoozeRepository) FetchSnoozedAlerts(ctx context.Context, snoozeID int) ([]*entity.SnoozedAlert, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+snoozedAlertColumns+`
+		FROM snoozed_alerts
+		WHERE snooze_id = ?
+		ORDER BY alert_at ASC, id ASC
+	`, snoozeID)
+	if err != nil {
+		log.WithError(err).WithField("snooze_id", snoozeID).Error("[repository - snooze - FetchSnoozedAlerts]: Failed to fetch snoozed alerts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*entity.SnoozedAlert
+
+	for rows.Next() {
+		var alert entity.SnoozedAlert
+
+		if err := rows.Scan(
+			&alert.ID,
+			&alert.SnoozeID,
+			&alert.EventID,
+			&alert.RuleID,
+			&alert.RuleLevel,
+			&alert.AgentName,
+			&alert.ClosedEventID,
+			&alert.AlertAt,
+			&alert.SnoozedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - snooze - FetchSnoozedAlerts]: Failed to scan snoozed alert")
+			return nil, err
+		}
+
+		alerts = append(alerts, &alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - snooze - FetchSnoozedAlerts]: Error iterating rows")
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (r *snoozeRepository) fetchSnoozes(ctx context.Context, query string, args ...interface{}) ([]*entity.Snooze, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - snooze - fetchSnoozes]: Failed to fetch snoozes")
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var snoozes []*entity.Snooze
+
+	for rows.Next() {
+		var snooze entity.Snooze
+		var endedAt sql.NullTime
+		var summary sql.NullString
+
+		if err := rows.Scan(
+			&snooze.ID,
+			&snooze.RuleID,
+			&snooze.AgentID,
+			&snooze.Fingerprint,
+			&snooze.MaxLevel,
+			&snooze.Reason,
+			&snooze.CreatedBy,
+			&snooze.CreatedAt,
+			&snooze.ExpiresAt,
+			&snooze.Status,
+			&snooze.SuppressedCount,
+			&snooze.EndedBy,
+			&endedAt,
+			&summary,
+		); err != nil {
+			log.WithError(err).Error("[repository - snooze - fetchSnoozes]: Failed to scan snooze")
+			return nil, err
+		}
+
+		if summary.String != "" {
+			snooze.Summary = &entity.SnoozeSummary{}
+			if err := json.Unmarshal([]byte(summary.String), snooze.Summary); err != nil {
+				log.WithError(err).WithField("snooze_id", snooze.ID).Warn("[repository - snooze - fetchSnoozes]: Failed to parse snooze summary")
+				snooze.Summary = nil
+			}
+		}
+
+		// A snooze reads as expired once past its expiry, before it is summarized
+		if snooze.Status == entity.SnoozeStatusActive && !snooze.ExpiresAt.After(now) {
+			snooze.Status = entity.SnoozeStatusExpired
+		}
+
+		snooze.EndedAt = nullTimePtr(endedAt)
+		snoozes = append(snoozes, &snooze)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - snooze - fetchSnoozes]: Error iterating rows")
+		return nil, err
+	}
+
+	return snoozes, nil
+}
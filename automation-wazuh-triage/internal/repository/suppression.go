@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+type suppressionRepository struct {
+	db *sql.DB
+}
+
+func NewSuppressionRepository(db *sql.DB) domain.SuppressionRepository {
+	return &suppressionRepository{
+		db: db,
+	}
+}
+
+const suppressionColumns = `id, parent_rule_id, conditions, description, created_by, status, wazuh_rule_id, filename, created_at, deployed_at`
+
+func (r *suppressionRepository) SaveSuppression(ctx context.Context, suppression *entity.Suppression) error {
+	log := logger.WithRequestID(ctx)
+
+	conditionsJSON, err := json.Marshal(suppression.Conditions)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO suppressions (parent_rule_id, conditions, description, created_by, status, wazuh_rule_id, filename, created_at, deployed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		suppression.ParentRuleID,
+		string(conditionsJSON),
+		suppression.Description,
+		suppression.CreatedBy,
+		suppression.Status,
+		suppression.WazuhRuleID,
+		suppression.Filename,
+		suppression.CreatedAt,
+		suppression.DeployedAt,
+	)
+	if err != nil {
+		log.WithError(err).Error("[repository - suppression - SaveSuppression]: Failed to save suppression")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	suppression.ID = int(id)
+
+	log.WithField("suppression_id", suppression.ID).Info("[repository - suppression - SaveSuppression]: Successfully saved suppression")
+	return nil
+}
+
+func (r *suppressionRepository) FetchSuppressions(ctx context.Context) ([]*entity.Suppression, error) {
+	return r.fetchSuppressions(ctx, `SELECT `+suppressionColumns+` FROM suppressions ORDER BY id DESC`)
+}
+
+func (r *suppressionRepository) FetchSuppressionsByStatus(ctx context.Context, status string) ([]*entity.Suppression, error) {
+	return r.fetchSuppressions(ctx, `SELECT `+suppressionColumns+` FROM suppressions WHERE status = ? ORDER BY id`, status)
+}
+
+func (r *suppressionRepository) FetchSuppressionByID(ctx context.Context, id int) (*entity.Suppression, error) {
+	suppressions, err := r.fetchSuppressions(ctx, `SELECT `+suppressionColumns+` FROM suppressions WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(suppressions) == 0 {
+		return nil, nil // Return nil to indicate not found
+	}
+
+	return suppressions[0], nil
+}
+
+func (r *suppressionRepository) UpdateSuppression(ctx context.Context, suppression *entity.Suppression) error {
+	log := logger.WithRequestID(ctx)
+
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE suppressions
+		SET status = ?, wazuh_rule_id = ?, filename = ?, deployed_at = ?
+		WHERE id = ?
+	`,
+		suppression.Status,
+		suppression.WazuhRuleID,
+		suppression.Filename,
+		suppression.DeployedAt,
+		suppression.ID,
+	)
+	if err != nil {
+		log.WithError(err).WithField("suppression_id", suppression.ID).Error("[repository - suppression - UpdateSuppression]: Failed to update suppression")
+		return err
+	}
+
+	return nil
+}
+
+func (r *suppressionRepository) fetchSuppressions(ctx context.Context, query string, args ...interface{}) ([]*entity.Suppression, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - suppression - fetchSuppressions]: Failed to fetch suppressions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppressions []*entity.Suppression
+
+	for rows.Next() {
+		var suppression entity.Suppression
+		var conditionsJSON string
+		var deployedAt sql.NullTime
+
+		if err := rows.Scan(
+			&suppression.ID,
+			&suppression.ParentRuleID,
+			&conditionsJSON,
+			&suppression.Description,
+			&suppression.CreatedBy,
+			&suppression.Status,
+			&suppression.WazuhRuleID,
+			&suppression.Filename,
+			&suppression.CreatedAt,
+			&deployedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - suppression - fetchSuppressions]: Failed to scan suppression")
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(conditionsJSON), &suppression.Conditions); err != nil {
+			log.WithError(err).WithField("suppression_id", suppression.ID).Warn("[repository - suppression - fetchSuppressions]: Failed to parse conditions")
+		}
+		if deployedAt.Valid {
+			suppression.DeployedAt = &deployedAt.Time
+		}
+
+		suppressions = append(suppressions, &suppression)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - suppression - fetchSuppressions]: Error iterating rows")
+		return nil, err
+	}
+
+	return suppressions, nil
+}
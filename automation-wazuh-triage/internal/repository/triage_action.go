@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type triageActionRepository struct {
+	db *sql.DB
+}
+
+func NewTriageActionRepository(db *sql.DB) domain.TriageActionRepository {
+	return &triageActionRepository{
+		db: db,
+	}
+}
+
+const triageActionColumns = "id, event_id, rule_id, agent_id, agent_name, action, actor, alert_at, created_at"
+
+func (r *triageActionRepository) SaveTriageAction(ctx context.Context, action *entity.TriageAction) error {
+	log := logger.WithRequestID(ctx)
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO triage_actions (event_id, rule_id, agent_id, agent_name, action, actor, alert_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		action.EventID,
+		action.RuleID,
+		action.AgentID,
+		action.AgentName,
+		action.Action,
+		action.Actor,
+		action.AlertAt,
+		action.CreatedAt,
+	)
+	if err != nil {
+		log.WithError(err).WithField("event_id", action.EventID).Error("[repository - triage_action - SaveTriageAction]: Failed to save triage action")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	action.ID = int(id)
+
+	return nil
+}
+
+func (r *triageActionRepository) FetchTriageActionsByEventID(ctx context.Context, eventID string) ([]*entity.TriageAction, error) {
+	return r.fetchTriageActions(ctx, `
+		SELECT `+triageActionColumns+`
+		FROM triage_actions
+		WHERE event_id = ?
+		ORDER BY created_at ASC
+	`, eventID)
+}
+
+// FetchTriageActionsForClosuresSince returns every action of the events closed since the given time,
+// including acknowledgements made before it
+func (r *triageActionRepository) FetchTriageActionsForClosuresSince(ctx context.Context, since time.Time) ([]*entity.TriageAction, error) {
+	return r.fetchTriageActions(ctx, `
+		SELECT `+triageActionColumns+`
+		FROM triage_actions
+		WHERE event_id IN (SELECT event_id FROM closed_events WHERE close_at >= ?)
+		ORDER BY created_at ASC
+	`, since)
+}
+
+// FetchTriageActionsForAlertsSince returns every action of the alerts fired since the given time
+func (r *triageActionRepository) FetchTriageActionsForAlertsSince(ctx context.Context, since time.Time) ([]*entity.TriageAction, error) {
+	return r.fetchTriageActions(ctx, `
+		SELECT `+triageActionColumns+`
+		FROM triage_actions
+		WHERE alert_at >= ?
+		ORDER BY created_at ASC
+	`, since)
+}
+
+// FetchTriageActionsByAgent returns up to limit actions on the alerts of an agent taken in [from, to], oldest
+// first by creation time then ID. Actions recorded before the agent ID was kept are matched by agent name when
+// one is given. A positive afterID returns the actions ordered after that action.
+func (r *triageActionRepository) FetchTriageActionsByAgent(ctx context.Context, agentID string, agentName string, ruleID string, from time.Time, to time.Time, afterID int, limit int) ([]*entity.TriageAction, error) {
+	conditions := []string{"created_at >= ?", "created_at <= ?"}
+	args := []interface{}{from, to}
+
+	if agentName != "" {
+		conditions = append(conditions, "(agent_id = ? OR (agent_id = '' AND agent_name = ?))")
+		args = append(args, agentID, agentName)
+	} else {
+		conditions = append(conditions, "agent_id = ?")
+		args = append(args, agentID)
+	}
+	if ruleID != "" {
+		conditions = append(conditions, "rule_id = ?")
+		args = append(args, ruleID)
+	}
+	if afterID > 0 {
+		conditions = append(conditions, `(created_at > (SELECT created_at FROM triage_actions WHERE id = ?)
+			OR (created_at = (SELECT created_at FROM triage_actions WHERE id = ?) AND id > ?))`)
+		args = append(args, afterID, afterID, afterID)
+	}
+	args = append(args, limit)
+
+	return r.fetchTriageActions(ctx, `
+		SELECT `+triageActionColumns+`
+		FROM triage_actions
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY created_at ASC, id ASC
+		LIMIT ?
+	`, args...)
+}
+
+func (r *triageActionRepository) fetchTriageActions(ctx context.Context, query string, args ...interface{}) ([]*entity.TriageAction, error) {
+	log := logger.WithRequestID(ctx)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("[repository - triage_action - fetchTriageActions]: Failed to fetch triage actions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []*entity.TriageAction
+
+	for rows.Next() {
+		var action entity.TriageAction
+		var alertAt sql.NullTime
+
+		if err := rows.Scan(
+			&action.ID,
+			&action.EventID,
+			&action.RuleID,
+			&action.AgentID,
+			&action.AgentName,
+			&action.Action,
+			&action.Actor,
+			&alertAt,
+			&action.CreatedAt,
+		); err != nil {
+			log.WithError(err).Error("[repository - triage_action - fetchTriageActions]: Failed to scan triage action")
+			return nil, err
+		}
+
+		action.AlertAt = nullTimePtr(alertAt)
+		actions = append(actions, &action)
+	}
+
+	if err = rows.Err(); err != nil {
+		log.WithError(err).Error("[repository - triage_action - fetchTriageActions]: Error iterating rows")
+		return nil, err
+	}
+
+	return actions, nil
+}
@@ -8,11 +8,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/olivere/elastic/v7"
 )
 
+// termsAggregationSize bounds the number of distinct values returned by a count aggregation
+const termsAggregationSize = 1000
+
 type wazuhEventRepository struct {
 	openSearchClient *elastic.Client
 }
@@ -35,22 +39,7 @@ func (r *wazuhEventRepository) FetchSecurityEvents(ctx context.Context, filter *
 		)
 
 	if filter.LevelRange != nil {
-		rangeQuery := elastic.NewRangeQuery("rule.level")
-
-		if filter.LevelRange.Gte != nil {
-			rangeQuery = rangeQuery.Gte(filter.LevelRange.Gte)
-		}
-		if filter.LevelRange.Gt != nil {
-			rangeQuery = rangeQuery.Gt(filter.LevelRange.Gt)
-		}
-		if filter.LevelRange.Lte != nil {
-			rangeQuery = rangeQuery.Lte(filter.LevelRange.Lte)
-		}
-		if filter.LevelRange.Lt != nil {
-			rangeQuery = rangeQuery.Lt(filter.LevelRange.Lt)
-		}
-
-		esQuery = esQuery.Filter(rangeQuery)
+		esQuery = esQuery.Filter(levelRangeQuery(filter.LevelRange))
 	}
 
 	limit := 10
@@ -73,6 +62,331 @@ func (r *wazuhEventRepository) FetchSecurityEvents(ctx context.Context, filter *
 	return searchResult.Hits.Hits, nil
 }
 
+// levelRangeQuery restricts alerts to the rule levels of an event search
+func levelRangeQuery(levelRange *model.RangeQuery) *elastic.RangeQuery {
+	rangeQuery := elastic.NewRangeQuery("rule.level")
+
+	if levelRange.Gte != nil {
+		rangeQuery = rangeQuery.Gte(levelRange.Gte)
+	}
+	if levelRange.Gt != nil {
+		rangeQuery = rangeQuery.Gt(levelRange.Gt)
+	}
+	if levelRange.Lte != nil {
+		rangeQuery = rangeQuery.Lte(levelRange.Lte)
+	}
+	if levelRange.Lt != nil {
+		rangeQuery = rangeQuery.Lt(levelRange.Lt)
+	}
+
+	return rangeQuery
+}
+
+// FetchFingerprintGroups groups the alerts fired at or after since within the level range of the filter by the
+// values of the fields, up to size groups in key order from a composite aggregation. A field with a script is
+// grouped by the value the script computes from the alert. Every group holds its newest alert under "latest" and
+// its oldest and newest timestamps under "first_seen" and "last_seen". Pass the returned key as after for the
+// next groups; it is nil once every group was read.
+func (r *wazuhEventRepository) FetchFingerprintGroups(ctx context.Context, filter *model.FetchEventsRequest, since time.Time, fields []string, scripts map[string]*elastic.Script, after map[string]interface{}, size int) ([]*elastic.AggregationBucketCompositeItem, map[string]interface{}, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").Gte(since.UTC().Format(time.RFC3339Nano)),
+		)
+	if filter.LevelRange != nil {
+		esQuery = esQuery.Filter(levelRangeQuery(filter.LevelRange))
+	}
+
+	sources := make([]elastic.CompositeAggregationValuesSource, 0, len(fields))
+	for i, field := range fields {
+		// Sources are named by position, field names may contain dots
+		source := elastic.NewCompositeAggregationTermsValuesSource(fmt.Sprintf("f%d", i)).MissingBucket(true)
+		if script, ok := scripts[field]; ok {
+			source = source.Script(script)
+		} else {
+			source = source.Field(field)
+		}
+		sources = append(sources, source)
+	}
+
+	aggregation := elastic.NewCompositeAggregation().
+		Size(size).
+		Sources(sources...).
+		SubAggregation("latest", elastic.NewTopHitsAggregation().Size(1).Sort("timestamp", false)).
+		SubAggregation("first_seen", elastic.NewMinAggregation().Field("timestamp")).
+		SubAggregation("last_seen", elastic.NewMaxAggregation().Field("timestamp"))
+	if len(after) > 0 {
+		aggregation = aggregation.AggregateAfter(after)
+	}
+
+	searchSource := elastic.NewSearchSource().
+		Size(0).
+		Query(esQuery).
+		Aggregation("groups", aggregation)
+
+	searchResult, err := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		SearchSource(searchSource).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).Error("[repository - event - FetchFingerprintGroups]: Failed to group security events")
+		return nil, nil, err
+	}
+
+	groups, found := searchResult.Aggregations.Composite("groups")
+	if !found {
+		return nil, nil, nil
+	}
+
+	// A short page is the last one
+	if len(groups.Buckets) < size {
+		return groups.Buckets, nil, nil
+	}
+	return groups.Buckets, groups.AfterKey, nil
+}
+
+// FetchSecurityEventsSince returns up to limit alerts fired at or after since, sorted by timestamp then id,
+// oldest first. Pass the sort values of the last hit of a page as searchAfter to read the next one, so alerts
+// sharing a timestamp are never read twice or skipped.
+func (r *wazuhEventRepository) FetchSecurityEventsSince(ctx context.Context, since time.Time, searchAfter []interface{}, limit int) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").Gte(since.UTC().Format(time.RFC3339Nano)),
+		)
+
+	search := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		Size(limit).
+		SortBy(
+			elastic.NewFieldSort("timestamp").Asc(),
+			elastic.NewFieldSort("id").Asc().UnmappedType("keyword"),
+		).
+		Query(esQuery)
+	if len(searchAfter) > 0 {
+		search = search.SearchAfter(searchAfter...)
+	}
+
+	searchResult, err := search.Do(ctx)
+	if err != nil {
+		log.WithError(err).Error("[repository - event - FetchSecurityEventsSince]: Failed to fetch security events")
+		return nil, err
+	}
+
+	return searchResult.Hits.Hits, nil
+}
+
+// FetchVulnerabilityAlertsSince returns up to limit vulnerability-detector alerts, those carrying
+// data.vulnerability.cve, fired at or after since, of one agent unless agentID is empty, sorted newest first by
+// timestamp then id. Pass the sort values of the last hit of a page as searchAfter to read the next one.
+func (r *wazuhEventRepository) FetchVulnerabilityAlertsSince(ctx context.Context, since time.Time, agentID string, searchAfter []interface{}, limit int) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").Gte(since.UTC().Format(time.RFC3339Nano)),
+			elastic.NewExistsQuery("data.vulnerability.cve"),
+		)
+	if agentID != "" {
+		esQuery = esQuery.Filter(elastic.NewTermQuery("agent.id", agentID))
+	}
+
+	search := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		Size(limit).
+		SortBy(
+			elastic.NewFieldSort("timestamp").Desc(),
+			elastic.NewFieldSort("id").Desc().UnmappedType("keyword"),
+		).
+		Query(esQuery)
+	if len(searchAfter) > 0 {
+		search = search.SearchAfter(searchAfter...)
+	}
+
+	searchResult, err := search.Do(ctx)
+	if err != nil {
+		log.WithError(err).Error("[repository - event - FetchVulnerabilityAlertsSince]: Failed to fetch vulnerability alerts")
+		return nil, err
+	}
+
+	return searchResult.Hits.Hits, nil
+}
+
+// FetchComplianceAlerts returns up to limit alerts fired in [from, to) that are tagged with the framework,
+// by the rule.<framework> field of the alert or by being one of the given rules, newest first
+func (r *wazuhEventRepository) FetchComplianceAlerts(ctx context.Context, framework string, ruleIDs []string, from time.Time, to time.Time, limit int) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	tagged := elastic.NewBoolQuery().
+		Should(elastic.NewExistsQuery("rule." + framework)).
+		MinimumNumberShouldMatch(1)
+	if len(ruleIDs) > 0 {
+		values := make([]interface{}, len(ruleIDs))
+		for i, ruleID := range ruleIDs {
+			values[i] = ruleID
+		}
+		tagged.Should(elastic.NewTermsQuery("rule.id", values...))
+	}
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").
+				Gte(from.UTC().Format(time.RFC3339Nano)).
+				Lt(to.UTC().Format(time.RFC3339Nano)),
+			tagged,
+		)
+
+	searchResult, err := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		Size(limit).
+		Sort("timestamp", false).
+		Query(esQuery).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).WithField("framework", framework).Error("[repository - event - FetchComplianceAlerts]: Failed to fetch compliance alerts")
+		return nil, err
+	}
+
+	return searchResult.Hits.Hits, nil
+}
+
+// FetchAlertsSharingValues returns up to limit alerts fired in [from, to] holding one of the values in one of
+// the fields, oldest first
+func (r *wazuhEventRepository) FetchAlertsSharingValues(ctx context.Context, fields []string, values []string, from time.Time, to time.Time, limit int) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	terms := make([]interface{}, len(values))
+	for i, value := range values {
+		terms[i] = value
+	}
+	sharing := elastic.NewBoolQuery().MinimumNumberShouldMatch(1)
+	for _, field := range fields {
+		sharing.Should(elastic.NewTermsQuery(field, terms...))
+	}
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").
+				Gte(from.UTC().Format(time.RFC3339Nano)).
+				Lte(to.UTC().Format(time.RFC3339Nano)),
+			sharing,
+		)
+
+	searchResult, err := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		Size(limit).
+		Sort("timestamp", true).
+		Query(esQuery).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).WithField("fields", fields).Error("[repository - event - FetchAlertsSharingValues]: Failed to fetch alerts sharing values")
+		return nil, err
+	}
+
+	return searchResult.Hits.Hits, nil
+}
+
+// retroHuntFields are the fields an indicator of each type is looked up in; every indicator is also searched
+// for as a phrase of full_log
+var retroHuntFields = map[string][]string{
+	entity.IOCTypeIP:   {"data.srcip", "data.dstip", "agent.ip", "data.win.eventdata.ipAddress"},
+	entity.IOCTypeHash: {"syscheck.md5_after", "syscheck.sha1_after", "syscheck.sha256_after"},
+}
+
+// FetchIndicatorAlerts returns up to size documents of an index pattern, fired in [from, to), that may hold one
+// of the indicators, by type. Documents are sorted by timestamp then id, oldest first; pass the sort values of
+// the last hit of a page as searchAfter to read the next one.
+func (r *wazuhEventRepository) FetchIndicatorAlerts(ctx context.Context, index string, indicators map[string][]string, from time.Time, to time.Time, searchAfter []interface{}, size int) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	candidates := elastic.NewBoolQuery().MinimumNumberShouldMatch(1)
+	for iocType, values := range indicators {
+		terms := make([]interface{}, len(values))
+		for i, value := range values {
+			terms[i] = value
+			candidates.Should(elastic.NewMatchPhraseQuery("full_log", value))
+			if iocType == entity.IOCTypeDomain {
+				candidates.Should(elastic.NewWildcardQuery("data.url", "*"+value+"*"))
+			}
+		}
+		for _, field := range retroHuntFields[iocType] {
+			candidates.Should(elastic.NewTermsQuery(field, terms...))
+		}
+	}
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").
+				Gte(from.UTC().Format(time.RFC3339Nano)).
+				Lt(to.UTC().Format(time.RFC3339Nano)),
+			candidates,
+		)
+
+	search := r.openSearchClient.Search().
+		Index(index).
+		Size(size).
+		SortBy(
+			elastic.NewFieldSort("timestamp").Asc(),
+			// archived events may not carry an id, so the tie-breaker must tolerate indices without it
+			elastic.NewFieldSort("id").Asc().UnmappedType("keyword"),
+		).
+		Query(esQuery)
+	if len(searchAfter) > 0 {
+		search = search.SearchAfter(searchAfter...)
+	}
+
+	searchResult, err := search.Do(ctx)
+	if err != nil {
+		log.WithError(err).WithField("index", index).Error("[repository - event - FetchIndicatorAlerts]: Failed to fetch indicator alerts")
+		return nil, err
+	}
+
+	return searchResult.Hits.Hits, nil
+}
+
+// FetchAgentAlerts returns up to size alerts of an agent fired in [from, to], optionally at or above a rule
+// level and of one rule. Alerts are sorted by timestamp then id, oldest first; pass the sort values of the last
+// hit of a page as searchAfter to read the next one.
+func (r *wazuhEventRepository) FetchAgentAlerts(ctx context.Context, agentID string, from time.Time, to time.Time, minLevel int, ruleID string, searchAfter []interface{}, size int) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewTermQuery("agent.id", agentID),
+			elastic.NewRangeQuery("timestamp").
+				Gte(from.UTC().Format(time.RFC3339Nano)).
+				Lte(to.UTC().Format(time.RFC3339Nano)),
+		)
+	if minLevel > 0 {
+		esQuery.Filter(elastic.NewRangeQuery("rule.level").Gte(minLevel))
+	}
+	if ruleID != "" {
+		esQuery.Filter(elastic.NewTermQuery("rule.id", ruleID))
+	}
+
+	search := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		Size(size).
+		SortBy(
+			elastic.NewFieldSort("timestamp").Asc(),
+			elastic.NewFieldSort("id").Asc().UnmappedType("keyword"),
+		).
+		Query(esQuery)
+	if len(searchAfter) > 0 {
+		search = search.SearchAfter(searchAfter...)
+	}
+
+	searchResult, err := search.Do(ctx)
+	if err != nil {
+		log.WithError(err).WithField("agent_id", agentID).Error("[repository - event - FetchAgentAlerts]: Failed to fetch agent alerts")
+		return nil, err
+	}
+
+	return searchResult.Hits.Hits, nil
+}
+
 func (r *wazuhEventRepository) FetchSecurityEventByID(ctx context.Context, eventID string) (*entity.WazuhSecurityEvent, *elastic.SearchHit, error) {
 	log := logger.WithRequestID(ctx)
 
@@ -107,3 +421,133 @@ func (r *wazuhEventRepository) FetchSecurityEventByID(ctx context.Context, event
 
 	return &event, searchResult.Hits.Hits[0], nil
 }
+
+// CountEventsByField counts the alerts since the given time per value of a keyword field such as rule.id
+func (r *wazuhEventRepository) CountEventsByField(ctx context.Context, field string, since time.Time) (map[string]int64, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").Gte(since.UTC().Format(time.RFC3339)),
+		)
+
+	searchSource := elastic.NewSearchSource().
+		Size(0).
+		Query(esQuery).
+		Aggregation("values", elastic.NewTermsAggregation().Field(field).Size(termsAggregationSize))
+
+	searchResult, err := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		SearchSource(searchSource).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).WithField("field", field).Error("[repository - event - CountEventsByField]: Failed to aggregate security events")
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+
+	buckets, found := searchResult.Aggregations.Terms("values")
+	if !found {
+		return counts, nil
+	}
+
+	for _, bucket := range buckets.Buckets {
+		counts[fmt.Sprint(bucket.Key)] = bucket.DocCount
+	}
+
+	return counts, nil
+}
+
+// CountEventsByFieldAfter counts the alerts since the given time per value of a keyword field, up to size values
+// in value order from a composite aggregation. Pass the returned key as after to count the next values; it is nil
+// once every value was counted.
+func (r *wazuhEventRepository) CountEventsByFieldAfter(ctx context.Context, field string, since time.Time, after map[string]interface{}, size int) (map[string]int64, map[string]interface{}, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").Gte(since.UTC().Format(time.RFC3339)),
+		)
+
+	aggregation := elastic.NewCompositeAggregation().
+		Size(size).
+		Sources(elastic.NewCompositeAggregationTermsValuesSource("value").Field(field))
+	if len(after) > 0 {
+		aggregation = aggregation.AggregateAfter(after)
+	}
+
+	searchSource := elastic.NewSearchSource().
+		Size(0).
+		Query(esQuery).
+		Aggregation("values", aggregation)
+
+	searchResult, err := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		SearchSource(searchSource).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).WithField("field", field).Error("[repository - event - CountEventsByFieldAfter]: Failed to aggregate security events")
+		return nil, nil, err
+	}
+
+	counts := map[string]int64{}
+
+	buckets, found := searchResult.Aggregations.Composite("values")
+	if !found {
+		return counts, nil, nil
+	}
+
+	for _, bucket := range buckets.Buckets {
+		counts[fmt.Sprint(bucket.Key["value"])] = bucket.DocCount
+	}
+
+	// A short page is the last one
+	if len(buckets.Buckets) < size {
+		return counts, nil, nil
+	}
+	return counts, buckets.AfterKey, nil
+}
+
+// CountEventsByDay counts the alerts since the given time per UTC day, keyed by YYYY-MM-DD
+func (r *wazuhEventRepository) CountEventsByDay(ctx context.Context, since time.Time) (map[string]int64, error) {
+	log := logger.WithRequestID(ctx)
+
+	esQuery := elastic.NewBoolQuery().
+		Filter(
+			elastic.NewRangeQuery("timestamp").Gte(since.UTC().Format(time.RFC3339)),
+		)
+
+	searchSource := elastic.NewSearchSource().
+		Size(0).
+		Query(esQuery).
+		Aggregation("days", elastic.NewDateHistogramAggregation().
+			Field("timestamp").
+			CalendarInterval("day").
+			Format("yyyy-MM-dd").
+			TimeZone("UTC"))
+
+	searchResult, err := r.openSearchClient.Search().
+		Index("wazuh-alerts-*").
+		SearchSource(searchSource).
+		Do(ctx)
+	if err != nil {
+		log.WithError(err).Error("[repository - event - CountEventsByDay]: Failed to aggregate security events by day")
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+
+	histogram, found := searchResult.Aggregations.DateHistogram("days")
+	if !found {
+		return counts, nil
+	}
+
+	for _, bucket := range histogram.Buckets {
+		if bucket.KeyAsString != nil {
+			counts[*bucket.KeyAsString] = bucket.DocCount
+		}
+	}
+
+	return counts, nil
+}
@@ -6,6 +6,9 @@ import (
 	"automation-wazuh-triage/internal/usecase"
 	"automation-wazuh-triage/pkg/database"
 	"automation-wazuh-triage/pkg/middleware"
+	"automation-wazuh-triage/pkg/notifier"
+	"automation-wazuh-triage/pkg/scheduler"
+	"context"
 	"log"
 	"time"
 
@@ -26,14 +29,98 @@ func SetupRoutes(app *fiber.App, openSearchClient *elastic.Client) {
 	eventRepository := repository.NewWazuhEventRepository(openSearchClient)
 	closedEventRepository := repository.NewClosedEventRepository(db)
 	ruleRepository := repository.NewRuleRepository()
+	ruleSnapshotRepository := repository.NewRuleSnapshotRepository(db)
+	ruleFileRepository := repository.NewRuleFileRepository()
+	ruleFileVersionRepository := repository.NewRuleFileVersionRepository(db)
+	suppressionRepository := repository.NewSuppressionRepository(db)
+	logtestRepository := repository.NewLogtestRepository()
+	proposalRepository := repository.NewProposalRepository(db)
+	triageActionRepository := repository.NewTriageActionRepository(db)
+	autoCloseDecisionRepository := repository.NewAutoCloseDecisionRepository(db)
+	settingRepository := repository.NewSettingRepository(db)
+	qaReviewRepository := repository.NewQAReviewRepository(db)
+	guardrailTripRepository := repository.NewGuardrailTripRepository(db)
+	caseRepository := repository.NewCaseRepository(db)
+	sequenceFindingRepository := repository.NewSequenceFindingRepository(db)
+	snoozeRepository := repository.NewSnoozeRepository(db)
+	maintenanceRepository := repository.NewMaintenanceRepository(db)
+	agentRepository := repository.NewAgentRepository()
+	assetRepository := repository.NewAssetRepository(db)
+	geoIPRepository := repository.NewGeoIPRepository()
+	cveRepository := repository.NewCVERepository(db)
+	retroHuntRepository := repository.NewRetroHuntRepository(db)
+
+	notify := notifier.NewNotifier()
 
 	// Initialize usecase
-	eventUsecase := usecase.NewEventUsecase(eventRepository, closedEventRepository, ruleRepository)
-	ruleUsecase := usecase.NewRuleUsecase(ruleRepository)
+	assetUsecase := usecase.NewAssetUsecase(assetRepository)
+	geoIPUsecase := usecase.NewGeoIPUsecase(geoIPRepository)
+	iocUsecase := usecase.NewIOCUsecase()
+	cveUsecase := usecase.NewCVEUsecase(cveRepository, eventRepository)
+	mitreUsecase := usecase.NewMitreUsecase()
+	guardrailUsecase := usecase.NewGuardrailUsecase(settingRepository, guardrailTripRepository, closedEventRepository, iocUsecase, notify)
+	fingerprintUsecase := usecase.NewFingerprintUsecase(eventRepository, closedEventRepository, triageActionRepository, settingRepository)
+	eventUsecase := usecase.NewEventUsecase(eventRepository, closedEventRepository, ruleRepository, triageActionRepository, autoCloseDecisionRepository, guardrailUsecase, assetUsecase, geoIPUsecase, iocUsecase, cveUsecase, mitreUsecase)
+	observableUsecase := usecase.NewObservableUsecase(eventRepository, closedEventRepository)
+	pivotUsecase := usecase.NewPivotUsecase(eventRepository, closedEventRepository, triageActionRepository)
+	retroHuntUsecase := usecase.NewRetroHuntUsecase(retroHuntRepository, eventRepository, notify)
+	ruleUsecase := usecase.NewRuleUsecase(ruleRepository, mitreUsecase)
+	ruleSnapshotUsecase := usecase.NewRuleSnapshotUsecase(ruleRepository, ruleSnapshotRepository, notify)
+	ruleFileUsecase := usecase.NewRuleFileUsecase(ruleFileRepository, ruleFileVersionRepository, suppressionRepository)
+	suppressionUsecase := usecase.NewSuppressionUsecase(suppressionRepository, ruleFileRepository, ruleFileUsecase)
+	logtestUsecase := usecase.NewLogtestUsecase(logtestRepository, ruleFileRepository, closedEventRepository)
+	proposalUsecase := usecase.NewProposalUsecase(proposalRepository, suppressionRepository, closedEventRepository, ruleFileRepository, suppressionUsecase, ruleFileUsecase)
+	analyticsUsecase := usecase.NewAnalyticsUsecase(eventRepository, closedEventRepository, ruleRepository, ruleSnapshotRepository, geoIPRepository, mitreUsecase)
+	kpiUsecase := usecase.NewKPIUsecase(eventRepository, closedEventRepository, triageActionRepository)
+	complianceUsecase := usecase.NewComplianceUsecase(eventRepository, closedEventRepository, triageActionRepository, ruleRepository, ruleSnapshotRepository)
+	evaluationUsecase := usecase.NewEvaluationUsecase(autoCloseDecisionRepository, closedEventRepository)
+	qaUsecase := usecase.NewQAUsecase(qaReviewRepository, settingRepository, closedEventRepository, autoCloseDecisionRepository, triageActionRepository, notify)
+	suppressionMinerUsecase := usecase.NewSuppressionMinerUsecase(closedEventRepository, suppressionRepository, proposalUsecase, notify)
+	maintenanceUsecase := usecase.NewMaintenanceUsecase(maintenanceRepository, agentRepository, closedEventRepository, triageActionRepository, guardrailUsecase)
+	snoozeUsecase := usecase.NewSnoozeUsecase(snoozeRepository, closedEventRepository, triageActionRepository, fingerprintUsecase, guardrailUsecase, notify)
+	sequenceUsecase := usecase.NewSequenceUsecase(settingRepository, sequenceFindingRepository, notify)
+	timelineUsecase := usecase.NewTimelineUsecase(eventRepository, triageActionRepository, agentRepository, maintenanceUsecase)
+	caseUsecase := usecase.NewCaseUsecase(eventRepository, caseRepository, closedEventRepository, triageActionRepository, settingRepository, geoIPUsecase, iocUsecase, cveUsecase, mitreUsecase, maintenanceUsecase, snoozeUsecase, sequenceUsecase, notify)
 
 	// Initialize handler
-	eventHandler := handler.NewEventHandler(eventUsecase)
+	eventHandler := handler.NewEventHandler(eventUsecase, fingerprintUsecase, observableUsecase, pivotUsecase)
+	fingerprintHandler := handler.NewFingerprintHandler(fingerprintUsecase)
 	ruleHandler := handler.NewRuleHandler(ruleUsecase)
+	ruleSnapshotHandler := handler.NewRuleSnapshotHandler(ruleSnapshotUsecase)
+	suppressionHandler := handler.NewSuppressionHandler(suppressionUsecase, ruleFileUsecase)
+	logtestHandler := handler.NewLogtestHandler(logtestUsecase)
+	proposalHandler := handler.NewProposalHandler(proposalUsecase)
+	suppressionMinerHandler := handler.NewSuppressionMinerHandler(suppressionMinerUsecase)
+	analyticsHandler := handler.NewAnalyticsHandler(analyticsUsecase)
+	kpiHandler := handler.NewKPIHandler(kpiUsecase)
+	complianceHandler := handler.NewComplianceHandler(complianceUsecase)
+	evaluationHandler := handler.NewEvaluationHandler(evaluationUsecase)
+	qaHandler := handler.NewQAHandler(qaUsecase)
+	guardrailHandler := handler.NewGuardrailHandler(guardrailUsecase)
+	caseHandler := handler.NewCaseHandler(caseUsecase)
+	sequenceHandler := handler.NewSequenceHandler(sequenceUsecase)
+	snoozeHandler := handler.NewSnoozeHandler(snoozeUsecase)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceUsecase)
+	assetHandler := handler.NewAssetHandler(assetUsecase)
+	iocHandler := handler.NewIOCHandler(iocUsecase)
+	cveHandler := handler.NewCVEHandler(cveUsecase)
+	mitreHandler := handler.NewMitreHandler(mitreUsecase)
+	observableHandler := handler.NewObservableHandler(observableUsecase)
+	pivotHandler := handler.NewPivotHandler(pivotUsecase)
+	retroHuntHandler := handler.NewRetroHuntHandler(retroHuntUsecase)
+	timelineHandler := handler.NewTimelineHandler(timelineUsecase)
+
+	// Start background jobs
+	jobCtx := context.Background()
+	scheduler.Every(jobCtx, "rule-snapshot", scheduler.IntervalFromEnv("RULE_SNAPSHOT_INTERVAL"), ruleSnapshotUsecase.RunScheduledSnapshot)
+	scheduler.Every(jobCtx, "suppression-miner", scheduler.IntervalFromEnv("SUPPRESSION_MINER_INTERVAL"), suppressionMinerUsecase.RunScheduledMining)
+	scheduler.Every(jobCtx, "qa-sampler", scheduler.IntervalFromEnv("QA_SAMPLER_INTERVAL"), qaUsecase.RunScheduledSampling)
+	scheduler.Every(jobCtx, "case-correlator", scheduler.IntervalFromEnv("CORRELATION_INTERVAL"), caseUsecase.RunScheduledCorrelation)
+	scheduler.Every(jobCtx, "snooze-summarizer", scheduler.IntervalFromEnv("SNOOZE_SUMMARY_INTERVAL"), snoozeUsecase.RunScheduledSummary)
+	scheduler.Every(jobCtx, "asset-inventory-reloader", scheduler.IntervalFromEnv("ASSET_INVENTORY_RELOAD_INTERVAL"), assetUsecase.RunScheduledReload)
+	scheduler.Every(jobCtx, "ioc-feed-reloader", scheduler.IntervalFromEnv("IOC_FEED_RELOAD_INTERVAL"), iocUsecase.RunScheduledReload)
+	scheduler.Every(jobCtx, "cve-feed-importer", scheduler.IntervalFromEnv("CVE_IMPORT_INTERVAL"), cveUsecase.RunScheduledImport)
+	scheduler.Every(jobCtx, "mitre-attack-reloader", scheduler.IntervalFromEnv("MITRE_ATTACK_RELOAD_INTERVAL"), mitreUsecase.RunScheduledReload)
 
 	app.Use(middleware.RequestIDMiddleware())
 	app.Use(middleware.LoggingMiddleware())
@@ -47,6 +134,9 @@ func SetupRoutes(app *fiber.App, openSearchClient *elastic.Client) {
 		})
 	})
 
+	// Prometheus scrape endpoint for the SOC KPIs
+	app.Get("/metrics", kpiHandler.Metrics)
+
 	// Swagger documentation
 	app.Get("/swagger/*", swagger.New(swagger.Config{
 		URL:         "/docs/openapi.yaml",
@@ -59,11 +149,121 @@ func SetupRoutes(app *fiber.App, openSearchClient *elastic.Client) {
 	v1 := app.Group("/v1")
 
 	v1.Post("/events", eventHandler.FetchEvents)
+	v1.Get("/events/fingerprints/config", fingerprintHandler.FetchConfig)
+	v1.Put("/events/fingerprints/config", fingerprintHandler.UpdateConfig)
+	v1.Post("/events/fingerprints/:fingerprint/close", fingerprintHandler.CloseByFingerprint)
 	v1.Post("/events/:event_id/close", eventHandler.AddToClose)
+	v1.Post("/events/:event_id/acknowledge", eventHandler.AcknowledgeEvent)
+	v1.Get("/events/:event_id/observables", observableHandler.FetchEventObservables)
+	v1.Get("/events/:event_id/related", pivotHandler.FetchRelatedAlerts)
 	v1.Get("/events/close", eventHandler.FetchClosedEvents)
 	v1.Get("/events/close/:id", eventHandler.FetchClosedEventByID)
 	v1.Patch("/events/close/:id/reason", eventHandler.UpdateClosedEventReason)
+	v1.Patch("/events/close/:id/label", eventHandler.UpdateClosedEventLabel)
+
+	v1.Post("/rules/snapshots", ruleSnapshotHandler.CreateSnapshot)
+	v1.Get("/rules/snapshots", ruleSnapshotHandler.FetchSnapshots)
+	v1.Get("/rules/snapshots/diff", ruleSnapshotHandler.DiffSnapshots)
+
+	v1.Post("/rules/logtest", logtestHandler.TestLogs)
+
+	v1.Get("/rules/files/:filename/versions", suppressionHandler.FetchRuleFileVersions)
 
 	v1.Get("/rules/:id", ruleHandler.GetDetailRules)
 	v1.Get("/rules/file/:filename", ruleHandler.GetListRulesByFiles)
+
+	v1.Get("/analytics/rules", analyticsHandler.FetchRuleAnalytics)
+	v1.Get("/analytics/countries", analyticsHandler.FetchCountryAnalytics)
+	v1.Get("/analytics/mitre", analyticsHandler.FetchMitreCoverage)
+	v1.Get("/kpis", kpiHandler.FetchKPIs)
+
+	v1.Get("/compliance/report", complianceHandler.FetchComplianceReport)
+
+	v1.Get("/observables", observableHandler.FetchObservables)
+
+	v1.Get("/evaluation", evaluationHandler.FetchEvaluation)
+	v1.Get("/evaluation/samples", evaluationHandler.FetchDecisionSample)
+	v1.Patch("/evaluation/decisions/:id/label", evaluationHandler.LabelDecision)
+
+	v1.Get("/qa/policy", qaHandler.FetchSamplingPolicy)
+	v1.Put("/qa/policy", qaHandler.UpdateSamplingPolicy)
+	v1.Post("/qa/sample", qaHandler.SampleDay)
+	v1.Get("/qa/reviews", qaHandler.FetchQAReviews)
+	v1.Get("/qa/reviews/:id", qaHandler.FetchQAReviewByID)
+	v1.Post("/qa/reviews/:id/confirm", qaHandler.ConfirmQAReview)
+	v1.Post("/qa/reviews/:id/overturn", qaHandler.OverturnQAReview)
+	v1.Get("/qa/criteria", qaHandler.FetchCriterionReports)
+
+	v1.Get("/guardrails", guardrailHandler.FetchStatus)
+	v1.Put("/guardrails", guardrailHandler.UpdateGuardrails)
+	v1.Put("/guardrails/kill-switch", guardrailHandler.SetKillSwitch)
+	v1.Get("/guardrails/trips", guardrailHandler.FetchTrips)
+
+	v1.Get("/cases/config", caseHandler.FetchCorrelationConfig)
+	v1.Put("/cases/config", caseHandler.UpdateCorrelationConfig)
+	v1.Post("/cases/correlate", caseHandler.Correlate)
+	v1.Get("/cases", caseHandler.FetchCases)
+	v1.Get("/cases/:id", caseHandler.FetchCaseByID)
+	v1.Post("/cases/:id/escalate", caseHandler.EscalateCase)
+	v1.Post("/cases/:id/close", caseHandler.CloseCase)
+
+	v1.Post("/alerts/webhook", caseHandler.IngestWebhookAlerts)
+
+	v1.Get("/sequences", sequenceHandler.FetchRules)
+	v1.Put("/sequences", sequenceHandler.UpdateRules)
+	v1.Get("/sequences/findings", sequenceHandler.FetchFindings)
+	v1.Get("/sequences/findings/:id", sequenceHandler.FetchFindingByID)
+
+	v1.Post("/snoozes", snoozeHandler.CreateSnooze)
+	v1.Get("/snoozes", snoozeHandler.FetchSnoozes)
+	v1.Get("/snoozes/:id", snoozeHandler.FetchSnoozeByID)
+	v1.Post("/snoozes/:id/cancel", snoozeHandler.CancelSnooze)
+
+	v1.Post("/maintenance", maintenanceHandler.CreateWindow)
+	v1.Get("/maintenance", maintenanceHandler.FetchWindows)
+	v1.Get("/maintenance/:id", maintenanceHandler.FetchWindowByID)
+	v1.Put("/maintenance/:id", maintenanceHandler.UpdateWindow)
+
+	v1.Post("/assets", assetHandler.CreateAsset)
+	v1.Get("/assets", assetHandler.FetchAssets)
+	v1.Get("/assets/match", assetHandler.MatchAsset)
+	v1.Post("/assets/reload", assetHandler.ReloadInventory)
+	v1.Get("/assets/:id", assetHandler.FetchAssetByID)
+	v1.Put("/assets/:id", assetHandler.UpdateAsset)
+	v1.Delete("/assets/:id", assetHandler.DeleteAsset)
+
+	v1.Get("/iocs/feeds", iocHandler.FetchStatus)
+	v1.Post("/iocs/reload", iocHandler.ReloadFeeds)
+	v1.Get("/iocs/lookup", iocHandler.LookupValue)
+
+	v1.Post("/retro-hunts", retroHuntHandler.CreateRetroHunt)
+	v1.Get("/retro-hunts", retroHuntHandler.FetchRetroHunts)
+	v1.Get("/retro-hunts/:id", retroHuntHandler.FetchRetroHuntByID)
+	v1.Get("/retro-hunts/:id/matches", retroHuntHandler.FetchRetroHuntMatches)
+
+	v1.Post("/cves/import", cveHandler.ImportFeeds)
+	v1.Get("/cves/imports", cveHandler.FetchFeedImports)
+	v1.Get("/cves/:id", cveHandler.FetchCVEByID)
+	v1.Get("/vulnerabilities/agents", cveHandler.FetchAgentVulnerabilities)
+	v1.Get("/vulnerabilities/agents/:agent_id", cveHandler.FetchAgentVulnerabilitiesByID)
+
+	v1.Get("/agents/:id/timeline", timelineHandler.FetchAgentTimeline)
+
+	v1.Get("/mitre/status", mitreHandler.FetchStatus)
+	v1.Post("/mitre/reload", mitreHandler.ReloadMatrix)
+	v1.Get("/mitre/techniques/:id", mitreHandler.FetchTechniqueByID)
+
+	v1.Get("/suppressions", suppressionHandler.FetchSuppressions)
+	v1.Get("/suppressions/:id", suppressionHandler.FetchSuppressionByID)
+	v1.Get("/suppressions/:id/xml", suppressionHandler.PreviewSuppressionXML)
+
+	v1.Post("/proposals", proposalHandler.CreateProposal)
+	v1.Get("/proposals", proposalHandler.FetchProposals)
+	v1.Post("/proposals/mine", suppressionMinerHandler.MineSuppressions)
+	v1.Get("/proposals/:id", proposalHandler.FetchProposalByID)
+	v1.Post("/proposals/:id/submit", proposalHandler.SubmitProposal)
+	v1.Post("/proposals/:id/approve", proposalHandler.ApproveProposal)
+	v1.Post("/proposals/:id/reject", proposalHandler.RejectProposal)
+	v1.Post("/proposals/:id/deploy", proposalHandler.DeployProposal)
+	v1.Post("/proposals/:id/rollback", proposalHandler.RollbackProposal)
 }
@@ -0,0 +1,462 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAnalyticsWindow is used when the request does not set a window
+	defaultAnalyticsWindow = 7 * 24 * time.Hour
+
+	// defaultAnalyticsLimit is the number of ranked rules returned when the request does not set a limit
+	defaultAnalyticsLimit = 50
+
+	// A rule is routinely auto-closed when at least routineAutoCloseMinimum of its alerts in the window, and
+	// routineAutoCloseRatio of them, were auto-closed
+	routineAutoCloseMinimum = 5
+	routineAutoCloseRatio   = 0.8
+
+	// sourceIPBatchSize addresses are counted per indexer query, at most maxSourceIPBatches times per report
+	sourceIPBatchSize  = 1000
+	maxSourceIPBatches = 100
+)
+
+type analyticsUsecase struct {
+	wazuhEventRepo  domain.WazuhEventRepository
+	closedEventRepo domain.ClosedEventRepository
+	ruleRepo        domain.RuleRepository
+	snapshotRepo    domain.RuleSnapshotRepository
+	geoIPRepo       domain.GeoIPRepository
+	mitreUsecase    domain.MitreUsecase
+}
+
+func NewAnalyticsUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	ruleRepo domain.RuleRepository,
+	snapshotRepo domain.RuleSnapshotRepository,
+	geoIPRepo domain.GeoIPRepository,
+	mitreUsecase domain.MitreUsecase,
+) domain.AnalyticsUsecase {
+	return &analyticsUsecase{
+		wazuhEventRepo:  wazuhEventRepo,
+		closedEventRepo: closedEventRepo,
+		ruleRepo:        ruleRepo,
+		snapshotRepo:    snapshotRepo,
+		geoIPRepo:       geoIPRepo,
+		mitreUsecase:    mitreUsecase,
+	}
+}
+
+// FetchRuleAnalytics joins indexer firing counts with closures per rule and ranks the rules by noise score.
+// The score is firings × (1 − true positive rate) + manual closures: rules that fire often without confirmed
+// threats, and rules that cost analysts the most hand work, rank first.
+func (u *analyticsUsecase) FetchRuleAnalytics(ctx context.Context, request *model.RuleAnalyticsRequest) (*entity.RuleAnalyticsReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	window := request.Window
+	if window <= 0 {
+		window = defaultAnalyticsWindow
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultAnalyticsLimit
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	firings, err := u.wazuhEventRepo.CountEventsByField(ctx, "rule.id", since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - analytics - FetchRuleAnalytics]: Failed to count firings per rule")
+		return nil, err
+	}
+
+	closedEvents, err := u.closedEventRepo.FetchClosedEventsSince(ctx, since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - analytics - FetchRuleAnalytics]: Failed to fetch closed events")
+		return nil, err
+	}
+
+	stats := map[string]*entity.RuleAnalytics{}
+	statsFor := func(ruleID string) *entity.RuleAnalytics {
+		if _, ok := stats[ruleID]; !ok {
+			stats[ruleID] = &entity.RuleAnalytics{RuleID: ruleID, Groups: []string{}}
+		}
+		return stats[ruleID]
+	}
+
+	for ruleID, count := range firings {
+		statsFor(ruleID).Firings = count
+	}
+
+	timesToClose := map[string][]float64{}
+	for _, closedEvent := range closedEvents {
+		if closedEvent.RuleID == "" {
+			continue
+		}
+
+		rule := statsFor(closedEvent.RuleID)
+		rule.Closures++
+
+		if closedEvent.CloseType == entity.CloseTypeAuto {
+			rule.AutoClosed++
+		} else {
+			rule.ManualClosed++
+		}
+
+		switch closedEvent.Label {
+		case entity.LabelFalsePositive:
+			rule.FalsePositives++
+		case entity.LabelTruePositive:
+			rule.TruePositives++
+		default:
+			rule.Unlabeled++
+		}
+
+		if firedAt, ok := alertTimestamp(closedEvent.RawEvent); ok && !closedEvent.CloseAt.Before(firedAt) {
+			timesToClose[closedEvent.RuleID] = append(timesToClose[closedEvent.RuleID], closedEvent.CloseAt.Sub(firedAt).Seconds())
+		}
+	}
+
+	catalog := fetchRuleCatalog(ctx, u.snapshotRepo, u.ruleRepo)
+
+	rules := make([]entity.RuleAnalytics, 0, len(stats))
+	for ruleID, rule := range stats {
+		if id, err := strconv.Atoi(ruleID); err == nil {
+			if metadata, ok := catalog[id]; ok {
+				rule.Level = metadata.Level
+				rule.Description = metadata.Description
+				rule.Filename = metadata.Filename
+				if metadata.Groups != nil {
+					rule.Groups = metadata.Groups
+				}
+			}
+		}
+
+		if durations := timesToClose[ruleID]; len(durations) > 0 {
+			medianSeconds := median(durations)
+			rule.MedianTimeToCloseSeconds = &medianSeconds
+		}
+
+		rule.NoiseScore = noiseScore(rule)
+		rules = append(rules, *rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].NoiseScore != rules[j].NoiseScore {
+			return rules[i].NoiseScore > rules[j].NoiseScore
+		}
+		return rules[i].RuleID < rules[j].RuleID
+	})
+
+	if len(rules) > limit {
+		rules = rules[:limit]
+	}
+
+	return &entity.RuleAnalyticsReport{
+		Window:      window.String(),
+		Since:       since,
+		GeneratedAt: now,
+		Rules:       rules,
+	}, nil
+}
+
+// FetchCountryAnalytics counts the alerts of the window by the country of data.srcip, busiest country first.
+// Addresses are counted page by page from the indexer's composite aggregation, so every address is located up to
+// maxSourceIPBatches pages, past which the report is flagged truncated.
+func (u *analyticsUsecase) FetchCountryAnalytics(ctx context.Context, request *model.CountryAnalyticsRequest) (*entity.CountryAnalyticsReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	window := request.Window
+	if window <= 0 {
+		window = defaultAnalyticsWindow
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultAnalyticsLimit
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	bySourceIP := map[string]int64{}
+	var after map[string]interface{}
+
+	for batch := 0; batch < maxSourceIPBatches; batch++ {
+		counts, next, err := u.wazuhEventRepo.CountEventsByFieldAfter(ctx, "data.srcip", since, after, sourceIPBatchSize)
+		if err != nil {
+			log.WithError(err).Error("[usecase - analytics - FetchCountryAnalytics]: Failed to count events per source address")
+			return nil, err
+		}
+		for ip, events := range counts {
+			bySourceIP[ip] = events
+		}
+
+		after = next
+		if after == nil {
+			break
+		}
+	}
+
+	report := &entity.CountryAnalyticsReport{
+		Window:      window.String(),
+		Since:       since,
+		GeneratedAt: now,
+		SourceIPs:   len(bySourceIP),
+		Truncated:   after != nil,
+	}
+
+	countries := map[string]*entity.CountryAnalytics{}
+	for ip, events := range bySourceIP {
+		location, err := u.geoIPRepo.LookupIP(ctx, ip)
+		if err != nil || location == nil || location.CountryISOCode == "" {
+			report.Unlocated += events
+			continue
+		}
+
+		country, ok := countries[location.CountryISOCode]
+		if !ok {
+			country = &entity.CountryAnalytics{CountryISOCode: location.CountryISOCode, CountryName: location.CountryName}
+			countries[location.CountryISOCode] = country
+		}
+		country.Events += events
+		country.SourceIPs++
+	}
+
+	report.Countries = make([]entity.CountryAnalytics, 0, len(countries))
+	for _, country := range countries {
+		report.Countries = append(report.Countries, *country)
+	}
+
+	sort.Slice(report.Countries, func(i, j int) bool {
+		if report.Countries[i].Events != report.Countries[j].Events {
+			return report.Countries[i].Events > report.Countries[j].Events
+		}
+		return report.Countries[i].CountryISOCode < report.Countries[j].CountryISOCode
+	})
+
+	if len(report.Countries) > limit {
+		report.Countries = report.Countries[:limit]
+	}
+
+	return report, nil
+}
+
+// FetchMitreCoverage crosses the rule catalog with the ATT&CK matrix. For each tactic it counts the
+// techniques that have rules, and of those rules how many fired in the window and how many are routinely
+// auto-closed, which covers a technique on paper only.
+func (u *analyticsUsecase) FetchMitreCoverage(ctx context.Context, request *model.MitreCoverageRequest) (*entity.MitreCoverageReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	window := request.Window
+	if window <= 0 {
+		window = defaultAnalyticsWindow
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	matrix, err := u.mitreUsecase.FetchMatrix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status, err := u.mitreUsecase.FetchStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	firings, err := u.wazuhEventRepo.CountEventsByField(ctx, "rule.id", since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - analytics - FetchMitreCoverage]: Failed to count firings per rule")
+		return nil, err
+	}
+
+	closedEvents, err := u.closedEventRepo.FetchClosedEventsSince(ctx, since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - analytics - FetchMitreCoverage]: Failed to fetch closed events")
+		return nil, err
+	}
+
+	autoClosed := map[string]int{}
+	for _, closedEvent := range closedEvents {
+		if closedEvent.RuleID != "" && closedEvent.CloseType == entity.CloseTypeAuto {
+			autoClosed[closedEvent.RuleID]++
+		}
+	}
+
+	catalog := fetchRuleCatalog(ctx, u.snapshotRepo, u.ruleRepo)
+
+	techniques := make(map[string]*entity.MitreTechnique, len(matrix.Techniques))
+	for _, technique := range matrix.Techniques {
+		techniques[technique.ID] = technique
+	}
+
+	report := &entity.MitreCoverageReport{
+		Window:            window.String(),
+		Since:             since,
+		GeneratedAt:       now,
+		MatrixVersion:     status.Version,
+		Rules:             len(catalog),
+		UnknownTechniques: []string{},
+		Tactics:           []entity.MitreTacticCoverage{},
+	}
+
+	ruleIDs := make([]int, 0, len(catalog))
+	for ruleID := range catalog {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Ints(ruleIDs)
+
+	coverage := map[string]*entity.MitreTechniqueCoverage{}
+	tacticRules := map[string]map[int]bool{}
+	unknown := map[string]bool{}
+
+	for _, ruleID := range ruleIDs {
+		key := strconv.Itoa(ruleID)
+		fired := firings[key]
+		routine := routinelyAutoClosed(fired, autoClosed[key])
+
+		mapped := false
+		for _, id := range catalog[ruleID].Mitre {
+			id = strings.ToUpper(strings.TrimSpace(id))
+			technique, ok := techniques[id]
+			if !ok {
+				unknown[id] = true
+				continue
+			}
+			mapped = true
+
+			techniqueCoverage, ok := coverage[id]
+			if !ok {
+				techniqueCoverage = &entity.MitreTechniqueCoverage{
+					ID:                  id,
+					Name:                technique.Name,
+					SubTechnique:        technique.SubTechnique,
+					Rules:               []int{},
+					RoutinelyAutoClosed: []int{},
+				}
+				coverage[id] = techniqueCoverage
+			}
+			techniqueCoverage.Rules = append(techniqueCoverage.Rules, ruleID)
+			if fired > 0 {
+				techniqueCoverage.FiredRules++
+				techniqueCoverage.Alerts += fired
+			}
+			techniqueCoverage.AutoClosed += autoClosed[key]
+			if routine {
+				techniqueCoverage.RoutinelyAutoClosed = append(techniqueCoverage.RoutinelyAutoClosed, ruleID)
+			}
+
+			for _, tactic := range technique.Tactics {
+				if tacticRules[tactic] == nil {
+					tacticRules[tactic] = map[int]bool{}
+				}
+				tacticRules[tactic][ruleID] = true
+			}
+		}
+		if mapped {
+			report.MappedRules++
+		}
+	}
+
+	for id := range unknown {
+		report.UnknownTechniques = append(report.UnknownTechniques, id)
+	}
+	sort.Strings(report.UnknownTechniques)
+
+	for _, tactic := range matrix.Tactics {
+		tacticCoverage := entity.MitreTacticCoverage{MitreTactic: tactic, Coverage: []entity.MitreTechniqueCoverage{}}
+
+		// Techniques count once, whether their rules name them or one of their sub-techniques
+		counted := map[string]bool{}
+		for _, technique := range matrix.Techniques {
+			if !technique.SubTechnique && !technique.Deprecated && containsString(technique.Tactics, tactic.Name) {
+				counted[technique.ID] = true
+			}
+		}
+		tacticCoverage.Techniques = len(counted)
+
+		covered := map[string]bool{}
+		for id, techniqueCoverage := range coverage {
+			technique := techniques[id]
+			if !containsString(technique.Tactics, tactic.Name) {
+				continue
+			}
+			tacticCoverage.Coverage = append(tacticCoverage.Coverage, *techniqueCoverage)
+
+			parentID := technique.ID
+			if technique.SubTechnique {
+				parentID = technique.ParentID
+			}
+			if counted[parentID] {
+				covered[parentID] = true
+			}
+		}
+		tacticCoverage.CoveredTechniques = len(covered)
+
+		sort.Slice(tacticCoverage.Coverage, func(i, j int) bool {
+			return tacticCoverage.Coverage[i].ID < tacticCoverage.Coverage[j].ID
+		})
+
+		for ruleID := range tacticRules[tactic.Name] {
+			key := strconv.Itoa(ruleID)
+			tacticCoverage.Rules++
+			if firings[key] > 0 {
+				tacticCoverage.FiredRules++
+				tacticCoverage.Alerts += firings[key]
+			}
+			tacticCoverage.AutoClosed += autoClosed[key]
+			if routinelyAutoClosed(firings[key], autoClosed[key]) {
+				tacticCoverage.RoutinelyAutoClosedRules++
+			}
+		}
+
+		report.Tactics = append(report.Tactics, tacticCoverage)
+	}
+
+	return report, nil
+}
+
+// routinelyAutoClosed reports whether most of a rule's alerts in the window were auto-closed. Closures
+// can outnumber the indexer's count of firings when alerts fired before the window, so the larger counts.
+func routinelyAutoClosed(firings int64, autoClosed int) bool {
+	if autoClosed < routineAutoCloseMinimum {
+		return false
+	}
+	alerts := float64(firings)
+	if float64(autoClosed) > alerts {
+		alerts = float64(autoClosed)
+	}
+	return float64(autoClosed) >= routineAutoCloseRatio*alerts
+}
+
+func noiseScore(rule *entity.RuleAnalytics) float64 {
+	truePositiveRate := 0.0
+	if rule.Closures > 0 {
+		truePositiveRate = float64(rule.TruePositives) / float64(rule.Closures)
+	}
+
+	score := float64(rule.Firings)*(1-truePositiveRate) + float64(rule.ManualClosed)
+	return math.Round(score*100) / 100
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
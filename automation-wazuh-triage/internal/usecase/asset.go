@@ -0,0 +1,684 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// assetTagSeparator splits the tags column of a CSV inventory
+	assetTagSeparator = ";"
+
+	// defaultAssetCriticality applies to assets that do not give one
+	defaultAssetCriticality = entity.AssetCriticalityMedium
+)
+
+var assetCriticalities = []string{
+	entity.AssetCriticalityLow,
+	entity.AssetCriticalityMedium,
+	entity.AssetCriticalityHigh,
+	entity.AssetCriticalityCritical,
+}
+
+// assetInventoryColumns are the columns a CSV inventory may have, in any order after its header row
+var assetInventoryColumns = []string{"name", "agent_id", "hostname", "ip", "owner", "criticality", "environment", "tags"}
+
+type assetUsecase struct {
+	assetRepo domain.AssetRepository
+
+	// index is built from the inventory on first use and dropped on every change
+	mu          sync.Mutex
+	index       *assetIndex
+	fileModTime time.Time
+	fileLoaded  bool
+}
+
+// assetIndex looks up assets by agent ID, lower-cased hostname and network, the most specific network first
+type assetIndex struct {
+	byAgentID  map[string]*entity.Asset
+	byHostname map[string]*entity.Asset
+	networks   []assetNetwork
+}
+
+type assetNetwork struct {
+	prefix netip.Prefix
+	asset  *entity.Asset
+}
+
+// assetInventoryFile is the YAML form of the inventory
+type assetInventoryFile struct {
+	Assets []struct {
+		Name        string   `yaml:"name"`
+		AgentID     string   `yaml:"agent_id"`
+		Hostname    string   `yaml:"hostname"`
+		IP          string   `yaml:"ip"`
+		Owner       string   `yaml:"owner"`
+		Criticality string   `yaml:"criticality"`
+		Environment string   `yaml:"environment"`
+		Tags        []string `yaml:"tags"`
+	} `yaml:"assets"`
+}
+
+func NewAssetUsecase(assetRepo domain.AssetRepository) domain.AssetUsecase {
+	return &assetUsecase{
+		assetRepo: assetRepo,
+	}
+}
+
+func (u *assetUsecase) CreateAsset(ctx context.Context, request *model.AssetRequest) (*entity.Asset, error) {
+	log := logger.WithRequestID(ctx)
+
+	analyst := strings.TrimSpace(request.Analyst)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid asset: analyst is required")
+	}
+
+	asset, err := newAsset(request)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset: %w", err)
+	}
+	asset.Source = entity.AssetSourceAPI
+	asset.UpdatedBy = analyst
+	asset.UpdatedAt = time.Now().UTC()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.assetRepo.SaveAsset(ctx, asset); err != nil {
+		log.WithError(err).Error("[usecase - asset - CreateAsset]: Failed to save asset")
+		return nil, err
+	}
+	u.index = nil
+
+	log.WithField("asset_id", asset.ID).WithField("name", asset.Name).WithField("analyst", analyst).Info("[usecase - asset - CreateAsset]: Asset created")
+	return asset, nil
+}
+
+// UpdateAsset replaces an asset managed through the API. Assets of the inventory file are changed in the file.
+func (u *assetUsecase) UpdateAsset(ctx context.Context, id int, request *model.AssetRequest) (*entity.Asset, error) {
+	log := logger.WithRequestID(ctx)
+
+	analyst := strings.TrimSpace(request.Analyst)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid asset: analyst is required")
+	}
+
+	asset, err := newAsset(request)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset: %w", err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.checkManagedByAPI(ctx, id); err != nil {
+		return nil, err
+	}
+
+	asset.ID = id
+	asset.Source = entity.AssetSourceAPI
+	asset.UpdatedBy = analyst
+	asset.UpdatedAt = time.Now().UTC()
+
+	if err := u.assetRepo.UpdateAsset(ctx, asset); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("asset with ID %d not found", id)
+		}
+		log.WithError(err).WithField("asset_id", id).Error("[usecase - asset - UpdateAsset]: Failed to update asset")
+		return nil, err
+	}
+	u.index = nil
+
+	log.WithField("asset_id", id).WithField("analyst", analyst).Info("[usecase - asset - UpdateAsset]: Asset updated")
+	return asset, nil
+}
+
+// DeleteAsset removes an asset managed through the API
+func (u *assetUsecase) DeleteAsset(ctx context.Context, id int) error {
+	log := logger.WithRequestID(ctx)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.checkManagedByAPI(ctx, id); err != nil {
+		return err
+	}
+
+	if err := u.assetRepo.DeleteAsset(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("asset with ID %d not found", id)
+		}
+		log.WithError(err).WithField("asset_id", id).Error("[usecase - asset - DeleteAsset]: Failed to delete asset")
+		return err
+	}
+	u.index = nil
+
+	log.WithField("asset_id", id).Info("[usecase - asset - DeleteAsset]: Asset deleted")
+	return nil
+}
+
+// FetchAssets returns the inventory, optionally only the assets with the given criticality, environment,
+// tag or source
+func (u *assetUsecase) FetchAssets(ctx context.Context, request *model.FetchAssetsRequest) ([]*entity.Asset, error) {
+	if request.Criticality != "" && !containsString(assetCriticalities, request.Criticality) {
+		return nil, fmt.Errorf("invalid criticality %q: must be one of %s", request.Criticality, strings.Join(assetCriticalities, ", "))
+	}
+	if request.Source != "" && request.Source != entity.AssetSourceFile && request.Source != entity.AssetSourceAPI {
+		return nil, fmt.Errorf("invalid source %q: must be %s or %s", request.Source, entity.AssetSourceFile, entity.AssetSourceAPI)
+	}
+
+	u.mu.Lock()
+	u.loadInventoryFileOnce(ctx)
+	u.mu.Unlock()
+
+	assets, err := u.assetRepo.FetchAssets(ctx)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - asset - FetchAssets]: Failed to fetch assets")
+		return nil, err
+	}
+
+	result := []*entity.Asset{}
+	for _, asset := range assets {
+		if request.Criticality != "" && asset.Criticality != request.Criticality {
+			continue
+		}
+		if request.Environment != "" && asset.Environment != request.Environment {
+			continue
+		}
+		if request.Tag != "" && !containsString(asset.Tags, request.Tag) {
+			continue
+		}
+		if request.Source != "" && asset.Source != request.Source {
+			continue
+		}
+		result = append(result, asset)
+	}
+
+	return result, nil
+}
+
+func (u *assetUsecase) FetchAssetByID(ctx context.Context, id int) (*entity.Asset, error) {
+	asset, err := u.assetRepo.FetchAssetByID(ctx, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("asset_id", id).Error("[usecase - asset - FetchAssetByID]: Failed to fetch asset")
+		return nil, err
+	}
+	if asset == nil {
+		return nil, fmt.Errorf("asset with ID %d not found", id)
+	}
+	return asset, nil
+}
+
+// MatchHost returns the asset of a host, matched by agent ID first, then hostname, then the most specific
+// network containing the address. It returns nil when no asset matches.
+func (u *assetUsecase) MatchHost(ctx context.Context, agentID string, hostname string, ip string) (*entity.AssetMatch, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	index, err := u.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return index.match(agentID, hostname, ip), nil
+}
+
+// MatchAlert returns the asset of the agent that raised the alert, or nil when none matches. Lookup failures
+// are logged so enrichment never fails a listing.
+func (u *assetUsecase) MatchAlert(ctx context.Context, source []byte) *entity.AssetMatch {
+	alert, ok := decodeAlertSource(source)
+	if !ok {
+		return nil
+	}
+
+	match, err := u.MatchHost(ctx, alert.Agent.ID, alert.Agent.Name, alert.Agent.IP)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Warn("[usecase - asset - MatchAlert]: Failed to match alert to an asset")
+		return nil
+	}
+	return match
+}
+
+// EnrichHits returns the hits with an asset block added to the source of each one whose agent is in the
+// inventory. The given hits are not modified.
+func (u *assetUsecase) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	enriched := make([]*elastic.SearchHit, 0, len(hits))
+
+	for _, hit := range hits {
+		match := u.MatchAlert(ctx, hit.Source)
+		if match == nil {
+			enriched = append(enriched, hit)
+			continue
+		}
+		enriched = append(enriched, tagAssetHit(hit, match))
+	}
+
+	return enriched
+}
+
+// ReloadInventory reads ASSET_INVENTORY_FILE and replaces the file assets with its content. An invalid file
+// leaves the inventory unchanged.
+func (u *assetUsecase) ReloadInventory(ctx context.Context) (*entity.AssetInventoryReload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.reloadInventory(ctx, true)
+}
+
+// RunScheduledReload reloads the inventory file when it changed since the last reload
+func (u *assetUsecase) RunScheduledReload(ctx context.Context) error {
+	if os.Getenv("ASSET_INVENTORY_FILE") == "" {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, err := u.reloadInventory(ctx, false)
+	return err
+}
+
+// reloadInventory reads the inventory file unless force is false and the file is unchanged. Callers hold mu.
+func (u *assetUsecase) reloadInventory(ctx context.Context, force bool) (*entity.AssetInventoryReload, error) {
+	log := logger.WithRequestID(ctx)
+
+	path := os.Getenv("ASSET_INVENTORY_FILE")
+	if path == "" {
+		return nil, fmt.Errorf("invalid reload: ASSET_INVENTORY_FILE is not set")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Error("[usecase - asset - reloadInventory]: Failed to read asset inventory file")
+		return nil, err
+	}
+
+	reload := &entity.AssetInventoryReload{
+		Path:       path,
+		ReloadedAt: time.Now().UTC(),
+	}
+	if !force && u.fileLoaded && info.ModTime().Equal(u.fileModTime) {
+		return reload, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Error("[usecase - asset - reloadInventory]: Failed to read asset inventory file")
+		return nil, err
+	}
+
+	assets, err := parseAssetInventory(path, content)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Error("[usecase - asset - reloadInventory]: Asset inventory file is invalid, inventory unchanged")
+		return nil, fmt.Errorf("invalid asset inventory %s: %w", path, err)
+	}
+
+	for _, asset := range assets {
+		asset.Source = entity.AssetSourceFile
+		asset.UpdatedBy = filepath.Base(path)
+		asset.UpdatedAt = reload.ReloadedAt
+	}
+
+	if err := u.assetRepo.ReplaceFileAssets(ctx, assets); err != nil {
+		log.WithError(err).Error("[usecase - asset - reloadInventory]: Failed to replace file assets")
+		return nil, err
+	}
+
+	u.fileModTime = info.ModTime()
+	u.fileLoaded = true
+	u.index = nil
+
+	reload.Loaded = len(assets)
+	reload.Changed = true
+
+	log.WithField("path", path).WithField("assets", len(assets)).Info("[usecase - asset - reloadInventory]: Asset inventory reloaded")
+	return reload, nil
+}
+
+// loadInventoryFileOnce reads the inventory file the first time this process needs the inventory, so edits
+// made while the service was down apply before the first scheduled reload. Callers hold mu.
+func (u *assetUsecase) loadInventoryFileOnce(ctx context.Context) {
+	if u.fileLoaded || os.Getenv("ASSET_INVENTORY_FILE") == "" {
+		return
+	}
+
+	if _, err := u.reloadInventory(ctx, false); err != nil {
+		logger.WithRequestID(ctx).WithError(err).Warn("[usecase - asset - loadInventoryFileOnce]: Failed to load asset inventory file, using the stored inventory")
+		// Stored assets keep serving until the file is fixed; the scheduled reload retries
+		u.fileLoaded = true
+	}
+}
+
+// loadIndex returns the lookup index, building it from the stored inventory when needed. Callers hold mu.
+func (u *assetUsecase) loadIndex(ctx context.Context) (*assetIndex, error) {
+	if u.index != nil {
+		return u.index, nil
+	}
+
+	u.loadInventoryFileOnce(ctx)
+
+	assets, err := u.assetRepo.FetchAssets(ctx)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - asset - loadIndex]: Failed to fetch assets")
+		return nil, err
+	}
+
+	u.index = newAssetIndex(assets)
+	return u.index, nil
+}
+
+// checkManagedByAPI fails unless the asset exists and was created through the API. Callers hold mu.
+func (u *assetUsecase) checkManagedByAPI(ctx context.Context, id int) error {
+	existing, err := u.assetRepo.FetchAssetByID(ctx, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("asset_id", id).Error("[usecase - asset - checkManagedByAPI]: Failed to fetch asset")
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("asset with ID %d not found", id)
+	}
+	if existing.Source == entity.AssetSourceFile {
+		return fmt.Errorf("invalid asset: asset %d is loaded from the inventory file, change it there", id)
+	}
+	return nil
+}
+
+// newAssetIndex indexes the assets. When several assets share a key, one managed through the API wins over
+// one from the file, and the older one wins otherwise.
+func newAssetIndex(assets []*entity.Asset) *assetIndex {
+	ordered := append([]*entity.Asset(nil), assets...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Source == entity.AssetSourceAPI && ordered[j].Source != entity.AssetSourceAPI
+	})
+
+	index := &assetIndex{
+		byAgentID:  map[string]*entity.Asset{},
+		byHostname: map[string]*entity.Asset{},
+	}
+	seenNetworks := map[netip.Prefix]bool{}
+
+	for _, asset := range ordered {
+		if asset.AgentID != "" {
+			if _, ok := index.byAgentID[asset.AgentID]; !ok {
+				index.byAgentID[asset.AgentID] = asset
+			}
+		}
+		if asset.Hostname != "" {
+			hostname := strings.ToLower(asset.Hostname)
+			if _, ok := index.byHostname[hostname]; !ok {
+				index.byHostname[hostname] = asset
+			}
+		}
+		if asset.IP != "" {
+			prefix, err := parseAssetNetwork(asset.IP)
+			if err != nil || seenNetworks[prefix] {
+				continue
+			}
+			seenNetworks[prefix] = true
+			index.networks = append(index.networks, assetNetwork{prefix: prefix, asset: asset})
+		}
+	}
+
+	sort.SliceStable(index.networks, func(i, j int) bool {
+		return index.networks[i].prefix.Bits() > index.networks[j].prefix.Bits()
+	})
+
+	return index
+}
+
+func (index *assetIndex) match(agentID string, hostname string, ip string) *entity.AssetMatch {
+	if asset, ok := index.byAgentID[agentID]; ok && agentID != "" {
+		return &entity.AssetMatch{Asset: asset, MatchedBy: entity.AssetMatchAgentID}
+	}
+	if asset, ok := index.byHostname[strings.ToLower(hostname)]; ok && hostname != "" {
+		return &entity.AssetMatch{Asset: asset, MatchedBy: entity.AssetMatchHostname}
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return nil
+	}
+	addr = addr.Unmap()
+
+	for _, network := range index.networks {
+		if network.prefix.Contains(addr) {
+			return &entity.AssetMatch{Asset: network.asset, MatchedBy: entity.AssetMatchIP}
+		}
+	}
+	return nil
+}
+
+// newAsset validates an asset and fills in its defaults. Errors describe the problem without a prefix so
+// the API and the inventory file can each say where it was found.
+func newAsset(request *model.AssetRequest) (*entity.Asset, error) {
+	asset := &entity.Asset{
+		Name:        strings.TrimSpace(request.Name),
+		AgentID:     strings.TrimSpace(request.AgentID),
+		Hostname:    strings.TrimSpace(request.Hostname),
+		IP:          strings.TrimSpace(request.IP),
+		Owner:       strings.TrimSpace(request.Owner),
+		Criticality: strings.ToLower(strings.TrimSpace(request.Criticality)),
+		Environment: strings.TrimSpace(request.Environment),
+		Tags:        cleanList(request.Tags),
+	}
+
+	if asset.AgentID == "" && asset.Hostname == "" && asset.IP == "" {
+		return nil, fmt.Errorf("one of agent_id, hostname and ip is required")
+	}
+
+	if asset.IP != "" {
+		prefix, err := parseAssetNetwork(asset.IP)
+		if err != nil {
+			return nil, fmt.Errorf("ip %q is not an address or CIDR", asset.IP)
+		}
+		if prefix.IsSingleIP() {
+			asset.IP = prefix.Addr().String()
+		} else {
+			asset.IP = prefix.String()
+		}
+	}
+
+	if asset.Criticality == "" {
+		asset.Criticality = defaultAssetCriticality
+	}
+	if !containsString(assetCriticalities, asset.Criticality) {
+		return nil, fmt.Errorf("criticality %q must be one of %s", asset.Criticality, strings.Join(assetCriticalities, ", "))
+	}
+
+	if asset.Name == "" {
+		for _, candidate := range []string{asset.Hostname, asset.AgentID, asset.IP} {
+			if candidate != "" {
+				asset.Name = candidate
+				break
+			}
+		}
+	}
+
+	return asset, nil
+}
+
+// parseAssetNetwork reads an address or CIDR as a network; an address is a network of one
+func parseAssetNetwork(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseAssetInventory reads a CSV or YAML inventory, chosen by the file extension. Any invalid entry fails
+// the whole file.
+func parseAssetInventory(path string, content []byte) ([]*entity.Asset, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return parseAssetInventoryCSV(content)
+	case ".yaml", ".yml":
+		return parseAssetInventoryYAML(content)
+	default:
+		return nil, fmt.Errorf("unsupported extension %q, use .csv, .yaml or .yml", filepath.Ext(path))
+	}
+}
+
+// parseAssetInventoryCSV reads a CSV inventory with a header row naming its columns. Tags are separated by
+// semicolons and lines starting with # are skipped.
+func parseAssetInventoryCSV(content []byte) ([]*entity.Asset, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []*entity.Asset{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !containsString(assetInventoryColumns, column) {
+			return nil, fmt.Errorf("unknown column %q, columns are %s", column, strings.Join(assetInventoryColumns, ", "))
+		}
+		columns[column] = i
+	}
+
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok {
+			return record[i]
+		}
+		return ""
+	}
+
+	assets := []*entity.Asset{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		asset, err := newAsset(&model.AssetRequest{
+			Name:        value(record, "name"),
+			AgentID:     value(record, "agent_id"),
+			Hostname:    value(record, "hostname"),
+			IP:          value(record, "ip"),
+			Owner:       value(record, "owner"),
+			Criticality: value(record, "criticality"),
+			Environment: value(record, "environment"),
+			Tags:        strings.Split(value(record, "tags"), assetTagSeparator),
+		})
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, nil
+}
+
+// parseAssetInventoryYAML reads a YAML inventory holding a list of assets under the assets key
+func parseAssetInventoryYAML(content []byte) ([]*entity.Asset, error) {
+	var inventory assetInventoryFile
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&inventory); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	assets := make([]*entity.Asset, 0, len(inventory.Assets))
+	for i, entry := range inventory.Assets {
+		asset, err := newAsset(&model.AssetRequest{
+			Name:        entry.Name,
+			AgentID:     entry.AgentID,
+			Hostname:    entry.Hostname,
+			IP:          entry.IP,
+			Owner:       entry.Owner,
+			Criticality: entry.Criticality,
+			Environment: entry.Environment,
+			Tags:        entry.Tags,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("asset %d: %w", i+1, err)
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, nil
+}
+
+// assetMatchesCondition reports whether the asset satisfies every list the condition gives
+func assetMatchesCondition(match *entity.AssetMatch, condition *model.AssetCondition) bool {
+	if condition == nil {
+		return true
+	}
+	if match == nil {
+		return false
+	}
+
+	if len(condition.Criticalities) > 0 && !containsString(condition.Criticalities, match.Criticality) {
+		return false
+	}
+	if len(condition.Environments) > 0 && !containsString(condition.Environments, match.Environment) {
+		return false
+	}
+	if len(condition.Tags) > 0 {
+		for _, tag := range condition.Tags {
+			if containsString(match.Tags, tag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// validateAssetCondition rejects criticalities that do not exist, so a typo cannot silently match nothing
+func validateAssetCondition(condition *model.AssetCondition) error {
+	if condition == nil {
+		return nil
+	}
+	for _, criticality := range condition.Criticalities {
+		if !containsString(assetCriticalities, criticality) {
+			return fmt.Errorf("invalid asset condition: criticality %q must be one of %s", criticality, strings.Join(assetCriticalities, ", "))
+		}
+	}
+	return nil
+}
+
+// tagAssetHit returns a copy of the hit with the asset added to its source
+func tagAssetHit(hit *elastic.SearchHit, match *entity.AssetMatch) *elastic.SearchHit {
+	return withSourceField(hit, "asset", match)
+}
@@ -0,0 +1,892 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// defaultCorrelationWindow and defaultCorrelationKeys apply until the correlation config is saved through the API
+	defaultCorrelationWindow = 30 * time.Minute
+	defaultCorrelationKeys   = entity.CorrelationKeySrcIP
+
+	// maxCorrelationWindow bounds how far apart two alerts of one case may fire
+	maxCorrelationWindow = 7 * 24 * time.Hour
+
+	// defaultCorrelationLag is how far before its cursor the scheduled correlator reads again, for alerts the
+	// indexer stored after a run passed their timestamp
+	defaultCorrelationLag = 5 * time.Minute
+
+	// correlationBatchSize and maxCorrelationBatches bound the new alerts read from the indexer per run
+	correlationBatchSize  = 1000
+	maxCorrelationBatches = 10
+
+	// correlatorActor is recorded as the updater of the correlation cursor
+	correlatorActor = "case-correlator"
+
+	// webhookAlertIndex is the index name given to alerts pushed to the webhook
+	webhookAlertIndex = "webhook"
+)
+
+var correlationKeys = []string{
+	entity.CorrelationKeySrcIP,
+	entity.CorrelationKeyAgentID,
+	entity.CorrelationKeyRuleGroups,
+	entity.CorrelationKeyUser,
+}
+
+type caseUsecase struct {
+	wazuhEventRepo     domain.WazuhEventRepository
+	caseRepo           domain.CaseRepository
+	closedEventRepo    domain.ClosedEventRepository
+	triageActionRepo   domain.TriageActionRepository
+	settingRepo        domain.SettingRepository
+	geoIPUsecase       domain.GeoIPUsecase
+	iocUsecase         domain.IOCUsecase
+	cveUsecase         domain.CVEUsecase
+	mitreUsecase       domain.MitreUsecase
+	maintenanceUsecase domain.MaintenanceUsecase
+	snoozeUsecase      domain.SnoozeUsecase
+	sequenceUsecase    domain.SequenceUsecase
+	notifier           *notifier.Notifier
+
+	// correlatorMu serializes scheduled runs, correlatorRead holds when each alert the scheduled correlator
+	// correlated inside the lag window fired
+	correlatorMu   sync.Mutex
+	correlatorRead map[string]time.Time
+}
+
+func NewCaseUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	caseRepo domain.CaseRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+	settingRepo domain.SettingRepository,
+	geoIPUsecase domain.GeoIPUsecase,
+	iocUsecase domain.IOCUsecase,
+	cveUsecase domain.CVEUsecase,
+	mitreUsecase domain.MitreUsecase,
+	maintenanceUsecase domain.MaintenanceUsecase,
+	snoozeUsecase domain.SnoozeUsecase,
+	sequenceUsecase domain.SequenceUsecase,
+	notifier *notifier.Notifier,
+) domain.CaseUsecase {
+	return &caseUsecase{
+		wazuhEventRepo:     wazuhEventRepo,
+		caseRepo:           caseRepo,
+		closedEventRepo:    closedEventRepo,
+		triageActionRepo:   triageActionRepo,
+		settingRepo:        settingRepo,
+		geoIPUsecase:       geoIPUsecase,
+		iocUsecase:         iocUsecase,
+		cveUsecase:         cveUsecase,
+		mitreUsecase:       mitreUsecase,
+		maintenanceUsecase: maintenanceUsecase,
+		snoozeUsecase:      snoozeUsecase,
+		sequenceUsecase:    sequenceUsecase,
+		notifier:           notifier,
+		correlatorRead:     map[string]time.Time{},
+	}
+}
+
+// FetchCorrelationConfig returns the saved correlation config, or the environment defaults when none was saved
+func (u *caseUsecase) FetchCorrelationConfig(ctx context.Context) (*entity.CorrelationConfig, error) {
+	config := &entity.CorrelationConfig{
+		Keys:   defaultCorrelationKeyList(),
+		Window: correlationWindowFromEnv().String(),
+	}
+
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeyCorrelationConfig, config)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - case - FetchCorrelationConfig]: Failed to fetch correlation config")
+		return nil, err
+	}
+
+	if setting != nil {
+		config.UpdatedBy = setting.UpdatedBy
+		config.UpdatedAt = &setting.UpdatedAt
+	}
+	return config, nil
+}
+
+// UpdateCorrelationConfig changes the fields given in the request and keeps the others. Existing cases keep
+// their correlation key; the new config applies to alerts correlated from now on.
+func (u *caseUsecase) UpdateCorrelationConfig(ctx context.Context, request *model.UpdateCorrelationConfigRequest) (*entity.CorrelationConfig, error) {
+	log := logger.WithRequestID(ctx)
+
+	updatedBy := strings.TrimSpace(request.UpdatedBy)
+	if updatedBy == "" {
+		return nil, fmt.Errorf("invalid correlation config: updated_by is required")
+	}
+
+	config, err := u.FetchCorrelationConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Keys != nil {
+		keys, err := normalizeCorrelationKeys(request.Keys)
+		if err != nil {
+			return nil, err
+		}
+		config.Keys = keys
+	}
+	if request.Window != nil {
+		window, err := time.ParseDuration(strings.TrimSpace(*request.Window))
+		if err != nil || window <= 0 || window > maxCorrelationWindow {
+			return nil, fmt.Errorf("invalid correlation config: window must be a duration between 1s and %s", maxCorrelationWindow)
+		}
+		config.Window = window.String()
+	}
+
+	now := time.Now()
+	config.UpdatedBy = updatedBy
+	config.UpdatedAt = &now
+
+	value := entity.CorrelationConfig{Keys: config.Keys, Window: config.Window}
+	if err := saveSetting(ctx, u.settingRepo, entity.SettingKeyCorrelationConfig, value, updatedBy, now); err != nil {
+		log.WithError(err).Error("[usecase - case - UpdateCorrelationConfig]: Failed to save correlation config")
+		return nil, err
+	}
+
+	log.WithField("keys", strings.Join(config.Keys, ",")).WithField("window", config.Window).WithField("updated_by", updatedBy).Info("[usecase - case - UpdateCorrelationConfig]: Updated correlation config")
+	return config, nil
+}
+
+// CorrelateAlerts locates the source addresses, matches threat-intel indicators, runs every alert through the
+// sequence rules, applies maintenance windows and snoozes, then adds each remaining alert and finding to the
+// active case sharing its correlation key, or opens a new case when no case of that key saw an alert within the
+// window. Alerts already in a case are skipped, so hits may overlap.
+func (u *caseUsecase) CorrelateAlerts(ctx context.Context, hits []*elastic.SearchHit) (*entity.CorrelationResult, error) {
+	log := logger.WithRequestID(ctx)
+
+	config, window, err := u.fetchCorrelationConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.CorrelationResult{Alerts: len(hits)}
+
+	// Enriched first, so alerts closed by maintenance windows and snoozes keep their geoip, ioc, cve and mitre blocks
+	hits = u.iocUsecase.EnrichHits(ctx, u.geoIPUsecase.EnrichHits(ctx, hits))
+	hits = u.mitreUsecase.EnrichHits(ctx, u.cveUsecase.EnrichHits(ctx, hits))
+	for _, hit := range hits {
+		if source, ok := decodeAlertSource(hit.Source); ok && len(source.IOC) > 0 {
+			result.IOCMatches++
+		}
+	}
+
+	// Sequences see the whole batch, so a snoozed or maintenance alert still counts as a step of an attack
+	findings, err := u.sequenceUsecase.EvaluateAlerts(ctx, hits)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - case - CorrelateAlerts]: Failed to evaluate sequence rules, correlating alerts only")
+	}
+	result.Findings = len(findings)
+
+	hits, result.Maintenance, err = u.maintenanceUsecase.ApplyMaintenance(ctx, hits)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, result.Snoozed, err = u.snoozeUsecase.ApplySnoozes(ctx, hits)
+	if err != nil {
+		return nil, err
+	}
+	hits = append(hits[:len(hits):len(hits)], findings...)
+
+	alerts := make([]correlationAlert, 0, len(hits))
+
+	for _, hit := range hits {
+		alert, ok := newCorrelationAlert(hit, config.Keys)
+		if !ok {
+			result.Uncorrelated++
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	// Alerts are correlated in the order they fired, so the window slides forward with the case
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].firedAt.Before(alerts[j].firedAt)
+	})
+
+	updated := map[int]*entity.Case{}
+
+	for _, alert := range alerts {
+		caseID, err := u.caseRepo.FetchCaseIDByEventID(ctx, alert.eventID)
+		if err != nil {
+			return nil, err
+		}
+		if caseID != 0 {
+			result.Duplicates++
+			continue
+		}
+
+		c, created, err := u.caseForAlert(ctx, alert, window, updated)
+		if err != nil {
+			return nil, err
+		}
+
+		added, err := u.caseRepo.SaveCaseAlert(ctx, &entity.CaseAlert{
+			CaseID:    c.ID,
+			EventID:   alert.eventID,
+			RuleID:    alert.rule.ID,
+			RuleLevel: alert.rule.Level,
+			AgentName: alert.source.Agent.Name,
+			RawEvent:  alert.rawEvent,
+			AlertAt:   alert.firedAt,
+			AddedAt:   time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !added {
+			result.Duplicates++
+			continue
+		}
+
+		c.AlertCount++
+		if alert.highPriority {
+			c.HighPriority = true
+		}
+		if alert.rule.Level > c.MaxLevel {
+			c.MaxLevel = alert.rule.Level
+		}
+		if alert.firedAt.Before(c.FirstSeen) {
+			c.FirstSeen = alert.firedAt
+		}
+		if alert.firedAt.After(c.LastSeen) {
+			c.LastSeen = alert.firedAt
+		}
+		c.UpdatedAt = time.Now()
+
+		if err := u.caseRepo.UpdateCaseActivity(ctx, c); err != nil {
+			return nil, err
+		}
+
+		if created {
+			result.CasesCreated++
+		}
+		updated[c.ID] = c
+		result.Correlated++
+	}
+
+	result.CasesUpdated = len(updated) - result.CasesCreated
+
+	log.WithField("alerts", result.Alerts).WithField("ioc_matches", result.IOCMatches).WithField("maintenance", result.Maintenance).WithField("snoozed", result.Snoozed).WithField("findings", result.Findings).WithField("correlated", result.Correlated).WithField("uncorrelated", result.Uncorrelated).WithField("duplicates", result.Duplicates).WithField("cases_created", result.CasesCreated).WithField("cases_updated", result.CasesUpdated).Info("[usecase - case - CorrelateAlerts]: Correlated alerts into cases")
+	return result, nil
+}
+
+// caseForAlert returns the active case of the alert's correlation key within the window, preferring a case
+// already touched in this run, or opens a new one. It reports whether the case was created.
+func (u *caseUsecase) caseForAlert(ctx context.Context, alert correlationAlert, window time.Duration, touched map[int]*entity.Case) (*entity.Case, bool, error) {
+	since := alert.firedAt.Add(-window)
+
+	var latest *entity.Case
+	for _, c := range touched {
+		if c.CorrelationKey == alert.correlationKey && c.Status != entity.CaseStatusClosed && !c.LastSeen.Before(since) {
+			if latest == nil || c.LastSeen.After(latest.LastSeen) {
+				latest = c
+			}
+		}
+	}
+	if latest != nil {
+		return latest, false, nil
+	}
+
+	c, err := u.caseRepo.FetchActiveCaseByKey(ctx, alert.correlationKey, since)
+	if err != nil {
+		return nil, false, err
+	}
+	if c != nil {
+		return c, false, nil
+	}
+
+	now := time.Now()
+	c = &entity.Case{
+		CorrelationKey: alert.correlationKey,
+		KeyValues:      alert.keyValues,
+		Title:          fmt.Sprintf("%s (%s)", alert.rule.Description, alert.correlationKey),
+		Status:         entity.CaseStatusOpen,
+		MaxLevel:       alert.rule.Level,
+		LowPriority:    alert.lowPriority,
+		HighPriority:   alert.highPriority,
+		FirstSeen:      alert.firedAt,
+		LastSeen:       alert.firedAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := u.caseRepo.SaveCase(ctx, c); err != nil {
+		return nil, false, err
+	}
+
+	return c, true, nil
+}
+
+// Correlate reads the alerts of the last window from the indexer and correlates them. A zero window uses
+// the correlation window.
+func (u *caseUsecase) Correlate(ctx context.Context, window time.Duration) (*entity.CorrelationResult, error) {
+	if window <= 0 {
+		_, configWindow, err := u.fetchCorrelationConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		window = configWindow
+	}
+
+	return u.correlateSince(ctx, time.Now().Add(-window))
+}
+
+// IngestAlerts correlates alerts pushed by Wazuh integrations instead of read from the indexer. Every alert
+// needs an id, so a later poll of the indexer recognises it as a duplicate.
+func (u *caseUsecase) IngestAlerts(ctx context.Context, alerts []json.RawMessage) (*entity.CorrelationResult, error) {
+	hits := make([]*elastic.SearchHit, 0, len(alerts))
+
+	for i, alert := range alerts {
+		var securityEvent entity.WazuhSecurityEvent
+		if err := json.Unmarshal(alert, &securityEvent); err != nil || len(securityEvent.ID) == 0 {
+			return nil, fmt.Errorf("invalid alert at index %d: an object with an id is required", i)
+		}
+		hits = append(hits, &elastic.SearchHit{Id: string(securityEvent.ID), Index: webhookAlertIndex, Source: alert})
+	}
+
+	result, err := u.CorrelateAlerts(ctx, hits)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - case - IngestAlerts]: Failed to correlate webhook alerts")
+		return nil, err
+	}
+
+	result.Since = time.Now()
+	return result, nil
+}
+
+// RunScheduledCorrelation correlates the alerts fired since the previous run. The first run starts one
+// correlation window back. Every run reads again from the correlation lag before the cursor, so alerts the indexer
+// stored after a run passed their timestamp are still correlated, and skips the alerts of that window it already
+// correlated. The cursor moves to the last alert of every batch, so a run that stops after maxCorrelationBatches
+// batches of new alerts is resumed by the next one.
+func (u *caseUsecase) RunScheduledCorrelation(ctx context.Context) error {
+	log := logger.WithRequestID(ctx)
+
+	u.correlatorMu.Lock()
+	defer u.correlatorMu.Unlock()
+
+	cursor := entity.CorrelationCursor{}
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeyCorrelationCursor, &cursor)
+	if err != nil {
+		log.WithError(err).Error("[usecase - case - RunScheduledCorrelation]: Failed to fetch correlation cursor")
+		return err
+	}
+
+	lag := correlationLagFromEnv()
+	since := cursor.Timestamp.Add(-lag)
+	if setting == nil {
+		_, window, err := u.fetchCorrelationConfig(ctx)
+		if err != nil {
+			return err
+		}
+		since = time.Now().Add(-window)
+	}
+
+	var searchAfter []interface{}
+	for batches := 0; batches < maxCorrelationBatches; {
+		hits, err := u.wazuhEventRepo.FetchSecurityEventsSince(ctx, since, searchAfter, correlationBatchSize)
+		if err != nil {
+			log.WithError(err).Error("[usecase - case - RunScheduledCorrelation]: Failed to fetch security events")
+			return err
+		}
+		if len(hits) == 0 {
+			break
+		}
+
+		unread := make([]*elastic.SearchHit, 0, len(hits))
+		for _, hit := range hits {
+			if _, read := u.correlatorRead[hit.Id]; !read {
+				unread = append(unread, hit)
+			}
+		}
+
+		if len(unread) > 0 {
+			if _, err := u.CorrelateAlerts(ctx, unread); err != nil {
+				log.WithError(err).Error("[usecase - case - RunScheduledCorrelation]: Failed to correlate alerts")
+				return err
+			}
+		}
+
+		for _, hit := range unread {
+			if position, ok := correlationPosition(hit); ok {
+				u.correlatorRead[hit.Id] = position.Timestamp
+			}
+		}
+
+		last := hits[len(hits)-1]
+		if position, ok := correlationPosition(last); ok && correlationPositionAfter(position, cursor) {
+			cursor = position
+			if err := saveSetting(ctx, u.settingRepo, entity.SettingKeyCorrelationCursor, cursor, correlatorActor, time.Now()); err != nil {
+				log.WithError(err).Error("[usecase - case - RunScheduledCorrelation]: Failed to save correlation cursor")
+				return err
+			}
+			// Batches of the lag window read again do not count, so a busy lag window cannot stall the cursor
+			batches++
+		}
+
+		// A short batch is the last one
+		if len(hits) < correlationBatchSize || len(last.Sort) == 0 {
+			break
+		}
+		searchAfter = last.Sort
+	}
+
+	// The next run reads again from the lag before the cursor, older alerts are not read again
+	for eventID, firedAt := range u.correlatorRead {
+		if firedAt.Before(cursor.Timestamp.Add(-lag)) {
+			delete(u.correlatorRead, eventID)
+		}
+	}
+
+	return nil
+}
+
+// correlationPosition returns the cursor of a hit read by FetchSecurityEventsSince from its sort values, the
+// timestamp in epoch milliseconds then the alert id
+func correlationPosition(hit *elastic.SearchHit) (entity.CorrelationCursor, bool) {
+	if len(hit.Sort) != 2 {
+		return entity.CorrelationCursor{}, false
+	}
+
+	var millis int64
+	switch value := hit.Sort[0].(type) {
+	case float64:
+		millis = int64(value)
+	case json.Number:
+		n, err := value.Int64()
+		if err != nil {
+			return entity.CorrelationCursor{}, false
+		}
+		millis = n
+	default:
+		return entity.CorrelationCursor{}, false
+	}
+
+	id, _ := hit.Sort[1].(string)
+	return entity.CorrelationCursor{Timestamp: time.UnixMilli(millis).UTC(), ID: id}, true
+}
+
+// correlationPositionAfter reports whether position comes after cursor in the timestamp then id order
+func correlationPositionAfter(position entity.CorrelationCursor, cursor entity.CorrelationCursor) bool {
+	if !position.Timestamp.Equal(cursor.Timestamp) {
+		return position.Timestamp.After(cursor.Timestamp)
+	}
+	return position.ID > cursor.ID
+}
+
+// fetchCorrelationConfig returns the correlation config with its parsed window
+func (u *caseUsecase) fetchCorrelationConfig(ctx context.Context) (*entity.CorrelationConfig, time.Duration, error) {
+	config, err := u.FetchCorrelationConfig(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	window, err := time.ParseDuration(config.Window)
+	if err != nil {
+		return nil, 0, fmt.Errorf("stored correlation window %q is not a duration: %w", config.Window, err)
+	}
+	return config, window, nil
+}
+
+// correlateSince correlates the alerts fired since the given time, batch by batch. Batches resume after the last
+// alert of the previous one, so a flood of alerts sharing a timestamp still moves forward.
+func (u *caseUsecase) correlateSince(ctx context.Context, since time.Time) (*entity.CorrelationResult, error) {
+	log := logger.WithRequestID(ctx)
+
+	total := &entity.CorrelationResult{Since: since}
+	var searchAfter []interface{}
+
+	for batch := 0; batch < maxCorrelationBatches; batch++ {
+		hits, err := u.wazuhEventRepo.FetchSecurityEventsSince(ctx, since, searchAfter, correlationBatchSize)
+		if err != nil {
+			log.WithError(err).Error("[usecase - case - correlateSince]: Failed to fetch security events")
+			return nil, err
+		}
+
+		result, err := u.CorrelateAlerts(ctx, hits)
+		if err != nil {
+			log.WithError(err).Error("[usecase - case - correlateSince]: Failed to correlate alerts")
+			return nil, err
+		}
+
+		total.Alerts += result.Alerts
+		total.IOCMatches += result.IOCMatches
+		total.Maintenance += result.Maintenance
+		total.Snoozed += result.Snoozed
+		total.Findings += result.Findings
+		total.Correlated += result.Correlated
+		total.Uncorrelated += result.Uncorrelated
+		total.Duplicates += result.Duplicates
+		total.CasesCreated += result.CasesCreated
+		total.CasesUpdated += result.CasesUpdated
+
+		// A short batch is the last one
+		if len(hits) < correlationBatchSize || len(hits[len(hits)-1].Sort) == 0 {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+
+	return total, nil
+}
+
+func (u *caseUsecase) FetchCases(ctx context.Context, status string, correlationKey string) ([]*entity.Case, error) {
+	cases, err := u.caseRepo.FetchCases(ctx, status, correlationKey)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - case - FetchCases]: Failed to fetch cases")
+		return nil, err
+	}
+
+	if cases == nil {
+		cases = []*entity.Case{}
+	}
+	return cases, nil
+}
+
+func (u *caseUsecase) FetchCaseByID(ctx context.Context, id int) (*entity.Case, []*entity.CaseAlert, error) {
+	log := logger.WithRequestID(ctx)
+
+	c, err := u.caseRepo.FetchCaseByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("case_id", id).Error("[usecase - case - FetchCaseByID]: Failed to fetch case")
+		return nil, nil, err
+	}
+	if c == nil {
+		return nil, nil, fmt.Errorf("case with ID %d not found", id)
+	}
+
+	alerts, err := u.caseRepo.FetchCaseAlerts(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("case_id", id).Error("[usecase - case - FetchCaseByID]: Failed to fetch case alerts")
+		return nil, nil, err
+	}
+
+	if alerts == nil {
+		alerts = []*entity.CaseAlert{}
+	}
+	return c, alerts, nil
+}
+
+// EscalateCase hands an open case over for investigation. Every open alert of the case records the
+// escalation as its triage action, and the team is notified.
+func (u *caseUsecase) EscalateCase(ctx context.Context, id int, request *model.EscalateCaseRequest) (*entity.Case, error) {
+	log := logger.WithRequestID(ctx)
+
+	analyst := strings.TrimSpace(request.Analyst)
+	reason := strings.TrimSpace(request.Reason)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid escalation: analyst is required")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("invalid escalation: reason is required")
+	}
+
+	c, alerts, err := u.FetchCaseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != entity.CaseStatusOpen {
+		return nil, fmt.Errorf("case with ID %d is already %s", id, c.Status)
+	}
+
+	if err := u.caseRepo.EscalateCase(ctx, id, analyst, reason, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("case with ID %d is already escalated or closed", id)
+		}
+		log.WithError(err).WithField("case_id", id).Error("[usecase - case - EscalateCase]: Failed to escalate case")
+		return nil, err
+	}
+
+	escalated := 0
+	for _, alert := range alerts {
+		closedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, alert.EventID)
+		if err != nil {
+			log.WithError(err).WithField("event_id", alert.EventID).Warn("[usecase - case - EscalateCase]: Failed to check closed event, skipping alert")
+			continue
+		}
+		if closedEvent != nil {
+			continue
+		}
+
+		u.recordTriageAction(ctx, alert, entity.TriageActionEscalated, analyst, time.Now())
+		escalated++
+	}
+
+	log.WithField("case_id", id).WithField("analyst", analyst).WithField("escalated_alerts", escalated).Info("[usecase - case - EscalateCase]: Case escalated")
+
+	if err := u.notifier.Notify(ctx, notifier.Notification{
+		Title:    fmt.Sprintf("Case #%d escalated", id),
+		Severity: "critical",
+		Message:  fmt.Sprintf("%s escalated %q with %d alerts: %s", analyst, c.Title, c.AlertCount, reason),
+		Data:     c,
+	}); err != nil {
+		log.WithError(err).Warn("[usecase - case - EscalateCase]: Failed to send notification")
+	}
+
+	return u.caseRepo.FetchCaseByID(ctx, id)
+}
+
+// CloseCase closes every alert of the case that is still open with the analyst's reason and label, then the
+// case itself. Alerts closed before are left as they are. A failure leaves the case open, so closing it again
+// picks up the remaining alerts.
+func (u *caseUsecase) CloseCase(ctx context.Context, id int, request *model.CloseCaseRequest) (*entity.Case, error) {
+	log := logger.WithRequestID(ctx)
+
+	analyst := strings.TrimSpace(request.Analyst)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid case closure: analyst is required")
+	}
+	if !entity.IsValidLabel(request.Label) {
+		return nil, fmt.Errorf("invalid label %q: must be %s or %s", request.Label, entity.LabelFalsePositive, entity.LabelTruePositive)
+	}
+
+	c, alerts, err := u.FetchCaseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status == entity.CaseStatusClosed {
+		return nil, fmt.Errorf("case with ID %d is already closed", id)
+	}
+
+	reason := strings.TrimSpace(request.Reason)
+	alertReason := fmt.Sprintf("closed with case #%d", id)
+	if reason != "" {
+		alertReason += ": " + reason
+	}
+
+	closed := 0
+	for _, alert := range alerts {
+		existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, alert.EventID)
+		if err != nil {
+			log.WithError(err).WithField("event_id", alert.EventID).Error("[usecase - case - CloseCase]: Failed to check existing closed event")
+			return nil, err
+		}
+		if existingClosedEvent != nil {
+			continue
+		}
+
+		closedEvent := &entity.ClosedEvent{
+			EventID:   alert.EventID,
+			RuleID:    alert.RuleID,
+			RawEvent:  alert.RawEvent,
+			Reason:    alertReason,
+			Status:    "closed",
+			CloseType: entity.CloseTypeManual,
+			Label:     request.Label,
+			CloseAt:   time.Now(),
+		}
+		if err := u.closedEventRepo.SaveClosedEvent(ctx, closedEvent); err != nil {
+			log.WithError(err).WithField("event_id", alert.EventID).Error("[usecase - case - CloseCase]: Failed to close case alert")
+			return nil, err
+		}
+
+		u.recordTriageAction(ctx, alert, entity.TriageActionClosed, analyst, closedEvent.CloseAt)
+		closed++
+	}
+
+	if err := u.caseRepo.CloseCase(ctx, id, analyst, reason, request.Label, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("case with ID %d is already closed", id)
+		}
+		log.WithError(err).WithField("case_id", id).Error("[usecase - case - CloseCase]: Failed to close case")
+		return nil, err
+	}
+
+	log.WithField("case_id", id).WithField("analyst", analyst).WithField("closed_alerts", closed).WithField("alert_count", len(alerts)).Info("[usecase - case - CloseCase]: Case closed")
+	return u.caseRepo.FetchCaseByID(ctx, id)
+}
+
+// recordTriageAction stores the transition of a case alert. The case change already succeeded, so a failure
+// only costs KPI accuracy and is logged instead of returned.
+func (u *caseUsecase) recordTriageAction(ctx context.Context, alert *entity.CaseAlert, action string, actor string, at time.Time) {
+	triageAction := newTriageAction(alert.EventID, alert.RuleID, alert.RawEvent, action, actor)
+	triageAction.CreatedAt = at
+
+	if err := u.triageActionRepo.SaveTriageAction(ctx, triageAction); err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("event_id", alert.EventID).Warn("[usecase - case - recordTriageAction]: Failed to record triage action")
+	}
+}
+
+// correlationAlert is an alert with the values it is correlated on
+type correlationAlert struct {
+	eventID        string
+	rule           *entity.WazuhSecurityEventRule
+	source         alertSource
+	keyValues      map[string]string
+	correlationKey string
+	rawEvent       string
+	firedAt        time.Time
+	lowPriority    bool // lowered by a maintenance window
+	highPriority   bool // matches a threat-intel indicator
+}
+
+// newCorrelationAlert reads the correlation keys of a search hit. It reports false when the alert cannot be
+// parsed or lacks a value for any of the keys.
+func newCorrelationAlert(hit *elastic.SearchHit, keys []string) (correlationAlert, bool) {
+	var securityEvent entity.WazuhSecurityEvent
+	if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || securityEvent.Rule == nil {
+		return correlationAlert{}, false
+	}
+	source, ok := decodeAlertSource(hit.Source)
+	if !ok {
+		return correlationAlert{}, false
+	}
+
+	keyValues := map[string]string{}
+	parts := make([]string, 0, len(keys))
+
+	if source.Sequence.Rule != "" {
+		// A sequence finding opens a case of its own, keyed by its rule and the values the rule grouped on
+		keyValues["sequence"] = source.Sequence.Rule
+		for key, value := range source.Sequence.KeyValues {
+			keyValues[key] = value
+		}
+		parts = append(parts, "sequence="+source.Sequence.Rule)
+		if source.Sequence.Key != "" {
+			parts = append(parts, source.Sequence.Key)
+		}
+	} else {
+		for _, key := range keys {
+			value := correlationValue(key, source, securityEvent.Rule)
+			if value == "" {
+				return correlationAlert{}, false
+			}
+			keyValues[key] = value
+			parts = append(parts, key+"="+value)
+		}
+		// Alerts lowered by a maintenance window open cases of their own, apart from the regular queue
+		if source.Maintenance.Outcome == entity.MaintenanceOutcomeLowered {
+			windowID := strconv.Itoa(source.Maintenance.WindowID)
+			keyValues["maintenance"] = windowID
+			parts = append(parts, "maintenance="+windowID)
+		}
+		sort.Strings(parts)
+	}
+
+	hitJSON, err := json.Marshal(hit)
+	if err != nil {
+		return correlationAlert{}, false
+	}
+
+	// Seen times are stored in UTC so they compare as text in SQLite whatever offset the indexer wrote
+	firedAt, ok := parseAlertTimestamp(source.Timestamp)
+	if !ok {
+		firedAt = time.Now()
+	}
+	firedAt = firedAt.UTC()
+
+	return correlationAlert{
+		eventID:        string(securityEvent.ID),
+		rule:           securityEvent.Rule,
+		source:         source,
+		keyValues:      keyValues,
+		correlationKey: strings.Join(parts, ","),
+		rawEvent:       string(hitJSON),
+		firedAt:        firedAt,
+		lowPriority:    source.Maintenance.Outcome == entity.MaintenanceOutcomeLowered,
+		highPriority:   len(source.IOC) > 0,
+	}, true
+}
+
+// correlationValue returns the value of one correlation key of an alert, empty when the alert has none.
+// Rule groups are compared as a whole, so alerts correlate only when their rules share all groups.
+func correlationValue(key string, source alertSource, rule *entity.WazuhSecurityEventRule) string {
+	switch key {
+	case entity.CorrelationKeySrcIP:
+		return source.Data.SrcIP
+	case entity.CorrelationKeyAgentID:
+		return source.Agent.ID
+	case entity.CorrelationKeyRuleGroups:
+		groups := append([]string{}, rule.Groups...)
+		sort.Strings(groups)
+		return strings.Join(groups, "+")
+	case entity.CorrelationKeyUser:
+		return alertUser(source)
+	}
+	return ""
+}
+
+// normalizeCorrelationKeys validates the keys and returns them sorted without duplicates
+func normalizeCorrelationKeys(keys []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := []string{}
+
+	for _, key := range cleanList(keys) {
+		if !isValidCorrelationKey(key) {
+			return nil, fmt.Errorf("invalid correlation config: unknown key %q, must be one of %s", key, strings.Join(correlationKeys, ", "))
+		}
+		if !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, key)
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("invalid correlation config: at least one key is required")
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func isValidCorrelationKey(key string) bool {
+	for _, known := range correlationKeys {
+		if key == known {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultCorrelationKeyList reads CORRELATION_KEYS, falling back to srcip when it is unset or invalid
+func defaultCorrelationKeyList() []string {
+	value := os.Getenv("CORRELATION_KEYS")
+	if value == "" {
+		value = defaultCorrelationKeys
+	}
+
+	keys, err := normalizeCorrelationKeys(strings.Split(value, ","))
+	if err != nil {
+		return []string{defaultCorrelationKeys}
+	}
+	return keys
+}
+
+// correlationLagFromEnv reads CORRELATION_LAG, zero turns the re-reading off
+func correlationLagFromEnv() time.Duration {
+	lag, err := time.ParseDuration(os.Getenv("CORRELATION_LAG"))
+	if err != nil || lag < 0 || lag > maxCorrelationWindow {
+		return defaultCorrelationLag
+	}
+	return lag
+}
+
+func correlationWindowFromEnv() time.Duration {
+	window, err := time.ParseDuration(os.Getenv("CORRELATION_WINDOW"))
+	if err != nil || window <= 0 || window > maxCorrelationWindow {
+		return defaultCorrelationWindow
+	}
+	return window
+}
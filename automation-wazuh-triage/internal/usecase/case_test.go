@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type testAlert struct {
+	id      string
+	firedAt time.Time
+}
+
+// memAlertIndex serves FetchSecurityEventsSince from memory with the sort values OpenSearch returns
+type memAlertIndex struct {
+	domain.WazuhEventRepository
+	alerts []testAlert
+}
+
+func (m *memAlertIndex) FetchSecurityEventsSince(ctx context.Context, since time.Time, searchAfter []interface{}, limit int) ([]*elastic.SearchHit, error) {
+	alerts := append([]testAlert(nil), m.alerts...)
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].firedAt.Equal(alerts[j].firedAt) {
+			return alerts[i].firedAt.Before(alerts[j].firedAt)
+		}
+		return alerts[i].id < alerts[j].id
+	})
+
+	var hits []*elastic.SearchHit
+	for _, alert := range alerts {
+		millis := alert.firedAt.UnixMilli()
+		if millis < since.UnixMilli() {
+			continue
+		}
+		if len(searchAfter) == 2 {
+			afterMillis, afterID := int64(searchAfter[0].(float64)), searchAfter[1].(string)
+			if millis < afterMillis || millis == afterMillis && alert.id <= afterID {
+				continue
+			}
+		}
+		if len(hits) == limit {
+			break
+		}
+
+		source, _ := json.Marshal(map[string]interface{}{
+			"id":        alert.id,
+			"timestamp": alert.firedAt.UTC().Format(time.RFC3339Nano),
+			"rule":      map[string]interface{}{"id": "5710", "level": 5, "description": "sshd: attempt to login using a non-existent user"},
+			"data":      map[string]interface{}{"srcip": "10.0.0.5"},
+		})
+		hits = append(hits, &elastic.SearchHit{
+			Id:     "doc-" + alert.id,
+			Source: source,
+			Sort:   []interface{}{float64(millis), alert.id},
+		})
+	}
+	return hits, nil
+}
+
+// memCases keeps cases and the case of every alert, and counts how often each alert is looked up to be correlated
+type memCases struct {
+	domain.CaseRepository
+	cases  []*entity.Case
+	alerts map[string]int
+	looked map[string]int
+}
+
+func (m *memCases) SaveCase(ctx context.Context, c *entity.Case) error {
+	c.ID = len(m.cases) + 1
+	m.cases = append(m.cases, c)
+	return nil
+}
+
+func (m *memCases) FetchActiveCaseByKey(ctx context.Context, correlationKey string, since time.Time) (*entity.Case, error) {
+	for _, c := range m.cases {
+		if c.CorrelationKey == correlationKey && c.Status != entity.CaseStatusClosed && !c.LastSeen.Before(since) {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memCases) UpdateCaseActivity(ctx context.Context, c *entity.Case) error { return nil }
+
+func (m *memCases) SaveCaseAlert(ctx context.Context, alert *entity.CaseAlert) (bool, error) {
+	if _, ok := m.alerts[alert.EventID]; ok {
+		return false, nil
+	}
+	m.alerts[alert.EventID] = alert.CaseID
+	return true, nil
+}
+
+func (m *memCases) FetchCaseIDByEventID(ctx context.Context, eventID string) (int, error) {
+	m.looked[eventID]++
+	return m.alerts[eventID], nil
+}
+
+// The enrichment and closing steps of correlation leave every alert as it is, and the sequence rules find nothing
+type (
+	plainGeoIP       struct{ domain.GeoIPUsecase }
+	plainIOC         struct{ domain.IOCUsecase }
+	plainCVE         struct{ domain.CVEUsecase }
+	plainMitre       struct{ domain.MitreUsecase }
+	plainMaintenance struct{ domain.MaintenanceUsecase }
+	plainSnooze      struct{ domain.SnoozeUsecase }
+	plainSequences   struct{ domain.SequenceUsecase }
+)
+
+func (plainGeoIP) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	return hits
+}
+
+func (plainIOC) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	return hits
+}
+
+func (plainCVE) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	return hits
+}
+
+func (plainMitre) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	return hits
+}
+
+func (plainMaintenance) ApplyMaintenance(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, int, error) {
+	return hits, 0, nil
+}
+
+func (plainSnooze) ApplySnoozes(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, int, error) {
+	return hits, 0, nil
+}
+
+func (plainSequences) EvaluateAlerts(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, error) {
+	return nil, nil
+}
+
+func TestRunScheduledCorrelation(t *testing.T) {
+	// Inside the first correlation window, at a whole millisecond like the indexer stores
+	start := time.Now().Add(-20 * time.Minute).Truncate(time.Second).UTC()
+	at := func(offset time.Duration) time.Time { return start.Add(offset) }
+
+	backlog := make([]testAlert, correlationBatchSize*maxCorrelationBatches+500)
+	for i := range backlog {
+		backlog[i] = testAlert{id: fmt.Sprintf("b%05d", i), firedAt: at(0)}
+	}
+
+	tests := []struct {
+		name       string
+		runs       [][]testAlert // alerts the indexer stored before each run
+		wantMissed []string      // alerts never correlated
+		wantCursor []entity.CorrelationCursor
+	}{
+		{
+			name: "new alerts of each run",
+			runs: [][]testAlert{
+				{{"a", at(0)}, {"b", at(time.Minute)}},
+				{{"c", at(2 * time.Minute)}},
+				{},
+			},
+			wantCursor: []entity.CorrelationCursor{
+				{Timestamp: at(time.Minute), ID: "b"},
+				{Timestamp: at(2 * time.Minute), ID: "c"},
+				{Timestamp: at(2 * time.Minute), ID: "c"},
+			},
+		},
+		{
+			name: "alert stored late inside the lag",
+			runs: [][]testAlert{
+				{{"a", at(0)}, {"b", at(time.Minute)}},
+				{{"late", at(30 * time.Second)}, {"c", at(2 * time.Minute)}},
+			},
+			wantCursor: []entity.CorrelationCursor{
+				{Timestamp: at(time.Minute), ID: "b"},
+				{Timestamp: at(2 * time.Minute), ID: "c"},
+			},
+		},
+		{
+			name: "alert stored late at the cursor timestamp",
+			runs: [][]testAlert{
+				{{"a", at(0)}, {"b", at(time.Minute)}},
+				{{"ab", at(time.Minute)}, {"a0", at(time.Minute)}},
+			},
+			wantCursor: []entity.CorrelationCursor{
+				{Timestamp: at(time.Minute), ID: "b"},
+				{Timestamp: at(time.Minute), ID: "b"},
+			},
+		},
+		{
+			name: "alert stored later than the lag is missed",
+			runs: [][]testAlert{
+				{{"a", at(0)}, {"b", at(10 * time.Minute)}},
+				{{"late", at(time.Minute)}},
+			},
+			wantMissed: []string{"late"},
+			wantCursor: []entity.CorrelationCursor{
+				{Timestamp: at(10 * time.Minute), ID: "b"},
+				{Timestamp: at(10 * time.Minute), ID: "b"},
+			},
+		},
+		{
+			name: "backlog over the batch limit resumes at the cursor",
+			runs: [][]testAlert{backlog, {}},
+			wantCursor: []entity.CorrelationCursor{
+				{Timestamp: at(0), ID: backlog[correlationBatchSize*maxCorrelationBatches-1].id},
+				{Timestamp: at(0), ID: backlog[len(backlog)-1].id},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CORRELATION_KEYS", "")
+			t.Setenv("CORRELATION_WINDOW", "")
+			t.Setenv("CORRELATION_LAG", "")
+			ctx := context.Background()
+
+			index := &memAlertIndex{}
+			settings := &memSettings{settings: map[string]*entity.Setting{}}
+			cases := &memCases{alerts: map[string]int{}, looked: map[string]int{}}
+			u := NewCaseUsecase(index, cases, nil, nil, settings, plainGeoIP{}, plainIOC{}, plainCVE{}, plainMitre{}, plainMaintenance{}, plainSnooze{}, plainSequences{}, nil)
+
+			for i, stored := range tt.runs {
+				index.alerts = append(index.alerts, stored...)
+				if err := u.RunScheduledCorrelation(ctx); err != nil {
+					t.Fatalf("run %d: %v", i, err)
+				}
+
+				var cursor entity.CorrelationCursor
+				if _, err := loadSetting(ctx, settings, entity.SettingKeyCorrelationCursor, &cursor); err != nil {
+					t.Fatalf("run %d: loadSetting: %v", i, err)
+				}
+				if !cursor.Timestamp.Equal(tt.wantCursor[i].Timestamp) || cursor.ID != tt.wantCursor[i].ID {
+					t.Fatalf("run %d: cursor = %+v, want %+v", i, cursor, tt.wantCursor[i])
+				}
+			}
+
+			missed := map[string]bool{}
+			for _, id := range tt.wantMissed {
+				missed[id] = true
+			}
+			for _, alert := range index.alerts {
+				want := 1
+				if missed[alert.id] {
+					want = 0
+				}
+				if looked := cases.looked[alert.id]; looked != want {
+					t.Errorf("alert %s was correlated %d times, want %d", alert.id, looked, want)
+				}
+			}
+			if want := len(index.alerts) - len(tt.wantMissed); len(cases.alerts) != want {
+				t.Errorf("%d alerts in cases, want %d", len(cases.alerts), want)
+			}
+		})
+	}
+}
+
+func TestCorrelationPosition(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 123000000, time.UTC)
+
+	tests := []struct {
+		name   string
+		sort   []interface{}
+		want   entity.CorrelationCursor
+		wantOK bool
+	}{
+		{name: "decoded sort values", sort: []interface{}{float64(at.UnixMilli()), "1760864400.1"}, want: entity.CorrelationCursor{Timestamp: at, ID: "1760864400.1"}, wantOK: true},
+		{name: "number sort values", sort: []interface{}{json.Number(fmt.Sprint(at.UnixMilli())), "1760864400.1"}, want: entity.CorrelationCursor{Timestamp: at, ID: "1760864400.1"}, wantOK: true},
+		{name: "alert without an id", sort: []interface{}{float64(at.UnixMilli()), nil}, want: entity.CorrelationCursor{Timestamp: at}, wantOK: true},
+		{name: "no sort values", sort: nil},
+		{name: "timestamp only", sort: []interface{}{float64(at.UnixMilli())}},
+		{name: "timestamp as a string", sort: []interface{}{"2026-10-19T09:00:00Z", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := correlationPosition(&elastic.SearchHit{Sort: tt.sort})
+			if ok != tt.wantOK || !got.Timestamp.Equal(tt.want.Timestamp) || got.ID != tt.want.ID {
+				t.Fatalf("correlationPosition(%v) = %+v, %v, want %+v, %v", tt.sort, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCorrelationPositionAfter(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		position entity.CorrelationCursor
+		cursor   entity.CorrelationCursor
+		want     bool
+	}{
+		{name: "later timestamp", position: entity.CorrelationCursor{Timestamp: at.Add(time.Millisecond), ID: "a"}, cursor: entity.CorrelationCursor{Timestamp: at, ID: "z"}, want: true},
+		{name: "earlier timestamp", position: entity.CorrelationCursor{Timestamp: at, ID: "z"}, cursor: entity.CorrelationCursor{Timestamp: at.Add(time.Millisecond), ID: "a"}, want: false},
+		{name: "same timestamp, later id", position: entity.CorrelationCursor{Timestamp: at, ID: "b"}, cursor: entity.CorrelationCursor{Timestamp: at, ID: "a"}, want: true},
+		{name: "same position", position: entity.CorrelationCursor{Timestamp: at, ID: "a"}, cursor: entity.CorrelationCursor{Timestamp: at, ID: "a"}, want: false},
+		{name: "cursor saved without an id", position: entity.CorrelationCursor{Timestamp: at, ID: "a"}, cursor: entity.CorrelationCursor{Timestamp: at}, want: true},
+		{name: "same instant in another location", position: entity.CorrelationCursor{Timestamp: at.In(time.FixedZone("UTC+2", 2*60*60)), ID: "a"}, cursor: entity.CorrelationCursor{Timestamp: at, ID: "a"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := correlationPositionAfter(tt.position, tt.cursor); got != tt.want {
+				t.Fatalf("%+v after %+v = %v, want %v", tt.position, tt.cursor, got, tt.want)
+			}
+		})
+	}
+}
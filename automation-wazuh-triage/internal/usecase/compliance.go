@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// defaultCompliancePeriod is the period of a report without a start
+	defaultCompliancePeriod = 30 * 24 * time.Hour
+
+	// maxComplianceAlerts caps the alerts a report reads, newest first
+	maxComplianceAlerts = 10000
+)
+
+// complianceFrameworks maps the accepted framework names to the rule field that tags them
+var complianceFrameworks = map[string]string{
+	"pci_dss":     entity.ComplianceFrameworkPCIDSS,
+	"pci-dss":     entity.ComplianceFrameworkPCIDSS,
+	"pcidss":      entity.ComplianceFrameworkPCIDSS,
+	"pci":         entity.ComplianceFrameworkPCIDSS,
+	"gdpr":        entity.ComplianceFrameworkGDPR,
+	"hipaa":       entity.ComplianceFrameworkHIPAA,
+	"nist_800_53": entity.ComplianceFrameworkNIST80053,
+	"nist-800-53": entity.ComplianceFrameworkNIST80053,
+	"nist":        entity.ComplianceFrameworkNIST80053,
+	"tsc":         entity.ComplianceFrameworkTSC,
+}
+
+type complianceUsecase struct {
+	wazuhEventRepo   domain.WazuhEventRepository
+	closedEventRepo  domain.ClosedEventRepository
+	triageActionRepo domain.TriageActionRepository
+	ruleRepo         domain.RuleRepository
+	snapshotRepo     domain.RuleSnapshotRepository
+}
+
+func NewComplianceUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+	ruleRepo domain.RuleRepository,
+	snapshotRepo domain.RuleSnapshotRepository,
+) domain.ComplianceUsecase {
+	return &complianceUsecase{
+		wazuhEventRepo:   wazuhEventRepo,
+		closedEventRepo:  closedEventRepo,
+		triageActionRepo: triageActionRepo,
+		ruleRepo:         ruleRepo,
+		snapshotRepo:     snapshotRepo,
+	}
+}
+
+// FetchComplianceReport lists each requirement of the framework with the rules tagged with it and the alerts
+// that fired in the period, how each was triaged and the reasons they were closed for. Requirements come from
+// the alert's own tags and the rule catalog, so requirements of rules that never fired are listed too.
+func (u *complianceUsecase) FetchComplianceReport(ctx context.Context, request *model.ComplianceReportRequest) (*entity.ComplianceReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	framework, ok := complianceFrameworks[strings.ToLower(strings.TrimSpace(request.Framework))]
+	if !ok {
+		return nil, fmt.Errorf("invalid framework %q, expected pci_dss, gdpr, hipaa, nist_800_53 or tsc", request.Framework)
+	}
+
+	to := request.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := request.From
+	if from.IsZero() {
+		from = to.Add(-defaultCompliancePeriod)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid period, from must be before to")
+	}
+
+	report := &entity.ComplianceReport{
+		Framework:    framework,
+		From:         from.UTC(),
+		To:           to.UTC(),
+		GeneratedAt:  time.Now().UTC(),
+		Requirements: []entity.ComplianceRequirement{},
+	}
+
+	requirements := map[string]*entity.ComplianceRequirement{}
+	requirementFor := func(name string) *entity.ComplianceRequirement {
+		requirement, ok := requirements[name]
+		if !ok {
+			requirement = &entity.ComplianceRequirement{
+				Requirement: name,
+				Rules:       []int{},
+				Reasons:     []entity.ComplianceReason{},
+				OpenItems:   []entity.ComplianceAlert{},
+				Alerts:      []entity.ComplianceAlert{},
+			}
+			requirements[name] = requirement
+		}
+		return requirement
+	}
+
+	catalog := fetchRuleCatalog(ctx, u.snapshotRepo, u.ruleRepo)
+	ruleIDs := make([]int, 0, len(catalog))
+	for id := range catalog {
+		ruleIDs = append(ruleIDs, id)
+	}
+	sort.Ints(ruleIDs)
+
+	ruleTags := map[string][]string{}
+	taggedRuleIDs := []string{}
+	for _, id := range ruleIDs {
+		rule := catalog[id]
+		tags := cleanList(rule.Tags(framework))
+		if len(tags) == 0 {
+			continue
+		}
+
+		report.Rules++
+		ruleTags[strconv.Itoa(id)] = tags
+		taggedRuleIDs = append(taggedRuleIDs, strconv.Itoa(id))
+		for _, tag := range tags {
+			requirement := requirementFor(tag)
+			requirement.Rules = append(requirement.Rules, id)
+		}
+	}
+
+	hits, err := u.wazuhEventRepo.FetchComplianceAlerts(ctx, framework, taggedRuleIDs, from, to, maxComplianceAlerts)
+	if err != nil {
+		log.WithError(err).Error("[usecase - compliance - FetchComplianceReport]: Failed to fetch alerts")
+		return nil, err
+	}
+	report.Truncated = len(hits) >= maxComplianceAlerts
+
+	closedEvents, err := u.closedEventRepo.FetchClosedEventsSince(ctx, from)
+	if err != nil {
+		log.WithError(err).Error("[usecase - compliance - FetchComplianceReport]: Failed to fetch closed events")
+		return nil, err
+	}
+	closures := make(map[string]*entity.ClosedEvent, len(closedEvents))
+	for _, closed := range closedEvents {
+		closures[closed.EventID] = closed
+	}
+
+	actions, err := u.triageActionRepo.FetchTriageActionsForAlertsSince(ctx, from.UTC())
+	if err != nil {
+		log.WithError(err).Error("[usecase - compliance - FetchComplianceReport]: Failed to fetch triage actions")
+		return nil, err
+	}
+	// Actions come oldest first, so the last closer and the first to acknowledge win
+	closers := map[string]string{}
+	acknowledgers := map[string]string{}
+	for _, action := range actions {
+		switch action.Action {
+		case entity.TriageActionClosed:
+			closers[action.EventID] = action.Actor
+		case entity.TriageActionAcknowledged:
+			if _, ok := acknowledgers[action.EventID]; !ok {
+				acknowledgers[action.EventID] = action.Actor
+			}
+		}
+	}
+
+	reasonCounts := map[string]map[string]int{}
+
+	for _, hit := range hits {
+		var securityEvent entity.WazuhSecurityEvent
+		source, ok := decodeAlertSource(hit.Source)
+		if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || !ok {
+			continue
+		}
+
+		// The alert's own tags, then those its rule has now, each requirement once
+		var tags []string
+		seen := map[string]bool{}
+		for _, tag := range cleanList(append(append([]string{}, complianceTags(source, framework)...), ruleTags[source.Rule.ID]...)) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+
+		eventID := string(securityEvent.ID)
+		alert := entity.ComplianceAlert{
+			EventID:     eventID,
+			RuleID:      source.Rule.ID,
+			Description: source.Rule.Description,
+			AgentID:     source.Agent.ID,
+			AgentName:   source.Agent.Name,
+			Outcome:     entity.ComplianceOutcomeOpen,
+		}
+		if firedAt, ok := parseAlertTimestamp(source.Timestamp); ok {
+			alert.FiredAt = firedAt.UTC()
+		}
+		if source.Rule.Level != nil {
+			alert.Level = *source.Rule.Level
+		}
+
+		if closed, ok := closures[eventID]; ok {
+			closedAt := closed.CloseAt.UTC()
+			alert.ClosedAt = &closedAt
+			alert.ClosedBy = closers[eventID]
+			alert.Label = closed.Label
+			alert.Reason = closed.Reason
+			alert.Outcome = entity.ComplianceOutcomeClosed
+			if closed.CloseType == entity.CloseTypeAuto {
+				alert.Outcome = entity.ComplianceOutcomeAutoClosed
+			}
+		} else if actor, ok := acknowledgers[eventID]; ok {
+			alert.Outcome = entity.ComplianceOutcomeAcknowledged
+			alert.AcknowledgedBy = actor
+		}
+
+		report.Alerts++
+		for _, tag := range tags {
+			requirement := requirementFor(tag)
+			requirement.AlertCount++
+			requirement.Alerts = append(requirement.Alerts, alert)
+
+			switch alert.Outcome {
+			case entity.ComplianceOutcomeOpen:
+				requirement.Open++
+				requirement.OpenItems = append(requirement.OpenItems, alert)
+			case entity.ComplianceOutcomeAcknowledged:
+				requirement.Acknowledged++
+				requirement.OpenItems = append(requirement.OpenItems, alert)
+			case entity.ComplianceOutcomeAutoClosed:
+				requirement.AutoClosed++
+			case entity.ComplianceOutcomeClosed:
+				requirement.Closed++
+			}
+
+			switch alert.Label {
+			case entity.LabelFalsePositive:
+				requirement.FalsePositives++
+			case entity.LabelTruePositive:
+				requirement.TruePositives++
+			}
+
+			if alert.Reason != "" {
+				if reasonCounts[tag] == nil {
+					reasonCounts[tag] = map[string]int{}
+				}
+				reasonCounts[tag][alert.Reason]++
+			}
+		}
+	}
+
+	for name, requirement := range requirements {
+		for reason, count := range reasonCounts[name] {
+			requirement.Reasons = append(requirement.Reasons, entity.ComplianceReason{Reason: reason, Count: count})
+		}
+		sort.Slice(requirement.Reasons, func(i, j int) bool {
+			if requirement.Reasons[i].Count != requirement.Reasons[j].Count {
+				return requirement.Reasons[i].Count > requirement.Reasons[j].Count
+			}
+			return requirement.Reasons[i].Reason < requirement.Reasons[j].Reason
+		})
+		sort.SliceStable(requirement.OpenItems, func(i, j int) bool {
+			return requirement.OpenItems[i].FiredAt.Before(requirement.OpenItems[j].FiredAt)
+		})
+		sort.SliceStable(requirement.Alerts, func(i, j int) bool {
+			return requirement.Alerts[i].FiredAt.After(requirement.Alerts[j].FiredAt)
+		})
+		report.Requirements = append(report.Requirements, *requirement)
+	}
+
+	sort.Slice(report.Requirements, func(i, j int) bool {
+		return requirementLess(report.Requirements[i].Requirement, report.Requirements[j].Requirement)
+	})
+
+	return report, nil
+}
+
+// complianceTags returns the requirements the alert's rule was tagged with in the framework when it fired
+func complianceTags(source alertSource, framework string) []string {
+	switch framework {
+	case entity.ComplianceFrameworkPCIDSS:
+		return source.Rule.PciDss
+	case entity.ComplianceFrameworkGDPR:
+		return source.Rule.Gdpr
+	case entity.ComplianceFrameworkHIPAA:
+		return source.Rule.Hipaa
+	case entity.ComplianceFrameworkNIST80053:
+		return source.Rule.Nist80053
+	case entity.ComplianceFrameworkTSC:
+		return source.Rule.Tsc
+	default:
+		return nil
+	}
+}
+
+// requirementLess orders requirement names naturally, so 10.2.4 comes before 10.2.10 and 11.4
+func requirementLess(a, b string) bool {
+	partsA, partsB := requirementParts(a), requirementParts(b)
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		if partsA[i] == partsB[i] {
+			continue
+		}
+		numberA, errA := strconv.Atoi(partsA[i])
+		numberB, errB := strconv.Atoi(partsB[i])
+		if errA == nil && errB == nil {
+			return numberA < numberB
+		}
+		return partsA[i] < partsB[i]
+	}
+	return len(partsA) < len(partsB)
+}
+
+// requirementParts splits a requirement name into runs of digits and of other characters
+func requirementParts(name string) []string {
+	var parts []string
+	start := 0
+	for i, r := range name {
+		if i > start && unicode.IsDigit(r) != unicode.IsDigit(rune(name[i-1])) {
+			parts = append(parts, name[start:i])
+			start = i
+		}
+	}
+	if start < len(name) {
+		parts = append(parts, name[start:])
+	}
+	return parts
+}
@@ -0,0 +1,843 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// cveImportBatchSize is the number of NVD records saved per transaction
+	cveImportBatchSize = 1000
+
+	// defaultVulnerabilityWindow and defaultVulnerabilityLimit apply when the request does not set them
+	defaultVulnerabilityWindow = 30 * 24 * time.Hour
+	defaultVulnerabilityLimit  = 50
+
+	// vulnerabilityBatchSize alerts are read per indexer query, at most maxVulnerabilityBatches times per report
+	vulnerabilityBatchSize  = 1000
+	maxVulnerabilityBatches = 10
+
+	// agentVulnerabilityPreview is the number of vulnerabilities listed per agent in the ranking
+	agentVulnerabilityPreview = 10
+
+	// vulnerabilityStatusSolved is the status of the alert Wazuh raises once a vulnerable package is fixed
+	vulnerabilityStatusSolved = "solved"
+)
+
+// nvdMetricKeys are the CVSS metric lists of an NVD record, newest CVSS version first
+var nvdMetricKeys = []string{"cvssMetricV40", "cvssMetricV31", "cvssMetricV30", "cvssMetricV2"}
+
+// nvdTimestampLayouts are the timestamp formats of NVD records, which carry no offset and are in UTC
+var nvdTimestampLayouts = []string{"2006-01-02T15:04:05.000", "2006-01-02T15:04:05", time.RFC3339Nano}
+
+var cveIDPattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+type cveUsecase struct {
+	cveRepo        domain.CVERepository
+	wazuhEventRepo domain.WazuhEventRepository
+
+	// mu serializes imports, so the scheduled import and an API call never write the same feed at once
+	mu sync.Mutex
+}
+
+// cveFeedFile is a feed file of the environment and its kind
+type cveFeedFile struct {
+	path string
+	kind string
+}
+
+// nvdRecord is the part of an NVD CVE API 2.0 vulnerability the importer reads
+type nvdRecord struct {
+	CVE struct {
+		ID           string `json:"id"`
+		Published    string `json:"published"`
+		LastModified string `json:"lastModified"`
+		Descriptions []struct {
+			Lang  string `json:"lang"`
+			Value string `json:"value"`
+		} `json:"descriptions"`
+		Metrics    map[string][]nvdMetric `json:"metrics"`
+		Weaknesses []struct {
+			Description []struct {
+				Lang  string `json:"lang"`
+				Value string `json:"value"`
+			} `json:"description"`
+		} `json:"weaknesses"`
+		References []struct {
+			URL    string   `json:"url"`
+			Source string   `json:"source"`
+			Tags   []string `json:"tags"`
+		} `json:"references"`
+	} `json:"cve"`
+}
+
+type nvdMetric struct {
+	Source   string `json:"source"`
+	Type     string `json:"type"`
+	CVSSData struct {
+		Version      string  `json:"version"`
+		VectorString string  `json:"vectorString"`
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"` // CVSS v2 keeps the severity next to the data
+}
+
+// kevCatalog is the CISA KEV catalog file
+type kevCatalog struct {
+	Vulnerabilities *[]struct {
+		CVEID                      string `json:"cveID"`
+		VendorProject              string `json:"vendorProject"`
+		Product                    string `json:"product"`
+		VulnerabilityName          string `json:"vulnerabilityName"`
+		DateAdded                  string `json:"dateAdded"`
+		RequiredAction             string `json:"requiredAction"`
+		DueDate                    string `json:"dueDate"`
+		KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+	} `json:"vulnerabilities"`
+}
+
+func NewCVEUsecase(cveRepo domain.CVERepository, wazuhEventRepo domain.WazuhEventRepository) domain.CVEUsecase {
+	return &cveUsecase{
+		cveRepo:        cveRepo,
+		wazuhEventRepo: wazuhEventRepo,
+	}
+}
+
+// ImportFeeds imports every configured feed file now, NVD files first so KEV and EPSS data land on complete
+// records. A file that fails keeps the data of its previous import and reports the error.
+func (u *cveUsecase) ImportFeeds(ctx context.Context) ([]*entity.CVEFeedImport, error) {
+	if len(cveFeedFiles()) == 0 {
+		return nil, fmt.Errorf("invalid import: none of CVE_NVD_FILES, CVE_KEV_FILE and CVE_EPSS_FILE is set")
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.importFeeds(ctx, true)
+}
+
+// RunScheduledImport imports the feed files that changed since their last import
+func (u *cveUsecase) RunScheduledImport(ctx context.Context) error {
+	if len(cveFeedFiles()) == 0 {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, err := u.importFeeds(ctx, false)
+	return err
+}
+
+func (u *cveUsecase) FetchFeedImports(ctx context.Context) ([]*entity.CVEFeedImport, error) {
+	imports, err := u.cveRepo.FetchFeedImports(ctx)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - cve - FetchFeedImports]: Failed to fetch feed imports")
+		return nil, err
+	}
+	if imports == nil {
+		imports = []*entity.CVEFeedImport{}
+	}
+	return imports, nil
+}
+
+func (u *cveUsecase) FetchCVEByID(ctx context.Context, id string) (*entity.CVE, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !cveIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid CVE ID %q, expected CVE-YYYY-NNNN", id)
+	}
+
+	cve, err := u.cveRepo.FetchCVEByID(ctx, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("cve_id", id).Error("[usecase - cve - FetchCVEByID]: Failed to fetch CVE")
+		return nil, err
+	}
+	if cve == nil {
+		return nil, fmt.Errorf("CVE %s not found", id)
+	}
+
+	cve.CVSS = preferredCVSSMetric(cve.CVSSMetrics)
+	return cve, nil
+}
+
+// EnrichHits returns the hits with a cve block, the CVSS scores, EPSS score, KEV entry and references of
+// data.vulnerability.cve, added to the source of each vulnerability alert whose CVE is known. The given
+// hits are not modified, and a failed lookup leaves them as they are.
+func (u *cveUsecase) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	ids := []string{}
+	seen := map[string]bool{}
+
+	for _, hit := range hits {
+		alert, ok := decodeAlertSource(hit.Source)
+		if !ok {
+			continue
+		}
+		if id := strings.ToUpper(alert.Data.Vulnerability.CVE); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return hits
+	}
+
+	cves, err := u.cveRepo.FetchCVEsByIDs(ctx, ids)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Warn("[usecase - cve - EnrichHits]: Failed to look up CVEs, leaving alerts unenriched")
+		return hits
+	}
+
+	enriched := make([]*elastic.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		alert, _ := decodeAlertSource(hit.Source)
+		cve, ok := cves[strings.ToUpper(alert.Data.Vulnerability.CVE)]
+		if !ok {
+			enriched = append(enriched, hit)
+			continue
+		}
+		cve.CVSS = preferredCVSSMetric(cve.CVSSMetrics)
+		enriched = append(enriched, withSourceField(hit, "cve", cve))
+	}
+
+	return enriched
+}
+
+// FetchAgentVulnerabilities lists the vulnerabilities each agent was last reported with in the window,
+// leaving out those a later alert reported solved, and ranks agents by KEV vulnerabilities, then by their
+// highest CVSS score
+func (u *cveUsecase) FetchAgentVulnerabilities(ctx context.Context, request *model.VulnerabilityReportRequest) (*entity.VulnerabilityReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	window := request.Window
+	if window <= 0 {
+		window = defaultVulnerabilityWindow
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultVulnerabilityLimit
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	// Newest first, so a truncated report leaves out the oldest alerts of the window
+	var hits []*elastic.SearchHit
+	var searchAfter []interface{}
+	full := false
+
+	for batch := 0; batch < maxVulnerabilityBatches; batch++ {
+		batchHits, err := u.wazuhEventRepo.FetchVulnerabilityAlertsSince(ctx, since, request.AgentID, searchAfter, vulnerabilityBatchSize)
+		if err != nil {
+			log.WithError(err).Error("[usecase - cve - FetchAgentVulnerabilities]: Failed to fetch vulnerability alerts")
+			return nil, err
+		}
+		hits = append(hits, batchHits...)
+
+		full = len(batchHits) == vulnerabilityBatchSize && len(batchHits[len(batchHits)-1].Sort) > 0
+		if !full {
+			break
+		}
+		searchAfter = batchHits[len(batchHits)-1].Sort
+	}
+
+	type agentState struct {
+		summary *entity.AgentVulnerabilitySummary
+		latest  map[string]*entity.AgentVulnerability
+		solved  map[string]bool
+	}
+	agents := map[string]*agentState{}
+	ids := []string{}
+	seenIDs := map[string]bool{}
+
+	for _, hit := range hits {
+		alert, ok := decodeAlertSource(hit.Source)
+		if !ok {
+			continue
+		}
+		id := strings.ToUpper(alert.Data.Vulnerability.CVE)
+		if id == "" || alert.Agent.ID == "" {
+			continue
+		}
+
+		firedAt, ok := parseAlertTimestamp(alert.Timestamp)
+		if !ok {
+			continue
+		}
+
+		state, ok := agents[alert.Agent.ID]
+		if !ok {
+			state = &agentState{
+				summary: &entity.AgentVulnerabilitySummary{AgentID: alert.Agent.ID, AgentName: alert.Agent.Name},
+				latest:  map[string]*entity.AgentVulnerability{},
+				solved:  map[string]bool{},
+			}
+			agents[alert.Agent.ID] = state
+		}
+
+		// The newest alert of a CVE on an agent decides whether it is still open
+		if previous, ok := state.latest[id]; ok && !firedAt.After(previous.LastSeen) {
+			continue
+		}
+		state.latest[id] = &entity.AgentVulnerability{
+			CVE:            id,
+			Package:        alert.Data.Vulnerability.Package.Name,
+			PackageVersion: alert.Data.Vulnerability.Package.Version,
+			Severity:       strings.ToLower(alert.Data.Vulnerability.Severity),
+			LastSeen:       firedAt.UTC(),
+		}
+		state.solved[id] = strings.EqualFold(alert.Data.Vulnerability.Status, vulnerabilityStatusSolved)
+
+		if !seenIDs[id] {
+			seenIDs[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	cves, err := u.cveRepo.FetchCVEsByIDs(ctx, ids)
+	if err != nil {
+		log.WithError(err).Error("[usecase - cve - FetchAgentVulnerabilities]: Failed to look up CVEs")
+		return nil, err
+	}
+
+	report := &entity.VulnerabilityReport{
+		Window:      window.String(),
+		Since:       since,
+		GeneratedAt: now,
+		Alerts:      len(hits),
+		Truncated:   full,
+		Agents:      []entity.AgentVulnerabilitySummary{},
+	}
+
+	for _, state := range agents {
+		summary := state.summary
+		summary.Vulnerabilities = []entity.AgentVulnerability{}
+
+		for id, vulnerability := range state.latest {
+			if state.solved[id] {
+				continue
+			}
+
+			if cve, ok := cves[id]; ok {
+				vulnerability.Known = true
+				vulnerability.KEV = cve.KEV != nil
+				vulnerability.EPSS = cve.EPSS
+				if metric := preferredCVSSMetric(cve.CVSSMetrics); metric != nil {
+					vulnerability.CVSSScore = metric.BaseScore
+					vulnerability.CVSSVector = metric.Vector
+					vulnerability.Severity = cvssSeverity(metric.BaseScore)
+				}
+			}
+
+			summary.CVEs++
+			if vulnerability.KEV {
+				summary.KEV++
+			}
+			if vulnerability.CVSSScore > summary.MaxCVSS {
+				summary.MaxCVSS = vulnerability.CVSSScore
+			}
+			switch vulnerability.Severity {
+			case "critical":
+				summary.Critical++
+			case "high":
+				summary.High++
+			case "medium":
+				summary.Medium++
+			case "low":
+				summary.Low++
+			}
+
+			summary.Vulnerabilities = append(summary.Vulnerabilities, *vulnerability)
+		}
+
+		if summary.CVEs == 0 {
+			continue
+		}
+
+		sortAgentVulnerabilities(summary.Vulnerabilities)
+		if request.AgentID == "" && len(summary.Vulnerabilities) > agentVulnerabilityPreview {
+			summary.Vulnerabilities = summary.Vulnerabilities[:agentVulnerabilityPreview]
+		}
+
+		report.Agents = append(report.Agents, *summary)
+	}
+
+	sort.Slice(report.Agents, func(i, j int) bool {
+		a, b := report.Agents[i], report.Agents[j]
+		if a.KEV != b.KEV {
+			return a.KEV > b.KEV
+		}
+		if a.MaxCVSS != b.MaxCVSS {
+			return a.MaxCVSS > b.MaxCVSS
+		}
+		if a.CVEs != b.CVEs {
+			return a.CVEs > b.CVEs
+		}
+		return a.AgentID < b.AgentID
+	})
+
+	if len(report.Agents) > limit {
+		report.Agents = report.Agents[:limit]
+	}
+
+	if request.AgentID != "" && len(report.Agents) == 0 {
+		return nil, fmt.Errorf("vulnerabilities of agent %s not found in the last %s", request.AgentID, window)
+	}
+
+	return report, nil
+}
+
+// importFeeds imports each feed file unless force is false and it is unchanged. Callers hold mu.
+func (u *cveUsecase) importFeeds(ctx context.Context, force bool) ([]*entity.CVEFeedImport, error) {
+	imports := []*entity.CVEFeedImport{}
+
+	for _, feed := range cveFeedFiles() {
+		feedImport, err := u.importFeed(ctx, feed, force)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, feedImport)
+	}
+
+	return imports, nil
+}
+
+// importFeed imports one feed file and records the outcome. A file that cannot be read or parsed is
+// recorded with its error; only a failure to record the outcome is returned.
+func (u *cveUsecase) importFeed(ctx context.Context, feed cveFeedFile, force bool) (*entity.CVEFeedImport, error) {
+	log := logger.WithRequestID(ctx).WithField("path", feed.path).WithField("kind", feed.kind)
+
+	previous, err := u.cveRepo.FetchFeedImport(ctx, feed.path)
+	if err != nil {
+		return nil, err
+	}
+
+	feedImport := &entity.CVEFeedImport{Path: feed.path, Kind: feed.kind}
+	if previous != nil {
+		feedImport.Records = previous.Records
+		feedImport.FileModTime = previous.FileModTime
+		feedImport.ImportedAt = previous.ImportedAt
+	}
+
+	info, err := os.Stat(feed.path)
+	if err == nil && !force && previous != nil && previous.Error == "" && previous.FileModTime != nil && info.ModTime().UTC().Equal(*previous.FileModTime) {
+		return feedImport, nil
+	}
+
+	records := 0
+	if err == nil {
+		records, err = u.importFile(ctx, feed)
+	}
+	if err != nil {
+		log.WithError(err).Warn("[usecase - cve - importFeed]: Failed to import feed file, keeping the previous data")
+		feedImport.Error = err.Error()
+		if saveErr := u.cveRepo.SaveFeedImport(ctx, feedImport); saveErr != nil {
+			return nil, saveErr
+		}
+		return feedImport, nil
+	}
+
+	modTime := info.ModTime().UTC()
+	importedAt := time.Now().UTC()
+	feedImport.Records = records
+	feedImport.FileModTime = &modTime
+	feedImport.ImportedAt = &importedAt
+	feedImport.Changed = true
+
+	if err := u.cveRepo.SaveFeedImport(ctx, feedImport); err != nil {
+		return nil, err
+	}
+
+	log.WithField("records", records).Info("[usecase - cve - importFeed]: Feed file imported")
+	return feedImport, nil
+}
+
+// importFile reads a feed file, gzip-compressed when it ends in .gz, and stores its records
+func (u *cveUsecase) importFile(ctx context.Context, feed cveFeedFile) (int, error) {
+	file, err := os.Open(feed.path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(strings.ToLower(feed.path), ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return 0, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	switch feed.kind {
+	case entity.CVEFeedNVD:
+		return u.importNVD(ctx, reader)
+	case entity.CVEFeedKEV:
+		entries, err := parseKEVCatalog(reader)
+		if err != nil {
+			return 0, err
+		}
+		return len(entries), u.cveRepo.ReplaceKEV(ctx, entries)
+	default:
+		scores, err := parseEPSSScores(reader)
+		if err != nil {
+			return 0, err
+		}
+		return len(scores), u.cveRepo.ReplaceEPSS(ctx, scores)
+	}
+}
+
+// importNVD streams the vulnerabilities of an NVD CVE API 2.0 file into the store in batches, so a yearly
+// feed is never held in memory whole
+func (u *cveUsecase) importNVD(ctx context.Context, reader io.Reader) (int, error) {
+	batch := make([]*entity.CVE, 0, cveImportBatchSize)
+	records := 0
+	now := time.Now().UTC()
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := u.cveRepo.SaveCVEs(ctx, batch); err != nil {
+			return err
+		}
+		records += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := decodeJSONArrayField(reader, "vulnerabilities", func(decoder *json.Decoder) error {
+		var record nvdRecord
+		if err := decoder.Decode(&record); err != nil {
+			return err
+		}
+		cve, err := newCVEFromNVD(&record)
+		if err != nil {
+			return err
+		}
+		cve.UpdatedAt = now
+
+		batch = append(batch, cve)
+		if len(batch) == cveImportBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return records, err
+	}
+
+	if err := flush(); err != nil {
+		return records, err
+	}
+	return records, nil
+}
+
+// cveFeedFiles returns the feed files of the environment in import order: CVE_NVD_FILES, a comma-separated
+// list, then CVE_KEV_FILE and CVE_EPSS_FILE
+func cveFeedFiles() []cveFeedFile {
+	var feeds []cveFeedFile
+	for _, path := range cleanList(strings.Split(os.Getenv("CVE_NVD_FILES"), ",")) {
+		feeds = append(feeds, cveFeedFile{path: path, kind: entity.CVEFeedNVD})
+	}
+	if path := strings.TrimSpace(os.Getenv("CVE_KEV_FILE")); path != "" {
+		feeds = append(feeds, cveFeedFile{path: path, kind: entity.CVEFeedKEV})
+	}
+	if path := strings.TrimSpace(os.Getenv("CVE_EPSS_FILE")); path != "" {
+		feeds = append(feeds, cveFeedFile{path: path, kind: entity.CVEFeedEPSS})
+	}
+	return feeds
+}
+
+// decodeJSONArrayField calls each once per element of the array under the given key of a JSON object,
+// with the decoder positioned at the element. Other keys are skipped.
+func decodeJSONArrayField(reader io.Reader, field string, each func(decoder *json.Decoder) error) error {
+	decoder := json.NewDecoder(reader)
+
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object")
+	}
+
+	found := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if key, _ := token.(string); key != field {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if token, err := decoder.Token(); err != nil {
+			return err
+		} else if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("%s is not an array", field)
+		}
+		for decoder.More() {
+			if err := each(decoder); err != nil {
+				return err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("no %s array in the file", field)
+	}
+	return nil
+}
+
+// newCVEFromNVD converts an NVD record, keeping the English description and every CVSS metric
+func newCVEFromNVD(record *nvdRecord) (*entity.CVE, error) {
+	id := strings.ToUpper(strings.TrimSpace(record.CVE.ID))
+	if !cveIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("record with invalid CVE ID %q", record.CVE.ID)
+	}
+
+	cve := &entity.CVE{
+		ID:          id,
+		CVSSMetrics: []entity.CVSSMetric{},
+		CWEs:        []string{},
+		References:  []entity.CVEReference{},
+	}
+
+	for _, description := range record.CVE.Descriptions {
+		if description.Lang == "en" {
+			cve.Description = description.Value
+			break
+		}
+	}
+
+	if published, ok := parseNVDTimestamp(record.CVE.Published); ok {
+		cve.Published = &published
+	}
+	if lastModified, ok := parseNVDTimestamp(record.CVE.LastModified); ok {
+		cve.LastModified = &lastModified
+	}
+
+	for _, key := range nvdMetricKeys {
+		for _, metric := range record.CVE.Metrics[key] {
+			severity := metric.CVSSData.BaseSeverity
+			if severity == "" {
+				severity = metric.BaseSeverity
+			}
+			cve.CVSSMetrics = append(cve.CVSSMetrics, entity.CVSSMetric{
+				Version:   metric.CVSSData.Version,
+				Vector:    metric.CVSSData.VectorString,
+				BaseScore: metric.CVSSData.BaseScore,
+				Severity:  strings.ToLower(severity),
+				Source:    metric.Source,
+				Type:      metric.Type,
+			})
+		}
+	}
+	cve.CVSS = preferredCVSSMetric(cve.CVSSMetrics)
+
+	for _, weakness := range record.CVE.Weaknesses {
+		for _, description := range weakness.Description {
+			if strings.HasPrefix(description.Value, "CWE-") && !containsString(cve.CWEs, description.Value) {
+				cve.CWEs = append(cve.CWEs, description.Value)
+			}
+		}
+	}
+
+	for _, reference := range record.CVE.References {
+		cve.References = append(cve.References, entity.CVEReference{
+			URL:    reference.URL,
+			Source: reference.Source,
+			Tags:   reference.Tags,
+		})
+	}
+
+	return cve, nil
+}
+
+// parseKEVCatalog reads the CISA KEV catalog by CVE ID
+func parseKEVCatalog(reader io.Reader) (map[string]*entity.KEVEntry, error) {
+	var catalog kevCatalog
+	if err := json.NewDecoder(reader).Decode(&catalog); err != nil {
+		return nil, err
+	}
+	if catalog.Vulnerabilities == nil {
+		return nil, fmt.Errorf("no vulnerabilities array in the file")
+	}
+
+	entries := map[string]*entity.KEVEntry{}
+	for _, vulnerability := range *catalog.Vulnerabilities {
+		id := strings.ToUpper(strings.TrimSpace(vulnerability.CVEID))
+		if !cveIDPattern.MatchString(id) {
+			continue
+		}
+		entries[id] = &entity.KEVEntry{
+			VendorProject:  vulnerability.VendorProject,
+			Product:        vulnerability.Product,
+			Name:           vulnerability.VulnerabilityName,
+			DateAdded:      vulnerability.DateAdded,
+			DueDate:        vulnerability.DueDate,
+			RequiredAction: vulnerability.RequiredAction,
+			Ransomware:     vulnerability.KnownRansomwareCampaignUse,
+		}
+	}
+
+	return entries, nil
+}
+
+// parseEPSSScores reads a FIRST EPSS scores CSV by CVE ID. The score date comes from the leading
+// #model_version comment line when the file has one.
+func parseEPSSScores(reader io.Reader) (map[string]*entity.EPSSScore, error) {
+	buffered := bufio.NewReader(reader)
+
+	date := ""
+	if first, err := buffered.Peek(1); err == nil && first[0] == '#' {
+		line, err := buffered.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		for _, part := range strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "#")), ",") {
+			if value, ok := strings.CutPrefix(part, "score_date:"); ok {
+				date = value
+				if len(date) >= len("2006-01-02") {
+					date = date[:len("2006-01-02")]
+				}
+			}
+		}
+	}
+
+	csvReader := csv.NewReader(buffered)
+	csvReader.Comment = '#'
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{"cve", "epss", "percentile"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("the header has no %s column", column)
+		}
+	}
+
+	scores := map[string]*entity.EPSSScore{}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id := strings.ToUpper(strings.TrimSpace(record[columns["cve"]]))
+		score, scoreErr := strconv.ParseFloat(strings.TrimSpace(record[columns["epss"]]), 64)
+		percentile, percentileErr := strconv.ParseFloat(strings.TrimSpace(record[columns["percentile"]]), 64)
+		if !cveIDPattern.MatchString(id) || scoreErr != nil || percentileErr != nil {
+			line, _ := csvReader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: invalid EPSS score", line)
+		}
+
+		scores[id] = &entity.EPSSScore{Score: score, Percentile: percentile, Date: date}
+	}
+
+	return scores, nil
+}
+
+// preferredCVSSMetric returns the metric of the newest CVSS version, preferring the primary scorer, or nil
+// when the CVE has none. Metrics are stored newest version first.
+func preferredCVSSMetric(metrics []entity.CVSSMetric) *entity.CVSSMetric {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	for i := range metrics {
+		if metrics[i].Version != metrics[0].Version {
+			break
+		}
+		if metrics[i].Type == "Primary" {
+			return &metrics[i]
+		}
+	}
+	return &metrics[0]
+}
+
+// cvssSeverity rates a CVSS v3 base score
+func cvssSeverity(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "critical"
+	case score >= 7.0:
+		return "high"
+	case score >= 4.0:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return "none"
+	}
+}
+
+// sortAgentVulnerabilities orders KEV vulnerabilities first, then by CVSS and EPSS score
+func sortAgentVulnerabilities(vulnerabilities []entity.AgentVulnerability) {
+	epss := func(vulnerability entity.AgentVulnerability) float64 {
+		if vulnerability.EPSS == nil {
+			return 0
+		}
+		return vulnerability.EPSS.Score
+	}
+
+	sort.Slice(vulnerabilities, func(i, j int) bool {
+		a, b := vulnerabilities[i], vulnerabilities[j]
+		if a.KEV != b.KEV {
+			return a.KEV
+		}
+		if a.CVSSScore != b.CVSSScore {
+			return a.CVSSScore > b.CVSSScore
+		}
+		if epss(a) != epss(b) {
+			return epss(a) > epss(b)
+		}
+		return a.CVE < b.CVE
+	})
+}
+
+// parseNVDTimestamp parses an NVD timestamp, which is in UTC without an offset
+func parseNVDTimestamp(value string) (time.Time, bool) {
+	for _, layout := range nvdTimestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,359 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultEvaluationWindow is used when no window is requested
+	defaultEvaluationWindow = 30 * 24 * time.Hour
+
+	// defaultDecisionSampleSize and maxDecisionSampleSize bound the decisions handed out for labelling
+	defaultDecisionSampleSize = 20
+	maxDecisionSampleSize     = 200
+
+	// criterionAll names the auto-close criterion without a level filter
+	criterionAll = "all"
+)
+
+type evaluationUsecase struct {
+	decisionRepo    domain.AutoCloseDecisionRepository
+	closedEventRepo domain.ClosedEventRepository
+}
+
+func NewEvaluationUsecase(
+	decisionRepo domain.AutoCloseDecisionRepository,
+	closedEventRepo domain.ClosedEventRepository,
+) domain.EvaluationUsecase {
+	return &evaluationUsecase{
+		decisionRepo:    decisionRepo,
+		closedEventRepo: closedEventRepo,
+	}
+}
+
+// evaluationAccumulator counts the confusion matrix of one bucket
+type evaluationAccumulator struct {
+	decisions int
+	labeled   int
+	correct   int
+	attacks   int
+	missed    int
+}
+
+// FetchEvaluation scores the auto-close decisions of the window against analyst labels, overall, per
+// criterion and per rule. Only labeled decisions count towards precision and recall.
+func (u *evaluationUsecase) FetchEvaluation(ctx context.Context, window time.Duration, mode string) (*entity.EvaluationReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	if mode != "" && !isValidAutoCloseMode(mode) {
+		return nil, fmt.Errorf("invalid mode %q: must be %s or %s", mode, entity.AutoCloseModeEnforce, entity.AutoCloseModeShadow)
+	}
+	if window <= 0 {
+		window = defaultEvaluationWindow
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	decisions, err := u.decisionRepo.FetchDecisionsSince(ctx, since, mode)
+	if err != nil {
+		log.WithError(err).Error("[usecase - evaluation - FetchEvaluation]: Failed to fetch auto-close decisions")
+		return nil, err
+	}
+
+	missedClosures, err := u.decisionRepo.FetchUndecidedFalsePositiveClosuresSince(ctx, since, mode)
+	if err != nil {
+		log.WithError(err).Error("[usecase - evaluation - FetchEvaluation]: Failed to fetch missed closures")
+		return nil, err
+	}
+
+	overall := &evaluationAccumulator{}
+	byCriterion := map[string]*evaluationAccumulator{}
+	byRule := map[string]*evaluationAccumulator{}
+	criterionFilters := map[string]string{}
+
+	for _, decision := range decisions {
+		criterionFilters[decision.Criterion] = decision.CriterionFilter
+
+		for _, acc := range []*evaluationAccumulator{
+			overall,
+			evaluationBucketFor(byCriterion, decision.Criterion),
+			evaluationBucketFor(byRule, decision.RuleID),
+		} {
+			acc.add(decision.Label)
+		}
+	}
+
+	// A missed closure counts against every criterion whose filter would have matched the alert,
+	// since that criterion could have closed it
+	for _, closedEvent := range missedClosures {
+		overall.missed++
+		evaluationBucketFor(byRule, closedEvent.RuleID).missed++
+
+		level, ok := alertRuleLevel(closedEvent.RawEvent)
+		if !ok {
+			continue
+		}
+		for criterion, filter := range criterionFilters {
+			if criterionMatches(filter, level) {
+				byCriterion[criterion].missed++
+			}
+		}
+	}
+
+	return &entity.EvaluationReport{
+		Window:      window.String(),
+		Since:       since,
+		GeneratedAt: now,
+		Mode:        mode,
+		Overall:     overall.metrics("overall"),
+		ByCriterion: evaluationBuckets(byCriterion),
+		ByRule:      evaluationBuckets(byRule),
+	}, nil
+}
+
+// FetchDecisionSample returns a random sample of unlabeled decisions for analysts to review
+func (u *evaluationUsecase) FetchDecisionSample(ctx context.Context, request *model.DecisionSampleRequest) ([]*entity.AutoCloseDecision, error) {
+	log := logger.WithRequestID(ctx)
+
+	if request.Mode != "" && !isValidAutoCloseMode(request.Mode) {
+		return nil, fmt.Errorf("invalid mode %q: must be %s or %s", request.Mode, entity.AutoCloseModeEnforce, entity.AutoCloseModeShadow)
+	}
+	if request.Size <= 0 {
+		request.Size = defaultDecisionSampleSize
+	}
+	if request.Size > maxDecisionSampleSize {
+		request.Size = maxDecisionSampleSize
+	}
+	if request.Window <= 0 {
+		request.Window = defaultEvaluationWindow
+	}
+
+	decisions, err := u.decisionRepo.FetchUnlabeledDecisionSample(ctx, request, time.Now().Add(-request.Window))
+	if err != nil {
+		log.WithError(err).Error("[usecase - evaluation - FetchDecisionSample]: Failed to sample decisions")
+		return nil, err
+	}
+
+	if decisions == nil {
+		decisions = []*entity.AutoCloseDecision{}
+	}
+	return decisions, nil
+}
+
+// LabelDecision records the analyst verdict on a decision. The label of an enforced decision is copied to the
+// closed event it produced, so closure analytics agree with the evaluation.
+func (u *evaluationUsecase) LabelDecision(ctx context.Context, id int, request *model.LabelDecisionRequest) (*entity.AutoCloseDecision, error) {
+	log := logger.WithRequestID(ctx)
+
+	if request.Label == "" || !entity.IsValidLabel(request.Label) {
+		return nil, fmt.Errorf("invalid label %q: must be %s or %s", request.Label, entity.LabelFalsePositive, entity.LabelTruePositive)
+	}
+	analyst := strings.TrimSpace(request.Analyst)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid label: analyst is required")
+	}
+
+	decision, err := u.decisionRepo.FetchDecisionByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("decision_id", id).Error("[usecase - evaluation - LabelDecision]: Failed to fetch decision")
+		return nil, err
+	}
+	if decision == nil {
+		return nil, fmt.Errorf("decision with ID %d not found", id)
+	}
+
+	if err := u.decisionRepo.UpdateDecisionLabel(ctx, id, request.Label, analyst, time.Now()); err != nil {
+		log.WithError(err).WithField("decision_id", id).Error("[usecase - evaluation - LabelDecision]: Failed to update decision label")
+		return nil, err
+	}
+
+	if decision.ClosedEventID != 0 {
+		if err := u.closedEventRepo.UpdateClosedEventLabel(ctx, strconv.Itoa(decision.ClosedEventID), request.Label); err != nil {
+			log.WithError(err).WithField("closed_event_id", decision.ClosedEventID).Warn("[usecase - evaluation - LabelDecision]: Failed to copy label to closed event")
+		}
+	}
+
+	log.WithField("decision_id", id).WithField("label", request.Label).Info("[usecase - evaluation - LabelDecision]: Successfully labeled decision")
+	return u.decisionRepo.FetchDecisionByID(ctx, id)
+}
+
+func (a *evaluationAccumulator) add(label string) {
+	a.decisions++
+
+	switch label {
+	case entity.LabelFalsePositive:
+		a.labeled++
+		a.correct++
+	case entity.LabelTruePositive:
+		a.labeled++
+		a.attacks++
+	}
+}
+
+func (a *evaluationAccumulator) metrics(key string) entity.EvaluationMetrics {
+	metrics := entity.EvaluationMetrics{
+		Key:             key,
+		Decisions:       a.decisions,
+		Labeled:         a.labeled,
+		CorrectClosures: a.correct,
+		AttacksClosed:   a.attacks,
+		MissedClosures:  a.missed,
+		Precision:       evaluationRatio(a.correct, a.correct+a.attacks),
+		Recall:          evaluationRatio(a.correct, a.correct+a.missed),
+	}
+
+	if metrics.Precision != nil && metrics.Recall != nil && *metrics.Precision+*metrics.Recall > 0 {
+		f1 := math.Round(2**metrics.Precision**metrics.Recall/(*metrics.Precision+*metrics.Recall)*10000) / 10000
+		metrics.F1 = &f1
+	}
+
+	return metrics
+}
+
+// evaluationRatio returns nil when there is nothing to divide by, so an unlabeled bucket is not reported as 0
+func evaluationRatio(numerator int, denominator int) *float64 {
+	if denominator == 0 {
+		return nil
+	}
+	ratio := math.Round(float64(numerator)/float64(denominator)*10000) / 10000
+	return &ratio
+}
+
+func evaluationBucketFor(buckets map[string]*evaluationAccumulator, key string) *evaluationAccumulator {
+	if _, ok := buckets[key]; !ok {
+		buckets[key] = &evaluationAccumulator{}
+	}
+	return buckets[key]
+}
+
+// evaluationBuckets returns the buckets with the most decisions first
+func evaluationBuckets(accumulators map[string]*evaluationAccumulator) []entity.EvaluationMetrics {
+	buckets := make([]entity.EvaluationMetrics, 0, len(accumulators))
+	for key, acc := range accumulators {
+		buckets = append(buckets, acc.metrics(key))
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Decisions != buckets[j].Decisions {
+			return buckets[i].Decisions > buckets[j].Decisions
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+// resolveAutoCloseMode falls back to AUTO_CLOSE_MODE, then enforce, when no mode is requested
+func resolveAutoCloseMode(mode string) (string, error) {
+	if mode == "" {
+		mode = os.Getenv("AUTO_CLOSE_MODE")
+	}
+	if mode == "" {
+		return entity.AutoCloseModeEnforce, nil
+	}
+
+	if !isValidAutoCloseMode(mode) {
+		return "", fmt.Errorf("invalid auto_close_mode %q: must be %s or %s", mode, entity.AutoCloseModeEnforce, entity.AutoCloseModeShadow)
+	}
+	return mode, nil
+}
+
+func isValidAutoCloseMode(mode string) bool {
+	return mode == entity.AutoCloseModeEnforce || mode == entity.AutoCloseModeShadow
+}
+
+// autoCloseCriterion returns the canonical name of a level filter and asset condition, such as
+// level>=3,level<7,asset.criticality=low, and both as JSON
+func autoCloseCriterion(levelRange *model.RangeQuery, asset *model.AssetCondition) (string, string) {
+	if levelRange == nil && asset == nil {
+		return criterionAll, "{}"
+	}
+
+	var parts []string
+	if levelRange != nil {
+		for _, bound := range []struct {
+			operator string
+			value    interface{}
+		}{
+			{">=", levelRange.Gte},
+			{">", levelRange.Gt},
+			{"<=", levelRange.Lte},
+			{"<", levelRange.Lt},
+		} {
+			if bound.value != nil {
+				parts = append(parts, "level"+bound.operator+fmt.Sprint(bound.value))
+			}
+		}
+	}
+
+	if asset != nil {
+		for _, condition := range []struct {
+			field  string
+			values []string
+		}{
+			{"criticality", asset.Criticalities},
+			{"environment", asset.Environments},
+			{"tags", asset.Tags},
+		} {
+			if len(condition.values) > 0 {
+				parts = append(parts, "asset."+condition.field+"="+strings.Join(condition.values, "|"))
+			}
+		}
+	}
+
+	// The level bounds stay at the top level of the filter, where criterionMatches reads them
+	filter, err := json.Marshal(struct {
+		*model.RangeQuery
+		Asset *model.AssetCondition `json:"asset,omitempty"`
+	}{levelRange, asset})
+	if err != nil {
+		filter = []byte("{}")
+	}
+
+	if len(parts) == 0 {
+		return criterionAll, string(filter)
+	}
+	return strings.Join(parts, ","), string(filter)
+}
+
+// criterionMatches replays a stored criterion filter against a rule level. Asset conditions are not replayed,
+// since the asset an alert had when it was closed is not known.
+func criterionMatches(filter string, level int) bool {
+	var levelRange model.RangeQuery
+	if err := json.Unmarshal([]byte(filter), &levelRange); err != nil {
+		return false
+	}
+
+	value := float64(level)
+	for _, bound := range []struct {
+		limit   interface{}
+		matches func(limit float64) bool
+	}{
+		{levelRange.Gte, func(limit float64) bool { return value >= limit }},
+		{levelRange.Gt, func(limit float64) bool { return value > limit }},
+		{levelRange.Lte, func(limit float64) bool { return value <= limit }},
+		{levelRange.Lt, func(limit float64) bool { return value < limit }},
+	} {
+		if bound.limit == nil {
+			continue
+		}
+		limit, err := strconv.ParseFloat(fmt.Sprint(bound.limit), 64)
+		if err != nil || !bound.matches(limit) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -8,47 +8,92 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/olivere/elastic/v7"
 )
 
 type eventUsecase struct {
-	wazuhEventRepo  domain.WazuhEventRepository
-	closedEventRepo domain.ClosedEventRepository
-	ruleRepo        domain.RuleRepository
+	wazuhEventRepo   domain.WazuhEventRepository
+	closedEventRepo  domain.ClosedEventRepository
+	ruleRepo         domain.RuleRepository
+	triageActionRepo domain.TriageActionRepository
+	decisionRepo     domain.AutoCloseDecisionRepository
+	guardrailUsecase domain.GuardrailUsecase
+	assetUsecase     domain.AssetUsecase
+	geoIPUsecase     domain.GeoIPUsecase
+	iocUsecase       domain.IOCUsecase
+	cveUsecase       domain.CVEUsecase
+	mitreUsecase     domain.MitreUsecase
 }
 
 func NewEventUsecase(
 	wazuhEventRepo domain.WazuhEventRepository,
 	closedEventRepo domain.ClosedEventRepository,
 	ruleRepo domain.RuleRepository,
+	triageActionRepo domain.TriageActionRepository,
+	decisionRepo domain.AutoCloseDecisionRepository,
+	guardrailUsecase domain.GuardrailUsecase,
+	assetUsecase domain.AssetUsecase,
+	geoIPUsecase domain.GeoIPUsecase,
+	iocUsecase domain.IOCUsecase,
+	cveUsecase domain.CVEUsecase,
+	mitreUsecase domain.MitreUsecase,
 ) domain.EventUsecase {
 	return &eventUsecase{
-		wazuhEventRepo:  wazuhEventRepo,
-		closedEventRepo: closedEventRepo,
-		ruleRepo:        ruleRepo,
+		wazuhEventRepo:   wazuhEventRepo,
+		closedEventRepo:  closedEventRepo,
+		ruleRepo:         ruleRepo,
+		triageActionRepo: triageActionRepo,
+		decisionRepo:     decisionRepo,
+		guardrailUsecase: guardrailUsecase,
+		assetUsecase:     assetUsecase,
+		geoIPUsecase:     geoIPUsecase,
+		iocUsecase:       iocUsecase,
+		cveUsecase:       cveUsecase,
+		mitreUsecase:     mitreUsecase,
 	}
 }
 
+// FetchEvents returns the matching events, each enriched with the location of its source address and the
+// inventory asset of its agent
 func (u *eventUsecase) FetchEvents(ctx context.Context, filter *model.FetchEventsRequest) (searchResults []*elastic.SearchHit, err error) {
-	return u.wazuhEventRepo.FetchSecurityEvents(ctx, filter)
+	searchResults, err = u.wazuhEventRepo.FetchSecurityEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return u.enrichHits(ctx, searchResults), nil
 }
 
 func (u *eventUsecase) FetchEventsWithAutoClose(ctx context.Context, filter *model.FetchEventsRequest) (searchResults []*elastic.SearchHit, err error) {
 	log := logger.WithRequestID(ctx)
 
+	mode, err := resolveAutoCloseMode(filter.AutoCloseMode)
+	if err != nil {
+		return nil, err
+	}
+	filter.AutoCloseMode = mode
+
+	if err := validateAssetCondition(filter.Asset); err != nil {
+		return nil, err
+	}
+
+	criterion, criterionFilter := autoCloseCriterion(filter.LevelRange, filter.Asset)
+
 	// First, fetch the events
 	searchResults, err = u.wazuhEventRepo.FetchSecurityEvents(ctx, filter)
 	if err != nil {
 		log.WithError(err).Error("[usecase - event - FetchEventsWithAutoClose]: Failed to fetch security events")
 		return nil, err
 	}
+	searchResults = u.enrichHits(ctx, searchResults)
 
 	// If autoAddToClose is enabled, process each event
 	if filter.AutoAddToClose {
 		successCount := 0
 		skipCount := 0
+		blockedCount := 0
 
 		for _, hit := range searchResults {
 			// Extract event ID from the hit
@@ -66,9 +111,21 @@ func (u *eventUsecase) FetchEventsWithAutoClose(ctx context.Context, filter *mod
 				skipCount++
 				continue
 			}
+			if securityEvent.Rule == nil {
+				log.WithField("event_id", eventRawID).Warn("[usecase - event - FetchEventsWithAutoClose]: Event has no rule, skipping auto-close")
+				skipCount++
+				continue
+			}
 
 			eventID := string(securityEvent.ID)
 
+			// Events outside the asset condition are left for analysts
+			if filter.Asset != nil && !assetMatchesCondition(u.assetUsecase.MatchAlert(ctx, hit.Source), filter.Asset) {
+				log.WithField("event_id", eventID).Debug("[usecase - event - FetchEventsWithAutoClose]: Event asset does not match the condition, skipping auto-close")
+				skipCount++
+				continue
+			}
+
 			// Check if the event is already closed
 			existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
 			if err != nil {
@@ -77,27 +134,71 @@ func (u *eventUsecase) FetchEventsWithAutoClose(ctx context.Context, filter *mod
 				continue
 			}
 
+			// Convert search hit to JSON string for storage
+			hitJSON, err := json.Marshal(hit)
+			if err != nil {
+				log.WithError(err).WithField("event_id", eventID).Warn("[usecase - event - FetchEventsWithAutoClose]: Failed to marshal hit to JSON, skipping auto-close")
+				skipCount++
+				continue
+			}
+
+			decision := &entity.AutoCloseDecision{
+				EventID:         eventID,
+				RuleID:          securityEvent.Rule.ID,
+				RuleLevel:       securityEvent.Rule.Level,
+				Criterion:       criterion,
+				CriterionFilter: criterionFilter,
+				Mode:            mode,
+				RawEvent:        string(hitJSON),
+				DecidedAt:       time.Now(),
+			}
+
+			// Shadow mode only records what would have been closed, including events analysts already closed,
+			// so their labels score the decision
+			if mode == entity.AutoCloseModeShadow {
+				u.recordDecision(ctx, decision)
+				successCount++
+				continue
+			}
+
 			if existingClosedEvent != nil {
 				log.WithField("event_id", eventID).WithField("existing_closed_id", existingClosedEvent.ID).Debug("[usecase - event - FetchEventsWithAutoClose]: Event already closed, skipping")
 				skipCount++
 				continue
 			}
 
-			// Convert search hit to JSON string for storage
-			hitJSON, err := json.Marshal(hit)
+			// An event auto-close already decided on was reopened, e.g. overturned in QA review, and stays open
+			previousDecision, err := u.decisionRepo.FetchDecisionByEventID(ctx, eventID, entity.AutoCloseModeEnforce)
 			if err != nil {
-				log.WithError(err).WithField("event_id", eventID).Warn("[usecase - event - FetchEventsWithAutoClose]: Failed to marshal hit to JSON, skipping auto-close")
+				log.WithError(err).WithField("event_id", eventID).Warn("[usecase - event - FetchEventsWithAutoClose]: Failed to check previous decision, skipping auto-close")
+				skipCount++
+				continue
+			}
+			if previousDecision != nil {
+				log.WithField("event_id", eventID).WithField("decision_id", previousDecision.ID).Debug("[usecase - event - FetchEventsWithAutoClose]: Event was reopened after auto-close, skipping")
 				skipCount++
 				continue
 			}
 
+			trip, err := u.guardrailUsecase.CheckAutoClose(ctx, eventID, securityEvent.Rule, hit.Source)
+			if err != nil {
+				log.WithError(err).WithField("event_id", eventID).Error("[usecase - event - FetchEventsWithAutoClose]: Failed to check guardrails, skipping auto-close")
+				skipCount++
+				continue
+			}
+			if trip != nil {
+				blockedCount++
+				continue
+			}
+
 			// Create closed event record
 			closedEvent := &entity.ClosedEvent{
-				EventID:  eventID,
-				RuleID:   securityEvent.Rule.ID,
-				RawEvent: string(hitJSON),
-				Status:   "closed",
-				CloseAt:  time.Now(),
+				EventID:   eventID,
+				RuleID:    securityEvent.Rule.ID,
+				RawEvent:  string(hitJSON),
+				Status:    "closed",
+				CloseType: entity.CloseTypeAuto,
+				CloseAt:   time.Now(),
 			}
 
 			// Save to closed events database
@@ -107,19 +208,28 @@ func (u *eventUsecase) FetchEventsWithAutoClose(ctx context.Context, filter *mod
 				continue
 			}
 
+			u.recordTriageAction(ctx, closedEvent, entity.TriageActionClosed, entity.ActorAutoClose)
+
+			decision.ClosedEventID = closedEvent.ID
+			u.recordDecision(ctx, decision)
+
 			log.WithField("event_id", eventID).Debug("[usecase - event - FetchEventsWithAutoClose]: Successfully auto-closed event")
 			successCount++
 		}
 
-		log.WithField("processed_events", len(searchResults)).WithField("success_count", successCount).WithField("skip_count", skipCount).Info("[usecase - event - FetchEventsWithAutoClose]: Completed auto-closing process")
+		log.WithField("processed_events", len(searchResults)).WithField("success_count", successCount).WithField("skip_count", skipCount).WithField("blocked_count", blockedCount).WithField("mode", mode).Info("[usecase - event - FetchEventsWithAutoClose]: Completed auto-closing process")
 	}
 
 	return searchResults, nil
 }
 
-func (u *eventUsecase) AddEventToCloseEvent(ctx context.Context, eventID string, reason string) error {
+func (u *eventUsecase) AddEventToCloseEvent(ctx context.Context, eventID string, request *model.CloseEventRequest) error {
 	log := logger.WithRequestID(ctx)
 
+	if !entity.IsValidLabel(request.Label) {
+		return fmt.Errorf("invalid label %q: must be %s or %s", request.Label, entity.LabelFalsePositive, entity.LabelTruePositive)
+	}
+
 	// Check if the event is already closed
 	existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
 	if err != nil {
@@ -137,6 +247,7 @@ func (u *eventUsecase) AddEventToCloseEvent(ctx context.Context, eventID string,
 		log.WithError(err).Error("[usecase - event - AddEventToCloseEvent]: Failed to fetch security event by ID")
 		return err
 	}
+	resultElastic = u.enrichAlertHits(ctx, []*elastic.SearchHit{resultElastic})[0]
 
 	// Convert elastic search result to JSON string
 	resultElasticJSON, err := json.Marshal(resultElastic)
@@ -146,19 +257,148 @@ func (u *eventUsecase) AddEventToCloseEvent(ctx context.Context, eventID string,
 	}
 
 	closedEvent := &entity.ClosedEvent{
-		EventID:  eventID,
-		RuleID:   securityEvent.Rule.ID,     // This would be fetched from the actual event
-		RawEvent: string(resultElasticJSON), // This would be the full event JSON
-		Reason:   reason,
-		Status:   "closed",
-		CloseAt:  time.Now(),
+		EventID:   eventID,
+		RuleID:    securityEvent.Rule.ID,     // This would be fetched from the actual event
+		RawEvent:  string(resultElasticJSON), // This would be the full event JSON
+		Reason:    request.Reason,
+		Status:    "closed",
+		CloseType: entity.CloseTypeManual,
+		Label:     request.Label,
+		CloseAt:   time.Now(),
 	}
 
-	return u.closedEventRepo.SaveClosedEvent(ctx, closedEvent)
+	if err := u.closedEventRepo.SaveClosedEvent(ctx, closedEvent); err != nil {
+		return err
+	}
+
+	actor := strings.TrimSpace(request.Analyst)
+	if actor == "" {
+		actor = entity.ActorUnknown
+	}
+	u.recordTriageAction(ctx, closedEvent, entity.TriageActionClosed, actor)
+
+	return nil
+}
+
+// AcknowledgeEvent records that an analyst started triaging an open event, which ends its time-to-triage
+func (u *eventUsecase) AcknowledgeEvent(ctx context.Context, eventID string, analyst string) (*entity.TriageAction, error) {
+	log := logger.WithRequestID(ctx)
+
+	analyst = strings.TrimSpace(analyst)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid acknowledgement: analyst is required")
+	}
+
+	existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).Error("[usecase - event - AcknowledgeEvent]: Failed to check existing closed event")
+		return nil, err
+	}
+	if existingClosedEvent != nil {
+		return nil, fmt.Errorf("event with ID %s is already closed (closed event ID: %d)", eventID, existingClosedEvent.ID)
+	}
+
+	actions, err := u.triageActionRepo.FetchTriageActionsByEventID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).Error("[usecase - event - AcknowledgeEvent]: Failed to fetch triage actions")
+		return nil, err
+	}
+	for _, action := range actions {
+		if action.Action == entity.TriageActionAcknowledged {
+			return nil, fmt.Errorf("event with ID %s is already acknowledged by %s", eventID, action.Actor)
+		}
+	}
+
+	securityEvent, resultElastic, err := u.wazuhEventRepo.FetchSecurityEventByID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).Error("[usecase - event - AcknowledgeEvent]: Failed to fetch security event by ID")
+		return nil, err
+	}
+
+	hitJSON, err := json.Marshal(resultElastic)
+	if err != nil {
+		return nil, err
+	}
+
+	action := newTriageAction(eventID, securityEvent.Rule.ID, string(hitJSON), entity.TriageActionAcknowledged, analyst)
+	if err := u.triageActionRepo.SaveTriageAction(ctx, action); err != nil {
+		log.WithError(err).Error("[usecase - event - AcknowledgeEvent]: Failed to save triage action")
+		return nil, err
+	}
+
+	log.WithField("event_id", eventID).WithField("analyst", analyst).Info("[usecase - event - AcknowledgeEvent]: Event acknowledged")
+	return action, nil
+}
+
+// recordTriageAction stores the transition of a closed event. The closure itself already succeeded,
+// so a failure only costs KPI accuracy and is logged instead of returned.
+func (u *eventUsecase) recordTriageAction(ctx context.Context, closedEvent *entity.ClosedEvent, action string, actor string) {
+	triageAction := newTriageAction(closedEvent.EventID, closedEvent.RuleID, closedEvent.RawEvent, action, actor)
+	triageAction.CreatedAt = closedEvent.CloseAt
+
+	if err := u.triageActionRepo.SaveTriageAction(ctx, triageAction); err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("event_id", closedEvent.EventID).Warn("[usecase - event - recordTriageAction]: Failed to record triage action")
+	}
+}
+
+// recordDecision stores an auto-close decision for evaluation. Failures are logged so they never block triage.
+func (u *eventUsecase) recordDecision(ctx context.Context, decision *entity.AutoCloseDecision) {
+	if _, err := u.decisionRepo.SaveDecision(ctx, decision); err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("event_id", decision.EventID).Warn("[usecase - event - recordDecision]: Failed to record auto-close decision")
+	}
+}
+
+// enrichHits adds the geoip, ioc, cve, mitre and asset blocks to the hits
+func (u *eventUsecase) enrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	return u.assetUsecase.EnrichHits(ctx, u.enrichAlertHits(ctx, hits))
+}
+
+// enrichAlertHits adds the blocks stored with a closed event: geoip, ioc, cve and mitre. The asset is
+// attached when the closed event is read, so it is always the current one.
+func (u *eventUsecase) enrichAlertHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	hits = u.iocUsecase.EnrichHits(ctx, u.geoIPUsecase.EnrichHits(ctx, hits))
+	return u.mitreUsecase.EnrichHits(ctx, u.cveUsecase.EnrichHits(ctx, hits))
+}
+
+// attachAsset sets the current inventory asset of the agent behind a closed event
+func (u *eventUsecase) attachAsset(ctx context.Context, closedEvent *entity.ClosedEvent) {
+	var hit struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := json.Unmarshal([]byte(closedEvent.RawEvent), &hit); err != nil || len(hit.Source) == 0 {
+		return
+	}
+	closedEvent.Asset = u.assetUsecase.MatchAlert(ctx, hit.Source)
+}
+
+func newTriageAction(eventID string, ruleID string, rawEvent string, action string, actor string) *entity.TriageAction {
+	triageAction := &entity.TriageAction{
+		EventID:   eventID,
+		RuleID:    ruleID,
+		AgentID:   alertAgentID(rawEvent),
+		AgentName: alertAgentName(rawEvent),
+		Action:    action,
+		Actor:     actor,
+		CreatedAt: time.Now(),
+	}
+
+	if firedAt, ok := alertTimestamp(rawEvent); ok {
+		triageAction.AlertAt = &firedAt
+	}
+
+	return triageAction
 }
 
 func (u *eventUsecase) FetchClosedEvents(ctx context.Context) ([]*entity.ClosedEvent, error) {
-	return u.closedEventRepo.FetchClosedEvents(ctx)
+	closedEvents, err := u.closedEventRepo.FetchClosedEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, closedEvent := range closedEvents {
+		u.attachAsset(ctx, closedEvent)
+	}
+	return closedEvents, nil
 }
 
 func (u *eventUsecase) FetchClosedEventDetailsByID(ctx context.Context, id string) (*entity.ClosedEvent, *entity.WazuhRule, []entity.WazuhRule, error) {
@@ -175,6 +415,7 @@ func (u *eventUsecase) FetchClosedEventDetailsByID(ctx context.Context, id strin
 		log.WithField("id", id).Warn("[usecase - event - FetchClosedEventDetailsByID]: Closed event not found")
 		return nil, nil, nil, nil
 	}
+	u.attachAsset(ctx, closedEvent)
 
 	// Get rule details if rule_id is available
 	var ruleDetail *entity.WazuhRule
@@ -231,3 +472,30 @@ func (u *eventUsecase) UpdateClosedEventReason(ctx context.Context, id string, r
 	log.WithField("id", id).WithField("reason", reason).Info("[usecase - event - UpdateClosedEventReason]: Successfully updated closed event reason")
 	return nil
 }
+
+func (u *eventUsecase) UpdateClosedEventLabel(ctx context.Context, id string, label string) error {
+	log := logger.WithRequestID(ctx)
+
+	if !entity.IsValidLabel(label) {
+		return fmt.Errorf("invalid label %q: must be %s or %s", label, entity.LabelFalsePositive, entity.LabelTruePositive)
+	}
+
+	closedEvent, err := u.closedEventRepo.FetchClosedEventByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("id", id).Error("[usecase - event - UpdateClosedEventLabel]: Failed to fetch closed event by ID")
+		return err
+	}
+
+	if closedEvent == nil {
+		log.WithField("id", id).Warn("[usecase - event - UpdateClosedEventLabel]: Closed event not found")
+		return fmt.Errorf("closed event with ID %s not found", id)
+	}
+
+	if err := u.closedEventRepo.UpdateClosedEventLabel(ctx, id, label); err != nil {
+		log.WithError(err).WithField("id", id).Error("[usecase - event - UpdateClosedEventLabel]: Failed to update closed event label")
+		return err
+	}
+
+	log.WithField("id", id).WithField("label", label).Info("[usecase - event - UpdateClosedEventLabel]: Successfully updated closed event label")
+	return nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// searchIndex returns the same hits for every search
+type searchIndex struct {
+	domain.WazuhEventRepository
+	hits []*elastic.SearchHit
+}
+
+func (m *searchIndex) FetchSecurityEvents(ctx context.Context, filter *model.FetchEventsRequest) ([]*elastic.SearchHit, error) {
+	return m.hits, nil
+}
+
+// memClosedEvents keeps closed events by alert ID
+type memClosedEvents struct {
+	domain.ClosedEventRepository
+	closed map[string]*entity.ClosedEvent
+}
+
+func (m *memClosedEvents) FetchClosedEventByEventID(ctx context.Context, eventID string) (*entity.ClosedEvent, error) {
+	return m.closed[eventID], nil
+}
+
+func (m *memClosedEvents) SaveClosedEvent(ctx context.Context, closedEvent *entity.ClosedEvent) error {
+	closedEvent.ID = len(m.closed) + 1
+	m.closed[closedEvent.EventID] = closedEvent
+	return nil
+}
+
+type memDecisions struct {
+	domain.AutoCloseDecisionRepository
+	decisions []*entity.AutoCloseDecision
+}
+
+func (m *memDecisions) SaveDecision(ctx context.Context, decision *entity.AutoCloseDecision) (bool, error) {
+	decision.ID = len(m.decisions) + 1
+	m.decisions = append(m.decisions, decision)
+	return true, nil
+}
+
+func (m *memDecisions) FetchDecisionByEventID(ctx context.Context, eventID string, mode string) (*entity.AutoCloseDecision, error) {
+	for _, decision := range m.decisions {
+		if decision.EventID == eventID && decision.Mode == mode {
+			return decision, nil
+		}
+	}
+	return nil, nil
+}
+
+type memTriageActions struct {
+	domain.TriageActionRepository
+	actions []*entity.TriageAction
+}
+
+func (m *memTriageActions) SaveTriageAction(ctx context.Context, action *entity.TriageAction) error {
+	m.actions = append(m.actions, action)
+	return nil
+}
+
+// tripAllGuardrails blocks every closure it is asked about
+type tripAllGuardrails struct {
+	domain.GuardrailUsecase
+	checked int
+}
+
+func (m *tripAllGuardrails) CheckAutoClose(ctx context.Context, eventID string, rule *entity.WazuhSecurityEventRule, source []byte) (*entity.GuardrailTrip, error) {
+	m.checked++
+	return &entity.GuardrailTrip{Guardrail: entity.GuardrailKillSwitch, EventID: eventID}, nil
+}
+
+type plainAsset struct{ domain.AssetUsecase }
+
+func (plainAsset) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	return hits
+}
+
+func TestFetchEventsWithAutoCloseMode(t *testing.T) {
+	alert := func(id string, level int) *elastic.SearchHit {
+		return &elastic.SearchHit{
+			Id:     "doc-" + id,
+			Source: json.RawMessage(fmt.Sprintf(`{"id":"%s","rule":{"id":"5710","level":%d}}`, id, level)),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		mode          string
+		closed        []string // alerts analysts closed before the run
+		enforced      []string // alerts auto-closed before in enforce mode, then reopened
+		wantDecisions []string // shadow decisions recorded by the run
+		wantChecked   int      // guardrail checks
+	}{
+		{name: "shadow records every open alert", mode: entity.AutoCloseModeShadow, wantDecisions: []string{"1", "2", "3"}},
+		{name: "shadow records alerts closed by analysts", mode: entity.AutoCloseModeShadow, closed: []string{"2"}, wantDecisions: []string{"1", "2", "3"}},
+		{name: "shadow records reopened alerts", mode: entity.AutoCloseModeShadow, enforced: []string{"3"}, wantDecisions: []string{"1", "2", "3"}},
+		// Every guardrail trips, so enforce mode closes nothing and records no decision
+		{name: "enforce checks guardrails of open alerts", mode: entity.AutoCloseModeEnforce, wantDecisions: []string{}, wantChecked: 3},
+		{name: "enforce skips closed and reopened alerts", mode: entity.AutoCloseModeEnforce, closed: []string{"2"}, enforced: []string{"3"}, wantDecisions: []string{}, wantChecked: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			index := &searchIndex{hits: []*elastic.SearchHit{alert("1", 3), alert("2", 5), alert("3", 12)}}
+			closedEvents := &memClosedEvents{closed: map[string]*entity.ClosedEvent{}}
+			for _, id := range tt.closed {
+				closedEvents.closed[id] = &entity.ClosedEvent{ID: 100, EventID: id, CloseType: entity.CloseTypeManual}
+			}
+			decisions := &memDecisions{}
+			for _, id := range tt.enforced {
+				decisions.decisions = append(decisions.decisions, &entity.AutoCloseDecision{EventID: id, Mode: entity.AutoCloseModeEnforce})
+			}
+			previous := len(decisions.decisions)
+			triageActions := &memTriageActions{}
+			guardrails := &tripAllGuardrails{}
+
+			u := NewEventUsecase(index, closedEvents, nil, triageActions, decisions, guardrails, plainAsset{}, plainGeoIP{}, plainIOC{}, plainCVE{}, plainMitre{})
+
+			hits, err := u.FetchEventsWithAutoClose(ctx, &model.FetchEventsRequest{
+				LevelRange:     &model.RangeQuery{Lte: float64(7)},
+				AutoAddToClose: true,
+				AutoCloseMode:  tt.mode,
+			})
+			if err != nil {
+				t.Fatalf("FetchEventsWithAutoClose: %v", err)
+			}
+			if len(hits) != len(index.hits) {
+				t.Errorf("returned %d hits, want %d", len(hits), len(index.hits))
+			}
+
+			recorded := decisions.decisions[previous:]
+			if len(recorded) != len(tt.wantDecisions) {
+				t.Fatalf("recorded %d decisions, want %d", len(recorded), len(tt.wantDecisions))
+			}
+			for i, decision := range recorded {
+				if decision.EventID != tt.wantDecisions[i] || decision.Mode != entity.AutoCloseModeShadow || decision.ClosedEventID != 0 {
+					t.Errorf("decision %d for %s in %s mode with closed event %d, want a shadow decision for %s with no closed event", i, decision.EventID, decision.Mode, decision.ClosedEventID, tt.wantDecisions[i])
+				}
+				if decision.Criterion != "level<=7" || decision.RuleID != "5710" || decision.RawEvent == "" {
+					t.Errorf("decision %d criterion %q rule %q, want level<=7 and rule 5710 with the raw event", i, decision.Criterion, decision.RuleID)
+				}
+			}
+
+			if len(closedEvents.closed) != len(tt.closed) {
+				t.Errorf("%d closed events, want the %d closed before the run", len(closedEvents.closed), len(tt.closed))
+			}
+			if len(triageActions.actions) != 0 {
+				t.Errorf("recorded %d triage actions, want none", len(triageActions.actions))
+			}
+			if guardrails.checked != tt.wantChecked {
+				t.Errorf("checked guardrails %d times, want %d", guardrails.checked, tt.wantChecked)
+			}
+		})
+	}
+}
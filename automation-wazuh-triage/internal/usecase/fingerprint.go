@@ -0,0 +1,445 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// defaultFingerprintFields apply until the fingerprint config is saved through the API
+	defaultFingerprintFields = "rule.id,agent.id,data.srcip,full_log"
+
+	// maxFingerprintFields bounds the fields hashed per alert
+	maxFingerprintFields = 20
+
+	// fingerprintLength is the number of hex characters kept of the SHA-256 hash
+	fingerprintLength = 16
+
+	// defaultFingerprintWindow and maxFingerprintWindow bound how far back alerts are grouped or closed by fingerprint
+	defaultFingerprintWindow = 24 * time.Hour
+	maxFingerprintWindow     = 7 * 24 * time.Hour
+
+	// defaultFingerprintGroupLimit and maxFingerprintGroupLimit bound the groups returned per page
+	defaultFingerprintGroupLimit = 10
+	maxFingerprintGroupLimit     = 1000
+
+	// fingerprintBatchSize and maxFingerprintBatches bound the alerts read from the indexer per close
+	fingerprintBatchSize  = 1000
+	maxFingerprintBatches = 10
+)
+
+var (
+	fingerprintFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_@-]+(\.[A-Za-z0-9_@-]+)*$`)
+	fingerprintPattern      = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+	// Variable parts of a log line, replaced in this order
+	logIPv4Pattern   = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}\b`)
+	logHexPattern    = regexp.MustCompile(`\b(0x)?[0-9a-f]{8,}\b`)
+	logNumberPattern = regexp.MustCompile(`\d+`)
+	logSpacePattern  = regexp.MustCompile(`\s+`)
+
+	// normalizedLogScript is normalizeLogLine run by the indexer, so alerts are grouped by the full_log their
+	// fingerprint hashes
+	normalizedLogScript = elastic.NewScript(`
+def value = params._source.full_log;
+if (value == null) { return ''; }
+String line = value.toString().toLowerCase();
+line = /\b\d{1,3}(\.\d{1,3}){3}\b/.matcher(line).replaceAll('<ip>');
+line = /\b(0x)?[0-9a-f]{8,}\b/.matcher(line).replaceAll('<hex>');
+line = /\d+/.matcher(line).replaceAll('<n>');
+line = /\s+/.matcher(line).replaceAll(' ');
+return line.trim();
+`).Lang("painless")
+)
+
+type fingerprintUsecase struct {
+	wazuhEventRepo   domain.WazuhEventRepository
+	closedEventRepo  domain.ClosedEventRepository
+	triageActionRepo domain.TriageActionRepository
+	settingRepo      domain.SettingRepository
+}
+
+func NewFingerprintUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+	settingRepo domain.SettingRepository,
+) domain.FingerprintUsecase {
+	return &fingerprintUsecase{
+		wazuhEventRepo:   wazuhEventRepo,
+		closedEventRepo:  closedEventRepo,
+		triageActionRepo: triageActionRepo,
+		settingRepo:      settingRepo,
+	}
+}
+
+// FetchConfig returns the saved fingerprint config, or the FINGERPRINT_FIELDS default when none was saved
+func (u *fingerprintUsecase) FetchConfig(ctx context.Context) (*entity.FingerprintConfig, error) {
+	config := &entity.FingerprintConfig{Fields: defaultFingerprintFieldList()}
+
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeyFingerprintConfig, config)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - fingerprint - FetchConfig]: Failed to fetch fingerprint config")
+		return nil, err
+	}
+
+	if setting != nil {
+		config.UpdatedBy = setting.UpdatedBy
+		config.UpdatedAt = &setting.UpdatedAt
+	}
+	return config, nil
+}
+
+// UpdateConfig replaces the fingerprint fields. Fingerprints are computed when alerts are listed, so the
+// change applies to every alert from now on.
+func (u *fingerprintUsecase) UpdateConfig(ctx context.Context, request *model.UpdateFingerprintConfigRequest) (*entity.FingerprintConfig, error) {
+	log := logger.WithRequestID(ctx)
+
+	updatedBy := strings.TrimSpace(request.UpdatedBy)
+	if updatedBy == "" {
+		return nil, fmt.Errorf("invalid fingerprint config: updated_by is required")
+	}
+
+	fields, err := normalizeFingerprintFields(request.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	config := &entity.FingerprintConfig{Fields: fields, UpdatedBy: updatedBy, UpdatedAt: &now}
+
+	if err := saveSetting(ctx, u.settingRepo, entity.SettingKeyFingerprintConfig, entity.FingerprintConfig{Fields: fields}, updatedBy, now); err != nil {
+		log.WithError(err).Error("[usecase - fingerprint - UpdateConfig]: Failed to save fingerprint config")
+		return nil, err
+	}
+
+	log.WithField("fields", strings.Join(fields, ",")).WithField("updated_by", updatedBy).Info("[usecase - fingerprint - UpdateConfig]: Updated fingerprint config")
+	return config, nil
+}
+
+// CollapseEvents groups the alerts of the window that match the event search by fingerprint. The indexer groups
+// them, so every group counts all its alerts of the window; a page holds up to limit groups in the order of their
+// field values, with the cursor of the next page.
+func (u *fingerprintUsecase) CollapseEvents(ctx context.Context, request *model.FetchEventsRequest) (*model.FingerprintGroupPage, error) {
+	log := logger.WithRequestID(ctx)
+
+	window := defaultFingerprintWindow
+	if request.CollapseWindow != "" {
+		parsed, err := time.ParseDuration(request.CollapseWindow)
+		if err != nil || parsed <= 0 || parsed > maxFingerprintWindow {
+			return nil, fmt.Errorf("invalid collapse_window %q: must be a duration of at most %s", request.CollapseWindow, maxFingerprintWindow)
+		}
+		window = parsed
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultFingerprintGroupLimit
+	}
+	if limit > maxFingerprintGroupLimit {
+		limit = maxFingerprintGroupLimit
+	}
+
+	after, err := decodeFingerprintCursor(request.CollapseCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := u.FetchConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.FingerprintGroupPage{Since: time.Now().Add(-window).UTC(), Groups: []*model.FingerprintGroup{}}
+
+	buckets, next, err := u.wazuhEventRepo.FetchFingerprintGroups(ctx, request, page.Since, config.Fields, map[string]*elastic.Script{"full_log": normalizedLogScript}, after, limit)
+	if err != nil {
+		log.WithError(err).Error("[usecase - fingerprint - CollapseEvents]: Failed to group security events")
+		return nil, err
+	}
+
+	for _, bucket := range buckets {
+		group := &model.FingerprintGroup{Count: bucket.DocCount}
+
+		if latest, ok := bucket.TopHits("latest"); ok && latest.Hits != nil && len(latest.Hits.Hits) > 0 {
+			group.Event = latest.Hits.Hits[0]
+			group.Fingerprint, _ = alertFingerprint(group.Event.Source, config.Fields)
+		}
+		if first, ok := bucket.Min("first_seen"); ok && first.Value != nil {
+			group.FirstSeen = time.UnixMilli(int64(*first.Value)).UTC()
+		}
+		if last, ok := bucket.Max("last_seen"); ok && last.Value != nil {
+			group.LastSeen = time.UnixMilli(int64(*last.Value)).UTC()
+		}
+
+		page.Groups = append(page.Groups, group)
+	}
+
+	if next != nil {
+		page.NextCursor = encodeFingerprintCursor(next)
+	}
+
+	return page, nil
+}
+
+// encodeFingerprintCursor turns the key of the last group of a page into an opaque cursor
+func encodeFingerprintCursor(after map[string]interface{}) string {
+	encoded, _ := json.Marshal(after)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeFingerprintCursor(cursor string) (map[string]interface{}, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collapse_cursor")
+	}
+
+	var after map[string]interface{}
+	if err := json.Unmarshal(decoded, &after); err != nil || len(after) == 0 {
+		return nil, fmt.Errorf("invalid collapse_cursor")
+	}
+	return after, nil
+}
+
+// CloseByFingerprint closes every open alert of the window whose fingerprint matches, with the analyst's
+// reason and label. Alerts closed before are counted and left as they are. At most maxFingerprintBatches
+// batches are read, oldest first; the result is flagged truncated when newer alerts were left unread.
+func (u *fingerprintUsecase) CloseByFingerprint(ctx context.Context, fingerprint string, window time.Duration, request *model.CloseEventRequest) (*entity.FingerprintCloseResult, error) {
+	log := logger.WithRequestID(ctx)
+
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+	if !fingerprintPattern.MatchString(fingerprint) {
+		return nil, fmt.Errorf("invalid fingerprint %q: expected %d hex characters", fingerprint, fingerprintLength)
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		return nil, fmt.Errorf("invalid close request: reason is required")
+	}
+	if !entity.IsValidLabel(request.Label) {
+		return nil, fmt.Errorf("invalid label %q: must be %s or %s", request.Label, entity.LabelFalsePositive, entity.LabelTruePositive)
+	}
+	if window <= 0 {
+		window = defaultFingerprintWindow
+	}
+	if window > maxFingerprintWindow {
+		return nil, fmt.Errorf("invalid window: must be at most %s", maxFingerprintWindow)
+	}
+
+	actor := strings.TrimSpace(request.Analyst)
+	if actor == "" {
+		actor = entity.ActorUnknown
+	}
+
+	config, err := u.FetchConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.FingerprintCloseResult{Fingerprint: fingerprint, Since: time.Now().Add(-window)}
+	var searchAfter []interface{}
+
+	for batch := 0; ; batch++ {
+		if batch == maxFingerprintBatches {
+			result.Truncated = true
+			log.WithField("fingerprint", fingerprint).WithField("scanned", result.Scanned).Warn("[usecase - fingerprint - CloseByFingerprint]: Alert limit reached, newer alerts left unread")
+			break
+		}
+
+		hits, err := u.wazuhEventRepo.FetchSecurityEventsSince(ctx, result.Since, searchAfter, fingerprintBatchSize)
+		if err != nil {
+			log.WithError(err).Error("[usecase - fingerprint - CloseByFingerprint]: Failed to fetch security events")
+			return nil, err
+		}
+
+		for _, hit := range hits {
+			result.Scanned++
+
+			if hitFingerprint, ok := alertFingerprint(hit.Source, config.Fields); !ok || hitFingerprint != fingerprint {
+				continue
+			}
+			result.Matched++
+
+			closed, err := u.closeHit(ctx, hit, request, actor)
+			if err != nil {
+				return nil, err
+			}
+			if closed {
+				result.Closed++
+			} else {
+				result.AlreadyClosed++
+			}
+		}
+
+		// A short batch is the last one
+		if len(hits) < fingerprintBatchSize || len(hits[len(hits)-1].Sort) == 0 {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+
+	log.WithField("fingerprint", fingerprint).WithField("analyst", actor).WithField("matched", result.Matched).WithField("closed", result.Closed).WithField("already_closed", result.AlreadyClosed).Info("[usecase - fingerprint - CloseByFingerprint]: Closed alerts by fingerprint")
+	return result, nil
+}
+
+// closeHit closes one alert unless it is closed already, and reports whether it was closed now
+func (u *fingerprintUsecase) closeHit(ctx context.Context, hit *elastic.SearchHit, request *model.CloseEventRequest, actor string) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	var securityEvent entity.WazuhSecurityEvent
+	if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || securityEvent.Rule == nil {
+		log.WithField("hit_id", hit.Id).Warn("[usecase - fingerprint - closeHit]: Failed to parse event, skipping it")
+		return false, nil
+	}
+	eventID := string(securityEvent.ID)
+
+	existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - fingerprint - closeHit]: Failed to check existing closed event")
+		return false, err
+	}
+	if existingClosedEvent != nil {
+		return false, nil
+	}
+
+	hitJSON, err := json.Marshal(hit)
+	if err != nil {
+		return false, err
+	}
+
+	closedEvent := &entity.ClosedEvent{
+		EventID:   eventID,
+		RuleID:    securityEvent.Rule.ID,
+		RawEvent:  string(hitJSON),
+		Reason:    request.Reason,
+		Status:    "closed",
+		CloseType: entity.CloseTypeManual,
+		Label:     request.Label,
+		CloseAt:   time.Now(),
+	}
+	if err := u.closedEventRepo.SaveClosedEvent(ctx, closedEvent); err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - fingerprint - closeHit]: Failed to close event")
+		return false, err
+	}
+
+	triageAction := newTriageAction(closedEvent.EventID, closedEvent.RuleID, closedEvent.RawEvent, entity.TriageActionClosed, actor)
+	if err := u.triageActionRepo.SaveTriageAction(ctx, triageAction); err != nil {
+		log.WithError(err).WithField("event_id", eventID).Warn("[usecase - fingerprint - closeHit]: Failed to record triage action")
+	}
+
+	return true, nil
+}
+
+// alertFingerprint hashes the configured fields of an alert source. Missing fields hash as empty, so alerts
+// without a source IP still share a fingerprint.
+func alertFingerprint(source []byte, fields []string) (string, bool) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(source, &document); err != nil {
+		return "", false
+	}
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+"="+fingerprintValue(document, field))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])[:fingerprintLength], true
+}
+
+// fingerprintValue returns the value at a dotted path of the alert, with full_log normalized
+func fingerprintValue(document map[string]interface{}, field string) string {
+	var value interface{} = document
+	for _, part := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		if value, ok = object[part]; !ok {
+			return ""
+		}
+	}
+
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		text = v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		text = string(encoded)
+	}
+
+	if field == "full_log" {
+		text = normalizeLogLine(text)
+	}
+	return text
+}
+
+// normalizeLogLine masks addresses, hashes and numbers so log lines of one pattern compare equal
+func normalizeLogLine(line string) string {
+	line = strings.ToLower(line)
+	line = logIPv4Pattern.ReplaceAllString(line, "<ip>")
+	line = logHexPattern.ReplaceAllString(line, "<hex>")
+	line = logNumberPattern.ReplaceAllString(line, "<n>")
+	line = logSpacePattern.ReplaceAllString(line, " ")
+	return strings.TrimSpace(line)
+}
+
+// normalizeFingerprintFields validates the fields and returns them without duplicates, keeping their order
+func normalizeFingerprintFields(fields []string) ([]string, error) {
+	seen := map[string]bool{}
+	normalized := []string{}
+
+	for _, field := range cleanList(fields) {
+		if !fingerprintFieldPattern.MatchString(field) {
+			return nil, fmt.Errorf("invalid fingerprint config: field %q must be a dotted path such as data.srcip", field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			normalized = append(normalized, field)
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("invalid fingerprint config: at least one field is required")
+	}
+	if len(normalized) > maxFingerprintFields {
+		return nil, fmt.Errorf("invalid fingerprint config: at most %d fields are allowed", maxFingerprintFields)
+	}
+	return normalized, nil
+}
+
+// defaultFingerprintFieldList reads FINGERPRINT_FIELDS, falling back to the built-in fields when it is unset or invalid
+func defaultFingerprintFieldList() []string {
+	value := os.Getenv("FINGERPRINT_FIELDS")
+	if value == "" {
+		value = defaultFingerprintFields
+	}
+
+	fields, err := normalizeFingerprintFields(strings.Split(value, ","))
+	if err != nil {
+		fields, _ = normalizeFingerprintFields(strings.Split(defaultFingerprintFields, ","))
+	}
+	return fields
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// groupingIndex answers fingerprint group searches with fixed buckets and records the page asked for
+type groupingIndex struct {
+	domain.WazuhEventRepository
+	buckets []*elastic.AggregationBucketCompositeItem
+	next    map[string]interface{}
+	after   map[string]interface{}
+	size    int
+}
+
+func (m *groupingIndex) FetchFingerprintGroups(ctx context.Context, filter *model.FetchEventsRequest, since time.Time, fields []string, scripts map[string]*elastic.Script, after map[string]interface{}, size int) ([]*elastic.AggregationBucketCompositeItem, map[string]interface{}, error) {
+	m.after = after
+	m.size = size
+	return m.buckets, m.next, nil
+}
+
+func compositeBucket(t *testing.T, raw string) *elastic.AggregationBucketCompositeItem {
+	t.Helper()
+
+	var bucket elastic.AggregationBucketCompositeItem
+	if err := json.Unmarshal([]byte(raw), &bucket); err != nil {
+		t.Fatalf("decode bucket: %v", err)
+	}
+	return &bucket
+}
+
+func TestCollapseEvents(t *testing.T) {
+	source := `{"rule":{"id":"5710"},"agent":{"id":"001"},"data":{"srcip":"10.0.0.1"},"full_log":"Failed password for root from 10.0.0.1 port 2201"}`
+	bucket := `{"key":{"f0":"5710","f1":"001","f2":"10.0.0.1","f3":"failed password for root from <ip> port <n>"},"doc_count":42,` +
+		`"latest":{"hits":{"hits":[{"_id":"a1","_source":` + source + `}]}},` +
+		`"first_seen":{"value":1792400400000},"last_seen":{"value":1792404000000}}`
+	next := map[string]interface{}{"f0": "5710", "f1": "001", "f2": "10.0.0.1", "f3": "x"}
+
+	tests := []struct {
+		name      string
+		request   *model.FetchEventsRequest
+		next      map[string]interface{}
+		wantErr   string
+		wantAfter map[string]interface{}
+		wantSize  int
+	}{
+		{name: "first page with the default limit", request: &model.FetchEventsRequest{Collapse: true}, wantSize: defaultFingerprintGroupLimit},
+		{name: "limit is capped", request: &model.FetchEventsRequest{Collapse: true, Limit: 5000}, wantSize: maxFingerprintGroupLimit},
+		{name: "next page from a cursor", request: &model.FetchEventsRequest{Collapse: true, CollapseCursor: encodeFingerprintCursor(next)}, next: next, wantAfter: next, wantSize: defaultFingerprintGroupLimit},
+		{name: "window too long", request: &model.FetchEventsRequest{Collapse: true, CollapseWindow: "200h"}, wantErr: "invalid collapse_window"},
+		{name: "window not a duration", request: &model.FetchEventsRequest{Collapse: true, CollapseWindow: "yesterday"}, wantErr: "invalid collapse_window"},
+		{name: "cursor not base64", request: &model.FetchEventsRequest{Collapse: true, CollapseCursor: "%%%"}, wantErr: "invalid collapse_cursor"},
+		{name: "cursor without a key", request: &model.FetchEventsRequest{Collapse: true, CollapseCursor: encodeFingerprintCursor(map[string]interface{}{})}, wantErr: "invalid collapse_cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := &groupingIndex{buckets: []*elastic.AggregationBucketCompositeItem{compositeBucket(t, bucket)}, next: tt.next}
+			settings := &memSettings{settings: map[string]*entity.Setting{}}
+			u := NewFingerprintUsecase(index, nil, nil, settings)
+
+			page, err := u.CollapseEvents(context.Background(), tt.request)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CollapseEvents: %v", err)
+			}
+
+			if index.size != tt.wantSize || !reflect.DeepEqual(index.after, tt.wantAfter) {
+				t.Errorf("searched after %v with size %d, want after %v with size %d", index.after, index.size, tt.wantAfter, tt.wantSize)
+			}
+
+			if len(page.Groups) != 1 {
+				t.Fatalf("got %d groups, want 1", len(page.Groups))
+			}
+			group := page.Groups[0]
+			wantFingerprint, _ := alertFingerprint(json.RawMessage(source), defaultFingerprintFieldList())
+			if group.Fingerprint != wantFingerprint || group.Count != 42 || group.Event == nil || group.Event.Id != "a1" {
+				t.Errorf("group = %+v, want fingerprint %s with 42 alerts and event a1", group, wantFingerprint)
+			}
+			if !group.FirstSeen.Equal(time.UnixMilli(1792400400000)) || !group.LastSeen.Equal(time.UnixMilli(1792404000000)) {
+				t.Errorf("seen from %s to %s", group.FirstSeen, group.LastSeen)
+			}
+
+			if tt.next == nil && page.NextCursor != "" {
+				t.Errorf("next cursor = %q on the last page", page.NextCursor)
+			}
+			if tt.next != nil {
+				after, err := decodeFingerprintCursor(page.NextCursor)
+				if err != nil || !reflect.DeepEqual(after, tt.next) {
+					t.Errorf("next cursor decodes to %v (%v), want %v", after, err, tt.next)
+				}
+			}
+		})
+	}
+}
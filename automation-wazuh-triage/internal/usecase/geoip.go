@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+
+	"github.com/olivere/elastic/v7"
+)
+
+type geoIPUsecase struct {
+	geoIPRepo domain.GeoIPRepository
+}
+
+func NewGeoIPUsecase(geoIPRepo domain.GeoIPRepository) domain.GeoIPUsecase {
+	return &geoIPUsecase{
+		geoIPRepo: geoIPRepo,
+	}
+}
+
+// EnrichHits returns the hits with a geoip block, the country, city and ASN of data.srcip, added to the
+// source of each one whose source address is located. The given hits are not modified, and lookup failures
+// leave the hit as it is.
+func (u *geoIPUsecase) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	enriched := make([]*elastic.SearchHit, 0, len(hits))
+	located := map[string]*entity.GeoLocation{}
+	failed := 0
+
+	for _, hit := range hits {
+		alert, ok := decodeAlertSource(hit.Source)
+		if !ok || alert.Data.SrcIP == "" {
+			enriched = append(enriched, hit)
+			continue
+		}
+
+		location, seen := located[alert.Data.SrcIP]
+		if !seen {
+			var err error
+			location, err = u.geoIPRepo.LookupIP(ctx, alert.Data.SrcIP)
+			if err != nil {
+				failed++
+			}
+			located[alert.Data.SrcIP] = location
+		}
+
+		if location == nil {
+			enriched = append(enriched, hit)
+			continue
+		}
+		enriched = append(enriched, withSourceField(hit, "geoip", location))
+	}
+
+	if failed > 0 {
+		logger.WithRequestID(ctx).WithField("failed", failed).Warn("[usecase - geoip - EnrichHits]: Some source addresses could not be located")
+	}
+
+	return enriched
+}
@@ -0,0 +1,366 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAutoCloseMaxLevel and defaultAutoCloseMaxPerMinute apply until guardrails are saved through the API
+	defaultAutoCloseMaxLevel     = 7
+	defaultAutoCloseMaxPerMinute = 100
+
+	// maxRuleLevel is the highest Wazuh rule level
+	maxRuleLevel = 16
+
+	// defaultGuardrailTripsWindow and defaultGuardrailTripsLimit bound the trips listed when none are requested
+	defaultGuardrailTripsWindow = 24 * time.Hour
+	defaultGuardrailTripsLimit  = 100
+	maxGuardrailTripsLimit      = 1000
+)
+
+type guardrailUsecase struct {
+	settingRepo     domain.SettingRepository
+	tripRepo        domain.GuardrailTripRepository
+	closedEventRepo domain.ClosedEventRepository
+	iocUsecase      domain.IOCUsecase
+	notifier        *notifier.Notifier
+}
+
+func NewGuardrailUsecase(
+	settingRepo domain.SettingRepository,
+	tripRepo domain.GuardrailTripRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	iocUsecase domain.IOCUsecase,
+	notifier *notifier.Notifier,
+) domain.GuardrailUsecase {
+	return &guardrailUsecase{
+		settingRepo:     settingRepo,
+		tripRepo:        tripRepo,
+		closedEventRepo: closedEventRepo,
+		iocUsecase:      iocUsecase,
+		notifier:        notifier,
+	}
+}
+
+// CheckAutoClose must pass before any automated closure of the alert with the given source. It returns the
+// recorded trip when a guardrail stops the closure, and nil when the event may be closed.
+func (u *guardrailUsecase) CheckAutoClose(ctx context.Context, eventID string, rule *entity.WazuhSecurityEventRule, source []byte) (*entity.GuardrailTrip, error) {
+	if rule == nil {
+		rule = &entity.WazuhSecurityEventRule{}
+	}
+
+	killSwitch, err := u.fetchKillSwitch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	guardrails, err := u.fetchGuardrails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	guardrail, detail, err := u.evaluate(ctx, killSwitch, guardrails, rule, source)
+	if err != nil || guardrail == "" {
+		return nil, err
+	}
+
+	trip := &entity.GuardrailTrip{
+		Guardrail: guardrail,
+		EventID:   eventID,
+		RuleID:    rule.ID,
+		RuleLevel: rule.Level,
+		Detail:    detail,
+		TrippedAt: time.Now(),
+	}
+
+	logger.WithRequestID(ctx).WithField("event_id", eventID).WithField("rule_id", rule.ID).WithField("guardrail", guardrail).Warn("[usecase - guardrail - CheckAutoClose]: Guardrail tripped, auto-close blocked: " + detail)
+
+	if err := u.tripRepo.SaveGuardrailTrip(ctx, trip); err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+// evaluate returns the first guardrail the rule trips with the reason, or an empty guardrail
+func (u *guardrailUsecase) evaluate(ctx context.Context, killSwitch *entity.KillSwitch, guardrails *entity.AutoCloseGuardrails, rule *entity.WazuhSecurityEventRule, source []byte) (string, string, error) {
+	if killSwitch.Engaged {
+		return entity.GuardrailKillSwitch, fmt.Sprintf("kill switch engaged by %s: %s", killSwitch.UpdatedBy, killSwitch.Reason), nil
+	}
+
+	if guardrails.BlockIOCMatches {
+		if matches := u.iocUsecase.MatchAlert(ctx, source); len(matches) > 0 {
+			match := matches[0]
+			return entity.GuardrailIOCMatch, fmt.Sprintf("%s %s matches %s indicator %s from %s (confidence %d)", match.Field, match.Observed, match.Type, match.Value, match.Source, match.Confidence), nil
+		}
+	}
+
+	if rule.Level > guardrails.MaxLevel {
+		return entity.GuardrailMaxLevel, fmt.Sprintf("rule level %d is above the auto-close ceiling %d", rule.Level, guardrails.MaxLevel), nil
+	}
+
+	for _, protected := range guardrails.ProtectedRules {
+		if rule.ID == protected {
+			return entity.GuardrailProtectedRule, fmt.Sprintf("rule %s is protected", rule.ID), nil
+		}
+	}
+
+	for _, group := range rule.Groups {
+		for _, protected := range guardrails.ProtectedGroups {
+			if group == protected {
+				return entity.GuardrailProtectedGroup, fmt.Sprintf("rule group %s is protected", group), nil
+			}
+		}
+	}
+
+	if guardrails.MaxPerMinutePerRule > 0 {
+		closed, err := u.closedEventRepo.CountAutoClosuresSince(ctx, rule.ID, time.Now().Add(-time.Minute))
+		if err != nil {
+			return "", "", err
+		}
+		if closed >= guardrails.MaxPerMinutePerRule {
+			return entity.GuardrailRateLimit, fmt.Sprintf("rule %s reached %d auto-closures in the last minute", rule.ID, guardrails.MaxPerMinutePerRule), nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// FetchStatus returns the guardrails, the kill switch and the trips of the last day per guardrail
+func (u *guardrailUsecase) FetchStatus(ctx context.Context) (*entity.GuardrailStatus, error) {
+	killSwitch, err := u.fetchKillSwitch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	guardrails, err := u.fetchGuardrails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trips, err := u.tripRepo.CountGuardrailTripsSince(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - guardrail - FetchStatus]: Failed to count guardrail trips")
+		return nil, err
+	}
+
+	return &entity.GuardrailStatus{
+		KillSwitch:   *killSwitch,
+		Guardrails:   *guardrails,
+		TripsLastDay: trips,
+	}, nil
+}
+
+// UpdateGuardrails changes the fields given in the request and keeps the others
+func (u *guardrailUsecase) UpdateGuardrails(ctx context.Context, request *model.UpdateGuardrailsRequest) (*entity.AutoCloseGuardrails, error) {
+	log := logger.WithRequestID(ctx)
+
+	updatedBy := strings.TrimSpace(request.UpdatedBy)
+	if updatedBy == "" {
+		return nil, fmt.Errorf("invalid guardrails: updated_by is required")
+	}
+
+	guardrails, err := u.fetchGuardrails(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.MaxLevel != nil {
+		guardrails.MaxLevel = *request.MaxLevel
+	}
+	if request.ProtectedRules != nil {
+		guardrails.ProtectedRules = cleanList(request.ProtectedRules)
+	}
+	if request.ProtectedGroups != nil {
+		guardrails.ProtectedGroups = cleanList(request.ProtectedGroups)
+	}
+	if request.MaxPerMinutePerRule != nil {
+		guardrails.MaxPerMinutePerRule = *request.MaxPerMinutePerRule
+	}
+	if request.BlockIOCMatches != nil {
+		guardrails.BlockIOCMatches = *request.BlockIOCMatches
+	}
+
+	if guardrails.MaxLevel < 0 || guardrails.MaxLevel > maxRuleLevel {
+		return nil, fmt.Errorf("invalid guardrails: max_level must be between 0 and %d", maxRuleLevel)
+	}
+	if guardrails.MaxPerMinutePerRule < 0 {
+		return nil, fmt.Errorf("invalid guardrails: max_per_minute_per_rule must not be negative")
+	}
+
+	now := time.Now()
+	guardrails.UpdatedBy = updatedBy
+	guardrails.UpdatedAt = &now
+
+	value := entity.AutoCloseGuardrails{
+		MaxLevel:            guardrails.MaxLevel,
+		ProtectedRules:      guardrails.ProtectedRules,
+		ProtectedGroups:     guardrails.ProtectedGroups,
+		MaxPerMinutePerRule: guardrails.MaxPerMinutePerRule,
+		BlockIOCMatches:     guardrails.BlockIOCMatches,
+	}
+	if err := saveSetting(ctx, u.settingRepo, entity.SettingKeyAutoCloseGuardrails, value, updatedBy, now); err != nil {
+		log.WithError(err).Error("[usecase - guardrail - UpdateGuardrails]: Failed to save guardrails")
+		return nil, err
+	}
+
+	log.WithField("max_level", guardrails.MaxLevel).WithField("max_per_minute_per_rule", guardrails.MaxPerMinutePerRule).WithField("block_ioc_matches", guardrails.BlockIOCMatches).WithField("updated_by", updatedBy).Info("[usecase - guardrail - UpdateGuardrails]: Updated auto-close guardrails")
+	return guardrails, nil
+}
+
+// SetKillSwitch engages or releases the kill switch and notifies the team either way
+func (u *guardrailUsecase) SetKillSwitch(ctx context.Context, request *model.KillSwitchRequest) (*entity.KillSwitch, error) {
+	log := logger.WithRequestID(ctx)
+
+	actor := strings.TrimSpace(request.Actor)
+	reason := strings.TrimSpace(request.Reason)
+
+	if request.Engaged == nil {
+		return nil, fmt.Errorf("invalid kill switch: engaged is required")
+	}
+	if actor == "" {
+		return nil, fmt.Errorf("invalid kill switch: actor is required")
+	}
+	if *request.Engaged && reason == "" {
+		return nil, fmt.Errorf("invalid kill switch: a reason is required to engage it")
+	}
+
+	now := time.Now()
+	killSwitch := &entity.KillSwitch{
+		Engaged:   *request.Engaged,
+		Reason:    reason,
+		UpdatedBy: actor,
+		UpdatedAt: &now,
+	}
+
+	value := entity.KillSwitch{Engaged: killSwitch.Engaged, Reason: killSwitch.Reason}
+	if err := saveSetting(ctx, u.settingRepo, entity.SettingKeyAutoCloseKillSwitch, value, actor, now); err != nil {
+		log.WithError(err).Error("[usecase - guardrail - SetKillSwitch]: Failed to save kill switch")
+		return nil, err
+	}
+
+	title := "Auto-close kill switch released"
+	message := fmt.Sprintf("%s released the kill switch, automated closure resumes", actor)
+	if killSwitch.Engaged {
+		title = "Auto-close kill switch engaged"
+		message = fmt.Sprintf("%s engaged the kill switch, all automated closure is stopped: %s", actor, reason)
+	}
+
+	log.WithField("engaged", killSwitch.Engaged).WithField("actor", actor).Warn("[usecase - guardrail - SetKillSwitch]: " + title)
+
+	if err := u.notifier.Notify(ctx, notifier.Notification{
+		Title:    title,
+		Severity: "warning",
+		Message:  message,
+		Data:     killSwitch,
+	}); err != nil {
+		log.WithError(err).Warn("[usecase - guardrail - SetKillSwitch]: Failed to send notification")
+	}
+
+	return killSwitch, nil
+}
+
+func (u *guardrailUsecase) FetchTrips(ctx context.Context, request *model.FetchGuardrailTripsRequest) ([]*entity.GuardrailTrip, error) {
+	if request.Window <= 0 {
+		request.Window = defaultGuardrailTripsWindow
+	}
+	if request.Limit <= 0 {
+		request.Limit = defaultGuardrailTripsLimit
+	}
+	if request.Limit > maxGuardrailTripsLimit {
+		request.Limit = maxGuardrailTripsLimit
+	}
+
+	trips, err := u.tripRepo.FetchGuardrailTrips(ctx, time.Now().Add(-request.Window), request.Guardrail, request.RuleID, request.Limit)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - guardrail - FetchTrips]: Failed to fetch guardrail trips")
+		return nil, err
+	}
+
+	if trips == nil {
+		trips = []*entity.GuardrailTrip{}
+	}
+	return trips, nil
+}
+
+// fetchGuardrails returns the saved guardrails, or the environment defaults when none were saved
+func (u *guardrailUsecase) fetchGuardrails(ctx context.Context) (*entity.AutoCloseGuardrails, error) {
+	guardrails := &entity.AutoCloseGuardrails{
+		MaxLevel:            autoCloseMaxLevel(),
+		ProtectedRules:      cleanList(strings.Split(os.Getenv("AUTO_CLOSE_PROTECTED_RULES"), ",")),
+		ProtectedGroups:     cleanList(strings.Split(os.Getenv("AUTO_CLOSE_PROTECTED_GROUPS"), ",")),
+		MaxPerMinutePerRule: autoCloseMaxPerMinute(),
+		BlockIOCMatches:     autoCloseBlockIOCMatches(),
+	}
+
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeyAutoCloseGuardrails, guardrails)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - guardrail - fetchGuardrails]: Failed to fetch guardrails")
+		return nil, err
+	}
+
+	if setting != nil {
+		guardrails.UpdatedBy = setting.UpdatedBy
+		guardrails.UpdatedAt = &setting.UpdatedAt
+	}
+	return guardrails, nil
+}
+
+// fetchKillSwitch returns the saved kill switch, released when it was never set
+func (u *guardrailUsecase) fetchKillSwitch(ctx context.Context) (*entity.KillSwitch, error) {
+	killSwitch := &entity.KillSwitch{}
+
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeyAutoCloseKillSwitch, killSwitch)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - guardrail - fetchKillSwitch]: Failed to fetch kill switch")
+		return nil, err
+	}
+
+	if setting != nil {
+		killSwitch.UpdatedBy = setting.UpdatedBy
+		killSwitch.UpdatedAt = &setting.UpdatedAt
+	}
+	return killSwitch, nil
+}
+
+// cleanList trims the values and drops empty ones, always returning a non-nil slice
+func cleanList(values []string) []string {
+	cleaned := []string{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return cleaned
+}
+
+func autoCloseMaxLevel() int {
+	level, err := strconv.Atoi(os.Getenv("AUTO_CLOSE_MAX_LEVEL"))
+	if err != nil || level < 0 || level > maxRuleLevel {
+		return defaultAutoCloseMaxLevel
+	}
+	return level
+}
+
+func autoCloseMaxPerMinute() int {
+	count, err := strconv.Atoi(os.Getenv("AUTO_CLOSE_MAX_PER_MINUTE"))
+	if err != nil || count < 0 {
+		return defaultAutoCloseMaxPerMinute
+	}
+	return count
+}
+
+func autoCloseBlockIOCMatches() bool {
+	block, err := strconv.ParseBool(os.Getenv("AUTO_CLOSE_BLOCK_IOC_MATCHES"))
+	if err != nil {
+		return true
+	}
+	return block
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type memSettings struct {
+	settings map[string]*entity.Setting
+}
+
+func (m *memSettings) FetchSetting(ctx context.Context, key string) (*entity.Setting, error) {
+	return m.settings[key], nil
+}
+
+func (m *memSettings) SaveSetting(ctx context.Context, setting *entity.Setting) error {
+	m.settings[setting.Key] = setting
+	return nil
+}
+
+type memGuardrailTrips struct {
+	domain.GuardrailTripRepository
+	trips []*entity.GuardrailTrip
+}
+
+func (m *memGuardrailTrips) SaveGuardrailTrip(ctx context.Context, trip *entity.GuardrailTrip) error {
+	trip.ID = len(m.trips) + 1
+	m.trips = append(m.trips, trip)
+	return nil
+}
+
+// recentClosures reports the same number of auto-closures for every rule and records the window asked for
+type recentClosures struct {
+	domain.ClosedEventRepository
+	closed int
+	since  time.Time
+}
+
+func (m *recentClosures) CountAutoClosuresSince(ctx context.Context, ruleID string, since time.Time) (int, error) {
+	m.since = since
+	return m.closed, nil
+}
+
+// matchingIOC matches every alert against the same indicators
+type matchingIOC struct {
+	domain.IOCUsecase
+	matches []entity.IOCMatch
+}
+
+func (m matchingIOC) MatchAlert(ctx context.Context, source []byte) []entity.IOCMatch {
+	return m.matches
+}
+
+func TestCheckAutoClose(t *testing.T) {
+	guardrails := entity.AutoCloseGuardrails{
+		MaxLevel:            7,
+		ProtectedRules:      []string{"5402"},
+		ProtectedGroups:     []string{"authentication_success"},
+		MaxPerMinutePerRule: 100,
+		BlockIOCMatches:     true,
+	}
+	indicator := []entity.IOCMatch{{Field: "data.srcip", Observed: "203.0.113.7", IOC: entity.IOC{Type: "ip", Value: "203.0.113.7", Source: "feed.csv", Confidence: 90}}}
+
+	tests := []struct {
+		name          string
+		killSwitch    bool
+		configure     func(*entity.AutoCloseGuardrails)
+		rule          *entity.WazuhSecurityEventRule
+		matches       []entity.IOCMatch
+		closed        int
+		wantGuardrail string // empty when the closure may go ahead
+		wantDetail    string
+	}{
+		{name: "nothing tripped", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 5, Groups: []string{"sshd"}}, closed: 99},
+		{name: "rule missing", rule: nil},
+		{name: "kill switch stops everything", killSwitch: true, rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 3}, wantGuardrail: entity.GuardrailKillSwitch, wantDetail: "engaged by alice: incident"},
+		{name: "kill switch before the other guardrails", killSwitch: true, rule: &entity.WazuhSecurityEventRule{ID: "5402", Level: 12}, matches: indicator, closed: 100, wantGuardrail: entity.GuardrailKillSwitch},
+		{name: "indicator match", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 3}, matches: indicator, wantGuardrail: entity.GuardrailIOCMatch, wantDetail: "data.srcip 203.0.113.7 matches ip indicator"},
+		{name: "indicator match before the level ceiling", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 12}, matches: indicator, wantGuardrail: entity.GuardrailIOCMatch},
+		{
+			name:      "indicator match allowed",
+			configure: func(g *entity.AutoCloseGuardrails) { g.BlockIOCMatches = false },
+			rule:      &entity.WazuhSecurityEventRule{ID: "5710", Level: 3},
+			matches:   indicator,
+		},
+		{name: "level at the ceiling", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 7}},
+		{name: "level above the ceiling", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 8}, wantGuardrail: entity.GuardrailMaxLevel, wantDetail: "rule level 8 is above the auto-close ceiling 7"},
+		{
+			name:          "ceiling of zero blocks every level",
+			configure:     func(g *entity.AutoCloseGuardrails) { g.MaxLevel = 0 },
+			rule:          &entity.WazuhSecurityEventRule{ID: "5710", Level: 1},
+			wantGuardrail: entity.GuardrailMaxLevel,
+		},
+		{name: "protected rule", rule: &entity.WazuhSecurityEventRule{ID: "5402", Level: 3}, wantGuardrail: entity.GuardrailProtectedRule, wantDetail: "rule 5402 is protected"},
+		{name: "protected group", rule: &entity.WazuhSecurityEventRule{ID: "5715", Level: 3, Groups: []string{"sshd", "authentication_success"}}, wantGuardrail: entity.GuardrailProtectedGroup, wantDetail: "rule group authentication_success is protected"},
+		{name: "group name is not a prefix match", rule: &entity.WazuhSecurityEventRule{ID: "5715", Level: 3, Groups: []string{"authentication"}}},
+		{name: "rate just below the cap", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 3}, closed: 99},
+		{name: "rate cap reached", rule: &entity.WazuhSecurityEventRule{ID: "5710", Level: 3}, closed: 100, wantGuardrail: entity.GuardrailRateLimit, wantDetail: "rule 5710 reached 100 auto-closures"},
+		{
+			name:      "rate cap of zero is disabled",
+			configure: func(g *entity.AutoCloseGuardrails) { g.MaxPerMinutePerRule = 0 },
+			rule:      &entity.WazuhSecurityEventRule{ID: "5710", Level: 3},
+			closed:    10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			settings := &memSettings{settings: map[string]*entity.Setting{}}
+
+			configured := guardrails
+			if tt.configure != nil {
+				tt.configure(&configured)
+			}
+			if err := saveSetting(ctx, settings, entity.SettingKeyAutoCloseGuardrails, configured, "alice", time.Now()); err != nil {
+				t.Fatalf("save guardrails: %v", err)
+			}
+			if err := saveSetting(ctx, settings, entity.SettingKeyAutoCloseKillSwitch, entity.KillSwitch{Engaged: tt.killSwitch, Reason: "incident"}, "alice", time.Now()); err != nil {
+				t.Fatalf("save kill switch: %v", err)
+			}
+
+			trips := &memGuardrailTrips{}
+			closures := &recentClosures{closed: tt.closed}
+			u := NewGuardrailUsecase(settings, trips, closures, matchingIOC{matches: tt.matches}, nil)
+
+			trip, err := u.CheckAutoClose(ctx, "1792400400.1234", tt.rule, []byte(`{}`))
+			if err != nil {
+				t.Fatalf("CheckAutoClose: %v", err)
+			}
+
+			if tt.wantGuardrail == "" {
+				if trip != nil || len(trips.trips) != 0 {
+					t.Fatalf("tripped %+v, want the closure to go ahead", trip)
+				}
+				return
+			}
+
+			if trip == nil {
+				t.Fatalf("no guardrail tripped, want %s", tt.wantGuardrail)
+			}
+			if trip.Guardrail != tt.wantGuardrail || !strings.Contains(trip.Detail, tt.wantDetail) {
+				t.Errorf("tripped %s (%s), want %s with a detail containing %q", trip.Guardrail, trip.Detail, tt.wantGuardrail, tt.wantDetail)
+			}
+			if trip.EventID != "1792400400.1234" || trip.RuleID != tt.rule.ID || trip.RuleLevel != tt.rule.Level {
+				t.Errorf("trip = %+v, want it to name the event and its rule", trip)
+			}
+			if len(trips.trips) != 1 || trips.trips[0] != trip {
+				t.Errorf("recorded %d trips, want the returned one", len(trips.trips))
+			}
+			if tt.wantGuardrail == entity.GuardrailRateLimit && time.Since(closures.since) > time.Minute+time.Second {
+				t.Errorf("counted auto-closures since %s, want the last minute", closures.since)
+			}
+		})
+	}
+}
@@ -0,0 +1,744 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// defaultIOCConfidence applies to indicators whose feed does not give a confidence
+	defaultIOCConfidence = 50
+	maxIOCConfidence     = 100
+)
+
+// iocTypeAliases maps the type names feeds commonly use to the IOC types
+var iocTypeAliases = map[string]string{
+	entity.IOCTypeIP:     entity.IOCTypeIP,
+	"ipv4":               entity.IOCTypeIP,
+	"ipv6":               entity.IOCTypeIP,
+	"ip-src":             entity.IOCTypeIP,
+	"ip-dst":             entity.IOCTypeIP,
+	entity.IOCTypeDomain: entity.IOCTypeDomain,
+	"hostname":           entity.IOCTypeDomain,
+	"domain-name":        entity.IOCTypeDomain,
+	entity.IOCTypeURL:    entity.IOCTypeURL,
+	"uri":                entity.IOCTypeURL,
+	entity.IOCTypeHash:   entity.IOCTypeHash,
+	"md5":                entity.IOCTypeHash,
+	"sha1":               entity.IOCTypeHash,
+	"sha256":             entity.IOCTypeHash,
+}
+
+var (
+	// iocDomainPattern accepts lower-cased host names with at least two labels
+	iocDomainPattern = regexp.MustCompile(`^([a-z0-9_]([a-z0-9_-]*[a-z0-9_])?\.)+[a-z0-9-]*[a-z0-9]$`)
+
+	// iocHashPattern accepts MD5, SHA-1 and SHA-256 digests in hex
+	iocHashPattern = regexp.MustCompile(`^([0-9a-f]{32}|[0-9a-f]{40}|[0-9a-f]{64})$`)
+
+	// stixComparison is one comparison of a STIX pattern, e.g. ipv4-addr:value = '198.51.100.1' or
+	// file:hashes.'SHA-256' = '...'
+	stixComparison = regexp.MustCompile(`(ipv4-addr|ipv6-addr|domain-name|url|file):(value|hashes\.(?:'[^']*'|[A-Za-z0-9-]+))\s*=\s*'((?:[^'\\]|\\.)*)'`)
+)
+
+// stixObjectTypes maps the STIX cyber-observable types of a pattern to IOC types
+var stixObjectTypes = map[string]string{
+	"ipv4-addr":   entity.IOCTypeIP,
+	"ipv6-addr":   entity.IOCTypeIP,
+	"domain-name": entity.IOCTypeDomain,
+	"url":         entity.IOCTypeURL,
+	"file":        entity.IOCTypeHash,
+}
+
+type iocUsecase struct {
+	// feeds are read on first use and re-read when their file changes
+	mu     sync.Mutex
+	feeds  map[string]*iocFeedState
+	index  *iocIndex
+	loaded bool
+}
+
+type iocFeedState struct {
+	feed       entity.IOCFeed
+	modTime    time.Time
+	indicators []*entity.IOC
+}
+
+// iocIndex looks up indicators by type and normalized value; CIDR indicators are checked apart, the most
+// specific network first
+type iocIndex struct {
+	byValue  map[string][]*entity.IOC
+	networks []iocNetwork
+}
+
+type iocNetwork struct {
+	prefix netip.Prefix
+	ioc    *entity.IOC
+}
+
+// stixBundle is the part of a STIX 2.1 bundle the IOC store reads
+type stixBundle struct {
+	Type    string `json:"type"`
+	Objects []struct {
+		Type         string `json:"type"`
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Pattern      string `json:"pattern"`
+		PatternType  string `json:"pattern_type"`
+		Confidence   *int   `json:"confidence"`
+		ValidUntil   string `json:"valid_until"`
+		Revoked      bool   `json:"revoked"`
+		CreatedByRef string `json:"created_by_ref"`
+	} `json:"objects"`
+}
+
+func NewIOCUsecase() domain.IOCUsecase {
+	return &iocUsecase{
+		feeds: map[string]*iocFeedState{},
+	}
+}
+
+// FetchStatus returns the feeds with their indicator counts, reading them first if this process has not yet
+func (u *iocUsecase) FetchStatus(ctx context.Context) (*entity.IOCStoreStatus, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.loadFeedsOnce(ctx)
+	return u.status(), nil
+}
+
+// ReloadFeeds re-reads every feed of IOC_FEED_FILES. A feed that cannot be read keeps its previous
+// indicators and reports the error.
+func (u *iocUsecase) ReloadFeeds(ctx context.Context) (*entity.IOCStoreStatus, error) {
+	if len(iocFeedPaths()) == 0 {
+		return nil, fmt.Errorf("invalid reload: IOC_FEED_FILES is not set")
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.reloadFeeds(ctx, true)
+	return u.status(), nil
+}
+
+// RunScheduledReload re-reads the feeds that changed since they were last read
+func (u *iocUsecase) RunScheduledReload(ctx context.Context) error {
+	if len(iocFeedPaths()) == 0 {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.reloadFeeds(ctx, false)
+	return nil
+}
+
+// LookupValue returns the live indicators matching an address, domain, URL or hash, its type guessed from
+// its form
+func (u *iocUsecase) LookupValue(ctx context.Context, value string) ([]entity.IOCMatch, error) {
+	value = strings.TrimSpace(value)
+	iocType := inferIOCType(value)
+	if iocType == "" {
+		return nil, fmt.Errorf("invalid value %q: not an address, domain, URL or hash", value)
+	}
+
+	index := u.loadIndex(ctx)
+	now := time.Now()
+
+	matches := []entity.IOCMatch{}
+	for _, ioc := range index.lookup(iocType, value, now) {
+		matches = append(matches, entity.IOCMatch{Observed: value, IOC: *ioc})
+	}
+	if iocType == entity.IOCTypeURL {
+		matches = append(matches, index.matchURLHost("", value, now)...)
+	}
+	return matches, nil
+}
+
+// MatchAlert returns the live indicators found in the source and destination addresses, the URL and the
+// syscheck hashes of an alert
+func (u *iocUsecase) MatchAlert(ctx context.Context, source []byte) []entity.IOCMatch {
+	alert, ok := decodeAlertSource(source)
+	if !ok {
+		return nil
+	}
+
+	index := u.loadIndex(ctx)
+	now := time.Now()
+
+	var matches []entity.IOCMatch
+	match := func(field string, iocType string, observed string) {
+		if observed == "" {
+			return
+		}
+		for _, ioc := range index.lookup(iocType, observed, now) {
+			matches = append(matches, entity.IOCMatch{Field: field, Observed: observed, IOC: *ioc})
+		}
+	}
+
+	match("data.srcip", entity.IOCTypeIP, alert.Data.SrcIP)
+	match("data.dstip", entity.IOCTypeIP, alert.Data.DstIP)
+	match("data.url", entity.IOCTypeURL, alert.Data.URL)
+	if alert.Data.URL != "" {
+		matches = append(matches, index.matchURLHost("data.url", alert.Data.URL, now)...)
+	}
+	match("syscheck.md5_after", entity.IOCTypeHash, alert.Syscheck.MD5After)
+	match("syscheck.sha1_after", entity.IOCTypeHash, alert.Syscheck.SHA1After)
+	match("syscheck.sha256_after", entity.IOCTypeHash, alert.Syscheck.SHA256After)
+
+	return matches
+}
+
+// EnrichHits returns the hits with an ioc block, the list of matching indicators, added to the source of
+// each one that matches. The given hits are not modified.
+func (u *iocUsecase) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	enriched := make([]*elastic.SearchHit, 0, len(hits))
+
+	for _, hit := range hits {
+		matches := u.MatchAlert(ctx, hit.Source)
+		if len(matches) == 0 {
+			enriched = append(enriched, hit)
+			continue
+		}
+		enriched = append(enriched, withSourceField(hit, "ioc", matches))
+	}
+
+	return enriched
+}
+
+// loadIndex returns the lookup index, reading the feeds the first time it is needed
+func (u *iocUsecase) loadIndex(ctx context.Context) *iocIndex {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.loadFeedsOnce(ctx)
+	if u.index == nil {
+		u.index = u.buildIndex()
+	}
+	return u.index
+}
+
+// loadFeedsOnce reads the feeds the first time this process needs them. Callers hold mu.
+func (u *iocUsecase) loadFeedsOnce(ctx context.Context) {
+	if u.loaded {
+		return
+	}
+	u.reloadFeeds(ctx, false)
+}
+
+// reloadFeeds reads each feed unless force is false and its file is unchanged. Callers hold mu.
+func (u *iocUsecase) reloadFeeds(ctx context.Context, force bool) {
+	log := logger.WithRequestID(ctx)
+	u.loaded = true
+
+	for _, path := range iocFeedPaths() {
+		state, ok := u.feeds[path]
+		if !ok {
+			state = &iocFeedState{feed: entity.IOCFeed{Path: path, Format: iocFeedFormat(path)}}
+			u.feeds[path] = state
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			log.WithError(err).WithField("path", path).Warn("[usecase - ioc - reloadFeeds]: Failed to read IOC feed, keeping its previous indicators")
+			state.feed.Error = err.Error()
+			continue
+		}
+		if !force && state.feed.LoadedAt != nil && info.ModTime().Equal(state.modTime) {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.WithError(err).WithField("path", path).Warn("[usecase - ioc - reloadFeeds]: Failed to read IOC feed, keeping its previous indicators")
+			state.feed.Error = err.Error()
+			continue
+		}
+
+		indicators, skipped, err := parseIOCFeed(path, content)
+		if err != nil {
+			log.WithError(err).WithField("path", path).Warn("[usecase - ioc - reloadFeeds]: IOC feed is invalid, keeping its previous indicators")
+			state.feed.Error = err.Error()
+			continue
+		}
+
+		loadedAt := time.Now().UTC()
+		state.indicators = indicators
+		state.modTime = info.ModTime()
+		state.feed.Indicators = len(indicators)
+		state.feed.Skipped = skipped
+		state.feed.LoadedAt = &loadedAt
+		state.feed.Error = ""
+		u.index = nil
+
+		log.WithField("path", path).WithField("indicators", len(indicators)).WithField("skipped", skipped).Info("[usecase - ioc - reloadFeeds]: IOC feed loaded")
+	}
+}
+
+// status describes the feeds in the order IOC_FEED_FILES lists them. Callers hold mu.
+func (u *iocUsecase) status() *entity.IOCStoreStatus {
+	now := time.Now()
+	status := &entity.IOCStoreStatus{
+		Feeds:     []entity.IOCFeed{},
+		ByType:    map[string]int{},
+		CheckedAt: now.UTC(),
+	}
+
+	for _, path := range iocFeedPaths() {
+		state, ok := u.feeds[path]
+		if !ok {
+			continue
+		}
+		status.Feeds = append(status.Feeds, state.feed)
+
+		for _, ioc := range state.indicators {
+			status.Indicators++
+			status.ByType[ioc.Type]++
+			if ioc.Expired(now) {
+				status.Expired++
+			}
+		}
+	}
+
+	return status
+}
+
+// buildIndex indexes the indicators of every feed, the most confident first. Callers hold mu.
+func (u *iocUsecase) buildIndex() *iocIndex {
+	index := &iocIndex{byValue: map[string][]*entity.IOC{}}
+
+	for _, path := range iocFeedPaths() {
+		state, ok := u.feeds[path]
+		if !ok {
+			continue
+		}
+
+		for _, ioc := range state.indicators {
+			if ioc.Type == entity.IOCTypeIP && strings.Contains(ioc.Value, "/") {
+				prefix, err := netip.ParsePrefix(ioc.Value)
+				if err == nil {
+					index.networks = append(index.networks, iocNetwork{prefix: prefix, ioc: ioc})
+				}
+				continue
+			}
+			key := ioc.Type + "|" + ioc.Value
+			index.byValue[key] = append(index.byValue[key], ioc)
+		}
+	}
+
+	for _, iocs := range index.byValue {
+		sort.SliceStable(iocs, func(i, j int) bool {
+			return iocs[i].Confidence > iocs[j].Confidence
+		})
+	}
+	sort.SliceStable(index.networks, func(i, j int) bool {
+		return index.networks[i].prefix.Bits() > index.networks[j].prefix.Bits()
+	})
+
+	return index
+}
+
+// lookup returns the live indicators of a type matching the observed value. Addresses also match the
+// networks containing them, and domains match indicators of any parent domain.
+func (index *iocIndex) lookup(iocType string, observed string, now time.Time) []*entity.IOC {
+	value, err := normalizeIOCValue(iocType, observed)
+	if err != nil {
+		return nil
+	}
+
+	var found []*entity.IOC
+	add := func(iocs ...*entity.IOC) {
+		for _, ioc := range iocs {
+			if !ioc.Expired(now) {
+				found = append(found, ioc)
+			}
+		}
+	}
+
+	switch iocType {
+	case entity.IOCTypeIP:
+		if strings.Contains(value, "/") {
+			break
+		}
+		add(index.byValue[iocType+"|"+value]...)
+
+		addr, _ := netip.ParseAddr(value)
+		for _, network := range index.networks {
+			if network.prefix.Contains(addr) {
+				add(network.ioc)
+			}
+		}
+	case entity.IOCTypeDomain:
+		for domain := value; strings.Contains(domain, "."); domain = domain[strings.Index(domain, ".")+1:] {
+			add(index.byValue[iocType+"|"+domain]...)
+		}
+	default:
+		add(index.byValue[iocType+"|"+value]...)
+	}
+
+	return found
+}
+
+// matchURLHost returns the domain and address indicators matching the host of a URL
+func (index *iocIndex) matchURLHost(field string, rawURL string, now time.Time) []entity.IOCMatch {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Hostname() == "" {
+		return nil
+	}
+	host := parsed.Hostname()
+
+	iocType := entity.IOCTypeDomain
+	if _, err := netip.ParseAddr(host); err == nil {
+		iocType = entity.IOCTypeIP
+	}
+
+	var matches []entity.IOCMatch
+	for _, ioc := range index.lookup(iocType, host, now) {
+		matches = append(matches, entity.IOCMatch{Field: field, Observed: host, IOC: *ioc})
+	}
+	return matches
+}
+
+// iocFeedPaths returns the feed files of IOC_FEED_FILES, a comma-separated list
+func iocFeedPaths() []string {
+	return cleanList(strings.Split(os.Getenv("IOC_FEED_FILES"), ","))
+}
+
+// iocFeedFormat chooses the format of a feed by its extension: .csv files are CSV, .json files are STIX
+// bundles and anything else is a plain list
+func iocFeedFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return entity.IOCFeedFormatCSV
+	case ".json":
+		return entity.IOCFeedFormatSTIX
+	default:
+		return entity.IOCFeedFormatList
+	}
+}
+
+// parseIOCFeed reads the indicators of a feed file. Entries that are not a valid indicator are skipped and
+// counted; only a file that cannot be read as a whole fails.
+func parseIOCFeed(path string, content []byte) ([]*entity.IOC, int, error) {
+	var (
+		indicators []*entity.IOC
+		skipped    int
+		err        error
+	)
+
+	switch iocFeedFormat(path) {
+	case entity.IOCFeedFormatCSV:
+		indicators, skipped, err = parseIOCFeedCSV(content, filepath.Base(path))
+	case entity.IOCFeedFormatSTIX:
+		indicators, skipped, err = parseIOCFeedSTIX(content, filepath.Base(path))
+	default:
+		indicators, skipped = parseIOCFeedList(content, filepath.Base(path))
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, ioc := range indicators {
+		ioc.Feed = path
+	}
+	return indicators, skipped, nil
+}
+
+// parseIOCFeedList reads one indicator per line, its type guessed from its form. Text after the first
+// whitespace and lines starting with # are ignored.
+func parseIOCFeedList(content []byte, source string) ([]*entity.IOC, int) {
+	indicators := []*entity.IOC{}
+	skipped := 0
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		ioc, err := newIOC(fields[0], "", source, defaultIOCConfidence, nil)
+		if err != nil {
+			skipped++
+			continue
+		}
+		indicators = append(indicators, ioc)
+	}
+
+	return indicators, skipped
+}
+
+// parseIOCFeedCSV reads a CSV feed with a header row. The value column is required; type, source,
+// confidence and expires_at are optional and other columns are ignored.
+func parseIOCFeedCSV(content []byte, defaultSource string) ([]*entity.IOC, int, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return []*entity.IOC{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columns["value"]; !ok {
+		return nil, 0, fmt.Errorf("the header has no value column")
+	}
+
+	value := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	indicators := []*entity.IOC{}
+	skipped := 0
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		source := value(record, "source")
+		if source == "" {
+			source = defaultSource
+		}
+
+		confidence := defaultIOCConfidence
+		if raw := value(record, "confidence"); raw != "" {
+			if confidence, err = strconv.Atoi(raw); err != nil {
+				skipped++
+				continue
+			}
+		}
+
+		var expiresAt *time.Time
+		if raw := value(record, "expires_at"); raw != "" {
+			expiry, ok := parseIOCExpiry(raw)
+			if !ok {
+				skipped++
+				continue
+			}
+			expiresAt = &expiry
+		}
+
+		ioc, err := newIOC(value(record, "value"), value(record, "type"), source, confidence, expiresAt)
+		if err != nil {
+			skipped++
+			continue
+		}
+		indicators = append(indicators, ioc)
+	}
+
+	return indicators, skipped, nil
+}
+
+// parseIOCFeedSTIX reads the indicators of a STIX 2.1 bundle. Each equality on an address, domain, URL or
+// file hash in a pattern becomes an indicator; patterns joining comparisons with AND or FOLLOWEDBY cannot be
+// matched against a single value and are skipped, as are revoked indicators.
+func parseIOCFeedSTIX(content []byte, defaultSource string) ([]*entity.IOC, int, error) {
+	var bundle stixBundle
+	if err := json.Unmarshal(content, &bundle); err != nil {
+		return nil, 0, err
+	}
+	if bundle.Type != "bundle" {
+		return nil, 0, fmt.Errorf("not a STIX bundle, type is %q", bundle.Type)
+	}
+
+	identities := map[string]string{}
+	for _, object := range bundle.Objects {
+		if object.Type == "identity" && object.Name != "" {
+			identities[object.ID] = object.Name
+		}
+	}
+
+	indicators := []*entity.IOC{}
+	skipped := 0
+
+	for _, object := range bundle.Objects {
+		if object.Type != "indicator" {
+			continue
+		}
+		if object.Revoked || (object.PatternType != "" && object.PatternType != "stix") ||
+			strings.Contains(object.Pattern, " AND ") || strings.Contains(object.Pattern, " FOLLOWEDBY ") {
+			skipped++
+			continue
+		}
+
+		source := identities[object.CreatedByRef]
+		if source == "" {
+			source = defaultSource
+		}
+
+		confidence := defaultIOCConfidence
+		if object.Confidence != nil {
+			confidence = *object.Confidence
+		}
+
+		var expiresAt *time.Time
+		if object.ValidUntil != "" {
+			expiry, ok := parseIOCExpiry(object.ValidUntil)
+			if !ok {
+				skipped++
+				continue
+			}
+			expiresAt = &expiry
+		}
+
+		comparisons := stixComparison.FindAllStringSubmatch(object.Pattern, -1)
+		if len(comparisons) == 0 {
+			skipped++
+			continue
+		}
+
+		for _, comparison := range comparisons {
+			value := strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(comparison[3])
+
+			ioc, err := newIOC(value, stixObjectTypes[comparison[1]], source, confidence, expiresAt)
+			if err != nil {
+				skipped++
+				continue
+			}
+			indicators = append(indicators, ioc)
+		}
+	}
+
+	return indicators, skipped, nil
+}
+
+// newIOC validates an indicator and normalizes its value. An empty type is guessed from the value.
+func newIOC(value string, iocType string, source string, confidence int, expiresAt *time.Time) (*entity.IOC, error) {
+	value = strings.TrimSpace(value)
+
+	if iocType == "" {
+		iocType = inferIOCType(value)
+	} else {
+		iocType = iocTypeAliases[strings.ToLower(strings.TrimSpace(iocType))]
+	}
+	if iocType == "" {
+		return nil, fmt.Errorf("unknown indicator type for %q", value)
+	}
+
+	if confidence < 0 || confidence > maxIOCConfidence {
+		return nil, fmt.Errorf("confidence %d must be between 0 and %d", confidence, maxIOCConfidence)
+	}
+
+	normalized, err := normalizeIOCValue(iocType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.IOC{
+		Type:       iocType,
+		Value:      normalized,
+		Source:     source,
+		Confidence: confidence,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// inferIOCType guesses the type of a value from its form, empty when it is not an indicator
+func inferIOCType(value string) string {
+	lower := strings.ToLower(value)
+	switch {
+	case value == "":
+		return ""
+	case strings.Contains(value, "://"):
+		return entity.IOCTypeURL
+	case iocHashPattern.MatchString(lower):
+		return entity.IOCTypeHash
+	}
+	if _, err := parseAssetNetwork(value); err == nil {
+		return entity.IOCTypeIP
+	}
+	if iocDomainPattern.MatchString(strings.TrimSuffix(lower, ".")) {
+		return entity.IOCTypeDomain
+	}
+	return ""
+}
+
+// normalizeIOCValue puts a value in the form indicators are indexed by, so feeds and events compare equal:
+// canonical addresses and networks, lower-case domains and hashes, and URLs with a lower-case scheme and host
+func normalizeIOCValue(iocType string, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch iocType {
+	case entity.IOCTypeIP:
+		prefix, err := parseAssetNetwork(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not an address or CIDR", value)
+		}
+		if prefix.IsSingleIP() {
+			return prefix.Addr().String(), nil
+		}
+		return prefix.String(), nil
+	case entity.IOCTypeDomain:
+		domain := strings.TrimSuffix(strings.ToLower(value), ".")
+		if !iocDomainPattern.MatchString(domain) {
+			return "", fmt.Errorf("%q is not a domain", value)
+		}
+		return domain, nil
+	case entity.IOCTypeURL:
+		parsed, err := url.Parse(value)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return "", fmt.Errorf("%q is not an absolute URL", value)
+		}
+		parsed.Scheme = strings.ToLower(parsed.Scheme)
+		parsed.Host = strings.ToLower(parsed.Host)
+		parsed.Fragment = ""
+		if parsed.Path == "/" {
+			parsed.Path = ""
+		}
+		return parsed.String(), nil
+	case entity.IOCTypeHash:
+		hash := strings.ToLower(value)
+		if !iocHashPattern.MatchString(hash) {
+			return "", fmt.Errorf("%q is not an MD5, SHA-1 or SHA-256 hash", value)
+		}
+		return hash, nil
+	default:
+		return "", fmt.Errorf("unknown indicator type %q", iocType)
+	}
+}
+
+// parseIOCExpiry reads an expiry as an RFC 3339 timestamp or a date, which expires at its start in UTC
+func parseIOCExpiry(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if expiry, err := time.Parse(layout, value); err == nil {
+			return expiry.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
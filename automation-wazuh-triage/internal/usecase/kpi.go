@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultKPIWindow is used when no window is requested
+	defaultKPIWindow = 30 * 24 * time.Hour
+
+	// defaultKPIBreakdownLimit bounds the rule, agent and analyst buckets, busiest first
+	defaultKPIBreakdownLimit = 50
+
+	// kpiUnknownKey groups closures whose agent or analyst is not known
+	kpiUnknownKey = "unknown"
+)
+
+type kpiUsecase struct {
+	wazuhEventRepo   domain.WazuhEventRepository
+	closedEventRepo  domain.ClosedEventRepository
+	triageActionRepo domain.TriageActionRepository
+}
+
+func NewKPIUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+) domain.KPIUsecase {
+	return &kpiUsecase{
+		wazuhEventRepo:   wazuhEventRepo,
+		closedEventRepo:  closedEventRepo,
+		triageActionRepo: triageActionRepo,
+	}
+}
+
+// kpiAccumulator sums the closures of one bucket until the means are computed
+type kpiAccumulator struct {
+	closures     int
+	autoClosed   int
+	manualClosed int
+	tttSum       float64
+	tttCount     int
+	ttrSum       float64
+	ttrCount     int
+}
+
+// FetchKPIs computes MTTT, MTTR, alert volume and the auto-close ratio over the events closed within the window.
+// MTTT runs from the alert timestamp to the first triage action (acknowledgement or closure), MTTR to the closure.
+func (u *kpiUsecase) FetchKPIs(ctx context.Context, window time.Duration) (*entity.KPIReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	if window <= 0 {
+		window = defaultKPIWindow
+	}
+
+	now := time.Now()
+	since := now.Add(-window)
+
+	closedEvents, err := u.closedEventRepo.FetchClosedEventsSince(ctx, since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - kpi - FetchKPIs]: Failed to fetch closed events")
+		return nil, err
+	}
+
+	actions, err := u.triageActionRepo.FetchTriageActionsForClosuresSince(ctx, since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - kpi - FetchKPIs]: Failed to fetch triage actions")
+		return nil, err
+	}
+
+	volumeByDay, err := u.wazuhEventRepo.CountEventsByDay(ctx, since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - kpi - FetchKPIs]: Failed to count alerts per day")
+		return nil, err
+	}
+	volumeByRule, err := u.wazuhEventRepo.CountEventsByField(ctx, "rule.id", since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - kpi - FetchKPIs]: Failed to count alerts per rule")
+		return nil, err
+	}
+	volumeByAgent, err := u.wazuhEventRepo.CountEventsByField(ctx, "agent.name", since)
+	if err != nil {
+		log.WithError(err).Error("[usecase - kpi - FetchKPIs]: Failed to count alerts per agent")
+		return nil, err
+	}
+
+	// Actions are ordered by time, so the first one seen per event is its first triage action
+	firstActionAt := map[string]time.Time{}
+	closedBy := map[string]string{}
+	for _, action := range actions {
+		if _, ok := firstActionAt[action.EventID]; !ok {
+			firstActionAt[action.EventID] = action.CreatedAt
+		}
+		if action.Action == entity.TriageActionClosed {
+			closedBy[action.EventID] = action.Actor
+		}
+	}
+
+	overall := &kpiAccumulator{}
+	byDay := map[string]*kpiAccumulator{}
+	byRule := map[string]*kpiAccumulator{}
+	byAgent := map[string]*kpiAccumulator{}
+	byAnalyst := map[string]*kpiAccumulator{}
+
+	for _, closedEvent := range closedEvents {
+		analyst := closedBy[closedEvent.EventID]
+		if analyst == "" {
+			analyst = kpiUnknownKey
+			if closedEvent.CloseType == entity.CloseTypeAuto {
+				analyst = entity.ActorAutoClose
+			}
+		}
+
+		agent := alertAgentName(closedEvent.RawEvent)
+		if agent == "" {
+			agent = kpiUnknownKey
+		}
+
+		ruleID := closedEvent.RuleID
+		if ruleID == "" {
+			ruleID = kpiUnknownKey
+		}
+
+		accumulators := []*kpiAccumulator{
+			overall,
+			kpiBucketFor(byDay, closedEvent.CloseAt.UTC().Format("2006-01-02")),
+			kpiBucketFor(byRule, ruleID),
+			kpiBucketFor(byAgent, agent),
+			kpiBucketFor(byAnalyst, analyst),
+		}
+
+		firedAt, hasAlertTime := alertTimestamp(closedEvent.RawEvent)
+
+		triagedAt := closedEvent.CloseAt
+		if actionAt, ok := firstActionAt[closedEvent.EventID]; ok && actionAt.Before(triagedAt) {
+			triagedAt = actionAt
+		}
+
+		for _, acc := range accumulators {
+			acc.closures++
+			if closedEvent.CloseType == entity.CloseTypeAuto {
+				acc.autoClosed++
+			} else {
+				acc.manualClosed++
+			}
+
+			if !hasAlertTime {
+				continue
+			}
+			if ttr := closedEvent.CloseAt.Sub(firedAt).Seconds(); ttr >= 0 {
+				acc.ttrSum += ttr
+				acc.ttrCount++
+			}
+			if ttt := triagedAt.Sub(firedAt).Seconds(); ttt >= 0 {
+				acc.tttSum += ttt
+				acc.tttCount++
+			}
+		}
+	}
+
+	var totalVolume int64
+	for _, count := range volumeByDay {
+		totalVolume += count
+	}
+
+	days := window.Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+
+	overallBucket := overall.bucket("overall")
+	overallBucket.AlertVolume = &totalVolume
+
+	limit := kpiBreakdownLimit()
+
+	return &entity.KPIReport{
+		Window:           window.String(),
+		Since:            since,
+		GeneratedAt:      now,
+		DailyAlertVolume: math.Round(float64(totalVolume)/days*100) / 100,
+		Overall:          overallBucket,
+		ByDay:            dayBuckets(byDay, volumeByDay),
+		ByRule:           rankedBuckets(byRule, volumeByRule, limit),
+		ByAgent:          rankedBuckets(byAgent, volumeByAgent, limit),
+		ByAnalyst:        rankedBuckets(byAnalyst, nil, limit),
+	}, nil
+}
+
+func (a *kpiAccumulator) bucket(key string) entity.KPIBucket {
+	bucket := entity.KPIBucket{
+		Key:          key,
+		Closures:     a.closures,
+		AutoClosed:   a.autoClosed,
+		ManualClosed: a.manualClosed,
+	}
+
+	if a.closures > 0 {
+		bucket.AutoCloseRatio = math.Round(float64(a.autoClosed)/float64(a.closures)*10000) / 10000
+	}
+	if a.tttCount > 0 {
+		mttt := math.Round(a.tttSum / float64(a.tttCount))
+		bucket.MTTTSeconds = &mttt
+	}
+	if a.ttrCount > 0 {
+		mttr := math.Round(a.ttrSum / float64(a.ttrCount))
+		bucket.MTTRSeconds = &mttr
+	}
+
+	return bucket
+}
+
+func kpiBucketFor(buckets map[string]*kpiAccumulator, key string) *kpiAccumulator {
+	if _, ok := buckets[key]; !ok {
+		buckets[key] = &kpiAccumulator{}
+	}
+	return buckets[key]
+}
+
+// dayBuckets returns one bucket per day that had alerts or closures, oldest first
+func dayBuckets(closures map[string]*kpiAccumulator, volumes map[string]int64) []entity.KPIBucket {
+	for day := range volumes {
+		kpiBucketFor(closures, day)
+	}
+
+	buckets := make([]entity.KPIBucket, 0, len(closures))
+	for day, acc := range closures {
+		bucket := acc.bucket(day)
+		volume := volumes[day]
+		bucket.AlertVolume = &volume
+		buckets = append(buckets, bucket)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Key < buckets[j].Key
+	})
+	return buckets
+}
+
+// rankedBuckets returns the busiest buckets by closures, then alert volume. A nil volumes map means the
+// dimension has no alert volume, as for analysts.
+func rankedBuckets(closures map[string]*kpiAccumulator, volumes map[string]int64, limit int) []entity.KPIBucket {
+	for key := range volumes {
+		kpiBucketFor(closures, key)
+	}
+
+	buckets := make([]entity.KPIBucket, 0, len(closures))
+	for key, acc := range closures {
+		bucket := acc.bucket(key)
+		if volumes != nil {
+			volume := volumes[key]
+			bucket.AlertVolume = &volume
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	volumeOf := func(bucket entity.KPIBucket) int64 {
+		if bucket.AlertVolume == nil {
+			return 0
+		}
+		return *bucket.AlertVolume
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Closures != buckets[j].Closures {
+			return buckets[i].Closures > buckets[j].Closures
+		}
+		if volumeOf(buckets[i]) != volumeOf(buckets[j]) {
+			return volumeOf(buckets[i]) > volumeOf(buckets[j])
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+
+	if len(buckets) > limit {
+		buckets = buckets[:limit]
+	}
+	return buckets
+}
+
+func kpiBreakdownLimit() int {
+	limit, err := strconv.Atoi(os.Getenv("KPI_BREAKDOWN_LIMIT"))
+	if err != nil || limit <= 0 {
+		return defaultKPIBreakdownLimit
+	}
+	return limit
+}
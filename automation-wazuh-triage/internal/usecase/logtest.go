@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// defaultLogtestRulesFile is the temporary rule file candidate rule XML is uploaded to
+	defaultLogtestRulesFile = "triage_logtest_candidate.xml"
+
+	// defaultLogtestLocation is used when neither the request nor the closed event carries a location
+	defaultLogtestLocation = "triage-logtest"
+
+	// maxLogtestSamples bounds the number of lines replayed in one request
+	maxLogtestSamples = 100
+)
+
+type logtestUsecase struct {
+	logtestRepo     domain.LogtestRepository
+	ruleFileRepo    domain.RuleFileRepository
+	closedEventRepo domain.ClosedEventRepository
+}
+
+func NewLogtestUsecase(
+	logtestRepo domain.LogtestRepository,
+	ruleFileRepo domain.RuleFileRepository,
+	closedEventRepo domain.ClosedEventRepository,
+) domain.LogtestUsecase {
+	return &logtestUsecase{
+		logtestRepo:     logtestRepo,
+		ruleFileRepo:    ruleFileRepo,
+		closedEventRepo: closedEventRepo,
+	}
+}
+
+// TestLogs replays the given log lines and closed event samples through the manager ruleset.
+// When candidate rule XML is given it is uploaded to a temporary rule file for the duration of
+// the test, so the lines are evaluated as if the candidate rules were deployed. Rule file pushes
+// wait until the file is removed again, so a manager restart cannot load it.
+func (u *logtestUsecase) TestLogs(ctx context.Context, request *model.LogtestRequest) ([]entity.LogtestResult, error) {
+	log := logger.WithRequestID(ctx)
+
+	samples, err := u.buildSamples(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("invalid logtest request: at least one log or closed event ID is required")
+	}
+	if len(samples) > maxLogtestSamples {
+		return nil, fmt.Errorf("invalid logtest request: at most %d samples are allowed", maxLogtestSamples)
+	}
+
+	if strings.TrimSpace(request.RuleXML) != "" {
+		var unlock func()
+		ctx, unlock = lockRuleFiles(ctx)
+		defer unlock()
+
+		filename := logtestRulesFile()
+		if err := u.checkCandidateRulesFile(ctx, filename); err != nil {
+			return nil, err
+		}
+		if err := u.uploadCandidateRules(ctx, filename, request.RuleXML); err != nil {
+			return nil, err
+		}
+
+		defer func() {
+			if err := u.ruleFileRepo.DeleteRuleFile(ctx, filename); err != nil {
+				log.WithError(err).WithField("filename", filename).Error("[usecase - logtest - TestLogs]: Failed to remove candidate rule file")
+			}
+		}()
+	}
+
+	// All samples share one session so the candidate ruleset is loaded once
+	var token string
+	results := make([]entity.LogtestResult, 0, len(samples))
+
+	for _, sample := range samples {
+		if sample.Log == "" {
+			results = append(results, entity.LogtestResult{
+				Source:        sample.Source,
+				ClosedEventID: sample.ClosedEventID,
+				Error:         "no full_log available for this event",
+			})
+			continue
+		}
+
+		result, sessionToken, err := u.logtestRepo.RunLogtest(ctx, token, sample)
+		if err != nil {
+			log.WithError(err).Error("[usecase - logtest - TestLogs]: Failed to run logtest")
+			u.endSession(ctx, token)
+			return nil, err
+		}
+
+		token = sessionToken
+		results = append(results, *result)
+	}
+
+	u.endSession(ctx, token)
+
+	log.WithField("samples", len(samples)).Info("[usecase - logtest - TestLogs]: Successfully replayed samples")
+	return results, nil
+}
+
+func (u *logtestUsecase) buildSamples(ctx context.Context, request *model.LogtestRequest) ([]entity.LogtestSample, error) {
+	location := request.Location
+	if location == "" {
+		location = defaultLogtestLocation
+	}
+
+	var samples []entity.LogtestSample
+
+	for _, line := range request.Logs {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		samples = append(samples, entity.LogtestSample{
+			Log:       line,
+			LogFormat: request.LogFormat,
+			Location:  location,
+			Source:    entity.LogtestSourceInput,
+		})
+	}
+
+	for _, id := range request.ClosedEventIDs {
+		closedEvent, err := u.closedEventRepo.FetchClosedEventByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if closedEvent == nil {
+			return nil, fmt.Errorf("closed event with ID %s not found", id)
+		}
+
+		fullLog, eventLocation := fullLogFromRawEvent(closedEvent.RawEvent)
+		if eventLocation == "" || request.Location != "" {
+			eventLocation = location
+		}
+
+		samples = append(samples, entity.LogtestSample{
+			Log:           fullLog,
+			LogFormat:     request.LogFormat,
+			Location:      eventLocation,
+			Source:        entity.LogtestSourceClosedEvent,
+			ClosedEventID: id,
+		})
+	}
+
+	return samples, nil
+}
+
+// checkCandidateRulesFile refuses a temporary rule file that would overwrite, and then delete, a file of the
+// ruleset: the suppression rules file or any file already on the manager
+func (u *logtestUsecase) checkCandidateRulesFile(ctx context.Context, filename string) error {
+	if filename == suppressionRulesFile() {
+		return fmt.Errorf("logtest rules file %s is the suppression rules file, set LOGTEST_RULES_FILE to an unused file name", filename)
+	}
+
+	_, exists, err := u.ruleFileRepo.GetRuleFile(ctx, filename)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("filename", filename).Error("[usecase - logtest - checkCandidateRulesFile]: Failed to check candidate rule file")
+		return err
+	}
+	if exists {
+		return fmt.Errorf("logtest rules file %s is already on the manager, set LOGTEST_RULES_FILE to an unused file name or remove the file", filename)
+	}
+
+	return nil
+}
+
+// uploadCandidateRules writes the candidate XML to the temporary rule file and makes sure the
+// manager accepts it. A rejected file is removed again so it cannot break the next restart.
+func (u *logtestUsecase) uploadCandidateRules(ctx context.Context, filename string, ruleXML string) error {
+	log := logger.WithRequestID(ctx).WithField("filename", filename)
+
+	if err := u.ruleFileRepo.UpdateRuleFile(ctx, filename, ruleXML); err != nil {
+		log.WithError(err).Warn("[usecase - logtest - uploadCandidateRules]: Failed to upload candidate rules")
+		return fmt.Errorf("candidate rule XML rejected by manager: %w", err)
+	}
+
+	if err := u.ruleFileRepo.ValidateConfiguration(ctx); err != nil {
+		log.WithError(err).Warn("[usecase - logtest - uploadCandidateRules]: Manager rejected candidate rules")
+
+		if deleteErr := u.ruleFileRepo.DeleteRuleFile(ctx, filename); deleteErr != nil {
+			log.WithError(deleteErr).Error("[usecase - logtest - uploadCandidateRules]: Failed to remove rejected candidate rule file")
+		}
+
+		return fmt.Errorf("candidate rule XML rejected by manager: %w", err)
+	}
+
+	return nil
+}
+
+func (u *logtestUsecase) endSession(ctx context.Context, token string) {
+	if token == "" {
+		return
+	}
+	// Failing to end a session is not fatal, the manager expires idle sessions on its own
+	_ = u.logtestRepo.EndLogtestSession(ctx, token)
+}
+
+// fullLogFromRawEvent extracts full_log and location from a stored search hit
+func fullLogFromRawEvent(rawEvent string) (string, string) {
+	var hit struct {
+		Source struct {
+			FullLog  string `json:"full_log"`
+			Location string `json:"location"`
+		} `json:"_source"`
+	}
+
+	if err := json.Unmarshal([]byte(rawEvent), &hit); err != nil {
+		return "", ""
+	}
+
+	return hit.Source.FullLog, hit.Source.Location
+}
+
+func logtestRulesFile() string {
+	if filename := os.Getenv("LOGTEST_RULES_FILE"); filename != "" {
+		return filename
+	}
+	return defaultLogtestRulesFile
+}
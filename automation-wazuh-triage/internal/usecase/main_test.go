@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/pkg/logger"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	logger.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
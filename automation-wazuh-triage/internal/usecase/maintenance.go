@@ -0,0 +1,655 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/cron"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Timezones of maintenance windows must load in containers without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// maxMaintenanceSpan bounds a one-off maintenance window
+	maxMaintenanceSpan = 30 * 24 * time.Hour
+
+	// maxMaintenanceDuration bounds each occurrence of a recurring maintenance window
+	maxMaintenanceDuration = 7 * 24 * time.Hour
+
+	// maintenanceGroupCacheTTL is how long the agents of a Wazuh group are reused before asking the API again
+	maintenanceGroupCacheTTL = 5 * time.Minute
+
+	// maintenanceGroupRetryDelay is how long a failed group lookup waits before asking the API again
+	maintenanceGroupRetryDelay = 30 * time.Second
+
+	defaultMaintenanceAlertLimit = 100
+	maxMaintenanceAlertLimit     = 1000
+
+	// maxMaintenanceOccurrences bounds the runs of one recurring window listed for a period
+	maxMaintenanceOccurrences = 500
+)
+
+// maintenancePolicyRank orders the policies when several windows cover an alert, the strongest first
+var maintenancePolicyRank = map[string]int{
+	entity.MaintenancePolicyAutoClose:     0,
+	entity.MaintenancePolicyLowerPriority: 1,
+	entity.MaintenancePolicyKeep:          2,
+}
+
+type maintenanceUsecase struct {
+	maintenanceRepo  domain.MaintenanceRepository
+	agentRepo        domain.AgentRepository
+	closedEventRepo  domain.ClosedEventRepository
+	triageActionRepo domain.TriageActionRepository
+	guardrailUsecase domain.GuardrailUsecase
+
+	// groupAgents caches the agent IDs of each Wazuh group
+	mu          sync.Mutex
+	groupAgents map[string]maintenanceGroup
+}
+
+type maintenanceGroup struct {
+	agentIDs  map[string]bool
+	expiresAt time.Time
+}
+
+func NewMaintenanceUsecase(
+	maintenanceRepo domain.MaintenanceRepository,
+	agentRepo domain.AgentRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+	guardrailUsecase domain.GuardrailUsecase,
+) domain.MaintenanceUsecase {
+	return &maintenanceUsecase{
+		maintenanceRepo:  maintenanceRepo,
+		agentRepo:        agentRepo,
+		closedEventRepo:  closedEventRepo,
+		triageActionRepo: triageActionRepo,
+		guardrailUsecase: guardrailUsecase,
+		groupAgents:      map[string]maintenanceGroup{},
+	}
+}
+
+func (u *maintenanceUsecase) CreateWindow(ctx context.Context, request *model.MaintenanceWindowRequest) (*entity.MaintenanceWindow, error) {
+	log := logger.WithRequestID(ctx)
+
+	window, err := newMaintenanceWindow(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if window.EndsAt != nil && !window.EndsAt.After(now) {
+		return nil, fmt.Errorf("invalid maintenance window: ends_at is in the past")
+	}
+
+	window.CreatedBy = window.UpdatedBy
+	window.CreatedAt = now
+	window.UpdatedAt = now
+
+	if err := u.maintenanceRepo.SaveMaintenanceWindow(ctx, window); err != nil {
+		log.WithError(err).Error("[usecase - maintenance - CreateWindow]: Failed to save maintenance window")
+		return nil, err
+	}
+
+	decorateMaintenanceWindow(window, now)
+
+	log.WithField("window_id", window.ID).WithField("name", window.Name).WithField("policy", window.Policy).WithField("analyst", window.CreatedBy).Info("[usecase - maintenance - CreateWindow]: Maintenance window created")
+	return window, nil
+}
+
+// UpdateWindow replaces a window. Alerts it already handled keep their outcome.
+func (u *maintenanceUsecase) UpdateWindow(ctx context.Context, id int, request *model.MaintenanceWindowRequest) (*entity.MaintenanceWindow, error) {
+	log := logger.WithRequestID(ctx)
+
+	window, err := newMaintenanceWindow(request)
+	if err != nil {
+		return nil, err
+	}
+
+	window.ID = id
+	window.UpdatedAt = time.Now().UTC()
+
+	if err := u.maintenanceRepo.UpdateMaintenanceWindow(ctx, window); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("maintenance window with ID %d not found", id)
+		}
+		log.WithError(err).WithField("window_id", id).Error("[usecase - maintenance - UpdateWindow]: Failed to update maintenance window")
+		return nil, err
+	}
+
+	log.WithField("window_id", id).WithField("policy", window.Policy).WithField("enabled", window.Enabled).WithField("analyst", window.UpdatedBy).Info("[usecase - maintenance - UpdateWindow]: Maintenance window updated")
+
+	updated, _, err := u.FetchWindowByID(ctx, id, 0)
+	return updated, err
+}
+
+func (u *maintenanceUsecase) FetchWindows(ctx context.Context, activeOnly bool) ([]*entity.MaintenanceWindow, error) {
+	windows, err := u.maintenanceRepo.FetchMaintenanceWindows(ctx, activeOnly)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - maintenance - FetchWindows]: Failed to fetch maintenance windows")
+		return nil, err
+	}
+
+	now := time.Now()
+	result := []*entity.MaintenanceWindow{}
+
+	for _, window := range windows {
+		decorateMaintenanceWindow(window, now)
+		if activeOnly && !window.Active {
+			continue
+		}
+		result = append(result, window)
+	}
+
+	return result, nil
+}
+
+// FetchWindowByID returns a window with the alerts it tagged, newest first
+func (u *maintenanceUsecase) FetchWindowByID(ctx context.Context, id int, limit int) (*entity.MaintenanceWindow, []*entity.MaintenanceAlert, error) {
+	log := logger.WithRequestID(ctx)
+
+	window, err := u.maintenanceRepo.FetchMaintenanceWindowByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("window_id", id).Error("[usecase - maintenance - FetchWindowByID]: Failed to fetch maintenance window")
+		return nil, nil, err
+	}
+	if window == nil {
+		return nil, nil, fmt.Errorf("maintenance window with ID %d not found", id)
+	}
+
+	decorateMaintenanceWindow(window, time.Now())
+
+	if limit <= 0 {
+		limit = defaultMaintenanceAlertLimit
+	}
+	if limit > maxMaintenanceAlertLimit {
+		limit = maxMaintenanceAlertLimit
+	}
+
+	alerts, err := u.maintenanceRepo.FetchMaintenanceAlerts(ctx, id, limit)
+	if err != nil {
+		log.WithError(err).WithField("window_id", id).Error("[usecase - maintenance - FetchWindowByID]: Failed to fetch maintenance alerts")
+		return nil, nil, err
+	}
+
+	if alerts == nil {
+		alerts = []*entity.MaintenanceAlert{}
+	}
+	return window, alerts, nil
+}
+
+// ApplyMaintenance records every alert that fired on an agent during an enabled window and applies the
+// window's policy. Closed alerts are dropped; the others are returned tagged with the window, and alerts no
+// window covers are returned unchanged. It also returns the number of alerts tagged in this run.
+func (u *maintenanceUsecase) ApplyMaintenance(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, int, error) {
+	log := logger.WithRequestID(ctx)
+
+	windows, err := u.maintenanceRepo.FetchMaintenanceWindows(ctx, true)
+	if err != nil {
+		log.WithError(err).Error("[usecase - maintenance - ApplyMaintenance]: Failed to fetch maintenance windows")
+		return nil, 0, err
+	}
+	if len(windows) == 0 {
+		return hits, 0, nil
+	}
+
+	windowsByID := make(map[int]*entity.MaintenanceWindow, len(windows))
+	for _, window := range windows {
+		windowsByID[window.ID] = window
+	}
+
+	remaining := make([]*elastic.SearchHit, 0, len(hits))
+	tagged := 0
+
+	for _, hit := range hits {
+		var securityEvent entity.WazuhSecurityEvent
+		source, ok := decodeAlertSource(hit.Source)
+		if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || securityEvent.Rule == nil || !ok {
+			remaining = append(remaining, hit)
+			continue
+		}
+		eventID := string(securityEvent.ID)
+
+		// An alert seen again keeps the outcome it got the first time
+		recorded, err := u.maintenanceRepo.FetchMaintenanceAlertByEventID(ctx, eventID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if recorded != nil {
+			if recorded.Outcome != entity.MaintenanceOutcomeClosed {
+				remaining = append(remaining, tagMaintenanceHit(hit, recorded, windowsByID[recorded.WindowID]))
+			}
+			continue
+		}
+
+		firedAt, ok := parseAlertTimestamp(source.Timestamp)
+		if !ok {
+			firedAt = time.Now()
+		}
+
+		window := u.windowForAlert(ctx, windows, source.Agent.ID, source.Agent.Name, firedAt)
+		if window == nil {
+			remaining = append(remaining, hit)
+			continue
+		}
+
+		alert, err := u.applyPolicy(ctx, window, hit, &securityEvent, source, firedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		tagged++
+
+		if alert.Outcome != entity.MaintenanceOutcomeClosed {
+			remaining = append(remaining, tagMaintenanceHit(hit, alert, window))
+		}
+	}
+
+	if tagged > 0 {
+		log.WithField("tagged", tagged).WithField("alerts", len(hits)).Info("[usecase - maintenance - ApplyMaintenance]: Tagged alerts fired during maintenance")
+	}
+
+	return remaining, tagged, nil
+}
+
+// FetchAgentOccurrences returns the runs of the enabled windows covering an agent that overlap [from, to],
+// oldest first. Group membership is read as it is now, not as it was during each run.
+func (u *maintenanceUsecase) FetchAgentOccurrences(ctx context.Context, agentID string, agentName string, from time.Time, to time.Time) ([]entity.MaintenanceOccurrence, error) {
+	windows, err := u.maintenanceRepo.FetchMaintenanceWindows(ctx, true)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - maintenance - FetchAgentOccurrences]: Failed to fetch maintenance windows")
+		return nil, err
+	}
+
+	occurrences := []entity.MaintenanceOccurrence{}
+
+	for _, window := range windows {
+		if !u.coversAgent(ctx, window, agentID, agentName) {
+			continue
+		}
+
+		occurrence := entity.MaintenanceOccurrence{
+			WindowID: window.ID,
+			Name:     window.Name,
+			Policy:   window.Policy,
+		}
+
+		if window.StartsAt != nil && window.EndsAt != nil {
+			if window.StartsAt.After(to) || !window.EndsAt.After(from) {
+				continue
+			}
+			occurrence.StartsAt = window.StartsAt.UTC()
+			occurrence.EndsAt = window.EndsAt.UTC()
+			occurrences = append(occurrences, occurrence)
+			continue
+		}
+
+		schedule, duration, location, ok := maintenanceSchedule(window)
+		if !ok {
+			continue
+		}
+		occurrence.Recurring = true
+
+		// The first run that can overlap the period started at most one duration before it
+		start := schedule.Next(from.In(location).Add(-duration))
+		for count := 0; !start.IsZero() && !start.After(to) && count < maxMaintenanceOccurrences; count++ {
+			occurrence.StartsAt = start.UTC()
+			occurrence.EndsAt = start.Add(duration).UTC()
+			if occurrence.EndsAt.After(from) {
+				occurrences = append(occurrences, occurrence)
+			}
+			start = schedule.Next(start)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if !occurrences[i].StartsAt.Equal(occurrences[j].StartsAt) {
+			return occurrences[i].StartsAt.Before(occurrences[j].StartsAt)
+		}
+		return occurrences[i].WindowID < occurrences[j].WindowID
+	})
+
+	return occurrences, nil
+}
+
+// windowForAlert returns the window with the strongest policy covering the agent when the alert fired, or nil
+func (u *maintenanceUsecase) windowForAlert(ctx context.Context, windows []*entity.MaintenanceWindow, agentID string, agentName string, firedAt time.Time) *entity.MaintenanceWindow {
+	var matched *entity.MaintenanceWindow
+
+	for _, window := range windows {
+		if matched != nil && maintenancePolicyRank[window.Policy] >= maintenancePolicyRank[matched.Policy] {
+			continue
+		}
+		if _, _, active := maintenanceOccurrence(window, firedAt); !active {
+			continue
+		}
+		if u.coversAgent(ctx, window, agentID, agentName) {
+			matched = window
+		}
+	}
+
+	return matched
+}
+
+// coversAgent reports whether the agent is listed by ID, matches a name glob or belongs to a group of the window
+func (u *maintenanceUsecase) coversAgent(ctx context.Context, window *entity.MaintenanceWindow, agentID string, agentName string) bool {
+	if agentID != "" && containsString(window.AgentIDs, agentID) {
+		return true
+	}
+
+	for _, pattern := range window.AgentNames {
+		if matched, _ := path.Match(pattern, agentName); matched && agentName != "" {
+			return true
+		}
+	}
+
+	for _, group := range window.AgentGroups {
+		if agentID != "" && u.fetchGroupAgents(ctx, group)[agentID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fetchGroupAgents returns the agent IDs of a Wazuh group, cached for a few minutes. When the Wazuh API cannot
+// be reached the last known agents are used, or none, so alerts are triaged as usual rather than held up.
+func (u *maintenanceUsecase) fetchGroupAgents(ctx context.Context, group string) map[string]bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	cached, ok := u.groupAgents[group]
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.agentIDs
+	}
+
+	agents, err := u.agentRepo.FetchAgentsByGroup(ctx, group)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("group", group).Warn("[usecase - maintenance - fetchGroupAgents]: Failed to fetch agents of group, using the last known agents")
+		if !ok {
+			cached.agentIDs = map[string]bool{}
+		}
+		cached.expiresAt = time.Now().Add(maintenanceGroupRetryDelay)
+		u.groupAgents[group] = cached
+		return cached.agentIDs
+	}
+
+	agentIDs := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		agentIDs[agent.ID] = true
+	}
+
+	u.groupAgents[group] = maintenanceGroup{agentIDs: agentIDs, expiresAt: time.Now().Add(maintenanceGroupCacheTTL)}
+	return agentIDs
+}
+
+// applyPolicy closes, lowers or keeps one alert as the window says and records what was done
+func (u *maintenanceUsecase) applyPolicy(ctx context.Context, window *entity.MaintenanceWindow, hit *elastic.SearchHit, securityEvent *entity.WazuhSecurityEvent, source alertSource, firedAt time.Time) (*entity.MaintenanceAlert, error) {
+	log := logger.WithRequestID(ctx)
+	eventID := string(securityEvent.ID)
+
+	alert := &entity.MaintenanceAlert{
+		WindowID:  window.ID,
+		EventID:   eventID,
+		RuleID:    securityEvent.Rule.ID,
+		RuleLevel: securityEvent.Rule.Level,
+		AgentID:   source.Agent.ID,
+		AgentName: source.Agent.Name,
+		Policy:    window.Policy,
+		AlertAt:   firedAt.UTC(),
+		TaggedAt:  time.Now(),
+	}
+
+	switch window.Policy {
+	case entity.MaintenancePolicyLowerPriority:
+		alert.Outcome = entity.MaintenanceOutcomeLowered
+	case entity.MaintenancePolicyAutoClose:
+		if err := u.closeAlert(ctx, window, alert, hit, securityEvent); err != nil {
+			return nil, err
+		}
+	default:
+		alert.Outcome = entity.MaintenanceOutcomeKept
+	}
+
+	if _, err := u.maintenanceRepo.SaveMaintenanceAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+
+	log.WithField("event_id", eventID).WithField("window_id", window.ID).WithField("outcome", alert.Outcome).Debug("[usecase - maintenance - applyPolicy]: Alert fired during maintenance")
+	return alert, nil
+}
+
+// closeAlert auto-closes an alert unless a guardrail blocks it, in which case the alert is kept in the queue
+func (u *maintenanceUsecase) closeAlert(ctx context.Context, window *entity.MaintenanceWindow, alert *entity.MaintenanceAlert, hit *elastic.SearchHit, securityEvent *entity.WazuhSecurityEvent) error {
+	log := logger.WithRequestID(ctx)
+
+	existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, alert.EventID)
+	if err != nil {
+		log.WithError(err).WithField("event_id", alert.EventID).Error("[usecase - maintenance - closeAlert]: Failed to check existing closed event")
+		return err
+	}
+	if existingClosedEvent != nil {
+		alert.Outcome = entity.MaintenanceOutcomeClosed
+		alert.ClosedEventID = existingClosedEvent.ID
+		return nil
+	}
+
+	trip, err := u.guardrailUsecase.CheckAutoClose(ctx, alert.EventID, securityEvent.Rule, hit.Source)
+	if err != nil {
+		return err
+	}
+	if trip != nil {
+		alert.Outcome = entity.MaintenanceOutcomeKept
+		alert.Detail = fmt.Sprintf("auto-close blocked by guardrail %s: %s", trip.Guardrail, trip.Detail)
+		return nil
+	}
+
+	hitJSON, err := json.Marshal(hit)
+	if err != nil {
+		return err
+	}
+
+	closedEvent := &entity.ClosedEvent{
+		EventID:   alert.EventID,
+		RuleID:    alert.RuleID,
+		RawEvent:  string(hitJSON),
+		Reason:    fmt.Sprintf("maintenance window #%d: %s", window.ID, window.Name),
+		Status:    "closed",
+		CloseType: entity.CloseTypeAuto,
+		CloseAt:   time.Now(),
+	}
+	if err := u.closedEventRepo.SaveClosedEvent(ctx, closedEvent); err != nil {
+		log.WithError(err).WithField("event_id", alert.EventID).Error("[usecase - maintenance - closeAlert]: Failed to close alert")
+		return err
+	}
+
+	triageAction := newTriageAction(closedEvent.EventID, closedEvent.RuleID, closedEvent.RawEvent, entity.TriageActionClosed, entity.ActorMaintenance)
+	if err := u.triageActionRepo.SaveTriageAction(ctx, triageAction); err != nil {
+		log.WithError(err).WithField("event_id", alert.EventID).Warn("[usecase - maintenance - closeAlert]: Failed to record triage action")
+	}
+
+	alert.Outcome = entity.MaintenanceOutcomeClosed
+	alert.ClosedEventID = closedEvent.ID
+	return nil
+}
+
+// newMaintenanceWindow validates a request into a window
+func newMaintenanceWindow(request *model.MaintenanceWindowRequest) (*entity.MaintenanceWindow, error) {
+	window := &entity.MaintenanceWindow{
+		Name:        strings.TrimSpace(request.Name),
+		Description: strings.TrimSpace(request.Description),
+		Cron:        strings.Join(strings.Fields(request.Cron), " "),
+		Duration:    strings.TrimSpace(request.Duration),
+		Timezone:    strings.TrimSpace(request.Timezone),
+		AgentIDs:    cleanList(request.AgentIDs),
+		AgentGroups: cleanList(request.AgentGroups),
+		AgentNames:  cleanList(request.AgentNames),
+		Policy:      strings.TrimSpace(request.Policy),
+		Enabled:     request.Enabled == nil || *request.Enabled,
+		UpdatedBy:   strings.TrimSpace(request.Analyst),
+	}
+
+	if window.UpdatedBy == "" {
+		return nil, fmt.Errorf("invalid maintenance window: analyst is required")
+	}
+	if window.Name == "" {
+		return nil, fmt.Errorf("invalid maintenance window: name is required")
+	}
+	if _, ok := maintenancePolicyRank[window.Policy]; !ok {
+		return nil, fmt.Errorf("invalid maintenance window: policy must be %s, %s or %s", entity.MaintenancePolicyAutoClose, entity.MaintenancePolicyLowerPriority, entity.MaintenancePolicyKeep)
+	}
+	if len(window.AgentIDs) == 0 && len(window.AgentGroups) == 0 && len(window.AgentNames) == 0 {
+		return nil, fmt.Errorf("invalid maintenance window: at least one of agent_ids, agent_groups and agent_names is required")
+	}
+	for _, pattern := range window.AgentNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid maintenance window: bad agent name glob %q", pattern)
+		}
+	}
+
+	if window.Timezone == "" {
+		window.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(window.Timezone); err != nil {
+		return nil, fmt.Errorf("invalid maintenance window: unknown timezone %q", window.Timezone)
+	}
+
+	oneOff := request.StartsAt != nil || request.EndsAt != nil
+	recurring := window.Cron != "" || window.Duration != ""
+
+	switch {
+	case oneOff && recurring:
+		return nil, fmt.Errorf("invalid maintenance window: give starts_at and ends_at, or cron and duration, not both")
+	case oneOff:
+		if request.StartsAt == nil || request.EndsAt == nil {
+			return nil, fmt.Errorf("invalid maintenance window: starts_at and ends_at are both required")
+		}
+		startsAt, endsAt := request.StartsAt.UTC(), request.EndsAt.UTC()
+		if !endsAt.After(startsAt) {
+			return nil, fmt.Errorf("invalid maintenance window: ends_at must be after starts_at")
+		}
+		if endsAt.Sub(startsAt) > maxMaintenanceSpan {
+			return nil, fmt.Errorf("invalid maintenance window: a one-off window may last at most %s", maxMaintenanceSpan)
+		}
+		window.StartsAt, window.EndsAt = &startsAt, &endsAt
+	case recurring:
+		if window.Cron == "" || window.Duration == "" {
+			return nil, fmt.Errorf("invalid maintenance window: cron and duration are both required")
+		}
+		schedule, err := cron.Parse(window.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance window: %w", err)
+		}
+		if schedule.Next(time.Now()).IsZero() {
+			return nil, fmt.Errorf("invalid maintenance window: cron %q never runs", window.Cron)
+		}
+		duration, err := time.ParseDuration(window.Duration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid maintenance window: duration must be a positive duration such as 4h")
+		}
+		if duration > maxMaintenanceDuration {
+			return nil, fmt.Errorf("invalid maintenance window: each occurrence may last at most %s", maxMaintenanceDuration)
+		}
+	default:
+		return nil, fmt.Errorf("invalid maintenance window: starts_at and ends_at, or cron and duration, are required")
+	}
+
+	return window, nil
+}
+
+// maintenanceOccurrence returns the start and end of the window's occurrence running at t, and false when
+// none is. A recurring occurrence runs when the last cron run before t is less than a duration ago.
+func maintenanceOccurrence(window *entity.MaintenanceWindow, t time.Time) (time.Time, time.Time, bool) {
+	if window.StartsAt != nil && window.EndsAt != nil {
+		if !t.Before(*window.StartsAt) && t.Before(*window.EndsAt) {
+			return *window.StartsAt, *window.EndsAt, true
+		}
+		return time.Time{}, time.Time{}, false
+	}
+
+	schedule, duration, location, ok := maintenanceSchedule(window)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := schedule.Next(t.In(location).Add(-duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(duration), true
+}
+
+// maintenanceSchedule parses the cron, duration and timezone of a recurring window
+func maintenanceSchedule(window *entity.MaintenanceWindow) (*cron.Schedule, time.Duration, *time.Location, bool) {
+	schedule, err := cron.Parse(window.Cron)
+	if err != nil {
+		return nil, 0, nil, false
+	}
+	duration, err := time.ParseDuration(window.Duration)
+	if err != nil || duration <= 0 {
+		return nil, 0, nil, false
+	}
+	location, err := time.LoadLocation(window.Timezone)
+	if err != nil {
+		return nil, 0, nil, false
+	}
+	return schedule, duration, location, true
+}
+
+// decorateMaintenanceWindow fills whether the window is running at now, when that occurrence ends and when
+// the next one starts
+func decorateMaintenanceWindow(window *entity.MaintenanceWindow, now time.Time) {
+	if start, end, active := maintenanceOccurrence(window, now); active {
+		window.Active = window.Enabled
+		if window.Enabled {
+			window.CurrentEnd = &end
+		}
+		now = start
+	}
+
+	if !window.Enabled {
+		return
+	}
+
+	if window.StartsAt != nil {
+		if window.StartsAt.After(now) {
+			window.NextStart = window.StartsAt
+		}
+		return
+	}
+
+	if schedule, _, location, ok := maintenanceSchedule(window); ok {
+		if next := schedule.Next(now.In(location)); !next.IsZero() {
+			window.NextStart = &next
+		}
+	}
+}
+
+// tagMaintenanceHit returns a copy of the hit whose alert carries the window and what it did with the alert,
+// so cases and closures keep the tag
+func tagMaintenanceHit(hit *elastic.SearchHit, alert *entity.MaintenanceAlert, window *entity.MaintenanceWindow) *elastic.SearchHit {
+	tag := map[string]interface{}{
+		"window_id": alert.WindowID,
+		"policy":    alert.Policy,
+		"outcome":   alert.Outcome,
+	}
+	if window != nil {
+		tag["name"] = window.Name
+	}
+
+	return withSourceField(hit, "maintenance", tag)
+}
@@ -0,0 +1,362 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// mitreKillChain is the kill chain name of the enterprise tactics in technique phases
+	mitreKillChain = "mitre-attack"
+
+	// mitreEnterpriseMatrix is the external ID of the enterprise matrix, whose tactic order the report follows
+	mitreEnterpriseMatrix = "enterprise-attack"
+)
+
+var mitreTechniquePattern = regexp.MustCompile(`^T\d{4}(\.\d{3})?$`)
+
+type mitreUsecase struct {
+	// the bundle is read on first use and re-read when its file changes
+	mu         sync.Mutex
+	status     entity.MitreMatrixStatus
+	modTime    time.Time
+	matrix     *entity.MitreMatrix
+	techniques map[string]*entity.MitreTechnique
+	loaded     bool
+}
+
+// attackObject is the part of an ATT&CK STIX object the matrix is built from
+type attackObject struct {
+	Type               string   `json:"type"`
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	ShortName          string   `json:"x_mitre_shortname"`
+	Version            string   `json:"x_mitre_version"`
+	IsSubtechnique     bool     `json:"x_mitre_is_subtechnique"`
+	Deprecated         bool     `json:"x_mitre_deprecated"`
+	Revoked            bool     `json:"revoked"`
+	TacticRefs         []string `json:"tactic_refs"`
+	ExternalReferences []struct {
+		SourceName string `json:"source_name"`
+		ExternalID string `json:"external_id"`
+		URL        string `json:"url"`
+	} `json:"external_references"`
+	KillChainPhases []struct {
+		KillChainName string `json:"kill_chain_name"`
+		PhaseName     string `json:"phase_name"`
+	} `json:"kill_chain_phases"`
+}
+
+func NewMitreUsecase() domain.MitreUsecase {
+	return &mitreUsecase{}
+}
+
+// FetchStatus describes the loaded bundle, reading it first if this process has not yet
+func (u *mitreUsecase) FetchStatus(ctx context.Context) (*entity.MitreMatrixStatus, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.loadOnce(ctx)
+	status := u.status
+	return &status, nil
+}
+
+// ReloadMatrix re-reads MITRE_ATTACK_FILE. A bundle that cannot be read leaves the previous one loaded and
+// reports the error.
+func (u *mitreUsecase) ReloadMatrix(ctx context.Context) (*entity.MitreMatrixStatus, error) {
+	if mitreAttackPath() == "" {
+		return nil, fmt.Errorf("invalid reload: MITRE_ATTACK_FILE is not set")
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.reload(ctx, true)
+	status := u.status
+	return &status, nil
+}
+
+// RunScheduledReload re-reads the bundle when its file changed
+func (u *mitreUsecase) RunScheduledReload(ctx context.Context) error {
+	if mitreAttackPath() == "" {
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.reload(ctx, false)
+	return nil
+}
+
+// FetchMatrix returns the tactics and techniques of the loaded bundle
+func (u *mitreUsecase) FetchMatrix(ctx context.Context) (*entity.MitreMatrix, error) {
+	matrix, _ := u.load(ctx)
+	if matrix == nil {
+		return nil, fmt.Errorf("invalid request: no MITRE ATT&CK bundle is loaded, set MITRE_ATTACK_FILE")
+	}
+	return matrix, nil
+}
+
+func (u *mitreUsecase) FetchTechniqueByID(ctx context.Context, id string) (*entity.MitreTechnique, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !mitreTechniquePattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid technique ID %q, expected T1234 or T1234.001", id)
+	}
+
+	_, techniques := u.load(ctx)
+	technique, ok := techniques[id]
+	if !ok {
+		return nil, fmt.Errorf("technique %s not found", id)
+	}
+
+	resolved := *technique
+	return &resolved, nil
+}
+
+// ResolveTechniques returns the techniques of the given IDs that the bundle knows, in the given order
+func (u *mitreUsecase) ResolveTechniques(ctx context.Context, ids []string) []entity.MitreTechnique {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, techniques := u.load(ctx)
+
+	var resolved []entity.MitreTechnique
+	seen := map[string]bool{}
+	for _, id := range ids {
+		id = strings.ToUpper(strings.TrimSpace(id))
+		technique, ok := techniques[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		resolved = append(resolved, *technique)
+	}
+	return resolved
+}
+
+// EnrichHits returns the hits with a mitre block, the techniques of rule.mitre.id with their names and
+// tactics, added to the source of each one whose techniques the bundle knows. The given hits are not modified.
+func (u *mitreUsecase) EnrichHits(ctx context.Context, hits []*elastic.SearchHit) []*elastic.SearchHit {
+	enriched := make([]*elastic.SearchHit, 0, len(hits))
+
+	for _, hit := range hits {
+		alert, ok := decodeAlertSource(hit.Source)
+		if !ok {
+			enriched = append(enriched, hit)
+			continue
+		}
+
+		techniques := u.ResolveTechniques(ctx, alert.Rule.Mitre.ID)
+		if len(techniques) == 0 {
+			enriched = append(enriched, hit)
+			continue
+		}
+		enriched = append(enriched, withSourceField(hit, "mitre", techniques))
+	}
+
+	return enriched
+}
+
+// load returns the matrix and techniques by ID, reading the bundle the first time it is needed
+func (u *mitreUsecase) load(ctx context.Context) (*entity.MitreMatrix, map[string]*entity.MitreTechnique) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.loadOnce(ctx)
+	return u.matrix, u.techniques
+}
+
+// loadOnce reads the bundle the first time this process needs it. Callers hold mu.
+func (u *mitreUsecase) loadOnce(ctx context.Context) {
+	if u.loaded {
+		return
+	}
+	u.reload(ctx, false)
+}
+
+// reload reads the bundle unless force is false and its file is unchanged. Callers hold mu.
+func (u *mitreUsecase) reload(ctx context.Context, force bool) {
+	u.loaded = true
+
+	path := mitreAttackPath()
+	if path == "" {
+		return
+	}
+	u.status.Path = path
+
+	log := logger.WithRequestID(ctx).WithField("path", path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - mitre - reload]: Failed to read ATT&CK bundle, keeping the loaded one")
+		u.status.Error = err.Error()
+		return
+	}
+	if !force && u.status.LoadedAt != nil && info.ModTime().Equal(u.modTime) {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - mitre - reload]: Failed to read ATT&CK bundle, keeping the loaded one")
+		u.status.Error = err.Error()
+		return
+	}
+	defer file.Close()
+
+	matrix, version, err := parseMitreBundle(file)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - mitre - reload]: ATT&CK bundle is invalid, keeping the loaded one")
+		u.status.Error = err.Error()
+		return
+	}
+
+	techniques := make(map[string]*entity.MitreTechnique, len(matrix.Techniques))
+	subTechniques := 0
+	for _, technique := range matrix.Techniques {
+		techniques[technique.ID] = technique
+		if technique.SubTechnique {
+			subTechniques++
+		}
+	}
+
+	loadedAt := time.Now().UTC()
+	u.matrix = matrix
+	u.techniques = techniques
+	u.modTime = info.ModTime()
+	u.status = entity.MitreMatrixStatus{
+		Path:          path,
+		Version:       version,
+		Tactics:       len(matrix.Tactics),
+		Techniques:    len(matrix.Techniques) - subTechniques,
+		SubTechniques: subTechniques,
+		LoadedAt:      &loadedAt,
+	}
+
+	log.WithField("version", version).WithField("techniques", len(techniques)).Info("[usecase - mitre - reload]: ATT&CK bundle loaded")
+}
+
+// mitreAttackPath is the enterprise ATT&CK STIX bundle of MITRE_ATTACK_FILE
+func mitreAttackPath() string {
+	return strings.TrimSpace(os.Getenv("MITRE_ATTACK_FILE"))
+}
+
+// parseMitreBundle reads the tactics and techniques of an ATT&CK STIX bundle, such as enterprise-attack.json,
+// and the ATT&CK release it belongs to. Revoked objects are skipped; deprecated techniques are kept, flagged,
+// since older rules still name them.
+func parseMitreBundle(reader io.Reader) (*entity.MitreMatrix, string, error) {
+	var (
+		version    string
+		tacticRefs []string
+		tactics    = map[string]entity.MitreTactic{} // by STIX ID
+		techniques []*entity.MitreTechnique
+		phases     = map[*entity.MitreTechnique][]string{}
+	)
+
+	err := decodeJSONArrayField(reader, "objects", func(decoder *json.Decoder) error {
+		var object attackObject
+		if err := decoder.Decode(&object); err != nil {
+			return err
+		}
+		if object.Revoked {
+			return nil
+		}
+
+		externalID, url := "", ""
+		for _, reference := range object.ExternalReferences {
+			if reference.SourceName == mitreKillChain {
+				externalID, url = strings.ToUpper(reference.ExternalID), reference.URL
+				break
+			}
+		}
+
+		switch object.Type {
+		case "x-mitre-collection":
+			version = object.Version
+		case "x-mitre-matrix":
+			if tacticRefs == nil || strings.EqualFold(externalID, mitreEnterpriseMatrix) {
+				tacticRefs = object.TacticRefs
+			}
+		case "x-mitre-tactic":
+			if externalID != "" && !object.Deprecated {
+				tactics[object.ID] = entity.MitreTactic{ID: externalID, ShortName: object.ShortName, Name: object.Name}
+			}
+		case "attack-pattern":
+			if !mitreTechniquePattern.MatchString(externalID) {
+				return nil
+			}
+			technique := &entity.MitreTechnique{
+				ID:           externalID,
+				Name:         object.Name,
+				Tactics:      []string{},
+				SubTechnique: object.IsSubtechnique || strings.Contains(externalID, "."),
+				URL:          url,
+				Deprecated:   object.Deprecated,
+			}
+			for _, phase := range object.KillChainPhases {
+				if phase.KillChainName == mitreKillChain {
+					phases[technique] = append(phases[technique], phase.PhaseName)
+				}
+			}
+			techniques = append(techniques, technique)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(techniques) == 0 {
+		return nil, "", fmt.Errorf("no ATT&CK techniques in the bundle")
+	}
+
+	matrix := &entity.MitreMatrix{Tactics: []entity.MitreTactic{}, Techniques: techniques}
+	for _, ref := range tacticRefs {
+		if tactic, ok := tactics[ref]; ok {
+			matrix.Tactics = append(matrix.Tactics, tactic)
+			delete(tactics, ref)
+		}
+	}
+	// Tactics outside the matrix, or every tactic of a bundle without one, follow by ID
+	rest := make([]entity.MitreTactic, 0, len(tactics))
+	for _, tactic := range tactics {
+		rest = append(rest, tactic)
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].ID < rest[j].ID })
+	matrix.Tactics = append(matrix.Tactics, rest...)
+
+	names := map[string]string{}
+	for _, technique := range techniques {
+		names[technique.ID] = technique.Name
+	}
+
+	for _, technique := range techniques {
+		for _, tactic := range matrix.Tactics {
+			if containsString(phases[technique], tactic.ShortName) {
+				technique.Tactics = append(technique.Tactics, tactic.Name)
+			}
+		}
+		if technique.SubTechnique {
+			technique.ParentID, _, _ = strings.Cut(technique.ID, ".")
+			technique.ParentName = names[technique.ParentID]
+		}
+	}
+
+	sort.Slice(matrix.Techniques, func(i, j int) bool { return matrix.Techniques[i].ID < matrix.Techniques[j].ID })
+
+	return matrix, version, nil
+}
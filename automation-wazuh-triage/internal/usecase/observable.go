@@ -0,0 +1,506 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultObservablesWindow and defaultObservablesLimit apply when the request does not set them
+	defaultObservablesWindow = 24 * time.Hour
+	defaultObservablesLimit  = 100
+
+	// observableBatchSize alerts are read per indexer query, at most maxObservableBatches times per report
+	observableBatchSize  = 1000
+	maxObservableBatches = 10
+
+	// maxObservableEventIDs bounds the sample of alerts kept per observable
+	maxObservableEventIDs = 5
+)
+
+var (
+	observableURLPattern    = regexp.MustCompile(`(?i)\b(?:https?|ftp)://[^\s"'<>\x60]+`)
+	observableEmailPattern  = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,63}\b`)
+	observableIPv4Pattern   = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	observableIPv6Pattern   = regexp.MustCompile(`[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*`)
+	observableHashPattern   = regexp.MustCompile(`\b(?:[0-9A-Fa-f]{64}|[0-9A-Fa-f]{40}|[0-9A-Fa-f]{32})\b`)
+	observableDomainPattern = regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}\b`)
+
+	// observableUserPatterns find user names in free-text logs, e.g. sshd and PAM messages or audit records
+	observableUserPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\b(?:for|by) (?:invalid |illegal )?user ([^\s;,'"()\[\]]+)`),
+		regexp.MustCompile(`(?i)\b(?:accepted|failed) \S+ for ([^\s;,'"()\[\]]+) from\b`),
+		regexp.MustCompile(`(?i)\b(?:user|username|ruser|acct|logname)=["']?([^\s;,'"()\[\]]+)`),
+	}
+)
+
+// observableUserKeys are the data fields, lower-cased without underscores, that hold a user name
+var observableUserKeys = map[string]bool{
+	"srcuser":         true,
+	"dstuser":         true,
+	"user":            true,
+	"username":        true,
+	"targetusername":  true,
+	"subjectusername": true,
+	"accountname":     true,
+	"login":           true,
+}
+
+// observableIgnoredUsers are placeholders logs use when there is no user
+var observableIgnoredUsers = map[string]bool{
+	"-":         true,
+	"unknown":   true,
+	"(unknown)": true,
+	"n/a":       true,
+	"none":      true,
+}
+
+// observableFileExtensions are suffixes of file and unit names that look like domains in logs
+var observableFileExtensions = map[string]bool{
+	"log": true, "txt": true, "conf": true, "cfg": true, "ini": true, "exe": true, "dll": true, "sys": true,
+	"bat": true, "cmd": true, "ps1": true, "vbs": true, "sh": true, "py": true, "pl": true, "rb": true,
+	"so": true, "json": true, "xml": true, "yml": true, "yaml": true, "tmp": true, "bak": true, "old": true,
+	"service": true, "socket": true, "target": true, "timer": true, "mount": true, "pid": true, "lock": true,
+	"db": true, "sqlite": true, "gz": true, "zip": true, "tar": true, "tgz": true, "jar": true, "class": true,
+	"java": true, "php": true, "js": true, "html": true, "htm": true, "asp": true, "aspx": true, "jsp": true,
+	"cgi": true, "png": true, "jpg": true, "gif": true, "css": true, "msi": true, "lnk": true, "tmpl": true,
+}
+
+type observableUsecase struct {
+	wazuhEventRepo  domain.WazuhEventRepository
+	closedEventRepo domain.ClosedEventRepository
+}
+
+func NewObservableUsecase(wazuhEventRepo domain.WazuhEventRepository, closedEventRepo domain.ClosedEventRepository) domain.ObservableUsecase {
+	return &observableUsecase{
+		wazuhEventRepo:  wazuhEventRepo,
+		closedEventRepo: closedEventRepo,
+	}
+}
+
+// ExtractRawEventObservables returns the observables of the alert behind a closed event's stored search hit
+func (u *observableUsecase) ExtractRawEventObservables(rawEvent string) []entity.Observable {
+	var hit struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := json.Unmarshal([]byte(rawEvent), &hit); err != nil {
+		return []entity.Observable{}
+	}
+	return extractObservables(hit.Source)
+}
+
+// FetchEventObservables returns the observables of an alert, read from the indexer or, once it left the
+// indexer, from the stored copy of its closure
+func (u *observableUsecase) FetchEventObservables(ctx context.Context, eventID string) (*entity.EventObservables, error) {
+	log := logger.WithRequestID(ctx)
+
+	closedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - observable - FetchEventObservables]: Failed to fetch closed event")
+		return nil, err
+	}
+
+	result := &entity.EventObservables{EventID: eventID, Status: "open"}
+	if closedEvent != nil {
+		result.Status = closedEvent.Status
+	}
+
+	_, hit, err := u.wazuhEventRepo.FetchSecurityEventByID(ctx, eventID)
+	if err == nil && hit != nil {
+		result.Observables = extractObservables(hit.Source)
+		return result, nil
+	}
+	if closedEvent != nil {
+		log.WithField("event_id", eventID).Warn("[usecase - observable - FetchEventObservables]: Alert not in the indexer, using the closed event")
+		result.Observables = u.ExtractRawEventObservables(closedEvent.RawEvent)
+		return result, nil
+	}
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - observable - FetchEventObservables]: Failed to fetch alert")
+		return nil, err
+	}
+	return nil, fmt.Errorf("event %s not found", eventID)
+}
+
+// FetchObservables aggregates the observables of the alerts of the window, the ones seen in most alerts first
+func (u *observableUsecase) FetchObservables(ctx context.Context, request *model.ObservablesRequest) (*entity.ObservableReport, error) {
+	log := logger.WithRequestID(ctx)
+
+	if request.Type != "" && !containsString(entity.ObservableTypes, request.Type) {
+		return nil, fmt.Errorf("invalid type %q, expected one of %s", request.Type, strings.Join(entity.ObservableTypes, ", "))
+	}
+
+	window := request.Window
+	if window <= 0 {
+		window = defaultObservablesWindow
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultObservablesLimit
+	}
+
+	report := &entity.ObservableReport{
+		Window:      window.String(),
+		Since:       time.Now().Add(-window).UTC(),
+		GeneratedAt: time.Now().UTC(),
+		ByType:      map[string]int{},
+		Observables: []entity.ObservableSummary{},
+	}
+
+	summaries := map[string]*entity.ObservableSummary{}
+	agents := map[string]map[string]bool{}
+	rules := map[string]map[string]bool{}
+	fields := map[string]map[string]bool{}
+
+	var searchAfter []interface{}
+	full := false
+
+	for batch := 0; batch < maxObservableBatches; batch++ {
+		hits, err := u.wazuhEventRepo.FetchSecurityEventsSince(ctx, report.Since, searchAfter, observableBatchSize)
+		if err != nil {
+			log.WithError(err).Error("[usecase - observable - FetchObservables]: Failed to fetch security events")
+			return nil, err
+		}
+
+		for _, hit := range hits {
+			source, ok := decodeAlertSource(hit.Source)
+			if !ok {
+				continue
+			}
+			firedAt, _ := parseAlertTimestamp(source.Timestamp)
+
+			if request.AgentID != "" && source.Agent.ID != request.AgentID {
+				continue
+			}
+			if request.RuleID != "" && source.Rule.ID != request.RuleID {
+				continue
+			}
+			report.Alerts++
+
+			var securityEvent entity.WazuhSecurityEvent
+			eventID := hit.Id
+			if err := json.Unmarshal(hit.Source, &securityEvent); err == nil && len(securityEvent.ID) > 0 {
+				eventID = string(securityEvent.ID)
+			}
+
+			agent := source.Agent.Name
+			if agent == "" {
+				agent = source.Agent.ID
+			}
+
+			for _, observable := range extractObservables(hit.Source) {
+				if request.Type != "" && observable.Type != request.Type {
+					continue
+				}
+				if request.ExcludeInternal && observable.Internal {
+					continue
+				}
+
+				key := observable.Type + "|" + observable.Value
+				summary, ok := summaries[key]
+				if !ok {
+					summary = &entity.ObservableSummary{
+						Type:      observable.Type,
+						Value:     observable.Value,
+						Internal:  observable.Internal,
+						FirstSeen: firedAt.UTC(),
+						LastSeen:  firedAt.UTC(),
+					}
+					summaries[key] = summary
+					agents[key], rules[key], fields[key] = map[string]bool{}, map[string]bool{}, map[string]bool{}
+				}
+
+				summary.Alerts++
+				if !firedAt.IsZero() {
+					if summary.FirstSeen.IsZero() || firedAt.Before(summary.FirstSeen) {
+						summary.FirstSeen = firedAt.UTC()
+					}
+					if firedAt.After(summary.LastSeen) {
+						summary.LastSeen = firedAt.UTC()
+					}
+				}
+				// Alerts come oldest first, so the sample keeps the latest ones
+				summary.EventIDs = append(summary.EventIDs, eventID)
+				if len(summary.EventIDs) > maxObservableEventIDs {
+					summary.EventIDs = summary.EventIDs[1:]
+				}
+				if agent != "" {
+					agents[key][agent] = true
+				}
+				if source.Rule.ID != "" {
+					rules[key][source.Rule.ID] = true
+				}
+				for _, field := range observable.Fields {
+					fields[key][field] = true
+				}
+			}
+		}
+
+		// A short batch is the last one
+		full = len(hits) == observableBatchSize && len(hits[len(hits)-1].Sort) > 0
+		if !full {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+	report.Truncated = full
+
+	for key, summary := range summaries {
+		summary.Agents = sortedKeys(agents[key])
+		summary.Rules = sortedKeys(rules[key])
+		summary.Fields = sortedKeys(fields[key])
+		for i, j := 0, len(summary.EventIDs)-1; i < j; i, j = i+1, j-1 {
+			summary.EventIDs[i], summary.EventIDs[j] = summary.EventIDs[j], summary.EventIDs[i]
+		}
+		report.ByType[summary.Type]++
+		report.Observables = append(report.Observables, *summary)
+	}
+
+	sort.Slice(report.Observables, func(i, j int) bool {
+		a, b := report.Observables[i], report.Observables[j]
+		if a.Alerts != b.Alerts {
+			return a.Alerts > b.Alerts
+		}
+		if !a.LastSeen.Equal(b.LastSeen) {
+			return a.LastSeen.After(b.LastSeen)
+		}
+		return observableLess(a.Type, a.Value, b.Type, b.Value)
+	})
+	if len(report.Observables) > limit {
+		report.Observables = report.Observables[:limit]
+	}
+
+	return report, nil
+}
+
+// extractObservables finds the addresses, domains, URLs, email addresses, user names and hashes in the data
+// fields and full_log of an alert
+func extractObservables(source []byte) []entity.Observable {
+	var document struct {
+		Data    interface{} `json:"data"`
+		FullLog string      `json:"full_log"`
+	}
+	if err := json.Unmarshal(source, &document); err != nil {
+		return []entity.Observable{}
+	}
+
+	extractor := &observableExtractor{found: map[string]*entity.Observable{}}
+	extractor.walk("data", "", document.Data)
+	if document.FullLog != "" {
+		extractor.scanText("full_log", document.FullLog, true)
+		for _, pattern := range observableUserPatterns {
+			for _, match := range pattern.FindAllStringSubmatch(document.FullLog, -1) {
+				extractor.addUser("full_log", match[1])
+			}
+		}
+	}
+
+	observables := make([]entity.Observable, 0, len(extractor.found))
+	for _, observable := range extractor.found {
+		sort.Strings(observable.Fields)
+		observables = append(observables, *observable)
+	}
+	sort.Slice(observables, func(i, j int) bool {
+		return observableLess(observables[i].Type, observables[i].Value, observables[j].Type, observables[j].Value)
+	})
+	return observables
+}
+
+// observableExtractor collects the observables of one alert by type and value
+type observableExtractor struct {
+	found map[string]*entity.Observable
+}
+
+// walk scans every string under a data field; array elements share their field's path
+func (e *observableExtractor) walk(path string, key string, value interface{}) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for childKey, child := range typed {
+			e.walk(path+"."+childKey, childKey, child)
+		}
+	case []interface{}:
+		for _, child := range typed {
+			e.walk(path, key, child)
+		}
+	case string:
+		// A dotted user name such as j.doe is not a domain
+		if observableUserKeys[strings.ToLower(strings.ReplaceAll(key, "_", ""))] {
+			e.addUser(path, typed)
+			e.scanText(path, typed, false)
+			return
+		}
+		e.scanText(path, typed, true)
+	}
+}
+
+// scanText finds the observables in a text, bare domains only when domains is set. URLs and email addresses
+// are taken first and blanked out, so their parts are not read again as domains; their host is kept as a
+// domain or address of its own.
+func (e *observableExtractor) scanText(field string, text string, domains bool) {
+	blanked := []byte(text)
+	blank := func(loc []int) {
+		for i := loc[0]; i < loc[1]; i++ {
+			blanked[i] = ' '
+		}
+	}
+
+	for _, loc := range observableURLPattern.FindAllStringIndex(text, -1) {
+		raw := strings.TrimRight(text[loc[0]:loc[1]], ".,;:!?)]}")
+		if normalized, err := normalizeIOCValue(entity.IOCTypeURL, raw); err == nil {
+			e.add(entity.ObservableTypeURL, normalized, false, field)
+			if parsed, err := url.Parse(normalized); err == nil {
+				e.addHost(field, parsed.Hostname())
+			}
+		}
+		blank(loc)
+	}
+
+	for _, loc := range observableEmailPattern.FindAllStringIndex(string(blanked), -1) {
+		email := strings.ToLower(text[loc[0]:loc[1]])
+		e.add(entity.ObservableTypeEmail, email, false, field)
+		_, host, _ := strings.Cut(email, "@")
+		e.addHost(field, host)
+		blank(loc)
+	}
+
+	remaining := string(blanked)
+
+	for _, loc := range observableIPv6Pattern.FindAllStringIndex(remaining, -1) {
+		if loc[0] > 0 && isWordByte(remaining[loc[0]-1]) {
+			continue
+		}
+		if loc[1] < len(remaining) && isWordByte(remaining[loc[1]]) {
+			continue
+		}
+		candidate := strings.Trim(remaining[loc[0]:loc[1]], ".")
+		if address, err := netip.ParseAddr(candidate); err == nil && !address.IsUnspecified() {
+			e.addAddress(field, address)
+		}
+	}
+
+	for _, loc := range observableIPv4Pattern.FindAllStringIndex(remaining, -1) {
+		// Skip the start of dotted version numbers such as 1.2.3.4.5
+		if loc[0] > 0 && (remaining[loc[0]-1] == '.' || isWordByte(remaining[loc[0]-1])) {
+			continue
+		}
+		if loc[1]+1 < len(remaining) && remaining[loc[1]] == '.' && isDigitByte(remaining[loc[1]+1]) {
+			continue
+		}
+		if address, err := netip.ParseAddr(remaining[loc[0]:loc[1]]); err == nil {
+			e.addAddress(field, address)
+		}
+	}
+
+	for _, match := range observableHashPattern.FindAllString(remaining, -1) {
+		hash := strings.ToLower(match)
+		switch len(hash) {
+		case 32:
+			e.add(entity.ObservableTypeMD5, hash, false, field)
+		case 40:
+			e.add(entity.ObservableTypeSHA1, hash, false, field)
+		case 64:
+			e.add(entity.ObservableTypeSHA256, hash, false, field)
+		}
+	}
+
+	if !domains {
+		return
+	}
+	for _, loc := range observableDomainPattern.FindAllStringIndex(remaining, -1) {
+		// Path components such as /var/log/auth.log are file names
+		if loc[0] > 0 && (remaining[loc[0]-1] == '/' || remaining[loc[0]-1] == '\\') {
+			continue
+		}
+		e.addHost(field, remaining[loc[0]:loc[1]])
+	}
+}
+
+// addHost adds a host name as a domain, or as an address when it is one
+func (e *observableExtractor) addHost(field string, host string) {
+	if address, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		e.addAddress(field, address)
+		return
+	}
+
+	domain := strings.TrimSuffix(strings.ToLower(host), ".")
+	dot := strings.LastIndex(domain, ".")
+	if dot < 0 || observableFileExtensions[domain[dot+1:]] || !iocDomainPattern.MatchString(domain) {
+		return
+	}
+	e.add(entity.ObservableTypeDomain, domain, false, field)
+}
+
+func (e *observableExtractor) addAddress(field string, address netip.Addr) {
+	address = address.Unmap()
+	internal := address.IsPrivate() || address.IsLoopback() || address.IsLinkLocalUnicast() || address.IsUnspecified()
+	if address.Is4() {
+		e.add(entity.ObservableTypeIPv4, address.String(), internal, field)
+		return
+	}
+	e.add(entity.ObservableTypeIPv6, address.String(), internal, field)
+}
+
+func (e *observableExtractor) addUser(field string, user string) {
+	user = strings.TrimRight(strings.TrimSpace(user), ".:")
+	if user == "" || len(user) > 256 || observableIgnoredUsers[strings.ToLower(user)] {
+		return
+	}
+	e.add(entity.ObservableTypeUsername, user, false, field)
+}
+
+func (e *observableExtractor) add(observableType string, value string, internal bool, field string) {
+	key := observableType + "|" + value
+	observable, ok := e.found[key]
+	if !ok {
+		observable = &entity.Observable{Type: observableType, Value: value, Internal: internal, Fields: []string{}}
+		e.found[key] = observable
+	}
+	if !containsString(observable.Fields, field) {
+		observable.Fields = append(observable.Fields, field)
+	}
+}
+
+// observableLess orders observables by type, in the order of entity.ObservableTypes, then by value
+func observableLess(typeA string, valueA string, typeB string, valueB string) bool {
+	if typeA != typeB {
+		return observableTypeRank(typeA) < observableTypeRank(typeB)
+	}
+	return valueA < valueB
+}
+
+func observableTypeRank(observableType string) int {
+	for i, known := range entity.ObservableTypes {
+		if known == observableType {
+			return i
+		}
+	}
+	return len(entity.ObservableTypes)
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || isDigitByte(b) || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isDigitByte(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
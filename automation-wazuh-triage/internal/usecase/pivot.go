@@ -0,0 +1,311 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultPivotWindow is how long before and after the pivot alert related alerts are searched
+	defaultPivotWindow = 24 * time.Hour
+	maxPivotWindow     = 7 * 24 * time.Hour
+
+	defaultRelatedAlertsLimit = 100
+	maxRelatedAlertsLimit     = 1000
+
+	// maxPivotAlerts bounds the related alerts read and counted for one pivot
+	maxPivotAlerts = 5000
+)
+
+// pivotFieldPaths are the alert fields holding the values of each entity field
+var pivotFieldPaths = map[string][]string{
+	entity.PivotFieldSrcIP:   {"data.srcip"},
+	entity.PivotFieldAgentID: {"agent.id"},
+	entity.PivotFieldUser:    {"data.dstuser", "data.win.eventdata.targetUserName", "data.srcuser"},
+	entity.PivotFieldFile:    {"syscheck.path"},
+	entity.PivotFieldHash:    {"syscheck.md5_after", "syscheck.sha1_after", "syscheck.sha256_after"},
+}
+
+// pivotStatuses lists the triage statuses a related alert may have
+var pivotStatuses = []string{
+	entity.PivotStatusOpen,
+	entity.PivotStatusAcknowledged,
+	entity.PivotStatusAutoClosed,
+	entity.PivotStatusClosed,
+}
+
+type pivotUsecase struct {
+	wazuhEventRepo   domain.WazuhEventRepository
+	closedEventRepo  domain.ClosedEventRepository
+	triageActionRepo domain.TriageActionRepository
+}
+
+func NewPivotUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+) domain.PivotUsecase {
+	return &pivotUsecase{
+		wazuhEventRepo:   wazuhEventRepo,
+		closedEventRepo:  closedEventRepo,
+		triageActionRepo: triageActionRepo,
+	}
+}
+
+// ExtractRawEventPivots returns the entity values of the alert stored with a closed event, the fields related
+// alerts can be found by
+func (u *pivotUsecase) ExtractRawEventPivots(rawEvent string) []entity.PivotValue {
+	source, ok := parseAlertSource(rawEvent)
+	if !ok {
+		return []entity.PivotValue{}
+	}
+	return pivotValues(source)
+}
+
+// FetchRelatedAlerts returns the alerts sharing the value of an entity field with an alert, fired within the
+// window before or after it, with how each was triaged. The alert is read from the indexer or, once it left
+// the indexer, from the stored copy of its closure.
+func (u *pivotUsecase) FetchRelatedAlerts(ctx context.Context, eventID string, request *model.RelatedAlertsRequest) (*entity.RelatedAlerts, error) {
+	log := logger.WithRequestID(ctx)
+
+	if !containsString(entity.PivotFields, request.Field) {
+		return nil, fmt.Errorf("invalid field %q, expected one of %s", request.Field, strings.Join(entity.PivotFields, ", "))
+	}
+	if request.Status != "" && !containsString(pivotStatuses, request.Status) {
+		return nil, fmt.Errorf("invalid status %q, expected one of %s", request.Status, strings.Join(pivotStatuses, ", "))
+	}
+
+	window := request.Window
+	if window <= 0 {
+		window = defaultPivotWindow
+	}
+	if window > maxPivotWindow {
+		return nil, fmt.Errorf("invalid window: must be at most %s", maxPivotWindow)
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultRelatedAlertsLimit
+	}
+	if limit > maxRelatedAlertsLimit {
+		limit = maxRelatedAlertsLimit
+	}
+
+	source, err := u.fetchPivotAlert(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	firedAt, ok := parseAlertTimestamp(source.Timestamp)
+	if !ok {
+		return nil, fmt.Errorf("invalid event: alert %s has no timestamp", eventID)
+	}
+	values := pivotFieldValues(source, request.Field)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("invalid field %q: alert %s has no value for it", request.Field, eventID)
+	}
+
+	related := &entity.RelatedAlerts{
+		EventID:  eventID,
+		FiredAt:  firedAt.UTC(),
+		Field:    request.Field,
+		Values:   values,
+		Window:   window.String(),
+		From:     firedAt.Add(-window).UTC(),
+		To:       firedAt.Add(window).UTC(),
+		ByStatus: map[string]int{},
+		Alerts:   []entity.RelatedAlert{},
+	}
+	for _, status := range pivotStatuses {
+		related.ByStatus[status] = 0
+	}
+
+	hits, err := u.wazuhEventRepo.FetchAlertsSharingValues(ctx, pivotFieldPaths[request.Field], values, related.From, related.To, maxPivotAlerts)
+	if err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - pivot - FetchRelatedAlerts]: Failed to fetch related alerts")
+		return nil, err
+	}
+	related.Truncated = len(hits) >= maxPivotAlerts
+
+	// A related alert fired at or after From, so it can only have been triaged since then
+	closedEvents, err := u.closedEventRepo.FetchClosedEventsSince(ctx, related.From)
+	if err != nil {
+		log.WithError(err).Error("[usecase - pivot - FetchRelatedAlerts]: Failed to fetch closed events")
+		return nil, err
+	}
+	closures := make(map[string]*entity.ClosedEvent, len(closedEvents))
+	for _, closed := range closedEvents {
+		closures[closed.EventID] = closed
+	}
+
+	actions, err := u.triageActionRepo.FetchTriageActionsForAlertsSince(ctx, related.From)
+	if err != nil {
+		log.WithError(err).Error("[usecase - pivot - FetchRelatedAlerts]: Failed to fetch triage actions")
+		return nil, err
+	}
+	// Actions come oldest first, so the last closer and the first to acknowledge win
+	closers := map[string]string{}
+	acknowledgers := map[string]string{}
+	for _, action := range actions {
+		switch action.Action {
+		case entity.TriageActionClosed:
+			closers[action.EventID] = action.Actor
+		case entity.TriageActionAcknowledged:
+			if _, ok := acknowledgers[action.EventID]; !ok {
+				acknowledgers[action.EventID] = action.Actor
+			}
+		}
+	}
+
+	rules := map[string]bool{}
+	agents := map[string]bool{}
+	var alerts []entity.RelatedAlert
+
+	for _, hit := range hits {
+		var securityEvent entity.WazuhSecurityEvent
+		alertSource, ok := decodeAlertSource(hit.Source)
+		if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || !ok {
+			continue
+		}
+		relatedID := string(securityEvent.ID)
+		if relatedID == eventID {
+			continue
+		}
+
+		alert := entity.RelatedAlert{
+			EventID:     relatedID,
+			RuleID:      alertSource.Rule.ID,
+			Description: alertSource.Rule.Description,
+			AgentID:     alertSource.Agent.ID,
+			AgentName:   alertSource.Agent.Name,
+			Status:      entity.PivotStatusOpen,
+		}
+		if relatedAt, ok := parseAlertTimestamp(alertSource.Timestamp); ok {
+			alert.FiredAt = relatedAt.UTC()
+			alert.Offset = relatedAt.Sub(firedAt).String()
+		}
+		if alertSource.Rule.Level != nil {
+			alert.Level = *alertSource.Rule.Level
+		}
+
+		if closed, ok := closures[relatedID]; ok {
+			closedAt := closed.CloseAt.UTC()
+			alert.ClosedAt = &closedAt
+			alert.ClosedBy = closers[relatedID]
+			alert.Label = closed.Label
+			alert.Reason = closed.Reason
+			alert.Status = entity.PivotStatusClosed
+			if closed.CloseType == entity.CloseTypeAuto {
+				alert.Status = entity.PivotStatusAutoClosed
+			}
+		} else if actor, ok := acknowledgers[relatedID]; ok {
+			alert.Status = entity.PivotStatusAcknowledged
+			alert.AcknowledgedBy = actor
+		}
+
+		related.Total++
+		related.ByStatus[alert.Status]++
+		rules[alert.RuleID] = true
+		agents[alert.AgentID] = true
+
+		if request.Status != "" && alert.Status != request.Status {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	related.Rules = len(rules)
+	related.Agents = len(agents)
+
+	// Keep the alerts nearest to the pivot alert, then list them in the order they fired
+	distance := func(alert entity.RelatedAlert) time.Duration {
+		if alert.FiredAt.Before(firedAt) {
+			return firedAt.Sub(alert.FiredAt)
+		}
+		return alert.FiredAt.Sub(firedAt)
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return distance(alerts[i]) < distance(alerts[j])
+	})
+	if len(alerts) > limit {
+		alerts = alerts[:limit]
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].FiredAt.Before(alerts[j].FiredAt)
+	})
+	related.Alerts = append(related.Alerts, alerts...)
+
+	return related, nil
+}
+
+// fetchPivotAlert reads an alert from the indexer, or from its closed event once it left the indexer
+func (u *pivotUsecase) fetchPivotAlert(ctx context.Context, eventID string) (alertSource, error) {
+	log := logger.WithRequestID(ctx)
+
+	_, hit, err := u.wazuhEventRepo.FetchSecurityEventByID(ctx, eventID)
+	if err == nil && hit != nil {
+		if source, ok := decodeAlertSource(hit.Source); ok {
+			return source, nil
+		}
+	}
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - pivot - fetchPivotAlert]: Failed to fetch alert")
+		return alertSource{}, err
+	}
+
+	closedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - pivot - fetchPivotAlert]: Failed to fetch closed event")
+		return alertSource{}, err
+	}
+	if closedEvent != nil {
+		if source, ok := parseAlertSource(closedEvent.RawEvent); ok {
+			log.WithField("event_id", eventID).Warn("[usecase - pivot - fetchPivotAlert]: Alert not in the indexer, using the closed event")
+			return source, nil
+		}
+	}
+
+	return alertSource{}, fmt.Errorf("event %s not found", eventID)
+}
+
+// pivotValues returns the entity fields an alert has values for, in the order of entity.PivotFields
+func pivotValues(source alertSource) []entity.PivotValue {
+	pivots := []entity.PivotValue{}
+	for _, field := range entity.PivotFields {
+		if values := pivotFieldValues(source, field); len(values) > 0 {
+			pivots = append(pivots, entity.PivotValue{Field: field, Values: values})
+		}
+	}
+	return pivots
+}
+
+// pivotFieldValues returns the distinct values an alert holds for an entity field
+func pivotFieldValues(source alertSource, field string) []string {
+	var values []string
+	switch field {
+	case entity.PivotFieldSrcIP:
+		values = []string{source.Data.SrcIP}
+	case entity.PivotFieldAgentID:
+		values = []string{source.Agent.ID}
+	case entity.PivotFieldUser:
+		values = []string{source.Data.DstUser, source.Data.Win.EventData.TargetUserName, source.Data.SrcUser}
+	case entity.PivotFieldFile:
+		values = []string{source.Syscheck.Path}
+	case entity.PivotFieldHash:
+		values = []string{source.Syscheck.MD5After, source.Syscheck.SHA1After, source.Syscheck.SHA256After}
+	}
+
+	distinct := []string{}
+	for _, value := range cleanList(values) {
+		if !containsString(distinct, value) {
+			distinct = append(distinct, value)
+		}
+	}
+	return distinct
+}
@@ -0,0 +1,455 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/textdiff"
+	"automation-wazuh-triage/pkg/wazuh"
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// proposalTransitions lists the states a proposal may move to from each state
+var proposalTransitions = map[string][]string{
+	entity.ProposalStatusDraft:           {entity.ProposalStatusPendingApproval},
+	entity.ProposalStatusPendingApproval: {entity.ProposalStatusApproved, entity.ProposalStatusRejected},
+	entity.ProposalStatusApproved:        {entity.ProposalStatusDeployed},
+	entity.ProposalStatusDeployed:        {entity.ProposalStatusRolledBack},
+}
+
+type proposalUsecase struct {
+	proposalRepo       domain.ProposalRepository
+	suppressionRepo    domain.SuppressionRepository
+	closedEventRepo    domain.ClosedEventRepository
+	ruleFileRepo       domain.RuleFileRepository
+	suppressionUsecase domain.SuppressionUsecase
+	ruleFileUsecase    domain.RuleFileUsecase
+}
+
+func NewProposalUsecase(
+	proposalRepo domain.ProposalRepository,
+	suppressionRepo domain.SuppressionRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	ruleFileRepo domain.RuleFileRepository,
+	suppressionUsecase domain.SuppressionUsecase,
+	ruleFileUsecase domain.RuleFileUsecase,
+) domain.ProposalUsecase {
+	return &proposalUsecase{
+		proposalRepo:       proposalRepo,
+		suppressionRepo:    suppressionRepo,
+		closedEventRepo:    closedEventRepo,
+		ruleFileRepo:       ruleFileRepo,
+		suppressionUsecase: suppressionUsecase,
+		ruleFileUsecase:    ruleFileUsecase,
+	}
+}
+
+func (u *proposalUsecase) CreateProposal(ctx context.Context, request *model.CreateProposalRequest) (*entity.Proposal, error) {
+	log := logger.WithRequestID(ctx)
+
+	author := strings.TrimSpace(request.Author)
+	if author == "" || strings.TrimSpace(request.Title) == "" {
+		return nil, fmt.Errorf("invalid proposal: title and author are required")
+	}
+
+	if len(request.EvidenceEventIDs) == 0 {
+		return nil, fmt.Errorf("invalid proposal: at least one evidence closed event is required")
+	}
+
+	for _, id := range request.EvidenceEventIDs {
+		closedEvent, err := u.closedEventRepo.FetchClosedEventByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if closedEvent == nil {
+			return nil, fmt.Errorf("invalid proposal: evidence closed event %s does not exist", id)
+		}
+	}
+
+	now := time.Now()
+	proposal := &entity.Proposal{
+		Type:             request.Type,
+		Title:            strings.TrimSpace(request.Title),
+		Description:      request.Description,
+		Author:           author,
+		Status:           entity.ProposalStatusDraft,
+		EvidenceEventIDs: request.EvidenceEventIDs,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	switch request.Type {
+	case entity.ProposalTypeSuppression:
+		if request.Suppression == nil || request.Suppression.ParentRuleID <= 0 || len(request.Suppression.Conditions) == 0 {
+			return nil, fmt.Errorf("invalid proposal: suppression parent_rule_id and conditions are required")
+		}
+
+		suppression, err := u.suppressionUsecase.CreateSuppression(ctx, &entity.Suppression{
+			ParentRuleID: request.Suppression.ParentRuleID,
+			Conditions:   request.Suppression.Conditions,
+			Description:  proposal.Title,
+			CreatedBy:    author,
+		})
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "invalid suppression") {
+				return nil, fmt.Errorf("invalid proposal: %w", err)
+			}
+			return nil, err
+		}
+
+		proposal.SuppressionID = suppression.ID
+		proposal.Filename = suppression.Filename
+
+	case entity.ProposalTypeRuleChange:
+		if request.Filename == "" || strings.TrimSpace(request.Content) == "" {
+			return nil, fmt.Errorf("invalid proposal: filename and content are required for a rule change")
+		}
+		if err := wazuh.ValidateRuleFileXML(request.Content); err != nil {
+			return nil, fmt.Errorf("invalid proposal: rule file content is not well-formed XML: %w", err)
+		}
+
+		proposal.Filename = request.Filename
+		proposal.Content = request.Content
+
+	default:
+		return nil, fmt.Errorf("invalid proposal: type must be %s or %s", entity.ProposalTypeSuppression, entity.ProposalTypeRuleChange)
+	}
+
+	if err := u.refreshDiff(ctx, proposal); err != nil {
+		log.WithError(err).Error("[usecase - proposal - CreateProposal]: Failed to compute proposal diff")
+		return nil, err
+	}
+
+	if err := u.proposalRepo.SaveProposal(ctx, proposal); err != nil {
+		log.WithError(err).Error("[usecase - proposal - CreateProposal]: Failed to save proposal")
+		return nil, err
+	}
+
+	return proposal, nil
+}
+
+func (u *proposalUsecase) FetchProposals(ctx context.Context, status string) ([]*entity.Proposal, error) {
+	return u.proposalRepo.FetchProposals(ctx, status)
+}
+
+func (u *proposalUsecase) FetchProposalDetails(ctx context.Context, id int) (*entity.Proposal, *entity.Suppression, []*entity.ClosedEvent, error) {
+	log := logger.WithRequestID(ctx)
+
+	proposal, err := u.fetchProposal(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var suppression *entity.Suppression
+	if proposal.SuppressionID != 0 {
+		suppression, err = u.suppressionRepo.FetchSuppressionByID(ctx, proposal.SuppressionID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	var evidence []*entity.ClosedEvent
+	for _, eventID := range proposal.EvidenceEventIDs {
+		closedEvent, err := u.closedEventRepo.FetchClosedEventByID(ctx, eventID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if closedEvent == nil {
+			log.WithField("proposal_id", id).WithField("closed_event_id", eventID).Warn("[usecase - proposal - FetchProposalDetails]: Evidence closed event no longer exists")
+			continue
+		}
+		evidence = append(evidence, closedEvent)
+	}
+
+	return proposal, suppression, evidence, nil
+}
+
+// SubmitProposal sends a draft for review. The diff is recomputed against the current rule file.
+func (u *proposalUsecase) SubmitProposal(ctx context.Context, id int, actor string) (*entity.Proposal, error) {
+	proposal, err := u.fetchProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sameActor(actor, proposal.Author) {
+		return nil, fmt.Errorf("proposal %d can only be submitted by its author", id)
+	}
+
+	from := proposal.Status
+	if err := transitionProposal(proposal, entity.ProposalStatusPendingApproval); err != nil {
+		return nil, err
+	}
+
+	if err := u.refreshDiff(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	proposal.SubmittedAt = &now
+
+	if err := u.updateProposal(ctx, proposal, from); err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+func (u *proposalUsecase) ApproveProposal(ctx context.Context, id int, actor string, comment string) (*entity.Proposal, error) {
+	return u.reviewProposal(ctx, id, actor, comment, entity.ProposalStatusApproved, entity.SuppressionStatusApproved)
+}
+
+func (u *proposalUsecase) RejectProposal(ctx context.Context, id int, actor string, comment string) (*entity.Proposal, error) {
+	return u.reviewProposal(ctx, id, actor, comment, entity.ProposalStatusRejected, entity.SuppressionStatusRejected)
+}
+
+// reviewProposal records the decision of a reviewer, who must not be the author
+func (u *proposalUsecase) reviewProposal(ctx context.Context, id int, actor string, comment string, status string, suppressionStatus string) (*entity.Proposal, error) {
+	log := logger.WithRequestID(ctx).WithField("proposal_id", id)
+
+	proposal, err := u.fetchProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewer := strings.TrimSpace(actor)
+	if reviewer == "" {
+		return nil, fmt.Errorf("invalid proposal action: actor is required")
+	}
+	if sameActor(reviewer, proposal.Author) {
+		return nil, fmt.Errorf("proposal %d: approver must differ from the author", id)
+	}
+
+	from := proposal.Status
+	if err := transitionProposal(proposal, status); err != nil {
+		return nil, err
+	}
+
+	if proposal.SuppressionID != 0 {
+		if err := u.setSuppressionStatus(ctx, proposal.SuppressionID, suppressionStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	proposal.Reviewer = reviewer
+	proposal.ReviewComment = comment
+	proposal.ReviewedAt = &now
+
+	if err := u.updateProposal(ctx, proposal, from); err != nil {
+		return nil, err
+	}
+
+	log.WithField("reviewer", reviewer).WithField("status", status).Info("[usecase - proposal - reviewProposal]: Proposal reviewed")
+	return proposal, nil
+}
+
+// DeployProposal pushes an approved proposal. The rule file lock is held from reading the proposal until its
+// new status is stored, so a proposal is deployed once and the rule file cannot change after its hash check.
+func (u *proposalUsecase) DeployProposal(ctx context.Context, id int, actor string) (*entity.Proposal, error) {
+	log := logger.WithRequestID(ctx).WithField("proposal_id", id)
+
+	ctx, unlock := lockRuleFiles(ctx)
+	defer unlock()
+
+	proposal, err := u.fetchProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deployer := strings.TrimSpace(actor)
+	if deployer == "" {
+		return nil, fmt.Errorf("invalid proposal action: actor is required")
+	}
+
+	from := proposal.Status
+	if err := transitionProposal(proposal, entity.ProposalStatusDeployed); err != nil {
+		return nil, err
+	}
+
+	switch proposal.Type {
+	case entity.ProposalTypeSuppression:
+		if _, err := u.suppressionUsecase.DeploySuppression(ctx, proposal.SuppressionID); err != nil {
+			log.WithError(err).Error("[usecase - proposal - DeployProposal]: Failed to deploy suppression")
+			return nil, err
+		}
+
+	case entity.ProposalTypeRuleChange:
+		// The reviewed diff is only meaningful against the file it was computed on
+		if err := u.requireRuleFileHash(ctx, proposal.Filename, proposal.BaseHash, "submitted"); err != nil {
+			return nil, err
+		}
+
+		if _, err := u.ruleFileUsecase.PushRuleFile(ctx, proposal.Filename, proposal.Content, "deploy proposal "+strconv.Itoa(proposal.ID)); err != nil {
+			log.WithError(err).Error("[usecase - proposal - DeployProposal]: Failed to push rule file")
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	proposal.DeployedBy = deployer
+	proposal.DeployedAt = &now
+
+	if err := u.updateProposal(ctx, proposal, from); err != nil {
+		return nil, err
+	}
+
+	log.WithField("deployed_by", deployer).Info("[usecase - proposal - DeployProposal]: Successfully deployed proposal")
+	return proposal, nil
+}
+
+// RollbackProposal undoes a deployed proposal, under the rule file lock like DeployProposal
+func (u *proposalUsecase) RollbackProposal(ctx context.Context, id int, actor string, comment string) (*entity.Proposal, error) {
+	log := logger.WithRequestID(ctx).WithField("proposal_id", id)
+
+	ctx, unlock := lockRuleFiles(ctx)
+	defer unlock()
+
+	proposal, err := u.fetchProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rolledBackBy := strings.TrimSpace(actor)
+	if rolledBackBy == "" {
+		return nil, fmt.Errorf("invalid proposal action: actor is required")
+	}
+
+	from := proposal.Status
+	if err := transitionProposal(proposal, entity.ProposalStatusRolledBack); err != nil {
+		return nil, err
+	}
+
+	switch proposal.Type {
+	case entity.ProposalTypeSuppression:
+		if _, err := u.suppressionUsecase.RetractSuppression(ctx, proposal.SuppressionID); err != nil {
+			log.WithError(err).Error("[usecase - proposal - RollbackProposal]: Failed to retract suppression")
+			return nil, err
+		}
+
+	case entity.ProposalTypeRuleChange:
+		// Restoring the base content would silently drop any change pushed after this proposal
+		if err := u.requireRuleFileHash(ctx, proposal.Filename, hashContent(proposal.Content), "deployed"); err != nil {
+			return nil, err
+		}
+
+		// The proposal created the file, remove it instead of leaving an empty rule file behind
+		if proposal.BaseContent == "" {
+			if err := u.ruleFileRepo.DeleteRuleFile(ctx, proposal.Filename); err != nil {
+				return nil, err
+			}
+		} else if _, err := u.ruleFileUsecase.PushRuleFile(ctx, proposal.Filename, proposal.BaseContent, "roll back proposal "+strconv.Itoa(proposal.ID)); err != nil {
+			log.WithError(err).Error("[usecase - proposal - RollbackProposal]: Failed to push rule file")
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	proposal.RolledBackBy = rolledBackBy
+	proposal.RolledBackAt = &now
+	if comment != "" {
+		proposal.ReviewComment = strings.TrimSpace(proposal.ReviewComment + "\nrollback: " + comment)
+	}
+
+	if err := u.updateProposal(ctx, proposal, from); err != nil {
+		return nil, err
+	}
+
+	log.WithField("rolled_back_by", rolledBackBy).Info("[usecase - proposal - RollbackProposal]: Successfully rolled back proposal")
+	return proposal, nil
+}
+
+// updateProposal stores a proposal that moved from the given status. When another request moved it first the
+// change is refused, so two reviewers or deployers cannot both act on one proposal.
+func (u *proposalUsecase) updateProposal(ctx context.Context, proposal *entity.Proposal, from string) error {
+	err := u.proposalRepo.UpdateProposal(ctx, proposal, from)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("proposal %d cannot move from %s to %s, another request changed it first", proposal.ID, from, proposal.Status)
+	}
+	return err
+}
+
+func (u *proposalUsecase) fetchProposal(ctx context.Context, id int) (*entity.Proposal, error) {
+	proposal, err := u.proposalRepo.FetchProposalByID(ctx, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("proposal_id", id).Error("[usecase - proposal - fetchProposal]: Failed to fetch proposal")
+		return nil, err
+	}
+
+	if proposal == nil {
+		return nil, fmt.Errorf("proposal with ID %d not found", id)
+	}
+
+	return proposal, nil
+}
+
+// refreshDiff captures the current rule file as the base of the proposal and diffs the proposed content against it
+func (u *proposalUsecase) refreshDiff(ctx context.Context, proposal *entity.Proposal) error {
+	var current, proposed string
+	var err error
+
+	switch proposal.Type {
+	case entity.ProposalTypeSuppression:
+		current, proposed, err = u.suppressionUsecase.PreviewRuleFile(ctx, proposal.SuppressionID)
+	default:
+		current, _, err = u.ruleFileRepo.GetRuleFile(ctx, proposal.Filename)
+		proposed = proposal.Content
+	}
+	if err != nil {
+		return err
+	}
+
+	proposal.BaseContent = current
+	proposal.BaseHash = hashContent(current)
+	proposal.Diff = textdiff.Unified("a/"+proposal.Filename, "b/"+proposal.Filename, current, proposed)
+	proposal.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// requireRuleFileHash fails when the rule file on the manager no longer has the expected content
+func (u *proposalUsecase) requireRuleFileHash(ctx context.Context, filename string, expected string, stage string) error {
+	current, _, err := u.ruleFileRepo.GetRuleFile(ctx, filename)
+	if err != nil {
+		return err
+	}
+
+	if hashContent(current) != expected {
+		return fmt.Errorf("rule file %s changed since the proposal was %s", filename, stage)
+	}
+
+	return nil
+}
+
+func (u *proposalUsecase) setSuppressionStatus(ctx context.Context, id int, status string) error {
+	suppression, err := u.suppressionRepo.FetchSuppressionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if suppression == nil {
+		return fmt.Errorf("suppression with ID %d not found", id)
+	}
+
+	suppression.Status = status
+	return u.suppressionRepo.UpdateSuppression(ctx, suppression)
+}
+
+func transitionProposal(proposal *entity.Proposal, to string) error {
+	for _, allowed := range proposalTransitions[proposal.Status] {
+		if allowed == to {
+			proposal.Status = to
+			proposal.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+
+	return fmt.Errorf("proposal %d cannot move from %s to %s", proposal.ID, proposal.Status, to)
+}
+
+func sameActor(a string, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memProposals stores copies and only updates a proposal still in the expected status, like the database
+type memProposals struct {
+	mu        sync.Mutex
+	proposals map[int]entity.Proposal
+}
+
+func (m *memProposals) SaveProposal(ctx context.Context, proposal *entity.Proposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	proposal.ID = len(m.proposals) + 1
+	m.proposals[proposal.ID] = *proposal
+	return nil
+}
+
+func (m *memProposals) FetchProposals(ctx context.Context, status string) ([]*entity.Proposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var proposals []*entity.Proposal
+	for id := 1; id <= len(m.proposals); id++ {
+		proposal := m.proposals[id]
+		if status == "" || proposal.Status == status {
+			proposals = append(proposals, &proposal)
+		}
+	}
+	return proposals, nil
+}
+
+func (m *memProposals) FetchProposalByID(ctx context.Context, id int) (*entity.Proposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	proposal, ok := m.proposals[id]
+	if !ok {
+		return nil, nil
+	}
+	return &proposal, nil
+}
+
+func (m *memProposals) UpdateProposal(ctx context.Context, proposal *entity.Proposal, fromStatus string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stored, ok := m.proposals[proposal.ID]; !ok || stored.Status != fromStatus {
+		return sql.ErrNoRows
+	}
+	m.proposals[proposal.ID] = *proposal
+	return nil
+}
+
+// evidenceClosedEvents knows every closed event ID, it only backs the evidence check of new proposals
+type evidenceClosedEvents struct {
+	domain.ClosedEventRepository
+}
+
+func (evidenceClosedEvents) FetchClosedEventByID(ctx context.Context, id string) (*entity.ClosedEvent, error) {
+	return &entity.ClosedEvent{EventID: id}, nil
+}
+
+// newTestProposalUsecase wires the proposal usecase over in-memory stores
+func newTestProposalUsecase() (*proposalUsecase, *memRuleFiles) {
+	files := newMemRuleFiles()
+	suppressions := newMemSuppressions()
+	ruleFiles := NewRuleFileUsecase(files, &memRuleFileVersions{}, suppressions)
+	suppressionUsecase := NewSuppressionUsecase(suppressions, files, ruleFiles)
+	u := NewProposalUsecase(&memProposals{proposals: map[int]entity.Proposal{}}, suppressions, evidenceClosedEvents{}, files, suppressionUsecase, ruleFiles)
+	return u.(*proposalUsecase), files
+}
+
+const (
+	testRuleFile    = "local_rules.xml"
+	testRuleContent = "<group name=\"local,\">\n  <rule id=\"100001\" level=\"5\">\n    <match>sshd</match>\n  </rule>\n</group>\n"
+)
+
+// createApprovedRuleChange creates a rule change by alice and takes it through review by bob
+func createApprovedRuleChange(t *testing.T, u *proposalUsecase) *entity.Proposal {
+	t.Helper()
+	ctx := context.Background()
+
+	proposal, err := u.CreateProposal(ctx, &model.CreateProposalRequest{
+		Type:             entity.ProposalTypeRuleChange,
+		Title:            "Raise sshd rule",
+		Author:           "alice",
+		EvidenceEventIDs: []string{"1"},
+		Filename:         testRuleFile,
+		Content:          strings.Replace(testRuleContent, `level="5"`, `level="7"`, 1),
+	})
+	if err != nil {
+		t.Fatalf("CreateProposal: %v", err)
+	}
+	if _, err := u.SubmitProposal(ctx, proposal.ID, "alice"); err != nil {
+		t.Fatalf("SubmitProposal: %v", err)
+	}
+	if proposal, err = u.ApproveProposal(ctx, proposal.ID, "bob", "ok"); err != nil {
+		t.Fatalf("ApproveProposal: %v", err)
+	}
+	return proposal
+}
+
+func TestTransitionProposal(t *testing.T) {
+	statuses := []string{
+		entity.ProposalStatusDraft,
+		entity.ProposalStatusPendingApproval,
+		entity.ProposalStatusApproved,
+		entity.ProposalStatusRejected,
+		entity.ProposalStatusDeployed,
+		entity.ProposalStatusRolledBack,
+	}
+	allowed := map[[2]string]bool{
+		{entity.ProposalStatusDraft, entity.ProposalStatusPendingApproval}:    true,
+		{entity.ProposalStatusPendingApproval, entity.ProposalStatusApproved}: true,
+		{entity.ProposalStatusPendingApproval, entity.ProposalStatusRejected}: true,
+		{entity.ProposalStatusApproved, entity.ProposalStatusDeployed}:        true,
+		{entity.ProposalStatusDeployed, entity.ProposalStatusRolledBack}:      true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				proposal := &entity.Proposal{ID: 1, Status: from}
+				err := transitionProposal(proposal, to)
+
+				if allowed[[2]string{from, to}] {
+					if err != nil || proposal.Status != to {
+						t.Fatalf("error = %v, status = %s, want a move to %s", err, proposal.Status, to)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), "cannot move") || proposal.Status != from {
+					t.Fatalf("error = %v, status = %s, want the move refused", err, proposal.Status)
+				}
+			})
+		}
+	}
+}
+
+func TestProposalActions(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error)
+		wantErr    string
+		wantStatus string
+	}{
+		{
+			name: "author cannot approve",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				return u.ApproveProposal(context.Background(), id, " Alice ", "")
+			},
+			wantErr:    "approver must differ from the author",
+			wantStatus: entity.ProposalStatusApproved,
+		},
+		{
+			name: "approved proposal cannot be approved again",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				return u.ApproveProposal(context.Background(), id, "carol", "")
+			},
+			wantErr:    "cannot move from approved to approved",
+			wantStatus: entity.ProposalStatusApproved,
+		},
+		{
+			name: "approved proposal cannot be rolled back",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				return u.RollbackProposal(context.Background(), id, "bob", "")
+			},
+			wantErr:    "cannot move from approved to rolled_back",
+			wantStatus: entity.ProposalStatusApproved,
+		},
+		{
+			name: "deploy pushes the proposed content",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				return u.DeployProposal(context.Background(), id, "bob")
+			},
+			wantStatus: entity.ProposalStatusDeployed,
+		},
+		{
+			name: "deploy refused after the rule file changed",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				_ = files.UpdateRuleFile(context.Background(), testRuleFile, testRuleContent+"<!-- edited -->\n")
+				return u.DeployProposal(context.Background(), id, "bob")
+			},
+			wantErr:    "changed since the proposal was submitted",
+			wantStatus: entity.ProposalStatusApproved,
+		},
+		{
+			name: "rollback restores the base content",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				if _, err := u.DeployProposal(context.Background(), id, "bob"); err != nil {
+					return nil, err
+				}
+				return u.RollbackProposal(context.Background(), id, "bob", "noisy")
+			},
+			wantStatus: entity.ProposalStatusRolledBack,
+		},
+		{
+			name: "rollback refused after the rule file changed",
+			action: func(u *proposalUsecase, files *memRuleFiles, id int) (*entity.Proposal, error) {
+				if _, err := u.DeployProposal(context.Background(), id, "bob"); err != nil {
+					return nil, err
+				}
+				_ = files.UpdateRuleFile(context.Background(), testRuleFile, testRuleContent+"<!-- edited -->\n")
+				return u.RollbackProposal(context.Background(), id, "bob", "")
+			},
+			wantErr:    "changed since the proposal was deployed",
+			wantStatus: entity.ProposalStatusDeployed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u, files := newTestProposalUsecase()
+			_ = files.UpdateRuleFile(ctx, testRuleFile, testRuleContent)
+			proposal := createApprovedRuleChange(t, u)
+
+			_, err := tt.action(u, files, proposal.ID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			stored, _ := u.proposalRepo.FetchProposalByID(ctx, proposal.ID)
+			if stored.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+
+			content, _, _ := files.GetRuleFile(ctx, testRuleFile)
+			switch {
+			case tt.wantErr != "":
+			case stored.Status == entity.ProposalStatusDeployed && content != stored.Content:
+				t.Fatalf("rule file is\n%s\nwant the proposed content\n%s", content, stored.Content)
+			case stored.Status == entity.ProposalStatusRolledBack && content != testRuleContent:
+				t.Fatalf("rule file is\n%s\nwant the base content\n%s", content, testRuleContent)
+			}
+		})
+	}
+}
+
+func TestProposalConcurrentActions(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(u *proposalUsecase, id int, actor string) (*entity.Proposal, error)
+	}{
+		{
+			name: "deploy",
+			action: func(u *proposalUsecase, id int, actor string) (*entity.Proposal, error) {
+				return u.DeployProposal(context.Background(), id, actor)
+			},
+		},
+		{
+			name: "approve",
+			action: func(u *proposalUsecase, id int, actor string) (*entity.Proposal, error) {
+				return u.ApproveProposal(context.Background(), id, actor, "")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u, files := newTestProposalUsecase()
+			_ = files.UpdateRuleFile(ctx, testRuleFile, testRuleContent)
+			proposal := createApprovedRuleChange(t, u)
+
+			// Approvals race from pending approval, so put the proposal back under review
+			if tt.name == "approve" {
+				proposal.Status = entity.ProposalStatusPendingApproval
+				if err := u.proposalRepo.UpdateProposal(ctx, proposal, entity.ProposalStatusApproved); err != nil {
+					t.Fatalf("UpdateProposal: %v", err)
+				}
+			}
+
+			actors := []string{"bob", "carol", "dave", "erin"}
+			var wg sync.WaitGroup
+			errs := make(chan error, len(actors))
+			for _, actor := range actors {
+				wg.Add(1)
+				go func(actor string) {
+					defer wg.Done()
+					_, err := tt.action(u, proposal.ID, actor)
+					errs <- err
+				}(actor)
+			}
+			wg.Wait()
+			close(errs)
+
+			succeeded := 0
+			for err := range errs {
+				if err == nil {
+					succeeded++
+				} else if !strings.Contains(err.Error(), "cannot move") {
+					t.Errorf("error = %v, want a conflict", err)
+				}
+			}
+			if succeeded != 1 {
+				t.Fatalf("%d of %d concurrent actions succeeded, want 1", succeeded, len(actors))
+			}
+		})
+	}
+}
+
+func TestUpdateProposalFromStaleStatus(t *testing.T) {
+	ctx := context.Background()
+	u, files := newTestProposalUsecase()
+	_ = files.UpdateRuleFile(ctx, testRuleFile, testRuleContent)
+	proposal := createApprovedRuleChange(t, u)
+
+	// Another request approved the proposal after this one read it as pending approval
+	stale := *proposal
+	stale.Status = entity.ProposalStatusApproved
+	err := u.updateProposal(ctx, &stale, entity.ProposalStatusPendingApproval)
+	if err == nil || !strings.Contains(err.Error(), "another request changed it first") {
+		t.Fatalf("error = %v, want a conflict", err)
+	}
+}
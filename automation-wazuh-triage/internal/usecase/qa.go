@@ -0,0 +1,428 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultQASampleRatePercent and defaultQASampleMinPerRule apply until a policy is saved through the API
+	defaultQASampleRatePercent = 2.0
+	defaultQASampleMinPerRule  = 1
+
+	// qaSampleDayLayout names the UTC day a review was sampled for
+	qaSampleDayLayout = "2006-01-02"
+)
+
+type qaUsecase struct {
+	qaReviewRepo     domain.QAReviewRepository
+	settingRepo      domain.SettingRepository
+	closedEventRepo  domain.ClosedEventRepository
+	decisionRepo     domain.AutoCloseDecisionRepository
+	triageActionRepo domain.TriageActionRepository
+	notifier         *notifier.Notifier
+}
+
+func NewQAUsecase(
+	qaReviewRepo domain.QAReviewRepository,
+	settingRepo domain.SettingRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	decisionRepo domain.AutoCloseDecisionRepository,
+	triageActionRepo domain.TriageActionRepository,
+	notifier *notifier.Notifier,
+) domain.QAUsecase {
+	return &qaUsecase{
+		qaReviewRepo:     qaReviewRepo,
+		settingRepo:      settingRepo,
+		closedEventRepo:  closedEventRepo,
+		decisionRepo:     decisionRepo,
+		triageActionRepo: triageActionRepo,
+		notifier:         notifier,
+	}
+}
+
+// FetchSamplingPolicy returns the saved policy, or the environment defaults when none was saved
+func (u *qaUsecase) FetchSamplingPolicy(ctx context.Context) (*entity.QASamplingPolicy, error) {
+	policy := &entity.QASamplingPolicy{
+		RatePercent: qaSampleRatePercent(),
+		MinPerRule:  qaSampleMinPerRule(),
+	}
+
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeyQASamplingPolicy, policy)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - qa - FetchSamplingPolicy]: Failed to fetch sampling policy")
+		return nil, err
+	}
+
+	if setting != nil {
+		policy.UpdatedBy = setting.UpdatedBy
+		policy.UpdatedAt = &setting.UpdatedAt
+	}
+	return policy, nil
+}
+
+// UpdateSamplingPolicy changes the fields given in the request and keeps the others
+func (u *qaUsecase) UpdateSamplingPolicy(ctx context.Context, request *model.UpdateQASamplingPolicyRequest) (*entity.QASamplingPolicy, error) {
+	log := logger.WithRequestID(ctx)
+
+	updatedBy := strings.TrimSpace(request.UpdatedBy)
+	if updatedBy == "" {
+		return nil, fmt.Errorf("invalid sampling policy: updated_by is required")
+	}
+
+	policy, err := u.FetchSamplingPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.RatePercent != nil {
+		policy.RatePercent = *request.RatePercent
+	}
+	if request.MinPerRule != nil {
+		policy.MinPerRule = *request.MinPerRule
+	}
+
+	if policy.RatePercent <= 0 || policy.RatePercent > 100 {
+		return nil, fmt.Errorf("invalid sampling policy: rate_percent must be greater than 0 and at most 100")
+	}
+	if policy.MinPerRule < 0 {
+		return nil, fmt.Errorf("invalid sampling policy: min_per_rule must not be negative")
+	}
+
+	now := time.Now()
+	policy.UpdatedBy = updatedBy
+	policy.UpdatedAt = &now
+
+	value := entity.QASamplingPolicy{RatePercent: policy.RatePercent, MinPerRule: policy.MinPerRule}
+	if err := saveSetting(ctx, u.settingRepo, entity.SettingKeyQASamplingPolicy, value, updatedBy, now); err != nil {
+		log.WithError(err).Error("[usecase - qa - UpdateSamplingPolicy]: Failed to save sampling policy")
+		return nil, err
+	}
+
+	log.WithField("rate_percent", policy.RatePercent).WithField("min_per_rule", policy.MinPerRule).WithField("updated_by", updatedBy).Info("[usecase - qa - UpdateSamplingPolicy]: Updated sampling policy")
+	return policy, nil
+}
+
+// SampleDay queues a random share of each rule's auto-closures on the given UTC day for review. Running it
+// again tops the sample up to the policy as more events are closed, so it is safe to run repeatedly.
+func (u *qaUsecase) SampleDay(ctx context.Context, day time.Time) ([]*entity.QAReview, error) {
+	log := logger.WithRequestID(ctx)
+
+	policy, err := u.FetchSamplingPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Date(day.UTC().Year(), day.UTC().Month(), day.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	sampleDay := from.Format(qaSampleDayLayout)
+
+	unsampled, err := u.qaReviewRepo.FetchUnsampledAutoClosures(ctx, from, from.Add(24*time.Hour))
+	if err != nil {
+		log.WithError(err).Error("[usecase - qa - SampleDay]: Failed to fetch auto-closures")
+		return nil, err
+	}
+
+	sampled, err := u.qaReviewRepo.CountQAReviewsByRule(ctx, sampleDay)
+	if err != nil {
+		log.WithError(err).Error("[usecase - qa - SampleDay]: Failed to count sampled auto-closures")
+		return nil, err
+	}
+
+	byRule := map[string][]*entity.ClosedEvent{}
+	for _, closedEvent := range unsampled {
+		byRule[closedEvent.RuleID] = append(byRule[closedEvent.RuleID], closedEvent)
+	}
+
+	ruleIDs := make([]string, 0, len(byRule))
+	for ruleID := range byRule {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	reviews := []*entity.QAReview{}
+	for _, ruleID := range ruleIDs {
+		candidates := byRule[ruleID]
+		needed := qaSampleTarget(policy, len(candidates)+sampled[ruleID]) - sampled[ruleID]
+		if needed <= 0 {
+			continue
+		}
+
+		rand.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		if needed < len(candidates) {
+			candidates = candidates[:needed]
+		}
+
+		for _, closedEvent := range candidates {
+			review := &entity.QAReview{
+				ClosedEventID: closedEvent.ID,
+				EventID:       closedEvent.EventID,
+				RuleID:        closedEvent.RuleID,
+				Criterion:     u.closingCriterion(ctx, closedEvent.EventID),
+				SampleDay:     sampleDay,
+				RawEvent:      closedEvent.RawEvent,
+				Status:        entity.QAReviewStatusPending,
+				ClosedAt:      closedEvent.CloseAt,
+				CreatedAt:     time.Now(),
+			}
+
+			queued, err := u.qaReviewRepo.SaveQAReview(ctx, review)
+			if err != nil {
+				return nil, err
+			}
+			if queued {
+				reviews = append(reviews, review)
+			}
+		}
+	}
+
+	log.WithField("sample_day", sampleDay).WithField("auto_closures", len(unsampled)).WithField("queued", len(reviews)).Info("[usecase - qa - SampleDay]: Completed QA sampling")
+	return reviews, nil
+}
+
+// RunScheduledSampling samples yesterday, to catch closures made after its last run, and today
+func (u *qaUsecase) RunScheduledSampling(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	var queued []*entity.QAReview
+	for _, day := range []time.Time{now.Add(-24 * time.Hour), now} {
+		reviews, err := u.SampleDay(ctx, day)
+		if err != nil {
+			return err
+		}
+		queued = append(queued, reviews...)
+	}
+
+	if len(queued) == 0 {
+		return nil
+	}
+
+	return u.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Auto-closed events awaiting QA review",
+		Severity: "info",
+		Message:  fmt.Sprintf("%d auto-closed event(s) were sampled for review", len(queued)),
+		Data:     queued,
+	})
+}
+
+func (u *qaUsecase) FetchQAReviews(ctx context.Context, status string, ruleID string) ([]*entity.QAReview, error) {
+	reviews, err := u.qaReviewRepo.FetchQAReviews(ctx, status, ruleID)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - qa - FetchQAReviews]: Failed to fetch QA reviews")
+		return nil, err
+	}
+
+	if reviews == nil {
+		reviews = []*entity.QAReview{}
+	}
+	return reviews, nil
+}
+
+func (u *qaUsecase) FetchQAReviewByID(ctx context.Context, id int) (*entity.QAReview, error) {
+	review, err := u.qaReviewRepo.FetchQAReviewByID(ctx, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("review_id", id).Error("[usecase - qa - FetchQAReviewByID]: Failed to fetch QA review")
+		return nil, err
+	}
+	if review == nil {
+		return nil, fmt.Errorf("QA review with ID %d not found", id)
+	}
+	return review, nil
+}
+
+// ConfirmQAReview records that the auto-closure was right, labeling the alert a false positive
+func (u *qaUsecase) ConfirmQAReview(ctx context.Context, id int, request *model.QAReviewActionRequest) (*entity.QAReview, error) {
+	log := logger.WithRequestID(ctx)
+
+	review, reviewer, err := u.resolveReview(ctx, id, request, entity.QAReviewStatusConfirmed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.closedEventRepo.UpdateClosedEventLabel(ctx, strconv.Itoa(review.ClosedEventID), entity.LabelFalsePositive); err != nil && err != sql.ErrNoRows {
+		log.WithError(err).WithField("closed_event_id", review.ClosedEventID).Warn("[usecase - qa - ConfirmQAReview]: Failed to label closed event")
+	}
+	u.labelDecision(ctx, review.EventID, entity.LabelFalsePositive, reviewer)
+
+	log.WithField("review_id", id).WithField("reviewer", reviewer).Info("[usecase - qa - ConfirmQAReview]: Auto-closure confirmed")
+	return u.FetchQAReviewByID(ctx, id)
+}
+
+// OverturnQAReview records that the auto-closure was wrong. The event is reopened by removing its closure,
+// the alert is labeled a true positive and the criterion that closed it is flagged.
+func (u *qaUsecase) OverturnQAReview(ctx context.Context, id int, request *model.QAReviewActionRequest) (*entity.QAReview, error) {
+	log := logger.WithRequestID(ctx)
+
+	review, reviewer, err := u.resolveReview(ctx, id, request, entity.QAReviewStatusOverturned)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.closedEventRepo.DeleteClosedEvent(ctx, strconv.Itoa(review.ClosedEventID)); err != nil && err != sql.ErrNoRows {
+		log.WithError(err).WithField("closed_event_id", review.ClosedEventID).Error("[usecase - qa - OverturnQAReview]: Failed to reopen event")
+		return nil, err
+	}
+
+	triageAction := newTriageAction(review.EventID, review.RuleID, review.RawEvent, entity.TriageActionReopened, reviewer)
+	if err := u.triageActionRepo.SaveTriageAction(ctx, triageAction); err != nil {
+		log.WithError(err).WithField("event_id", review.EventID).Warn("[usecase - qa - OverturnQAReview]: Failed to record triage action")
+	}
+	u.labelDecision(ctx, review.EventID, entity.LabelTruePositive, reviewer)
+
+	review, err = u.FetchQAReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	criterion := review.Criterion
+	if criterion == "" {
+		criterion = "unknown"
+	}
+	log.WithField("review_id", id).WithField("event_id", review.EventID).WithField("criterion", criterion).Warn("[usecase - qa - OverturnQAReview]: Auto-closure overturned, event reopened and criterion flagged")
+
+	if err := u.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Auto-close criterion flagged by QA",
+		Severity: "critical",
+		Message:  fmt.Sprintf("%s overturned the auto-closure of event %s (rule %s, criterion %s); the event was reopened", reviewer, review.EventID, review.RuleID, criterion),
+		Data:     review,
+	}); err != nil {
+		log.WithError(err).Warn("[usecase - qa - OverturnQAReview]: Failed to send notification")
+	}
+
+	return review, nil
+}
+
+// FetchCriterionReports summarises the reviews per auto-close criterion, flagged criteria first
+func (u *qaUsecase) FetchCriterionReports(ctx context.Context) ([]*entity.QACriterionReport, error) {
+	reviews, err := u.FetchQAReviews(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	byCriterion := map[string]*entity.QACriterionReport{}
+	reports := []*entity.QACriterionReport{}
+
+	for _, review := range reviews {
+		report, ok := byCriterion[review.Criterion]
+		if !ok {
+			report = &entity.QACriterionReport{Criterion: review.Criterion}
+			byCriterion[review.Criterion] = report
+			reports = append(reports, report)
+		}
+
+		report.Sampled++
+		switch review.Status {
+		case entity.QAReviewStatusPending:
+			report.Pending++
+		case entity.QAReviewStatusConfirmed:
+			report.Confirmed++
+		case entity.QAReviewStatusOverturned:
+			report.Overturned++
+			report.Flagged = true
+			if review.ReviewedAt != nil && (report.LastOverturnedAt == nil || review.ReviewedAt.After(*report.LastOverturnedAt)) {
+				report.LastOverturnedAt = review.ReviewedAt
+			}
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Overturned != reports[j].Overturned {
+			return reports[i].Overturned > reports[j].Overturned
+		}
+		if reports[i].Sampled != reports[j].Sampled {
+			return reports[i].Sampled > reports[j].Sampled
+		}
+		return reports[i].Criterion < reports[j].Criterion
+	})
+
+	return reports, nil
+}
+
+// resolveReview moves a pending review to the given status and returns it with the trimmed reviewer
+func (u *qaUsecase) resolveReview(ctx context.Context, id int, request *model.QAReviewActionRequest, status string) (*entity.QAReview, string, error) {
+	reviewer := strings.TrimSpace(request.Reviewer)
+	if reviewer == "" {
+		return nil, "", fmt.Errorf("invalid QA review: reviewer is required")
+	}
+
+	review, err := u.FetchQAReviewByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if review.Status != entity.QAReviewStatusPending {
+		return nil, "", fmt.Errorf("QA review %d is already %s", id, review.Status)
+	}
+
+	if err := u.qaReviewRepo.ResolveQAReview(ctx, id, status, reviewer, strings.TrimSpace(request.Note), time.Now()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", fmt.Errorf("QA review %d is already resolved", id)
+		}
+		logger.WithRequestID(ctx).WithError(err).WithField("review_id", id).Error("[usecase - qa - resolveReview]: Failed to resolve QA review")
+		return nil, "", err
+	}
+
+	return review, reviewer, nil
+}
+
+// closingCriterion returns the criterion of the enforced auto-close decision on an event, empty when none was recorded
+func (u *qaUsecase) closingCriterion(ctx context.Context, eventID string) string {
+	decision, err := u.decisionRepo.FetchDecisionByEventID(ctx, eventID, entity.AutoCloseModeEnforce)
+	if err != nil || decision == nil {
+		return ""
+	}
+	return decision.Criterion
+}
+
+// labelDecision copies a QA verdict to the enforced decision, so it counts in the auto-close evaluation
+func (u *qaUsecase) labelDecision(ctx context.Context, eventID string, label string, reviewer string) {
+	decision, err := u.decisionRepo.FetchDecisionByEventID(ctx, eventID, entity.AutoCloseModeEnforce)
+	if err != nil || decision == nil {
+		return
+	}
+
+	if err := u.decisionRepo.UpdateDecisionLabel(ctx, decision.ID, label, reviewer, time.Now()); err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("decision_id", decision.ID).Warn("[usecase - qa - labelDecision]: Failed to label auto-close decision")
+	}
+}
+
+// qaSampleTarget is the number of a rule's closures on one day that should be under review
+func qaSampleTarget(policy *entity.QASamplingPolicy, closures int) int {
+	target := int(math.Ceil(float64(closures) * policy.RatePercent / 100))
+	if target < policy.MinPerRule {
+		target = policy.MinPerRule
+	}
+	if target > closures {
+		target = closures
+	}
+	return target
+}
+
+func qaSampleRatePercent() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("QA_SAMPLE_RATE_PERCENT"), 64)
+	if err != nil || rate <= 0 || rate > 100 {
+		return defaultQASampleRatePercent
+	}
+	return rate
+}
+
+func qaSampleMinPerRule() int {
+	count, err := strconv.Atoi(os.Getenv("QA_SAMPLE_MIN_PER_RULE"))
+	if err != nil || count < 0 {
+		return defaultQASampleMinPerRule
+	}
+	return count
+}
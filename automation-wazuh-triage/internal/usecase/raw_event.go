@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"encoding/json"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// alertTimestampLayouts are the timestamp formats written by the Wazuh indexer templates
+var alertTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-0700",
+	"2006-01-02T15:04:05-0700",
+}
+
+// alertSource holds the fields of a stored search hit that triage bookkeeping needs
+type alertSource struct {
+	Timestamp string `json:"timestamp"`
+	Agent     struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		IP   string `json:"ip"`
+	} `json:"agent"`
+	Rule struct {
+		ID          string `json:"id"`
+		Level       *int   `json:"level"`
+		Description string `json:"description"`
+		Mitre       struct {
+			ID []string `json:"id"`
+		} `json:"mitre"`
+		PciDss    []string `json:"pci_dss"`
+		Gdpr      []string `json:"gdpr"`
+		Hipaa     []string `json:"hipaa"`
+		Nist80053 []string `json:"nist_800_53"`
+		Tsc       []string `json:"tsc"`
+	} `json:"rule"`
+	Data struct {
+		SrcIP         string `json:"srcip"`
+		DstIP         string `json:"dstip"`
+		URL           string `json:"url"`
+		Vulnerability struct {
+			CVE      string `json:"cve"`
+			Severity string `json:"severity"`
+			Status   string `json:"status"` // Active, or Solved once the package was fixed
+			Package  struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"package"`
+		} `json:"vulnerability"` // set on vulnerability-detector alerts
+		SrcUser string `json:"srcuser"`
+		DstUser string `json:"dstuser"`
+		Win     struct {
+			EventData struct {
+				TargetUserName string `json:"targetUserName"`
+			} `json:"eventdata"`
+		} `json:"win"`
+	} `json:"data"`
+	Sequence struct {
+		Rule      string            `json:"rule"`
+		Key       string            `json:"key"`
+		KeyValues map[string]string `json:"key_values"`
+	} `json:"sequence"` // set on the synthetic alerts of sequence findings
+	Maintenance struct {
+		WindowID int    `json:"window_id"`
+		Name     string `json:"name"`
+		Policy   string `json:"policy"`
+		Outcome  string `json:"outcome"`
+	} `json:"maintenance"` // set on alerts that fired during a maintenance window
+	Syscheck struct {
+		Path        string `json:"path"`
+		MD5After    string `json:"md5_after"`
+		SHA1After   string `json:"sha1_after"`
+		SHA256After string `json:"sha256_after"`
+	} `json:"syscheck"`
+	IOC []entity.IOCMatch `json:"ioc"` // set on alerts matching threat-intel indicators
+}
+
+// parseAlertSource reads the alert fields from a stored search hit
+func parseAlertSource(rawEvent string) (alertSource, bool) {
+	var hit struct {
+		Source alertSource `json:"_source"`
+	}
+
+	if err := json.Unmarshal([]byte(rawEvent), &hit); err != nil {
+		return alertSource{}, false
+	}
+	return hit.Source, true
+}
+
+// decodeAlertSource reads the alert fields from the _source of a search hit
+func decodeAlertSource(source []byte) (alertSource, bool) {
+	var alert alertSource
+	if err := json.Unmarshal(source, &alert); err != nil {
+		return alertSource{}, false
+	}
+	return alert, true
+}
+
+// alertTimestamp returns when the alert behind a closed event fired, read from the stored search hit
+func alertTimestamp(rawEvent string) (time.Time, bool) {
+	source, ok := parseAlertSource(rawEvent)
+	if !ok {
+		return time.Time{}, false
+	}
+	return parseAlertTimestamp(source.Timestamp)
+}
+
+// parseAlertTimestamp parses an alert timestamp in any of the indexer formats
+func parseAlertTimestamp(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	for _, layout := range alertTimestampLayouts {
+		if firedAt, err := time.Parse(layout, value); err == nil {
+			return firedAt, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// alertAgentID returns the ID of the agent that raised the alert behind a stored search hit
+func alertAgentID(rawEvent string) string {
+	source, _ := parseAlertSource(rawEvent)
+	return source.Agent.ID
+}
+
+// alertAgentName returns the agent that raised the alert behind a stored search hit
+func alertAgentName(rawEvent string) string {
+	source, _ := parseAlertSource(rawEvent)
+	return source.Agent.Name
+}
+
+// alertRuleLevel returns the level of the rule that raised the alert behind a stored search hit
+func alertRuleLevel(rawEvent string) (int, bool) {
+	source, ok := parseAlertSource(rawEvent)
+	if !ok || source.Rule.Level == nil {
+		return 0, false
+	}
+	return *source.Rule.Level, true
+}
+
+// alertUser returns the user an alert is about, preferring the target user over the source user
+func alertUser(source alertSource) string {
+	for _, user := range []string{source.Data.DstUser, source.Data.Win.EventData.TargetUserName, source.Data.SrcUser} {
+		if user != "" {
+			return user
+		}
+	}
+	return ""
+}
+
+// withSourceField returns a copy of the hit with value set under key in its source, or the hit itself when the
+// source is not a JSON object
+func withSourceField(hit *elastic.SearchHit, key string, value interface{}) *elastic.SearchHit {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(hit.Source, &document); err != nil || document == nil {
+		return hit
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return hit
+	}
+	document[key] = encoded
+
+	source, err := json.Marshal(document)
+	if err != nil {
+		return hit
+	}
+
+	tagged := *hit
+	tagged.Source = source
+	return &tagged
+}
@@ -0,0 +1,591 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRetroHuntWindow is how far back a hunt searches when the request does not say
+	defaultRetroHuntWindow = 90 * 24 * time.Hour
+	maxRetroHuntWindow     = 365 * 24 * time.Hour
+
+	maxRetroHuntIndicators = 5000
+
+	// a hunt searches for retroHuntChunkSize indicators at a time, reading retroHuntPageSize documents per page,
+	// and stops once it recorded maxRetroHuntMatches matches
+	retroHuntChunkSize  = 100
+	retroHuntPageSize   = 500
+	maxRetroHuntMatches = 10000
+
+	// maxConcurrentRetroHunts bounds the hunts searching the indexer at once; the others wait queued
+	maxConcurrentRetroHunts = 2
+
+	retroHuntAlertsIndex = "wazuh-alerts-*"
+
+	// retroHuntInterrupted is the error of a hunt the service stopped running, e.g. by restarting
+	retroHuntInterrupted = "interrupted: the service restarted while the hunt ran"
+)
+
+type retroHuntUsecase struct {
+	retroHuntRepo  domain.RetroHuntRepository
+	wazuhEventRepo domain.WazuhEventRepository
+	notifier       *notifier.Notifier
+
+	slots chan struct{}
+
+	// running holds the hunts queued or running in this process
+	mu      sync.Mutex
+	running map[int]bool
+}
+
+func NewRetroHuntUsecase(
+	retroHuntRepo domain.RetroHuntRepository,
+	wazuhEventRepo domain.WazuhEventRepository,
+	notifier *notifier.Notifier,
+) domain.RetroHuntUsecase {
+	return &retroHuntUsecase{
+		retroHuntRepo:  retroHuntRepo,
+		wazuhEventRepo: wazuhEventRepo,
+		notifier:       notifier,
+		slots:          make(chan struct{}, maxConcurrentRetroHunts),
+		running:        map[int]bool{},
+	}
+}
+
+// CreateRetroHunt validates the indicators, saves the hunt as queued and runs it in the background
+func (u *retroHuntUsecase) CreateRetroHunt(ctx context.Context, request *model.CreateRetroHuntRequest) (*entity.RetroHunt, error) {
+	log := logger.WithRequestID(ctx)
+
+	indicators := []entity.RetroHuntIndicator{}
+	seen := map[string]bool{}
+	for _, value := range cleanList(request.Indicators) {
+		indicator, err := parseRetroHuntIndicator(value)
+		if err != nil {
+			return nil, err
+		}
+		key := indicator.Type + "|" + indicator.Value
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		indicators = append(indicators, indicator)
+	}
+	if len(indicators) == 0 {
+		return nil, fmt.Errorf("invalid retro-hunt: indicators are required")
+	}
+	if len(indicators) > maxRetroHuntIndicators {
+		return nil, fmt.Errorf("invalid retro-hunt: at most %d indicators may be hunted at once", maxRetroHuntIndicators)
+	}
+
+	window := defaultRetroHuntWindow
+	if value := strings.TrimSpace(request.Window); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid retro-hunt: window must be a positive duration such as 720h")
+		}
+		window = duration
+	}
+	if window > maxRetroHuntWindow {
+		return nil, fmt.Errorf("invalid retro-hunt: window may be at most %s", maxRetroHuntWindow)
+	}
+
+	indices := []string{retroHuntAlertsIndex}
+	archiveIndex := strings.TrimSpace(os.Getenv("RETRO_HUNT_ARCHIVE_INDEX"))
+	switch {
+	case request.IncludeArchives == nil:
+		if archiveIndex != "" {
+			indices = append(indices, archiveIndex)
+		}
+	case *request.IncludeArchives:
+		if archiveIndex == "" {
+			return nil, fmt.Errorf("invalid retro-hunt: include_archives needs RETRO_HUNT_ARCHIVE_INDEX to be set")
+		}
+		indices = append(indices, archiveIndex)
+	}
+
+	name := strings.TrimSpace(request.Name)
+	switch {
+	case name != "":
+	case len(indicators) == 1:
+		name = indicators[0].Value
+	default:
+		name = fmt.Sprintf("%d indicators", len(indicators))
+	}
+
+	now := time.Now().UTC()
+	hunt := &entity.RetroHunt{
+		Name:       name,
+		Indicators: indicators,
+		Indices:    indices,
+		From:       now.Add(-window),
+		To:         now,
+		Status:     entity.RetroHuntStatusQueued,
+		CreatedBy:  strings.TrimSpace(request.CreatedBy),
+		CreatedAt:  now,
+	}
+
+	if err := u.retroHuntRepo.SaveRetroHunt(ctx, hunt); err != nil {
+		log.WithError(err).Error("[usecase - retro_hunt - CreateRetroHunt]: Failed to save retro-hunt")
+		return nil, err
+	}
+
+	u.mu.Lock()
+	u.running[hunt.ID] = true
+	u.mu.Unlock()
+
+	go u.runRetroHunt(hunt)
+
+	log.WithField("hunt_id", hunt.ID).WithField("indicators", len(indicators)).WithField("indices", indices).WithField("created_by", hunt.CreatedBy).Info("[usecase - retro_hunt - CreateRetroHunt]: Retro-hunt queued")
+	return hunt, nil
+}
+
+func (u *retroHuntUsecase) FetchRetroHunts(ctx context.Context) ([]*entity.RetroHunt, error) {
+	log := logger.WithRequestID(ctx)
+
+	hunts, err := u.retroHuntRepo.FetchRetroHunts(ctx)
+	if err != nil {
+		log.WithError(err).Error("[usecase - retro_hunt - FetchRetroHunts]: Failed to fetch retro-hunts")
+		return nil, err
+	}
+
+	for _, hunt := range hunts {
+		if err := u.summarizeRetroHunt(ctx, hunt); err != nil {
+			return nil, err
+		}
+	}
+
+	return hunts, nil
+}
+
+// FetchRetroHuntByID returns a hunt with its matches counted by current triage state and by indicator
+func (u *retroHuntUsecase) FetchRetroHuntByID(ctx context.Context, id int) (*entity.RetroHunt, error) {
+	log := logger.WithRequestID(ctx)
+
+	hunt, err := u.retroHuntRepo.FetchRetroHuntByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", id).Error("[usecase - retro_hunt - FetchRetroHuntByID]: Failed to fetch retro-hunt")
+		return nil, err
+	}
+	if hunt == nil {
+		return nil, fmt.Errorf("retro-hunt %d not found", id)
+	}
+
+	if err := u.summarizeRetroHunt(ctx, hunt); err != nil {
+		return nil, err
+	}
+
+	return hunt, nil
+}
+
+// FetchRetroHuntMatches returns the matches of a hunt, newest alert first, with the current triage state of
+// each alert
+func (u *retroHuntUsecase) FetchRetroHuntMatches(ctx context.Context, id int, filter *model.RetroHuntMatchFilter) ([]*entity.RetroHuntMatch, error) {
+	log := logger.WithRequestID(ctx)
+
+	triageStates := []string{
+		entity.RetroHuntTriageOpen,
+		entity.RetroHuntTriageAcknowledged,
+		entity.RetroHuntTriageClosed,
+		entity.RetroHuntTriageArchived,
+	}
+	if filter.TriageState != "" && !containsString(triageStates, filter.TriageState) {
+		return nil, fmt.Errorf("invalid triage_state %q, expected one of %s", filter.TriageState, strings.Join(triageStates, ", "))
+	}
+
+	hunt, err := u.retroHuntRepo.FetchRetroHuntByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", id).Error("[usecase - retro_hunt - FetchRetroHuntMatches]: Failed to fetch retro-hunt")
+		return nil, err
+	}
+	if hunt == nil {
+		return nil, fmt.Errorf("retro-hunt %d not found", id)
+	}
+
+	matches, err := u.retroHuntRepo.FetchRetroHuntMatches(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", id).Error("[usecase - retro_hunt - FetchRetroHuntMatches]: Failed to fetch retro-hunt matches")
+		return nil, err
+	}
+
+	indicator := strings.ToLower(strings.TrimSpace(filter.Indicator))
+	if parsed, err := parseRetroHuntIndicator(indicator); err == nil {
+		indicator = parsed.Value
+	}
+
+	filtered := []*entity.RetroHuntMatch{}
+	for _, match := range matches {
+		if indicator != "" && match.Indicator != indicator {
+			continue
+		}
+		if filter.TriageState != "" && match.TriageState != filter.TriageState {
+			continue
+		}
+		if filter.AutoClosed && !match.AutoClosed {
+			continue
+		}
+		filtered = append(filtered, match)
+	}
+
+	return filtered, nil
+}
+
+// runRetroHunt waits for a free slot, searches the hunt's indices and records how it ended
+func (u *retroHuntUsecase) runRetroHunt(hunt *entity.RetroHunt) {
+	ctx := context.WithValue(context.Background(), "request_id", fmt.Sprintf("retro-hunt-%d", hunt.ID))
+	log := logger.WithRequestID(ctx)
+
+	defer func() {
+		u.mu.Lock()
+		delete(u.running, hunt.ID)
+		u.mu.Unlock()
+	}()
+
+	u.slots <- struct{}{}
+	defer func() { <-u.slots }()
+
+	if err := u.retroHuntRepo.StartRetroHunt(ctx, hunt.ID, time.Now().UTC()); err != nil {
+		log.WithError(err).WithField("hunt_id", hunt.ID).Error("[usecase - retro_hunt - runRetroHunt]: Failed to start retro-hunt")
+		return
+	}
+	log.WithField("hunt_id", hunt.ID).Info("[usecase - retro_hunt - runRetroHunt]: Retro-hunt started")
+
+	truncated, err := u.searchRetroHunt(ctx, hunt)
+
+	status, errMessage := entity.RetroHuntStatusCompleted, ""
+	if err != nil {
+		status, errMessage = entity.RetroHuntStatusFailed, err.Error()
+		log.WithError(err).WithField("hunt_id", hunt.ID).Error("[usecase - retro_hunt - runRetroHunt]: Retro-hunt failed")
+	}
+
+	if err := u.retroHuntRepo.FinishRetroHunt(ctx, hunt.ID, status, errMessage, truncated, time.Now().UTC()); err != nil {
+		log.WithError(err).WithField("hunt_id", hunt.ID).Error("[usecase - retro_hunt - runRetroHunt]: Failed to finish retro-hunt")
+		return
+	}
+	hunt.Status, hunt.Error, hunt.Truncated = status, errMessage, truncated
+
+	if err := u.summarizeRetroHunt(ctx, hunt); err != nil {
+		return
+	}
+	log.WithField("hunt_id", hunt.ID).WithField("status", status).WithField("matched_alerts", hunt.Summary.MatchedAlerts).Info("[usecase - retro_hunt - runRetroHunt]: Retro-hunt finished")
+
+	u.notifyRetroHunt(ctx, hunt)
+}
+
+// searchRetroHunt pages through the documents of each index that may hold a chunk of the indicators, confirms
+// the indicators in each document and records a match per document and indicator. It reports whether it
+// stopped at the match limit.
+func (u *retroHuntUsecase) searchRetroHunt(ctx context.Context, hunt *entity.RetroHunt) (bool, error) {
+	log := logger.WithRequestID(ctx)
+
+	searched, matched := 0, 0
+
+	for _, index := range hunt.Indices {
+		for start := 0; start < len(hunt.Indicators); start += retroHuntChunkSize {
+			end := start + retroHuntChunkSize
+			if end > len(hunt.Indicators) {
+				end = len(hunt.Indicators)
+			}
+
+			byType := map[string][]string{}
+			wanted := map[string]bool{}
+			for _, indicator := range hunt.Indicators[start:end] {
+				byType[indicator.Type] = append(byType[indicator.Type], indicator.Value)
+				wanted[indicator.Type+"|"+indicator.Value] = true
+			}
+
+			var searchAfter []interface{}
+			for {
+				hits, err := u.wazuhEventRepo.FetchIndicatorAlerts(ctx, index, byType, hunt.From, hunt.To, searchAfter, retroHuntPageSize)
+				if err != nil {
+					log.WithError(err).WithField("hunt_id", hunt.ID).WithField("index", index).Error("[usecase - retro_hunt - searchRetroHunt]: Failed to fetch indicator alerts")
+					return false, err
+				}
+
+				for _, hit := range hits {
+					source, ok := decodeAlertSource(hit.Source)
+					if !ok {
+						continue
+					}
+
+					eventID := hit.Id
+					var securityEvent entity.WazuhSecurityEvent
+					if err := json.Unmarshal(hit.Source, &securityEvent); err == nil && len(securityEvent.ID) > 0 {
+						eventID = string(securityEvent.ID)
+					}
+					alertAt, _ := parseAlertTimestamp(source.Timestamp)
+					level := 0
+					if source.Rule.Level != nil {
+						level = *source.Rule.Level
+					}
+
+					for _, found := range matchRetroHuntIndicators(hit.Source, source, wanted) {
+						match := &entity.RetroHuntMatch{
+							HuntID:          hunt.ID,
+							EventID:         eventID,
+							Index:           hit.Index,
+							AlertAt:         alertAt.UTC(),
+							RuleID:          source.Rule.ID,
+							RuleLevel:       level,
+							RuleDescription: source.Rule.Description,
+							AgentID:         source.Agent.ID,
+							AgentName:       source.Agent.Name,
+							IndicatorType:   found.indicator.Type,
+							Indicator:       found.indicator.Value,
+							Observed:        found.observed,
+							Fields:          found.fields,
+							Archived:        index != retroHuntAlertsIndex,
+							MatchedAt:       time.Now().UTC(),
+						}
+
+						saved, err := u.retroHuntRepo.SaveRetroHuntMatch(ctx, match, hit.Id)
+						if err != nil {
+							log.WithError(err).WithField("hunt_id", hunt.ID).Error("[usecase - retro_hunt - searchRetroHunt]: Failed to save retro-hunt match")
+							return false, err
+						}
+						if !saved {
+							continue
+						}
+
+						matched++
+						if matched >= maxRetroHuntMatches {
+							log.WithField("hunt_id", hunt.ID).Warn("[usecase - retro_hunt - searchRetroHunt]: Retro-hunt reached its match limit")
+							return true, u.retroHuntRepo.UpdateRetroHuntSearched(ctx, hunt.ID, searched)
+						}
+					}
+				}
+
+				searched += len(hits)
+				if err := u.retroHuntRepo.UpdateRetroHuntSearched(ctx, hunt.ID, searched); err != nil {
+					return false, err
+				}
+
+				if len(hits) < retroHuntPageSize || len(hits[len(hits)-1].Sort) == 0 {
+					break
+				}
+				searchAfter = hits[len(hits)-1].Sort
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// summarizeRetroHunt counts the matched alerts of a hunt by current triage state and by indicator. A hunt left
+// queued or running by a previous run of the service is marked failed first.
+func (u *retroHuntUsecase) summarizeRetroHunt(ctx context.Context, hunt *entity.RetroHunt) error {
+	log := logger.WithRequestID(ctx)
+
+	if hunt.Status == entity.RetroHuntStatusQueued || hunt.Status == entity.RetroHuntStatusRunning {
+		u.mu.Lock()
+		running := u.running[hunt.ID]
+		u.mu.Unlock()
+
+		if !running {
+			finishedAt := time.Now().UTC()
+			if err := u.retroHuntRepo.FinishRetroHunt(ctx, hunt.ID, entity.RetroHuntStatusFailed, retroHuntInterrupted, hunt.Truncated, finishedAt); err != nil {
+				log.WithError(err).WithField("hunt_id", hunt.ID).Error("[usecase - retro_hunt - summarizeRetroHunt]: Failed to mark interrupted retro-hunt")
+				return err
+			}
+			hunt.Status, hunt.Error, hunt.FinishedAt = entity.RetroHuntStatusFailed, retroHuntInterrupted, &finishedAt
+		}
+	}
+
+	matches, err := u.retroHuntRepo.FetchRetroHuntMatches(ctx, hunt.ID)
+	if err != nil {
+		log.WithError(err).WithField("hunt_id", hunt.ID).Error("[usecase - retro_hunt - summarizeRetroHunt]: Failed to fetch retro-hunt matches")
+		return err
+	}
+
+	summary := entity.RetroHuntSummary{Matches: len(matches)}
+	hits := map[string]*entity.RetroHuntHits{}
+	alerts := map[string]bool{}
+	indicatorAlerts := map[string]bool{}
+
+	for _, match := range matches {
+		alert := match.Index + "/" + match.EventID
+
+		key := match.IndicatorType + "|" + match.Indicator
+		indicatorHits, ok := hits[key]
+		if !ok {
+			indicatorHits = &entity.RetroHuntHits{
+				RetroHuntIndicator: entity.RetroHuntIndicator{Type: match.IndicatorType, Value: match.Indicator},
+				FirstSeen:          match.AlertAt,
+				LastSeen:           match.AlertAt,
+			}
+			hits[key] = indicatorHits
+		}
+		if !indicatorAlerts[key+"|"+alert] {
+			indicatorAlerts[key+"|"+alert] = true
+			indicatorHits.Alerts++
+			if match.AutoClosed {
+				indicatorHits.AutoClosed++
+			}
+		}
+		if match.AlertAt.Before(indicatorHits.FirstSeen) {
+			indicatorHits.FirstSeen = match.AlertAt
+		}
+		if match.AlertAt.After(indicatorHits.LastSeen) {
+			indicatorHits.LastSeen = match.AlertAt
+		}
+
+		if alerts[alert] {
+			continue
+		}
+		alerts[alert] = true
+		summary.MatchedAlerts++
+
+		switch match.TriageState {
+		case entity.RetroHuntTriageOpen:
+			summary.Open++
+		case entity.RetroHuntTriageAcknowledged:
+			summary.Acknowledged++
+		case entity.RetroHuntTriageClosed:
+			summary.Closed++
+		case entity.RetroHuntTriageArchived:
+			summary.Archived++
+		}
+		if match.AutoClosed {
+			summary.AutoClosed++
+		}
+	}
+
+	summary.MatchedIndicators = len(hits)
+	summary.AnyAutoClosed = summary.AutoClosed > 0
+
+	counts := make([]entity.RetroHuntHits, 0, len(hits))
+	for _, indicatorHits := range hits {
+		counts = append(counts, *indicatorHits)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Alerts != counts[j].Alerts {
+			return counts[i].Alerts > counts[j].Alerts
+		}
+		return counts[i].Value < counts[j].Value
+	})
+
+	hunt.Summary = summary
+	hunt.IndicatorCounts = counts
+	return nil
+}
+
+func (u *retroHuntUsecase) notifyRetroHunt(ctx context.Context, hunt *entity.RetroHunt) {
+	log := logger.WithRequestID(ctx)
+
+	severity := "info"
+	message := fmt.Sprintf("Retro-hunt #%d (%s) matched %d alerts for %d of %d indicators between %s and %s",
+		hunt.ID, hunt.Name, hunt.Summary.MatchedAlerts, hunt.Summary.MatchedIndicators, len(hunt.Indicators),
+		hunt.From.Format(time.RFC3339), hunt.To.Format(time.RFC3339))
+	switch {
+	case hunt.Status == entity.RetroHuntStatusFailed:
+		severity = "warning"
+		message += "; it failed: " + hunt.Error
+	case hunt.Truncated:
+		message += "; it stopped at its match limit"
+	}
+	if hunt.Summary.AnyAutoClosed {
+		severity = "warning"
+		message += fmt.Sprintf("; %d matched alerts had been auto-closed", hunt.Summary.AutoClosed)
+	}
+
+	if err := u.notifier.Notify(ctx, notifier.Notification{
+		Title:    fmt.Sprintf("Retro-hunt #%d %s", hunt.ID, hunt.Status),
+		Severity: severity,
+		Message:  message,
+		Data:     map[string]interface{}{"retro_hunt": hunt},
+	}); err != nil {
+		log.WithError(err).Warn("[usecase - retro_hunt - notifyRetroHunt]: Failed to send notification")
+	}
+}
+
+// parseRetroHuntIndicator reads an address, a domain or a hash in the form indicators are compared in
+func parseRetroHuntIndicator(value string) (entity.RetroHuntIndicator, error) {
+	ioc, err := newIOC(value, "", "", 0, nil)
+	if err != nil || strings.Contains(ioc.Value, "/") {
+		return entity.RetroHuntIndicator{}, fmt.Errorf("invalid indicator %q: expected an address, a domain or an MD5, SHA-1 or SHA-256 hash", value)
+	}
+	return entity.RetroHuntIndicator{Type: ioc.Type, Value: ioc.Value}, nil
+}
+
+// retroHuntFound is an indicator confirmed in a document, with the value seen and the fields it was seen in
+type retroHuntFound struct {
+	indicator entity.RetroHuntIndicator
+	observed  string
+	fields    []string
+}
+
+// matchRetroHuntIndicators confirms which of the wanted indicators a candidate document holds, as the search
+// may return documents that merely mention a value in passing. Domains also match their subdomains.
+func matchRetroHuntIndicators(raw []byte, source alertSource, wanted map[string]bool) []retroHuntFound {
+	found := map[string]*retroHuntFound{}
+	add := func(iocType string, indicator string, observed string, fields []string) {
+		key := iocType + "|" + indicator
+		if !wanted[key] {
+			return
+		}
+		match, ok := found[key]
+		if !ok {
+			match = &retroHuntFound{
+				indicator: entity.RetroHuntIndicator{Type: iocType, Value: indicator},
+				observed:  observed,
+				fields:    []string{},
+			}
+			found[key] = match
+		}
+		for _, field := range fields {
+			if !containsString(match.fields, field) {
+				match.fields = append(match.fields, field)
+			}
+		}
+	}
+
+	// the agent address and syscheck hashes sit outside the data fields observables are extracted from
+	if value, err := normalizeIOCValue(entity.IOCTypeIP, source.Agent.IP); err == nil {
+		add(entity.IOCTypeIP, value, source.Agent.IP, []string{"agent.ip"})
+	}
+	for field, hash := range map[string]string{
+		"syscheck.md5_after":    source.Syscheck.MD5After,
+		"syscheck.sha1_after":   source.Syscheck.SHA1After,
+		"syscheck.sha256_after": source.Syscheck.SHA256After,
+	} {
+		if hash != "" {
+			add(entity.IOCTypeHash, strings.ToLower(hash), hash, []string{field})
+		}
+	}
+
+	for _, observable := range extractObservables(raw) {
+		switch observable.Type {
+		case entity.ObservableTypeIPv4, entity.ObservableTypeIPv6:
+			if value, err := normalizeIOCValue(entity.IOCTypeIP, observable.Value); err == nil {
+				add(entity.IOCTypeIP, value, observable.Value, observable.Fields)
+			}
+		case entity.ObservableTypeDomain:
+			for domain := observable.Value; strings.Contains(domain, "."); domain = domain[strings.Index(domain, ".")+1:] {
+				add(entity.IOCTypeDomain, domain, observable.Value, observable.Fields)
+			}
+		case entity.ObservableTypeMD5, entity.ObservableTypeSHA1, entity.ObservableTypeSHA256:
+			add(entity.IOCTypeHash, observable.Value, observable.Value, observable.Fields)
+		}
+	}
+
+	matches := make([]retroHuntFound, 0, len(found))
+	for _, match := range found {
+		sort.Strings(match.fields)
+		matches = append(matches, *match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].indicator.Value < matches[j].indicator.Value
+	})
+	return matches
+}
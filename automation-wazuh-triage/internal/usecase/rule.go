@@ -3,23 +3,79 @@ package usecase
 import (
 	"automation-wazuh-triage/internal/domain"
 	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
 	"context"
 )
 
 type ruleUsecase struct {
-	ruleRepo domain.RuleRepository
+	ruleRepo     domain.RuleRepository
+	mitreUsecase domain.MitreUsecase
 }
 
-func NewRuleUsecase(ruleRepo domain.RuleRepository) domain.RuleUsecase {
+func NewRuleUsecase(ruleRepo domain.RuleRepository, mitreUsecase domain.MitreUsecase) domain.RuleUsecase {
 	return &ruleUsecase{
-		ruleRepo: ruleRepo,
+		ruleRepo:     ruleRepo,
+		mitreUsecase: mitreUsecase,
 	}
 }
 
 func (u *ruleUsecase) GetDetailRules(ctx context.Context, ruleID string) (*entity.WazuhRule, error) {
-	return u.ruleRepo.GetDetailRules(ctx, ruleID)
+	rule, err := u.ruleRepo.GetDetailRules(ctx, ruleID)
+	if err != nil || rule == nil {
+		return rule, err
+	}
+
+	u.resolveMitre(ctx, rule)
+	return rule, nil
 }
 
 func (u *ruleUsecase) GetListRulesByFiles(ctx context.Context, filename string) ([]entity.WazuhRule, error) {
-	return u.ruleRepo.GetListRulesByFiles(ctx, filename)
+	rules, err := u.ruleRepo.GetListRulesByFiles(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		u.resolveMitre(ctx, &rules[i])
+	}
+	return rules, nil
+}
+
+// resolveMitre sets the ATT&CK techniques of the rule's technique IDs that the local bundle knows
+func (u *ruleUsecase) resolveMitre(ctx context.Context, rule *entity.WazuhRule) {
+	rule.MitreTechniques = append([]entity.MitreTechnique{}, u.mitreUsecase.ResolveTechniques(ctx, rule.Mitre)...)
+}
+
+// fetchRuleCatalog returns rule metadata by ID from the latest rule snapshot, falling back to the manager
+// when no snapshot was taken yet. Callers carry on without metadata if both fail.
+func fetchRuleCatalog(ctx context.Context, snapshotRepo domain.RuleSnapshotRepository, ruleRepo domain.RuleRepository) map[int]entity.WazuhRule {
+	log := logger.WithRequestID(ctx)
+	catalog := map[int]entity.WazuhRule{}
+
+	snapshot, err := snapshotRepo.FetchLatestSnapshot(ctx)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - rule - fetchRuleCatalog]: Failed to fetch latest rule snapshot")
+	}
+
+	if snapshot != nil {
+		items, err := snapshotRepo.FetchSnapshotItems(ctx, snapshot.ID)
+		if err == nil {
+			for _, item := range items {
+				catalog[item.RuleID] = item.Rule
+			}
+			return catalog
+		}
+		log.WithError(err).WithField("snapshot_id", snapshot.ID).Warn("[usecase - rule - fetchRuleCatalog]: Failed to fetch rule snapshot items")
+	}
+
+	rules, err := ruleRepo.GetAllRules(ctx)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - rule - fetchRuleCatalog]: Failed to fetch rules from Wazuh, continuing without rule metadata")
+		return catalog
+	}
+
+	for _, rule := range rules {
+		catalog[rule.ID] = rule
+	}
+	return catalog
 }
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ruleFileMu serializes every change to the manager ruleset: pushes with their restart, and the candidate rule
+// files of logtest requests, so a restart never loads a candidate file
+var ruleFileMu sync.Mutex
+
+// ruleFileLockKey marks a context whose caller holds ruleFileMu
+type ruleFileLockKey struct{}
+
+// lockRuleFiles takes ruleFileMu, unless ctx already holds it, and returns the context to pass to the calls
+// made under the lock. Callers hold it from reading the ruleset until the state derived from it is stored.
+func lockRuleFiles(ctx context.Context) (context.Context, func()) {
+	if held, _ := ctx.Value(ruleFileLockKey{}).(bool); held {
+		return ctx, func() {}
+	}
+
+	ruleFileMu.Lock()
+	return context.WithValue(ctx, ruleFileLockKey{}, true), ruleFileMu.Unlock
+}
+
+type ruleFileUsecase struct {
+	ruleFileRepo        domain.RuleFileRepository
+	ruleFileVersionRepo domain.RuleFileVersionRepository
+	suppressionRepo     domain.SuppressionRepository
+}
+
+func NewRuleFileUsecase(
+	ruleFileRepo domain.RuleFileRepository,
+	ruleFileVersionRepo domain.RuleFileVersionRepository,
+	suppressionRepo domain.SuppressionRepository,
+) domain.RuleFileUsecase {
+	return &ruleFileUsecase{
+		ruleFileRepo:        ruleFileRepo,
+		ruleFileVersionRepo: ruleFileVersionRepo,
+		suppressionRepo:     suppressionRepo,
+	}
+}
+
+// PushRuleFile uploads new content for a rule file. The content currently on the manager is
+// stored as a version first so the push can be rolled back. When the manager rejects the new
+// configuration the previous content is restored and an error is returned.
+func (u *ruleFileUsecase) PushRuleFile(ctx context.Context, filename string, content string, reason string) (*entity.RuleFileVersion, error) {
+	log := logger.WithRequestID(ctx).WithField("filename", filename)
+
+	ctx, unlock := lockRuleFiles(ctx)
+	defer unlock()
+
+	current, _, err := u.ruleFileRepo.GetRuleFile(ctx, filename)
+	if err != nil {
+		log.WithError(err).Error("[usecase - rule_file - PushRuleFile]: Failed to fetch current rule file")
+		return nil, err
+	}
+
+	if err := u.saveVersion(ctx, filename, current, "before: "+reason); err != nil {
+		return nil, err
+	}
+
+	if err := u.ruleFileRepo.UpdateRuleFile(ctx, filename, content); err != nil {
+		log.WithError(err).Error("[usecase - rule_file - PushRuleFile]: Failed to upload rule file")
+		return nil, err
+	}
+
+	if err := u.ruleFileRepo.ValidateConfiguration(ctx); err != nil {
+		log.WithError(err).Warn("[usecase - rule_file - PushRuleFile]: Manager rejected rule file, restoring previous content")
+
+		if restoreErr := u.ruleFileRepo.UpdateRuleFile(ctx, filename, current); restoreErr != nil {
+			log.WithError(restoreErr).Error("[usecase - rule_file - PushRuleFile]: Failed to restore previous rule file")
+		}
+
+		return nil, fmt.Errorf("rule file %s rejected by manager: %w", filename, err)
+	}
+
+	version := &entity.RuleFileVersion{
+		Filename:  filename,
+		Content:   content,
+		Hash:      hashContent(content),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+	if err := u.ruleFileVersionRepo.SaveVersion(ctx, version); err != nil {
+		return nil, err
+	}
+
+	if restartOnRulePush() {
+		if err := u.ruleFileRepo.RestartManager(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.reconcileSuppressions(ctx, filename, content); err != nil {
+		log.WithError(err).Warn("[usecase - rule_file - PushRuleFile]: Failed to reconcile suppression statuses")
+	}
+
+	log.WithField("version_id", version.ID).Info("[usecase - rule_file - PushRuleFile]: Successfully pushed rule file")
+	return version, nil
+}
+
+func (u *ruleFileUsecase) FetchRuleFileVersions(ctx context.Context, filename string) ([]*entity.RuleFileVersion, error) {
+	return u.ruleFileVersionRepo.FetchVersions(ctx, filename)
+}
+
+func (u *ruleFileUsecase) saveVersion(ctx context.Context, filename string, content string, reason string) error {
+	return u.ruleFileVersionRepo.SaveVersion(ctx, &entity.RuleFileVersion{
+		Filename:  filename,
+		Content:   content,
+		Hash:      hashContent(content),
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+}
+
+// reconcileSuppressions keeps suppression statuses in line with what is actually in the rule file,
+// so a rollback marks the suppressions it removed as rolled back
+func (u *ruleFileUsecase) reconcileSuppressions(ctx context.Context, filename string, content string) error {
+	suppressions, err := u.suppressionRepo.FetchSuppressions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, suppression := range suppressions {
+		if suppression.Filename != filename || suppression.WazuhRuleID == 0 {
+			continue
+		}
+
+		present := strings.Contains(content, `<rule id="`+strconv.Itoa(suppression.WazuhRuleID)+`"`)
+
+		switch {
+		case suppression.Status == entity.SuppressionStatusDeployed && !present:
+			suppression.Status = entity.SuppressionStatusRolledBack
+		case suppression.Status == entity.SuppressionStatusRolledBack && present:
+			suppression.Status = entity.SuppressionStatusDeployed
+		default:
+			continue
+		}
+
+		if err := u.suppressionRepo.UpdateSuppression(ctx, suppression); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func restartOnRulePush() bool {
+	return os.Getenv("WAZUH_RESTART_ON_RULE_PUSH") == "true"
+}
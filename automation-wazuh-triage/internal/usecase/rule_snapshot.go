@@ -0,0 +1,350 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// defaultCriticalRuleLevel is used when RULE_SNAPSHOT_CRITICAL_LEVEL is not set
+const defaultCriticalRuleLevel = 12
+
+type ruleSnapshotUsecase struct {
+	ruleRepo     domain.RuleRepository
+	snapshotRepo domain.RuleSnapshotRepository
+	notifier     *notifier.Notifier
+}
+
+func NewRuleSnapshotUsecase(
+	ruleRepo domain.RuleRepository,
+	snapshotRepo domain.RuleSnapshotRepository,
+	notifier *notifier.Notifier,
+) domain.RuleSnapshotUsecase {
+	return &ruleSnapshotUsecase{
+		ruleRepo:     ruleRepo,
+		snapshotRepo: snapshotRepo,
+		notifier:     notifier,
+	}
+}
+
+func (u *ruleSnapshotUsecase) CreateSnapshot(ctx context.Context, trigger string) (*entity.RuleSnapshot, error) {
+	snapshot, items, err := u.buildSnapshot(ctx, trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.saveSnapshot(ctx, snapshot, items); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// buildSnapshot reads the current catalog from Wazuh and hashes it without storing anything
+func (u *ruleSnapshotUsecase) buildSnapshot(ctx context.Context, trigger string) (*entity.RuleSnapshot, []entity.RuleSnapshotItem, error) {
+	log := logger.WithRequestID(ctx)
+
+	rules, err := u.ruleRepo.GetAllRules(ctx)
+	if err != nil {
+		log.WithError(err).Error("[usecase - rule_snapshot - CreateSnapshot]: Failed to fetch rules from Wazuh")
+		return nil, nil, err
+	}
+
+	items := make([]entity.RuleSnapshotItem, 0, len(rules))
+	for _, rule := range rules {
+		hash, err := hashRule(rule)
+		if err != nil {
+			log.WithError(err).WithField("rule_id", rule.ID).Error("[usecase - rule_snapshot - CreateSnapshot]: Failed to hash rule")
+			return nil, nil, err
+		}
+
+		items = append(items, entity.RuleSnapshotItem{
+			RuleID:      rule.ID,
+			Filename:    rule.Filename,
+			Level:       rule.Level,
+			Status:      rule.Status,
+			Groups:      rule.Groups,
+			Description: rule.Description,
+			Hash:        hash,
+			Rule:        rule,
+		})
+	}
+
+	// Wazuh can return the same rule ID twice when it is overwritten, keep the last definition
+	items = dedupeSnapshotItems(items)
+
+	snapshot := &entity.RuleSnapshot{
+		Trigger:   trigger,
+		RuleCount: len(items),
+		Hash:      hashSnapshotItems(items),
+		CreatedAt: time.Now(),
+	}
+	return snapshot, items, nil
+}
+
+func (u *ruleSnapshotUsecase) saveSnapshot(ctx context.Context, snapshot *entity.RuleSnapshot, items []entity.RuleSnapshotItem) error {
+	log := logger.WithRequestID(ctx)
+
+	if err := u.snapshotRepo.SaveSnapshot(ctx, snapshot, items); err != nil {
+		log.WithError(err).Error("[usecase - rule_snapshot - CreateSnapshot]: Failed to save snapshot")
+		return err
+	}
+
+	log.WithField("snapshot_id", snapshot.ID).WithField("rule_count", snapshot.RuleCount).Info("[usecase - rule_snapshot - CreateSnapshot]: Successfully created rule snapshot")
+	return nil
+}
+
+func (u *ruleSnapshotUsecase) FetchSnapshots(ctx context.Context) ([]*entity.RuleSnapshot, error) {
+	return u.snapshotRepo.FetchSnapshots(ctx)
+}
+
+func (u *ruleSnapshotUsecase) DiffSnapshots(ctx context.Context, fromID int, toID int) (*entity.RuleSnapshotDiff, error) {
+	log := logger.WithRequestID(ctx)
+
+	for _, id := range []int{fromID, toID} {
+		snapshot, err := u.snapshotRepo.FetchSnapshotByID(ctx, id)
+		if err != nil {
+			log.WithError(err).WithField("snapshot_id", id).Error("[usecase - rule_snapshot - DiffSnapshots]: Failed to fetch snapshot")
+			return nil, err
+		}
+		if snapshot == nil {
+			return nil, fmt.Errorf("rule snapshot with ID %d not found", id)
+		}
+	}
+
+	fromItems, err := u.snapshotRepo.FetchSnapshotItems(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	toItems, err := u.snapshotRepo.FetchSnapshotItems(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffSnapshotItems(fromItems, toItems, criticalRuleLevel())
+	diff.FromSnapshotID = fromID
+	diff.ToSnapshotID = toID
+
+	return diff, nil
+}
+
+// RunScheduledSnapshot hashes the current catalog and only stores a new snapshot when it
+// differs from the previous one, then raises a notification when any rule at or above the
+// critical level changed
+func (u *ruleSnapshotUsecase) RunScheduledSnapshot(ctx context.Context) error {
+	log := logger.WithRequestID(ctx)
+
+	previous, err := u.snapshotRepo.FetchLatestSnapshot(ctx)
+	if err != nil {
+		log.WithError(err).Error("[usecase - rule_snapshot - RunScheduledSnapshot]: Failed to fetch previous snapshot")
+		return err
+	}
+
+	current, items, err := u.buildSnapshot(ctx, entity.SnapshotTriggerScheduled)
+	if err != nil {
+		return err
+	}
+
+	// An unchanged catalog is not stored again, the previous snapshot still describes it
+	if previous != nil && previous.Hash == current.Hash {
+		log.WithField("snapshot_id", previous.ID).Info("[usecase - rule_snapshot - RunScheduledSnapshot]: No ruleset changes detected")
+		return nil
+	}
+
+	if err := u.saveSnapshot(ctx, current, items); err != nil {
+		return err
+	}
+
+	if previous == nil {
+		log.WithField("snapshot_id", current.ID).Info("[usecase - rule_snapshot - RunScheduledSnapshot]: Stored first rule snapshot")
+		return nil
+	}
+
+	diff, err := u.DiffSnapshots(ctx, previous.ID, current.ID)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("from", previous.ID).WithField("to", current.ID).WithField("critical_rules", len(diff.CriticalRuleIDs)).Info("[usecase - rule_snapshot - RunScheduledSnapshot]: Ruleset changes detected")
+
+	if len(diff.CriticalRuleIDs) == 0 {
+		return nil
+	}
+
+	return u.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Critical Wazuh rules changed",
+		Severity: "critical",
+		Message: fmt.Sprintf("%d critical rule(s) changed between snapshot %d and %d",
+			len(diff.CriticalRuleIDs), previous.ID, current.ID),
+		Data: diff,
+	})
+}
+
+// hashRule computes a stable content hash for a single rule
+func hashRule(rule entity.WazuhRule) (string, error) {
+	ruleJSON, err := json.Marshal(rule)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(ruleJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func hashSnapshotItems(items []entity.RuleSnapshotItem) string {
+	hasher := sha256.New()
+	for _, item := range items {
+		hasher.Write([]byte(strconv.Itoa(item.RuleID) + ":" + item.Hash + "\n"))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func dedupeSnapshotItems(items []entity.RuleSnapshotItem) []entity.RuleSnapshotItem {
+	byID := make(map[int]entity.RuleSnapshotItem, len(items))
+	for _, item := range items {
+		byID[item.RuleID] = item
+	}
+
+	deduped := make([]entity.RuleSnapshotItem, 0, len(byID))
+	for _, item := range byID {
+		deduped = append(deduped, item)
+	}
+
+	sort.Slice(deduped, func(i, j int) bool { return deduped[i].RuleID < deduped[j].RuleID })
+	return deduped
+}
+
+func diffSnapshotItems(fromItems, toItems []entity.RuleSnapshotItem, criticalLevel int) *entity.RuleSnapshotDiff {
+	diff := &entity.RuleSnapshotDiff{
+		Added:              []entity.RuleSnapshotItem{},
+		Removed:            []entity.RuleSnapshotItem{},
+		LevelChanges:       []entity.RuleLevelChange{},
+		GroupChanges:       []entity.RuleGroupChange{},
+		DescriptionChanges: []entity.RuleDescriptionChange{},
+		OtherChanges:       []int{},
+		CriticalRuleIDs:    []int{},
+	}
+
+	fromByID := make(map[int]entity.RuleSnapshotItem, len(fromItems))
+	for _, item := range fromItems {
+		fromByID[item.RuleID] = item
+	}
+
+	toByID := make(map[int]entity.RuleSnapshotItem, len(toItems))
+	for _, item := range toItems {
+		toByID[item.RuleID] = item
+	}
+
+	critical := make(map[int]bool)
+
+	for _, newItem := range toItems {
+		oldItem, exists := fromByID[newItem.RuleID]
+		if !exists {
+			diff.Added = append(diff.Added, newItem)
+			if newItem.Level >= criticalLevel {
+				critical[newItem.RuleID] = true
+			}
+			continue
+		}
+
+		if oldItem.Hash == newItem.Hash {
+			continue
+		}
+
+		tracked := false
+
+		if oldItem.Level != newItem.Level {
+			diff.LevelChanges = append(diff.LevelChanges, entity.RuleLevelChange{
+				RuleID:   newItem.RuleID,
+				OldLevel: oldItem.Level,
+				NewLevel: newItem.Level,
+			})
+			tracked = true
+		}
+
+		added, removed := diffStrings(oldItem.Groups, newItem.Groups)
+		if len(added) > 0 || len(removed) > 0 {
+			diff.GroupChanges = append(diff.GroupChanges, entity.RuleGroupChange{
+				RuleID:  newItem.RuleID,
+				Added:   added,
+				Removed: removed,
+			})
+			tracked = true
+		}
+
+		if oldItem.Description != newItem.Description {
+			diff.DescriptionChanges = append(diff.DescriptionChanges, entity.RuleDescriptionChange{
+				RuleID:         newItem.RuleID,
+				OldDescription: oldItem.Description,
+				NewDescription: newItem.Description,
+			})
+			tracked = true
+		}
+
+		if !tracked {
+			diff.OtherChanges = append(diff.OtherChanges, newItem.RuleID)
+		}
+
+		if oldItem.Level >= criticalLevel || newItem.Level >= criticalLevel {
+			critical[newItem.RuleID] = true
+		}
+	}
+
+	for _, oldItem := range fromItems {
+		if _, exists := toByID[oldItem.RuleID]; !exists {
+			diff.Removed = append(diff.Removed, oldItem)
+			if oldItem.Level >= criticalLevel {
+				critical[oldItem.RuleID] = true
+			}
+		}
+	}
+
+	for ruleID := range critical {
+		diff.CriticalRuleIDs = append(diff.CriticalRuleIDs, ruleID)
+	}
+	sort.Ints(diff.CriticalRuleIDs)
+
+	return diff
+}
+
+// diffStrings returns the values only present in newValues and only present in oldValues
+func diffStrings(oldValues, newValues []string) (added []string, removed []string) {
+	oldSet := make(map[string]bool, len(oldValues))
+	for _, value := range oldValues {
+		oldSet[value] = true
+	}
+
+	newSet := make(map[string]bool, len(newValues))
+	for _, value := range newValues {
+		newSet[value] = true
+		if !oldSet[value] {
+			added = append(added, value)
+		}
+	}
+
+	for _, value := range oldValues {
+		if !newSet[value] {
+			removed = append(removed, value)
+		}
+	}
+
+	return added, removed
+}
+
+func criticalRuleLevel() int {
+	level, err := strconv.Atoi(os.Getenv("RULE_SNAPSHOT_CRITICAL_LEVEL"))
+	if err != nil || level <= 0 {
+		return defaultCriticalRuleLevel
+	}
+	return level
+}
@@ -0,0 +1,665 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/olivere/elastic/v7"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultSequenceLevel is the rule level of a finding whose rule sets none
+	defaultSequenceLevel = 12
+
+	// maxSequenceWindow and maxSequenceStepCount bound the alerts a sequence keeps in memory
+	maxSequenceWindow    = 24 * time.Hour
+	maxSequenceStepCount = 1000
+
+	// defaultSequenceFindingsWindow and defaultSequenceFindingsLimit bound the findings listed when none are requested
+	defaultSequenceFindingsWindow = 24 * time.Hour
+	defaultSequenceFindingsLimit  = 100
+	maxSequenceFindingsLimit      = 1000
+)
+
+// storedSequenceRules is the value saved under SettingKeySequenceRules
+type storedSequenceRules struct {
+	YAML string `json:"yaml"`
+}
+
+// compiledSequenceRule is a validated rule with its parsed window
+type compiledSequenceRule struct {
+	entity.SequenceRule
+	window time.Duration
+}
+
+// sequenceEvent is an alert that matched a step
+type sequenceEvent struct {
+	eventID string
+	firedAt time.Time
+}
+
+// sequenceProgress holds the alerts that matched each step of one rule for one group
+type sequenceProgress struct {
+	window    time.Duration
+	keyValues map[string]string
+	steps     [][]sequenceEvent
+}
+
+// sequenceAlert is an alert of the stream with the fields the steps match on
+type sequenceAlert struct {
+	eventID  string
+	rule     *entity.WazuhSecurityEventRule
+	source   alertSource
+	document map[string]interface{}
+	firedAt  time.Time
+}
+
+// completedSequence is a sequence that matched all its steps and still has to be stored as a finding
+type completedSequence struct {
+	rule      compiledSequenceRule
+	groupKey  string
+	keyValues map[string]string
+	eventIDs  []string
+	firstSeen time.Time
+	last      sequenceAlert
+}
+
+type sequenceUsecase struct {
+	settingRepo domain.SettingRepository
+	findingRepo domain.SequenceFindingRepository
+	notifier    *notifier.Notifier
+
+	// mu guards the loaded rules and the in-memory progress of partial sequences
+	mu       sync.Mutex
+	loaded   bool
+	ruleSet  *entity.SequenceRules
+	compiled []compiledSequenceRule
+	progress map[string]*sequenceProgress // keyed by rule name and group key
+	seen     map[string]time.Time         // alerts already evaluated, so polling and webhook may overlap
+}
+
+func NewSequenceUsecase(
+	settingRepo domain.SettingRepository,
+	findingRepo domain.SequenceFindingRepository,
+	notifier *notifier.Notifier,
+) domain.SequenceUsecase {
+	return &sequenceUsecase{
+		settingRepo: settingRepo,
+		findingRepo: findingRepo,
+		notifier:    notifier,
+		progress:    map[string]*sequenceProgress{},
+		seen:        map[string]time.Time{},
+	}
+}
+
+func (u *sequenceUsecase) FetchRules(ctx context.Context) (*entity.SequenceRules, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.loadRules(ctx); err != nil {
+		return nil, err
+	}
+
+	ruleSet := *u.ruleSet
+	return &ruleSet, nil
+}
+
+// UpdateRules validates and stores a new YAML rule set. Partial sequences are dropped, since their steps may
+// no longer exist.
+func (u *sequenceUsecase) UpdateRules(ctx context.Context, request *model.UpdateSequenceRulesRequest) (*entity.SequenceRules, error) {
+	log := logger.WithRequestID(ctx)
+
+	updatedBy := strings.TrimSpace(request.UpdatedBy)
+	if updatedBy == "" {
+		return nil, fmt.Errorf("invalid sequence rules: updated_by is required")
+	}
+
+	compiled, err := parseSequenceRules(request.YAML)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := saveSetting(ctx, u.settingRepo, entity.SettingKeySequenceRules, storedSequenceRules{YAML: request.YAML}, updatedBy, now); err != nil {
+		log.WithError(err).Error("[usecase - sequence - UpdateRules]: Failed to save sequence rules")
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.setRules(request.YAML, compiled)
+	u.ruleSet.UpdatedBy = updatedBy
+	u.ruleSet.UpdatedAt = &now
+	u.progress = map[string]*sequenceProgress{}
+
+	log.WithField("rules", len(compiled)).WithField("updated_by", updatedBy).Info("[usecase - sequence - UpdateRules]: Updated sequence rules")
+
+	ruleSet := *u.ruleSet
+	return &ruleSet, nil
+}
+
+// EvaluateAlerts advances the sequence rules with the alerts in the order they fired and returns a synthetic
+// alert for every sequence they completed. Partial sequences are kept in memory, so a restart forgets the
+// steps seen before it.
+func (u *sequenceUsecase) EvaluateAlerts(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, error) {
+	log := logger.WithRequestID(ctx)
+
+	completed, err := u.advance(ctx, hits)
+	if err != nil || len(completed) == 0 {
+		return nil, err
+	}
+
+	findingHits := make([]*elastic.SearchHit, 0, len(completed))
+	for _, sequence := range completed {
+		finding, hit, err := newSequenceFinding(sequence)
+		if err != nil {
+			log.WithError(err).WithField("rule_name", sequence.rule.Name).Error("[usecase - sequence - EvaluateAlerts]: Failed to build sequence finding")
+			continue
+		}
+
+		if err := u.findingRepo.SaveSequenceFinding(ctx, finding); err != nil {
+			log.WithError(err).WithField("rule_name", sequence.rule.Name).Error("[usecase - sequence - EvaluateAlerts]: Failed to save sequence finding")
+			continue
+		}
+
+		log.WithField("rule_name", finding.RuleName).WithField("group_key", finding.GroupKey).WithField("event_id", finding.EventID).WithField("alerts", len(finding.EventIDs)).Warn("[usecase - sequence - EvaluateAlerts]: Sequence matched")
+
+		if err := u.notifier.Notify(ctx, notifier.Notification{
+			Title:    fmt.Sprintf("Sequence %s matched", finding.RuleName),
+			Severity: "critical",
+			Message:  fmt.Sprintf("%s: %d alerts for %s between %s and %s", sequenceDescription(sequence.rule.SequenceRule), len(finding.EventIDs), finding.GroupKey, finding.FirstSeen.Format(time.RFC3339), finding.LastSeen.Format(time.RFC3339)),
+			Data:     finding,
+		}); err != nil {
+			log.WithError(err).Warn("[usecase - sequence - EvaluateAlerts]: Failed to send notification")
+		}
+
+		findingHits = append(findingHits, hit)
+	}
+
+	return findingHits, nil
+}
+
+// advance feeds the alerts to every rule and returns the sequences they completed
+func (u *sequenceUsecase) advance(ctx context.Context, hits []*elastic.SearchHit) ([]completedSequence, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.loadRules(ctx); err != nil {
+		return nil, err
+	}
+	if len(u.compiled) == 0 {
+		return nil, nil
+	}
+
+	alerts := make([]sequenceAlert, 0, len(hits))
+	for _, hit := range hits {
+		if alert, ok := newSequenceAlert(hit); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].firedAt.Before(alerts[j].firedAt)
+	})
+	u.prune(alerts[len(alerts)-1].firedAt)
+
+	var completed []completedSequence
+
+	for _, alert := range alerts {
+		if _, ok := u.seen[alert.eventID]; ok {
+			continue
+		}
+		u.seen[alert.eventID] = alert.firedAt
+
+		for _, rule := range u.compiled {
+			if !sequenceRuleMatchesAny(rule, alert.rule) {
+				continue
+			}
+
+			keyValues := map[string]string{}
+			parts := make([]string, 0, len(rule.GroupBy))
+			grouped := true
+			for _, key := range rule.GroupBy {
+				value := correlationValue(key, alert.source, alert.rule)
+				if value == "" {
+					grouped = false
+					break
+				}
+				keyValues[key] = value
+				parts = append(parts, key+"="+value)
+			}
+			if !grouped {
+				continue
+			}
+			groupKey := strings.Join(parts, ",")
+
+			progressKey := rule.Name + "|" + groupKey
+			progress, ok := u.progress[progressKey]
+			if !ok {
+				progress = &sequenceProgress{
+					window:    rule.window,
+					keyValues: keyValues,
+					steps:     make([][]sequenceEvent, len(rule.Steps)),
+				}
+			}
+
+			if !progress.add(rule, alert) {
+				u.progress[progressKey] = progress
+				continue
+			}
+
+			delete(u.progress, progressKey)
+			completed = append(completed, progress.complete(rule, groupKey, alert))
+		}
+	}
+
+	return completed, nil
+}
+
+// prune forgets alerts and partial sequences too old to take part in a sequence ending at now
+func (u *sequenceUsecase) prune(now time.Time) {
+	for eventID, firedAt := range u.seen {
+		if firedAt.Before(now.Add(-maxSequenceWindow)) {
+			delete(u.seen, eventID)
+		}
+	}
+
+	for key, progress := range u.progress {
+		if progress.lastSeen().Before(now.Add(-progress.window)) {
+			delete(u.progress, key)
+		}
+	}
+}
+
+func (u *sequenceUsecase) FetchFindings(ctx context.Context, request *model.FetchSequenceFindingsRequest) ([]*entity.SequenceFinding, error) {
+	if request.Window <= 0 {
+		request.Window = defaultSequenceFindingsWindow
+	}
+	if request.Limit <= 0 {
+		request.Limit = defaultSequenceFindingsLimit
+	}
+	if request.Limit > maxSequenceFindingsLimit {
+		request.Limit = maxSequenceFindingsLimit
+	}
+
+	findings, err := u.findingRepo.FetchSequenceFindings(ctx, time.Now().Add(-request.Window), request.RuleName, request.Limit)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - sequence - FetchFindings]: Failed to fetch sequence findings")
+		return nil, err
+	}
+
+	if findings == nil {
+		findings = []*entity.SequenceFinding{}
+	}
+	return findings, nil
+}
+
+func (u *sequenceUsecase) FetchFindingByID(ctx context.Context, id int) (*entity.SequenceFinding, error) {
+	finding, err := u.findingRepo.FetchSequenceFindingByID(ctx, id)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).WithField("finding_id", id).Error("[usecase - sequence - FetchFindingByID]: Failed to fetch sequence finding")
+		return nil, err
+	}
+	if finding == nil {
+		return nil, fmt.Errorf("sequence finding with ID %d not found", id)
+	}
+	return finding, nil
+}
+
+// loadRules reads the rules once, from the settings or else from SEQUENCE_RULES_FILE. Invalid stored rules are
+// logged and leave the engine without rules rather than failing the alert stream. Callers hold mu.
+func (u *sequenceUsecase) loadRules(ctx context.Context) error {
+	if u.loaded {
+		return nil
+	}
+	log := logger.WithRequestID(ctx)
+
+	stored := &storedSequenceRules{}
+	setting, err := loadSetting(ctx, u.settingRepo, entity.SettingKeySequenceRules, stored)
+	if err != nil {
+		log.WithError(err).Error("[usecase - sequence - loadRules]: Failed to fetch sequence rules")
+		return err
+	}
+
+	if setting == nil {
+		if path := os.Getenv("SEQUENCE_RULES_FILE"); path != "" {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.WithError(err).WithField("path", path).Error("[usecase - sequence - loadRules]: Failed to read sequence rules file")
+			}
+			stored.YAML = string(content)
+		}
+	}
+
+	compiled, err := parseSequenceRules(stored.YAML)
+	if err != nil {
+		log.WithError(err).Error("[usecase - sequence - loadRules]: Stored sequence rules are invalid, no sequence is evaluated")
+		compiled = nil
+	}
+
+	u.setRules(stored.YAML, compiled)
+	if setting != nil {
+		u.ruleSet.UpdatedBy = setting.UpdatedBy
+		u.ruleSet.UpdatedAt = &setting.UpdatedAt
+	}
+	u.loaded = true
+
+	log.WithField("rules", len(compiled)).Info("[usecase - sequence - loadRules]: Loaded sequence rules")
+	return nil
+}
+
+// setRules replaces the active rules. Callers hold mu.
+func (u *sequenceUsecase) setRules(text string, compiled []compiledSequenceRule) {
+	rules := make([]entity.SequenceRule, 0, len(compiled))
+	for _, rule := range compiled {
+		rules = append(rules, rule.SequenceRule)
+	}
+
+	u.ruleSet = &entity.SequenceRules{YAML: text, Rules: rules}
+	u.compiled = compiled
+	u.loaded = true
+}
+
+// add records the alert against the rule's steps and reports whether all steps are now complete.
+// Alerts older than the window before this one are dropped first, along with the steps that no longer
+// follow a complete step.
+func (p *sequenceProgress) add(rule compiledSequenceRule, alert sequenceAlert) bool {
+	cutoff := alert.firedAt.Add(-rule.window)
+	for i := range p.steps {
+		kept := p.steps[i][:0]
+		for _, event := range p.steps[i] {
+			if !event.firedAt.Before(cutoff) {
+				kept = append(kept, event)
+			}
+		}
+		p.steps[i] = kept
+	}
+
+	current := p.currentStep(rule)
+	for i := current + 1; i < len(p.steps); i++ {
+		p.steps[i] = nil
+	}
+
+	// The alert counts for the current step, or else for the latest completed step it matches, so repeats
+	// of an earlier step keep the sequence inside the window
+	for i := current; i >= 0; i-- {
+		if i < len(rule.Steps) && sequenceStepMatches(rule.Steps[i], alert.rule) {
+			p.steps[i] = append(p.steps[i], sequenceEvent{eventID: alert.eventID, firedAt: alert.firedAt})
+			break
+		}
+	}
+
+	return p.currentStep(rule) == len(rule.Steps)
+}
+
+// currentStep returns the first step that has fewer alerts than its count
+func (p *sequenceProgress) currentStep(rule compiledSequenceRule) int {
+	for i, step := range rule.Steps {
+		if len(p.steps[i]) < step.Count {
+			return i
+		}
+	}
+	return len(rule.Steps)
+}
+
+func (p *sequenceProgress) lastSeen() time.Time {
+	var last time.Time
+	for _, events := range p.steps {
+		for _, event := range events {
+			if event.firedAt.After(last) {
+				last = event.firedAt
+			}
+		}
+	}
+	return last
+}
+
+func (p *sequenceProgress) complete(rule compiledSequenceRule, groupKey string, last sequenceAlert) completedSequence {
+	sequence := completedSequence{
+		rule:      rule,
+		groupKey:  groupKey,
+		keyValues: p.keyValues,
+		firstSeen: last.firedAt,
+		last:      last,
+	}
+
+	for _, events := range p.steps {
+		for _, event := range events {
+			sequence.eventIDs = append(sequence.eventIDs, event.eventID)
+			if event.firedAt.Before(sequence.firstSeen) {
+				sequence.firstSeen = event.firedAt
+			}
+		}
+	}
+
+	return sequence
+}
+
+// newSequenceFinding builds the finding of a completed sequence and the synthetic alert it enters the triage
+// queue as. The alert copies the last alert of the sequence, so it keeps its agent and data fields.
+func newSequenceFinding(sequence completedSequence) (*entity.SequenceFinding, *elastic.SearchHit, error) {
+	eventID := "sequence-" + uuid.New().String()
+
+	document := map[string]interface{}{}
+	for key, value := range sequence.last.document {
+		document[key] = value
+	}
+	document["id"] = eventID
+	document["timestamp"] = sequence.last.firedAt.UTC().Format(time.RFC3339Nano)
+	document["rule"] = map[string]interface{}{
+		"id":          entity.SequenceRuleIDPrefix + sequence.rule.Name,
+		"level":       sequence.rule.Level,
+		"description": sequenceDescription(sequence.rule.SequenceRule),
+		"groups":      []string{"sequence_correlation", sequence.rule.Name},
+	}
+	document["sequence"] = map[string]interface{}{
+		"rule":       sequence.rule.Name,
+		"key":        sequence.groupKey,
+		"key_values": sequence.keyValues,
+		"event_ids":  sequence.eventIDs,
+	}
+
+	source, err := json.Marshal(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	hit := &elastic.SearchHit{Id: eventID, Index: entity.SequenceFindingIndex, Source: source}
+
+	hitJSON, err := json.Marshal(hit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &entity.SequenceFinding{
+		EventID:   eventID,
+		RuleName:  sequence.rule.Name,
+		Level:     sequence.rule.Level,
+		GroupKey:  sequence.groupKey,
+		KeyValues: sequence.keyValues,
+		EventIDs:  sequence.eventIDs,
+		RawEvent:  string(hitJSON),
+		FirstSeen: sequence.firstSeen,
+		LastSeen:  sequence.last.firedAt,
+		CreatedAt: time.Now(),
+	}, hit, nil
+}
+
+// newSequenceAlert reads an alert of the stream. Synthetic alerts of findings are skipped, so findings never
+// feed sequences.
+func newSequenceAlert(hit *elastic.SearchHit) (sequenceAlert, bool) {
+	var securityEvent entity.WazuhSecurityEvent
+	if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || securityEvent.Rule == nil {
+		return sequenceAlert{}, false
+	}
+	if strings.HasPrefix(securityEvent.Rule.ID, entity.SequenceRuleIDPrefix) {
+		return sequenceAlert{}, false
+	}
+
+	source, ok := decodeAlertSource(hit.Source)
+	if !ok {
+		return sequenceAlert{}, false
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(hit.Source, &document); err != nil {
+		return sequenceAlert{}, false
+	}
+
+	firedAt, ok := parseAlertTimestamp(source.Timestamp)
+	if !ok {
+		firedAt = time.Now()
+	}
+
+	return sequenceAlert{
+		eventID:  string(securityEvent.ID),
+		rule:     securityEvent.Rule,
+		source:   source,
+		document: document,
+		firedAt:  firedAt.UTC(),
+	}, true
+}
+
+// sequenceStepMatches reports whether an alert of the rule matches the step. All conditions of a step must
+// hold; a list matches any of its values.
+func sequenceStepMatches(step entity.SequenceStep, rule *entity.WazuhSecurityEventRule) bool {
+	if rule.Level < step.MinLevel {
+		return false
+	}
+
+	if len(step.RuleIDs) > 0 && !containsString(step.RuleIDs, rule.ID) {
+		return false
+	}
+
+	if len(step.RuleGroups) > 0 {
+		matched := false
+		for _, group := range rule.Groups {
+			if containsString(step.RuleGroups, group) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sequenceRuleMatchesAny(rule compiledSequenceRule, alertRule *entity.WazuhSecurityEventRule) bool {
+	for _, step := range rule.Steps {
+		if sequenceStepMatches(step, alertRule) {
+			return true
+		}
+	}
+	return false
+}
+
+func sequenceDescription(rule entity.SequenceRule) string {
+	if rule.Description != "" {
+		return rule.Description
+	}
+	return "Sequence " + rule.Name
+}
+
+// parseSequenceRules decodes and validates a YAML rule set. Unknown fields are rejected so a typo cannot
+// silently widen a step. Empty YAML holds no rules.
+func parseSequenceRules(text string) ([]compiledSequenceRule, error) {
+	var ruleSet entity.SequenceRuleSet
+
+	decoder := yaml.NewDecoder(bytes.NewBufferString(text))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&ruleSet); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid sequence rules: %v", err)
+	}
+
+	names := map[string]bool{}
+	compiled := make([]compiledSequenceRule, 0, len(ruleSet.Rules))
+
+	for i, rule := range ruleSet.Rules {
+		rule.Name = strings.TrimSpace(rule.Name)
+		if rule.Name == "" {
+			return nil, fmt.Errorf("invalid sequence rules: rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("invalid sequence rules: rule %q is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.Level == 0 {
+			rule.Level = defaultSequenceLevel
+		}
+		if rule.Level < 1 || rule.Level > maxRuleLevel {
+			return nil, fmt.Errorf("invalid sequence rules: rule %q: level must be between 1 and %d", rule.Name, maxRuleLevel)
+		}
+
+		window, err := time.ParseDuration(rule.Window)
+		if err != nil || window <= 0 || window > maxSequenceWindow {
+			return nil, fmt.Errorf("invalid sequence rules: rule %q: window must be a duration between 1s and %s", rule.Name, maxSequenceWindow)
+		}
+
+		groupBy := []string{}
+		for _, key := range cleanList(rule.GroupBy) {
+			if !isValidCorrelationKey(key) {
+				return nil, fmt.Errorf("invalid sequence rules: rule %q: unknown group_by key %q, must be one of %s", rule.Name, key, strings.Join(correlationKeys, ", "))
+			}
+			if !containsString(groupBy, key) {
+				groupBy = append(groupBy, key)
+			}
+		}
+		sort.Strings(groupBy)
+		rule.GroupBy = groupBy
+
+		if len(rule.Steps) == 0 {
+			return nil, fmt.Errorf("invalid sequence rules: rule %q has no steps", rule.Name)
+		}
+		for j := range rule.Steps {
+			step := &rule.Steps[j]
+			step.RuleIDs = cleanList(step.RuleIDs)
+			step.RuleGroups = cleanList(step.RuleGroups)
+
+			if len(step.RuleIDs) == 0 && len(step.RuleGroups) == 0 && step.MinLevel == 0 {
+				return nil, fmt.Errorf("invalid sequence rules: rule %q: step %d needs rule_ids, rule_groups or min_level", rule.Name, j+1)
+			}
+			if step.Count == 0 {
+				step.Count = 1
+			}
+			if step.Count < 1 || step.Count > maxSequenceStepCount {
+				return nil, fmt.Errorf("invalid sequence rules: rule %q: step %d count must be between 1 and %d", rule.Name, j+1, maxSequenceStepCount)
+			}
+		}
+
+		compiled = append(compiled, compiledSequenceRule{SequenceRule: rule, window: window})
+	}
+
+	return compiled, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSequenceRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    []compiledSequenceRule
+		wantErr string
+	}{
+		{
+			name: "empty document holds no rules",
+			yaml: "",
+			want: []compiledSequenceRule{},
+		},
+		{
+			name: "defaults and cleaned lists",
+			yaml: `
+rules:
+  - name: " brute force then login "
+    window: 10m
+    group_by: [srcip, agent.id, srcip, " "]
+    steps:
+      - rule_groups: [authentication_failed, " "]
+        count: 5
+      - rule_ids: ["5715"]
+`,
+			want: []compiledSequenceRule{{
+				SequenceRule: entity.SequenceRule{
+					Name:    "brute force then login",
+					Level:   defaultSequenceLevel,
+					Window:  "10m",
+					GroupBy: []string{"agent.id", "srcip"},
+					Steps: []entity.SequenceStep{
+						{RuleIDs: []string{}, RuleGroups: []string{"authentication_failed"}, Count: 5},
+						{RuleIDs: []string{"5715"}, RuleGroups: []string{}, Count: 1},
+					},
+				},
+				window: 10 * time.Minute,
+			}},
+		},
+		{
+			name:    "unknown field",
+			yaml:    "rules:\n  - name: a\n    window: 1m\n    steps:\n      - rule_id: [\"1\"]\n",
+			wantErr: "invalid sequence rules",
+		},
+		{
+			name:    "missing name",
+			yaml:    "rules:\n  - window: 1m\n    steps:\n      - min_level: 3\n",
+			wantErr: "rule 1 has no name",
+		},
+		{
+			name:    "duplicate name",
+			yaml:    "rules:\n  - name: a\n    window: 1m\n    steps:\n      - min_level: 3\n  - name: a\n    window: 1m\n    steps:\n      - min_level: 3\n",
+			wantErr: `rule "a" is defined twice`,
+		},
+		{
+			name:    "level out of range",
+			yaml:    "rules:\n  - name: a\n    level: 17\n    window: 1m\n    steps:\n      - min_level: 3\n",
+			wantErr: "level must be between 1 and 16",
+		},
+		{
+			name:    "window too long",
+			yaml:    "rules:\n  - name: a\n    window: 25h\n    steps:\n      - min_level: 3\n",
+			wantErr: "window must be a duration",
+		},
+		{
+			name:    "window missing",
+			yaml:    "rules:\n  - name: a\n    steps:\n      - min_level: 3\n",
+			wantErr: "window must be a duration",
+		},
+		{
+			name:    "unknown group_by key",
+			yaml:    "rules:\n  - name: a\n    window: 1m\n    group_by: [dstip]\n    steps:\n      - min_level: 3\n",
+			wantErr: `unknown group_by key "dstip"`,
+		},
+		{
+			name:    "no steps",
+			yaml:    "rules:\n  - name: a\n    window: 1m\n",
+			wantErr: `rule "a" has no steps`,
+		},
+		{
+			name:    "step without a condition",
+			yaml:    "rules:\n  - name: a\n    window: 1m\n    steps:\n      - rule_ids: [\" \"]\n",
+			wantErr: "step 1 needs rule_ids, rule_groups or min_level",
+		},
+		{
+			name:    "step count too large",
+			yaml:    "rules:\n  - name: a\n    window: 1m\n    steps:\n      - min_level: 3\n        count: 1001\n",
+			wantErr: "step 1 count must be between 1 and 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSequenceRules(tt.yaml)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSequenceRules: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequenceProgressAdd(t *testing.T) {
+	// Three failed logins, then a successful one, within ten minutes
+	bruteForce := compiledSequenceRule{
+		SequenceRule: entity.SequenceRule{Steps: []entity.SequenceStep{
+			{RuleIDs: []string{"5710"}, Count: 3},
+			{RuleIDs: []string{"5715"}, Count: 1},
+		}},
+		window: 10 * time.Minute,
+	}
+	// A login, a privilege escalation, then a new user, within ten minutes
+	escalation := compiledSequenceRule{
+		SequenceRule: entity.SequenceRule{Steps: []entity.SequenceStep{
+			{RuleIDs: []string{"5715"}, Count: 1},
+			{RuleIDs: []string{"5402"}, Count: 1},
+			{RuleIDs: []string{"5902"}, Count: 1},
+		}},
+		window: 10 * time.Minute,
+	}
+
+	type alert struct {
+		ruleID string
+		at     time.Duration // after the first alert
+	}
+
+	tests := []struct {
+		name   string
+		rule   compiledSequenceRule
+		alerts []alert
+		want   []bool // whether each alert completes the sequence
+	}{
+		{
+			name:   "steps in order",
+			rule:   bruteForce,
+			alerts: []alert{{"5710", 0}, {"5710", time.Minute}, {"5710", 2 * time.Minute}, {"5715", 3 * time.Minute}},
+			want:   []bool{false, false, false, true},
+		},
+		{
+			name:   "later step before the earlier one is ignored",
+			rule:   bruteForce,
+			alerts: []alert{{"5715", 0}, {"5710", time.Minute}, {"5710", 2 * time.Minute}, {"5710", 3 * time.Minute}},
+			want:   []bool{false, false, false, false},
+		},
+		{
+			name:   "step count not reached",
+			rule:   bruteForce,
+			alerts: []alert{{"5710", 0}, {"5710", time.Minute}, {"5715", 2 * time.Minute}},
+			want:   []bool{false, false, false},
+		},
+		{
+			name:   "alert exactly one window after the first still counts",
+			rule:   bruteForce,
+			alerts: []alert{{"5710", 0}, {"5710", 0}, {"5710", 0}, {"5715", 10 * time.Minute}},
+			want:   []bool{false, false, false, true},
+		},
+		{
+			name:   "alerts older than the window are dropped",
+			rule:   bruteForce,
+			alerts: []alert{{"5710", 0}, {"5710", time.Minute}, {"5710", 2 * time.Minute}, {"5715", 12*time.Minute + time.Second}},
+			want:   []bool{false, false, false, false},
+		},
+		{
+			name: "repeats of a completed step keep it inside the window",
+			rule: bruteForce,
+			alerts: []alert{
+				{"5710", 0}, {"5710", time.Minute}, {"5710", 2 * time.Minute},
+				{"5710", 8 * time.Minute}, {"5710", 9 * time.Minute}, {"5710", 10 * time.Minute},
+				{"5715", 15 * time.Minute},
+			},
+			want: []bool{false, false, false, false, false, false, true},
+		},
+		{
+			name:   "later steps are dropped with the step they follow",
+			rule:   escalation,
+			alerts: []alert{{"5715", 0}, {"5402", 5 * time.Minute}, {"5902", 11 * time.Minute}},
+			want:   []bool{false, false, false},
+		},
+		{
+			name:   "sequence restarts after its first step expired",
+			rule:   escalation,
+			alerts: []alert{{"5715", 0}, {"5402", 5 * time.Minute}, {"5715", 11 * time.Minute}, {"5402", 12 * time.Minute}, {"5902", 13 * time.Minute}},
+			want:   []bool{false, false, false, false, true},
+		},
+	}
+
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := &sequenceProgress{window: tt.rule.window, steps: make([][]sequenceEvent, len(tt.rule.Steps))}
+
+			for i, a := range tt.alerts {
+				got := progress.add(tt.rule, sequenceAlert{
+					eventID: strconv.Itoa(i),
+					rule:    &entity.WazuhSecurityEventRule{ID: a.ruleID, Level: 5},
+					firedAt: start.Add(a.at),
+				})
+				if got != tt.want[i] {
+					t.Fatalf("alert %d (rule %s at +%s) completed = %v, want %v", i, a.ruleID, a.at, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
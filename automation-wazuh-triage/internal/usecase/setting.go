@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// loadSetting decodes the JSON setting stored under key into target. It returns nil, leaving target
+// untouched, when the setting was never saved.
+func loadSetting(ctx context.Context, settingRepo domain.SettingRepository, key string, target interface{}) (*entity.Setting, error) {
+	setting, err := settingRepo.FetchSetting(ctx, key)
+	if err != nil || setting == nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(setting.Value), target); err != nil {
+		return nil, fmt.Errorf("stored setting %s is not valid JSON: %w", key, err)
+	}
+	return setting, nil
+}
+
+// saveSetting stores value as JSON under key
+func saveSetting(ctx context.Context, settingRepo domain.SettingRepository, key string, value interface{}, updatedBy string, updatedAt time.Time) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return settingRepo.SaveSetting(ctx, &entity.Setting{
+		Key:       key,
+		Value:     string(encoded),
+		UpdatedBy: updatedBy,
+		UpdatedAt: updatedAt,
+	})
+}
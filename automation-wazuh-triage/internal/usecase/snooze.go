@@ -0,0 +1,482 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+const (
+	// maxSnoozeDuration bounds how long a snooze may close alerts
+	maxSnoozeDuration = 30 * 24 * time.Hour
+
+	// snoozeSummaryGrace delays the summary of an expired snooze so alerts fired before the expiry but still
+	// being indexed are closed and counted
+	snoozeSummaryGrace = 5 * time.Minute
+
+	// maxSnoozeSampleEvents bounds the event IDs listed in a snooze summary
+	maxSnoozeSampleEvents = 10
+)
+
+type snoozeUsecase struct {
+	snoozeRepo         domain.SnoozeRepository
+	closedEventRepo    domain.ClosedEventRepository
+	triageActionRepo   domain.TriageActionRepository
+	fingerprintUsecase domain.FingerprintUsecase
+	guardrailUsecase   domain.GuardrailUsecase
+	notifier           *notifier.Notifier
+}
+
+func NewSnoozeUsecase(
+	snoozeRepo domain.SnoozeRepository,
+	closedEventRepo domain.ClosedEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+	fingerprintUsecase domain.FingerprintUsecase,
+	guardrailUsecase domain.GuardrailUsecase,
+	notifier *notifier.Notifier,
+) domain.SnoozeUsecase {
+	return &snoozeUsecase{
+		snoozeRepo:         snoozeRepo,
+		closedEventRepo:    closedEventRepo,
+		triageActionRepo:   triageActionRepo,
+		fingerprintUsecase: fingerprintUsecase,
+		guardrailUsecase:   guardrailUsecase,
+		notifier:           notifier,
+	}
+}
+
+// CreateSnooze starts closing the alerts of a rule, agent or fingerprint, or any combination of them, until
+// the snooze expires
+func (u *snoozeUsecase) CreateSnooze(ctx context.Context, request *model.CreateSnoozeRequest) (*entity.Snooze, error) {
+	log := logger.WithRequestID(ctx)
+
+	createdBy := strings.TrimSpace(request.CreatedBy)
+	reason := strings.TrimSpace(request.Reason)
+	if createdBy == "" {
+		return nil, fmt.Errorf("invalid snooze: created_by is required")
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("invalid snooze: reason is required")
+	}
+
+	snooze := &entity.Snooze{
+		RuleID:      strings.TrimSpace(request.RuleID),
+		AgentID:     strings.TrimSpace(request.AgentID),
+		Fingerprint: strings.ToLower(strings.TrimSpace(request.Fingerprint)),
+		MaxLevel:    request.MaxLevel,
+		Reason:      reason,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+		Status:      entity.SnoozeStatusActive,
+	}
+
+	if snooze.RuleID == "" && snooze.AgentID == "" && snooze.Fingerprint == "" {
+		return nil, fmt.Errorf("invalid snooze: at least one of rule_id, agent_id and fingerprint is required")
+	}
+	if snooze.Fingerprint != "" && !fingerprintPattern.MatchString(snooze.Fingerprint) {
+		return nil, fmt.Errorf("invalid snooze: fingerprint must be %d hex characters", fingerprintLength)
+	}
+	if snooze.MaxLevel < 0 || snooze.MaxLevel > maxRuleLevel {
+		return nil, fmt.Errorf("invalid snooze: max_level must be between 0 and %d", maxRuleLevel)
+	}
+
+	switch {
+	case strings.TrimSpace(request.Duration) != "":
+		duration, err := time.ParseDuration(strings.TrimSpace(request.Duration))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid snooze: duration must be a positive duration such as 2h")
+		}
+		snooze.ExpiresAt = snooze.CreatedAt.Add(duration)
+	case request.ExpiresAt != nil:
+		snooze.ExpiresAt = request.ExpiresAt.UTC()
+	default:
+		return nil, fmt.Errorf("invalid snooze: duration or expires_at is required")
+	}
+
+	if !snooze.ExpiresAt.After(snooze.CreatedAt) {
+		return nil, fmt.Errorf("invalid snooze: expires_at must be in the future")
+	}
+	if snooze.ExpiresAt.Sub(snooze.CreatedAt) > maxSnoozeDuration {
+		return nil, fmt.Errorf("invalid snooze: a snooze may last at most %s", maxSnoozeDuration)
+	}
+
+	if err := u.snoozeRepo.SaveSnooze(ctx, snooze); err != nil {
+		log.WithError(err).Error("[usecase - snooze - CreateSnooze]: Failed to save snooze")
+		return nil, err
+	}
+
+	log.WithField("snooze_id", snooze.ID).WithField("scope", snoozeScope(snooze)).WithField("expires_at", snooze.ExpiresAt).WithField("created_by", createdBy).Info("[usecase - snooze - CreateSnooze]: Snooze created")
+	return snooze, nil
+}
+
+func (u *snoozeUsecase) FetchSnoozes(ctx context.Context, status string) ([]*entity.Snooze, error) {
+	switch status {
+	case "", entity.SnoozeStatusActive, entity.SnoozeStatusExpired, entity.SnoozeStatusCancelled, entity.SnoozeStatusConditionChanged:
+	default:
+		return nil, fmt.Errorf("invalid status %q: must be %s, %s, %s or %s", status, entity.SnoozeStatusActive, entity.SnoozeStatusExpired, entity.SnoozeStatusCancelled, entity.SnoozeStatusConditionChanged)
+	}
+
+	snoozes, err := u.snoozeRepo.FetchSnoozes(ctx, status, time.Now().UTC())
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - snooze - FetchSnoozes]: Failed to fetch snoozes")
+		return nil, err
+	}
+
+	if snoozes == nil {
+		snoozes = []*entity.Snooze{}
+	}
+	return snoozes, nil
+}
+
+func (u *snoozeUsecase) FetchSnoozeByID(ctx context.Context, id int) (*entity.Snooze, []*entity.SnoozedAlert, error) {
+	log := logger.WithRequestID(ctx)
+
+	snooze, err := u.snoozeRepo.FetchSnoozeByID(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("snooze_id", id).Error("[usecase - snooze - FetchSnoozeByID]: Failed to fetch snooze")
+		return nil, nil, err
+	}
+	if snooze == nil {
+		return nil, nil, fmt.Errorf("snooze with ID %d not found", id)
+	}
+
+	alerts, err := u.snoozeRepo.FetchSnoozedAlerts(ctx, id)
+	if err != nil {
+		log.WithError(err).WithField("snooze_id", id).Error("[usecase - snooze - FetchSnoozeByID]: Failed to fetch snoozed alerts")
+		return nil, nil, err
+	}
+
+	if alerts == nil {
+		alerts = []*entity.SnoozedAlert{}
+	}
+	return snooze, alerts, nil
+}
+
+// CancelSnooze ends an active snooze early and emits its summary. Alerts it closed stay closed.
+func (u *snoozeUsecase) CancelSnooze(ctx context.Context, id int, request *model.CancelSnoozeRequest) (*entity.Snooze, error) {
+	analyst := strings.TrimSpace(request.Analyst)
+	if analyst == "" {
+		return nil, fmt.Errorf("invalid cancellation: analyst is required")
+	}
+
+	snooze, _, err := u.FetchSnoozeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if snooze.Status != entity.SnoozeStatusActive {
+		return nil, fmt.Errorf("snooze with ID %d is already %s", id, snooze.Status)
+	}
+
+	if err := u.endSnooze(ctx, snooze, entity.SnoozeStatusCancelled, analyst, ""); err != nil {
+		return nil, err
+	}
+
+	return u.snoozeRepo.FetchSnoozeByID(ctx, id)
+}
+
+// ApplySnoozes closes the alerts that fired while a matching snooze was active and returns the others with
+// the number closed. Alerts a snooze matches are held back even when they were closed before, so they do not
+// reach cases. A matching alert above a snooze's max level ends that snooze and passes through, and one a
+// guardrail blocks from being closed stays in the queue.
+func (u *snoozeUsecase) ApplySnoozes(ctx context.Context, hits []*elastic.SearchHit) ([]*elastic.SearchHit, int, error) {
+	log := logger.WithRequestID(ctx)
+
+	snoozes, err := u.snoozeRepo.FetchUnendedSnoozes(ctx)
+	if err != nil {
+		log.WithError(err).Error("[usecase - snooze - ApplySnoozes]: Failed to fetch snoozes")
+		return nil, 0, err
+	}
+	if len(snoozes) == 0 {
+		return hits, 0, nil
+	}
+
+	var fingerprintFields []string
+	for _, snooze := range snoozes {
+		if snooze.Fingerprint != "" {
+			config, err := u.fingerprintUsecase.FetchConfig(ctx)
+			if err != nil {
+				return nil, 0, err
+			}
+			fingerprintFields = config.Fields
+			break
+		}
+	}
+
+	remaining := make([]*elastic.SearchHit, 0, len(hits))
+	ended := map[int]bool{}
+	snoozed := 0
+
+	for _, hit := range hits {
+		var securityEvent entity.WazuhSecurityEvent
+		source, ok := decodeAlertSource(hit.Source)
+		if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || securityEvent.Rule == nil || !ok {
+			remaining = append(remaining, hit)
+			continue
+		}
+
+		// Alerts matching a threat-intel indicator reach the queue whatever is snoozed
+		if len(source.IOC) > 0 {
+			remaining = append(remaining, hit)
+			continue
+		}
+
+		firedAt, ok := parseAlertTimestamp(source.Timestamp)
+		if !ok {
+			firedAt = time.Now()
+		}
+
+		fingerprint := ""
+		if fingerprintFields != nil {
+			fingerprint, _ = alertFingerprint(hit.Source, fingerprintFields)
+		}
+
+		var matched *entity.Snooze
+		for _, snooze := range snoozes {
+			if ended[snooze.ID] || !snoozeMatches(snooze, securityEvent.Rule.ID, source.Agent.ID, fingerprint, firedAt) {
+				continue
+			}
+
+			if snooze.MaxLevel > 0 && securityEvent.Rule.Level > snooze.MaxLevel {
+				note := fmt.Sprintf("alert %s of rule %s fired at level %d, above the snoozed max level %d", string(securityEvent.ID), securityEvent.Rule.ID, securityEvent.Rule.Level, snooze.MaxLevel)
+				if err := u.endSnooze(ctx, snooze, entity.SnoozeStatusConditionChanged, entity.ActorSnooze, note); err != nil {
+					return nil, 0, err
+				}
+				ended[snooze.ID] = true
+				continue
+			}
+
+			matched = snooze
+			break
+		}
+
+		if matched == nil {
+			remaining = append(remaining, hit)
+			continue
+		}
+
+		closed, kept, err := u.snoozeHit(ctx, matched, hit, &securityEvent, source, firedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		if kept {
+			remaining = append(remaining, hit)
+		}
+		if closed {
+			snoozed++
+		}
+	}
+
+	if snoozed > 0 {
+		log.WithField("snoozed", snoozed).WithField("alerts", len(hits)).Info("[usecase - snooze - ApplySnoozes]: Closed snoozed alerts")
+	}
+
+	if err := u.RunScheduledSummary(ctx); err != nil {
+		log.WithError(err).Warn("[usecase - snooze - ApplySnoozes]: Failed to summarize expired snoozes")
+	}
+
+	return remaining, snoozed, nil
+}
+
+// RunScheduledSummary ends the snoozes past their expiry and grace period and emits their summaries
+func (u *snoozeUsecase) RunScheduledSummary(ctx context.Context) error {
+	snoozes, err := u.snoozeRepo.FetchUnendedSnoozes(ctx)
+	if err != nil {
+		logger.WithRequestID(ctx).WithError(err).Error("[usecase - snooze - RunScheduledSummary]: Failed to fetch snoozes")
+		return err
+	}
+
+	now := time.Now()
+	for _, snooze := range snoozes {
+		if now.Before(snooze.ExpiresAt.Add(snoozeSummaryGrace)) {
+			continue
+		}
+		if err := u.endSnooze(ctx, snooze, entity.SnoozeStatusExpired, entity.ActorSnooze, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snoozeHit closes one alert with the snooze's reason and reports whether it was closed now, or kept in the
+// queue because a guardrail blocked the closure
+func (u *snoozeUsecase) snoozeHit(ctx context.Context, snooze *entity.Snooze, hit *elastic.SearchHit, securityEvent *entity.WazuhSecurityEvent, source alertSource, firedAt time.Time) (bool, bool, error) {
+	log := logger.WithRequestID(ctx)
+	eventID := string(securityEvent.ID)
+
+	existingClosedEvent, err := u.closedEventRepo.FetchClosedEventByEventID(ctx, eventID)
+	if err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - snooze - snoozeHit]: Failed to check existing closed event")
+		return false, false, err
+	}
+	if existingClosedEvent != nil {
+		return false, false, nil
+	}
+
+	trip, err := u.guardrailUsecase.CheckAutoClose(ctx, eventID, securityEvent.Rule, hit.Source)
+	if err != nil {
+		return false, false, err
+	}
+	if trip != nil {
+		log.WithField("event_id", eventID).WithField("snooze_id", snooze.ID).WithField("guardrail", trip.Guardrail).Info("[usecase - snooze - snoozeHit]: Snooze closure blocked by guardrail, alert kept")
+		return false, true, nil
+	}
+
+	hitJSON, err := json.Marshal(hit)
+	if err != nil {
+		return false, false, err
+	}
+
+	closedEvent := &entity.ClosedEvent{
+		EventID:   eventID,
+		RuleID:    securityEvent.Rule.ID,
+		RawEvent:  string(hitJSON),
+		Reason:    fmt.Sprintf("snoozed by #%d: %s", snooze.ID, snooze.Reason),
+		Status:    "closed",
+		CloseType: entity.CloseTypeAuto,
+		CloseAt:   time.Now(),
+	}
+	if err := u.closedEventRepo.SaveClosedEvent(ctx, closedEvent); err != nil {
+		log.WithError(err).WithField("event_id", eventID).Error("[usecase - snooze - snoozeHit]: Failed to close snoozed alert")
+		return false, false, err
+	}
+
+	if _, err := u.snoozeRepo.SaveSnoozedAlert(ctx, &entity.SnoozedAlert{
+		SnoozeID:      snooze.ID,
+		EventID:       eventID,
+		RuleID:        securityEvent.Rule.ID,
+		RuleLevel:     securityEvent.Rule.Level,
+		AgentName:     source.Agent.Name,
+		ClosedEventID: closedEvent.ID,
+		AlertAt:       firedAt.UTC(),
+		SnoozedAt:     closedEvent.CloseAt,
+	}); err != nil {
+		return false, false, err
+	}
+
+	triageAction := newTriageAction(closedEvent.EventID, closedEvent.RuleID, closedEvent.RawEvent, entity.TriageActionClosed, entity.ActorSnooze)
+	if err := u.triageActionRepo.SaveTriageAction(ctx, triageAction); err != nil {
+		log.WithError(err).WithField("event_id", eventID).Warn("[usecase - snooze - snoozeHit]: Failed to record triage action")
+	}
+
+	return true, false, nil
+}
+
+// endSnooze summarizes what the snooze closed, stores how it ended and notifies the team
+func (u *snoozeUsecase) endSnooze(ctx context.Context, snooze *entity.Snooze, status string, endedBy string, note string) error {
+	log := logger.WithRequestID(ctx)
+
+	alerts, err := u.snoozeRepo.FetchSnoozedAlerts(ctx, snooze.ID)
+	if err != nil {
+		log.WithError(err).WithField("snooze_id", snooze.ID).Error("[usecase - snooze - endSnooze]: Failed to fetch snoozed alerts")
+		return err
+	}
+
+	summary := summarizeSnoozedAlerts(alerts)
+	summary.EndNote = note
+
+	if err := u.snoozeRepo.EndSnooze(ctx, snooze.ID, status, endedBy, time.Now().UTC(), summary); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another run ended it first and already emitted the summary
+			return nil
+		}
+		log.WithError(err).WithField("snooze_id", snooze.ID).Error("[usecase - snooze - endSnooze]: Failed to end snooze")
+		return err
+	}
+
+	log.WithField("snooze_id", snooze.ID).WithField("status", status).WithField("suppressed", summary.Suppressed).Info("[usecase - snooze - endSnooze]: Snooze ended")
+
+	severity := "info"
+	if status == entity.SnoozeStatusConditionChanged {
+		severity = "warning"
+	}
+
+	message := fmt.Sprintf("Snooze #%d on %s (%s) suppressed %d alerts", snooze.ID, snoozeScope(snooze), snooze.Reason, summary.Suppressed)
+	if summary.FirstAlertAt != nil && summary.LastAlertAt != nil {
+		message += fmt.Sprintf(" between %s and %s", summary.FirstAlertAt.Format(time.RFC3339), summary.LastAlertAt.Format(time.RFC3339))
+	}
+	if note != "" {
+		message += "; ended early: " + note
+	}
+
+	if err := u.notifier.Notify(ctx, notifier.Notification{
+		Title:    fmt.Sprintf("Snooze #%d %s", snooze.ID, strings.ReplaceAll(status, "_", " ")),
+		Severity: severity,
+		Message:  message,
+		Data:     map[string]interface{}{"snooze": snooze, "summary": summary},
+	}); err != nil {
+		log.WithError(err).Warn("[usecase - snooze - endSnooze]: Failed to send notification")
+	}
+
+	return nil
+}
+
+// snoozeMatches reports whether an alert falls in the snooze's scope and fired while it was active
+func snoozeMatches(snooze *entity.Snooze, ruleID string, agentID string, fingerprint string, firedAt time.Time) bool {
+	if firedAt.Before(snooze.CreatedAt) || !firedAt.Before(snooze.ExpiresAt) {
+		return false
+	}
+	if snooze.RuleID != "" && snooze.RuleID != ruleID {
+		return false
+	}
+	if snooze.AgentID != "" && snooze.AgentID != agentID {
+		return false
+	}
+	if snooze.Fingerprint != "" && snooze.Fingerprint != fingerprint {
+		return false
+	}
+	return true
+}
+
+func summarizeSnoozedAlerts(alerts []*entity.SnoozedAlert) *entity.SnoozeSummary {
+	summary := &entity.SnoozeSummary{
+		Suppressed:     len(alerts),
+		ByRule:         map[string]int{},
+		ByAgent:        map[string]int{},
+		SampleEventIDs: []string{},
+	}
+
+	for _, alert := range alerts {
+		summary.ByRule[alert.RuleID]++
+		summary.ByAgent[alert.AgentName]++
+
+		alertAt := alert.AlertAt
+		if summary.FirstAlertAt == nil || alertAt.Before(*summary.FirstAlertAt) {
+			summary.FirstAlertAt = &alertAt
+		}
+		if summary.LastAlertAt == nil || alertAt.After(*summary.LastAlertAt) {
+			summary.LastAlertAt = &alertAt
+		}
+		if len(summary.SampleEventIDs) < maxSnoozeSampleEvents {
+			summary.SampleEventIDs = append(summary.SampleEventIDs, alert.EventID)
+		}
+	}
+
+	return summary
+}
+
+// snoozeScope describes the fields a snooze matches on, e.g. rule 5716, agent 001
+func snoozeScope(snooze *entity.Snooze) string {
+	var parts []string
+	if snooze.RuleID != "" {
+		parts = append(parts, "rule "+snooze.RuleID)
+	}
+	if snooze.AgentID != "" {
+		parts = append(parts, "agent "+snooze.AgentID)
+	}
+	if snooze.Fingerprint != "" {
+		parts = append(parts, "fingerprint "+snooze.Fingerprint)
+	}
+	return strings.Join(parts, ", ")
+}
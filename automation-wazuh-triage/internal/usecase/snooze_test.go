@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+)
+
+// memSnoozes keeps snoozes and the alerts they closed, ending a snooze only while it is active
+type memSnoozes struct {
+	domain.SnoozeRepository
+	snoozes []*entity.Snooze
+	alerts  []*entity.SnoozedAlert
+}
+
+func (m *memSnoozes) FetchUnendedSnoozes(ctx context.Context) ([]*entity.Snooze, error) {
+	var snoozes []*entity.Snooze
+	for _, snooze := range m.snoozes {
+		if snooze.Status == entity.SnoozeStatusActive {
+			snoozes = append(snoozes, snooze)
+		}
+	}
+	return snoozes, nil
+}
+
+func (m *memSnoozes) EndSnooze(ctx context.Context, id int, status string, endedBy string, endedAt time.Time, summary *entity.SnoozeSummary) error {
+	for _, snooze := range m.snoozes {
+		if snooze.ID == id && snooze.Status == entity.SnoozeStatusActive {
+			snooze.Status = status
+			snooze.EndedBy = endedBy
+			snooze.EndedAt = &endedAt
+			snooze.Summary = summary
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *memSnoozes) SaveSnoozedAlert(ctx context.Context, alert *entity.SnoozedAlert) (bool, error) {
+	alert.ID = len(m.alerts) + 1
+	m.alerts = append(m.alerts, alert)
+	return true, nil
+}
+
+func (m *memSnoozes) FetchSnoozedAlerts(ctx context.Context, snoozeID int) ([]*entity.SnoozedAlert, error) {
+	var alerts []*entity.SnoozedAlert
+	for _, alert := range m.alerts {
+		if alert.SnoozeID == snoozeID {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+// fixedGuardrails blocks every closure when block is set and lets every one through otherwise
+type fixedGuardrails struct {
+	domain.GuardrailUsecase
+	block bool
+}
+
+func (m fixedGuardrails) CheckAutoClose(ctx context.Context, eventID string, rule *entity.WazuhSecurityEventRule, source []byte) (*entity.GuardrailTrip, error) {
+	if !m.block {
+		return nil, nil
+	}
+	return &entity.GuardrailTrip{Guardrail: entity.GuardrailProtectedRule, EventID: eventID}, nil
+}
+
+func TestSnoozeMatches(t *testing.T) {
+	created := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	expires := created.Add(2 * time.Hour)
+	during := created.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		snooze      entity.Snooze
+		ruleID      string
+		agentID     string
+		fingerprint string
+		firedAt     time.Time
+		want        bool
+	}{
+		{name: "rule matches on any agent", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5710", agentID: "002", firedAt: during, want: true},
+		{name: "other rule", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5711", agentID: "001", firedAt: during},
+		{name: "agent matches on any rule", snooze: entity.Snooze{AgentID: "001"}, ruleID: "31101", agentID: "001", firedAt: during, want: true},
+		{name: "other agent", snooze: entity.Snooze{AgentID: "001"}, ruleID: "5710", agentID: "002", firedAt: during},
+		{name: "fingerprint matches", snooze: entity.Snooze{Fingerprint: "0123456789abcdef"}, ruleID: "5710", fingerprint: "0123456789abcdef", firedAt: during, want: true},
+		{name: "other fingerprint", snooze: entity.Snooze{Fingerprint: "0123456789abcdef"}, ruleID: "5710", fingerprint: "fedcba9876543210", firedAt: during},
+		{name: "alert without a fingerprint", snooze: entity.Snooze{Fingerprint: "0123456789abcdef"}, ruleID: "5710", firedAt: during},
+		{name: "every scope field matches", snooze: entity.Snooze{RuleID: "5710", AgentID: "001", Fingerprint: "0123456789abcdef"}, ruleID: "5710", agentID: "001", fingerprint: "0123456789abcdef", firedAt: during, want: true},
+		{name: "rule matches but agent differs", snooze: entity.Snooze{RuleID: "5710", AgentID: "001"}, ruleID: "5710", agentID: "002", firedAt: during},
+		{name: "agent matches but rule differs", snooze: entity.Snooze{RuleID: "5710", AgentID: "001"}, ruleID: "5711", agentID: "001", firedAt: during},
+		{name: "rule ids are compared exactly", snooze: entity.Snooze{RuleID: "571"}, ruleID: "5710", firedAt: during},
+		{name: "fired when the snooze was created", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5710", firedAt: created, want: true},
+		{name: "fired just before the snooze was created", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5710", firedAt: created.Add(-time.Nanosecond)},
+		{name: "fired just before the expiry", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5710", firedAt: expires.Add(-time.Nanosecond), want: true},
+		{name: "fired at the expiry", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5710", firedAt: expires},
+		{name: "fired after the expiry", snooze: entity.Snooze{RuleID: "5710"}, ruleID: "5710", firedAt: expires.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snooze := tt.snooze
+			snooze.CreatedAt = created
+			snooze.ExpiresAt = expires
+
+			if got := snoozeMatches(&snooze, tt.ruleID, tt.agentID, tt.fingerprint, tt.firedAt); got != tt.want {
+				t.Fatalf("snoozeMatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySnoozes(t *testing.T) {
+	type alert struct {
+		ruleID  string
+		level   int
+		firedAt time.Duration // relative to the snooze expiry
+		ioc     bool
+	}
+
+	tests := []struct {
+		name         string
+		maxLevel     int
+		expiredAgo   time.Duration // how long before the run the snooze expired, negative while it runs
+		alert        alert
+		closedBefore bool
+		blocked      bool
+		wantKept     bool
+		wantClosed   bool
+		wantStatus   string
+	}{
+		{name: "matching alert is closed", expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 5, firedAt: -2 * time.Hour}, wantClosed: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "other rule reaches the queue", expiredAgo: -time.Hour, alert: alert{ruleID: "5711", level: 5, firedAt: -2 * time.Hour}, wantKept: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "alert fired before the snooze reaches the queue", expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 5, firedAt: -4 * time.Hour}, wantKept: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "alert at the max level is closed", maxLevel: 7, expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 7, firedAt: -2 * time.Hour}, wantClosed: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "alert above the max level ends the snooze", maxLevel: 7, expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 8, firedAt: -2 * time.Hour}, wantKept: true, wantStatus: entity.SnoozeStatusConditionChanged},
+		{name: "indicator match reaches the queue", expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 5, firedAt: -2 * time.Hour, ioc: true}, wantKept: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "alert closed before is held back", expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 5, firedAt: -2 * time.Hour}, closedBefore: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "alert a guardrail blocks stays in the queue", expiredAgo: -time.Hour, alert: alert{ruleID: "5710", level: 5, firedAt: -2 * time.Hour}, blocked: true, wantKept: true, wantStatus: entity.SnoozeStatusActive},
+		// An alert indexed late is matched by when it fired, until the summary grace ends the snooze
+		{name: "alert fired just before the expiry is closed late", expiredAgo: time.Minute, alert: alert{ruleID: "5710", level: 5, firedAt: -time.Millisecond}, wantClosed: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "alert fired at the expiry reaches the queue", expiredAgo: time.Minute, alert: alert{ruleID: "5710", level: 5, firedAt: 0}, wantKept: true, wantStatus: entity.SnoozeStatusActive},
+		{name: "snooze past its grace is closed out after the alert", expiredAgo: snoozeSummaryGrace + time.Second, alert: alert{ruleID: "5710", level: 5, firedAt: -time.Minute}, wantClosed: true, wantStatus: entity.SnoozeStatusExpired},
+		{name: "snooze within its grace stays active", expiredAgo: snoozeSummaryGrace - time.Second, alert: alert{ruleID: "5711", level: 5, firedAt: -time.Minute}, wantKept: true, wantStatus: entity.SnoozeStatusActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			expires := time.Now().UTC().Add(-tt.expiredAgo).Truncate(time.Millisecond)
+			snoozes := &memSnoozes{snoozes: []*entity.Snooze{{
+				ID:        1,
+				RuleID:    "5710",
+				MaxLevel:  tt.maxLevel,
+				Reason:    "patch window",
+				CreatedAt: expires.Add(-3 * time.Hour),
+				ExpiresAt: expires,
+				Status:    entity.SnoozeStatusActive,
+			}}}
+
+			ioc := ""
+			if tt.alert.ioc {
+				ioc = `,"ioc":[{"field":"data.srcip","observed":"203.0.113.7","type":"ip","value":"203.0.113.7"}]`
+			}
+			hit := &elastic.SearchHit{
+				Id: "doc-1",
+				Source: json.RawMessage(fmt.Sprintf(`{"id":"1","timestamp":%q,"rule":{"id":%q,"level":%d},"agent":{"id":"001","name":"web-01"}%s}`,
+					expires.Add(tt.alert.firedAt).Format(time.RFC3339Nano), tt.alert.ruleID, tt.alert.level, ioc)),
+			}
+
+			closedEvents := &memClosedEvents{closed: map[string]*entity.ClosedEvent{}}
+			if tt.closedBefore {
+				closedEvents.closed["1"] = &entity.ClosedEvent{ID: 100, EventID: "1", CloseType: entity.CloseTypeManual}
+			}
+			triageActions := &memTriageActions{}
+			fingerprints := NewFingerprintUsecase(nil, nil, nil, &memSettings{settings: map[string]*entity.Setting{}})
+			u := NewSnoozeUsecase(snoozes, closedEvents, triageActions, fingerprints, fixedGuardrails{block: tt.blocked}, &notifier.Notifier{})
+
+			remaining, closed, err := u.ApplySnoozes(ctx, []*elastic.SearchHit{hit})
+			if err != nil {
+				t.Fatalf("ApplySnoozes: %v", err)
+			}
+
+			if kept := len(remaining) == 1; kept != tt.wantKept {
+				t.Errorf("alert kept in the queue = %v, want %v", kept, tt.wantKept)
+			}
+			if (closed == 1) != tt.wantClosed {
+				t.Errorf("closed %d alerts, want closed = %v", closed, tt.wantClosed)
+			}
+			if (len(snoozes.alerts) == 1) != tt.wantClosed || (len(triageActions.actions) == 1) != tt.wantClosed {
+				t.Errorf("recorded %d snoozed alerts and %d triage actions, want closed = %v", len(snoozes.alerts), len(triageActions.actions), tt.wantClosed)
+			}
+
+			snooze := snoozes.snoozes[0]
+			if snooze.Status != tt.wantStatus {
+				t.Fatalf("snooze status = %s, want %s", snooze.Status, tt.wantStatus)
+			}
+			if snooze.Status != entity.SnoozeStatusActive {
+				want := 0
+				if tt.wantClosed {
+					want = 1
+				}
+				if snooze.Summary == nil || snooze.Summary.Suppressed != want {
+					t.Errorf("summary = %+v, want %d suppressed", snooze.Summary, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplySnoozesByFingerprint(t *testing.T) {
+	source := func(srcip string) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"id":%q,"timestamp":%q,"rule":{"id":"5710","level":5},"agent":{"id":"001"},"data":{"srcip":%q},"full_log":"Failed password from %s port 22"}`,
+			srcip, time.Now().UTC().Add(-time.Minute).Format(time.RFC3339Nano), srcip, srcip))
+	}
+	fingerprint, _ := alertFingerprint(source("10.0.0.1"), defaultFingerprintFieldList())
+
+	snoozes := &memSnoozes{snoozes: []*entity.Snooze{{
+		ID:          1,
+		Fingerprint: fingerprint,
+		Reason:      "scanner",
+		CreatedAt:   time.Now().UTC().Add(-time.Hour),
+		ExpiresAt:   time.Now().UTC().Add(time.Hour),
+		Status:      entity.SnoozeStatusActive,
+	}}}
+	fingerprints := NewFingerprintUsecase(nil, nil, nil, &memSettings{settings: map[string]*entity.Setting{}})
+	u := NewSnoozeUsecase(snoozes, &memClosedEvents{closed: map[string]*entity.ClosedEvent{}}, &memTriageActions{}, fingerprints, fixedGuardrails{}, &notifier.Notifier{})
+
+	remaining, closed, err := u.ApplySnoozes(context.Background(), []*elastic.SearchHit{
+		{Id: "doc-1", Source: source("10.0.0.1")},
+		{Id: "doc-2", Source: source("10.0.0.2")},
+	})
+	if err != nil {
+		t.Fatalf("ApplySnoozes: %v", err)
+	}
+	if closed != 1 || len(remaining) != 1 || remaining[0].Id != "doc-2" {
+		t.Fatalf("closed %d and kept %d alerts, want the alert from 10.0.0.1 closed and the other kept", closed, len(remaining))
+	}
+}
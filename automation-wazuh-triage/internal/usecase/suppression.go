@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/wazuh"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultSuppressionRulesFile is the rule file the generated child rules are written to
+	defaultSuppressionRulesFile = "local_rules.xml"
+
+	// defaultSuppressionRuleIDBase keeps generated rule IDs inside the Wazuh custom rule range
+	defaultSuppressionRuleIDBase = 110000
+)
+
+type suppressionUsecase struct {
+	suppressionRepo domain.SuppressionRepository
+	ruleFileRepo    domain.RuleFileRepository
+	ruleFileUsecase domain.RuleFileUsecase
+}
+
+func NewSuppressionUsecase(
+	suppressionRepo domain.SuppressionRepository,
+	ruleFileRepo domain.RuleFileRepository,
+	ruleFileUsecase domain.RuleFileUsecase,
+) domain.SuppressionUsecase {
+	return &suppressionUsecase{
+		suppressionRepo: suppressionRepo,
+		ruleFileRepo:    ruleFileRepo,
+		ruleFileUsecase: ruleFileUsecase,
+	}
+}
+
+func (u *suppressionUsecase) CreateSuppression(ctx context.Context, suppression *entity.Suppression) (*entity.Suppression, error) {
+	log := logger.WithRequestID(ctx)
+
+	// Validate the conditions by rendering them before anything is stored
+	preview := *suppression
+	preview.WazuhRuleID = suppressionRuleIDBase()
+	if _, err := wazuh.GenerateSuppressionRulesXML([]wazuh.SuppressionRule{toWazuhSuppressionRule(&preview)}); err != nil {
+		log.WithError(err).Warn("[usecase - suppression - CreateSuppression]: Invalid suppression")
+		return nil, fmt.Errorf("invalid suppression: %w", err)
+	}
+
+	// Suppressions start as proposed, only an approved proposal makes them deployable
+	suppression.Status = entity.SuppressionStatusProposed
+	suppression.Filename = suppressionRulesFile()
+	suppression.CreatedAt = time.Now()
+
+	if err := u.suppressionRepo.SaveSuppression(ctx, suppression); err != nil {
+		log.WithError(err).Error("[usecase - suppression - CreateSuppression]: Failed to save suppression")
+		return nil, err
+	}
+
+	// The child rule ID is derived from the suppression ID so it is stable across pushes
+	suppression.WazuhRuleID = suppressionRuleIDBase() + suppression.ID
+	if err := u.suppressionRepo.UpdateSuppression(ctx, suppression); err != nil {
+		return nil, err
+	}
+
+	return suppression, nil
+}
+
+func (u *suppressionUsecase) FetchSuppressions(ctx context.Context) ([]*entity.Suppression, error) {
+	return u.suppressionRepo.FetchSuppressions(ctx)
+}
+
+func (u *suppressionUsecase) FetchSuppressionByID(ctx context.Context, id int) (*entity.Suppression, error) {
+	return u.suppressionRepo.FetchSuppressionByID(ctx, id)
+}
+
+func (u *suppressionUsecase) PreviewSuppressionXML(ctx context.Context, id int) (string, error) {
+	suppression, err := u.suppressionRepo.FetchSuppressionByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	if suppression == nil {
+		return "", fmt.Errorf("suppression with ID %d not found", id)
+	}
+
+	return wazuh.GenerateSuppressionRulesXML([]wazuh.SuppressionRule{toWazuhSuppressionRule(suppression)})
+}
+
+// DeploySuppression regenerates the managed block of the suppression rule file with every deployed
+// suppression plus the given one, and pushes the file to the manager. The rule file lock is held from reading
+// the deployed suppressions until the new status is stored, so concurrent deploys cannot drop each other.
+func (u *suppressionUsecase) DeploySuppression(ctx context.Context, id int) (*entity.Suppression, error) {
+	log := logger.WithRequestID(ctx).WithField("suppression_id", id)
+
+	ctx, unlock := lockRuleFiles(ctx)
+	defer unlock()
+
+	suppression, err := u.suppressionRepo.FetchSuppressionByID(ctx, id)
+	if err != nil {
+		log.WithError(err).Error("[usecase - suppression - DeploySuppression]: Failed to fetch suppression")
+		return nil, err
+	}
+
+	if suppression == nil {
+		return nil, fmt.Errorf("suppression with ID %d not found", id)
+	}
+
+	if suppression.Status == entity.SuppressionStatusDeployed {
+		return nil, fmt.Errorf("suppression with ID %d is already deployed", id)
+	}
+
+	if suppression.Status != entity.SuppressionStatusApproved {
+		return nil, fmt.Errorf("suppression with ID %d is not approved", id)
+	}
+
+	_, content, err := u.renderRuleFile(ctx, suppression.Filename, suppression, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.ruleFileUsecase.PushRuleFile(ctx, suppression.Filename, content, "deploy suppression "+strconv.Itoa(suppression.ID)); err != nil {
+		log.WithError(err).Error("[usecase - suppression - DeploySuppression]: Failed to push rule file")
+		return nil, err
+	}
+
+	now := time.Now()
+	suppression.Status = entity.SuppressionStatusDeployed
+	suppression.DeployedAt = &now
+
+	if err := u.suppressionRepo.UpdateSuppression(ctx, suppression); err != nil {
+		log.WithError(err).Error("[usecase - suppression - DeploySuppression]: Failed to update suppression status")
+		return nil, err
+	}
+
+	log.WithField("wazuh_rule_id", suppression.WazuhRuleID).Info("[usecase - suppression - DeploySuppression]: Successfully deployed suppression")
+	return suppression, nil
+}
+
+// RetractSuppression removes a deployed suppression from the rule file and marks it rolled back, under the
+// rule file lock like DeploySuppression
+func (u *suppressionUsecase) RetractSuppression(ctx context.Context, id int) (*entity.Suppression, error) {
+	log := logger.WithRequestID(ctx).WithField("suppression_id", id)
+
+	ctx, unlock := lockRuleFiles(ctx)
+	defer unlock()
+
+	suppression, err := u.suppressionRepo.FetchSuppressionByID(ctx, id)
+	if err != nil {
+		log.WithError(err).Error("[usecase - suppression - RetractSuppression]: Failed to fetch suppression")
+		return nil, err
+	}
+
+	if suppression == nil {
+		return nil, fmt.Errorf("suppression with ID %d not found", id)
+	}
+
+	if suppression.Status != entity.SuppressionStatusDeployed {
+		return nil, fmt.Errorf("suppression with ID %d is not deployed", id)
+	}
+
+	_, content, err := u.renderRuleFile(ctx, suppression.Filename, nil, suppression.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.ruleFileUsecase.PushRuleFile(ctx, suppression.Filename, content, "retract suppression "+strconv.Itoa(suppression.ID)); err != nil {
+		log.WithError(err).Error("[usecase - suppression - RetractSuppression]: Failed to push rule file")
+		return nil, err
+	}
+
+	// The push already reconciled the stored status, keep the returned copy in line with it
+	suppression.Status = entity.SuppressionStatusRolledBack
+	if err := u.suppressionRepo.UpdateSuppression(ctx, suppression); err != nil {
+		return nil, err
+	}
+
+	log.Info("[usecase - suppression - RetractSuppression]: Successfully retracted suppression")
+	return suppression, nil
+}
+
+// PreviewRuleFile returns the current content of the suppression rule file and the content
+// it would have once the suppression is deployed
+func (u *suppressionUsecase) PreviewRuleFile(ctx context.Context, id int) (string, string, error) {
+	suppression, err := u.suppressionRepo.FetchSuppressionByID(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	if suppression == nil {
+		return "", "", fmt.Errorf("suppression with ID %d not found", id)
+	}
+
+	return u.renderRuleFile(ctx, suppression.Filename, suppression, 0)
+}
+
+// renderRuleFile returns the current content of a rule file and the content with its managed block
+// regenerated from every deployed suppression of that file, plus include and minus excludeID
+func (u *suppressionUsecase) renderRuleFile(ctx context.Context, filename string, include *entity.Suppression, excludeID int) (string, string, error) {
+	deployed, err := u.suppressionRepo.FetchSuppressionsByStatus(ctx, entity.SuppressionStatusDeployed)
+	if err != nil {
+		return "", "", err
+	}
+
+	var rules []wazuh.SuppressionRule
+	for _, suppression := range deployed {
+		if suppression.Filename != filename || suppression.ID == excludeID {
+			continue
+		}
+		if include != nil && suppression.ID == include.ID {
+			continue
+		}
+		rules = append(rules, toWazuhSuppressionRule(suppression))
+	}
+	if include != nil {
+		rules = append(rules, toWazuhSuppressionRule(include))
+	}
+
+	block, err := wazuh.GenerateSuppressionRulesXML(rules)
+	if err != nil {
+		return "", "", err
+	}
+
+	current, _, err := u.ruleFileRepo.GetRuleFile(ctx, filename)
+	if err != nil {
+		return "", "", err
+	}
+
+	return current, wazuh.MergeSuppressionBlock(current, block), nil
+}
+
+func toWazuhSuppressionRule(suppression *entity.Suppression) wazuh.SuppressionRule {
+	rule := wazuh.SuppressionRule{
+		ID:           suppression.WazuhRuleID,
+		ParentRuleID: suppression.ParentRuleID,
+		Description:  suppression.Description,
+	}
+
+	for _, condition := range suppression.Conditions {
+		rule.Conditions = append(rule.Conditions, wazuh.SuppressionCondition{
+			Field:    condition.Field,
+			Value:    condition.Value,
+			Operator: condition.Operator,
+			Negate:   condition.Negate,
+		})
+	}
+
+	return rule
+}
+
+func suppressionRulesFile() string {
+	if filename := os.Getenv("SUPPRESSION_RULES_FILE"); filename != "" {
+		return filename
+	}
+	return defaultSuppressionRulesFile
+}
+
+func suppressionRuleIDBase() int {
+	base, err := strconv.Atoi(os.Getenv("SUPPRESSION_RULE_ID_BASE"))
+	if err != nil || base <= 0 {
+		return defaultSuppressionRuleIDBase
+	}
+	return base
+}
@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"automation-wazuh-triage/pkg/notifier"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMinerWindow is how far back analyst closures are mined
+	defaultMinerWindow = 7 * 24 * time.Hour
+
+	// defaultMinerMinCount is the number of closures of a rule and value needed for a proposal
+	defaultMinerMinCount = 10
+
+	// minerSampleSize bounds the closed events attached to a proposal as evidence
+	minerSampleSize = 10
+
+	// minerMaxProposalsPerRun keeps one run from flooding the review queue
+	minerMaxProposalsPerRun = 20
+
+	// minerTopReasons is the number of most frequent closure reasons reported per candidate
+	minerTopReasons = 3
+)
+
+// minerFields are the fields closures are grouped by, keyed by the suppression condition field they map to
+var minerFields = []string{"agent.name", "srcip", "user", "location"}
+
+type suppressionMinerUsecase struct {
+	closedEventRepo domain.ClosedEventRepository
+	suppressionRepo domain.SuppressionRepository
+	proposalUsecase domain.ProposalUsecase
+	notifier        *notifier.Notifier
+}
+
+func NewSuppressionMinerUsecase(
+	closedEventRepo domain.ClosedEventRepository,
+	suppressionRepo domain.SuppressionRepository,
+	proposalUsecase domain.ProposalUsecase,
+	notifier *notifier.Notifier,
+) domain.SuppressionMinerUsecase {
+	return &suppressionMinerUsecase{
+		closedEventRepo: closedEventRepo,
+		suppressionRepo: suppressionRepo,
+		proposalUsecase: proposalUsecase,
+		notifier:        notifier,
+	}
+}
+
+// minerGroup accumulates the closures of one rule and field value
+type minerGroup struct {
+	candidate entity.SuppressionCandidate
+	reasons   map[string]int
+}
+
+// MineSuppressions groups analyst closures within the window by rule and field value. Every group
+// above the threshold becomes a suppression proposal submitted for review, never a deployed rule.
+func (u *suppressionMinerUsecase) MineSuppressions(ctx context.Context, dryRun bool) ([]entity.SuppressionCandidate, error) {
+	log := logger.WithRequestID(ctx)
+
+	window := minerWindow()
+	closedEvents, err := u.closedEventRepo.FetchClosedEventsSince(ctx, time.Now().Add(-window))
+	if err != nil {
+		log.WithError(err).Error("[usecase - suppression_miner - MineSuppressions]: Failed to fetch closed events")
+		return nil, err
+	}
+
+	candidates := groupClosures(closedEvents, minerMinCount())
+
+	existing, err := u.existingSuppressionKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	created := 0
+	for i := range candidates {
+		candidate := &candidates[i]
+
+		if existing[suppressionKey(candidate.RuleID, candidate.Field, candidate.Value)] {
+			candidate.Skipped = "a suppression with this condition was already proposed"
+			continue
+		}
+		if dryRun {
+			continue
+		}
+		if created >= minerMaxProposalsPerRun {
+			candidate.Skipped = "proposal limit per run reached"
+			continue
+		}
+
+		proposal, err := u.proposeCandidate(ctx, candidate, window)
+		if err != nil {
+			log.WithError(err).WithField("rule_id", candidate.RuleID).WithField("field", candidate.Field).Warn("[usecase - suppression_miner - MineSuppressions]: Failed to create proposal")
+			candidate.Skipped = err.Error()
+			continue
+		}
+
+		candidate.ProposalID = proposal.ID
+		created++
+	}
+
+	log.WithField("closures", len(closedEvents)).WithField("candidates", len(candidates)).WithField("proposals", created).Info("[usecase - suppression_miner - MineSuppressions]: Completed suppression mining")
+	return candidates, nil
+}
+
+// RunScheduledMining mines closures and notifies reviewers when new proposals are waiting
+func (u *suppressionMinerUsecase) RunScheduledMining(ctx context.Context) error {
+	candidates, err := u.MineSuppressions(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	var proposed []entity.SuppressionCandidate
+	for _, candidate := range candidates {
+		if candidate.ProposalID != 0 {
+			proposed = append(proposed, candidate)
+		}
+	}
+
+	if len(proposed) == 0 {
+		return nil
+	}
+
+	return u.notifier.Notify(ctx, notifier.Notification{
+		Title:    "Suppression proposals awaiting review",
+		Severity: "info",
+		Message:  fmt.Sprintf("%d suppression proposal(s) were mined from analyst closures and need approval", len(proposed)),
+		Data:     proposed,
+	})
+}
+
+// proposeCandidate files a proposal for the candidate and submits it for approval
+func (u *suppressionMinerUsecase) proposeCandidate(ctx context.Context, candidate *entity.SuppressionCandidate, window time.Duration) (*entity.Proposal, error) {
+	parentRuleID, err := strconv.Atoi(candidate.RuleID)
+	if err != nil {
+		return nil, fmt.Errorf("rule ID %q is not numeric", candidate.RuleID)
+	}
+
+	description := fmt.Sprintf(
+		"Mined from %d analyst closures of rule %s with %s=%s in the last %s (%.0f%% of the rule's %d closures, first %s, last %s).",
+		candidate.Count, candidate.RuleID, candidate.Field, candidate.Value, window,
+		candidate.Share*100, candidate.RuleClosures,
+		candidate.FirstClosedAt.Format(time.RFC3339), candidate.LastClosedAt.Format(time.RFC3339),
+	)
+	if len(candidate.TopReasons) > 0 {
+		description += " Top closure reasons: " + strings.Join(candidate.TopReasons, "; ") + "."
+	}
+
+	proposal, err := u.proposalUsecase.CreateProposal(ctx, &model.CreateProposalRequest{
+		Type:        entity.ProposalTypeSuppression,
+		Title:       fmt.Sprintf("Suppress rule %s for %s %s", candidate.RuleID, candidate.Field, candidate.Value),
+		Description: description,
+		Author:      entity.ProposalAuthorMiner,
+		Suppression: &model.ProposalSuppression{
+			ParentRuleID: parentRuleID,
+			Conditions:   []entity.SuppressionCondition{{Field: candidate.Field, Value: candidate.Value}},
+		},
+		EvidenceEventIDs: candidate.SampleEventIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return u.proposalUsecase.SubmitProposal(ctx, proposal.ID, entity.ProposalAuthorMiner)
+}
+
+// existingSuppressionKeys returns the single-condition suppressions that already exist in any status,
+// so rejected candidates are not proposed again on every run
+func (u *suppressionMinerUsecase) existingSuppressionKeys(ctx context.Context) (map[string]bool, error) {
+	suppressions, err := u.suppressionRepo.FetchSuppressions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for _, suppression := range suppressions {
+		if len(suppression.Conditions) != 1 {
+			continue
+		}
+		condition := suppression.Conditions[0]
+		if condition.Negate || (condition.Operator != "" && condition.Operator != "equals") {
+			continue
+		}
+		keys[suppressionKey(strconv.Itoa(suppression.ParentRuleID), condition.Field, condition.Value)] = true
+	}
+
+	return keys, nil
+}
+
+// groupClosures counts analyst closures per rule and field value and returns the groups at or
+// above minCount, most frequent first. Auto-closed events and confirmed true positives are ignored.
+func groupClosures(closedEvents []*entity.ClosedEvent, minCount int) []entity.SuppressionCandidate {
+	groups := map[string]*minerGroup{}
+	ruleClosures := map[string]int{}
+
+	// Closed events arrive newest first, so the first sample of a group is its latest closure
+	for _, closedEvent := range closedEvents {
+		if closedEvent.CloseType != entity.CloseTypeManual || closedEvent.Label == entity.LabelTruePositive || closedEvent.RuleID == "" {
+			continue
+		}
+		ruleClosures[closedEvent.RuleID]++
+
+		values := closureFieldValues(closedEvent.RawEvent)
+		for _, field := range minerFields {
+			value := values[field]
+			if value == "" {
+				continue
+			}
+
+			key := suppressionKey(closedEvent.RuleID, field, value)
+			group, ok := groups[key]
+			if !ok {
+				group = &minerGroup{
+					candidate: entity.SuppressionCandidate{
+						RuleID:       closedEvent.RuleID,
+						Field:        field,
+						Value:        value,
+						LastClosedAt: closedEvent.CloseAt,
+					},
+					reasons: map[string]int{},
+				}
+				groups[key] = group
+			}
+
+			group.candidate.Count++
+			group.candidate.FirstClosedAt = closedEvent.CloseAt
+			group.reasons[strings.TrimSpace(closedEvent.Reason)]++
+			if len(group.candidate.SampleEventIDs) < minerSampleSize {
+				group.candidate.SampleEventIDs = append(group.candidate.SampleEventIDs, strconv.Itoa(closedEvent.ID))
+			}
+		}
+	}
+
+	var candidates []entity.SuppressionCandidate
+	for _, group := range groups {
+		if group.candidate.Count < minCount {
+			continue
+		}
+
+		candidate := group.candidate
+		candidate.RuleClosures = ruleClosures[candidate.RuleID]
+		candidate.Share = float64(candidate.Count) / float64(candidate.RuleClosures)
+		candidate.TopReasons = topReasons(group.reasons, minerTopReasons)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		return suppressionKey(candidates[i].RuleID, candidates[i].Field, candidates[i].Value) <
+			suppressionKey(candidates[j].RuleID, candidates[j].Field, candidates[j].Value)
+	})
+
+	return candidates
+}
+
+// closureFieldValues extracts the mined fields from a stored search hit
+func closureFieldValues(rawEvent string) map[string]string {
+	var hit struct {
+		Source struct {
+			Agent struct {
+				Name string `json:"name"`
+			} `json:"agent"`
+			Data struct {
+				SrcIP   string `json:"srcip"`
+				SrcUser string `json:"srcuser"`
+				DstUser string `json:"dstuser"`
+			} `json:"data"`
+			Location string `json:"location"`
+		} `json:"_source"`
+	}
+
+	values := map[string]string{}
+	if err := json.Unmarshal([]byte(rawEvent), &hit); err != nil {
+		return values
+	}
+
+	source := hit.Source
+	values["agent.name"] = source.Agent.Name
+	values["location"] = source.Location
+
+	// Only literal addresses can become <srcip> conditions
+	if net.ParseIP(source.Data.SrcIP) != nil {
+		values["srcip"] = source.Data.SrcIP
+	}
+
+	values["user"] = source.Data.SrcUser
+	if values["user"] == "" {
+		values["user"] = source.Data.DstUser
+	}
+
+	return values
+}
+
+func topReasons(reasons map[string]int, limit int) []string {
+	var sorted []string
+	for reason := range reasons {
+		sorted = append(sorted, reason)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if reasons[sorted[i]] != reasons[sorted[j]] {
+			return reasons[sorted[i]] > reasons[sorted[j]]
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+func suppressionKey(ruleID string, field string, value string) string {
+	return ruleID + "|" + field + "|" + value
+}
+
+func minerWindow() time.Duration {
+	window, err := time.ParseDuration(os.Getenv("SUPPRESSION_MINER_WINDOW"))
+	if err != nil || window <= 0 {
+		return defaultMinerWindow
+	}
+	return window
+}
+
+func minerMinCount() int {
+	count, err := strconv.Atoi(os.Getenv("SUPPRESSION_MINER_MIN_COUNT"))
+	if err != nil || count <= 0 {
+		return defaultMinerMinCount
+	}
+	return count
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memRuleFiles is a manager holding rule files in memory. Reads pause briefly so unserialized
+// read-modify-write cycles overlap.
+type memRuleFiles struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func newMemRuleFiles() *memRuleFiles {
+	return &memRuleFiles{files: map[string]string{}}
+}
+
+func (m *memRuleFiles) GetRuleFile(ctx context.Context, filename string) (string, bool, error) {
+	m.mu.Lock()
+	content, exists := m.files[filename]
+	m.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	return content, exists, nil
+}
+
+func (m *memRuleFiles) UpdateRuleFile(ctx context.Context, filename string, content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filename] = content
+	return nil
+}
+
+func (m *memRuleFiles) DeleteRuleFile(ctx context.Context, filename string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.files, filename)
+	return nil
+}
+
+func (m *memRuleFiles) ValidateConfiguration(ctx context.Context) error { return nil }
+func (m *memRuleFiles) RestartManager(ctx context.Context) error        { return nil }
+
+type memRuleFileVersions struct {
+	mu       sync.Mutex
+	versions []*entity.RuleFileVersion
+}
+
+func (m *memRuleFileVersions) SaveVersion(ctx context.Context, version *entity.RuleFileVersion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	version.ID = len(m.versions) + 1
+	m.versions = append(m.versions, version)
+	return nil
+}
+
+func (m *memRuleFileVersions) FetchVersions(ctx context.Context, filename string) ([]*entity.RuleFileVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var versions []*entity.RuleFileVersion
+	for _, version := range m.versions {
+		if version.Filename == filename {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// memSuppressions stores copies, like a database, so callers never share a suppression
+type memSuppressions struct {
+	mu           sync.Mutex
+	suppressions map[int]entity.Suppression
+}
+
+func newMemSuppressions() *memSuppressions {
+	return &memSuppressions{suppressions: map[int]entity.Suppression{}}
+}
+
+func (m *memSuppressions) SaveSuppression(ctx context.Context, suppression *entity.Suppression) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	suppression.ID = len(m.suppressions) + 1
+	m.suppressions[suppression.ID] = *suppression
+	return nil
+}
+
+func (m *memSuppressions) FetchSuppressions(ctx context.Context) ([]*entity.Suppression, error) {
+	return m.FetchSuppressionsByStatus(ctx, "")
+}
+
+func (m *memSuppressions) FetchSuppressionsByStatus(ctx context.Context, status string) ([]*entity.Suppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var suppressions []*entity.Suppression
+	for id := 1; id <= len(m.suppressions); id++ {
+		suppression := m.suppressions[id]
+		if status == "" || suppression.Status == status {
+			suppressions = append(suppressions, &suppression)
+		}
+	}
+	return suppressions, nil
+}
+
+func (m *memSuppressions) FetchSuppressionByID(ctx context.Context, id int) (*entity.Suppression, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	suppression, ok := m.suppressions[id]
+	if !ok {
+		return nil, nil
+	}
+	return &suppression, nil
+}
+
+func (m *memSuppressions) UpdateSuppression(ctx context.Context, suppression *entity.Suppression) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suppressions[suppression.ID] = *suppression
+	return nil
+}
+
+// newTestSuppressionUsecase wires the suppression and rule file usecases over in-memory stores
+func newTestSuppressionUsecase() (*suppressionUsecase, *memRuleFiles, *memSuppressions) {
+	files := newMemRuleFiles()
+	suppressions := newMemSuppressions()
+	ruleFiles := NewRuleFileUsecase(files, &memRuleFileVersions{}, suppressions)
+	return NewSuppressionUsecase(suppressions, files, ruleFiles).(*suppressionUsecase), files, suppressions
+}
+
+func createApprovedSuppression(t *testing.T, u *suppressionUsecase, repo *memSuppressions, parentRuleID int) *entity.Suppression {
+	t.Helper()
+
+	suppression, err := u.CreateSuppression(context.Background(), &entity.Suppression{
+		ParentRuleID: parentRuleID,
+		Conditions:   []entity.SuppressionCondition{{Field: "data.srcip", Value: "10.0.0." + strconv.Itoa(parentRuleID%250)}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSuppression: %v", err)
+	}
+	suppression.Status = entity.SuppressionStatusApproved
+	if err := repo.UpdateSuppression(context.Background(), suppression); err != nil {
+		t.Fatalf("UpdateSuppression: %v", err)
+	}
+	return suppression
+}
+
+func TestDeploySuppressionConcurrently(t *testing.T) {
+	u, files, repo := newTestSuppressionUsecase()
+
+	var suppressions []*entity.Suppression
+	for i := 0; i < 8; i++ {
+		suppressions = append(suppressions, createApprovedSuppression(t, u, repo, 5700+i))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(suppressions))
+	for _, suppression := range suppressions {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if _, err := u.DeploySuppression(context.Background(), id); err != nil {
+				errs <- fmt.Errorf("deploy %d: %w", id, err)
+			}
+		}(suppression.ID)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	content, _, _ := files.GetRuleFile(context.Background(), suppressionRulesFile())
+	for _, suppression := range suppressions {
+		stored, _ := repo.FetchSuppressionByID(context.Background(), suppression.ID)
+		present := strings.Contains(content, `<rule id="`+strconv.Itoa(stored.WazuhRuleID)+`"`)
+		if !present || stored.Status != entity.SuppressionStatusDeployed {
+			t.Errorf("suppression %d: in rule file = %v, status = %s", stored.ID, present, stored.Status)
+		}
+	}
+}
+
+func TestDeployAndRetractSuppression(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		retract    bool
+		wantErr    string
+		wantStatus string
+		wantInFile bool
+	}{
+		{name: "deploy approved", status: entity.SuppressionStatusApproved, wantStatus: entity.SuppressionStatusDeployed, wantInFile: true},
+		{name: "deploy proposed", status: entity.SuppressionStatusProposed, wantErr: "is not approved", wantStatus: entity.SuppressionStatusProposed},
+		{name: "deploy deployed", status: entity.SuppressionStatusDeployed, wantErr: "is already deployed", wantStatus: entity.SuppressionStatusDeployed},
+		{name: "retract deployed", status: entity.SuppressionStatusApproved, retract: true, wantStatus: entity.SuppressionStatusRolledBack},
+		{name: "retract approved", status: entity.SuppressionStatusApproved, retract: true, wantErr: "is not deployed", wantStatus: entity.SuppressionStatusApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			u, files, repo := newTestSuppressionUsecase()
+			kept := createApprovedSuppression(t, u, repo, 5710)
+			if _, err := u.DeploySuppression(ctx, kept.ID); err != nil {
+				t.Fatalf("deploy kept suppression: %v", err)
+			}
+
+			suppression := createApprovedSuppression(t, u, repo, 5711)
+			// A retract of a deployed suppression deploys it first
+			if tt.retract && tt.wantErr == "" {
+				if _, err := u.DeploySuppression(ctx, suppression.ID); err != nil {
+					t.Fatalf("deploy: %v", err)
+				}
+			} else {
+				suppression.Status = tt.status
+				_ = repo.UpdateSuppression(ctx, suppression)
+			}
+
+			var err error
+			if tt.retract {
+				_, err = u.RetractSuppression(ctx, suppression.ID)
+			} else {
+				_, err = u.DeploySuppression(ctx, suppression.ID)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			stored, _ := repo.FetchSuppressionByID(ctx, suppression.ID)
+			if stored.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+
+			content, _, _ := files.GetRuleFile(ctx, suppressionRulesFile())
+			if inFile := strings.Contains(content, `<rule id="`+strconv.Itoa(stored.WazuhRuleID)+`"`); inFile != tt.wantInFile && tt.wantErr == "" {
+				t.Errorf("in rule file = %v, want %v", inFile, tt.wantInFile)
+			}
+			if !strings.Contains(content, `<rule id="`+strconv.Itoa(kept.WazuhRuleID)+`"`) {
+				t.Errorf("the other deployed suppression was dropped from the rule file")
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/domain"
+	"automation-wazuh-triage/internal/entity"
+	"automation-wazuh-triage/internal/model"
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultTimelinePeriod is how far back a timeline reads when no start is given
+	defaultTimelinePeriod = 24 * time.Hour
+	maxTimelinePeriod     = 31 * 24 * time.Hour
+
+	defaultTimelineLimit = 100
+	maxTimelineLimit     = 500
+)
+
+// timelineRanks orders the item kinds sharing a time, as listed by entity.TimelineItemTypes
+var timelineRanks = map[string]int{
+	entity.TimelineItemAlert:       0,
+	entity.TimelineItemTriage:      1,
+	entity.TimelineItemMaintenance: 2,
+}
+
+// timelineKey is the position of an item: its time, then its kind, then its ID within the kind. Alert IDs
+// compare as strings, as the indexer sorts them, and action and window IDs as numbers.
+type timelineKey struct {
+	at   time.Time
+	kind string
+	id   string
+}
+
+// after reports whether the key is ordered after the other one
+func (k timelineKey) after(other timelineKey) bool {
+	if !k.at.Equal(other.at) {
+		return k.at.After(other.at)
+	}
+	if k.kind != other.kind {
+		return timelineRanks[k.kind] > timelineRanks[other.kind]
+	}
+	if k.kind == entity.TimelineItemAlert {
+		return k.id > other.id
+	}
+	id, _ := strconv.Atoi(k.id)
+	otherID, _ := strconv.Atoi(other.id)
+	return id > otherID
+}
+
+// encodeTimelineCursor writes the key of the last item of a page as an opaque cursor
+func encodeTimelineCursor(key timelineKey) string {
+	value := fmt.Sprintf("%d|%s|%s", key.at.UnixNano(), key.kind, key.id)
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// decodeTimelineCursor reads a cursor written by encodeTimelineCursor
+func decodeTimelineCursor(cursor string) (timelineKey, error) {
+	value, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return timelineKey{}, fmt.Errorf("invalid cursor")
+	}
+	parts := strings.SplitN(string(value), "|", 3)
+	if len(parts) != 3 {
+		return timelineKey{}, fmt.Errorf("invalid cursor")
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if _, ok := timelineRanks[parts[1]]; err != nil || !ok || parts[2] == "" {
+		return timelineKey{}, fmt.Errorf("invalid cursor")
+	}
+	return timelineKey{at: time.Unix(0, nanos).UTC(), kind: parts[1], id: parts[2]}, nil
+}
+
+// timelineEntry is an item with its position
+type timelineEntry struct {
+	key  timelineKey
+	item entity.TimelineItem
+}
+
+type timelineUsecase struct {
+	wazuhEventRepo     domain.WazuhEventRepository
+	triageActionRepo   domain.TriageActionRepository
+	agentRepo          domain.AgentRepository
+	maintenanceUsecase domain.MaintenanceUsecase
+}
+
+func NewTimelineUsecase(
+	wazuhEventRepo domain.WazuhEventRepository,
+	triageActionRepo domain.TriageActionRepository,
+	agentRepo domain.AgentRepository,
+	maintenanceUsecase domain.MaintenanceUsecase,
+) domain.TimelineUsecase {
+	return &timelineUsecase{
+		wazuhEventRepo:     wazuhEventRepo,
+		triageActionRepo:   triageActionRepo,
+		agentRepo:          agentRepo,
+		maintenanceUsecase: maintenanceUsecase,
+	}
+}
+
+// FetchAgentTimeline returns one page of the alerts an agent raised, the triage actions taken on them and the
+// runs of the maintenance windows covering it, merged oldest first. Each source is read from just after the
+// cursor, one item more than the page holds, so the merged page knows whether another one follows.
+func (u *timelineUsecase) FetchAgentTimeline(ctx context.Context, agentID string, request *model.AgentTimelineRequest) (*entity.AgentTimeline, error) {
+	log := logger.WithRequestID(ctx).WithField("agent_id", agentID)
+
+	types := cleanList(request.Types)
+	if len(types) == 0 {
+		types = entity.TimelineItemTypes
+	}
+	for _, itemType := range types {
+		if !containsString(entity.TimelineItemTypes, itemType) {
+			return nil, fmt.Errorf("invalid type %q, expected one of %s", itemType, strings.Join(entity.TimelineItemTypes, ", "))
+		}
+	}
+
+	to := request.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := request.From
+	if from.IsZero() {
+		from = to.Add(-defaultTimelinePeriod)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid period: from must be before to")
+	}
+	if to.Sub(from) > maxTimelinePeriod {
+		return nil, fmt.Errorf("invalid period: must be at most %s", maxTimelinePeriod)
+	}
+	if request.MinLevel < 0 {
+		return nil, fmt.Errorf("invalid min_level: must not be negative")
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultTimelineLimit
+	}
+	if limit > maxTimelineLimit {
+		limit = maxTimelineLimit
+	}
+
+	var cursor *timelineKey
+	if request.Cursor != "" {
+		key, err := decodeTimelineCursor(request.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &key
+	}
+
+	timeline := &entity.AgentTimeline{
+		AgentID: agentID,
+		From:    from.UTC(),
+		To:      to.UTC(),
+		Types:   types,
+		Items:   []entity.TimelineItem{},
+	}
+
+	// The name matches maintenance windows by glob and actions recorded before the agent ID was kept. An agent
+	// removed from Wazuh is named after its alerts.
+	agent, err := u.agentRepo.FetchAgentByID(ctx, agentID)
+	if err != nil {
+		log.WithError(err).Warn("[usecase - timeline - FetchAgentTimeline]: Failed to fetch agent, naming it after its alerts")
+	}
+	if agent != nil {
+		timeline.AgentName = agent.Name
+	}
+
+	var entries []timelineEntry
+
+	if containsString(types, entity.TimelineItemAlert) {
+		alerts, err := u.fetchAlertEntries(ctx, agentID, from, to, request, cursor, limit+1)
+		if err != nil {
+			log.WithError(err).Error("[usecase - timeline - FetchAgentTimeline]: Failed to fetch alerts")
+			return nil, err
+		}
+		entries = append(entries, alerts...)
+	}
+	if timeline.AgentName == "" {
+		timeline.AgentName = u.fetchAlertAgentName(ctx, agentID, from, to)
+	}
+
+	if containsString(types, entity.TimelineItemTriage) {
+		actions, err := u.fetchTriageEntries(ctx, agentID, timeline.AgentName, from, to, request.RuleID, cursor, limit+1)
+		if err != nil {
+			log.WithError(err).Error("[usecase - timeline - FetchAgentTimeline]: Failed to fetch triage actions")
+			return nil, err
+		}
+		entries = append(entries, actions...)
+	}
+
+	if containsString(types, entity.TimelineItemMaintenance) {
+		occurrences, err := u.maintenanceUsecase.FetchAgentOccurrences(ctx, agentID, timeline.AgentName, from, to)
+		if err != nil {
+			log.WithError(err).Error("[usecase - timeline - FetchAgentTimeline]: Failed to fetch maintenance windows")
+			return nil, err
+		}
+		for i := range occurrences {
+			occurrence := occurrences[i]
+			entries = append(entries, timelineEntry{
+				key: timelineKey{at: occurrence.StartsAt, kind: entity.TimelineItemMaintenance, id: strconv.Itoa(occurrence.WindowID)},
+				item: entity.TimelineItem{
+					Type:        entity.TimelineItemMaintenance,
+					Time:        occurrence.StartsAt,
+					Maintenance: &occurrence,
+				},
+			})
+		}
+	}
+
+	// Every source was read from the cursor on, the filter only guards the items sharing its time
+	if cursor != nil {
+		remaining := entries[:0]
+		for _, entry := range entries {
+			if entry.key.after(*cursor) {
+				remaining = append(remaining, entry)
+			}
+		}
+		entries = remaining
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[j].key.after(entries[i].key)
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+		timeline.NextCursor = encodeTimelineCursor(entries[limit-1].key)
+	}
+	for _, entry := range entries {
+		timeline.Items = append(timeline.Items, entry.item)
+	}
+
+	return timeline, nil
+}
+
+// fetchAlertEntries reads up to size alerts of the agent ordered after the cursor. After an alert the indexer
+// resumes from its sort values; after another kind, from the next millisecond, the precision of alert times.
+func (u *timelineUsecase) fetchAlertEntries(ctx context.Context, agentID string, from time.Time, to time.Time, request *model.AgentTimelineRequest, cursor *timelineKey, size int) ([]timelineEntry, error) {
+	var searchAfter []interface{}
+	if cursor != nil {
+		if cursor.kind == entity.TimelineItemAlert {
+			searchAfter = []interface{}{cursor.at.UnixMilli(), cursor.id}
+		} else if next := cursor.at.Truncate(time.Millisecond).Add(time.Millisecond); next.After(from) {
+			from = next
+		}
+	}
+	if from.After(to) {
+		return nil, nil
+	}
+
+	hits, err := u.wazuhEventRepo.FetchAgentAlerts(ctx, agentID, from, to, request.MinLevel, request.RuleID, searchAfter, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []timelineEntry
+	for _, hit := range hits {
+		var securityEvent entity.WazuhSecurityEvent
+		source, ok := decodeAlertSource(hit.Source)
+		if err := json.Unmarshal(hit.Source, &securityEvent); err != nil || !ok {
+			continue
+		}
+		firedAt, ok := parseAlertTimestamp(source.Timestamp)
+		if !ok {
+			continue
+		}
+		firedAt = firedAt.UTC()
+
+		alert := &entity.TimelineAlert{
+			EventID:         string(securityEvent.ID),
+			Index:           hit.Index,
+			RuleID:          source.Rule.ID,
+			RuleDescription: source.Rule.Description,
+			SrcIP:           source.Data.SrcIP,
+			User:            alertUser(source),
+			Mitre:           source.Rule.Mitre.ID,
+		}
+		if source.Rule.Level != nil {
+			alert.RuleLevel = *source.Rule.Level
+		}
+
+		entries = append(entries, timelineEntry{
+			key: timelineKey{at: firedAt, kind: entity.TimelineItemAlert, id: alert.EventID},
+			item: entity.TimelineItem{
+				Type:  entity.TimelineItemAlert,
+				Time:  firedAt,
+				Alert: alert,
+			},
+		})
+	}
+
+	return entries, nil
+}
+
+// fetchTriageEntries reads up to size triage actions on the agent's alerts ordered after the cursor
+func (u *timelineUsecase) fetchTriageEntries(ctx context.Context, agentID string, agentName string, from time.Time, to time.Time, ruleID string, cursor *timelineKey, size int) ([]timelineEntry, error) {
+	afterID := 0
+	if cursor != nil {
+		switch cursor.kind {
+		case entity.TimelineItemAlert:
+			if cursor.at.After(from) {
+				from = cursor.at
+			}
+		case entity.TimelineItemTriage:
+			afterID, _ = strconv.Atoi(cursor.id)
+		default:
+			if next := cursor.at.Add(time.Nanosecond); next.After(from) {
+				from = next
+			}
+		}
+	}
+	if from.After(to) {
+		return nil, nil
+	}
+
+	actions, err := u.triageActionRepo.FetchTriageActionsByAgent(ctx, agentID, agentName, ruleID, from, to, afterID, size)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]timelineEntry, 0, len(actions))
+	for _, action := range actions {
+		createdAt := action.CreatedAt.UTC()
+		entries = append(entries, timelineEntry{
+			key: timelineKey{at: createdAt, kind: entity.TimelineItemTriage, id: strconv.Itoa(action.ID)},
+			item: entity.TimelineItem{
+				Type:         entity.TimelineItemTriage,
+				Time:         createdAt,
+				TriageAction: action,
+			},
+		})
+	}
+
+	return entries, nil
+}
+
+// fetchAlertAgentName names an agent Wazuh does not know after its first alert of the period, regardless of
+// the filters, so every page of a timeline uses the same name
+func (u *timelineUsecase) fetchAlertAgentName(ctx context.Context, agentID string, from time.Time, to time.Time) string {
+	hits, err := u.wazuhEventRepo.FetchAgentAlerts(ctx, agentID, from, to, 0, "", nil, 1)
+	if err != nil || len(hits) == 0 {
+		return ""
+	}
+	source, _ := decodeAlertSource(hits[0].Source)
+	return source.Agent.Name
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"automation-wazuh-triage/internal/entity"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestTimelineKeyAfter(t *testing.T) {
+	at := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		key   timelineKey
+		other timelineKey
+		want  bool
+	}{
+		{
+			name:  "later time",
+			key:   timelineKey{at: at.Add(time.Nanosecond), kind: entity.TimelineItemAlert, id: "a"},
+			other: timelineKey{at: at, kind: entity.TimelineItemMaintenance, id: "9"},
+			want:  true,
+		},
+		{
+			name:  "earlier time wins over kind",
+			key:   timelineKey{at: at, kind: entity.TimelineItemMaintenance, id: "1"},
+			other: timelineKey{at: at.Add(time.Second), kind: entity.TimelineItemAlert, id: "a"},
+			want:  false,
+		},
+		{
+			name:  "same instant in another location",
+			key:   timelineKey{at: at.In(time.FixedZone("UTC+2", 2*60*60)), kind: entity.TimelineItemAlert, id: "b"},
+			other: timelineKey{at: at, kind: entity.TimelineItemAlert, id: "a"},
+			want:  true,
+		},
+		{
+			name:  "triage after alert at the same time",
+			key:   timelineKey{at: at, kind: entity.TimelineItemTriage, id: "1"},
+			other: timelineKey{at: at, kind: entity.TimelineItemAlert, id: "z"},
+			want:  true,
+		},
+		{
+			name:  "alert before triage at the same time",
+			key:   timelineKey{at: at, kind: entity.TimelineItemAlert, id: "z"},
+			other: timelineKey{at: at, kind: entity.TimelineItemTriage, id: "1"},
+			want:  false,
+		},
+		{
+			name:  "maintenance after triage at the same time",
+			key:   timelineKey{at: at, kind: entity.TimelineItemMaintenance, id: "1"},
+			other: timelineKey{at: at, kind: entity.TimelineItemTriage, id: "99"},
+			want:  true,
+		},
+		{
+			name:  "alert ids compare as strings",
+			key:   timelineKey{at: at, kind: entity.TimelineItemAlert, id: "9"},
+			other: timelineKey{at: at, kind: entity.TimelineItemAlert, id: "10"},
+			want:  true,
+		},
+		{
+			name:  "triage ids compare as numbers",
+			key:   timelineKey{at: at, kind: entity.TimelineItemTriage, id: "10"},
+			other: timelineKey{at: at, kind: entity.TimelineItemTriage, id: "9"},
+			want:  true,
+		},
+		{
+			name:  "maintenance ids compare as numbers",
+			key:   timelineKey{at: at, kind: entity.TimelineItemMaintenance, id: "9"},
+			other: timelineKey{at: at, kind: entity.TimelineItemMaintenance, id: "10"},
+			want:  false,
+		},
+		{
+			name:  "equal keys",
+			key:   timelineKey{at: at, kind: entity.TimelineItemAlert, id: "a"},
+			other: timelineKey{at: at, kind: entity.TimelineItemAlert, id: "a"},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.after(tt.other); got != tt.want {
+				t.Fatalf("%+v after %+v = %v, want %v", tt.key, tt.other, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimelineCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		key  timelineKey
+	}{
+		{name: "alert", key: timelineKey{at: time.Date(2026, 10, 19, 9, 0, 0, 123456789, time.UTC), kind: entity.TimelineItemAlert, id: "1760864400.123456"}},
+		{name: "alert id with separator", key: timelineKey{at: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), kind: entity.TimelineItemAlert, id: "a|b"}},
+		{name: "triage", key: timelineKey{at: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), kind: entity.TimelineItemTriage, id: "42"}},
+		{name: "maintenance", key: timelineKey{at: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), kind: entity.TimelineItemMaintenance, id: "7"}},
+		{name: "location is normalized", key: timelineKey{at: time.Date(2026, 10, 19, 11, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), kind: entity.TimelineItemTriage, id: "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTimelineCursor(encodeTimelineCursor(tt.key))
+			if err != nil {
+				t.Fatalf("decodeTimelineCursor: %v", err)
+			}
+			if !got.at.Equal(tt.key.at) || got.kind != tt.key.kind || got.id != tt.key.id {
+				t.Fatalf("decoded %+v, want %+v", got, tt.key)
+			}
+			if got.at.Location() != time.UTC {
+				t.Fatalf("decoded time is in %s, want UTC", got.at.Location())
+			}
+			if got.after(tt.key) || tt.key.after(got) {
+				t.Fatalf("decoded %+v is not at the position of %+v", got, tt.key)
+			}
+		})
+	}
+}
+
+func TestDecodeTimelineCursorErrors(t *testing.T) {
+	encode := func(value string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte("1|alert|ab"))},
+		{name: "missing parts", cursor: encode("1|alert")},
+		{name: "time is not a number", cursor: encode("soon|alert|a")},
+		{name: "unknown kind", cursor: encode("1|agent|a")},
+		{name: "empty id", cursor: encode("1|triage|")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeTimelineCursor(tt.cursor); err == nil || err.Error() != "invalid cursor" {
+				t.Fatalf("decodeTimelineCursor(%q) error = %v, want invalid cursor", tt.cursor, err)
+			}
+		})
+	}
+}
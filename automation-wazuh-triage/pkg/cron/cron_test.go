@@ -0,0 +1,144 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "too few fields", expression: "* * * *"},
+		{name: "too many fields", expression: "* * * * * *"},
+		{name: "minute out of range", expression: "60 * * * *"},
+		{name: "day of month zero", expression: "0 0 0 * *"},
+		{name: "zero step", expression: "*/0 * * * *"},
+		{name: "negative step", expression: "*/-5 * * * *"},
+		{name: "reversed range", expression: "30-10 * * * *"},
+		{name: "unknown name", expression: "0 0 * * funday"},
+		{name: "month name in weekday field", expression: "0 0 * * jan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expression); err == nil {
+				t.Fatalf("Parse(%q) returned no error", tt.expression)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	local := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, newYork)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		from       time.Time
+		want       time.Time
+	}{
+		// 2026-10-19 is a Monday
+		{name: "strictly after the given time", expression: "0 9 * * *", from: utc(2026, 10, 19, 9, 0), want: utc(2026, 10, 20, 9, 0)},
+		{name: "seconds are dropped", expression: "* * * * *", from: utc(2026, 10, 19, 9, 0).Add(30 * time.Second), want: utc(2026, 10, 19, 9, 1)},
+		{name: "weekday only", expression: "0 9 * * mon", from: utc(2026, 10, 19, 9, 0), want: utc(2026, 10, 26, 9, 0)},
+		{name: "day of month only", expression: "0 9 1 * *", from: utc(2026, 10, 19, 9, 0), want: utc(2026, 11, 1, 9, 0)},
+		{name: "both day fields, weekday first", expression: "0 0 13 * fri", from: utc(2026, 10, 19, 0, 0), want: utc(2026, 10, 23, 0, 0)},
+		{name: "both day fields, day of month first", expression: "0 0 13 * fri", from: utc(2026, 10, 10, 0, 0), want: utc(2026, 10, 13, 0, 0)},
+		{name: "stepped day of month is restricted", expression: "0 0 */10 * mon", from: utc(2026, 10, 19, 0, 0), want: utc(2026, 10, 21, 0, 0)},
+		{name: "weekday 7 is sunday", expression: "0 0 * * 7", from: utc(2026, 10, 19, 0, 0), want: utc(2026, 10, 25, 0, 0)},
+		{name: "weekday range", expression: "0 22 * * mon-fri", from: utc(2026, 10, 23, 22, 0), want: utc(2026, 10, 26, 22, 0)},
+		{name: "month name", expression: "0 0 1 jan *", from: utc(2026, 10, 19, 0, 0), want: utc(2027, 1, 1, 0, 0)},
+		{name: "minute step", expression: "*/15 * * * *", from: utc(2026, 10, 19, 10, 7), want: utc(2026, 10, 19, 10, 15)},
+		{name: "minute step wraps to the next hour", expression: "*/15 * * * *", from: utc(2026, 10, 19, 10, 45), want: utc(2026, 10, 19, 11, 0)},
+		{name: "step from a single value", expression: "5/20 * * * *", from: utc(2026, 10, 19, 10, 26), want: utc(2026, 10, 19, 10, 45)},
+		{name: "step over a range", expression: "0 0-10/5 * * *", from: utc(2026, 10, 19, 6, 0), want: utc(2026, 10, 19, 10, 0)},
+		{name: "list", expression: "0,30 8,17 * * *", from: utc(2026, 10, 19, 8, 30), want: utc(2026, 10, 19, 17, 0)},
+		{name: "leap day", expression: "0 0 29 2 *", from: utc(2026, 10, 19, 0, 0), want: utc(2028, 2, 29, 0, 0)},
+		{name: "impossible date never runs", expression: "0 0 30 2 *", from: utc(2026, 10, 19, 0, 0), want: time.Time{}},
+		// Clocks in New York jump from 02:00 to 03:00 on 2026-03-08
+		{name: "run inside the dst gap is skipped", expression: "30 2 * * *", from: local(2026, 3, 8, 0, 0), want: local(2026, 3, 9, 2, 30)},
+		{name: "step across the dst gap", expression: "*/30 * * * *", from: local(2026, 3, 8, 1, 45), want: local(2026, 3, 8, 3, 0)},
+		{name: "hour after the dst gap", expression: "0 3 * * *", from: local(2026, 3, 8, 0, 0), want: local(2026, 3, 8, 3, 0)},
+		// Clocks in New York go back from 02:00 to 01:00 on 2026-11-01
+		{name: "first run in the repeated hour", expression: "30 1 * * *", from: local(2026, 11, 1, 0, 0), want: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(newYork)},
+		{name: "repeated hour runs again", expression: "30 1 * * *", from: time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC).In(newYork), want: time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC).In(newYork)},
+		{name: "day after the repeated hour", expression: "30 1 * * *", from: time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC).In(newYork), want: local(2026, 11, 2, 1, 30)},
+		{name: "hour after the repeated hour", expression: "0 2 * * *", from: local(2026, 11, 1, 0, 0), want: time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC).In(newYork)},
+		// Clocks in Sao Paulo jumped from midnight to 01:00 on 2018-11-04
+		{name: "day across a dst gap at midnight", expression: "0 12 5 * *", from: time.Date(2018, 11, 3, 12, 0, 0, 0, saoPaulo), want: time.Date(2018, 11, 5, 12, 0, 0, 0, saoPaulo)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expression, err)
+			}
+
+			got := schedule.Next(tt.from)
+			if !got.Equal(tt.want) {
+				t.Fatalf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+			if !got.IsZero() && got.Location() != tt.from.Location() {
+				t.Fatalf("Next(%s) is in %s, want %s", tt.from, got.Location(), tt.from.Location())
+			}
+		})
+	}
+}
+
+// TestNextMovesForward chains runs the way maintenance windows list their occurrences, across both daylight
+// saving changes of a year, and checks that every run is after the one before
+func TestNextMovesForward(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		from       time.Time
+		runs       int
+	}{
+		{name: "daily in the repeated hour", expression: "30 1 * * *", from: time.Date(2026, 10, 30, 0, 0, 0, 0, newYork), runs: 10},
+		{name: "daily in the skipped hour", expression: "30 2 * * *", from: time.Date(2026, 3, 6, 0, 0, 0, 0, newYork), runs: 10},
+		{name: "every quarter hour on fall back day", expression: "*/15 * * * *", from: time.Date(2026, 11, 1, 0, 0, 0, 0, newYork), runs: 200},
+		{name: "every quarter hour on spring forward day", expression: "*/15 * * * *", from: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), runs: 200},
+		{name: "mid-minute start", expression: "* * * * *", from: time.Date(2026, 11, 1, 1, 59, 30, 0, newYork), runs: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expression, err)
+			}
+
+			previous := tt.from
+			for i := 0; i < tt.runs; i++ {
+				next := schedule.Next(previous)
+				if !next.After(previous) {
+					t.Fatalf("run %d: Next(%s) = %s, not after it", i, previous, next)
+				}
+				previous = next
+			}
+		})
+	}
+}
@@ -30,6 +30,84 @@ func InitSQLite(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to create closed_events table: %w", err)
 	}
 
+	if err := migrateClosedEventsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate closed_events table: %w", err)
+	}
+
+	// Create rule snapshot tables
+	if err := createRuleSnapshotTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create rule snapshot tables: %w", err)
+	}
+
+	// Create suppression tables
+	if err := createSuppressionTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create suppression tables: %w", err)
+	}
+
+	if err := createProposalsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create proposals table: %w", err)
+	}
+
+	if err := createTriageActionsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create triage_actions table: %w", err)
+	}
+
+	if err := migrateTriageActionsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate triage_actions table: %w", err)
+	}
+
+	if err := createAutoCloseDecisionsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create auto_close_decisions table: %w", err)
+	}
+
+	if err := createSettingsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create settings table: %w", err)
+	}
+
+	if err := createQAReviewsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create qa_reviews table: %w", err)
+	}
+
+	if err := createGuardrailTripsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create guardrail_trips table: %w", err)
+	}
+
+	if err := createCaseTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create case tables: %w", err)
+	}
+
+	if err := addColumnIfMissing(db, "cases", "low_priority", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, fmt.Errorf("failed to migrate cases table: %w", err)
+	}
+
+	if err := addColumnIfMissing(db, "cases", "high_priority", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return nil, fmt.Errorf("failed to migrate cases table: %w", err)
+	}
+
+	if err := createSequenceFindingsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create sequence_findings table: %w", err)
+	}
+
+	if err := createSnoozeTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create snooze tables: %w", err)
+	}
+
+	if err := createMaintenanceTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create maintenance tables: %w", err)
+	}
+
+	if err := createAssetsTable(db); err != nil {
+		return nil, fmt.Errorf("failed to create assets table: %w", err)
+	}
+
+	if err := createCVETables(db); err != nil {
+		return nil, fmt.Errorf("failed to create cve tables: %w", err)
+	}
+
+	if err := createRetroHuntTables(db); err != nil {
+		return nil, fmt.Errorf("failed to create retro-hunt tables: %w", err)
+	}
+
 	return db, nil
 }
 
@@ -52,3 +130,529 @@ func createClosedEventsTable(db *sql.DB) error {
 	_, err := db.Exec(query)
 	return err
 }
+
+// migrateClosedEventsTable adds the triage columns to databases created before they existed.
+// Closures without a reason came from auto-close, so existing rows are backfilled from it.
+func migrateClosedEventsTable(db *sql.DB) error {
+	if err := addColumnIfMissing(db, "closed_events", "close_type", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(db, "closed_events", "label", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE closed_events
+		SET close_type = CASE WHEN reason = '' THEN 'auto' ELSE 'manual' END
+		WHERE close_type = '';
+		CREATE INDEX IF NOT EXISTS idx_closed_events_rule_id ON closed_events(rule_id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// addColumnIfMissing runs ALTER TABLE ADD COLUMN unless the table already has the column
+func addColumnIfMissing(db *sql.DB, table string, column string, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+func createRuleSnapshotTables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS rule_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			trigger_type TEXT NOT NULL,
+			rule_count INTEGER NOT NULL,
+			hash TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS rule_snapshot_items (
+			snapshot_id INTEGER NOT NULL,
+			rule_id INTEGER NOT NULL,
+			filename TEXT,
+			level INTEGER NOT NULL,
+			status TEXT,
+			rule_groups TEXT,
+			description TEXT,
+			hash TEXT NOT NULL,
+			raw_rule TEXT,
+			PRIMARY KEY (snapshot_id, rule_id),
+			FOREIGN KEY (snapshot_id) REFERENCES rule_snapshots(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_rule_snapshots_created_at ON rule_snapshots(created_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createSuppressionTables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS suppressions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			parent_rule_id INTEGER NOT NULL,
+			conditions TEXT NOT NULL,
+			description TEXT,
+			created_by TEXT,
+			status TEXT NOT NULL,
+			wazuh_rule_id INTEGER,
+			filename TEXT,
+			created_at DATETIME NOT NULL,
+			deployed_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS rule_file_versions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			filename TEXT NOT NULL,
+			content TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			reason TEXT,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_suppressions_status ON suppressions(status);
+		CREATE INDEX IF NOT EXISTS idx_rule_file_versions_filename ON rule_file_versions(filename);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createProposalsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS proposals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			description TEXT,
+			author TEXT NOT NULL,
+			status TEXT NOT NULL,
+			suppression_id INTEGER NOT NULL DEFAULT 0,
+			filename TEXT NOT NULL,
+			content TEXT NOT NULL DEFAULT '',
+			base_content TEXT NOT NULL DEFAULT '',
+			base_hash TEXT NOT NULL DEFAULT '',
+			diff TEXT NOT NULL DEFAULT '',
+			evidence_event_ids TEXT NOT NULL,
+			reviewer TEXT NOT NULL DEFAULT '',
+			review_comment TEXT NOT NULL DEFAULT '',
+			deployed_by TEXT NOT NULL DEFAULT '',
+			rolled_back_by TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL,
+			submitted_at DATETIME,
+			reviewed_at DATETIME,
+			deployed_at DATETIME,
+			rolled_back_at DATETIME
+		);
+		CREATE INDEX IF NOT EXISTS idx_proposals_status ON proposals(status);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createTriageActionsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS triage_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			agent_name TEXT NOT NULL DEFAULT '',
+			action TEXT NOT NULL,
+			actor TEXT NOT NULL,
+			alert_at DATETIME,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_triage_actions_event_id ON triage_actions(event_id);
+		CREATE INDEX IF NOT EXISTS idx_triage_actions_created_at ON triage_actions(created_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// migrateTriageActionsTable adds the agent ID, filled from the stored alert of closed events. Actions on
+// alerts that were never closed keep an empty agent ID.
+func migrateTriageActionsTable(db *sql.DB) error {
+	if err := addColumnIfMissing(db, "triage_actions", "agent_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE triage_actions
+		SET agent_id = COALESCE((
+			SELECT json_extract(c.raw_event, '$._source.agent.id')
+			FROM closed_events c
+			WHERE c.event_id = triage_actions.event_id AND json_valid(c.raw_event)
+			LIMIT 1
+		), '')
+		WHERE agent_id = '';
+		CREATE INDEX IF NOT EXISTS idx_triage_actions_agent_id ON triage_actions(agent_id, created_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createAutoCloseDecisionsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS auto_close_decisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			rule_level INTEGER NOT NULL DEFAULT 0,
+			criterion TEXT NOT NULL,
+			criterion_filter TEXT NOT NULL,
+			mode TEXT NOT NULL,
+			closed_event_id INTEGER NOT NULL DEFAULT 0,
+			raw_event TEXT,
+			label TEXT NOT NULL DEFAULT '',
+			labeled_by TEXT NOT NULL DEFAULT '',
+			labeled_at DATETIME,
+			decided_at DATETIME NOT NULL,
+			UNIQUE(event_id, mode)
+		);
+		CREATE INDEX IF NOT EXISTS idx_auto_close_decisions_decided_at ON auto_close_decisions(decided_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createSettingsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_by TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL
+		);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createQAReviewsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS qa_reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			closed_event_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			criterion TEXT NOT NULL DEFAULT '',
+			sample_day TEXT NOT NULL,
+			raw_event TEXT,
+			status TEXT NOT NULL,
+			reviewer TEXT NOT NULL DEFAULT '',
+			note TEXT NOT NULL DEFAULT '',
+			closed_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL,
+			reviewed_at DATETIME,
+			UNIQUE(closed_event_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_qa_reviews_status ON qa_reviews(status);
+		CREATE INDEX IF NOT EXISTS idx_qa_reviews_sample_day ON qa_reviews(sample_day);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createGuardrailTripsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS guardrail_trips (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guardrail TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			rule_level INTEGER NOT NULL DEFAULT 0,
+			detail TEXT NOT NULL DEFAULT '',
+			tripped_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_guardrail_trips_tripped_at ON guardrail_trips(tripped_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createCaseTables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS cases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			correlation_key TEXT NOT NULL,
+			key_values TEXT NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL,
+			max_level INTEGER NOT NULL DEFAULT 0,
+			alert_count INTEGER NOT NULL DEFAULT 0,
+			low_priority INTEGER NOT NULL DEFAULT 0,
+			high_priority INTEGER NOT NULL DEFAULT 0,
+			first_seen DATETIME NOT NULL,
+			last_seen DATETIME NOT NULL,
+			escalated_by TEXT NOT NULL DEFAULT '',
+			escalate_reason TEXT NOT NULL DEFAULT '',
+			escalated_at DATETIME,
+			closed_by TEXT NOT NULL DEFAULT '',
+			close_reason TEXT NOT NULL DEFAULT '',
+			label TEXT NOT NULL DEFAULT '',
+			closed_at DATETIME,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_cases_correlation_key ON cases(correlation_key, last_seen);
+		CREATE INDEX IF NOT EXISTS idx_cases_status ON cases(status);
+
+		CREATE TABLE IF NOT EXISTS case_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			case_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			rule_level INTEGER NOT NULL DEFAULT 0,
+			agent_name TEXT NOT NULL DEFAULT '',
+			raw_event TEXT,
+			alert_at DATETIME NOT NULL,
+			added_at DATETIME NOT NULL,
+			UNIQUE(event_id),
+			FOREIGN KEY (case_id) REFERENCES cases(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_case_alerts_case_id ON case_alerts(case_id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createSequenceFindingsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS sequence_findings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_id TEXT NOT NULL UNIQUE,
+			rule_name TEXT NOT NULL,
+			level INTEGER NOT NULL,
+			group_key TEXT NOT NULL DEFAULT '',
+			key_values TEXT NOT NULL,
+			event_ids TEXT NOT NULL,
+			raw_event TEXT,
+			first_seen DATETIME NOT NULL,
+			last_seen DATETIME NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_sequence_findings_created_at ON sequence_findings(created_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createSnoozeTables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS snoozes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			rule_id TEXT NOT NULL DEFAULT '',
+			agent_id TEXT NOT NULL DEFAULT '',
+			fingerprint TEXT NOT NULL DEFAULT '',
+			max_level INTEGER NOT NULL DEFAULT 0,
+			reason TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL,
+			status TEXT NOT NULL,
+			ended_by TEXT NOT NULL DEFAULT '',
+			ended_at DATETIME,
+			summary TEXT
+		);
+		CREATE INDEX IF NOT EXISTS idx_snoozes_status ON snoozes(status, expires_at);
+
+		CREATE TABLE IF NOT EXISTS snoozed_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			snooze_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			rule_level INTEGER NOT NULL DEFAULT 0,
+			agent_name TEXT NOT NULL DEFAULT '',
+			closed_event_id INTEGER NOT NULL,
+			alert_at DATETIME NOT NULL,
+			snoozed_at DATETIME NOT NULL,
+			UNIQUE(event_id),
+			FOREIGN KEY (snooze_id) REFERENCES snoozes(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_snoozed_alerts_snooze_id ON snoozed_alerts(snooze_id);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createMaintenanceTables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS maintenance_windows (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			starts_at DATETIME,
+			ends_at DATETIME,
+			cron TEXT NOT NULL DEFAULT '',
+			duration TEXT NOT NULL DEFAULT '',
+			timezone TEXT NOT NULL DEFAULT 'UTC',
+			agent_ids TEXT NOT NULL DEFAULT '[]',
+			agent_groups TEXT NOT NULL DEFAULT '[]',
+			agent_names TEXT NOT NULL DEFAULT '[]',
+			policy TEXT NOT NULL,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_by TEXT NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS maintenance_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			window_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			rule_level INTEGER NOT NULL DEFAULT 0,
+			agent_id TEXT NOT NULL DEFAULT '',
+			agent_name TEXT NOT NULL DEFAULT '',
+			policy TEXT NOT NULL,
+			outcome TEXT NOT NULL,
+			detail TEXT NOT NULL DEFAULT '',
+			closed_event_id INTEGER NOT NULL DEFAULT 0,
+			alert_at DATETIME NOT NULL,
+			tagged_at DATETIME NOT NULL,
+			UNIQUE(event_id),
+			FOREIGN KEY (window_id) REFERENCES maintenance_windows(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_maintenance_alerts_window_id ON maintenance_alerts(window_id, alert_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createAssetsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS assets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			agent_id TEXT NOT NULL DEFAULT '',
+			hostname TEXT NOT NULL DEFAULT '',
+			ip TEXT NOT NULL DEFAULT '',
+			owner TEXT NOT NULL DEFAULT '',
+			criticality TEXT NOT NULL,
+			environment TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT '[]',
+			source TEXT NOT NULL,
+			updated_by TEXT NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_assets_source ON assets(source);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createCVETables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS cves (
+			cve_id TEXT PRIMARY KEY,
+			description TEXT NOT NULL DEFAULT '',
+			published DATETIME,
+			last_modified DATETIME,
+			cvss_score REAL NOT NULL DEFAULT 0,
+			cvss_metrics TEXT NOT NULL DEFAULT '[]',
+			cwes TEXT NOT NULL DEFAULT '[]',
+			refs TEXT NOT NULL DEFAULT '[]',
+			epss TEXT NOT NULL DEFAULT '',
+			kev TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS cve_feed_imports (
+			path TEXT PRIMARY KEY,
+			kind TEXT NOT NULL,
+			records INTEGER NOT NULL DEFAULT 0,
+			file_mod_time DATETIME,
+			imported_at DATETIME,
+			error TEXT NOT NULL DEFAULT ''
+		);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
+
+func createRetroHuntTables(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS retro_hunts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL DEFAULT '',
+			indicators TEXT NOT NULL DEFAULT '[]',
+			indices TEXT NOT NULL DEFAULT '[]',
+			from_time DATETIME NOT NULL,
+			to_time DATETIME NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT '',
+			searched INTEGER NOT NULL DEFAULT 0,
+			truncated INTEGER NOT NULL DEFAULT 0,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			started_at DATETIME,
+			finished_at DATETIME
+		);
+
+		CREATE TABLE IF NOT EXISTS retro_hunt_matches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			hunt_id INTEGER NOT NULL,
+			document_id TEXT NOT NULL,
+			event_id TEXT NOT NULL,
+			index_name TEXT NOT NULL,
+			alert_at DATETIME NOT NULL,
+			rule_id TEXT NOT NULL DEFAULT '',
+			rule_level INTEGER NOT NULL DEFAULT 0,
+			rule_description TEXT NOT NULL DEFAULT '',
+			agent_id TEXT NOT NULL DEFAULT '',
+			agent_name TEXT NOT NULL DEFAULT '',
+			indicator_type TEXT NOT NULL,
+			indicator TEXT NOT NULL,
+			observed TEXT NOT NULL,
+			fields TEXT NOT NULL DEFAULT '[]',
+			archived INTEGER NOT NULL DEFAULT 0,
+			matched_at DATETIME NOT NULL,
+			UNIQUE(hunt_id, index_name, document_id, indicator_type, indicator),
+			FOREIGN KEY (hunt_id) REFERENCES retro_hunts(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_retro_hunt_matches_hunt_id ON retro_hunt_matches(hunt_id, alert_at);
+	`
+
+	_, err := db.Exec(query)
+	return err
+}
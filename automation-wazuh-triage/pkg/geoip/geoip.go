@@ -0,0 +1,123 @@
+package geoip
+
+import (
+	"automation-wazuh-triage/pkg/logger"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// checkInterval is how often the file is checked for a replacement while lookups are made
+const checkInterval = 30 * time.Second
+
+// Database is a MaxMind .mmdb file, such as GeoLite2-City or GeoLite2-ASN. It is opened on first lookup and
+// reopened when the file on disk changes, so a database updated by replacing the file (as geoipupdate does) is
+// picked up without a restart. A missing or unreadable file only disables lookups until it is fixed.
+type Database struct {
+	path string
+
+	mu        sync.RWMutex
+	reader    *maxminddb.Reader
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+// City is the part of a GeoLite2-City record the triage uses
+type City struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude  float64 `maxminddb:"latitude"`
+		Longitude float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+// ASN is a GeoLite2-ASN record
+type ASN struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Open returns the database at path; an empty path returns nil, which never finds anything
+func Open(path string) *Database {
+	if path == "" {
+		return nil
+	}
+	return &Database{path: path}
+}
+
+// Lookup decodes the record of the address into result and reports whether the database has one
+func (d *Database) Lookup(ip net.IP, result interface{}) (bool, error) {
+	if d == nil {
+		return false, nil
+	}
+
+	d.refresh()
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.reader == nil {
+		return false, nil
+	}
+
+	_, found, err := d.reader.LookupNetwork(ip, result)
+	return found, err
+}
+
+// refresh opens the file, or reopens it when its size or modification time changed, at most once per
+// checkInterval. The previous database keeps serving when the new file cannot be opened.
+func (d *Database) refresh() {
+	d.mu.RLock()
+	due := time.Since(d.checkedAt) >= checkInterval
+	d.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.checkedAt) < checkInterval {
+		return
+	}
+	d.checkedAt = time.Now()
+
+	log := logger.GetLogger().WithField("path", d.path)
+
+	info, err := os.Stat(d.path)
+	if err != nil {
+		log.WithError(err).Warn("[geoip]: Failed to read database file")
+		return
+	}
+	if d.reader != nil && info.ModTime().Equal(d.modTime) && info.Size() == d.size {
+		return
+	}
+
+	reader, err := maxminddb.Open(d.path)
+	if err != nil {
+		log.WithError(err).Warn("[geoip]: Failed to open database file, keeping the loaded database")
+		return
+	}
+
+	if d.reader != nil {
+		d.reader.Close()
+	}
+	d.reader = reader
+	d.modTime = info.ModTime()
+	d.size = info.Size()
+
+	log.WithField("type", reader.Metadata.DatabaseType).WithField("built_at", time.Unix(int64(reader.Metadata.BuildEpoch), 0).UTC()).Info("[geoip]: Database loaded")
+}
@@ -0,0 +1,99 @@
+// Package metrics renders gauges in the Prometheus text exposition format
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sample is a single gauge value with its labels
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Gauge is a metric family whose samples are written under one HELP and TYPE header
+type Gauge struct {
+	Name    string
+	Help    string
+	Samples []Sample
+}
+
+// Add appends a sample to the gauge. Labels are given as name, value pairs.
+func (g *Gauge) Add(value float64, labels ...string) {
+	sample := Sample{Value: value}
+	if len(labels) > 0 {
+		sample.Labels = map[string]string{}
+		for i := 0; i+1 < len(labels); i += 2 {
+			sample.Labels[labels[i]] = labels[i+1]
+		}
+	}
+	g.Samples = append(g.Samples, sample)
+}
+
+// Write renders the gauges. Families without samples are skipped.
+func Write(w io.Writer, gauges []*Gauge) error {
+	for _, gauge := range gauges {
+		if len(gauge.Samples) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", gauge.Name, escapeHelp(gauge.Help), gauge.Name); err != nil {
+			return err
+		}
+
+		for _, sample := range gauge.Samples {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", gauge.Name, formatLabels(sample.Labels), formatValue(sample.Value)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabelValue(labels[name])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Notification is the payload delivered to the configured webhook
+type Notification struct {
+	Title     string      `json:"title"`
+	Severity  string      `json:"severity"` // info, warning or critical
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp string      `json:"timestamp"`
+}
+
+type Notifier struct {
+	Client     *resty.Client
+	WebhookURL string
+}
+
+func NewNotifier() *Notifier {
+	rest := resty.New()
+	rest.SetTimeout(10 * time.Second)
+	rest.SetHeaders(map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	})
+
+	return &Notifier{
+		Client:     rest,
+		WebhookURL: os.Getenv("NOTIFY_WEBHOOK_URL"),
+	}
+}
+
+// Notify always logs the notification and, when NOTIFY_WEBHOOK_URL is set, posts it to the webhook
+func (n *Notifier) Notify(ctx context.Context, notification Notification) error {
+	log := logger.WithRequestID(ctx)
+
+	if notification.Timestamp == "" {
+		notification.Timestamp = time.Now().Format(time.RFC3339)
+	}
+
+	log.WithField("title", notification.Title).WithField("severity", notification.Severity).Warn("[notifier]: " + notification.Message)
+
+	if n.WebhookURL == "" {
+		return nil
+	}
+
+	resp, err := n.Client.R().SetContext(ctx).SetBody(notification).Post(n.WebhookURL)
+	if err != nil {
+		log.WithError(err).Error("[notifier]: Failed to deliver notification")
+		return err
+	}
+
+	if resp.IsError() {
+		log.WithField("status", resp.StatusCode()).Error("[notifier]: Webhook rejected notification")
+		return fmt.Errorf("notification webhook failed: %s", resp.Status())
+	}
+
+	return nil
+}
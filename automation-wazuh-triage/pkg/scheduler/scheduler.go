@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"automation-wazuh-triage/pkg/logger"
+	"context"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job is a unit of work executed on every tick
+type Job func(ctx context.Context) error
+
+// IntervalFromEnv parses a duration such as "1h" or "15m" from the given environment variable.
+// An empty or invalid value disables the job by returning zero.
+func IntervalFromEnv(key string) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		logger.GetLogger().WithError(err).WithField("env", key).Warn("[scheduler]: Invalid interval, job disabled")
+		return 0
+	}
+
+	return interval
+}
+
+// Every runs job in the background on the given interval until ctx is cancelled.
+// A zero interval disables the job.
+func Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	log := logger.GetLogger().WithField("job", name)
+
+	if interval <= 0 {
+		log.Info("[scheduler]: Job disabled")
+		return
+	}
+
+	log.WithField("interval", interval.String()).Info("[scheduler]: Job scheduled")
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Every run gets its own request ID so its logs can be correlated like an HTTP request
+				jobCtx := context.WithValue(ctx, "request_id", name+"-"+uuid.New().String())
+
+				start := time.Now()
+				if err := job(jobCtx); err != nil {
+					log.WithError(err).Error("[scheduler]: Job run failed")
+					continue
+				}
+				log.WithField("duration", time.Since(start).String()).Debug("[scheduler]: Job run completed")
+			}
+		}
+	}()
+}
@@ -0,0 +1,21 @@
+package wazuh
+
+import "fmt"
+
+func (w *Wazuh) GetAgents(queryString string) ([]byte, error) {
+	err := w.authenticate()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := w.Client.R().SetQueryString(queryString).Get("/agents")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("GetAgents failed: %s", resp.String())
+	}
+
+	return resp.Body(), nil
+}
@@ -0,0 +1,54 @@
+package wazuh
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// LogtestRequest is the body of PUT /logtest. An empty token starts a new session.
+type LogtestRequest struct {
+	Token     string `json:"token,omitempty"`
+	LogFormat string `json:"log_format"`
+	Location  string `json:"location"`
+	Event     string `json:"event"`
+}
+
+// RunLogtest sends a single log line through the manager decoders and rules.
+// The manager loads the ruleset when a session starts, so rule file changes are only
+// visible to sessions created after the upload.
+func (w *Wazuh) RunLogtest(request LogtestRequest) ([]byte, error) {
+	err := w.authenticate()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := w.Client.R().SetBody(request).Put("/logtest")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("RunLogtest failed: %s", resp.String())
+	}
+
+	return resp.Body(), nil
+}
+
+// EndLogtestSession removes a logtest session and frees its ruleset on the manager
+func (w *Wazuh) EndLogtestSession(token string) error {
+	err := w.authenticate()
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.Client.R().Delete("/logtest/sessions/" + url.PathEscape(token))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("EndLogtestSession failed: %s", resp.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package wazuh
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ValidateConfiguration asks the manager to validate its configuration, including every rule file
+func (w *Wazuh) ValidateConfiguration() error {
+	err := w.authenticate()
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.Client.R().Get("/manager/configuration/validation")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("ValidateConfiguration failed: %s", resp.String())
+	}
+
+	var result struct {
+		Data struct {
+			AffectedItems []struct {
+				Status string `json:"status"`
+			} `json:"affected_items"`
+			FailedItems []struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"failed_items"`
+		} `json:"data"`
+		Error int `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return err
+	}
+
+	if len(result.Data.FailedItems) > 0 {
+		return fmt.Errorf("configuration validation failed: %s", result.Data.FailedItems[0].Error.Message)
+	}
+
+	for _, item := range result.Data.AffectedItems {
+		if item.Status != "OK" {
+			return fmt.Errorf("configuration validation failed with status %s", item.Status)
+		}
+	}
+
+	return nil
+}
+
+// RestartManager restarts the manager so rule file changes take effect
+func (w *Wazuh) RestartManager() error {
+	err := w.authenticate()
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.Client.R().Put("/manager/restart")
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("RestartManager failed: %s", resp.String())
+	}
+
+	return nil
+}
@@ -1,7 +1,12 @@
 package wazuh
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"net/url"
+	"strings"
 )
 
 func (w *Wazuh) GetRules(queryString string) ([]byte, error) {
@@ -35,3 +40,108 @@ func (w *Wazuh) GetRulesFiles(queryString string) ([]byte, error) {
 
 	return resp.Body(), nil
 }
+
+// GetRuleFile returns the raw XML content of a rule file. The boolean is false when the file does not exist.
+func (w *Wazuh) GetRuleFile(filename string) ([]byte, bool, error) {
+	err := w.authenticate()
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := w.Client.R().
+		SetQueryParam("raw", "true").
+		SetHeader("Accept", "application/xml").
+		Get("/rules/files/" + url.PathEscape(filename))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if resp.StatusCode() == 404 {
+		return nil, false, nil
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, false, fmt.Errorf("GetRuleFile failed: %s", resp.String())
+	}
+
+	return resp.Body(), true, nil
+}
+
+// UpdateRuleFile uploads the content of a rule file, overwriting the existing one
+func (w *Wazuh) UpdateRuleFile(filename string, content []byte) ([]byte, error) {
+	err := w.authenticate()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := w.Client.R().
+		SetQueryParam("overwrite", "true").
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody(content).
+		Put("/rules/files/" + url.PathEscape(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("UpdateRuleFile failed: %s", resp.String())
+	}
+
+	// Wazuh answers 200 even when the file is rejected, the failure is reported in the body
+	var result struct {
+		Error int `json:"error"`
+		Data  struct {
+			FailedItems []struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"failed_items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body(), &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != 0 {
+		if len(result.Data.FailedItems) > 0 {
+			return nil, fmt.Errorf("UpdateRuleFile failed: %s", result.Data.FailedItems[0].Error.Message)
+		}
+		return nil, fmt.Errorf("UpdateRuleFile failed: %s", resp.String())
+	}
+
+	return resp.Body(), nil
+}
+
+// DeleteRuleFile removes a custom rule file from the manager
+func (w *Wazuh) DeleteRuleFile(filename string) error {
+	err := w.authenticate()
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.Client.R().Delete("/rules/files/" + url.PathEscape(filename))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != 200 && resp.StatusCode() != 404 {
+		return fmt.Errorf("DeleteRuleFile failed: %s", resp.String())
+	}
+
+	return nil
+}
+
+// ValidateRuleFileXML checks that rule file content is well-formed XML. Rule files have several
+// top-level elements, so the content is wrapped before decoding. The manager still has the final word.
+func ValidateRuleFileXML(content string) error {
+	decoder := xml.NewDecoder(strings.NewReader("<root>" + content + "</root>"))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package wazuh
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SuppressionBlockBegin and SuppressionBlockEnd delimit the generated section of a rule file.
+	// Everything outside the markers is left untouched when suppressions are pushed.
+	SuppressionBlockBegin = "<!-- triage-suppressions:begin (generated, do not edit) -->"
+	SuppressionBlockEnd   = "<!-- triage-suppressions:end -->"
+
+	OperatorEquals   = "equals"
+	OperatorContains = "contains"
+	OperatorRegex    = "regex"
+)
+
+// staticFields are the Wazuh rule options that can be matched directly, without <field name="...">
+var staticFields = map[string]bool{
+	"srcip":        true,
+	"dstip":        true,
+	"srcport":      true,
+	"dstport":      true,
+	"user":         true,
+	"hostname":     true,
+	"program_name": true,
+	"location":     true,
+	"url":          true,
+	"id":           true,
+	"system_name":  true,
+	"protocol":     true,
+	"action":       true,
+	"status":       true,
+	"data":         true,
+	"extra_data":   true,
+	"match":        true,
+}
+
+// fieldAliases maps alert field paths to the matching Wazuh rule option
+var fieldAliases = map[string]string{
+	"data.srcip":              "srcip",
+	"data.dstip":              "dstip",
+	"data.srcport":            "srcport",
+	"data.dstport":            "dstport",
+	"data.srcuser":            "user",
+	"data.dstuser":            "user",
+	"data.url":                "url",
+	"data.protocol":           "protocol",
+	"data.id":                 "id",
+	"full_log":                "match",
+	"predecoder.hostname":     "hostname",
+	"predecoder.program_name": "program_name",
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// SuppressionRule is the input of the generator
+type SuppressionRule struct {
+	ID           int
+	ParentRuleID int
+	Description  string
+	Conditions   []SuppressionCondition
+}
+
+// SuppressionCondition is a single field condition of a suppression rule
+type SuppressionCondition struct {
+	Field    string
+	Value    string
+	Operator string
+	Negate   bool
+}
+
+type xmlRuleOption struct {
+	XMLName xml.Name
+	Name    string `xml:"name,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Negate  string `xml:"negate,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+type xmlRule struct {
+	XMLName     xml.Name        `xml:"rule"`
+	ID          int             `xml:"id,attr"`
+	Level       int             `xml:"level,attr"`
+	IfSid       int             `xml:"if_sid"`
+	Options     []xmlRuleOption `xml:",any"`
+	Description string          `xml:"description"`
+}
+
+type xmlGroup struct {
+	XMLName xml.Name  `xml:"group"`
+	Name    string    `xml:"name,attr"`
+	Rules   []xmlRule `xml:"rule"`
+}
+
+// GenerateSuppressionRulesXML renders the given suppressions as level 0 child rules
+// wrapped in the managed block markers
+func GenerateSuppressionRulesXML(rules []SuppressionRule) (string, error) {
+	group := xmlGroup{Name: "local,triage_suppression,"}
+
+	for _, rule := range rules {
+		generated, err := buildSuppressionRule(rule)
+		if err != nil {
+			return "", err
+		}
+		group.Rules = append(group.Rules, generated)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(SuppressionBlockBegin + "\n")
+
+	if len(group.Rules) > 0 {
+		encoder := xml.NewEncoder(&buf)
+		encoder.Indent("", "  ")
+		if err := encoder.Encode(group); err != nil {
+			return "", err
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString(SuppressionBlockEnd + "\n")
+	return buf.String(), nil
+}
+
+// MergeSuppressionBlock replaces the managed block inside an existing rule file,
+// or appends it when the file has no managed block yet
+func MergeSuppressionBlock(fileContent string, block string) string {
+	begin := strings.Index(fileContent, SuppressionBlockBegin)
+	end := strings.Index(fileContent, SuppressionBlockEnd)
+
+	if begin >= 0 && end > begin {
+		tail := fileContent[end+len(SuppressionBlockEnd):]
+		tail = strings.TrimPrefix(tail, "\n")
+		return fileContent[:begin] + block + tail
+	}
+
+	if fileContent != "" && !strings.HasSuffix(fileContent, "\n") {
+		fileContent += "\n"
+	}
+	if fileContent != "" {
+		fileContent += "\n"
+	}
+
+	return fileContent + block
+}
+
+func buildSuppressionRule(rule SuppressionRule) (xmlRule, error) {
+	if rule.ID <= 0 {
+		return xmlRule{}, fmt.Errorf("suppression rule ID must be positive")
+	}
+	if rule.ParentRuleID <= 0 {
+		return xmlRule{}, fmt.Errorf("suppression %d: parent rule ID must be positive", rule.ID)
+	}
+	if len(rule.Conditions) == 0 {
+		return xmlRule{}, fmt.Errorf("suppression %d: at least one condition is required", rule.ID)
+	}
+
+	generated := xmlRule{
+		ID:          rule.ID,
+		Level:       0,
+		IfSid:       rule.ParentRuleID,
+		Description: rule.Description,
+	}
+
+	if generated.Description == "" {
+		generated.Description = "Triage suppression of rule " + strconv.Itoa(rule.ParentRuleID)
+	}
+
+	for _, condition := range rule.Conditions {
+		option, err := buildRuleOption(condition)
+		if err != nil {
+			return xmlRule{}, fmt.Errorf("suppression %d: %w", rule.ID, err)
+		}
+		generated.Options = append(generated.Options, option)
+	}
+
+	return generated, nil
+}
+
+func buildRuleOption(condition SuppressionCondition) (xmlRuleOption, error) {
+	field := strings.TrimSpace(condition.Field)
+	if field == "" {
+		return xmlRuleOption{}, fmt.Errorf("condition field is required")
+	}
+	if condition.Value == "" {
+		return xmlRuleOption{}, fmt.Errorf("condition %s: value is required", field)
+	}
+
+	if alias, ok := fieldAliases[field]; ok {
+		field = alias
+	}
+
+	option := xmlRuleOption{}
+	if condition.Negate {
+		option.Negate = "yes"
+	}
+
+	// Agents are matched on the event location, which starts with "(agent_name)" for agent events
+	if field == "agent" || field == "agent.name" {
+		option.XMLName = xml.Name{Local: "location"}
+		option.Type = "pcre2"
+		option.Value = `\(` + regexp.QuoteMeta(condition.Value) + `\)`
+		return option, nil
+	}
+	if field == "agent.id" {
+		return xmlRuleOption{}, fmt.Errorf("agent.id cannot be matched by Wazuh rules, use agent.name instead")
+	}
+
+	// IP options take a literal address or CIDR and do not support a type attribute
+	if field == "srcip" || field == "dstip" {
+		if net.ParseIP(condition.Value) == nil {
+			if _, _, err := net.ParseCIDR(condition.Value); err != nil {
+				return xmlRuleOption{}, fmt.Errorf("condition %s: %q is not an IP address or CIDR", field, condition.Value)
+			}
+		}
+		option.XMLName = xml.Name{Local: field}
+		option.Value = condition.Value
+		if condition.Negate {
+			option.Negate = ""
+			option.Value = "!" + condition.Value
+		}
+		return option, nil
+	}
+
+	if staticFields[field] {
+		option.XMLName = xml.Name{Local: field}
+	} else {
+		if !fieldNamePattern.MatchString(field) {
+			return xmlRuleOption{}, fmt.Errorf("condition field %q contains invalid characters", field)
+		}
+		option.XMLName = xml.Name{Local: "field"}
+		option.Name = strings.TrimPrefix(field, "data.")
+	}
+
+	option.Type = "pcre2"
+
+	switch condition.Operator {
+	case "", OperatorEquals:
+		option.Value = "^" + regexp.QuoteMeta(condition.Value) + "$"
+	case OperatorContains:
+		option.Value = regexp.QuoteMeta(condition.Value)
+	case OperatorRegex:
+		if _, err := regexp.Compile(condition.Value); err != nil {
+			return xmlRuleOption{}, fmt.Errorf("condition %s: invalid regex: %w", field, err)
+		}
+		option.Value = condition.Value
+	default:
+		return xmlRuleOption{}, fmt.Errorf("condition %s: unsupported operator %q", field, condition.Operator)
+	}
+
+	return option, nil
+}
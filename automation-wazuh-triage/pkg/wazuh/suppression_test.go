@@ -0,0 +1,166 @@
+package wazuh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateSuppressionRulesXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []SuppressionRule
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "no suppressions keeps an empty block",
+			rules: nil,
+			want:  SuppressionBlockBegin + "\n" + SuppressionBlockEnd + "\n",
+		},
+		{
+			name: "field options",
+			rules: []SuppressionRule{{
+				ID:           100100,
+				ParentRuleID: 5710,
+				Conditions: []SuppressionCondition{
+					{Field: "data.srcip", Value: "10.0.0.0/8", Negate: true},
+					{Field: "agent.name", Value: "web-01"},
+					{Field: "data.win.system.eventID", Value: "4625", Operator: OperatorContains},
+					{Field: "full_log", Value: "a.b"},
+				},
+			}},
+			want: SuppressionBlockBegin + "\n" +
+				`<group name="local,triage_suppression,">` + "\n" +
+				`  <rule id="100100" level="0">` + "\n" +
+				`    <if_sid>5710</if_sid>` + "\n" +
+				`    <srcip>!10.0.0.0/8</srcip>` + "\n" +
+				`    <location type="pcre2">\(web-01\)</location>` + "\n" +
+				`    <field name="win.system.eventID" type="pcre2">4625</field>` + "\n" +
+				`    <match type="pcre2">^a\.b$</match>` + "\n" +
+				`    <description>Triage suppression of rule 5710</description>` + "\n" +
+				`  </rule>` + "\n" +
+				`</group>` + "\n" +
+				SuppressionBlockEnd + "\n",
+		},
+		{
+			name: "description, negated regex and escaping",
+			rules: []SuppressionRule{{
+				ID:           100101,
+				ParentRuleID: 31101,
+				Description:  "Scanner <internal>",
+				Conditions: []SuppressionCondition{
+					{Field: "data.url", Value: "^/health(z)?$", Operator: OperatorRegex, Negate: true},
+				},
+			}},
+			want: SuppressionBlockBegin + "\n" +
+				`<group name="local,triage_suppression,">` + "\n" +
+				`  <rule id="100101" level="0">` + "\n" +
+				`    <if_sid>31101</if_sid>` + "\n" +
+				`    <url type="pcre2" negate="yes">^/health(z)?$</url>` + "\n" +
+				`    <description>Scanner &lt;internal&gt;</description>` + "\n" +
+				`  </rule>` + "\n" +
+				`</group>` + "\n" +
+				SuppressionBlockEnd + "\n",
+		},
+		{
+			name:    "missing parent rule",
+			rules:   []SuppressionRule{{ID: 100100, Conditions: []SuppressionCondition{{Field: "user", Value: "root"}}}},
+			wantErr: "parent rule ID must be positive",
+		},
+		{
+			name:    "no conditions",
+			rules:   []SuppressionRule{{ID: 100100, ParentRuleID: 5710}},
+			wantErr: "at least one condition is required",
+		},
+		{
+			name:    "agent id",
+			rules:   []SuppressionRule{{ID: 100100, ParentRuleID: 5710, Conditions: []SuppressionCondition{{Field: "agent.id", Value: "001"}}}},
+			wantErr: "use agent.name instead",
+		},
+		{
+			name:    "ip that is not an address",
+			rules:   []SuppressionRule{{ID: 100100, ParentRuleID: 5710, Conditions: []SuppressionCondition{{Field: "srcip", Value: "host"}}}},
+			wantErr: "is not an IP address or CIDR",
+		},
+		{
+			name:    "field name with markup",
+			rules:   []SuppressionRule{{ID: 100100, ParentRuleID: 5710, Conditions: []SuppressionCondition{{Field: `data.x"><y`, Value: "1"}}}},
+			wantErr: "contains invalid characters",
+		},
+		{
+			name:    "unsupported operator",
+			rules:   []SuppressionRule{{ID: 100100, ParentRuleID: 5710, Conditions: []SuppressionCondition{{Field: "user", Value: "root", Operator: "starts_with"}}}},
+			wantErr: "unsupported operator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateSuppressionRulesXML(tt.rules)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSuppressionRulesXML: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSuppressionBlock(t *testing.T) {
+	block := SuppressionBlockBegin + "\n<group/>\n" + SuppressionBlockEnd + "\n"
+	oldBlock := SuppressionBlockBegin + "\n<group name=\"old\"/>\n" + SuppressionBlockEnd
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			want:    block,
+		},
+		{
+			name:    "appended after a blank line",
+			content: "<group name=\"local,\"/>\n",
+			want:    "<group name=\"local,\"/>\n\n" + block,
+		},
+		{
+			name:    "appended to a file without a trailing newline",
+			content: "<group name=\"local,\"/>",
+			want:    "<group name=\"local,\"/>\n\n" + block,
+		},
+		{
+			name:    "block replaced, surrounding rules kept",
+			content: "<!-- head -->\n" + oldBlock + "\n<!-- tail -->\n",
+			want:    "<!-- head -->\n" + block + "<!-- tail -->\n",
+		},
+		{
+			name:    "block at the end without a trailing newline",
+			content: "<!-- head -->\n" + oldBlock,
+			want:    "<!-- head -->\n" + block,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeSuppressionBlock(tt.content, block)
+			if got != tt.want {
+				t.Fatalf("got\n%q\nwant\n%q", got, tt.want)
+			}
+			// Pushing the same block again must leave a file with one block as it is
+			if strings.Count(got, SuppressionBlockBegin) == 1 {
+				if again := MergeSuppressionBlock(got, block); again != got {
+					t.Fatalf("merging twice changed the file:\n%q\nthen\n%q", got, again)
+				}
+			}
+		})
+	}
+}